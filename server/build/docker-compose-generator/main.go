@@ -27,6 +27,8 @@ func main() {
 		"mysql":              3306,
 		"postgres":           5432,
 		"minio":              9000,
+		"fake-gcs":           4443,
+		"azurite":            10000,
 		"inbucket":           9001,
 		"openldap":           389,
 		"elasticsearch":      9200,
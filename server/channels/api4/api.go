@@ -97,6 +97,8 @@ type Routes struct {
 
 	DataRetention *mux.Router // 'api/v4/data_retention'
 
+	LegalHolds *mux.Router // 'api/v4/legal_holds'
+
 	Brand *mux.Router // 'api/v4/brand'
 
 	System *mux.Router // 'api/v4/system'
@@ -250,6 +252,7 @@ func Init(srv *app.Server) (*API, error) {
 	api.BaseRoutes.Elasticsearch = api.BaseRoutes.APIRoot.PathPrefix("/elasticsearch").Subrouter()
 	api.BaseRoutes.Bleve = api.BaseRoutes.APIRoot.PathPrefix("/bleve").Subrouter()
 	api.BaseRoutes.DataRetention = api.BaseRoutes.APIRoot.PathPrefix("/data_retention").Subrouter()
+	api.BaseRoutes.LegalHolds = api.BaseRoutes.APIRoot.PathPrefix("/legal_holds").Subrouter()
 
 	api.BaseRoutes.Emojis = api.BaseRoutes.APIRoot.PathPrefix("/emoji").Subrouter()
 	api.BaseRoutes.Emoji = api.BaseRoutes.APIRoot.PathPrefix("/emoji/{emoji_id:[A-Za-z0-9]+}").Subrouter()
@@ -317,6 +320,7 @@ func Init(srv *app.Server) (*API, error) {
 	api.InitElasticsearch()
 	api.InitBleve()
 	api.InitDataRetention()
+	api.InitLegalHold()
 	api.InitBrand()
 	api.InitJob()
 	api.InitCommand()
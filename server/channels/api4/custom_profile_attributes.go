@@ -196,14 +196,14 @@ func patchCPAValues(c *Context, w http.ResponseWriter, r *http.Request) {
 	defer c.LogAuditRec(auditRec)
 	audit.AddEventParameter(auditRec, "user_id", userID)
 
-	results := make(map[string]string)
 	for fieldID, value := range attributeValues {
-		patchedValue, appErr := c.App.PatchCPAValue(userID, fieldID, strings.TrimSpace(value))
-		if appErr != nil {
-			c.Err = appErr
-			return
-		}
-		results[fieldID] = patchedValue.Value
+		attributeValues[fieldID] = strings.TrimSpace(value)
+	}
+
+	results, appErr := c.App.PatchCPAValues(c.AppContext, userID, attributeValues)
+	if appErr != nil {
+		c.Err = appErr
+		return
 	}
 
 	auditRec.Success()
@@ -500,6 +500,11 @@ func getFile(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if info.IsQuarantined() {
+		c.Err = model.NewAppError("getFile", "api.file.get_file.quarantined.app_error", nil, "file_id="+info.Id, http.StatusForbidden)
+		return
+	}
+
 	fileReader, err := c.App.FileReader(info.Path)
 	if err != nil {
 		c.Err = err
@@ -543,6 +548,11 @@ func getFileThumbnail(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if info.IsQuarantined() {
+		c.Err = model.NewAppError("getFileThumbnail", "api.file.get_file.quarantined.app_error", nil, "file_id="+info.Id, http.StatusForbidden)
+		return
+	}
+
 	if info.ThumbnailPath == "" {
 		c.Err = model.NewAppError("getFileThumbnail", "api.file.get_file_thumbnail.no_thumbnail.app_error", nil, "file_id="+info.Id, http.StatusBadRequest)
 		return
@@ -597,6 +607,11 @@ func getFileLink(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if info.IsQuarantined() {
+		c.Err = model.NewAppError("getPublicLink", "api.file.get_file.quarantined.app_error", nil, "file_id="+info.Id, http.StatusForbidden)
+		return
+	}
+
 	if info.PostId == "" && info.CreatorId != model.BookmarkFileOwner {
 		c.Err = model.NewAppError("getPublicLink", "api.file.get_public_link.no_post.app_error", nil, "file_id="+info.Id, http.StatusBadRequest)
 		return
@@ -643,6 +658,11 @@ func getFilePreview(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if info.IsQuarantined() {
+		c.Err = model.NewAppError("getFilePreview", "api.file.get_file.quarantined.app_error", nil, "file_id="+info.Id, http.StatusForbidden)
+		return
+	}
+
 	if info.PreviewPath == "" {
 		c.Err = model.NewAppError("getFilePreview", "api.file.get_file_preview.no_preview.app_error", nil, "file_id="+info.Id, http.StatusBadRequest)
 		return
@@ -726,6 +746,11 @@ func getPublicFile(c *Context, w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if info.IsQuarantined() {
+		c.Err = model.NewAppError("getPublicFile", "api.file.get_file.quarantined.app_error", nil, "file_id="+info.Id, http.StatusForbidden)
+		return
+	}
+
 	fileReader, err := c.App.FileReader(info.Path)
 	if err != nil {
 		c.Err = err
@@ -0,0 +1,170 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package api4
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/v8/channels/audit"
+)
+
+func (api *API) InitLegalHold() {
+	api.BaseRoutes.LegalHolds.Handle("", api.APISessionRequired(getLegalHolds)).Methods(http.MethodGet)
+	api.BaseRoutes.LegalHolds.Handle("", api.APISessionRequired(createLegalHold)).Methods(http.MethodPost)
+	api.BaseRoutes.LegalHolds.Handle("/{legal_hold_id:[A-Za-z0-9]+}", api.APISessionRequired(getLegalHold)).Methods(http.MethodGet)
+	api.BaseRoutes.LegalHolds.Handle("/{legal_hold_id:[A-Za-z0-9]+}/release", api.APISessionRequired(releaseLegalHold)).Methods(http.MethodPost)
+	api.BaseRoutes.LegalHolds.Handle("/{legal_hold_id:[A-Za-z0-9]+}/export", api.APISessionRequired(exportLegalHold)).Methods(http.MethodPost)
+}
+
+func getLegalHolds(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !c.App.SessionHasPermissionTo(*c.AppContext.Session(), model.PermissionSysconsoleReadComplianceDataRetentionPolicy) {
+		c.SetPermissionError(model.PermissionSysconsoleReadComplianceDataRetentionPolicy)
+		return
+	}
+
+	includeReleased, _ := strconv.ParseBool(r.URL.Query().Get("include_released"))
+
+	holds, appErr := c.App.GetLegalHolds(c.Params.Page, c.Params.PerPage, includeReleased)
+	if appErr != nil {
+		c.Err = appErr
+		return
+	}
+
+	js, err := json.Marshal(holds)
+	if err != nil {
+		c.Err = model.NewAppError("getLegalHolds", "api.marshal_error", nil, "", http.StatusInternalServerError).Wrap(err)
+		return
+	}
+	w.Write(js)
+}
+
+func getLegalHold(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireLegalHoldId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(*c.AppContext.Session(), model.PermissionSysconsoleReadComplianceDataRetentionPolicy) {
+		c.SetPermissionError(model.PermissionSysconsoleReadComplianceDataRetentionPolicy)
+		return
+	}
+
+	hold, appErr := c.App.GetLegalHold(c.Params.LegalHoldId)
+	if appErr != nil {
+		c.Err = appErr
+		return
+	}
+
+	js, err := json.Marshal(hold)
+	if err != nil {
+		c.Err = model.NewAppError("getLegalHold", "api.marshal_error", nil, "", http.StatusInternalServerError).Wrap(err)
+		return
+	}
+	w.Write(js)
+}
+
+func createLegalHold(c *Context, w http.ResponseWriter, r *http.Request) {
+	var hold model.LegalHold
+	if jsonErr := json.NewDecoder(r.Body).Decode(&hold); jsonErr != nil {
+		c.SetInvalidParamWithErr("legal_hold", jsonErr)
+		return
+	}
+
+	auditRec := c.MakeAuditRecord("createLegalHold", audit.Fail)
+	defer c.LogAuditRec(auditRec)
+	audit.AddEventParameterAuditable(auditRec, "legal_hold", &hold)
+
+	if !c.App.SessionHasPermissionTo(*c.AppContext.Session(), model.PermissionSysconsoleWriteComplianceDataRetentionPolicy) {
+		c.SetPermissionError(model.PermissionSysconsoleWriteComplianceDataRetentionPolicy)
+		return
+	}
+
+	hold.Id = ""
+	hold.CreatorId = c.AppContext.Session().UserId
+
+	newHold, appErr := c.App.CreateLegalHold(c.AppContext, &hold)
+	if appErr != nil {
+		c.Err = appErr
+		return
+	}
+
+	auditRec.AddEventResultState(newHold)
+	auditRec.AddEventObjectType("legal_hold")
+	js, err := json.Marshal(newHold)
+	if err != nil {
+		c.Err = model.NewAppError("createLegalHold", "api.marshal_error", nil, "", http.StatusInternalServerError).Wrap(err)
+		return
+	}
+	auditRec.Success()
+	w.WriteHeader(http.StatusCreated)
+	w.Write(js)
+}
+
+func releaseLegalHold(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireLegalHoldId()
+	if c.Err != nil {
+		return
+	}
+
+	auditRec := c.MakeAuditRecord("releaseLegalHold", audit.Fail)
+	defer c.LogAuditRec(auditRec)
+	audit.AddEventParameter(auditRec, "legal_hold_id", c.Params.LegalHoldId)
+
+	if !c.App.SessionHasPermissionTo(*c.AppContext.Session(), model.PermissionSysconsoleWriteComplianceDataRetentionPolicy) {
+		c.SetPermissionError(model.PermissionSysconsoleWriteComplianceDataRetentionPolicy)
+		return
+	}
+
+	hold, appErr := c.App.ReleaseLegalHold(c.AppContext, c.Params.LegalHoldId)
+	if appErr != nil {
+		c.Err = appErr
+		return
+	}
+
+	auditRec.AddEventResultState(hold)
+	auditRec.AddEventObjectType("legal_hold")
+	js, err := json.Marshal(hold)
+	if err != nil {
+		c.Err = model.NewAppError("releaseLegalHold", "api.marshal_error", nil, "", http.StatusInternalServerError).Wrap(err)
+		return
+	}
+	auditRec.Success()
+	w.Write(js)
+}
+
+func exportLegalHold(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireLegalHoldId()
+	if c.Err != nil {
+		return
+	}
+
+	auditRec := c.MakeAuditRecord("exportLegalHold", audit.Fail)
+	defer c.LogAuditRec(auditRec)
+	audit.AddEventParameter(auditRec, "legal_hold_id", c.Params.LegalHoldId)
+
+	if !c.App.SessionHasPermissionTo(*c.AppContext.Session(), model.PermissionCreateComplianceExportJob) {
+		c.SetPermissionError(model.PermissionCreateComplianceExportJob)
+		return
+	}
+
+	job, appErr := c.App.ExportLegalHold(c.AppContext, c.Params.LegalHoldId)
+	if appErr != nil {
+		c.Err = appErr
+		return
+	}
+
+	auditRec.AddEventResultState(job)
+	auditRec.AddEventObjectType("job")
+	js, err := json.Marshal(job)
+	if err != nil {
+		c.Err = model.NewAppError("exportLegalHold", "api.marshal_error", nil, "", http.StatusInternalServerError).Wrap(err)
+		return
+	}
+	auditRec.Success()
+	w.WriteHeader(http.StatusCreated)
+	w.Write(js)
+}
@@ -9,7 +9,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -68,6 +70,7 @@ func (api *API) InitTeam() {
 	api.BaseRoutes.TeamMember.Handle("/roles", api.APISessionRequired(updateTeamMemberRoles)).Methods(http.MethodPut)
 	api.BaseRoutes.TeamMember.Handle("/schemeRoles", api.APISessionRequired(updateTeamMemberSchemeRoles)).Methods(http.MethodPut)
 	api.BaseRoutes.Team.Handle("/import", api.APISessionRequired(importTeam)).Methods(http.MethodPost)
+	api.BaseRoutes.Team.Handle("/import/job", api.APISessionRequired(importTeamJob)).Methods(http.MethodPost)
 	api.BaseRoutes.Team.Handle("/invite/email", api.APISessionRequired(inviteUsersToTeam)).Methods(http.MethodPost)
 	api.BaseRoutes.Team.Handle("/invite-guests/email", api.APISessionRequired(inviteGuestsToChannels)).Methods(http.MethodPost)
 	api.BaseRoutes.Teams.Handle("/invites/email", api.APISessionRequired(invalidateAllEmailInvites)).Methods(http.MethodDelete)
@@ -1297,62 +1300,15 @@ func teamExists(c *Context, w http.ResponseWriter, r *http.Request) {
 }
 
 func importTeam(c *Context, w http.ResponseWriter, r *http.Request) {
-	if c.App.Channels().License().IsCloud() {
-		c.Err = model.NewAppError("importTeam", "api.restricted_system_admin", nil, "", http.StatusForbidden)
-		return
-	}
-
-	c.RequireTeamId()
+	importFrom, fileSize, fileInfo := parseImportTeamForm(c, r, "importTeam")
 	if c.Err != nil {
 		return
 	}
 
-	if !c.App.SessionHasPermissionToTeam(*c.AppContext.Session(), c.Params.TeamId, model.PermissionImportTeam) {
-		c.SetPermissionError(model.PermissionImportTeam)
-		return
-	}
-
-	if err := r.ParseMultipartForm(MaximumBulkImportSize); err != nil {
-		c.Err = model.NewAppError("importTeam", "api.team.import_team.parse.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
-		return
-	}
-
-	importFromArray, ok := r.MultipartForm.Value["importFrom"]
-	if !ok || len(importFromArray) < 1 {
-		c.Err = model.NewAppError("importTeam", "api.team.import_team.no_import_from.app_error", nil, "", http.StatusBadRequest)
-		return
-	}
-	importFrom := importFromArray[0]
-
-	fileSizeStr, ok := r.MultipartForm.Value["filesize"]
-	if !ok || len(fileSizeStr) < 1 {
-		c.Err = model.NewAppError("importTeam", "api.team.import_team.unavailable.app_error", nil, "", http.StatusBadRequest)
-		return
-	}
-
-	fileSize, err := strconv.ParseInt(fileSizeStr[0], 10, 64)
-	if err != nil {
-		c.Err = model.NewAppError("importTeam", "api.team.import_team.integer.app_error", nil, "", http.StatusBadRequest)
-		return
-	}
-
-	fileInfoArray, ok := r.MultipartForm.File["file"]
-	if !ok {
-		c.Err = model.NewAppError("importTeam", "api.team.import_team.no_file.app_error", nil, "", http.StatusBadRequest)
-		return
-	}
-
-	if len(fileInfoArray) <= 0 {
-		c.Err = model.NewAppError("importTeam", "api.team.import_team.array.app_error", nil, "", http.StatusBadRequest)
-		return
-	}
-
 	auditRec := c.MakeAuditRecord("importTeam", audit.Fail)
 	defer c.LogAuditRec(auditRec)
 	audit.AddEventParameter(auditRec, "team_id", c.Params.TeamId)
 
-	fileInfo := fileInfoArray[0]
-
 	fileData, err := fileInfo.Open()
 	if err != nil {
 		c.Err = model.NewAppError("importTeam", "api.team.import_team.open.app_error", nil, "", http.StatusBadRequest).Wrap(err)
@@ -1387,6 +1343,118 @@ func importTeam(c *Context, w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func importTeamJob(c *Context, w http.ResponseWriter, r *http.Request) {
+	importFrom, fileSize, fileInfo := parseImportTeamForm(c, r, "importTeamJob")
+	if c.Err != nil {
+		return
+	}
+
+	auditRec := c.MakeAuditRecord("importTeamJob", audit.Fail)
+	defer c.LogAuditRec(auditRec)
+	audit.AddEventParameter(auditRec, "team_id", c.Params.TeamId)
+
+	fileData, err := fileInfo.Open()
+	if err != nil {
+		c.Err = model.NewAppError("importTeamJob", "api.team.import_team.open.app_error", nil, "", http.StatusBadRequest).Wrap(err)
+		return
+	}
+	defer fileData.Close()
+	audit.AddEventParameter(auditRec, "filename", fileInfo.Filename)
+	audit.AddEventParameter(auditRec, "filesize", fileSize)
+	audit.AddEventParameter(auditRec, "from", importFrom)
+
+	var job *model.Job
+	switch importFrom {
+	case "slack":
+		// The import runs as a job, so the upload is kept in the import directory until the job is done with it.
+		importFile := model.NewId() + "_" + filepath.Base(fileInfo.Filename)
+		if _, appErr := c.App.WriteFile(fileData, filepath.Join(*c.App.Config().ImportSettings.Directory, importFile)); appErr != nil {
+			c.Err = appErr
+			return
+		}
+
+		var appErr *model.AppError
+		job, appErr = c.App.CreateJob(c.AppContext, &model.Job{
+			Type: model.JobTypeSlackImport,
+			Data: map[string]string{
+				"import_file": importFile,
+				"team_id":     c.Params.TeamId,
+				"local_mode":  "false",
+			},
+		})
+		if appErr != nil {
+			c.Err = appErr
+			return
+		}
+	default:
+		c.Err = model.NewAppError("importTeamJob", "api.team.import_team.unknown_import_from.app_error", nil, "", http.StatusBadRequest)
+		return
+	}
+
+	auditRec.Success()
+	auditRec.AddMeta("job_id", job.Id)
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(job); err != nil {
+		c.Logger.Warn("Error while writing response", mlog.Err(err))
+	}
+}
+
+// parseImportTeamForm checks the permissions to import into the team and returns the fields
+// of the import form, setting c.Err when the request is not valid.
+func parseImportTeamForm(c *Context, r *http.Request, where string) (string, int64, *multipart.FileHeader) {
+	if c.App.Channels().License().IsCloud() {
+		c.Err = model.NewAppError(where, "api.restricted_system_admin", nil, "", http.StatusForbidden)
+		return "", 0, nil
+	}
+
+	c.RequireTeamId()
+	if c.Err != nil {
+		return "", 0, nil
+	}
+
+	if !c.App.SessionHasPermissionToTeam(*c.AppContext.Session(), c.Params.TeamId, model.PermissionImportTeam) {
+		c.SetPermissionError(model.PermissionImportTeam)
+		return "", 0, nil
+	}
+
+	if err := r.ParseMultipartForm(MaximumBulkImportSize); err != nil {
+		c.Err = model.NewAppError(where, "api.team.import_team.parse.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+		return "", 0, nil
+	}
+
+	importFromArray, ok := r.MultipartForm.Value["importFrom"]
+	if !ok || len(importFromArray) < 1 {
+		c.Err = model.NewAppError(where, "api.team.import_team.no_import_from.app_error", nil, "", http.StatusBadRequest)
+		return "", 0, nil
+	}
+
+	fileSizeStr, ok := r.MultipartForm.Value["filesize"]
+	if !ok || len(fileSizeStr) < 1 {
+		c.Err = model.NewAppError(where, "api.team.import_team.unavailable.app_error", nil, "", http.StatusBadRequest)
+		return "", 0, nil
+	}
+
+	fileSize, err := strconv.ParseInt(fileSizeStr[0], 10, 64)
+	if err != nil {
+		c.Err = model.NewAppError(where, "api.team.import_team.integer.app_error", nil, "", http.StatusBadRequest)
+		return "", 0, nil
+	}
+
+	fileInfoArray, ok := r.MultipartForm.File["file"]
+	if !ok {
+		c.Err = model.NewAppError(where, "api.team.import_team.no_file.app_error", nil, "", http.StatusBadRequest)
+		return "", 0, nil
+	}
+
+	if len(fileInfoArray) <= 0 {
+		c.Err = model.NewAppError(where, "api.team.import_team.array.app_error", nil, "", http.StatusBadRequest)
+		return "", 0, nil
+	}
+
+	return importFromArray[0], fileSize, fileInfoArray[0]
+}
+
 func inviteUsersToTeam(c *Context, w http.ResponseWriter, r *http.Request) {
 	graceful := r.URL.Query().Get("graceful") != ""
 
@@ -10,6 +10,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"testing"
@@ -3302,6 +3303,41 @@ func TestImportTeam(t *testing.T) {
 		require.Equal(t, posts.Posts[posts.Order[3]].Message, "This is a test post to test the import process", "missing posts in the import process")
 	})
 
+	t.Run("ImportTeamJob", func(t *testing.T) {
+		data, err := testutils.ReadTestFile("Fake_Team_Import.zip")
+		require.False(t, err != nil && len(data) == 0, "Error while reading the test file.")
+
+		_, resp, err := th.SystemAdminClient.ImportTeamJob(context.Background(), data, binary.Size(data), "XYZ", "Fake_Team_Import.zip", th.BasicTeam.Id)
+		require.Error(t, err)
+		CheckBadRequestStatus(t, resp)
+
+		_, resp, err = th.Client.ImportTeamJob(context.Background(), data, binary.Size(data), "slack", "Fake_Team_Import.zip", th.BasicTeam.Id)
+		require.Error(t, err)
+		CheckForbiddenStatus(t, resp)
+
+		// The import is handed over to a job
+		job, resp, err := th.SystemAdminClient.ImportTeamJob(context.Background(), data, binary.Size(data), "slack", "Fake_Team_Import.zip", th.BasicTeam.Id)
+		require.NoError(t, err)
+		CheckCreatedStatus(t, resp)
+		require.Equal(t, model.JobTypeSlackImport, job.Type)
+		require.Equal(t, th.BasicTeam.Id, job.Data["team_id"])
+		require.True(t, strings.HasSuffix(job.Data["import_file"], "_Fake_Team_Import.zip"), "unexpected import file: %s", job.Data["import_file"])
+
+		// The upload is kept in the import directory for the job
+		importPath := filepath.Join(*th.App.Config().ImportSettings.Directory, job.Data["import_file"])
+		exists, appErr := th.App.FileExists(importPath)
+		require.Nil(t, appErr)
+		require.True(t, exists, "the upload should be stored in the import directory")
+		defer func() {
+			appErr = th.App.RemoveFile(importPath)
+			require.Nil(t, appErr)
+		}()
+
+		storedJob, appErr := th.App.GetJob(th.Context, job.Id)
+		require.Nil(t, appErr)
+		require.Equal(t, model.JobStatusPending, storedJob.Status)
+	})
+
 	t.Run("Cloud Forbidden", func(t *testing.T) {
 		var data []byte
 		var err error
@@ -61,9 +61,15 @@ func (api *API) InitUser() {
 
 	api.BaseRoutes.User.Handle("/mfa", api.APISessionRequiredMfa(updateUserMfa)).Methods(http.MethodPut)
 	api.BaseRoutes.User.Handle("/mfa/generate", api.APISessionRequiredMfa(generateMfaSecret)).Methods(http.MethodPost)
+	api.BaseRoutes.User.Handle("/mfa/recovery_codes", api.APISessionRequiredMfa(regenerateMfaRecoveryCodes)).Methods(http.MethodPost)
+	api.BaseRoutes.User.Handle("/mfa/webauthn/register/begin", api.APISessionRequiredMfa(beginWebAuthnRegistration)).Methods(http.MethodPost)
+	api.BaseRoutes.User.Handle("/mfa/webauthn/register/finish", api.APISessionRequiredMfa(finishWebAuthnRegistration)).Methods(http.MethodPost)
+	api.BaseRoutes.User.Handle("/mfa/webauthn/credentials", api.APISessionRequired(getWebAuthnCredentials)).Methods(http.MethodGet)
+	api.BaseRoutes.User.Handle("/mfa/webauthn/credentials/{credential_id:[A-Za-z0-9]+}", api.APISessionRequired(deleteWebAuthnCredential)).Methods(http.MethodDelete)
 
 	api.BaseRoutes.Users.Handle("/login", api.APIHandler(login)).Methods(http.MethodPost)
 	api.BaseRoutes.Users.Handle("/login/desktop_token", api.RateLimitedHandler(api.APIHandler(loginWithDesktopToken), model.RateLimitSettings{PerSec: model.NewPointer(2), MaxBurst: model.NewPointer(1)})).Methods(http.MethodPost)
+	api.BaseRoutes.Users.Handle("/login/webauthn/begin", api.RateLimitedHandler(api.APIHandler(beginWebAuthnLogin), model.RateLimitSettings{PerSec: model.NewPointer(2), MaxBurst: model.NewPointer(1)})).Methods(http.MethodPost)
 	api.BaseRoutes.Users.Handle("/login/switch", api.APIHandler(switchAccountType)).Methods(http.MethodPost)
 	api.BaseRoutes.Users.Handle("/login/cws", api.APIHandlerTrustRequester(loginCWS)).Methods(http.MethodPost)
 	api.BaseRoutes.Users.Handle("/logout", api.APIHandler(logout)).Methods(http.MethodPost)
@@ -1672,7 +1678,8 @@ func updateUserMfa(c *Context, w http.ResponseWriter, r *http.Request) {
 
 	c.LogAudit("attempt")
 
-	if err := c.App.UpdateMfa(c.AppContext, activate, c.Params.UserId, code); err != nil {
+	codes, err := c.App.UpdateMfa(c.AppContext, activate, c.Params.UserId, code)
+	if err != nil {
 		c.Err = err
 		return
 	}
@@ -1681,7 +1688,20 @@ func updateUserMfa(c *Context, w http.ResponseWriter, r *http.Request) {
 	auditRec.AddMeta("activate", activate)
 	c.LogAudit("success - mfa updated")
 
-	ReturnStatusOK(w)
+	if codes == nil {
+		ReturnStatusOK(w)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Pragma", "no-cache")
+	w.Header().Set("Expires", "0")
+	if err := json.NewEncoder(w).Encode(map[string]any{
+		"status":         model.StatusOk,
+		"recovery_codes": codes.RecoveryCodes,
+	}); err != nil {
+		c.Logger.Warn("Error while writing response", mlog.Err(err))
+	}
 }
 
 func generateMfaSecret(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -1715,6 +1735,183 @@ func generateMfaSecret(c *Context, w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func regenerateMfaRecoveryCodes(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	auditRec := c.MakeAuditRecord("regenerateMfaRecoveryCodes", audit.Fail)
+	defer c.LogAuditRec(auditRec)
+	audit.AddEventParameter(auditRec, "user_id", c.Params.UserId)
+
+	if c.AppContext.Session().IsOAuth {
+		c.SetPermissionError(model.PermissionEditOtherUsers)
+		c.Err.DetailedError += ", attempted access by oauth app"
+		return
+	}
+
+	// Recovery codes are a credential, so only their owner gets to see them.
+	if c.Params.UserId != c.AppContext.Session().UserId {
+		c.SetPermissionError(model.PermissionEditOtherUsers)
+		return
+	}
+
+	codes, err := c.App.RegenerateMfaRecoveryCodes(c.Params.UserId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	auditRec.Success()
+	c.LogAudit("success - mfa recovery codes regenerated")
+
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Pragma", "no-cache")
+	w.Header().Set("Expires", "0")
+	if err := json.NewEncoder(w).Encode(codes); err != nil {
+		c.Logger.Warn("Error while writing response", mlog.Err(err))
+	}
+}
+
+func beginWebAuthnRegistration(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	if c.AppContext.Session().IsOAuth {
+		c.SetPermissionError(model.PermissionEditOtherUsers)
+		c.Err.DetailedError += ", attempted access by oauth app"
+		return
+	}
+
+	// Security keys are bound to the device of the user registering them.
+	if c.Params.UserId != c.AppContext.Session().UserId {
+		c.SetPermissionError(model.PermissionEditOtherUsers)
+		return
+	}
+
+	creation, err := c.App.BeginWebAuthnRegistration(c.Params.UserId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-cache")
+	if err := json.NewEncoder(w).Encode(creation); err != nil {
+		c.Logger.Warn("Error while writing response", mlog.Err(err))
+	}
+}
+
+func finishWebAuthnRegistration(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	auditRec := c.MakeAuditRecord("finishWebAuthnRegistration", audit.Fail)
+	defer c.LogAuditRec(auditRec)
+
+	if c.AppContext.Session().IsOAuth {
+		c.SetPermissionError(model.PermissionEditOtherUsers)
+		c.Err.DetailedError += ", attempted access by oauth app"
+		return
+	}
+
+	if c.Params.UserId != c.AppContext.Session().UserId {
+		c.SetPermissionError(model.PermissionEditOtherUsers)
+		return
+	}
+
+	var registration model.WebAuthnRegistration
+	if jsonErr := json.NewDecoder(r.Body).Decode(&registration); jsonErr != nil {
+		c.SetInvalidParamWithErr("registration", jsonErr)
+		return
+	}
+
+	if len(registration.Response) == 0 {
+		c.SetInvalidParam("response")
+		return
+	}
+
+	c.LogAudit("attempt")
+
+	credential, err := c.App.FinishWebAuthnRegistration(c.Params.UserId, registration.Name, registration.Response)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	auditRec.Success()
+	auditRec.AddEventResultState(credential)
+	auditRec.AddEventObjectType("webauthn_credential")
+	c.LogAudit("success - webauthn_credential_id=" + credential.Id)
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(credential); err != nil {
+		c.Logger.Warn("Error while writing response", mlog.Err(err))
+	}
+}
+
+func getWebAuthnCredentials(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionToUser(*c.AppContext.Session(), c.Params.UserId) {
+		c.SetPermissionError(model.PermissionEditOtherUsers)
+		return
+	}
+
+	credentials, err := c.App.GetWebAuthnCredentials(c.Params.UserId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(credentials); err != nil {
+		c.Logger.Warn("Error while writing response", mlog.Err(err))
+	}
+}
+
+func deleteWebAuthnCredential(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId().RequireCredentialId()
+	if c.Err != nil {
+		return
+	}
+
+	auditRec := c.MakeAuditRecord("deleteWebAuthnCredential", audit.Fail)
+	defer c.LogAuditRec(auditRec)
+	audit.AddEventParameter(auditRec, "credential_id", c.Params.CredentialId)
+
+	if c.AppContext.Session().IsOAuth {
+		c.SetPermissionError(model.PermissionEditOtherUsers)
+		c.Err.DetailedError += ", attempted access by oauth app"
+		return
+	}
+
+	if !c.App.SessionHasPermissionToUser(*c.AppContext.Session(), c.Params.UserId) {
+		c.SetPermissionError(model.PermissionEditOtherUsers)
+		return
+	}
+
+	if user, err := c.App.GetUser(c.Params.UserId); err == nil {
+		audit.AddEventParameterAuditable(auditRec, "user", user)
+	}
+
+	if err := c.App.DeleteWebAuthnCredential(c.Params.UserId, c.Params.CredentialId); err != nil {
+		c.Err = err
+		return
+	}
+
+	auditRec.Success()
+	c.LogAudit("success - webauthn_credential_id=" + c.Params.CredentialId)
+
+	ReturnStatusOK(w)
+}
+
 func updatePassword(c *Context, w http.ResponseWriter, r *http.Request) {
 	c.RequireUserId()
 	if c.Err != nil {
@@ -2420,6 +2617,45 @@ func sendVerificationEmail(c *Context, w http.ResponseWriter, r *http.Request) {
 	ReturnStatusOK(w)
 }
 
+func beginWebAuthnLogin(c *Context, w http.ResponseWriter, r *http.Request) {
+	// Only disclose whether security keys can be used once the password has been verified
+	defer func() {
+		if c.Err == nil {
+			return
+		}
+
+		switch c.Err.Id {
+		case "mfa.mfa_disabled.app_error",
+			"mfa.webauthn.disabled.app_error",
+			"mfa.webauthn.no_credentials.app_error",
+			"api.user.login.blank_pwd.app_error",
+			"api.user.check_user_login_attempts.too_many.app_error":
+			return
+		}
+
+		c.Err = model.NewAppError("beginWebAuthnLogin", "api.user.login.invalid_credentials_email_username", nil, "", http.StatusUnauthorized)
+	}()
+
+	props := model.MapFromJSON(r.Body)
+	id := props["id"]
+	loginId := props["login_id"]
+	password := props["password"]
+
+	c.LogAuditWithUserId(id, "attempt - webauthn login_id="+loginId)
+
+	assertion, err := c.App.BeginWebAuthnLogin(c.AppContext, id, loginId, password)
+	if err != nil {
+		c.LogAuditWithUserId(id, "failure - webauthn login_id="+loginId)
+		c.Err = err
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-cache")
+	if err := json.NewEncoder(w).Encode(assertion); err != nil {
+		c.Logger.Warn("Error while writing response", mlog.Err(err))
+	}
+}
+
 func switchAccountType(c *Context, w http.ResponseWriter, r *http.Request) {
 	var switchRequest model.SwitchRequest
 	if jsonErr := json.NewDecoder(r.Body).Decode(&switchRequest); jsonErr != nil {
@@ -3563,6 +3563,72 @@ func TestGenerateMfaSecret(t *testing.T) {
 	CheckUnauthorizedStatus(t, resp)
 }
 
+func TestRegenerateMfaRecoveryCodes(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.ServiceSettings.EnableMultifactorAuthentication = false })
+
+	_, resp, err := th.Client.RegenerateMfaRecoveryCodes(context.Background(), th.BasicUser.Id)
+	require.Error(t, err)
+	CheckNotImplementedStatus(t, resp)
+
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.ServiceSettings.EnableMultifactorAuthentication = true })
+
+	_, _, err = th.Client.RegenerateMfaRecoveryCodes(context.Background(), th.BasicUser.Id)
+	CheckErrorID(t, err, "mfa.recovery_codes.mfa_inactive.app_error")
+
+	_, resp, err = th.Client.RegenerateMfaRecoveryCodes(context.Background(), th.BasicUser2.Id)
+	require.Error(t, err)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp, err = th.SystemAdminClient.RegenerateMfaRecoveryCodes(context.Background(), th.BasicUser.Id)
+	require.Error(t, err)
+	CheckForbiddenStatus(t, resp)
+
+	err = th.Server.Store().User().UpdateMfaActive(th.BasicUser.Id, true)
+	require.NoError(t, err)
+	th.App.InvalidateCacheForUser(th.BasicUser.Id)
+
+	old, _, err := th.Client.RegenerateMfaRecoveryCodes(context.Background(), th.BasicUser.Id)
+	require.NoError(t, err)
+	require.Len(t, old.RecoveryCodes, 10)
+
+	codes, _, err := th.Client.RegenerateMfaRecoveryCodes(context.Background(), th.BasicUser.Id)
+	require.NoError(t, err)
+	require.Len(t, codes.RecoveryCodes, 10)
+
+	client := th.CreateClient()
+
+	t.Run("previous codes are invalidated", func(t *testing.T) {
+		_, _, err := client.LoginWithMFA(context.Background(), th.BasicUser.Email, th.BasicUser.Password, old.RecoveryCodes[0])
+		CheckErrorID(t, err, "api.user.check_user_mfa.bad_code.app_error")
+	})
+
+	t.Run("login with a recovery code", func(t *testing.T) {
+		user, _, err := client.LoginWithMFA(context.Background(), th.BasicUser.Email, th.BasicUser.Password, codes.RecoveryCodes[0])
+		require.NoError(t, err)
+		require.Equal(t, th.BasicUser.Id, user.Id)
+	})
+
+	t.Run("recovery codes are single use", func(t *testing.T) {
+		_, _, err := client.LoginWithMFA(context.Background(), th.BasicUser.Email, th.BasicUser.Password, codes.RecoveryCodes[0])
+		CheckErrorID(t, err, "api.user.check_user_mfa.bad_code.app_error")
+	})
+
+	t.Run("deactivating mfa removes the codes", func(t *testing.T) {
+		appErr := th.App.DeactivateMfa(th.BasicUser.Id)
+		require.Nil(t, appErr)
+
+		err := th.Server.Store().User().UpdateMfaActive(th.BasicUser.Id, true)
+		require.NoError(t, err)
+		th.App.InvalidateCacheForUser(th.BasicUser.Id)
+
+		_, _, err = client.LoginWithMFA(context.Background(), th.BasicUser.Email, th.BasicUser.Password, codes.RecoveryCodes[1])
+		CheckErrorID(t, err, "api.user.check_user_mfa.bad_code.app_error")
+	})
+}
+
 func TestUpdateUserPassword(t *testing.T) {
 	th := Setup(t).InitBasic()
 	defer th.TearDown()
@@ -8973,3 +9039,138 @@ func TestSearchUsersWithMfaEnforced(t *testing.T) {
 		CheckForbiddenStatus(t, resp)
 	})
 }
+
+func TestWebAuthnRegistration(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.ServiceSettings.EnableMultifactorAuthentication = true
+		*cfg.ServiceSettings.EnableWebAuthn = false
+		*cfg.ServiceSettings.SiteURL = "http://localhost:8065"
+	})
+
+	_, resp, err := th.Client.BeginWebAuthnRegistration(context.Background(), th.BasicUser.Id)
+	require.Error(t, err)
+	CheckNotImplementedStatus(t, resp)
+
+	th.App.UpdateConfig(func(cfg *model.Config) { *cfg.ServiceSettings.EnableWebAuthn = true })
+
+	t.Run("begin registration for self", func(t *testing.T) {
+		options, _, err := th.Client.BeginWebAuthnRegistration(context.Background(), th.BasicUser.Id)
+		require.NoError(t, err)
+		require.Contains(t, string(options), "challenge")
+	})
+
+	t.Run("cannot register a key for another user", func(t *testing.T) {
+		_, resp, err := th.SystemAdminClient.BeginWebAuthnRegistration(context.Background(), th.BasicUser.Id)
+		require.Error(t, err)
+		CheckForbiddenStatus(t, resp)
+
+		_, resp, err = th.SystemAdminClient.FinishWebAuthnRegistration(context.Background(), th.BasicUser.Id, &model.WebAuthnRegistration{Name: "key", Response: []byte(`{}`)})
+		require.Error(t, err)
+		CheckForbiddenStatus(t, resp)
+	})
+
+	t.Run("invalid response", func(t *testing.T) {
+		_, resp, err := th.Client.FinishWebAuthnRegistration(context.Background(), th.BasicUser.Id, &model.WebAuthnRegistration{Name: "key", Response: []byte(`{"id":"abc"}`)})
+		require.Error(t, err)
+		CheckBadRequestStatus(t, resp)
+		CheckErrorID(t, err, "mfa.webauthn.invalid_response.app_error")
+
+		_, resp, err = th.Client.FinishWebAuthnRegistration(context.Background(), th.BasicUser.Id, &model.WebAuthnRegistration{Name: "key"})
+		require.Error(t, err)
+		CheckBadRequestStatus(t, resp)
+	})
+}
+
+func TestWebAuthnCredentials(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.ServiceSettings.EnableMultifactorAuthentication = true
+		*cfg.ServiceSettings.EnableWebAuthn = true
+	})
+
+	credential, err := th.App.Srv().Store().WebAuthnCredential().Save(&model.WebAuthnCredential{
+		UserId:       th.BasicUser.Id,
+		Name:         "security key",
+		CredentialId: model.NewId(),
+		PublicKey:    model.NewId(),
+	})
+	require.NoError(t, err)
+	err = th.App.Srv().Store().User().UpdateMfaActive(th.BasicUser.Id, true)
+	require.NoError(t, err)
+	th.App.InvalidateCacheForUser(th.BasicUser.Id)
+
+	t.Run("list credentials", func(t *testing.T) {
+		credentials, _, err := th.Client.GetWebAuthnCredentials(context.Background(), th.BasicUser.Id)
+		require.NoError(t, err)
+		require.Len(t, credentials, 1)
+		require.Equal(t, credential.Id, credentials[0].Id)
+		require.Empty(t, credentials[0].PublicKey, "key material should be sanitized")
+
+		_, resp, err := th.Client.GetWebAuthnCredentials(context.Background(), th.BasicUser2.Id)
+		require.Error(t, err)
+		CheckForbiddenStatus(t, resp)
+
+		credentials, _, err = th.SystemAdminClient.GetWebAuthnCredentials(context.Background(), th.BasicUser.Id)
+		require.NoError(t, err)
+		require.Len(t, credentials, 1)
+	})
+
+	t.Run("delete credential", func(t *testing.T) {
+		resp, err := th.Client.DeleteWebAuthnCredential(context.Background(), th.BasicUser2.Id, credential.Id)
+		require.Error(t, err)
+		CheckForbiddenStatus(t, resp)
+
+		resp, err = th.Client.DeleteWebAuthnCredential(context.Background(), th.BasicUser.Id, model.NewId())
+		require.Error(t, err)
+		CheckNotFoundStatus(t, resp)
+
+		_, err = th.Client.DeleteWebAuthnCredential(context.Background(), th.BasicUser.Id, credential.Id)
+		require.NoError(t, err)
+
+		credentials, _, err := th.Client.GetWebAuthnCredentials(context.Background(), th.BasicUser.Id)
+		require.NoError(t, err)
+		require.Empty(t, credentials)
+
+		user, appErr := th.App.GetUser(th.BasicUser.Id)
+		require.Nil(t, appErr)
+		require.False(t, user.MfaActive, "removing the last key should turn off MFA")
+	})
+}
+
+func TestBeginWebAuthnLogin(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.ServiceSettings.EnableMultifactorAuthentication = true
+		*cfg.ServiceSettings.EnableWebAuthn = true
+		*cfg.ServiceSettings.SiteURL = "http://localhost:8065"
+	})
+
+	_, err := th.Client.Logout(context.Background())
+	require.NoError(t, err)
+
+	_, _, err = th.Client.BeginWebAuthnLogin(context.Background(), th.BasicUser.Email, "wrong")
+	CheckErrorID(t, err, "api.user.login.invalid_credentials_email_username")
+
+	_, _, err = th.Client.BeginWebAuthnLogin(context.Background(), th.BasicUser.Email, th.BasicUser.Password)
+	CheckErrorID(t, err, "mfa.webauthn.no_credentials.app_error")
+
+	_, err = th.App.Srv().Store().WebAuthnCredential().Save(&model.WebAuthnCredential{
+		UserId:       th.BasicUser.Id,
+		Name:         "security key",
+		CredentialId: model.NewId(),
+		PublicKey:    "a2V5",
+	})
+	require.NoError(t, err)
+
+	options, _, err := th.Client.BeginWebAuthnLogin(context.Background(), th.BasicUser.Email, th.BasicUser.Password)
+	require.NoError(t, err)
+	require.Contains(t, string(options), "challenge")
+	require.Contains(t, string(options), "allowCredentials")
+}
@@ -14,7 +14,6 @@ import (
 	"github.com/mattermost/mattermost/server/public/shared/i18n"
 	"github.com/mattermost/mattermost/server/public/shared/request"
 	"github.com/mattermost/mattermost/server/v8/platform/services/cache"
-	"github.com/mattermost/mattermost/server/v8/platform/shared/mail"
 )
 
 var latestVersionCache = cache.NewLRU(&cache.CacheOptions{
@@ -196,9 +195,7 @@ func (a *App) TestEmail(rctx request.CTX, userID string, cfg *model.Config) *mod
 	}
 
 	T := i18n.GetUserTranslations(user.Locale)
-	license := a.Srv().License()
-	mailConfig := a.Srv().MailServiceConfig()
-	if err := mail.SendMailUsingConfig(user.Email, T("api.admin.test_email.subject"), T("api.admin.test_email.body"), mailConfig, license != nil && *license.Features.Compliance, "", "", "", "", ""); err != nil {
+	if err := a.Srv().EmailService.SendTestEmail(user.Email, T("api.admin.test_email.subject"), T("api.admin.test_email.body")); err != nil {
 		return model.NewAppError("testEmail", "app.admin.test_email.failure", map[string]any{"Error": err.Error()}, "", http.StatusInternalServerError)
 	}
 
@@ -0,0 +1,86 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/platform/shared/antivirus"
+)
+
+// AntivirusScanner returns the scanner checking the uploaded files, or nil when
+// antivirus scanning is disabled. A registered scanner takes precedence over the
+// built-in ClamAV client.
+func (a *App) AntivirusScanner() antivirus.Scanner {
+	settings := a.Config().FileSettings
+	if !*settings.EnableAntivirusScan {
+		return nil
+	}
+
+	if a.ch.AntivirusScanner != nil {
+		return a.ch.AntivirusScanner
+	}
+
+	return antivirus.NewClamAVScanner(*settings.AntivirusClamdAddress, time.Duration(*settings.AntivirusScanTimeoutSeconds)*time.Second)
+}
+
+// ScanFile scans the content stored for the given FileInfo and returns the name
+// of the detected signature, or an empty string when the content is clean.
+func (a *App) ScanFile(rctx request.CTX, scanner antivirus.Scanner, info *model.FileInfo) (string, *model.AppError) {
+	file, appErr := a.FileReader(info.Path)
+	if appErr != nil {
+		return "", appErr
+	}
+	defer file.Close()
+
+	signature, err := scanner.Scan(rctx.Context(), file)
+	if err != nil {
+		return "", model.NewAppError("ScanFile", "app.file.antivirus_scan.app_error", nil, "", http.StatusServiceUnavailable).Wrap(err)
+	}
+
+	return signature, nil
+}
+
+// scanUploadedFile checks the content of a file being uploaded before its
+// FileInfo is saved. Unless the content is clean, the stored file is removed
+// and an error is returned: the upload fails when the scanner is unavailable,
+// and flagged files are saved in quarantine, so that the system admins, who
+// are notified, know about them.
+func (a *App) scanUploadedFile(rctx request.CTX, info *model.FileInfo) *model.AppError {
+	scanner := a.AntivirusScanner()
+	if scanner == nil {
+		return nil
+	}
+
+	signature, appErr := a.ScanFile(rctx, scanner, info)
+	if appErr != nil {
+		a.RemoveFilesFromFileStore(rctx, []*model.FileInfo{info})
+		return appErr
+	}
+	if signature == "" {
+		return nil
+	}
+
+	rctx.Logger().Warn("Uploaded file flagged by the antivirus scan", mlog.String("file_id", info.Id), mlog.String("path", info.Path), mlog.String("signature", signature))
+	a.RemoveFilesFromFileStore(rctx, []*model.FileInfo{info})
+
+	info.QuarantinedAt = model.GetMillis()
+	info.QuarantineReason = signature
+	if _, err := a.Srv().Store().FileInfo().Save(rctx, info); err != nil {
+		rctx.Logger().Error("Failed to save quarantined file", mlog.String("file_id", info.Id), mlog.Err(err))
+	}
+
+	// The notification outlives the upload request.
+	notifyCtx := request.EmptyContext(rctx.Logger())
+	infoCopy := *info
+	a.Srv().Go(func() {
+		a.NotifyAdminsOfQuarantinedFiles(notifyCtx, []*model.FileInfo{&infoCopy})
+	})
+
+	return model.NewAppError("scanUploadedFile", "app.file.antivirus_scan.infected.app_error", map[string]any{"Filename": info.Name}, "signature="+signature, http.StatusBadRequest)
+}
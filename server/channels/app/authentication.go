@@ -221,6 +221,19 @@ func (a *App) CheckUserMfa(rctx request.CTX, user *model.User, token string) *mo
 		return model.NewAppError("CheckUserMfa", "mfa.mfa_disabled.app_error", nil, "", http.StatusNotImplemented)
 	}
 
+	if mfa.IsWebAuthnAssertion(token) {
+		return a.checkUserWebAuthnAssertion(user, token)
+	}
+
+	if mfa.IsRecoveryCode(token) {
+		return a.checkUserMfaRecoveryCode(rctx, user, token)
+	}
+
+	// Users who only registered security keys have no secret to validate a code against.
+	if user.MfaSecret == "" {
+		return model.NewAppError("checkUserMfa", "api.user.check_user_mfa.bad_code.app_error", nil, "", http.StatusUnauthorized)
+	}
+
 	ok, err := mfa.New(a.Srv().Store().User()).ValidateToken(user, token)
 	if err != nil {
 		return model.NewAppError("CheckUserMfa", "mfa.validate_token.authenticate.app_error", nil, "", http.StatusBadRequest).Wrap(err)
@@ -662,6 +662,16 @@ func (a *App) UpdateChannel(c request.CTX, channel *model.Channel) (*model.Chann
 	messageWs.Add("channel", string(channelJSON))
 	a.Publish(messageWs)
 
+	// The channel is copied as callers may keep modifying it, e.g. to revert a failed update.
+	updatedChannel := channel.DeepCopy()
+	a.Srv().Go(func() {
+		pluginContext := pluginContext(c)
+		a.ch.RunMultiHook(func(hooks plugin.Hooks, _ *model.Manifest) bool {
+			hooks.ChannelHasBeenUpdated(pluginContext, updatedChannel)
+			return true
+		}, plugin.ChannelHasBeenUpdatedID)
+	})
+
 	return channel, nil
 }
 
@@ -707,6 +717,24 @@ func (a *App) UpdateChannelScheme(c request.CTX, channel *model.Channel) (*model
 }
 
 func (a *App) UpdateChannelPrivacy(c request.CTX, oldChannel *model.Channel, user *model.User) (*model.Channel, *model.AppError) {
+	if pluginsEnvironment := a.GetPluginsEnvironment(); pluginsEnvironment != nil {
+		storedChannel, err := a.GetChannel(c, oldChannel.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		var rejectionReason string
+		pluginContext := pluginContext(c)
+		pluginsEnvironment.RunMultiPluginHook(func(hooks plugin.Hooks, _ *model.Manifest) bool {
+			rejectionReason = hooks.ChannelPrivacyWillBeUpdated(pluginContext, storedChannel, oldChannel.Type)
+			return rejectionReason == ""
+		}, plugin.ChannelPrivacyWillBeUpdatedID)
+
+		if rejectionReason != "" {
+			return nil, model.NewAppError("UpdateChannelPrivacy", "Channel privacy update rejected by plugin. "+rejectionReason, nil, "", http.StatusBadRequest)
+		}
+	}
+
 	channel, err := a.UpdateChannel(c, oldChannel)
 	if err != nil {
 		return channel, err
@@ -792,6 +820,15 @@ func (a *App) RestoreChannel(c request.CTX, channel *model.Channel, userID strin
 	message.Add("channel_id", channel.Id)
 	a.Publish(message)
 
+	restoredChannel := channel.DeepCopy()
+	a.Srv().Go(func() {
+		pluginContext := pluginContext(c)
+		a.ch.RunMultiHook(func(hooks plugin.Hooks, _ *model.Manifest) bool {
+			hooks.ChannelHasBeenRestored(pluginContext, restoredChannel)
+			return true
+		}, plugin.ChannelHasBeenRestoredID)
+	})
+
 	var user *model.User
 	if userID != "" {
 		var nErr error
@@ -1458,6 +1495,17 @@ func (a *App) DeleteChannel(c request.CTX, channel *model.Channel, userID string
 		return err
 	}
 
+	var rejectionReason string
+	pluginContext := pluginContext(c)
+	a.ch.RunMultiHook(func(hooks plugin.Hooks, _ *model.Manifest) bool {
+		rejectionReason = hooks.ChannelWillBeArchived(pluginContext, channel)
+		return rejectionReason == ""
+	}, plugin.ChannelWillBeArchivedID)
+
+	if rejectionReason != "" {
+		return model.NewAppError("DeleteChannel", "Channel archiving rejected by plugin. "+rejectionReason, nil, "", http.StatusBadRequest)
+	}
+
 	if user != nil {
 		T := i18n.GetUserTranslations(user.Locale)
 
@@ -1531,6 +1579,15 @@ func (a *App) DeleteChannel(c request.CTX, channel *model.Channel, userID string
 	message.Add("delete_at", deleteAt)
 	a.Publish(message)
 
+	archivedChannel := channel.DeepCopy()
+	archivedChannel.DeleteAt = deleteAt
+	a.Srv().Go(func() {
+		a.ch.RunMultiHook(func(hooks plugin.Hooks, _ *model.Manifest) bool {
+			hooks.ChannelHasBeenArchived(pluginContext, archivedChannel)
+			return true
+		}, plugin.ChannelHasBeenArchivedID)
+	})
+
 	return nil
 }
 
@@ -3133,6 +3190,10 @@ func (a *App) ViewChannel(c request.CTX, view *model.ChannelView, userID string,
 }
 
 func (a *App) PermanentDeleteChannel(c request.CTX, channel *model.Channel) *model.AppError {
+	if appErr := a.checkChannelNotUnderLegalHold(channel.Id); appErr != nil {
+		return appErr
+	}
+
 	if err := a.Srv().Store().Post().PermanentDeleteByChannel(c, channel.Id); err != nil {
 		return model.NewAppError("PermanentDeleteChannel", "app.post.permanent_delete_by_channel.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
 	}
@@ -21,6 +21,7 @@ import (
 	"github.com/mattermost/mattermost/server/v8/config"
 	"github.com/mattermost/mattermost/server/v8/einterfaces"
 	"github.com/mattermost/mattermost/server/v8/platform/services/imageproxy"
+	"github.com/mattermost/mattermost/server/v8/platform/shared/antivirus"
 	"github.com/mattermost/mattermost/server/v8/platform/shared/filestore"
 )
 
@@ -65,6 +66,8 @@ type Channels struct {
 	Notification     einterfaces.NotificationInterface
 	Ldap             einterfaces.LdapInterface
 
+	AntivirusScanner antivirus.Scanner
+
 	// These are used to prevent concurrent upload requests
 	// for a given upload session which could cause inconsistencies
 	// and data corruption.
@@ -119,6 +122,9 @@ func NewChannels(s *Server) (*Channels, error) {
 	if notificationInterface != nil {
 		ch.Notification = notificationInterface(New(ServerConnector(ch)))
 	}
+	if antivirusScannerInterface != nil {
+		ch.AntivirusScanner = antivirusScannerInterface(New(ServerConnector(ch)))
+	}
 	if samlInterface != nil {
 		ch.Saml = samlInterface(New(ServerConnector(ch)))
 		if err := ch.Saml.ConfigureSP(request.EmptyContext(s.Log())); err != nil {
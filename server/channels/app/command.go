@@ -16,6 +16,7 @@ import (
 	"unicode"
 
 	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
 	"github.com/mattermost/mattermost/server/public/shared/i18n"
 	"github.com/mattermost/mattermost/server/public/shared/mlog"
 	"github.com/mattermost/mattermost/server/public/shared/request"
@@ -178,6 +179,52 @@ func (a *App) ListAllCommands(teamID string, T i18n.TranslateFunc) ([]*model.Com
 }
 
 func (a *App) ExecuteCommand(c request.CTX, args *model.CommandArgs) (*model.CommandResponse, *model.AppError) {
+	if appErr := a.runCommandWillBeExecutedHook(c, args); appErr != nil {
+		a.commandHasBeenExecuted(c, args, nil, appErr)
+		return nil, appErr
+	}
+
+	response, appErr := a.executeCommand(c, args)
+	a.commandHasBeenExecuted(c, args, response, appErr)
+
+	return response, appErr
+}
+
+// runCommandWillBeExecutedHook lets plugins reject the command, or rewrite it in place.
+func (a *App) runCommandWillBeExecutedHook(c request.CTX, args *model.CommandArgs) *model.AppError {
+	var rejectionReason string
+	pluginContext := pluginContext(c)
+	a.ch.RunMultiHook(func(hooks plugin.Hooks, _ *model.Manifest) bool {
+		var replacementArgs *model.CommandArgs
+		replacementArgs, rejectionReason = hooks.CommandWillBeExecuted(pluginContext, args)
+		if rejectionReason != "" {
+			return false
+		}
+		if replacementArgs != nil {
+			args.Command = replacementArgs.Command
+		}
+		return true
+	}, plugin.CommandWillBeExecutedID)
+
+	if rejectionReason != "" {
+		return model.NewAppError("ExecuteCommand", "Command rejected by plugin. "+rejectionReason, nil, "", http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (a *App) commandHasBeenExecuted(c request.CTX, args *model.CommandArgs, response *model.CommandResponse, appErr *model.AppError) {
+	argsCopy := *args
+	a.Srv().Go(func() {
+		pluginContext := pluginContext(c)
+		a.ch.RunMultiHook(func(hooks plugin.Hooks, _ *model.Manifest) bool {
+			hooks.CommandHasBeenExecuted(pluginContext, &argsCopy, response, appErr)
+			return true
+		}, plugin.CommandHasBeenExecutedID)
+	})
+}
+
+func (a *App) executeCommand(c request.CTX, args *model.CommandArgs) (*model.CommandResponse, *model.AppError) {
 	trigger := ""
 	message := ""
 	index := strings.IndexFunc(args.Command, unicode.IsSpace)
@@ -7,6 +7,7 @@ import (
 	"net/http"
 
 	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/request"
 	"github.com/mattermost/mattermost/server/v8/channels/store"
 	"github.com/pkg/errors"
 )
@@ -238,3 +239,33 @@ func (a *App) PatchCPAValue(userID string, fieldID string, value string) (*model
 	}
 	return existingValue, nil
 }
+
+// PatchCPAValues sets the user's custom profile attribute values, keyed by field ID, once the
+// plugins had a chance to modify or reject them. It returns the values set, keyed by field ID.
+func (a *App) PatchCPAValues(c request.CTX, userID string, values map[string]string) (map[string]string, *model.AppError) {
+	user, appErr := a.GetUser(userID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if values == nil {
+		values = map[string]string{}
+	}
+	_, values, appErr = a.runUserWillBeUpdatedHook(c, user, user, values)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	results := make(map[string]string, len(values))
+	for fieldID, value := range values {
+		patchedValue, appErr := a.PatchCPAValue(userID, fieldID, value)
+		if appErr != nil {
+			return nil, appErr
+		}
+		results[fieldID] = patchedValue.Value
+	}
+
+	a.userHasBeenUpdated(c, user, user, results)
+
+	return results, nil
+}
@@ -460,3 +460,50 @@ func TestPatchCPAValue(t *testing.T) {
 		require.Nil(t, patchedValue)
 	})
 }
+
+func TestPatchCPAValues(t *testing.T) {
+	os.Setenv("MM_FEATUREFLAGS_CUSTOMPROFILEATTRIBUTES", "true")
+	defer os.Unsetenv("MM_FEATUREFLAGS_CUSTOMPROFILEATTRIBUTES")
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	cpaGroupID, cErr := th.App.cpaGroupID()
+	require.NoError(t, cErr)
+
+	var fieldIDs []string
+	for range 2 {
+		createdField, err := th.App.Srv().propertyService.CreatePropertyField(&model.PropertyField{
+			GroupID: cpaGroupID,
+			Name:    model.NewId(),
+			Type:    model.PropertyFieldTypeText,
+		})
+		require.NoError(t, err)
+		fieldIDs = append(fieldIDs, createdField.ID)
+	}
+
+	t.Run("should set all the values", func(t *testing.T) {
+		results, appErr := th.App.PatchCPAValues(th.Context, th.BasicUser.Id, map[string]string{
+			fieldIDs[0]: "first value",
+			fieldIDs[1]: "second value",
+		})
+		require.Nil(t, appErr)
+		require.Equal(t, map[string]string{
+			fieldIDs[0]: "first value",
+			fieldIDs[1]: "second value",
+		}, results)
+
+		values, appErr := th.App.ListCPAValues(th.BasicUser.Id)
+		require.Nil(t, appErr)
+		require.Len(t, values, 2)
+	})
+
+	t.Run("should fail for an unknown user", func(t *testing.T) {
+		_, appErr := th.App.PatchCPAValues(th.Context, model.NewId(), map[string]string{fieldIDs[0]: "value"})
+		require.NotNil(t, appErr)
+	})
+
+	t.Run("should fail for an unknown field", func(t *testing.T) {
+		_, appErr := th.App.PatchCPAValues(th.Context, th.BasicUser.Id, map[string]string{model.NewId(): "value"})
+		require.NotNil(t, appErr)
+	})
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
 	"github.com/mattermost/mattermost/server/public/shared/i18n"
 	"github.com/mattermost/mattermost/server/public/shared/mlog"
 	"github.com/mattermost/mattermost/server/v8/platform/shared/mail"
@@ -814,44 +815,78 @@ func (es *Service) SendNotificationMail(to, subject, htmlBody string) error {
 	return es.sendMail(to, subject, htmlBody, "NotificationEmail")
 }
 
+// SendSecurityBulletinEmail sends a security bulletin to a system admin.
+func (es *Service) SendSecurityBulletinEmail(to, subject, htmlBody string) error {
+	return es.sendMail(to, subject, htmlBody, "SecurityUpdateCheck")
+}
+
+// SendTestEmail sends the email checking the SMTP settings from the System Console.
+func (es *Service) SendTestEmail(to, subject, htmlBody string) error {
+	return es.sendMail(to, subject, htmlBody, "")
+}
+
 func (es *Service) sendMail(to, subject, htmlBody, category string) error {
 	return es.sendMailWithCC(to, subject, htmlBody, "", category)
 }
 
 func (es *Service) sendEmailWithCustomReplyTo(to, subject, htmlBody, replyToAddress, category string) error {
-	license := es.license()
-	mailConfig := es.mailServiceConfig(replyToAddress)
-
-	category = getSendGridCategory(category, license.IsCloud())
-
-	return mail.SendMailUsingConfig(to, subject, htmlBody, mailConfig, license != nil && *license.Features.Compliance, "", "", "", "", category)
+	email := &model.Email{To: to, Subject: subject, HTMLBody: htmlBody, Category: category}
+	return es.sendEmail(email, nil, replyToAddress, "", "", "")
 }
 
 func (es *Service) sendMailWithCC(to, subject, htmlBody, ccMail, category string) error {
-	license := es.license()
-	mailConfig := es.mailServiceConfig("")
+	email := &model.Email{To: to, CC: ccMail, Subject: subject, HTMLBody: htmlBody, Category: category}
+	return es.sendEmail(email, nil, "", "", "", "")
+}
 
-	category = getSendGridCategory(category, license.IsCloud())
+func (es *Service) SendMailWithEmbeddedFilesAndCustomReplyTo(to, subject, htmlBody, replyToAddress string, embeddedFiles map[string]io.Reader, category string) error {
+	email := &model.Email{To: to, Subject: subject, HTMLBody: htmlBody, Category: category}
+	return es.sendEmail(email, embeddedFiles, replyToAddress, "", "", "")
+}
 
-	return mail.SendMailUsingConfig(to, subject, htmlBody, mailConfig, license != nil && *license.Features.Compliance, "", "", "", ccMail, category)
+func (es *Service) SendMailWithEmbeddedFiles(to, subject, htmlBody string, embeddedFiles map[string]io.Reader, messageID string, inReplyTo string, references string, category string) error {
+	email := &model.Email{To: to, Subject: subject, HTMLBody: htmlBody, Category: category}
+	return es.sendEmail(email, embeddedFiles, "", messageID, inReplyTo, references)
 }
 
-func (es *Service) SendMailWithEmbeddedFilesAndCustomReplyTo(to, subject, htmlBody, replyToAddress string, embeddedFiles map[string]io.Reader, category string) error {
+// sendEmail sends the rendered email, once the plugins implementing the EmailWillBeSent hook
+// had a chance to modify or reject it. A rejected email is not considered an error.
+func (es *Service) sendEmail(email *model.Email, embeddedFiles map[string]io.Reader, replyToAddress, messageID, inReplyTo, references string) error {
+	email.TextBody = mail.HTMLToText(email.HTMLBody)
+
+	email, rejectionReason := es.runEmailWillBeSentHook(email)
+	if rejectionReason != "" {
+		mlog.Info("Email cancelled by plugin.", mlog.String("category", email.Category), mlog.String("rejection reason", rejectionReason))
+		return nil
+	}
+
 	license := es.license()
 	mailConfig := es.mailServiceConfig(replyToAddress)
 
-	category = getSendGridCategory(category, license.IsCloud())
+	category := getSendGridCategory(email.Category, license.IsCloud())
 
-	return mail.SendMailWithEmbeddedFilesUsingConfig(to, subject, htmlBody, embeddedFiles, mailConfig, license != nil && *license.Features.Compliance, "", "", "", "", category)
+	return mail.SendMailWithTextBodyUsingConfig(email.To, email.Subject, email.HTMLBody, email.TextBody, embeddedFiles, mailConfig, messageID, inReplyTo, references, email.CC, category)
 }
 
-func (es *Service) SendMailWithEmbeddedFiles(to, subject, htmlBody string, embeddedFiles map[string]io.Reader, messageID string, inReplyTo string, references string, category string) error {
-	license := es.license()
-	mailConfig := es.mailServiceConfig("")
+func (es *Service) runEmailWillBeSentHook(email *model.Email) (*model.Email, string) {
+	if es.hookRunner == nil {
+		return email, ""
+	}
 
-	category = getSendGridCategory(category, license.IsCloud())
+	rejectionReason := ""
+	es.hookRunner.RunMultiHook(func(hooks plugin.Hooks, _ *model.Manifest) bool {
+		var replacementEmail *model.Email
+		replacementEmail, rejectionReason = hooks.EmailWillBeSent(email)
+		if rejectionReason != "" {
+			return false
+		}
+		if replacementEmail != nil {
+			email = replacementEmail
+		}
+		return true
+	}, plugin.EmailWillBeSentID)
 
-	return mail.SendMailWithEmbeddedFilesUsingConfig(to, subject, htmlBody, embeddedFiles, mailConfig, license != nil && *license.Features.Compliance, messageID, inReplyTo, references, "", category)
+	return email, rejectionReason
 }
 
 func (es *Service) InvalidateVerifyEmailTokensForUser(userID string) *model.AppError {
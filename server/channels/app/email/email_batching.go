@@ -11,19 +11,16 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
+	"github.com/pkg/errors"
+
 	"github.com/mattermost/mattermost/server/public/model"
 	"github.com/mattermost/mattermost/server/public/shared/i18n"
 	"github.com/mattermost/mattermost/server/public/shared/mlog"
 	"github.com/mattermost/mattermost/server/v8/channels/utils"
 )
 
-const (
-	EmailBatchingTaskName = "Email Batching"
-)
-
 type postData struct {
 	SenderName               string
 	ChannelName              string
@@ -37,125 +34,115 @@ type postData struct {
 	MessageAttachments       []*EmailMessageAttachment
 }
 
-func (es *Service) InitEmailBatching() {
-	if *es.config().EmailSettings.EnableEmailBatching {
-		if es.EmailBatching == nil {
-			es.EmailBatching = NewEmailBatchingJob(es, *es.config().EmailSettings.EmailBatchingBufferSize)
-		}
-
-		// note that we don't support changing EmailBatchingBufferSize without restarting the server
-
-		es.EmailBatching.Start()
-	}
-}
-
 func (es *Service) AddNotificationEmailToBatch(user *model.User, post *model.Post, team *model.Team) *model.AppError {
 	if !*es.config().EmailSettings.EnableEmailBatching {
 		return model.NewAppError("AddNotificationEmailToBatch", "api.email_batching.add_notification_email_to_batch.disabled.app_error", nil, "", http.StatusNotImplemented)
 	}
 
-	if !es.EmailBatching.Add(user, post, team) {
-		mlog.Error("Email batching job's receiving buffer was full. Please increase the EmailBatchingBufferSize. Falling back to sending immediate mail.")
-		return model.NewAppError("AddNotificationEmailToBatch", "api.email_batching.add_notification_email_to_batch.channel_full.app_error", nil, "", http.StatusInternalServerError)
+	if err := es.EmailBatching.Add(user, post, team); err != nil {
+		mlog.Error("Unable to save the batched email notification. Falling back to sending immediate mail.", mlog.Err(err))
+		return model.NewAppError("AddNotificationEmailToBatch", "api.email_batching.add_notification_email_to_batch.save.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
 	}
 
 	return nil
 }
 
+// SendBatchedEmailNotifications sends the batched notifications of the users whose email interval has elapsed.
+// It's run by the email batching job, so by a single node of the cluster at a time.
+func (es *Service) SendBatchedEmailNotifications() error {
+	return es.EmailBatching.CheckPendingEmails()
+}
+
 type batchedNotification struct {
+	id       string
 	userID   string
 	post     *model.Post
 	teamName string
 }
 
+// EmailBatchingJob sends the notifications batched in the BatchedEmailNotifications table, which
+// survive restarts and are shared by the nodes of the cluster.
 type EmailBatchingJob struct {
 	config  func() *model.Config
 	service *Service
 
-	newNotifications     chan *batchedNotification
 	pendingNotifications map[string][]*batchedNotification
-	task                 *model.ScheduledTask
-	taskMutex            sync.Mutex
 }
 
-func NewEmailBatchingJob(es *Service, bufferSize int) *EmailBatchingJob {
+func NewEmailBatchingJob(es *Service) *EmailBatchingJob {
 	return &EmailBatchingJob{
 		config:               es.config,
 		service:              es,
-		newNotifications:     make(chan *batchedNotification, bufferSize),
 		pendingNotifications: make(map[string][]*batchedNotification),
 	}
 }
 
-func (job *EmailBatchingJob) Start() {
-	mlog.Debug("Email batching job starting. Checking for pending emails periodically.", mlog.Int("interval_in_seconds", *job.config().EmailSettings.EmailBatchingInterval))
-	newTask := model.CreateRecurringTask(EmailBatchingTaskName, job.CheckPendingEmails, time.Duration(*job.config().EmailSettings.EmailBatchingInterval)*time.Second)
-
-	job.taskMutex.Lock()
-	oldTask := job.task
-	job.task = newTask
-	job.taskMutex.Unlock()
-
-	if oldTask != nil {
-		oldTask.Cancel()
-	}
-}
-
-// Stop will cancel the task properly, flushing out any pending notifications.
-// Although this still won't send those notifications which are yet to be sent
-// due to a user's PreferenceNameEmailInterval.
-func (job *EmailBatchingJob) Stop() {
-	job.taskMutex.Lock()
-	if task := job.task; task != nil {
-		task.Cancel()
-	}
-	job.taskMutex.Unlock()
+func (job *EmailBatchingJob) Add(user *model.User, post *model.Post, team *model.Team) error {
+	return job.service.store.BatchedEmailNotification().Save(&model.BatchedEmailNotification{
+		UserId:   user.Id,
+		PostId:   post.Id,
+		TeamName: team.Name,
+	})
 }
 
-func (job *EmailBatchingJob) Add(user *model.User, post *model.Post, team *model.Team) bool {
-	notification := &batchedNotification{
-		userID:   user.Id,
-		post:     post,
-		teamName: team.Name,
-	}
-
-	select {
-	case job.newNotifications <- notification:
-		return true
-	default:
-		// return false if we couldn't queue the email notification so that we can send an immediate email
-		return false
+func (job *EmailBatchingJob) CheckPendingEmails() error {
+	if err := job.loadPendingNotifications(); err != nil {
+		return err
 	}
-}
-
-func (job *EmailBatchingJob) CheckPendingEmails() {
-	job.handleNewNotifications()
 
 	// it's a bit weird to pass the send email function through here, but it makes it so that we can test
 	// without actually sending emails
 	job.checkPendingNotifications(time.Now(), job.service.sendBatchedEmailNotification)
 
 	mlog.Debug("Email batching job ran. Notifications might be still pending.", mlog.Int("number_of_users", len(job.pendingNotifications)))
+	return nil
 }
 
-func (job *EmailBatchingJob) handleNewNotifications() {
-	receiving := true
+// loadPendingNotifications reads the batched notifications from the store, grouped by user.
+func (job *EmailBatchingJob) loadPendingNotifications() error {
+	stored, err := job.service.store.BatchedEmailNotification().GetAll()
+	if err != nil {
+		return errors.Wrap(err, "failed to get the batched email notifications")
+	}
 
-	// read in new notifications to send
-	for receiving {
-		select {
-		case notification := <-job.newNotifications:
-			userID := notification.userID
+	job.pendingNotifications = make(map[string][]*batchedNotification)
+	var deletedPostNotifications []string
+	for _, notification := range stored {
+		if notification.Post == nil {
+			deletedPostNotifications = append(deletedPostNotifications, notification.Id)
+			continue
+		}
 
-			if _, ok := job.pendingNotifications[userID]; !ok {
-				job.pendingNotifications[userID] = []*batchedNotification{notification}
-			} else {
-				job.pendingNotifications[userID] = append(job.pendingNotifications[userID], notification)
-			}
-		default:
-			receiving = false
+		job.pendingNotifications[notification.UserId] = append(job.pendingNotifications[notification.UserId], &batchedNotification{
+			id:       notification.Id,
+			userID:   notification.UserId,
+			post:     notification.Post,
+			teamName: notification.TeamName,
+		})
+	}
+
+	if len(deletedPostNotifications) == 0 {
+		return nil
+	}
+	if err := job.service.store.BatchedEmailNotification().Delete(deletedPostNotifications); err != nil {
+		mlog.Warn("Unable to delete the batched email notifications of deleted posts", mlog.Err(err))
+	}
+
+	return nil
+}
+
+// deleteNotifications removes the sent or discarded notifications of a user.
+func (job *EmailBatchingJob) deleteNotifications(userID string) error {
+	notifications := job.pendingNotifications[userID]
+	delete(job.pendingNotifications, userID)
+
+	ids := make([]string, 0, len(notifications))
+	for _, notification := range notifications {
+		if notification.id != "" {
+			ids = append(ids, notification.id)
 		}
 	}
+	return job.service.store.BatchedEmailNotification().Delete(ids)
 }
 
 func (job *EmailBatchingJob) checkPendingNotifications(now time.Time, handler func(string, []*batchedNotification)) {
@@ -216,7 +203,9 @@ func (job *EmailBatchingJob) checkPendingNotifications(now time.Time, handler fu
 			for _, channelMember := range channelMembers {
 				if channelMember.LastViewedAt >= batchStartTime {
 					mlog.Debug("Deleted notifications for user", mlog.String("user_id", userID))
-					delete(job.pendingNotifications, userID)
+					if err := job.deleteNotifications(userID); err != nil {
+						mlog.Error("Unable to delete the batched email notifications", mlog.String("user_id", userID), mlog.Err(err))
+					}
 					deleted = true
 					break
 				}
@@ -232,8 +221,13 @@ func (job *EmailBatchingJob) checkPendingNotifications(now time.Time, handler fu
 			continue
 		}
 
-		handler(userID, job.pendingNotifications[userID])
-		delete(job.pendingNotifications, userID)
+		// The notifications are deleted before being sent, so that they are never sent twice.
+		notifications = job.pendingNotifications[userID]
+		if err := job.deleteNotifications(userID); err != nil {
+			mlog.Error("Unable to delete the batched email notifications, not sending them", mlog.String("user_id", userID), mlog.Err(err))
+			continue
+		}
+		handler(userID, notifications)
 	}
 }
 
@@ -5,6 +5,7 @@ package email
 
 import (
 	"context"
+	"errors"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -13,72 +14,102 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest/mock"
+	"github.com/mattermost/mattermost/server/v8/channels/store/storetest/mocks"
 )
 
-func TestHandleNewNotifications(t *testing.T) {
+func TestAddNotificationEmailToBatch(t *testing.T) {
+	th := SetupWithStoreMock(t)
+	defer th.TearDown()
+	th.UpdateConfig(func(cfg *model.Config) {
+		*cfg.EmailSettings.EnableEmailBatching = true
+	})
+
+	user := &model.User{Id: model.NewId()}
+	post := &model.Post{Id: model.NewId()}
+	team := &model.Team{Name: "team"}
+
+	batchedStore := &mocks.BatchedEmailNotificationStore{}
+	batchedStore.On("Save", mock.MatchedBy(func(notification *model.BatchedEmailNotification) bool {
+		return notification.UserId == user.Id && notification.PostId == post.Id && notification.TeamName == "team"
+	})).Return(nil).Once()
+	batchedStore.On("Save", mock.Anything).Return(errors.New("failed to save")).Once()
+	th.service.store.(*mocks.Store).On("BatchedEmailNotification").Return(batchedStore)
+
+	require.Nil(t, th.service.AddNotificationEmailToBatch(user, post, team))
+
+	// The notification is sent immediately if it can't be saved.
+	appErr := th.service.AddNotificationEmailToBatch(user, post, team)
+	require.NotNil(t, appErr)
+	assert.Equal(t, "api.email_batching.add_notification_email_to_batch.save.app_error", appErr.Id)
+	batchedStore.AssertExpectations(t)
+}
+
+func TestLoadPendingNotifications(t *testing.T) {
 	th := SetupWithStoreMock(t)
 	defer th.TearDown()
 
 	id1 := model.NewId()
 	id2 := model.NewId()
-	id3 := model.NewId()
-
-	// test queueing of received posts by user
-	job := NewEmailBatchingJob(th.service, 128)
-
-	job.handleNewNotifications()
-
-	require.Empty(t, job.pendingNotifications, "shouldn't have added any pending notifications")
-
-	job.Add(&model.User{Id: id1}, &model.Post{UserId: id1, Message: "test"}, &model.Team{Name: "team"})
-	require.Empty(t, job.pendingNotifications, "shouldn't have added any pending notifications")
-
-	job.handleNewNotifications()
-	require.Len(t, job.pendingNotifications, 1, "should have received posts for 1 user")
-	require.Len(t, job.pendingNotifications[id1], 1, "should have received 1 post for user")
-
-	job.Add(&model.User{Id: id1}, &model.Post{UserId: id1, Message: "test"}, &model.Team{Name: "team"})
-	job.handleNewNotifications()
-	require.Len(t, job.pendingNotifications, 1, "should have received posts for 1 user")
-	require.Len(t, job.pendingNotifications[id1], 2, "should have received 2 posts for user1")
-
-	job.Add(&model.User{Id: id2}, &model.Post{UserId: id1, Message: "test"}, &model.Team{Name: "team"})
-	job.handleNewNotifications()
-	require.Len(t, job.pendingNotifications, 2, "should have received posts for 2 users")
-	require.Len(t, job.pendingNotifications[id1], 2, "should have received 2 posts for user1")
-	require.Len(t, job.pendingNotifications[id2], 1, "should have received 1 post for user2")
-
-	job.Add(&model.User{Id: id2}, &model.Post{UserId: id2, Message: "test"}, &model.Team{Name: "team"})
-	job.Add(&model.User{Id: id1}, &model.Post{UserId: id3, Message: "test"}, &model.Team{Name: "team"})
-	job.Add(&model.User{Id: id3}, &model.Post{UserId: id3, Message: "test"}, &model.Team{Name: "team"})
-	job.Add(&model.User{Id: id2}, &model.Post{UserId: id2, Message: "test"}, &model.Team{Name: "team"})
-	job.handleNewNotifications()
-	require.Len(t, job.pendingNotifications, 3, "should have received posts for 3 users")
-	require.Len(t, job.pendingNotifications[id1], 3, "should have received 3 posts for user1")
-	require.Len(t, job.pendingNotifications[id2], 3, "should have received 3 posts for user2")
-	require.Len(t, job.pendingNotifications[id3], 1, "should have received 1 post for user3")
-
-	// test ordering of received posts
-	job = NewEmailBatchingJob(th.service, 128)
-
-	job.Add(&model.User{Id: id1}, &model.Post{UserId: id1, Message: "test1"}, &model.Team{Name: "team"})
-	job.Add(&model.User{Id: id1}, &model.Post{UserId: id1, Message: "test2"}, &model.Team{Name: "team"})
-	job.Add(&model.User{Id: id2}, &model.Post{UserId: id1, Message: "test3"}, &model.Team{Name: "team"})
-	job.Add(&model.User{Id: id1}, &model.Post{UserId: id1, Message: "test4"}, &model.Team{Name: "team"})
-	job.Add(&model.User{Id: id2}, &model.Post{UserId: id1, Message: "test5"}, &model.Team{Name: "team"})
-	job.handleNewNotifications()
-	assert.Equal(t, job.pendingNotifications[id1][0].post.Message, "test1", "incorrect order of received posts for user1")
-	assert.Equal(t, job.pendingNotifications[id1][1].post.Message, "test2", "incorrect order of received posts for user1")
-	assert.Equal(t, job.pendingNotifications[id1][2].post.Message, "test4", "incorrect order of received posts for user1")
-	assert.Equal(t, job.pendingNotifications[id2][0].post.Message, "test3", "incorrect order of received posts for user2")
-	assert.Equal(t, job.pendingNotifications[id2][1].post.Message, "test5", "incorrect order of received posts for user2")
+
+	post1 := &model.Post{Id: model.NewId(), UserId: id2, Message: "test1"}
+	post2 := &model.Post{Id: model.NewId(), UserId: id2, Message: "test2"}
+	deletedPost := &model.Post{Id: model.NewId(), UserId: id2, DeleteAt: model.GetMillis()}
+	missingPostID := model.NewId()
+	post3 := &model.Post{Id: model.NewId(), UserId: id1, Message: "test3"}
+
+	user1Notifications := []*model.BatchedEmailNotification{
+		{Id: model.NewId(), UserId: id1, PostId: post1.Id, TeamName: "team", Post: post1},
+		{Id: model.NewId(), UserId: id1, PostId: deletedPost.Id, TeamName: "team"},
+		{Id: model.NewId(), UserId: id1, PostId: missingPostID, TeamName: "team"},
+		{Id: model.NewId(), UserId: id1, PostId: post2.Id, TeamName: "other", Post: post2},
+	}
+	user2Notifications := []*model.BatchedEmailNotification{
+		{Id: model.NewId(), UserId: id2, PostId: post3.Id, TeamName: "team", Post: post3},
+	}
+
+	// The pending notifications are read in a single query, along with their post.
+	batchedStore := &mocks.BatchedEmailNotificationStore{}
+	batchedStore.On("GetAll").Return(append(user1Notifications, user2Notifications...), nil).Once()
+	batchedStore.On("Delete", []string{user1Notifications[1].Id, user1Notifications[2].Id}).Return(nil).Once()
+
+	mockStore := th.service.store.(*mocks.Store)
+	mockStore.On("BatchedEmailNotification").Return(batchedStore)
+
+	job := NewEmailBatchingJob(th.service)
+	require.NoError(t, job.loadPendingNotifications())
+
+	require.Len(t, job.pendingNotifications, 2, "should have loaded posts for 2 users")
+	require.Len(t, job.pendingNotifications[id1], 2, "should have skipped the deleted and missing posts")
+	require.Len(t, job.pendingNotifications[id2], 1, "should have loaded 1 post for user2")
+
+	// the posts keep the order of the notifications
+	assert.Equal(t, "test1", job.pendingNotifications[id1][0].post.Message)
+	assert.Equal(t, user1Notifications[0].Id, job.pendingNotifications[id1][0].id)
+	assert.Equal(t, "team", job.pendingNotifications[id1][0].teamName)
+	assert.Equal(t, "test2", job.pendingNotifications[id1][1].post.Message)
+	assert.Equal(t, "other", job.pendingNotifications[id1][1].teamName)
+	assert.Equal(t, "test3", job.pendingNotifications[id2][0].post.Message)
+	batchedStore.AssertExpectations(t)
+
+	t.Run("the notifications loaded before are discarded", func(t *testing.T) {
+		job.pendingNotifications[model.NewId()] = []*batchedNotification{{post: post1}}
+
+		emptyStore := &mocks.BatchedEmailNotificationStore{}
+		emptyStore.On("GetAll").Return([]*model.BatchedEmailNotification{}, nil)
+		mockStore.ExpectedCalls = nil
+		mockStore.On("BatchedEmailNotification").Return(emptyStore)
+
+		require.NoError(t, job.loadPendingNotifications())
+		require.Empty(t, job.pendingNotifications)
+	})
 }
 
 func TestCheckPendingNotifications(t *testing.T) {
 	th := Setup(t).InitBasic()
 	defer th.TearDown()
 
-	job := NewEmailBatchingJob(th.service, 128)
+	job := NewEmailBatchingJob(th.service)
 	job.pendingNotifications[th.BasicUser.Id] = []*batchedNotification{
 		{
 			post: &model.Post{
@@ -197,7 +228,7 @@ func TestCheckPendingNotificationsDefaultInterval(t *testing.T) {
 	th := Setup(t).InitBasic()
 	defer th.TearDown()
 
-	job := NewEmailBatchingJob(th.service, 128)
+	job := NewEmailBatchingJob(th.service)
 
 	// bypasses recent user activity check
 	require.NotNil(t, th.store)
@@ -240,7 +271,7 @@ func TestCheckPendingNotificationsCantParseInterval(t *testing.T) {
 	th := Setup(t).InitBasic()
 	defer th.TearDown()
 
-	job := NewEmailBatchingJob(th.service, 128)
+	job := NewEmailBatchingJob(th.service)
 
 	require.NotNil(t, th.store)
 	require.NotNil(t, th.store.Channel())
@@ -283,3 +314,60 @@ func TestCheckPendingNotificationsCantParseInterval(t *testing.T) {
 
 	require.Nil(t, job.pendingNotifications[th.BasicUser.Id], "should have sent queued post")
 }
+
+func TestEmailBatchingNodeFailover(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+	th.UpdateConfig(func(cfg *model.Config) {
+		*cfg.EmailSettings.EnableEmailBatching = true
+	})
+
+	// bypasses recent user activity check
+	channelMember, err := th.store.Channel().GetMember(context.Background(), th.BasicChannel.Id, th.BasicUser.Id)
+	require.NoError(t, err)
+	channelMember.LastViewedAt = 0
+	_, err = th.store.Channel().UpdateMember(th.Context, channelMember)
+	require.NoError(t, err)
+
+	var posts []*model.Post
+	for _, message := range []string{"post1", "post2"} {
+		post, err := th.store.Post().Save(th.Context, &model.Post{
+			UserId:    th.BasicUser2.Id,
+			ChannelId: th.BasicChannel.Id,
+			Message:   message,
+		})
+		require.NoError(t, err)
+		posts = append(posts, post)
+	}
+
+	// The notifications are batched by the first node, which goes away before sending them.
+	for _, post := range posts {
+		require.Nil(t, th.service.AddNotificationEmailToBatch(th.BasicUser, post, th.BasicTeam))
+	}
+	th.service.EmailBatching = nil
+
+	// Another node runs the next email batching job, and sends them.
+	job := NewEmailBatchingJob(th.service)
+	require.NoError(t, job.loadPendingNotifications())
+	require.Len(t, job.pendingNotifications[th.BasicUser.Id], 2)
+
+	var received []string
+	job.checkPendingNotifications(time.Now().Add(time.Hour), func(userID string, notifications []*batchedNotification) {
+		assert.Equal(t, th.BasicUser.Id, userID)
+		for _, notification := range notifications {
+			received = append(received, notification.post.Message)
+		}
+	})
+	assert.Equal(t, []string{"post1", "post2"}, received)
+
+	stored, err := th.store.BatchedEmailNotification().GetAll()
+	require.NoError(t, err)
+	for _, notification := range stored {
+		assert.NotEqual(t, th.BasicUser.Id, notification.UserId, "should have deleted the sent notifications")
+	}
+
+	// The next job doesn't send the notifications again.
+	job = NewEmailBatchingJob(th.service)
+	require.NoError(t, job.loadPendingNotifications())
+	assert.Empty(t, job.pendingNotifications)
+}
@@ -7,9 +7,13 @@ import (
 	"os"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest/mock"
 	"github.com/mattermost/mattermost/server/v8/platform/shared/mail"
 )
 
@@ -349,3 +353,140 @@ func TestMailServiceConfig(t *testing.T) {
 		require.Equal(t, configuredReplyTo, mailConfig.ReplyToAddress)
 	})
 }
+
+type testHookRunner struct {
+	hooks []*plugintest.Hooks
+}
+
+func (r *testHookRunner) RunMultiHook(hookRunnerFunc func(hooks plugin.Hooks, _ *model.Manifest) bool, hookId int) {
+	for _, hooks := range r.hooks {
+		if !hookRunnerFunc(hooks, nil) {
+			return
+		}
+	}
+}
+
+func TestRunEmailWillBeSentHook(t *testing.T) {
+	newEmail := func() *model.Email {
+		return &model.Email{
+			To:       "user@example.com",
+			Subject:  "Subject",
+			HTMLBody: "<p>Body</p>",
+			TextBody: "Body",
+			Category: "NotificationEmail",
+		}
+	}
+
+	t.Run("without plugins", func(t *testing.T) {
+		es := &Service{}
+
+		email, rejectionReason := es.runEmailWillBeSentHook(newEmail())
+		assert.Empty(t, rejectionReason)
+		assert.Equal(t, newEmail(), email)
+	})
+
+	t.Run("email allowed", func(t *testing.T) {
+		hooks := &plugintest.Hooks{}
+		hooks.On("EmailWillBeSent", newEmail()).Return(nil, "")
+		es := &Service{hookRunner: &testHookRunner{hooks: []*plugintest.Hooks{hooks}}}
+
+		email, rejectionReason := es.runEmailWillBeSentHook(newEmail())
+		assert.Empty(t, rejectionReason)
+		assert.Equal(t, newEmail(), email)
+		hooks.AssertExpectations(t)
+	})
+
+	t.Run("email modified by each plugin", func(t *testing.T) {
+		first := newEmail()
+		first.Subject = "First subject"
+		second := newEmail()
+		second.Subject = "Second subject"
+		second.To = "other@example.com"
+
+		firstHooks := &plugintest.Hooks{}
+		firstHooks.On("EmailWillBeSent", newEmail()).Return(first, "")
+		secondHooks := &plugintest.Hooks{}
+		secondHooks.On("EmailWillBeSent", first).Return(second, "")
+		es := &Service{hookRunner: &testHookRunner{hooks: []*plugintest.Hooks{firstHooks, secondHooks}}}
+
+		email, rejectionReason := es.runEmailWillBeSentHook(newEmail())
+		assert.Empty(t, rejectionReason)
+		assert.Equal(t, second, email)
+		firstHooks.AssertExpectations(t)
+		secondHooks.AssertExpectations(t)
+	})
+
+	t.Run("email rejected", func(t *testing.T) {
+		firstHooks := &plugintest.Hooks{}
+		firstHooks.On("EmailWillBeSent", newEmail()).Return(nil, "rejected")
+		secondHooks := &plugintest.Hooks{}
+		es := &Service{hookRunner: &testHookRunner{hooks: []*plugintest.Hooks{firstHooks, secondHooks}}}
+
+		_, rejectionReason := es.runEmailWillBeSentHook(newEmail())
+		assert.Equal(t, "rejected", rejectionReason)
+		firstHooks.AssertExpectations(t)
+		secondHooks.AssertNotCalled(t, "EmailWillBeSent", mock.Anything)
+	})
+}
+
+func TestSendEmailWithEmailWillBeSentHook(t *testing.T) {
+	th := SetupWithStoreMock(t)
+	defer th.TearDown()
+	th.ConfigureInbucketMail()
+
+	emailTo := "emailwillbesent@example.com"
+	hooks := &plugintest.Hooks{}
+	th.service.hookRunner = &testHookRunner{hooks: []*plugintest.Hooks{hooks}}
+
+	t.Run("sends the modified email", func(t *testing.T) {
+		err := mail.DeleteMailBox(emailTo)
+		require.NoError(t, err, "Failed to delete mailbox")
+
+		hooks.On("EmailWillBeSent", mock.AnythingOfType("*model.Email")).Return(func(email *model.Email) (*model.Email, string) {
+			assert.Equal(t, emailTo, email.To)
+			assert.Equal(t, "Original subject", email.Subject)
+			assert.Equal(t, "Original body", email.TextBody)
+			assert.Equal(t, "NotificationEmail", email.Category)
+
+			email.Subject = "Modified subject"
+			email.TextBody = "Modified plain text body"
+			return email, ""
+		}).Once()
+
+		err = th.service.sendMail(emailTo, "Original subject", "<p>Original body</p>", "NotificationEmail")
+		require.NoError(t, err)
+
+		var resultsMailbox mail.JSONMessageHeaderInbucket
+		err = mail.RetryInbucket(5, func() error {
+			var err error
+			resultsMailbox, err = mail.GetMailBox(emailTo)
+			return err
+		})
+		if err != nil {
+			t.Skipf("No email was received, maybe due load on the server: %v", err)
+		}
+
+		require.Len(t, resultsMailbox, 1)
+		resultsEmail, err := mail.GetMessageFromMailbox(emailTo, resultsMailbox[0].ID)
+		require.NoError(t, err, "Could not get message from mailbox")
+		assert.Equal(t, "Modified subject", resultsEmail.Subject)
+		assert.Contains(t, resultsEmail.Body.Text, "Modified plain text body")
+		assert.Contains(t, resultsEmail.Body.HTML, "<p>Original body</p>")
+	})
+
+	t.Run("does not send a rejected email", func(t *testing.T) {
+		err := mail.DeleteMailBox(emailTo)
+		require.NoError(t, err, "Failed to delete mailbox")
+
+		hooks.On("EmailWillBeSent", mock.AnythingOfType("*model.Email")).Return(nil, "rejected").Once()
+
+		err = th.service.sendMail(emailTo, "Subject", "<p>Body</p>", "NotificationEmail")
+		require.NoError(t, err)
+
+		resultsMailbox, err := mail.GetMailBox(emailTo)
+		if err == nil {
+			assert.Empty(t, resultsMailbox)
+		}
+		hooks.AssertExpectations(t)
+	})
+}
@@ -130,6 +130,7 @@ func setupTestHelper(s store.Store, tb testing.TB) *TestHelper {
 	if err := service.setUpRateLimiters(); err != nil {
 		panic(err)
 	}
+	service.EmailBatching = NewEmailBatchingJob(service)
 
 	return &TestHelper{
 		service:     service,
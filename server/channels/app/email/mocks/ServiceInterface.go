@@ -141,11 +141,6 @@ func (_m *ServiceInterface) GetPerDayEmailRateLimiter() *throttled.GCRARateLimit
 	return r0
 }
 
-// InitEmailBatching provides a mock function with given fields:
-func (_m *ServiceInterface) InitEmailBatching() {
-	_m.Called()
-}
-
 // NewEmailTemplateData provides a mock function with given fields: locale
 func (_m *ServiceInterface) NewEmailTemplateData(locale string) templates.Data {
 	ret := _m.Called(locale)
@@ -164,6 +159,24 @@ func (_m *ServiceInterface) NewEmailTemplateData(locale string) templates.Data {
 	return r0
 }
 
+// SendBatchedEmailNotifications provides a mock function with given fields:
+func (_m *ServiceInterface) SendBatchedEmailNotifications() error {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendBatchedEmailNotifications")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SendChangeUsernameEmail provides a mock function with given fields: newUsername, _a1, locale, siteURL
 func (_m *ServiceInterface) SendChangeUsernameEmail(newUsername string, _a1 string, locale string, siteURL string) error {
 	ret := _m.Called(newUsername, _a1, locale, siteURL)
@@ -474,6 +487,24 @@ func (_m *ServiceInterface) SendRemoveExpiredLicenseEmail(ctaText string, ctaLin
 	return r0
 }
 
+// SendSecurityBulletinEmail provides a mock function with given fields: to, subject, htmlBody
+func (_m *ServiceInterface) SendSecurityBulletinEmail(to string, subject string, htmlBody string) error {
+	ret := _m.Called(to, subject, htmlBody)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendSecurityBulletinEmail")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string) error); ok {
+		r0 = rf(to, subject, htmlBody)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SendSignInChangeEmail provides a mock function with given fields: _a0, method, locale, siteURL
 func (_m *ServiceInterface) SendSignInChangeEmail(_a0 string, method string, locale string, siteURL string) error {
 	ret := _m.Called(_a0, method, locale, siteURL)
@@ -492,6 +523,24 @@ func (_m *ServiceInterface) SendSignInChangeEmail(_a0 string, method string, loc
 	return r0
 }
 
+// SendTestEmail provides a mock function with given fields: to, subject, htmlBody
+func (_m *ServiceInterface) SendTestEmail(to string, subject string, htmlBody string) error {
+	ret := _m.Called(to, subject, htmlBody)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendTestEmail")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string) error); ok {
+		r0 = rf(to, subject, htmlBody)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SendUserAccessTokenAddedEmail provides a mock function with given fields: _a0, locale, siteURL
 func (_m *ServiceInterface) SendUserAccessTokenAddedEmail(_a0 string, locale string, siteURL string) error {
 	ret := _m.Called(_a0, locale, siteURL)
@@ -551,11 +600,6 @@ func (_m *ServiceInterface) SetStore(st store.Store) {
 	_m.Called(st)
 }
 
-// Stop provides a mock function with given fields:
-func (_m *ServiceInterface) Stop() {
-	_m.Called()
-}
-
 // NewServiceInterface creates a new instance of ServiceInterface. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewServiceInterface(t interface {
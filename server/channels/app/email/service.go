@@ -13,8 +13,8 @@ import (
 	"github.com/throttled/throttled/store/memstore"
 
 	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin"
 	"github.com/mattermost/mattermost/server/public/shared/i18n"
-	"github.com/mattermost/mattermost/server/public/shared/mlog"
 	"github.com/mattermost/mattermost/server/v8/channels/app/users"
 	"github.com/mattermost/mattermost/server/v8/channels/store"
 	"github.com/mattermost/mattermost/server/v8/platform/shared/templates"
@@ -41,12 +41,18 @@ func condenseSiteURL(siteURL string) string {
 	return path.Join(parsedSiteURL.Host, parsedSiteURL.Path)
 }
 
+// HookRunner runs the plugin hooks invoked before an email is sent.
+type HookRunner interface {
+	RunMultiHook(hookRunnerFunc func(hooks plugin.Hooks, _ *model.Manifest) bool, hookId int)
+}
+
 type Service struct {
 	config  func() *model.Config
 	license func() *model.License
 
 	userService *users.UserService
 	store       store.Store
+	hookRunner  HookRunner
 
 	templatesContainer      *templates.Container
 	perHourEmailRateLimiter *throttled.GCRARateLimiter
@@ -61,6 +67,8 @@ type ServiceConfig struct {
 	TemplatesContainer *templates.Container
 	UserService        *users.UserService
 	Store              store.Store
+	// HookRunner is optional, the EmailWillBeSent hook is not run without it.
+	HookRunner HookRunner
 }
 
 func NewService(config ServiceConfig) (*Service, error) {
@@ -73,21 +81,15 @@ func NewService(config ServiceConfig) (*Service, error) {
 		license:            config.LicenseFn,
 		store:              config.Store,
 		userService:        config.UserService,
+		hookRunner:         config.HookRunner,
 	}
 	if err := service.setUpRateLimiters(); err != nil {
 		return nil, err
 	}
-	service.InitEmailBatching()
+	service.EmailBatching = NewEmailBatchingJob(service)
 	return service, nil
 }
 
-func (es *Service) Stop() {
-	mlog.Info("Shutting down Email batching service...")
-	if es.EmailBatching != nil {
-		es.EmailBatching.Stop()
-	}
-}
-
 func (c *ServiceConfig) validate() error {
 	if c.ConfigFn == nil || c.Store == nil || c.LicenseFn == nil || c.TemplatesContainer == nil {
 		return errors.New("invalid service config")
@@ -144,18 +146,19 @@ type ServiceInterface interface {
 	SendInviteEmailsToTeamAndChannels(team *model.Team, channels []*model.Channel, senderName string, senderUserId string, senderProfileImage []byte, invites []string, siteURL string, reminderData *model.TeamInviteReminderData, message string, errorWhenNotSent bool, isSystemAdmin bool, isFirstAdmin bool) ([]*model.EmailInviteWithError, error)
 	SendDeactivateAccountEmail(email string, locale, siteURL string) error
 	SendNotificationMail(to, subject, htmlBody string) error
+	SendSecurityBulletinEmail(to, subject, htmlBody string) error
+	SendTestEmail(to, subject, htmlBody string) error
 	SendMailWithEmbeddedFiles(to, subject, htmlBody string, embeddedFiles map[string]io.Reader, messageID string, inReplyTo string, references string, category string) error
 	SendLicenseUpForRenewalEmail(email, name, locale, siteURL, ctaTitle, ctaLink, ctaText string, daysToExpiration int) error
 	SendRemoveExpiredLicenseEmail(ctaText, ctaLink, email, locale, siteURL string) error
 	AddNotificationEmailToBatch(user *model.User, post *model.Post, team *model.Team) *model.AppError
 	GetMessageForNotification(post *model.Post, teamName, siteUrl string, translateFunc i18n.TranslateFunc) string
 	GenerateHyperlinkForChannels(postMessage, teamName, teamURL string) (string, error)
-	InitEmailBatching()
+	SendBatchedEmailNotifications() error
 	SendChangeUsernameEmail(newUsername, email, locale, siteURL string) error
 	CreateVerifyEmailToken(userID string, newEmail string) (*model.Token, error)
 	SendIPFiltersChangedEmail(email string, userWhoChangedFilter *model.User, siteURL, portalURL, locale string, isWorkspaceOwner bool) error
 	SetStore(st store.Store)
-	Stop()
 }
 
 func (es *Service) Store() store.Store {
@@ -6,6 +6,7 @@ package app
 import (
 	"github.com/mattermost/mattermost/server/v8/einterfaces"
 	ejobs "github.com/mattermost/mattermost/server/v8/einterfaces/jobs"
+	"github.com/mattermost/mattermost/server/v8/platform/shared/antivirus"
 )
 
 var accountMigrationInterface func(*App) einterfaces.AccountMigrationInterface
@@ -14,6 +15,12 @@ func RegisterAccountMigrationInterface(f func(*App) einterfaces.AccountMigration
 	accountMigrationInterface = f
 }
 
+var antivirusScannerInterface func(*App) antivirus.Scanner
+
+func RegisterAntivirusScannerInterface(f func(*App) antivirus.Scanner) {
+	antivirusScannerInterface = f
+}
+
 var complianceInterface func(*App) einterfaces.ComplianceInterface
 
 func RegisterComplianceInterface(f func(*App) einterfaces.ComplianceInterface) {
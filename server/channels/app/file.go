@@ -77,6 +77,10 @@ func connectionTestErrorToAppError(connTestErr error) *model.AppError {
 		return model.NewAppError("TestConnection", "api.file.test_connection_s3_auth.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
 	case *filestore.S3FileBackendNoBucketError:
 		return model.NewAppError("TestConnection", "api.file.test_connection_s3_bucket_does_not_exist.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	case *filestore.GCSFileBackendNoBucketError:
+		return model.NewAppError("TestConnection", "api.file.test_connection_gcs_bucket_does_not_exist.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	case *filestore.AzureFileBackendNoContainerError:
+		return model.NewAppError("TestConnection", "api.file.test_connection_azure_container_does_not_exist.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
 	default:
 		return model.NewAppError("TestConnection", "api.file.test_connection.app_error", nil, "", http.StatusInternalServerError).Wrap(connTestErr)
 	}
@@ -829,6 +833,15 @@ func (a *App) UploadFileX(c request.CTX, channelID, name string, input io.Reader
 		t.postprocessImage(file)
 	}
 
+	if aerr = a.scanUploadedFile(c, t.fileinfo); aerr != nil {
+		return nil, aerr
+	}
+
+	duplicatePath, aerr := a.deduplicateFile(t.fileinfo, "")
+	if aerr != nil {
+		return nil, aerr
+	}
+
 	if _, err := t.saveToDatabase(c, t.fileinfo); err != nil {
 		var appErr *model.AppError
 		switch {
@@ -838,6 +851,7 @@ func (a *App) UploadFileX(c request.CTX, channelID, name string, input io.Reader
 			return nil, model.NewAppError("UploadFileX", "app.file_info.save.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
 		}
 	}
+	a.commitDeduplicatedFile(c, t.fileinfo, duplicatePath)
 
 	if *a.Config().FileSettings.ExtractContent && t.ExtractContent {
 		infoCopy := *t.fileinfo
@@ -1089,6 +1103,22 @@ func (a *App) DoUploadFileExpectModification(c request.CTX, now time.Time, rawTe
 		return nil, data, err
 	}
 
+	if err := a.scanUploadedFile(c, info); err != nil {
+		return nil, data, err
+	}
+
+	var duplicatePath string
+	if *a.Config().FileSettings.EnableFileDeduplication {
+		hash, hashErr := filestore.HashContent(bytes.NewReader(data))
+		if hashErr != nil {
+			return nil, data, model.NewAppError("DoUploadFileExpectModification", "app.file.deduplicate.app_error", nil, "", http.StatusInternalServerError).Wrap(hashErr)
+		}
+		var err *model.AppError
+		if duplicatePath, err = a.deduplicateFile(info, hash); err != nil {
+			return nil, data, err
+		}
+	}
+
 	if _, err := a.Srv().Store().FileInfo().Save(c, info); err != nil {
 		var appErr *model.AppError
 		switch {
@@ -1098,6 +1128,7 @@ func (a *App) DoUploadFileExpectModification(c request.CTX, now time.Time, rawTe
 			return nil, data, model.NewAppError("DoUploadFileExpectModification", "app.file_info.save.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
 		}
 	}
+	a.commitDeduplicatedFile(c, info, duplicatePath)
 
 	// The extra boolean extractContent is used to turn off extraction
 	// during the import process. It is unnecessary overhead during the import,
@@ -1336,6 +1367,10 @@ func (a *App) GetFile(rctx request.CTX, fileID string) ([]byte, *model.AppError)
 		return nil, err
 	}
 
+	if info.IsQuarantined() {
+		return nil, model.NewAppError("GetFile", "api.file.get_file.quarantined.app_error", nil, "", http.StatusForbidden)
+	}
+
 	data, err := a.ReadFile(info.Path)
 	if err != nil {
 		return nil, err
@@ -1466,6 +1501,14 @@ func (a *App) SearchFilesInTeamForUser(c request.CTX, terms string, userId strin
 		return model.NewFileInfoList(), nil
 	}
 
+	finalParamsList, appErr := a.runSearchWillBeExecutedHook(c, model.SearchTypeFiles, userId, teamId, finalParamsList)
+	if appErr != nil {
+		return nil, appErr
+	}
+	if len(finalParamsList) == 0 {
+		return model.NewFileInfoList(), nil
+	}
+
 	fileInfoSearchResults, nErr := a.Srv().Store().FileInfo().Search(c, finalParamsList, userId, teamId, page, perPage)
 	if nErr != nil {
 		var appErr *model.AppError
@@ -1477,7 +1520,13 @@ func (a *App) SearchFilesInTeamForUser(c request.CTX, terms string, userId strin
 		}
 	}
 
-	return fileInfoSearchResults, a.filterInaccessibleFiles(fileInfoSearchResults, filterFileOptions{assumeSortedCreatedAt: true})
+	if appErr := a.filterInaccessibleFiles(fileInfoSearchResults, filterFileOptions{assumeSortedCreatedAt: true}); appErr != nil {
+		return fileInfoSearchResults, appErr
+	}
+
+	_, fileInfoSearchResults = a.runSearchResultsWillBeReturnedHook(c, userId, nil, fileInfoSearchResults)
+
+	return fileInfoSearchResults, nil
 }
 
 func (a *App) ExtractContentFromFileInfo(rctx request.CTX, fileInfo *model.FileInfo) error {
@@ -1629,22 +1678,39 @@ func (a *App) PermanentDeleteFilesByPost(rctx request.CTX, postID string) *model
 		return nil
 	}
 
+	// The files covered by a legal hold are kept.
+	fileInfos, appErr := a.filterFilesNotUnderLegalHold(fileInfos)
+	if appErr != nil {
+		return appErr
+	}
+	fileIDs := make([]string, 0, len(fileInfos))
+	for _, info := range fileInfos {
+		fileIDs = append(fileIDs, info.Id)
+	}
+
 	a.RemoveFilesFromFileStore(rctx, fileInfos)
 
-	err = a.Srv().Store().FileInfo().PermanentDeleteForPost(rctx, postID)
+	_, err = a.Srv().Store().FileInfo().PermanentDeleteByIds(rctx, fileIDs)
 	if err != nil {
 		return model.NewAppError("PermanentDeleteFilesByPost", "app.file_info.permanent_delete_for_post.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
 	}
 
+	a.RemoveUnreferencedFileBlobs(rctx, fileInfos)
+
 	a.Srv().Store().FileInfo().InvalidateFileInfosForPostCache(postID, true)
 	a.Srv().Store().FileInfo().InvalidateFileInfosForPostCache(postID, false)
 
 	return nil
 }
 
+// RemoveFilesFromFileStore removes the content, preview and thumbnail of the given
+// files. Deduplicated content is shared with other files and is only removed by
+// RemoveUnreferencedFileBlobs once the FileInfos are deleted.
 func (a *App) RemoveFilesFromFileStore(rctx request.CTX, fileInfos []*model.FileInfo) {
 	for _, info := range fileInfos {
-		a.RemoveFileFromFileStore(rctx, info.Path)
+		if info.ContentHash == "" {
+			a.RemoveFileFromFileStore(rctx, info.Path)
+		}
 		if info.PreviewPath != "" {
 			a.RemoveFileFromFileStore(rctx, info.PreviewPath)
 		}
@@ -1654,6 +1720,73 @@ func (a *App) RemoveFilesFromFileStore(rctx request.CTX, fileInfos []*model.File
 	}
 }
 
+// RemoveUnreferencedFileBlobs removes the deduplicated content of the given files
+// that is no longer referenced by any FileInfo. It must be called after the
+// FileInfos have been permanently deleted.
+func (a *App) RemoveUnreferencedFileBlobs(rctx request.CTX, fileInfos []*model.FileInfo) {
+	checked := make(map[string]bool)
+	for _, info := range fileInfos {
+		if info.ContentHash == "" || checked[info.ContentHash] {
+			continue
+		}
+		checked[info.ContentHash] = true
+
+		err := filestore.RemoveBlob(a.FileBackend(), info.ContentHash, func() (bool, error) {
+			count, err := a.Srv().Store().FileInfo().CountByContentHash(info.ContentHash)
+			return count > 0, err
+		})
+		if err != nil {
+			rctx.Logger().Warn("Error removing unreferenced file blob", mlog.String("content_hash", info.ContentHash), mlog.Err(err))
+		}
+	}
+}
+
+// deduplicateFile stores the content uploaded at info.Path as a blob shared with
+// the identical files and points info to it. The hash of the content is computed
+// from the stored file when not given. It does nothing when file deduplication is
+// disabled.
+//
+// When an identical blob is already stored, the uploaded file is kept until info
+// has been saved, and its path is returned to be passed to commitDeduplicatedFile.
+func (a *App) deduplicateFile(info *model.FileInfo, hash string) (string, *model.AppError) {
+	if !*a.Config().FileSettings.EnableFileDeduplication {
+		return "", nil
+	}
+
+	var err error
+	if hash == "" {
+		if hash, err = filestore.HashFile(a.FileBackend(), info.Path); err != nil {
+			return "", model.NewAppError("deduplicateFile", "app.file.deduplicate.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+		}
+	}
+
+	uploadPath := info.Path
+	blobPath, duplicate, err := filestore.StoreBlob(a.FileBackend(), uploadPath, hash)
+	if err != nil {
+		return "", model.NewAppError("deduplicateFile", "app.file.deduplicate.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
+	info.Path = blobPath
+	info.ContentHash = hash
+	if !duplicate {
+		return "", nil
+	}
+	return uploadPath, nil
+}
+
+// commitDeduplicatedFile removes the uploaded duplicate of a blob once info, which
+// references the blob, has been saved. Should the blob have been removed with its
+// last previous reference in the meantime, the duplicate takes its place.
+func (a *App) commitDeduplicatedFile(rctx request.CTX, info *model.FileInfo, duplicatePath string) {
+	if duplicatePath == "" {
+		return
+	}
+
+	if err := filestore.CommitBlob(a.FileBackend(), duplicatePath, info.ContentHash); err != nil {
+		rctx.Logger().Warn("Error committing deduplicated file", mlog.String("path", duplicatePath), mlog.String("content_hash", info.ContentHash), mlog.Err(err))
+	}
+}
+
 func (a *App) RemoveFileFromFileStore(rctx request.CTX, path string) {
 	res, appErr := a.FileExists(path)
 	if appErr != nil {
@@ -5,13 +5,17 @@ package app
 
 import (
 	"archive/zip"
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"image"
 	"io"
+	"net/http"
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -786,3 +790,157 @@ func TestPermanentDeleteFilesByPost(t *testing.T) {
 		assert.Nil(t, err)
 	})
 }
+
+func TestFileDeduplication(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.FileSettings.EnableFileDeduplication = true
+	})
+
+	createPost := func(t *testing.T, fileID string) *model.Post {
+		post, appErr := th.App.CreatePost(th.Context, &model.Post{
+			Message:   "asd",
+			ChannelId: th.BasicChannel.Id,
+			UserId:    th.BasicUser.Id,
+			FileIds:   []string{fileID},
+		}, th.BasicChannel, model.CreatePostFlags{SetOnline: true})
+		require.Nil(t, appErr)
+		return post
+	}
+
+	data := []byte("deduplicated content " + model.NewId())
+
+	info1, appErr := th.App.DoUploadFile(th.Context, time.Now(), th.BasicTeam.Id, th.BasicChannel.Id, th.BasicUser.Id, "first.txt", data, true)
+	require.Nil(t, appErr)
+	info2, appErr := th.App.UploadFileX(th.Context, th.BasicChannel.Id, "second.txt", bytes.NewReader(data),
+		UploadFileSetTeamId(th.BasicTeam.Id),
+		UploadFileSetUserId(th.BasicUser.Id),
+		UploadFileSetTimestamp(time.Now()),
+	)
+	require.Nil(t, appErr)
+
+	require.NotEmpty(t, info1.ContentHash)
+	assert.Equal(t, info1.ContentHash, info2.ContentHash)
+	assert.Equal(t, info1.Path, info2.Path)
+
+	post1 := createPost(t, info1.Id)
+	post2 := createPost(t, info2.Id)
+
+	appErr = th.App.PermanentDeleteFilesByPost(th.Context, post1.Id)
+	require.Nil(t, appErr)
+
+	// The blob is still referenced by the second file.
+	content, appErr := th.App.GetFile(th.Context, info2.Id)
+	require.Nil(t, appErr)
+	assert.Equal(t, data, content)
+
+	appErr = th.App.PermanentDeleteFilesByPost(th.Context, post2.Id)
+	require.Nil(t, appErr)
+
+	exists, appErr := th.App.FileExists(info2.Path)
+	require.Nil(t, appErr)
+	assert.False(t, exists)
+}
+
+type fakeAntivirusScanner struct {
+	err error
+}
+
+func (s *fakeAntivirusScanner) Scan(_ context.Context, r io.Reader) (string, error) {
+	if s.err != nil {
+		return "", s.err
+	}
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	if bytes.Contains(content, []byte("infected")) {
+		return "Test-Signature", nil
+	}
+	return "", nil
+}
+
+func (s *fakeAntivirusScanner) SignatureVersion(_ context.Context) (string, error) {
+	return "1", nil
+}
+
+func TestAntivirusScan(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	scanner := &fakeAntivirusScanner{}
+	th.App.Channels().AntivirusScanner = scanner
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.FileSettings.EnableAntivirusScan = true
+	})
+
+	t.Run("clean file", func(t *testing.T) {
+		info, appErr := th.App.DoUploadFile(th.Context, time.Now(), th.BasicTeam.Id, th.BasicChannel.Id, th.BasicUser.Id, "clean.txt", []byte("clean content"), true)
+		require.Nil(t, appErr)
+		assert.False(t, info.IsQuarantined())
+
+		content, appErr := th.App.GetFile(th.Context, info.Id)
+		require.Nil(t, appErr)
+		assert.Equal(t, []byte("clean content"), content)
+	})
+
+	t.Run("infected file", func(t *testing.T) {
+		_, appErr := th.App.UploadFileX(th.Context, th.BasicChannel.Id, "infected.txt", strings.NewReader("infected content"),
+			UploadFileSetTeamId(th.BasicTeam.Id),
+			UploadFileSetUserId(th.BasicUser.Id),
+			UploadFileSetTimestamp(time.Now()),
+		)
+		require.NotNil(t, appErr)
+		assert.Equal(t, "app.file.antivirus_scan.infected.app_error", appErr.Id)
+
+		infos, err := th.App.Srv().Store().FileInfo().GetForUser(th.BasicUser.Id)
+		require.NoError(t, err)
+		var quarantined *model.FileInfo
+		for _, info := range infos {
+			if info.Name == "infected.txt" {
+				quarantined = info
+			}
+		}
+		require.NotNil(t, quarantined)
+		assert.True(t, quarantined.IsQuarantined())
+		assert.Equal(t, "Test-Signature", quarantined.QuarantineReason)
+
+		_, appErr = th.App.GetFile(th.Context, quarantined.Id)
+		require.NotNil(t, appErr)
+		assert.Equal(t, http.StatusForbidden, appErr.StatusCode)
+
+		exists, appErr := th.App.FileExists(quarantined.Path)
+		require.Nil(t, appErr)
+		assert.False(t, exists, "flagged content should have been removed")
+	})
+
+	t.Run("scanner unavailable", func(t *testing.T) {
+		scanner.err = errors.New("connection refused")
+		defer func() { scanner.err = nil }()
+
+		_, appErr := th.App.DoUploadFile(th.Context, time.Now(), th.BasicTeam.Id, th.BasicChannel.Id, th.BasicUser.Id, "unscanned.txt", []byte("clean content"), true)
+		require.NotNil(t, appErr)
+		assert.Equal(t, "app.file.antivirus_scan.app_error", appErr.Id)
+
+		files, appErr := th.App.ListDirectoryRecursively("")
+		require.Nil(t, appErr)
+		for _, file := range files {
+			assert.NotEqual(t, "unscanned.txt", filepath.Base(file), "unscanned content should have been removed")
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.FileSettings.EnableAntivirusScan = false
+		})
+		defer th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.FileSettings.EnableAntivirusScan = true
+		})
+
+		info, appErr := th.App.DoUploadFile(th.Context, time.Now(), th.BasicTeam.Id, th.BasicChannel.Id, th.BasicUser.Id, "infected.txt", []byte("infected content"), true)
+		require.Nil(t, appErr)
+		assert.False(t, info.IsQuarantined())
+	})
+}
@@ -1428,7 +1428,7 @@ func (a *App) importReplies(rctx request.CTX, data []imports.ReplyImportData, po
 		fileIDs := a.uploadAttachments(rctx, replyData.Attachments, reply, teamID, extractContent)
 		for _, fileID := range reply.FileIds {
 			if _, ok := fileIDs[fileID]; !ok {
-				a.Srv().Store().FileInfo().PermanentDelete(rctx, fileID)
+				a.permanentDeleteImportedFileInfo(rctx, fileID)
 			}
 		}
 		reply.FileIds = make([]string, 0)
@@ -1872,7 +1872,7 @@ func (a *App) importMultiplePostLines(rctx request.CTX, lines []imports.LineImpo
 		fileIDs := a.uploadAttachments(rctx, line.Post.Attachments, post, team.Id, extractContent)
 		for _, fileID := range post.FileIds {
 			if _, ok := fileIDs[fileID]; !ok {
-				a.Srv().Store().FileInfo().PermanentDelete(rctx, fileID)
+				a.permanentDeleteImportedFileInfo(rctx, fileID)
 			}
 		}
 		post.FileIds = make([]string, 0)
@@ -2008,6 +2008,23 @@ func (a *App) importMultiplePostLines(rctx request.CTX, lines []imports.LineImpo
 	return 0, nil
 }
 
+// permanentDeleteImportedFileInfo deletes an attachment replaced by the import of
+// its post, and releases its deduplicated content.
+func (a *App) permanentDeleteImportedFileInfo(rctx request.CTX, fileID string) {
+	info, err := a.Srv().Store().FileInfo().Get(fileID)
+	if err != nil {
+		rctx.Logger().Warn("Failed to get replaced attachment", mlog.String("file_id", fileID), mlog.Err(err))
+		return
+	}
+
+	if err := a.Srv().Store().FileInfo().PermanentDelete(rctx, fileID); err != nil {
+		rctx.Logger().Warn("Failed to delete replaced attachment", mlog.String("file_id", fileID), mlog.Err(err))
+		return
+	}
+
+	a.RemoveUnreferencedFileBlobs(rctx, []*model.FileInfo{info})
+}
+
 // uploadAttachments imports new attachments and returns current attachments of the post as a map
 func (a *App) uploadAttachments(rctx request.CTX, attachments *[]imports.AttachmentImportData, post *model.Post, teamID string, extractContent bool) map[string]bool {
 	if attachments == nil {
@@ -2392,7 +2409,7 @@ func (a *App) importMultipleDirectPostLines(rctx request.CTX, lines []imports.Li
 		fileIDs := a.uploadAttachments(rctx, line.DirectPost.Attachments, post, "noteam", extractContent)
 		for _, fileID := range post.FileIds {
 			if _, ok := fileIDs[fileID]; !ok {
-				a.Srv().Store().FileInfo().PermanentDelete(rctx, fileID)
+				a.permanentDeleteImportedFileInfo(rctx, fileID)
 			}
 		}
 		post.FileIds = make([]string, 0)
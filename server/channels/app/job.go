@@ -87,7 +87,7 @@ func (a *App) SessionHasPermissionToCreateJob(session model.Session, job *model.
 		return a.SessionHasPermissionTo(session, model.PermissionCreatePostBleveIndexesJob), model.PermissionCreatePostBleveIndexesJob
 	case model.JobTypeDataRetention:
 		return a.SessionHasPermissionTo(session, model.PermissionCreateDataRetentionJob), model.PermissionCreateDataRetentionJob
-	case model.JobTypeMessageExport:
+	case model.JobTypeMessageExport, model.JobTypeLegalHoldExport:
 		return a.SessionHasPermissionTo(session, model.PermissionCreateComplianceExportJob), model.PermissionCreateComplianceExportJob
 	case model.JobTypeElasticsearchPostIndexing:
 		return a.SessionHasPermissionTo(session, model.PermissionCreateElasticsearchPostIndexingJob), model.PermissionCreateElasticsearchPostIndexingJob
@@ -102,10 +102,14 @@ func (a *App) SessionHasPermissionToCreateJob(session model.Session, job *model.
 		model.JobTypeExpiryNotify,
 		model.JobTypeActiveUsers,
 		model.JobTypeImportProcess,
+		model.JobTypeSlackImport,
+		model.JobTypePostgresPostIndexing,
 		model.JobTypeImportDelete,
 		model.JobTypeExportProcess,
 		model.JobTypeExportDelete,
 		model.JobTypeCloud,
+		model.JobTypeFileEncryptionKeyRotation,
+		model.JobTypeAntivirusRescan,
 		model.JobTypeExtractContent:
 		return a.SessionHasPermissionTo(session, model.PermissionManageJobs), model.PermissionManageJobs
 	}
@@ -121,7 +125,7 @@ func (a *App) SessionHasPermissionToManageJob(session model.Session, job *model.
 		permission = model.PermissionManagePostBleveIndexesJob
 	case model.JobTypeDataRetention:
 		permission = model.PermissionManageDataRetentionJob
-	case model.JobTypeMessageExport:
+	case model.JobTypeMessageExport, model.JobTypeLegalHoldExport:
 		permission = model.PermissionManageComplianceExportJob
 	case model.JobTypeElasticsearchPostIndexing:
 		permission = model.PermissionManageElasticsearchPostIndexingJob
@@ -136,10 +140,14 @@ func (a *App) SessionHasPermissionToManageJob(session model.Session, job *model.
 		model.JobTypeExpiryNotify,
 		model.JobTypeActiveUsers,
 		model.JobTypeImportProcess,
+		model.JobTypeSlackImport,
+		model.JobTypePostgresPostIndexing,
 		model.JobTypeImportDelete,
 		model.JobTypeExportProcess,
 		model.JobTypeExportDelete,
 		model.JobTypeCloud,
+		model.JobTypeFileEncryptionKeyRotation,
+		model.JobTypeAntivirusRescan,
 		model.JobTypeExtractContent:
 		permission = model.PermissionManageJobs
 	}
@@ -155,7 +163,7 @@ func (a *App) SessionHasPermissionToReadJob(session model.Session, jobType strin
 	switch jobType {
 	case model.JobTypeDataRetention:
 		return a.SessionHasPermissionTo(session, model.PermissionReadDataRetentionJob), model.PermissionReadDataRetentionJob
-	case model.JobTypeMessageExport:
+	case model.JobTypeMessageExport, model.JobTypeLegalHoldExport:
 		return a.SessionHasPermissionTo(session, model.PermissionReadComplianceExportJob), model.PermissionReadComplianceExportJob
 	case model.JobTypeElasticsearchPostIndexing:
 		return a.SessionHasPermissionTo(session, model.PermissionReadElasticsearchPostIndexingJob), model.PermissionReadElasticsearchPostIndexingJob
@@ -171,11 +179,15 @@ func (a *App) SessionHasPermissionToReadJob(session model.Session, jobType strin
 		model.JobTypeExpiryNotify,
 		model.JobTypeActiveUsers,
 		model.JobTypeImportProcess,
+		model.JobTypeSlackImport,
+		model.JobTypePostgresPostIndexing,
 		model.JobTypeImportDelete,
 		model.JobTypeExportProcess,
 		model.JobTypeExportDelete,
 		model.JobTypeCloud,
 		model.JobTypeMobileSessionMetadata,
+		model.JobTypeFileEncryptionKeyRotation,
+		model.JobTypeAntivirusRescan,
 		model.JobTypeExtractContent:
 		return a.SessionHasPermissionTo(session, model.PermissionReadJobs), model.PermissionReadJobs
 	}
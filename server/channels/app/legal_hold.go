@@ -0,0 +1,203 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+)
+
+// LegalHoldIdJobDataKey is the job data key holding the id of the legal hold
+// exported by a legal hold export job.
+const LegalHoldIdJobDataKey = "legal_hold_id"
+
+func (a *App) CreateLegalHold(rctx request.CTX, hold *model.LegalHold) (*model.LegalHold, *model.AppError) {
+	if len(hold.UserIds) > 0 {
+		users, err := a.Srv().Store().User().GetMany(context.Background(), hold.UserIds)
+		if err != nil {
+			return nil, model.NewAppError("CreateLegalHold", "app.user.get.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+		}
+		if len(users) != len(model.RemoveDuplicateStrings(hold.UserIds)) {
+			return nil, model.NewAppError("CreateLegalHold", "app.legal_hold.create.invalid_user.app_error", nil, "", http.StatusBadRequest)
+		}
+	}
+
+	if len(hold.ChannelIds) > 0 {
+		channels, err := a.Srv().Store().Channel().GetChannelsByIds(hold.ChannelIds, true)
+		if err != nil {
+			return nil, model.NewAppError("CreateLegalHold", "app.channel.get_channels_by_ids.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+		}
+		if len(channels) != len(model.RemoveDuplicateStrings(hold.ChannelIds)) {
+			return nil, model.NewAppError("CreateLegalHold", "app.legal_hold.create.invalid_channel.app_error", nil, "", http.StatusBadRequest)
+		}
+	}
+
+	saved, err := a.Srv().Store().LegalHold().Save(hold)
+	if err != nil {
+		var appErr *model.AppError
+		switch {
+		case errors.As(err, &appErr):
+			return nil, appErr
+		default:
+			return nil, model.NewAppError("CreateLegalHold", "app.legal_hold.save.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+		}
+	}
+
+	rctx.Logger().Info("Created legal hold", mlog.String("legal_hold_id", saved.Id), mlog.Int("users", len(saved.UserIds)), mlog.Int("channels", len(saved.ChannelIds)))
+
+	return saved, nil
+}
+
+func (a *App) GetLegalHold(id string) (*model.LegalHold, *model.AppError) {
+	hold, err := a.Srv().Store().LegalHold().Get(id)
+	if err != nil {
+		var nfErr *store.ErrNotFound
+		switch {
+		case errors.As(err, &nfErr):
+			return nil, model.NewAppError("GetLegalHold", "app.legal_hold.get.not_found.app_error", nil, "", http.StatusNotFound).Wrap(err)
+		default:
+			return nil, model.NewAppError("GetLegalHold", "app.legal_hold.get.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+		}
+	}
+
+	return hold, nil
+}
+
+func (a *App) GetLegalHolds(page, perPage int, includeReleased bool) ([]*model.LegalHold, *model.AppError) {
+	holds, err := a.Srv().Store().LegalHold().GetAll(page*perPage, perPage, includeReleased)
+	if err != nil {
+		return nil, model.NewAppError("GetLegalHolds", "app.legal_hold.get.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
+	return holds, nil
+}
+
+// ReleaseLegalHold releases a hold. The content it covered can be deleted again,
+// unless it is covered by another active hold.
+func (a *App) ReleaseLegalHold(rctx request.CTX, id string) (*model.LegalHold, *model.AppError) {
+	hold, appErr := a.GetLegalHold(id)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if !hold.IsActive() {
+		return nil, model.NewAppError("ReleaseLegalHold", "app.legal_hold.release.already_released.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	releasedAt := model.GetMillis()
+	if err := a.Srv().Store().LegalHold().Release(id, releasedAt); err != nil {
+		return nil, model.NewAppError("ReleaseLegalHold", "app.legal_hold.release.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	hold.ReleasedAt = releasedAt
+	hold.UpdateAt = releasedAt
+
+	rctx.Logger().Info("Released legal hold", mlog.String("legal_hold_id", hold.Id))
+
+	return hold, nil
+}
+
+// ExportLegalHold starts a job exporting the posts covered by the hold through
+// the compliance export pipeline, in the configured export format.
+func (a *App) ExportLegalHold(rctx request.CTX, id string) (*model.Job, *model.AppError) {
+	if a.MessageExport() == nil {
+		return nil, model.NewAppError("ExportLegalHold", "app.legal_hold.export.not_available.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	if _, appErr := a.GetLegalHold(id); appErr != nil {
+		return nil, appErr
+	}
+
+	return a.Srv().Jobs.CreateJob(rctx, model.JobTypeLegalHoldExport, map[string]string{
+		LegalHoldIdJobDataKey: id,
+	})
+}
+
+func (a *App) getActiveLegalHolds() ([]*model.LegalHold, *model.AppError) {
+	holds, err := a.Srv().Store().LegalHold().GetActive()
+	if err != nil {
+		return nil, model.NewAppError("getActiveLegalHolds", "app.legal_hold.get.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
+	return holds, nil
+}
+
+// checkPostNotUnderLegalHold returns an error when the post is covered by an
+// active legal hold, and so must not be permanently deleted.
+func (a *App) checkPostNotUnderLegalHold(post *model.Post) *model.AppError {
+	holds, appErr := a.getActiveLegalHolds()
+	if appErr != nil {
+		return appErr
+	}
+
+	if model.LegalHoldsCover(holds, post.UserId, post.ChannelId, post.CreateAt) {
+		return model.NewAppError("checkPostNotUnderLegalHold", "app.legal_hold.post_held.app_error", nil, "post_id="+post.Id, http.StatusForbidden)
+	}
+
+	return nil
+}
+
+// checkUserNotUnderLegalHold returns an error when the user is part of an
+// active legal hold, and so must not be permanently deleted.
+func (a *App) checkUserNotUnderLegalHold(userID string) *model.AppError {
+	holds, appErr := a.getActiveLegalHolds()
+	if appErr != nil {
+		return appErr
+	}
+
+	for _, hold := range holds {
+		for _, id := range hold.UserIds {
+			if id == userID {
+				return model.NewAppError("checkUserNotUnderLegalHold", "app.legal_hold.user_held.app_error", nil, "user_id="+userID+" legal_hold_id="+hold.Id, http.StatusForbidden)
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkChannelNotUnderLegalHold returns an error when the channel is part of an
+// active legal hold, and so must not be permanently deleted.
+func (a *App) checkChannelNotUnderLegalHold(channelID string) *model.AppError {
+	holds, appErr := a.getActiveLegalHolds()
+	if appErr != nil {
+		return appErr
+	}
+
+	for _, hold := range holds {
+		for _, id := range hold.ChannelIds {
+			if id == channelID {
+				return model.NewAppError("checkChannelNotUnderLegalHold", "app.legal_hold.channel_held.app_error", nil, "channel_id="+channelID+" legal_hold_id="+hold.Id, http.StatusForbidden)
+			}
+		}
+	}
+
+	return nil
+}
+
+// filterFilesNotUnderLegalHold returns the files which are not covered by an
+// active legal hold.
+func (a *App) filterFilesNotUnderLegalHold(infos []*model.FileInfo) ([]*model.FileInfo, *model.AppError) {
+	holds, appErr := a.getActiveLegalHolds()
+	if appErr != nil {
+		return nil, appErr
+	}
+	if len(holds) == 0 {
+		return infos, nil
+	}
+
+	notHeld := make([]*model.FileInfo, 0, len(infos))
+	for _, info := range infos {
+		if !model.LegalHoldsCover(holds, info.CreatorId, info.ChannelId, info.CreateAt) {
+			notHeld = append(notHeld, info)
+		}
+	}
+
+	return notHeld, nil
+}
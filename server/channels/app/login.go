@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/avct/uasurfer"
+	"github.com/go-webauthn/webauthn/protocol"
 
 	"github.com/mattermost/mattermost/server/public/model"
 	"github.com/mattermost/mattermost/server/public/plugin"
@@ -22,6 +23,7 @@ import (
 	"github.com/mattermost/mattermost/server/public/shared/request"
 	"github.com/mattermost/mattermost/server/v8/channels/store"
 	"github.com/mattermost/mattermost/server/v8/channels/utils"
+	"github.com/mattermost/mattermost/server/v8/platform/shared/mfa"
 )
 
 const cwsTokenEnv = "CWS_CLOUD_TOKEN"
@@ -120,6 +122,60 @@ func (a *App) AuthenticateUserForLogin(c request.CTX, id, loginId, password, mfa
 	return user, nil
 }
 
+// BeginWebAuthnLogin checks the first factor of a login and returns the options the
+// client passes to navigator.credentials.get(). The resulting assertion is then sent
+// as the MFA token of a regular login.
+func (a *App) BeginWebAuthnLogin(c request.CTX, id, loginId, password string) (*protocol.CredentialAssertion, *model.AppError) {
+	w, appErr := a.webAuthn()
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if password == "" {
+		return nil, model.NewAppError("BeginWebAuthnLogin", "api.user.login.blank_pwd.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	user, appErr := a.GetUserForLogin(c, id, loginId)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if appErr = a.CheckUserPreflightAuthenticationCriteria(c, user, ""); appErr != nil {
+		return nil, appErr
+	}
+
+	switch user.AuthService {
+	case model.UserAuthServiceLdap:
+		license := a.Srv().License()
+		if !*a.Config().LdapSettings.Enable || a.Ldap() == nil || license == nil || !*license.Features.LDAP || user.AuthData == nil {
+			return nil, model.NewAppError("BeginWebAuthnLogin", "api.user.login_ldap.not_available.app_error", nil, "", http.StatusNotImplemented)
+		}
+
+		if user, appErr = a.Ldap().DoLogin(c, *user.AuthData, password); appErr != nil {
+			appErr.StatusCode = http.StatusUnauthorized
+			return nil, appErr
+		}
+	case "":
+		if appErr = a.DoubleCheckPassword(c, user, password); appErr != nil {
+			return nil, appErr
+		}
+	default:
+		return nil, model.NewAppError("BeginWebAuthnLogin", "api.user.login.use_auth_service.app_error", map[string]any{"AuthService": user.AuthService}, "", http.StatusBadRequest)
+	}
+
+	assertion, err := w.BeginLogin(user)
+	if err != nil {
+		switch {
+		case errors.Is(err, mfa.NoWebAuthnCredentials):
+			return nil, model.NewAppError("BeginWebAuthnLogin", "mfa.webauthn.no_credentials.app_error", nil, "", http.StatusBadRequest)
+		default:
+			return nil, model.NewAppError("BeginWebAuthnLogin", "mfa.webauthn.login.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+		}
+	}
+
+	return assertion, nil
+}
+
 func (a *App) GetUserForLogin(c request.CTX, id, loginId string) (*model.User, *model.AppError) {
 	enableUsername := *a.Config().EmailSettings.EnableSignInWithUsername
 	enableEmail := *a.Config().EmailSettings.EnableSignInWithEmail
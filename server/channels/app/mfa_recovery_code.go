@@ -0,0 +1,59 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"net/http"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/channels/audit"
+	"github.com/mattermost/mattermost/server/v8/platform/shared/mfa"
+)
+
+func (a *App) generateMfaRecoveryCodes(userID string) (*model.MfaRecoveryCodes, *model.AppError) {
+	codes, err := mfa.NewRecoveryCodes(a.Srv().Store().MfaRecoveryCode()).Generate(userID)
+	if err != nil {
+		return nil, model.NewAppError("generateMfaRecoveryCodes", "mfa.recovery_codes.generate.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
+	return &model.MfaRecoveryCodes{RecoveryCodes: codes}, nil
+}
+
+// RegenerateMfaRecoveryCodes issues a new set of recovery codes for the user,
+// invalidating the ones issued previously.
+func (a *App) RegenerateMfaRecoveryCodes(userID string) (*model.MfaRecoveryCodes, *model.AppError) {
+	if !*a.Config().ServiceSettings.EnableMultifactorAuthentication {
+		return nil, model.NewAppError("RegenerateMfaRecoveryCodes", "mfa.mfa_disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	user, appErr := a.GetUser(userID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if !user.MfaActive {
+		return nil, model.NewAppError("RegenerateMfaRecoveryCodes", "mfa.recovery_codes.mfa_inactive.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	return a.generateMfaRecoveryCodes(user.Id)
+}
+
+func (a *App) checkUserMfaRecoveryCode(rctx request.CTX, user *model.User, code string) *model.AppError {
+	auditRec := a.MakeAuditRecord(rctx, "useMfaRecoveryCode", audit.Fail)
+	defer a.LogAuditRec(rctx, auditRec, nil)
+	audit.AddEventParameter(auditRec, "user_id", user.Id)
+
+	ok, err := mfa.NewRecoveryCodes(a.Srv().Store().MfaRecoveryCode()).Validate(user.Id, code)
+	if err != nil {
+		return model.NewAppError("checkUserMfaRecoveryCode", "mfa.validate_token.authenticate.app_error", nil, "", http.StatusBadRequest).Wrap(err)
+	}
+
+	if !ok {
+		return model.NewAppError("checkUserMfaRecoveryCode", "api.user.check_user_mfa.bad_code.app_error", nil, "", http.StatusUnauthorized)
+	}
+
+	auditRec.Success()
+	return nil
+}
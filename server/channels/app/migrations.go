@@ -673,6 +673,32 @@ func (s *Server) doDeleteDmsPreferencesMigration(c request.CTX) error {
 	return nil
 }
 
+func (s *Server) doFileDeduplicationMigration(c request.CTX) error {
+	// Existing files are only deduplicated once deduplication is enabled.
+	if !*s.platform.Config().FileSettings.EnableFileDeduplication {
+		return nil
+	}
+
+	// If the migration is already marked as completed, don't do it again.
+	if _, err := s.Store().System().GetByName(model.MigrationKeyFileDeduplication); err == nil {
+		return nil
+	}
+
+	jobs, err := s.Store().Job().GetAllByTypeAndStatus(c, model.JobTypeFileDeduplicationMigration, model.JobStatusPending)
+	if err != nil {
+		return fmt.Errorf("failed to get jobs by type and status: %w", err)
+	}
+	if len(jobs) > 0 {
+		return nil
+	}
+
+	if _, appErr := s.Jobs.CreateJobOnce(c, model.JobTypeFileDeduplicationMigration, nil); appErr != nil {
+		return fmt.Errorf("failed to start job for deduplicating files: %w", appErr)
+	}
+
+	return nil
+}
+
 func (a *App) DoAppMigrations() {
 	a.Srv().doAppMigrations()
 }
@@ -716,6 +742,7 @@ func (s *Server) doAppMigrations() {
 		{"Delete Empty Drafts Migration", s.doDeleteEmptyDraftsMigration},
 		{"Delete Orphan Drafts Migration", s.doDeleteOrphanDraftsMigration},
 		{"Delete Invalid Dms Preferences Migration", s.doDeleteDmsPreferencesMigration},
+		{"File Deduplication Migration", s.doFileDeduplicationMigration},
 	}
 
 	c := request.EmptyContext(s.Log())
@@ -278,3 +278,59 @@ func (a *App) groupNotifyAdminByPlugin(data []*model.NotifyAdminData) map[string
 	}
 	return myMap
 }
+
+// NotifyAdminsOfQuarantinedFiles sends a direct message from the system bot to
+// every system admin listing the files quarantined by the antivirus scan.
+func (a *App) NotifyAdminsOfQuarantinedFiles(c request.CTX, infos []*model.FileInfo) {
+	if len(infos) == 0 {
+		return
+	}
+
+	sysadmins, appErr := a.GetUsersFromProfiles(&model.UserGetOptions{
+		Page:     0,
+		PerPage:  100,
+		Role:     model.SystemAdminRoleId,
+		Inactive: false,
+	})
+	if appErr != nil {
+		c.Logger().Warn("Error getting system admins", mlog.Err(appErr))
+		return
+	}
+
+	systemBot, appErr := a.GetSystemBot(c)
+	if appErr != nil {
+		c.Logger().Warn("Error getting system bot", mlog.Err(appErr))
+		return
+	}
+
+	for _, admin := range sysadmins {
+		T := i18n.GetUserTranslations(admin.Locale)
+
+		var files strings.Builder
+		for _, info := range infos {
+			files.WriteString(T("app.file.quarantine.admin_notification.file", map[string]any{
+				"Filename":  info.Name,
+				"FileId":    info.Id,
+				"UserId":    info.CreatorId,
+				"Signature": info.QuarantineReason,
+			}))
+			files.WriteString("\n")
+		}
+
+		channel, appErr := a.GetOrCreateDirectChannel(c, systemBot.UserId, admin.Id)
+		if appErr != nil {
+			c.Logger().Warn("Error getting direct channel", mlog.Err(appErr))
+			continue
+		}
+
+		post := &model.Post{
+			Message:   T("app.file.quarantine.admin_notification", map[string]any{"Count": len(infos), "Files": files.String()}),
+			UserId:    systemBot.UserId,
+			ChannelId: channel.Id,
+		}
+
+		if _, appErr = a.CreatePost(c, post, channel, model.CreatePostFlags{SetOnline: true}); appErr != nil {
+			c.Logger().Warn("Error creating post", mlog.Err(appErr))
+		}
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/mattermost/mattermost/server/public/shared/mlog"
 	"github.com/mattermost/mattermost/server/v8/channels/store"
 	"github.com/mattermost/mattermost/server/v8/einterfaces"
+	"github.com/mattermost/mattermost/server/v8/platform/services/cluster"
 )
 
 func (ps *PlatformService) Cluster() einterfaces.ClusterInterface {
@@ -29,7 +30,12 @@ func (ps *PlatformService) NewClusterDiscoveryService() *ClusterDiscoveryService
 }
 
 func (ps *PlatformService) IsLeader() bool {
-	if ps.License() != nil && *ps.Config().ClusterSettings.Enable && ps.clusterIFace != nil {
+	if !*ps.Config().ClusterSettings.Enable || ps.clusterIFace == nil {
+		return true
+	}
+
+	// The built-in cluster implementation doesn't require a license.
+	if _, ok := ps.clusterIFace.(*cluster.Cluster); ok || ps.License() != nil {
 		return ps.clusterIFace.IsLeader()
 	}
 
@@ -17,6 +17,12 @@ func (ps *PlatformService) StartSearchEngine() (string, string) {
 		})
 	}
 
+	if ps.SearchEngine.PostgresEngine != nil && ps.SearchEngine.PostgresEngine.IsEnabled() {
+		if err := ps.SearchEngine.PostgresEngine.Start(); err != nil {
+			ps.Log().Error("Failed to start the Postgres search engine", mlog.Err(err))
+		}
+	}
+
 	configListenerId := ps.AddConfigListener(func(oldConfig *model.Config, newConfig *model.Config) {
 		if ps.SearchEngine == nil {
 			return
@@ -91,4 +97,9 @@ func (ps *PlatformService) StopSearchEngine() {
 			ps.Log().Error("Failed to stop Bleve Engine", mlog.Err(err))
 		}
 	}
+	if ps.SearchEngine != nil && ps.SearchEngine.PostgresEngine != nil && ps.SearchEngine.PostgresEngine.IsActive() {
+		if err := ps.SearchEngine.PostgresEngine.Stop(); err != nil {
+			ps.Log().Error("Failed to stop the Postgres search engine", mlog.Err(err))
+		}
+	}
 }
@@ -29,8 +29,10 @@ import (
 	"github.com/mattermost/mattermost/server/v8/config"
 	"github.com/mattermost/mattermost/server/v8/einterfaces"
 	"github.com/mattermost/mattermost/server/v8/platform/services/cache"
+	"github.com/mattermost/mattermost/server/v8/platform/services/cluster"
 	"github.com/mattermost/mattermost/server/v8/platform/services/searchengine"
 	"github.com/mattermost/mattermost/server/v8/platform/services/searchengine/bleveengine"
+	"github.com/mattermost/mattermost/server/v8/platform/services/searchengine/postgresengine"
 	"github.com/mattermost/mattermost/server/v8/platform/shared/filestore"
 )
 
@@ -202,12 +204,20 @@ func New(sc ServiceConfig, options ...Option) (*PlatformService, error) {
 		return nil, err
 	}
 	searchEngine.RegisterBleveEngine(bleveEngine)
+	searchEngine.RegisterPostgresEngine(postgresengine.NewPostgresEngine(ps.Config(), ps.Log()))
 	ps.SearchEngine = searchEngine
 
 	// Step 4: Init Enterprise
 	// Depends on step 3 (s.SearchEngine must be non-nil)
 	ps.initEnterprise()
 
+	// The Bleve indexes of the nodes are kept in sync through the cluster, if it's enabled.
+	// Depends on step 4 (the cluster must be set)
+	if ps.clusterIFace != nil {
+		bleveEngine.SetCluster(ps.clusterIFace)
+		ps.RegisterClusterMessageHandler(model.ClusterEventBleveIndexSync, bleveEngine.HandleClusterMessage)
+	}
+
 	// Step 5: Init Metrics
 	if metricsInterfaceFn != nil && ps.metricsIFace == nil { // if the metrics interface is set by options, do not override it
 		ps.metricsIFace = metricsInterfaceFn(ps, *ps.configStore.Get().SqlSettings.DriverName, *ps.configStore.Get().SqlSettings.DataSource)
@@ -457,6 +467,8 @@ func (ps *PlatformService) SetLogger(logger *mlog.Logger) {
 func (ps *PlatformService) initEnterprise() {
 	if clusterInterface != nil && ps.clusterIFace == nil {
 		ps.clusterIFace = clusterInterface(ps)
+	} else if ps.clusterIFace == nil && *ps.Config().ClusterSettings.Enable {
+		ps.clusterIFace = cluster.New(ps)
 	}
 
 	if elasticsearchInterface != nil {
@@ -507,6 +519,11 @@ func (ps *PlatformService) Shutdown() error {
 	return nil
 }
 
+// GetStore returns the store of the platform.
+func (ps *PlatformService) GetStore() store.Store {
+	return ps.Store
+}
+
 func (ps *PlatformService) CacheProvider() cache.Provider {
 	return ps.cacheProvider
 }
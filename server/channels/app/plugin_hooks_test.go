@@ -26,6 +26,7 @@ import (
 	"github.com/mattermost/mattermost/server/public/plugin"
 	"github.com/mattermost/mattermost/server/public/plugin/plugintest"
 	"github.com/mattermost/mattermost/server/public/plugin/utils"
+	"github.com/mattermost/mattermost/server/public/shared/i18n"
 	"github.com/mattermost/mattermost/server/public/shared/request"
 	"github.com/mattermost/mattermost/server/v8/einterfaces/mocks"
 )
@@ -2141,3 +2142,542 @@ func TestUserHasJoinedChannel(t *testing.T) {
 		}
 	})
 }
+
+func TestChannelLifecycleHooks(t *testing.T) {
+	pluginCode := `
+		package main
+
+		import (
+			"github.com/mattermost/mattermost/server/public/plugin"
+			"github.com/mattermost/mattermost/server/public/model"
+		)
+
+		type MyPlugin struct {
+			plugin.MattermostPlugin
+		}
+
+		func (p *MyPlugin) ChannelHasBeenUpdated(c *plugin.Context, channel *model.Channel) {
+			p.API.KVSet("updated_"+channel.Id, []byte(channel.DisplayName+" "+string(channel.Type)))
+		}
+
+		func (p *MyPlugin) ChannelWillBeArchived(c *plugin.Context, channel *model.Channel) string {
+			if channel.Header == "keep" {
+				return "channel is kept"
+			}
+			return ""
+		}
+
+		func (p *MyPlugin) ChannelHasBeenArchived(c *plugin.Context, channel *model.Channel) {
+			if channel.DeleteAt > 0 {
+				p.API.KVSet("archived_"+channel.Id, []byte(channel.Name))
+			}
+		}
+
+		func (p *MyPlugin) ChannelHasBeenRestored(c *plugin.Context, channel *model.Channel) {
+			if channel.DeleteAt == 0 {
+				p.API.KVSet("restored_"+channel.Id, []byte(channel.Name))
+			}
+		}
+
+		func (p *MyPlugin) ChannelPrivacyWillBeUpdated(c *plugin.Context, channel *model.Channel, privacy model.ChannelType) string {
+			if channel.Type == model.ChannelTypePrivate && privacy == model.ChannelTypeOpen {
+				return "private channels stay private"
+			}
+			return ""
+		}
+
+		func main() {
+			plugin.ClientMain(&MyPlugin{})
+		}
+	`
+	pluginID := "testplugin"
+	pluginManifest := `{"id": "testplugin", "server": {"executable": "backend.exe"}}`
+
+	requirePluginKey := func(t *testing.T, th *TestHelper, key, expected string) {
+		t.Helper()
+		require.EventuallyWithT(t, func(c *assert.CollectT) {
+			value, appErr := th.App.GetPluginKey(pluginID, key)
+			assert.Nil(c, appErr)
+			assert.Equal(c, expected, string(value))
+		}, 5*time.Second, 100*time.Millisecond)
+	}
+
+	t.Run("should call hook when a channel is updated", func(t *testing.T) {
+		th := Setup(t).InitBasic()
+		defer th.TearDown()
+
+		setupPluginAPITest(t, pluginCode, pluginManifest, pluginID, th.App, th.Context)
+
+		channel := th.CreateChannel(th.Context, th.BasicTeam)
+		_, appErr := th.App.PatchChannel(th.Context, channel, &model.ChannelPatch{DisplayName: model.NewPointer("Renamed")}, th.BasicUser.Id)
+		require.Nil(t, appErr)
+
+		requirePluginKey(t, th, "updated_"+channel.Id, "Renamed O")
+	})
+
+	t.Run("should call hooks when a channel is archived and restored", func(t *testing.T) {
+		th := Setup(t).InitBasic()
+		defer th.TearDown()
+
+		setupPluginAPITest(t, pluginCode, pluginManifest, pluginID, th.App, th.Context)
+
+		channel := th.CreateChannel(th.Context, th.BasicTeam)
+		appErr := th.App.DeleteChannel(th.Context, channel, th.BasicUser.Id)
+		require.Nil(t, appErr)
+		requirePluginKey(t, th, "archived_"+channel.Id, channel.Name)
+
+		channel, appErr = th.App.GetChannel(th.Context, channel.Id)
+		require.Nil(t, appErr)
+		_, appErr = th.App.RestoreChannel(th.Context, channel, th.BasicUser.Id)
+		require.Nil(t, appErr)
+		requirePluginKey(t, th, "restored_"+channel.Id, channel.Name)
+	})
+
+	t.Run("should not archive a channel rejected by the plugin", func(t *testing.T) {
+		th := Setup(t).InitBasic()
+		defer th.TearDown()
+
+		setupPluginAPITest(t, pluginCode, pluginManifest, pluginID, th.App, th.Context)
+
+		channel := th.CreateChannel(th.Context, th.BasicTeam)
+		channel.Header = "keep"
+		channel, appErr := th.App.UpdateChannel(th.Context, channel)
+		require.Nil(t, appErr)
+
+		appErr = th.App.DeleteChannel(th.Context, channel, th.BasicUser.Id)
+		require.NotNil(t, appErr)
+		assert.Equal(t, "Channel archiving rejected by plugin. channel is kept", appErr.Id)
+
+		channel, appErr = th.App.GetChannel(th.Context, channel.Id)
+		require.Nil(t, appErr)
+		assert.Zero(t, channel.DeleteAt)
+	})
+
+	t.Run("should not convert a channel rejected by the plugin", func(t *testing.T) {
+		th := Setup(t).InitBasic()
+		defer th.TearDown()
+
+		setupPluginAPITest(t, pluginCode, pluginManifest, pluginID, th.App, th.Context)
+
+		channel := th.CreatePrivateChannel(th.Context, th.BasicTeam)
+		channel.Type = model.ChannelTypeOpen
+		_, appErr := th.App.UpdateChannelPrivacy(th.Context, channel, th.BasicUser)
+		require.NotNil(t, appErr)
+		assert.Equal(t, "Channel privacy update rejected by plugin. private channels stay private", appErr.Id)
+
+		channel, appErr = th.App.GetChannel(th.Context, channel.Id)
+		require.Nil(t, appErr)
+		assert.Equal(t, model.ChannelTypePrivate, channel.Type)
+
+		openChannel := th.CreateChannel(th.Context, th.BasicTeam)
+		openChannel.Type = model.ChannelTypePrivate
+		_, appErr = th.App.UpdateChannelPrivacy(th.Context, openChannel, th.BasicUser)
+		require.Nil(t, appErr)
+		requirePluginKey(t, th, "updated_"+openChannel.Id, openChannel.DisplayName+" P")
+	})
+}
+
+func TestTeamLifecycleHooks(t *testing.T) {
+	pluginCode := `
+		package main
+
+		import (
+			"strconv"
+
+			"github.com/mattermost/mattermost/server/public/plugin"
+			"github.com/mattermost/mattermost/server/public/model"
+		)
+
+		type MyPlugin struct {
+			plugin.MattermostPlugin
+		}
+
+		func (p *MyPlugin) TeamHasBeenCreated(c *plugin.Context, team *model.Team) {
+			p.API.KVSet("created_"+team.Id, []byte(team.Name))
+		}
+
+		func (p *MyPlugin) TeamHasBeenUpdated(c *plugin.Context, team *model.Team) {
+			p.API.KVSet("updated_"+team.Id, []byte(team.DisplayName))
+		}
+
+		func (p *MyPlugin) TeamHasBeenDeleted(c *plugin.Context, team *model.Team) {
+			p.API.KVSet("deleted_"+team.Id, []byte(strconv.FormatBool(team.DeleteAt > 0)))
+		}
+
+		func main() {
+			plugin.ClientMain(&MyPlugin{})
+		}
+	`
+	pluginID := "testplugin"
+	pluginManifest := `{"id": "testplugin", "server": {"executable": "backend.exe"}}`
+
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	setupPluginAPITest(t, pluginCode, pluginManifest, pluginID, th.App, th.Context)
+
+	requirePluginKey := func(t *testing.T, key, expected string) {
+		t.Helper()
+		require.EventuallyWithT(t, func(c *assert.CollectT) {
+			value, appErr := th.App.GetPluginKey(pluginID, key)
+			assert.Nil(c, appErr)
+			assert.Equal(c, expected, string(value))
+		}, 5*time.Second, 100*time.Millisecond)
+	}
+
+	team := th.CreateTeam()
+	requirePluginKey(t, "created_"+team.Id, team.Name)
+
+	_, appErr := th.App.PatchTeam(team.Id, &model.TeamPatch{DisplayName: model.NewPointer("Patched")})
+	require.Nil(t, appErr)
+	requirePluginKey(t, "updated_"+team.Id, "Patched")
+
+	appErr = th.App.SoftDeleteTeam(team.Id)
+	require.Nil(t, appErr)
+	requirePluginKey(t, "deleted_"+team.Id, "true")
+}
+
+func TestUserWillBeUpdated(t *testing.T) {
+	os.Setenv("MM_FEATUREFLAGS_CUSTOMPROFILEATTRIBUTES", "true")
+	defer os.Unsetenv("MM_FEATUREFLAGS_CUSTOMPROFILEATTRIBUTES")
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	tearDown, _, _ := SetAppEnvironmentWithPlugins(t,
+		[]string{
+			`
+		package main
+
+		import (
+			"strings"
+
+			"github.com/mattermost/mattermost/server/public/plugin"
+			"github.com/mattermost/mattermost/server/public/model"
+		)
+
+		type MyPlugin struct {
+			plugin.MattermostPlugin
+		}
+
+		func (p *MyPlugin) UserWillBeUpdated(c *plugin.Context, newUser, oldUser *model.User, attributes map[string]string) (*model.User, map[string]string, string) {
+			if strings.HasPrefix(newUser.Username, "admin") {
+				return nil, nil, "reserved username"
+			}
+			if attributes != nil {
+				for fieldID, value := range attributes {
+					attributes[fieldID] = strings.ToUpper(value)
+				}
+				return nil, attributes, ""
+			}
+			newUser.Nickname = strings.ToUpper(newUser.Nickname)
+			newUser.Id = model.NewId()
+			return newUser, nil, ""
+		}
+
+		func main() {
+			plugin.ClientMain(&MyPlugin{})
+		}
+	`}, th.App, th.NewPluginAPI)
+	defer tearDown()
+
+	t.Run("rejects the update", func(t *testing.T) {
+		_, appErr := th.App.PatchUser(th.Context, th.BasicUser.Id, &model.UserPatch{Username: model.NewPointer("admin" + model.NewId()[:10])}, true)
+		require.NotNil(t, appErr)
+		assert.Equal(t, "User update rejected by plugin. reserved username", appErr.Id)
+
+		user, appErr := th.App.GetUser(th.BasicUser.Id)
+		require.Nil(t, appErr)
+		assert.Equal(t, th.BasicUser.Username, user.Username)
+	})
+
+	t.Run("modifies the update", func(t *testing.T) {
+		updatedUser, appErr := th.App.PatchUser(th.Context, th.BasicUser.Id, &model.UserPatch{Nickname: model.NewPointer("nick")}, true)
+		require.Nil(t, appErr)
+		assert.Equal(t, th.BasicUser.Id, updatedUser.Id)
+		assert.Equal(t, "NICK", updatedUser.Nickname)
+	})
+
+	t.Run("modifies the custom profile attribute values", func(t *testing.T) {
+		cpaGroupID, err := th.App.cpaGroupID()
+		require.NoError(t, err)
+		field, err := th.App.Srv().propertyService.CreatePropertyField(&model.PropertyField{
+			GroupID: cpaGroupID,
+			Name:    model.NewId(),
+			Type:    model.PropertyFieldTypeText,
+		})
+		require.NoError(t, err)
+
+		results, appErr := th.App.PatchCPAValues(th.Context, th.BasicUser.Id, map[string]string{field.ID: "engineering"})
+		require.Nil(t, appErr)
+		assert.Equal(t, map[string]string{field.ID: "ENGINEERING"}, results)
+	})
+}
+
+func TestUserHasBeenUpdated(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	tearDown, pluginIDs, _ := SetAppEnvironmentWithPlugins(t,
+		[]string{
+			`
+		package main
+
+		import (
+			"github.com/mattermost/mattermost/server/public/plugin"
+			"github.com/mattermost/mattermost/server/public/model"
+		)
+
+		type MyPlugin struct {
+			plugin.MattermostPlugin
+		}
+
+		func (p *MyPlugin) UserHasBeenUpdated(c *plugin.Context, newUser, oldUser *model.User, attributes map[string]string) {
+			p.API.KVSet("updated_"+newUser.Id, []byte(oldUser.Position+" -> "+newUser.Position))
+		}
+
+		func main() {
+			plugin.ClientMain(&MyPlugin{})
+		}
+	`}, th.App, th.NewPluginAPI)
+	defer tearDown()
+
+	_, appErr := th.App.PatchUser(th.Context, th.BasicUser.Id, &model.UserPatch{Position: model.NewPointer("Engineer")}, true)
+	require.Nil(t, appErr)
+
+	require.EventuallyWithT(t, func(c *assert.CollectT) {
+		value, appErr := th.App.GetPluginKey(pluginIDs[0], "updated_"+th.BasicUser.Id)
+		assert.Nil(c, appErr)
+		assert.Equal(c, th.BasicUser.Position+" -> Engineer", string(value))
+	}, 5*time.Second, 100*time.Millisecond)
+}
+
+func TestCommandHooks(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	tearDown, pluginIDs, activationErrors := SetAppEnvironmentWithPlugins(t,
+		[]string{
+			`
+		package main
+
+		import (
+			"strings"
+
+			"github.com/mattermost/mattermost/server/public/plugin"
+			"github.com/mattermost/mattermost/server/public/model"
+		)
+
+		type MyPlugin struct {
+			plugin.MattermostPlugin
+		}
+
+		func (p *MyPlugin) OnActivate() error {
+			return p.API.RegisterCommand(&model.Command{
+				Trigger: "echoargs",
+			})
+		}
+
+		func (p *MyPlugin) ExecuteCommand(c *plugin.Context, args *model.CommandArgs) (*model.CommandResponse, *model.AppError) {
+			return &model.CommandResponse{
+				ResponseType: model.CommandResponseTypeEphemeral,
+				Text:         args.Command,
+			}, nil
+		}
+
+		func (p *MyPlugin) CommandWillBeExecuted(c *plugin.Context, args *model.CommandArgs) (*model.CommandArgs, string) {
+			if strings.HasPrefix(args.Command, "/invite") {
+				return nil, "invites are disabled"
+			}
+			if strings.HasPrefix(args.Command, "/echoargs secret") {
+				args.Command = "/echoargs redacted"
+				args.UserId = model.NewId()
+				return args, ""
+			}
+			return nil, ""
+		}
+
+		func (p *MyPlugin) CommandHasBeenExecuted(c *plugin.Context, args *model.CommandArgs, response *model.CommandResponse, appErr *model.AppError) {
+			if appErr != nil {
+				p.API.KVSet("failed_"+args.UserId, []byte(args.Command+": "+appErr.Id))
+				return
+			}
+			p.API.KVSet("executed_"+args.UserId, []byte(response.Text))
+		}
+
+		func main() {
+			plugin.ClientMain(&MyPlugin{})
+		}
+	`}, th.App, th.NewPluginAPI)
+	defer tearDown()
+	require.Len(t, activationErrors, 1)
+	require.Nil(t, activationErrors[0])
+
+	newArgs := func(command string) *model.CommandArgs {
+		return &model.CommandArgs{
+			UserId:    th.BasicUser.Id,
+			ChannelId: th.BasicChannel.Id,
+			TeamId:    th.BasicTeam.Id,
+			Command:   command,
+			T:         i18n.IdentityTfunc(),
+		}
+	}
+
+	t.Run("rejects the command", func(t *testing.T) {
+		_, appErr := th.App.ExecuteCommand(th.Context, newArgs("/invite @"+th.BasicUser2.Username))
+		require.NotNil(t, appErr)
+		assert.Equal(t, "Command rejected by plugin. invites are disabled", appErr.Id)
+
+		require.EventuallyWithT(t, func(c *assert.CollectT) {
+			value, appErr := th.App.GetPluginKey(pluginIDs[0], "failed_"+th.BasicUser.Id)
+			assert.Nil(c, appErr)
+			assert.Equal(c, "/invite @"+th.BasicUser2.Username+": Command rejected by plugin. invites are disabled", string(value))
+		}, 5*time.Second, 100*time.Millisecond)
+	})
+
+	t.Run("reports the failed command", func(t *testing.T) {
+		_, appErr := th.App.ExecuteCommand(th.Context, newArgs("/doesnotexist"))
+		require.NotNil(t, appErr)
+
+		require.EventuallyWithT(t, func(c *assert.CollectT) {
+			value, kvErr := th.App.GetPluginKey(pluginIDs[0], "failed_"+th.BasicUser.Id)
+			assert.Nil(c, kvErr)
+			assert.Equal(c, "/doesnotexist: "+appErr.Id, string(value))
+		}, 5*time.Second, 100*time.Millisecond)
+	})
+
+	t.Run("executes the command unmodified", func(t *testing.T) {
+		resp, appErr := th.App.ExecuteCommand(th.Context, newArgs("/echoargs hello"))
+		require.Nil(t, appErr)
+		assert.Equal(t, "/echoargs hello", resp.Text)
+
+		require.EventuallyWithT(t, func(c *assert.CollectT) {
+			value, appErr := th.App.GetPluginKey(pluginIDs[0], "executed_"+th.BasicUser.Id)
+			assert.Nil(c, appErr)
+			assert.Equal(c, "/echoargs hello", string(value))
+		}, 5*time.Second, 100*time.Millisecond)
+	})
+
+	t.Run("rewrites the command but not the user", func(t *testing.T) {
+		args := newArgs("/echoargs secret")
+		resp, appErr := th.App.ExecuteCommand(th.Context, args)
+		require.Nil(t, appErr)
+		assert.Equal(t, "/echoargs redacted", resp.Text)
+		assert.Equal(t, th.BasicUser.Id, args.UserId)
+
+		require.EventuallyWithT(t, func(c *assert.CollectT) {
+			value, appErr := th.App.GetPluginKey(pluginIDs[0], "executed_"+th.BasicUser.Id)
+			assert.Nil(c, appErr)
+			assert.Equal(c, "/echoargs redacted", string(value))
+		}, 5*time.Second, 100*time.Millisecond)
+	})
+}
+
+func TestSearchHooks(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.ElasticsearchSettings.EnableSearching = false
+	})
+
+	publicPost := th.CreateMessagePost(th.BasicChannel, "quarterly report")
+	secretPost := th.CreateMessagePost(th.BasicChannel, "quarterly report with the secret numbers")
+	for _, name := range []string{"report.txt", "secret-report.txt"} {
+		_, err := th.App.Srv().Store().FileInfo().Save(th.Context, &model.FileInfo{
+			CreatorId: th.BasicUser.Id,
+			PostId:    publicPost.Id,
+			ChannelId: th.BasicChannel.Id,
+			Name:      name,
+			Path:      name,
+			Extension: "txt",
+			MimeType:  "text/plain",
+		})
+		require.NoError(t, err)
+	}
+
+	tearDown, _, activationErrors := SetAppEnvironmentWithPlugins(t,
+		[]string{
+			`
+		package main
+
+		import (
+			"strings"
+
+			"github.com/mattermost/mattermost/server/public/plugin"
+			"github.com/mattermost/mattermost/server/public/model"
+		)
+
+		type MyPlugin struct {
+			plugin.MattermostPlugin
+		}
+
+		func (p *MyPlugin) SearchWillBeExecuted(c *plugin.Context, searchType, userID, teamID string, params []*model.SearchParams) ([]*model.SearchParams, string) {
+			for _, param := range params {
+				if strings.Contains(param.Terms, "password") {
+					return nil, "searching for passwords is not allowed"
+				}
+				if param.Terms == "financials" {
+					param.Terms = "quarterly"
+					if searchType == model.SearchTypeFiles {
+						param.Terms = "report"
+					}
+				}
+			}
+			return params, ""
+		}
+
+		func (p *MyPlugin) SearchResultsWillBeReturned(c *plugin.Context, userID string, postResults *model.PostSearchResults, fileResults *model.FileInfoList) (*model.PostSearchResults, *model.FileInfoList) {
+			if postResults != nil {
+				for _, post := range postResults.Posts {
+					if strings.Contains(post.Message, "secret") {
+						post.Message = "redacted"
+					}
+				}
+				return postResults, nil
+			}
+
+			order := []string{}
+			for _, id := range fileResults.Order {
+				if strings.HasPrefix(fileResults.FileInfos[id].Name, "secret") {
+					delete(fileResults.FileInfos, id)
+					continue
+				}
+				order = append(order, id)
+			}
+			fileResults.Order = order
+			return nil, fileResults
+		}
+
+		func main() {
+			plugin.ClientMain(&MyPlugin{})
+		}
+	`}, th.App, th.NewPluginAPI)
+	defer tearDown()
+	require.Len(t, activationErrors, 1)
+	require.Nil(t, activationErrors[0])
+
+	t.Run("rejects the search", func(t *testing.T) {
+		_, appErr := th.App.SearchPostsForUser(th.Context, "password", th.BasicUser.Id, th.BasicTeam.Id, false, false, 0, 0, 20)
+		require.NotNil(t, appErr)
+		assert.Equal(t, "Search rejected by plugin. searching for passwords is not allowed", appErr.Id)
+
+		_, appErr = th.App.SearchFilesInTeamForUser(th.Context, "password", th.BasicUser.Id, th.BasicTeam.Id, false, false, 0, 0, 20)
+		require.NotNil(t, appErr)
+		assert.Equal(t, "Search rejected by plugin. searching for passwords is not allowed", appErr.Id)
+	})
+
+	t.Run("rewrites the search and redacts the posts", func(t *testing.T) {
+		results, appErr := th.App.SearchPostsForUser(th.Context, "financials", th.BasicUser.Id, th.BasicTeam.Id, false, false, 0, 0, 20)
+		require.Nil(t, appErr)
+		require.Len(t, results.Posts, 2)
+		assert.Equal(t, "quarterly report", results.Posts[publicPost.Id].Message)
+		assert.Equal(t, "redacted", results.Posts[secretPost.Id].Message)
+	})
+
+	t.Run("rewrites the search and drops the files", func(t *testing.T) {
+		results, appErr := th.App.SearchFilesInTeamForUser(th.Context, "financials", th.BasicUser.Id, th.BasicTeam.Id, false, false, 0, 0, 20)
+		require.Nil(t, appErr)
+		require.Len(t, results.Order, 1)
+		assert.Equal(t, "report.txt", results.FileInfos[results.Order[0]].Name)
+	})
+}
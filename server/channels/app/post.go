@@ -1739,6 +1739,14 @@ func (a *App) SearchPostsForUser(c request.CTX, terms string, userID string, tea
 		return model.MakePostSearchResults(model.NewPostList(), nil), nil
 	}
 
+	finalParamsList, appErr := a.runSearchWillBeExecutedHook(c, model.SearchTypePosts, userID, teamID, finalParamsList)
+	if appErr != nil {
+		return nil, appErr
+	}
+	if len(finalParamsList) == 0 {
+		return model.MakePostSearchResults(model.NewPostList(), nil), nil
+	}
+
 	postSearchResults, err := a.Srv().Store().Post().SearchPostsForUser(c, finalParamsList, userID, teamID, page, perPage)
 	if err != nil {
 		var appErr *model.AppError
@@ -1754,9 +1762,52 @@ func (a *App) SearchPostsForUser(c request.CTX, terms string, userID string, tea
 		return nil, appErr
 	}
 
+	postSearchResults, _ = a.runSearchResultsWillBeReturnedHook(c, userID, postSearchResults, nil)
+
 	return postSearchResults, nil
 }
 
+// runSearchWillBeExecutedHook lets plugins reject the search, or replace its params.
+func (a *App) runSearchWillBeExecutedHook(c request.CTX, searchType, userID, teamID string, paramsList []*model.SearchParams) ([]*model.SearchParams, *model.AppError) {
+	var rejectionReason string
+	pluginContext := pluginContext(c)
+	a.ch.RunMultiHook(func(hooks plugin.Hooks, _ *model.Manifest) bool {
+		var replacementParamsList []*model.SearchParams
+		replacementParamsList, rejectionReason = hooks.SearchWillBeExecuted(pluginContext, searchType, userID, teamID, paramsList)
+		if rejectionReason != "" {
+			return false
+		}
+		if replacementParamsList != nil {
+			paramsList = replacementParamsList
+		}
+		return true
+	}, plugin.SearchWillBeExecutedID)
+
+	if rejectionReason != "" {
+		return nil, model.NewAppError("Search", "Search rejected by plugin. "+rejectionReason, nil, "", http.StatusBadRequest)
+	}
+
+	return paramsList, nil
+}
+
+// runSearchResultsWillBeReturnedHook lets plugins drop or redact the results of a search.
+// Only one of postResults and fileResults is expected to be set.
+func (a *App) runSearchResultsWillBeReturnedHook(c request.CTX, userID string, postResults *model.PostSearchResults, fileResults *model.FileInfoList) (*model.PostSearchResults, *model.FileInfoList) {
+	pluginContext := pluginContext(c)
+	a.ch.RunMultiHook(func(hooks plugin.Hooks, _ *model.Manifest) bool {
+		replacementPostResults, replacementFileResults := hooks.SearchResultsWillBeReturned(pluginContext, userID, postResults, fileResults)
+		if postResults != nil && replacementPostResults != nil {
+			postResults = replacementPostResults
+		}
+		if fileResults != nil && replacementFileResults != nil {
+			fileResults = replacementFileResults
+		}
+		return true
+	}, plugin.SearchResultsWillBeReturnedID)
+
+	return postResults, fileResults
+}
+
 func (a *App) GetFileInfosForPostWithMigration(rctx request.CTX, postID string, includeDeleted bool) ([]*model.FileInfo, *model.AppError) {
 	pchan := make(chan store.StoreResult[*model.Post], 1)
 	go func() {
@@ -2702,6 +2753,10 @@ func (a *App) PermanentDeletePost(rctx request.CTX, postID, deleteByID string) *
 		return model.NewAppError("DeletePost", "app.post.get.app_error", nil, "", http.StatusBadRequest).Wrap(err)
 	}
 
+	if appErr := a.checkPostNotUnderLegalHold(post); appErr != nil {
+		return appErr
+	}
+
 	if len(post.FileIds) > 0 {
 		appErr := a.PermanentDeleteFilesByPost(rctx, post.Id)
 		if appErr != nil {
@@ -4,8 +4,11 @@
 package app
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"math"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -17,22 +20,60 @@ import (
 	"github.com/mattermost/mattermost/server/public/shared/i18n"
 	"github.com/mattermost/mattermost/server/public/shared/mlog"
 	"github.com/mattermost/mattermost/server/v8/channels/utils"
+	"github.com/mattermost/mattermost/server/v8/einterfaces"
+	"github.com/mattermost/mattermost/server/v8/platform/services/cache"
 )
 
+// rateLimitRedisKeyPrefix is the prefix of the rate limiting keys when the state is shared
+// through Redis.
+const rateLimitRedisKeyPrefix = "ratelimit:"
+
 type RateLimiter struct {
 	throttledRateLimiter *throttled.GCRARateLimiter
+	policies             []*policyRateLimiter
+	metrics              func() einterfaces.MetricsInterface
 	useAuth              bool
 	useIP                bool
 	header               string
 	trustedProxyIPHeader []string
 }
 
+// rateLimitGlobalPolicy names the global rate limit in the metrics.
+const rateLimitGlobalPolicy = "global"
+
+// policyRateLimiter enforces the quota of a rate limit policy on the requests matching it.
+type policyRateLimiter struct {
+	name      string
+	route     []string
+	methods   []string
+	authTypes []string
+	roles     []string
+	varyBy    string
+	keyPrefix string
+	limiter   *throttled.GCRARateLimiter
+}
+
 func NewRateLimiter(settings *model.RateLimitSettings, trustedProxyIPHeader []string) (*RateLimiter, error) {
 	store, err := memstore.New(*settings.MemoryStoreSize)
 	if err != nil {
 		return nil, errors.Wrap(err, i18n.T("api.server.start_server.rate_limiting_memory_store"))
 	}
 
+	return newRateLimiter(settings, trustedProxyIPHeader, store)
+}
+
+// NewRedisRateLimiter creates a rate limiter keeping its state in Redis through the given
+// cache provider, so that the limits apply to the whole cluster rather than to every node.
+func NewRedisRateLimiter(settings *model.RateLimitSettings, trustedProxyIPHeader []string, cacheProvider cache.Provider) (*RateLimiter, error) {
+	store, err := cache.NewRedisRateLimitStore(cacheProvider, rateLimitRedisKeyPrefix)
+	if err != nil {
+		return nil, errors.Wrap(err, i18n.T("api.server.start_server.rate_limiting_redis_store"))
+	}
+
+	return newRateLimiter(settings, trustedProxyIPHeader, store)
+}
+
+func newRateLimiter(settings *model.RateLimitSettings, trustedProxyIPHeader []string, store throttled.GCRAStore) (*RateLimiter, error) {
 	quota := throttled.RateQuota{
 		MaxRate:  throttled.PerSec(*settings.PerSec),
 		MaxBurst: *settings.MaxBurst,
@@ -43,8 +84,38 @@ func NewRateLimiter(settings *model.RateLimitSettings, trustedProxyIPHeader []st
 		return nil, errors.Wrap(err, i18n.T("api.server.start_server.rate_limiting_rate_limiter"))
 	}
 
+	policies := make([]*policyRateLimiter, 0, len(settings.Policies))
+	for _, policy := range settings.Policies {
+		policyQuota := throttled.RateQuota{
+			MaxRate:  throttled.PerSec(*policy.PerSec),
+			MaxBurst: *policy.MaxBurst,
+		}
+
+		limiter, err := throttled.NewGCRARateLimiter(store, policyQuota)
+		if err != nil {
+			return nil, errors.Wrap(err, i18n.T("api.server.start_server.rate_limiting_rate_limiter"))
+		}
+
+		methods := make([]string, 0, len(policy.Methods))
+		for _, method := range policy.Methods {
+			methods = append(methods, strings.ToUpper(method))
+		}
+
+		policies = append(policies, &policyRateLimiter{
+			name:      *policy.Name,
+			route:     routeSegments(*policy.Route),
+			methods:   methods,
+			authTypes: policy.AuthTypes,
+			roles:     policy.Roles,
+			varyBy:    *policy.VaryBy,
+			keyPrefix: "policy:" + *policy.Name + ":",
+			limiter:   limiter,
+		})
+	}
+
 	return &RateLimiter{
 		throttledRateLimiter: throttledRateLimiter,
+		policies:             policies,
 		useAuth:              *settings.VaryByUser,
 		useIP:                *settings.VaryByRemoteAddr,
 		header:               settings.VaryByHeader,
@@ -75,8 +146,25 @@ func (rl *RateLimiter) GenerateKey(r *http.Request) string {
 	return key
 }
 
+// SetMetrics sets the function returning the metrics the denied requests are counted in, as
+// the metrics can be enabled after the rate limiter is created.
+func (rl *RateLimiter) SetMetrics(metrics func() einterfaces.MetricsInterface) {
+	rl.metrics = metrics
+}
+
 func (rl *RateLimiter) RateLimitWriter(key string, w http.ResponseWriter) bool {
-	limited, context, err := rl.throttledRateLimiter.RateLimit(key, 1)
+	return rl.rateLimitWriter(rl.throttledRateLimiter, rateLimitGlobalPolicy, key, w)
+}
+
+// rateLimitStoreKey hashes the key the requests are counted by, as it can hold their
+// authentication token, which must not be readable by anyone with access to a shared store.
+func rateLimitStoreKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func (rl *RateLimiter) rateLimitWriter(limiter *throttled.GCRARateLimiter, policyName, key string, w http.ResponseWriter) bool {
+	limited, context, err := limiter.RateLimit(rateLimitStoreKey(key), 1)
 	if err != nil {
 		mlog.Error("Internal server error when rate limiting. Rate Limiting broken.", mlog.Err(err))
 		return false
@@ -85,7 +173,12 @@ func (rl *RateLimiter) RateLimitWriter(key string, w http.ResponseWriter) bool {
 	setRateLimitHeaders(w, context)
 
 	if limited {
-		mlog.Debug("Denied due to throttling settings code=429", mlog.String("key", key))
+		mlog.Debug("Denied due to throttling settings code=429", mlog.String("policy", policyName))
+		if rl.metrics != nil {
+			if metrics := rl.metrics(); metrics != nil {
+				metrics.IncrementHTTPRateLimitRejection(policyName)
+			}
+		}
 		http.Error(w, "limit exceeded", http.StatusTooManyRequests)
 	}
 
@@ -99,6 +192,110 @@ func (rl *RateLimiter) UserIdRateLimit(userID string, w http.ResponseWriter) boo
 	return false
 }
 
+// RouteRateLimit applies the policy matching the request, if any, and reports whether the
+// request was denied. It is called once the session of the request is known, so that the
+// policies can match on and count by the user.
+func (rl *RateLimiter) RouteRateLimit(r *http.Request, session *model.Session, w http.ResponseWriter) bool {
+	policy := rl.matchPolicy(r, session)
+	if policy == nil {
+		return false
+	}
+
+	return rl.rateLimitWriter(policy.limiter, policy.name, policy.keyPrefix+rl.policyKey(policy, r, session), w)
+}
+
+// matchPolicy returns the policy matching the request with the most specific route, or the
+// first one configured among them.
+func (rl *RateLimiter) matchPolicy(r *http.Request, session *model.Session) *policyRateLimiter {
+	path := routeSegments(r.URL.Path)
+	authType := rateLimitAuthType(session)
+
+	var match *policyRateLimiter
+	for _, policy := range rl.policies {
+		if !routeMatches(policy.route, path) {
+			continue
+		}
+		if len(policy.methods) > 0 && !slices.Contains(policy.methods, r.Method) {
+			continue
+		}
+		if len(policy.authTypes) > 0 && !slices.Contains(policy.authTypes, authType) {
+			continue
+		}
+		if len(policy.roles) > 0 && (session == nil || !hasAnyRole(session.GetUserRoles(), policy.roles)) {
+			continue
+		}
+		if match == nil || len(policy.route) > len(match.route) {
+			match = policy
+		}
+	}
+
+	return match
+}
+
+func routeSegments(route string) []string {
+	return strings.Split(strings.Trim(route, "/"), "/")
+}
+
+// routeMatches reports whether the path is the route or one of its sub-paths, a * segment of
+// the route matching any segment of the path.
+func routeMatches(route, path []string) bool {
+	if len(path) < len(route) {
+		return false
+	}
+
+	for i, segment := range route {
+		if segment != "*" && segment != path[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func hasAnyRole(userRoles, roles []string) bool {
+	for _, role := range roles {
+		if slices.Contains(userRoles, role) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// rateLimitAuthType returns how the session was authenticated, or an empty string for the
+// requests without a session.
+func rateLimitAuthType(session *model.Session) string {
+	switch {
+	case session == nil || session.UserId == "":
+		return ""
+	case session.IsBotUser():
+		return model.RateLimitAuthTypeBot
+	case session.IsOAuth:
+		return model.RateLimitAuthTypeOAuth
+	case session.IsUserAccessToken():
+		return model.RateLimitAuthTypePAT
+	default:
+		return model.RateLimitAuthTypeSession
+	}
+}
+
+// policyKey returns the key the request is counted by. Requests without a session or a token
+// are counted by IP address.
+func (rl *RateLimiter) policyKey(policy *policyRateLimiter, r *http.Request, session *model.Session) string {
+	switch policy.varyBy {
+	case model.RateLimitVaryByUser:
+		if session != nil && session.UserId != "" {
+			return "user:" + session.UserId
+		}
+	case model.RateLimitVaryByToken:
+		if token, tokenLocation := ParseAuthTokenFromRequest(r); tokenLocation != TokenLocationNotFound {
+			return "token:" + token
+		}
+	}
+
+	return "ip:" + utils.GetIPAddress(r, rl.trustedProxyIPHeader)
+}
+
 func (rl *RateLimiter) RateLimitHandler(wrappedHandler http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		key := rl.GenerateKey(r)
@@ -109,23 +306,27 @@ func (rl *RateLimiter) RateLimitHandler(wrappedHandler http.Handler) http.Handle
 	})
 }
 
-// Copied from https://github.com/throttled/throttled http.go
+// Copied from https://github.com/throttled/throttled http.go, along with the RateLimit-*
+// headers of the IETF draft.
 func setRateLimitHeaders(w http.ResponseWriter, context throttled.RateLimitResult) {
 	if v := context.Limit; v >= 0 {
-		w.Header().Add("X-RateLimit-Limit", strconv.Itoa(v))
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(v))
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(v))
 	}
 
 	if v := context.Remaining; v >= 0 {
-		w.Header().Add("X-RateLimit-Remaining", strconv.Itoa(v))
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(v))
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(v))
 	}
 
 	if v := context.ResetAfter; v >= 0 {
 		vi := int(math.Ceil(v.Seconds()))
-		w.Header().Add("X-RateLimit-Reset", strconv.Itoa(vi))
+		w.Header().Set("X-RateLimit-Reset", strconv.Itoa(vi))
+		w.Header().Set("RateLimit-Reset", strconv.Itoa(vi))
 	}
 
 	if v := context.RetryAfter; v >= 0 {
 		vi := int(math.Ceil(v.Seconds()))
-		w.Header().Add("Retry-After", strconv.Itoa(vi))
+		w.Header().Set("Retry-After", strconv.Itoa(vi))
 	}
 }
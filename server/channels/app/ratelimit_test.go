@@ -8,8 +8,10 @@ import (
 	"net/http/httptest"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+	"github.com/throttled/throttled/store/memstore"
 
 	"github.com/mattermost/mattermost/server/public/model"
 )
@@ -103,3 +105,139 @@ func TestGenerateKey_TrustedHeader(t *testing.T) {
 	key = rateLimiter.GenerateKey(req)
 	require.Equal(t, "10.10.10.5", key, "Wrong key on test without allowed trusted proxy header")
 }
+
+func TestRouteRateLimit(t *testing.T) {
+	settings := genRateLimitSettings(false, true, "")
+	settings.Policies = []*model.RateLimitPolicy{
+		{Route: model.NewPointer("/api/v4/users"), PerSec: model.NewPointer(1), MaxBurst: model.NewPointer(5), VaryBy: model.NewPointer(model.RateLimitVaryByIP)},
+		{Route: model.NewPointer("/api/v4/users/login"), Methods: []string{"post"}, PerSec: model.NewPointer(1), MaxBurst: model.NewPointer(1), VaryBy: model.NewPointer(model.RateLimitVaryByUser)},
+		{Name: model.NewPointer("bots"), Route: model.NewPointer("/api/v4/channels/*/posts"), AuthTypes: []string{model.RateLimitAuthTypeBot}, PerSec: model.NewPointer(1), MaxBurst: model.NewPointer(1), VaryBy: model.NewPointer(model.RateLimitVaryByUser)},
+		{Name: model.NewPointer("guests"), Route: model.NewPointer("/api/v4/channels/*/posts"), Roles: []string{model.SystemGuestRoleId}, PerSec: model.NewPointer(1), MaxBurst: model.NewPointer(1), VaryBy: model.NewPointer(model.RateLimitVaryByUser)},
+	}
+	for _, policy := range settings.Policies {
+		policy.SetDefaults()
+	}
+	rateLimiter, err := NewRateLimiter(settings, nil)
+	require.NoError(t, err)
+
+	matchPolicy := func(method, path string, session *model.Session) string {
+		policy := rateLimiter.matchPolicy(httptest.NewRequest(method, path, nil), session)
+		if policy == nil {
+			return ""
+		}
+		return policy.name
+	}
+
+	t.Run("matches the most specific route", func(t *testing.T) {
+		require.Equal(t, "/api/v4/users/login", matchPolicy("POST", "/api/v4/users/login", nil))
+		require.Equal(t, "/api/v4/users", matchPolicy("GET", "/api/v4/users/me", nil))
+		require.Equal(t, "/api/v4/users", matchPolicy("GET", "/api/v4/users", nil))
+		require.Empty(t, matchPolicy("GET", "/api/v4/usersearch", nil))
+		require.Empty(t, matchPolicy("GET", "/api/v4/posts", nil))
+	})
+
+	t.Run("matches the method", func(t *testing.T) {
+		require.Equal(t, "/api/v4/users", matchPolicy("GET", "/api/v4/users/login", nil))
+	})
+
+	t.Run("matches the auth type and roles", func(t *testing.T) {
+		bot := &model.Session{UserId: model.NewId(), Props: model.StringMap{model.SessionPropIsBot: model.SessionPropIsBotValue}}
+		guest := &model.Session{UserId: model.NewId(), Roles: model.SystemGuestRoleId}
+		user := &model.Session{UserId: model.NewId(), Roles: model.SystemUserRoleId}
+
+		require.Equal(t, "bots", matchPolicy("POST", "/api/v4/channels/channelid/posts", bot))
+		require.Equal(t, "guests", matchPolicy("POST", "/api/v4/channels/channelid/posts", guest))
+		require.Empty(t, matchPolicy("POST", "/api/v4/channels/channelid/posts", user))
+		require.Empty(t, matchPolicy("POST", "/api/v4/channels/channelid/posts", nil))
+		require.Empty(t, matchPolicy("POST", "/api/v4/channels/channelid/members", bot))
+	})
+
+	t.Run("derives the auth type from the session", func(t *testing.T) {
+		require.Empty(t, rateLimitAuthType(nil))
+		require.Empty(t, rateLimitAuthType(&model.Session{}))
+		require.Equal(t, model.RateLimitAuthTypeSession, rateLimitAuthType(&model.Session{UserId: "userid"}))
+		require.Equal(t, model.RateLimitAuthTypeOAuth, rateLimitAuthType(&model.Session{UserId: "userid", IsOAuth: true}))
+		require.Equal(t, model.RateLimitAuthTypePAT, rateLimitAuthType(&model.Session{UserId: "userid", Props: model.StringMap{model.SessionPropType: model.SessionTypeUserAccessToken}}))
+		require.Equal(t, model.RateLimitAuthTypeBot, rateLimitAuthType(&model.Session{UserId: "userid", Props: model.StringMap{model.SessionPropIsBot: model.SessionPropIsBotValue}}))
+	})
+
+	t.Run("keys by user, falling back to the IP address", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/api/v4/users/login", nil)
+		policy := rateLimiter.matchPolicy(req, nil)
+		req.RemoteAddr = "10.10.10.5:80"
+
+		require.Equal(t, "user:userid", rateLimiter.policyKey(policy, req, &model.Session{UserId: "userid"}))
+		require.Equal(t, "ip:10.10.10.5", rateLimiter.policyKey(policy, req, &model.Session{}))
+		require.Equal(t, "ip:10.10.10.5", rateLimiter.policyKey(policy, req, nil))
+	})
+
+	t.Run("keys by token", func(t *testing.T) {
+		policy := &policyRateLimiter{varyBy: model.RateLimitVaryByToken}
+		req := httptest.NewRequest("GET", "/api/v4/users/me", nil)
+		req.RemoteAddr = "10.10.10.5:80"
+		require.Equal(t, "ip:10.10.10.5", rateLimiter.policyKey(policy, req, nil))
+
+		req.Header.Set(model.HeaderAuth, model.HeaderBearer+" tokenvalue")
+		require.Equal(t, "token:tokenvalue", rateLimiter.policyKey(policy, req, nil))
+	})
+
+	t.Run("denies requests over the quota of the route", func(t *testing.T) {
+		session := &model.Session{UserId: model.NewId()}
+		req := httptest.NewRequest("POST", "/api/v4/users/login", nil)
+
+		// The quota allows the burst on top of the current request
+		w := httptest.NewRecorder()
+		require.False(t, rateLimiter.RouteRateLimit(req, session, w))
+		require.Equal(t, "2", w.Header().Get("X-RateLimit-Limit"))
+		require.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+		require.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+		require.False(t, rateLimiter.RouteRateLimit(req, session, httptest.NewRecorder()))
+
+		w = httptest.NewRecorder()
+		require.True(t, rateLimiter.RouteRateLimit(req, session, w))
+		require.Equal(t, http.StatusTooManyRequests, w.Code)
+
+		// Other users and routes without a policy are not affected
+		require.False(t, rateLimiter.RouteRateLimit(req, &model.Session{UserId: model.NewId()}, httptest.NewRecorder()))
+		require.False(t, rateLimiter.RouteRateLimit(httptest.NewRequest("GET", "/api/v4/posts", nil), session, httptest.NewRecorder()))
+	})
+}
+
+// keyRecordingStore records the keys the rate limiter stores.
+type keyRecordingStore struct {
+	*memstore.MemStore
+	keys []string
+}
+
+func (s *keyRecordingStore) SetIfNotExistsWithTTL(key string, value int64, ttl time.Duration) (bool, error) {
+	s.keys = append(s.keys, key)
+	return s.MemStore.SetIfNotExistsWithTTL(key, value, ttl)
+}
+
+func TestRateLimitStoreKeys(t *testing.T) {
+	settings := genRateLimitSettings(true, true, "")
+	settings.Policies = []*model.RateLimitPolicy{
+		{Route: model.NewPointer("/api/v4/users"), PerSec: model.NewPointer(1), MaxBurst: model.NewPointer(5), VaryBy: model.NewPointer(model.RateLimitVaryByToken)},
+	}
+	settings.Policies[0].SetDefaults()
+
+	mem, err := memstore.New(100)
+	require.NoError(t, err)
+	store := &keyRecordingStore{MemStore: mem}
+	rateLimiter, err := newRateLimiter(settings, nil, store)
+	require.NoError(t, err)
+
+	token := model.NewId()
+	req := httptest.NewRequest("GET", "/api/v4/users/me", nil)
+	req.Header.Set(model.HeaderAuth, model.HeaderBearer+" "+token)
+
+	require.False(t, rateLimiter.RateLimitWriter(rateLimiter.GenerateKey(req), httptest.NewRecorder()))
+	require.False(t, rateLimiter.RouteRateLimit(req, nil, httptest.NewRecorder()))
+
+	require.Len(t, store.keys, 2)
+	for _, key := range store.keys {
+		require.NotContains(t, key, token, "the authentication token should not be stored")
+		require.Equal(t, 64, len(key))
+	}
+	require.NotEqual(t, store.keys[0], store.keys[1])
+}
@@ -40,15 +40,20 @@ import (
 	"github.com/mattermost/mattermost/server/v8/channels/audit"
 	"github.com/mattermost/mattermost/server/v8/channels/jobs"
 	"github.com/mattermost/mattermost/server/v8/channels/jobs/active_users"
+	"github.com/mattermost/mattermost/server/v8/channels/jobs/antivirus_rescan"
 	"github.com/mattermost/mattermost/server/v8/channels/jobs/cleanup_desktop_tokens"
+	"github.com/mattermost/mattermost/server/v8/channels/jobs/data_retention"
 	"github.com/mattermost/mattermost/server/v8/channels/jobs/delete_dms_preferences_migration"
 	"github.com/mattermost/mattermost/server/v8/channels/jobs/delete_empty_drafts_migration"
 	"github.com/mattermost/mattermost/server/v8/channels/jobs/delete_orphan_drafts_migration"
+	"github.com/mattermost/mattermost/server/v8/channels/jobs/email_batching"
 	"github.com/mattermost/mattermost/server/v8/channels/jobs/expirynotify"
 	"github.com/mattermost/mattermost/server/v8/channels/jobs/export_delete"
 	"github.com/mattermost/mattermost/server/v8/channels/jobs/export_process"
 	"github.com/mattermost/mattermost/server/v8/channels/jobs/export_users_to_csv"
 	"github.com/mattermost/mattermost/server/v8/channels/jobs/extract_content"
+	"github.com/mattermost/mattermost/server/v8/channels/jobs/file_deduplication_migration"
+	"github.com/mattermost/mattermost/server/v8/channels/jobs/file_encryption_key_rotation"
 	"github.com/mattermost/mattermost/server/v8/channels/jobs/hosted_purchase_screening"
 	"github.com/mattermost/mattermost/server/v8/channels/jobs/import_delete"
 	"github.com/mattermost/mattermost/server/v8/channels/jobs/import_process"
@@ -63,6 +68,7 @@ import (
 	"github.com/mattermost/mattermost/server/v8/channels/jobs/refresh_post_stats"
 	"github.com/mattermost/mattermost/server/v8/channels/jobs/resend_invitation_email"
 	"github.com/mattermost/mattermost/server/v8/channels/jobs/s3_path_migration"
+	"github.com/mattermost/mattermost/server/v8/channels/jobs/slack_import"
 	"github.com/mattermost/mattermost/server/v8/channels/store"
 	"github.com/mattermost/mattermost/server/v8/channels/utils"
 	"github.com/mattermost/mattermost/server/v8/config"
@@ -72,6 +78,8 @@ import (
 	"github.com/mattermost/mattermost/server/v8/platform/services/remotecluster"
 	"github.com/mattermost/mattermost/server/v8/platform/services/searchengine/bleveengine"
 	"github.com/mattermost/mattermost/server/v8/platform/services/searchengine/bleveengine/indexer"
+	"github.com/mattermost/mattermost/server/v8/platform/services/searchengine/postgresengine"
+	pgindexer "github.com/mattermost/mattermost/server/v8/platform/services/searchengine/postgresengine/indexer"
 	"github.com/mattermost/mattermost/server/v8/platform/services/sharedchannel"
 	"github.com/mattermost/mattermost/server/v8/platform/services/telemetry"
 	"github.com/mattermost/mattermost/server/v8/platform/services/upgrader"
@@ -358,6 +366,7 @@ func NewServer(options ...Option) (*Server, error) {
 		TemplatesContainer: s.TemplatesContainer(),
 		UserService:        s.userService,
 		Store:              s.GetStore(),
+		HookRunner:         s.ch,
 	})
 	if err != nil {
 		return nil, errors.Wrapf(err, "unable to initialize email service")
@@ -396,11 +405,6 @@ func NewServer(options ...Option) (*Server, error) {
 		mlog.Error("SiteURL must be set. Some features will operate incorrectly if the SiteURL is not set. See documentation for details: https://mattermost.com/pl/configure-site-url")
 	}
 
-	// Start email batching because it's not like the other jobs
-	s.platform.AddConfigListener(func(_, _ *model.Config) {
-		s.EmailService.InitEmailBatching()
-	})
-
 	isTrial := false
 	if licence := s.License(); licence != nil {
 		isTrial = licence.IsTrial
@@ -723,10 +727,6 @@ func (s *Server) Shutdown() {
 		s.Log().Warn("Failed to stop metrics server", mlog.Err(err))
 	}
 
-	// Stopping email service after HTTP server has stopped to prevent
-	// any stray notifications from being queued.
-	s.EmailService.Stop()
-
 	// This must be done after the cluster is stopped.
 	if s.Jobs != nil {
 		// For simplicity we don't check if workers and schedulers are active
@@ -875,8 +875,15 @@ func (s *Server) Start() error {
 
 	err := s.FileBackend().TestConnection()
 	if err != nil {
+		backend := s.FileBackend()
+		if encrypted, ok := backend.(*filestore.EncryptedFileBackend); ok {
+			backend = encrypted.Unwrap()
+		}
 		if _, ok := err.(*filestore.S3FileBackendNoBucketError); ok {
-			err = s.FileBackend().(*filestore.S3FileBackend).MakeBucket()
+			err = backend.(*filestore.S3FileBackend).MakeBucket()
+		}
+		if _, ok := err.(*filestore.AzureFileBackendNoContainerError); ok {
+			err = backend.(*filestore.AzureFileBackend).MakeContainer()
 		}
 		if err != nil {
 			mlog.Error("Problem with file storage settings", mlog.Err(err))
@@ -929,11 +936,18 @@ func (s *Server) Start() error {
 	if *s.platform.Config().RateLimitSettings.Enable {
 		mlog.Info("RateLimiter is enabled")
 
-		rateLimiter, err2 := NewRateLimiter(&s.platform.Config().RateLimitSettings, s.platform.Config().ServiceSettings.TrustedProxyIPHeader)
+		var rateLimiter *RateLimiter
+		var err2 error
+		if *s.platform.Config().RateLimitSettings.StoreType == model.RateLimitStoreTypeRedis {
+			rateLimiter, err2 = NewRedisRateLimiter(&s.platform.Config().RateLimitSettings, s.platform.Config().ServiceSettings.TrustedProxyIPHeader, s.platform.CacheProvider())
+		} else {
+			rateLimiter, err2 = NewRateLimiter(&s.platform.Config().RateLimitSettings, s.platform.Config().ServiceSettings.TrustedProxyIPHeader)
+		}
 		if err2 != nil {
 			return err2
 		}
 
+		rateLimiter.SetMetrics(s.GetMetrics)
 		s.RateLimiter = rateLimiter
 		handler = rateLimiter.RateLimitHandler(handler)
 	}
@@ -1479,11 +1493,18 @@ func (s *Server) initJobs() {
 	if jobsDataRetentionJobInterface != nil {
 		builder := jobsDataRetentionJobInterface(s)
 		s.Jobs.RegisterJobType(model.JobTypeDataRetention, builder.MakeWorker(), builder.MakeScheduler())
+	} else {
+		s.Jobs.RegisterJobType(
+			model.JobTypeDataRetention,
+			data_retention.MakeWorker(s.Jobs, s.Store(), New(ServerConnector(s.Channels()))),
+			data_retention.MakeScheduler(s.Jobs, s.Store()),
+		)
 	}
 
 	if jobsMessageExportJobInterface != nil {
 		builder := jobsMessageExportJobInterface(s)
 		s.Jobs.RegisterJobType(model.JobTypeMessageExport, builder.MakeWorker(), builder.MakeScheduler())
+		s.Jobs.RegisterJobType(model.JobTypeLegalHoldExport, builder.MakeWorker(), nil)
 	}
 
 	if jobsElasticsearchAggregatorInterface != nil {
@@ -1501,11 +1522,18 @@ func (s *Server) initJobs() {
 		s.Jobs.RegisterJobType(model.JobTypeLdapSync, builder.MakeWorker(), builder.MakeScheduler())
 	}
 
-	s.Jobs.RegisterJobType(
-		model.JobTypeBlevePostIndexing,
-		indexer.MakeWorker(s.Jobs, s.platform.SearchEngine.BleveEngine.(*bleveengine.BleveEngine)),
-		nil,
-	)
+	bleveEngine := s.platform.SearchEngine.BleveEngine.(*bleveengine.BleveEngine)
+	bleveIndexer := indexer.MakeWorker(s.Jobs, bleveEngine)
+	s.Jobs.RegisterJobType(model.JobTypeBlevePostIndexing, bleveIndexer, nil)
+	bleveEngine.SetCatchUpHandler(bleveIndexer.CatchUp)
+	// The new owner of the Bleve indexes takes over the indexing jobs of the previous one, and
+	// the other nodes, joining or coming back to the cluster, catch up on what they missed.
+	s.AddClusterLeaderChangedListener(bleveIndexer.ResumeOrphanedJobs)
+	s.AddClusterLeaderChangedListener(bleveEngine.RequestCatchUp)
+
+	if postgresEngine, ok := s.platform.SearchEngine.PostgresEngine.(*postgresengine.PostgresEngine); ok && postgresEngine != nil {
+		s.Jobs.RegisterJobType(model.JobTypePostgresPostIndexing, pgindexer.MakeWorker(s.Jobs, postgresEngine), nil)
+	}
 
 	s.Jobs.RegisterJobType(
 		model.JobTypeMigrations,
@@ -1537,6 +1565,12 @@ func (s *Server) initJobs() {
 		nil,
 	)
 
+	s.Jobs.RegisterJobType(
+		model.JobTypeSlackImport,
+		slack_import.MakeWorker(s.Jobs, New(ServerConnector(s.Channels()))),
+		nil,
+	)
+
 	s.Jobs.RegisterJobType(
 		model.JobTypeImportDelete,
 		import_delete.MakeWorker(s.Jobs, New(ServerConnector(s.Channels())), s.Store()),
@@ -1558,12 +1592,29 @@ func (s *Server) initJobs() {
 		delete_orphan_drafts_migration.MakeWorker(s.Jobs, s.Store(), New(ServerConnector(s.Channels()))),
 		nil)
 
+	s.Jobs.RegisterJobType(
+		model.JobTypeFileDeduplicationMigration,
+		file_deduplication_migration.MakeWorker(s.Jobs, s.Store(), New(ServerConnector(s.Channels()))),
+		nil)
+
 	s.Jobs.RegisterJobType(
 		model.JobTypeExportDelete,
 		export_delete.MakeWorker(s.Jobs, New(ServerConnector(s.Channels()))),
 		export_delete.MakeScheduler(s.Jobs),
 	)
 
+	s.Jobs.RegisterJobType(
+		model.JobTypeFileEncryptionKeyRotation,
+		file_encryption_key_rotation.MakeWorker(s.Jobs, New(ServerConnector(s.Channels()))),
+		nil,
+	)
+
+	s.Jobs.RegisterJobType(
+		model.JobTypeAntivirusRescan,
+		antivirus_rescan.MakeWorker(s.Jobs, s.Store(), New(ServerConnector(s.Channels()))),
+		antivirus_rescan.MakeScheduler(s.Jobs),
+	)
+
 	s.Jobs.RegisterJobType(
 		model.JobTypeExportProcess,
 		export_process.MakeWorker(s.Jobs, New(ServerConnector(s.Channels()))),
@@ -1624,6 +1675,12 @@ func (s *Server) initJobs() {
 		post_persistent_notifications.MakeScheduler(s.Jobs, func() *model.License { return s.License() }),
 	)
 
+	s.Jobs.RegisterJobType(
+		model.JobTypeEmailBatching,
+		email_batching.MakeWorker(s.Jobs, s.EmailService),
+		email_batching.MakeScheduler(s.Jobs),
+	)
+
 	s.Jobs.RegisterJobType(
 		model.JobTypeInstallPluginNotifyAdmin,
 		notify_admin.MakeInstallPluginNotifyWorker(s.Jobs, New(ServerConnector(s.Channels()))),
@@ -8,6 +8,7 @@ import (
 	"context"
 	"fmt"
 	"image"
+	"io"
 	"mime/multipart"
 	"regexp"
 	"strings"
@@ -20,6 +21,16 @@ import (
 )
 
 func (a *App) SlackImport(c request.CTX, fileData multipart.File, fileSize int64, teamID string) (*model.AppError, *bytes.Buffer) {
+	return a.slackImporter(c).SlackImport(c, fileData, fileSize, teamID)
+}
+
+// SlackImportWithOptions imports a Slack export, resuming from the checkpoint of the options
+// and reporting its progress. It is safe to run again on an export already imported.
+func (a *App) SlackImportWithOptions(c request.CTX, fileData io.ReaderAt, fileSize int64, teamID string, options slackimport.Options) (*model.AppError, *bytes.Buffer) {
+	return a.slackImporter(c).SlackImportWithOptions(c, fileData, fileSize, teamID, options)
+}
+
+func (a *App) slackImporter(c request.CTX) *slackimport.SlackImporter {
 	actions := slackimport.Actions{
 		UpdateActive: func(user *model.User, active bool) (*model.User, *model.AppError) {
 			return a.UpdateActive(c, user, active)
@@ -49,8 +60,7 @@ func (a *App) SlackImport(c request.CTX, fileData multipart.File, fileSize int64
 		},
 	}
 
-	importer := slackimport.New(a.Srv().Store(), actions, a.Config())
-	return importer.SlackImport(c, fileData, fileSize, teamID)
+	return slackimport.New(a.Srv().Store(), actions, a.Config())
 }
 
 func (a *App) ProcessSlackText(text string) string {
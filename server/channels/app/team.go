@@ -136,6 +136,16 @@ func (a *App) CreateTeam(c request.CTX, team *model.Team) (*model.Team, *model.A
 		}
 	}
 
+	// The team is sanitized in place by the API, so the hooks get their own copy.
+	createdTeam := rteam.ShallowCopy()
+	a.Srv().Go(func() {
+		pluginContext := pluginContext(c)
+		a.ch.RunMultiHook(func(hooks plugin.Hooks, _ *model.Manifest) bool {
+			hooks.TeamHasBeenCreated(pluginContext, createdTeam)
+			return true
+		}, plugin.TeamHasBeenCreatedID)
+	})
+
 	return rteam, nil
 }
 
@@ -187,6 +197,8 @@ func (a *App) UpdateTeam(team *model.Team) (*model.Team, *model.AppError) {
 		return nil, appErr
 	}
 
+	a.teamHasBeenUpdated(&plugin.Context{}, oldTeam)
+
 	return oldTeam, nil
 }
 
@@ -229,6 +241,8 @@ func (a *App) RenameTeam(team *model.Team, newTeamName string, newDisplayName st
 		}
 	}
 
+	a.teamHasBeenUpdated(&plugin.Context{}, newTeam)
+
 	return newTeam, nil
 }
 
@@ -265,6 +279,8 @@ func (a *App) UpdateTeamScheme(team *model.Team) (*model.Team, *model.AppError)
 		return nil, appErr
 	}
 
+	a.teamHasBeenUpdated(&plugin.Context{}, oldTeam)
+
 	return oldTeam, nil
 }
 
@@ -300,6 +316,8 @@ func (a *App) UpdateTeamPrivacy(teamID string, teamType string, allowOpenInvite
 		return appErr
 	}
 
+	a.teamHasBeenUpdated(&plugin.Context{}, oldTeam)
+
 	return nil
 }
 
@@ -328,6 +346,8 @@ func (a *App) PatchTeam(teamID string, patch *model.TeamPatch) (*model.Team, *mo
 		return nil, appErr
 	}
 
+	a.teamHasBeenUpdated(&plugin.Context{}, team)
+
 	return team, nil
 }
 
@@ -357,9 +377,35 @@ func (a *App) RegenerateTeamInviteId(teamID string) (*model.Team, *model.AppErro
 		return nil, appErr
 	}
 
+	a.teamHasBeenUpdated(&plugin.Context{}, updatedTeam)
+
 	return updatedTeam, nil
 }
 
+// teamHasBeenUpdated runs the TeamHasBeenUpdated hook. Most team updates are not made within a
+// request, the hook then gets an empty plugin context. The hook gets a copy of the team, as the
+// API sanitizes the returned team in place.
+func (a *App) teamHasBeenUpdated(pluginContext *plugin.Context, team *model.Team) {
+	team = team.ShallowCopy()
+	a.Srv().Go(func() {
+		a.ch.RunMultiHook(func(hooks plugin.Hooks, _ *model.Manifest) bool {
+			hooks.TeamHasBeenUpdated(pluginContext, team)
+			return true
+		}, plugin.TeamHasBeenUpdatedID)
+	})
+}
+
+// teamHasBeenDeleted runs the TeamHasBeenDeleted hook, see teamHasBeenUpdated.
+func (a *App) teamHasBeenDeleted(pluginContext *plugin.Context, team *model.Team) {
+	team = team.ShallowCopy()
+	a.Srv().Go(func() {
+		a.ch.RunMultiHook(func(hooks plugin.Hooks, _ *model.Manifest) bool {
+			hooks.TeamHasBeenDeleted(pluginContext, team)
+			return true
+		}, plugin.TeamHasBeenDeletedID)
+	})
+}
+
 func (a *App) sendTeamEvent(team *model.Team, event model.WebsocketEventType) *model.AppError {
 	sanitizedTeam := &model.Team{}
 	*sanitizedTeam = *team
@@ -1772,6 +1818,8 @@ func (a *App) PermanentDeleteTeam(c request.CTX, team *model.Team) *model.AppErr
 		return appErr
 	}
 
+	a.teamHasBeenDeleted(pluginContext(c), team)
+
 	return nil
 }
 
@@ -1804,6 +1852,8 @@ func (a *App) SoftDeleteTeam(teamID string) *model.AppError {
 		return appErr
 	}
 
+	a.teamHasBeenDeleted(&plugin.Context{}, team)
+
 	return nil
 }
 
@@ -1832,6 +1882,8 @@ func (a *App) RestoreTeam(teamID string) *model.AppError {
 		return appErr
 	}
 
+	a.teamHasBeenUpdated(&plugin.Context{}, team)
+
 	return nil
 }
 
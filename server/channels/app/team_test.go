@@ -1468,7 +1468,6 @@ func TestInviteNewUsersToTeamGracefully(t *testing.T) {
 			false,
 			false,
 		).Once().Return(nil)
-		emailServiceMock.On("Stop").Once().Return()
 		th.App.Srv().EmailService = &emailServiceMock
 
 		res, err := th.App.InviteNewUsersToTeamGracefully(th.Context, memberInvite, th.BasicTeam.Id, th.BasicUser.Id, "")
@@ -1493,7 +1492,6 @@ func TestInviteNewUsersToTeamGracefully(t *testing.T) {
 			false,
 			false,
 		).Once().Return(email.SendMailError)
-		emailServiceMock.On("Stop").Once().Return()
 		th.App.Srv().EmailService = &emailServiceMock
 
 		res, err := th.App.InviteNewUsersToTeamGracefully(th.Context, memberInvite, th.BasicTeam.Id, th.BasicUser.Id, "")
@@ -1522,7 +1520,6 @@ func TestInviteNewUsersToTeamGracefully(t *testing.T) {
 			false,
 			false,
 		).Once().Return([]*model.EmailInviteWithError{}, nil)
-		emailServiceMock.On("Stop").Once().Return()
 		th.App.Srv().EmailService = &emailServiceMock
 
 		res, err := th.App.InviteNewUsersToTeamGracefully(th.Context, memberInvite, th.BasicTeam.Id, th.BasicUser.Id, "")
@@ -1547,7 +1544,6 @@ func TestInviteNewUsersToTeamGracefully(t *testing.T) {
 			false,
 			false,
 		).Once().Return(nil)
-		emailServiceMock.On("Stop").Once().Return()
 		th.App.Srv().EmailService = &emailServiceMock
 
 		res, err := th.App.InviteNewUsersToTeamGracefully(th.Context, memberInvite, th.BasicTeam.Id, th.BasicUser.Id, "")
@@ -1580,7 +1576,6 @@ func TestInviteGuestsToChannelsGracefully(t *testing.T) {
 			false,
 			false,
 		).Once().Return(nil)
-		emailServiceMock.On("Stop").Once().Return()
 		th.App.Srv().EmailService = &emailServiceMock
 
 		res, err := th.App.InviteGuestsToChannelsGracefully(th.Context, th.BasicTeam.Id, &model.GuestsInvite{
@@ -1607,7 +1602,6 @@ func TestInviteGuestsToChannelsGracefully(t *testing.T) {
 			false,
 			false,
 		).Once().Return(email.SendMailError)
-		emailServiceMock.On("Stop").Once().Return()
 		th.App.Srv().EmailService = &emailServiceMock
 
 		res, err := th.App.InviteGuestsToChannelsGracefully(th.Context, th.BasicTeam.Id, &model.GuestsInvite{
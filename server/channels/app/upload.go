@@ -4,11 +4,15 @@
 package app
 
 import (
+	"cmp"
 	"errors"
+	"fmt"
 	"io"
 	"mime"
 	"net/http"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -18,6 +22,7 @@ import (
 	"github.com/mattermost/mattermost/server/public/shared/mlog"
 	"github.com/mattermost/mattermost/server/public/shared/request"
 	"github.com/mattermost/mattermost/server/v8/channels/store"
+	"github.com/mattermost/mattermost/server/v8/platform/shared/filestore"
 )
 
 const minFirstPartSize = 5 * 1024 * 1024 // 5MB
@@ -180,6 +185,86 @@ func (a *App) GetUploadSessionsForUser(userID string) ([]*model.UploadSession, *
 	return uss, nil
 }
 
+// uploadPartPath returns the path of the part of an upload starting at the
+// given offset, used when the file backend cannot append to the upload file.
+// Parts keep the incomplete upload suffix so they are cleaned up with it.
+func uploadPartPath(uploadPath string, offset int64) string {
+	return fmt.Sprintf("%s.%d%s", uploadPath, offset, model.IncompleteUploadSuffix)
+}
+
+func (a *App) writeUploadPart(rd io.Reader, uploadPath string, offset int64) (int64, *model.AppError) {
+	partPath := uploadPartPath(uploadPath, offset)
+	written, err := a.WriteFile(rd, partPath)
+	if err != nil {
+		// A partially written part can't be resumed, so the client has to send it again.
+		a.RemoveFile(partPath)
+		return 0, err
+	}
+	return written, nil
+}
+
+// joinUploadParts writes the upload file followed by its parts in a single
+// pass, then removes the parts.
+func (a *App) joinUploadParts(uploadPath string) *model.AppError {
+	files, appErr := a.ListDirectory(filepath.Dir(uploadPath))
+	if appErr != nil {
+		return appErr
+	}
+
+	type uploadPart struct {
+		path   string
+		offset int64
+	}
+	var parts []uploadPart
+	prefix := filepath.Base(uploadPath) + "."
+	for _, file := range files {
+		name := filepath.Base(file)
+		if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, model.IncompleteUploadSuffix) {
+			continue
+		}
+		offset, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimPrefix(name, prefix), model.IncompleteUploadSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		parts = append(parts, uploadPart{path: uploadPartPath(uploadPath, offset), offset: offset})
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+	slices.SortFunc(parts, func(a, b uploadPart) int {
+		return cmp.Compare(a.offset, b.offset)
+	})
+
+	paths := []string{uploadPath}
+	for _, part := range parts {
+		paths = append(paths, part.path)
+	}
+	readers := make([]io.Reader, 0, len(paths))
+	for _, path := range paths {
+		file, appErr := a.FileReader(path)
+		if appErr != nil {
+			return appErr
+		}
+		defer file.Close()
+		readers = append(readers, file)
+	}
+
+	joinedPath := uploadPath + "." + model.NewId() + model.IncompleteUploadSuffix
+	if _, appErr := a.WriteFile(io.MultiReader(readers...), joinedPath); appErr != nil {
+		a.RemoveFile(joinedPath)
+		return appErr
+	}
+	if appErr := a.MoveFile(joinedPath, uploadPath); appErr != nil {
+		a.RemoveFile(joinedPath)
+		return appErr
+	}
+
+	for _, part := range parts {
+		a.RemoveFile(part.path)
+	}
+	return nil
+}
+
 func (a *App) UploadData(c request.CTX, us *model.UploadSession, rd io.Reader) (*model.FileInfo, *model.AppError) {
 	// prevent more than one caller to upload data at the same time for a given upload session.
 	// This is to avoid possible inconsistencies.
@@ -240,7 +325,11 @@ func (a *App) UploadData(c request.CTX, us *model.UploadSession, rd io.Reader) (
 		}
 	} else if us.FileOffset < us.FileSize {
 		// resume upload
-		written, err = a.AppendFile(lr, uploadPath)
+		if filestore.SupportsAppend(a.FileBackend()) {
+			written, err = a.AppendFile(lr, uploadPath)
+		} else {
+			written, err = a.writeUploadPart(lr, uploadPath, us.FileOffset)
+		}
 	}
 	if written > 0 {
 		us.FileOffset += written
@@ -257,6 +346,12 @@ func (a *App) UploadData(c request.CTX, us *model.UploadSession, rd io.Reader) (
 		return nil, nil
 	}
 
+	if !filestore.SupportsAppend(a.FileBackend()) {
+		if err := a.joinUploadParts(uploadPath); err != nil {
+			return nil, err
+		}
+	}
+
 	// upload is done, create FileInfo
 	file, err := a.FileReader(uploadPath)
 	if err != nil {
@@ -306,6 +401,17 @@ func (a *App) UploadData(c request.CTX, us *model.UploadSession, rd io.Reader) (
 		}
 	}
 
+	var duplicatePath string
+	if us.Type == model.UploadTypeAttachment {
+		if err := a.scanUploadedFile(c, info); err != nil {
+			return nil, err
+		}
+
+		if duplicatePath, err = a.deduplicateFile(info, ""); err != nil {
+			return nil, err
+		}
+	}
+
 	var storeErr error
 	if info, storeErr = a.Srv().Store().FileInfo().Save(c, info); storeErr != nil {
 		var appErr *model.AppError
@@ -316,6 +422,7 @@ func (a *App) UploadData(c request.CTX, us *model.UploadSession, rd io.Reader) (
 			return nil, model.NewAppError("uploadData", "app.upload.upload_data.save.app_error", nil, "", http.StatusInternalServerError).Wrap(storeErr)
 		}
 	}
+	a.commitDeduplicatedFile(c, info, duplicatePath)
 
 	if *a.Config().FileSettings.ExtractContent {
 		infoCopy := *info
@@ -5,10 +5,12 @@ package app
 
 import (
 	"bytes"
+	"encoding/base64"
 	"io"
 	"math/rand"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -18,6 +20,7 @@ import (
 	"github.com/mattermost/mattermost/server/public/model"
 	"github.com/mattermost/mattermost/server/v8/channels/utils/fileutils"
 	"github.com/mattermost/mattermost/server/v8/channels/utils/imgutils"
+	"github.com/mattermost/mattermost/server/v8/platform/shared/filestore"
 )
 
 func TestCreateUploadSession(t *testing.T) {
@@ -243,6 +246,55 @@ func TestUploadData(t *testing.T) {
 	})
 }
 
+func TestUploadDataEncrypted(t *testing.T) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+
+	th := SetupConfig(t, func(cfg *model.Config) {
+		cfg.FileSettings.EncryptionAtRestKey = model.NewPointer(base64.StdEncoding.EncodeToString(key))
+	}).InitBasic()
+	defer th.TearDown()
+	require.False(t, filestore.SupportsAppend(th.App.FileBackend()))
+
+	us := &model.UploadSession{
+		Id:        model.NewId(),
+		Type:      model.UploadTypeAttachment,
+		UserId:    th.BasicUser.Id,
+		ChannelId: th.BasicChannel.Id,
+		Filename:  "upload",
+		FileSize:  8 * 1024 * 1024,
+	}
+
+	us, appErr := th.App.CreateUploadSession(th.Context, us)
+	require.Nil(t, appErr)
+
+	data := make([]byte, us.FileSize)
+	_, err = rand.Read(data)
+	require.NoError(t, err)
+
+	for _, size := range []int64{5 * 1024 * 1024, 2 * 1024 * 1024, 1024 * 1024} {
+		rd := &io.LimitedReader{
+			R: bytes.NewReader(data[us.FileOffset:]),
+			N: size,
+		}
+		var info *model.FileInfo
+		info, appErr = th.App.UploadData(th.Context, us, rd)
+		require.Nil(t, appErr)
+		require.Equal(t, us.FileOffset == us.FileSize, info != nil)
+	}
+
+	d, appErr := th.App.ReadFile(us.Path)
+	require.Nil(t, appErr)
+	require.Equal(t, data, d)
+
+	files, appErr := th.App.ListDirectory(filepath.Dir(us.Path))
+	require.Nil(t, appErr)
+	for _, file := range files {
+		require.False(t, strings.HasSuffix(file, model.IncompleteUploadSuffix), "upload parts should have been removed")
+	}
+}
+
 func TestUploadDataConcurrent(t *testing.T) {
 	th := Setup(t).InitBasic()
 	defer th.TearDown()
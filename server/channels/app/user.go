@@ -751,33 +751,45 @@ func (a *App) GenerateMfaSecret(userID string) (*model.MfaSecret, *model.AppErro
 	return mfaSecret, nil
 }
 
-func (a *App) ActivateMfa(userID, token string) *model.AppError {
+// ActivateMfa turns on multi-factor authentication for the user and returns
+// a fresh set of recovery codes.
+func (a *App) ActivateMfa(userID, token string) (*model.MfaRecoveryCodes, *model.AppError) {
 	user, appErr := a.GetUser(userID)
 	if appErr != nil {
-		return appErr
+		return nil, appErr
 	}
 
 	if user.AuthService != "" && user.AuthService != model.UserAuthServiceLdap {
-		return model.NewAppError("ActivateMfa", "api.user.activate_mfa.email_and_ldap_only.app_error", nil, "", http.StatusBadRequest)
+		return nil, model.NewAppError("ActivateMfa", "api.user.activate_mfa.email_and_ldap_only.app_error", nil, "", http.StatusBadRequest)
 	}
 
 	if !*a.Config().ServiceSettings.EnableMultifactorAuthentication {
-		return model.NewAppError("ActivateMfa", "mfa.mfa_disabled.app_error", nil, "", http.StatusNotImplemented)
+		return nil, model.NewAppError("ActivateMfa", "mfa.mfa_disabled.app_error", nil, "", http.StatusNotImplemented)
 	}
 
 	if err := a.ch.srv.userService.ActivateMfa(user, token); err != nil {
 		switch {
 		case errors.Is(err, mfa.InvalidToken):
-			return model.NewAppError("ActivateMfa", "mfa.activate.bad_token.app_error", nil, "", http.StatusUnauthorized)
+			return nil, model.NewAppError("ActivateMfa", "mfa.activate.bad_token.app_error", nil, "", http.StatusUnauthorized)
 		default:
-			return model.NewAppError("ActivateMfa", "mfa.activate.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+			return nil, model.NewAppError("ActivateMfa", "mfa.activate.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
 		}
 	}
 
 	// Make sure old MFA status is not cached locally or in cluster nodes.
-	a.InvalidateCacheForUser(userID)
+	defer a.InvalidateCacheForUser(userID)
 
-	return nil
+	codes, appErr := a.generateMfaRecoveryCodes(userID)
+	if appErr != nil {
+		// Without recovery codes the user could be locked out, so MFA stays off and the
+		// secret is kept for another attempt.
+		if err := a.Srv().Store().User().UpdateMfaActive(userID, false); err != nil {
+			mlog.Error("Failed to roll back the MFA activation", mlog.String("user_id", userID), mlog.Err(err))
+		}
+		return nil, appErr
+	}
+
+	return codes, nil
 }
 
 func (a *App) DeactivateMfa(userID string) *model.AppError {
@@ -790,6 +802,15 @@ func (a *App) DeactivateMfa(userID string) *model.AppError {
 		return model.NewAppError("DeactivateMfa", "mfa.deactivate.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
 	}
 
+	// Registered security keys are a second factor too, so they go away along with the secret.
+	if err := a.Srv().Store().WebAuthnCredential().DeleteAllForUser(user.Id); err != nil {
+		return model.NewAppError("DeactivateMfa", "app.webauthn_credential.delete.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
+	if err := a.Srv().Store().MfaRecoveryCode().DeleteAllForUser(user.Id); err != nil {
+		return model.NewAppError("DeactivateMfa", "mfa.recovery_codes.delete.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
 	// Make sure old MFA status is not cached locally or in cluster nodes.
 	a.InvalidateCacheForUser(userID)
 
@@ -1190,6 +1211,49 @@ func (a *App) UpdateUserAuth(c request.CTX, userID string, userAuth *model.UserA
 	return userAuth, nil
 }
 
+// runUserWillBeUpdatedHook lets the plugins modify or reject an update of the user's profile or
+// custom profile attribute values, returning the user and attribute values to save.
+func (a *App) runUserWillBeUpdatedHook(c request.CTX, newUser, oldUser *model.User, attributes map[string]string) (*model.User, map[string]string, *model.AppError) {
+	var rejectionReason string
+	pluginContext := pluginContext(c)
+	a.ch.RunMultiHook(func(hooks plugin.Hooks, _ *model.Manifest) bool {
+		var replacementUser *model.User
+		var replacementAttributes map[string]string
+		replacementUser, replacementAttributes, rejectionReason = hooks.UserWillBeUpdated(pluginContext, newUser, oldUser, attributes)
+		if rejectionReason != "" {
+			return false
+		}
+		// Profile updates come without attribute values, only the relevant replacement is used.
+		if attributes == nil && replacementUser != nil {
+			// Plugins may not redirect the update to another user.
+			replacementUser.Id = oldUser.Id
+			newUser = replacementUser
+		} else if attributes != nil && replacementAttributes != nil {
+			attributes = replacementAttributes
+		}
+		return true
+	}, plugin.UserWillBeUpdatedID)
+
+	if rejectionReason != "" {
+		return nil, nil, model.NewAppError("UpdateUser", "User update rejected by plugin. "+rejectionReason, nil, "", http.StatusBadRequest)
+	}
+
+	return newUser, attributes, nil
+}
+
+func (a *App) userHasBeenUpdated(c request.CTX, newUser, oldUser *model.User, attributes map[string]string) {
+	// The users are copied as they are sanitized before being returned.
+	newUser = newUser.DeepCopy()
+	oldUser = oldUser.DeepCopy()
+	a.Srv().Go(func() {
+		pluginContext := pluginContext(c)
+		a.ch.RunMultiHook(func(hooks plugin.Hooks, _ *model.Manifest) bool {
+			hooks.UserHasBeenUpdated(pluginContext, newUser, oldUser, attributes)
+			return true
+		}, plugin.UserHasBeenUpdatedID)
+	})
+}
+
 func (a *App) sendUpdatedUserEvent(user *model.User) {
 	// exclude event creator user from admin, member user broadcast
 	omitUsers := make(map[string]bool, 1)
@@ -1251,6 +1315,11 @@ func (a *App) UpdateUser(c request.CTX, user *model.User, sendNotifications bool
 		user.CreateAt = prev.CreateAt
 	}
 
+	user, _, appErr := a.runUserWillBeUpdatedHook(c, user, prev, nil)
+	if appErr != nil {
+		return nil, appErr
+	}
+
 	if user.Username != prev.Username {
 		if err := a.isUniqueToGroupNames(user.Username); err != nil {
 			err.Where = "UpdateUser"
@@ -1356,6 +1425,8 @@ func (a *App) UpdateUser(c request.CTX, user *model.User, sendNotifications bool
 	a.InvalidateCacheForUser(user.Id)
 	a.onUserProfileChange(user.Id)
 
+	a.userHasBeenUpdated(c, newUser, userUpdate.Old, nil)
+
 	newUser.Sanitize(map[string]bool{})
 
 	return newUser, nil
@@ -1392,14 +1463,18 @@ func (a *App) updateUserNotifyProps(userID string, props map[string]string) *mod
 	return nil
 }
 
-func (a *App) UpdateMfa(c request.CTX, activate bool, userID, token string) *model.AppError {
+// UpdateMfa activates or deactivates multi-factor authentication for the user.
+// The recovery codes issued on activation are returned, nil otherwise.
+func (a *App) UpdateMfa(c request.CTX, activate bool, userID, token string) (*model.MfaRecoveryCodes, *model.AppError) {
+	var codes *model.MfaRecoveryCodes
 	if activate {
-		if err := a.ActivateMfa(userID, token); err != nil {
-			return err
+		var err *model.AppError
+		if codes, err = a.ActivateMfa(userID, token); err != nil {
+			return nil, err
 		}
 	} else {
 		if err := a.DeactivateMfa(userID); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
@@ -1415,7 +1490,7 @@ func (a *App) UpdateMfa(c request.CTX, activate bool, userID, token string) *mod
 		}
 	})
 
-	return nil
+	return codes, nil
 }
 
 func (a *App) UpdatePasswordByUserIdSendEmail(c request.CTX, userID, newPassword, method string) *model.AppError {
@@ -1775,6 +1850,10 @@ func (a *App) UpdateUserRolesWithUser(c request.CTX, user *model.User, newRoles
 }
 
 func (a *App) PermanentDeleteUser(rctx request.CTX, user *model.User) *model.AppError {
+	if appErr := a.checkUserNotUnderLegalHold(user.Id); appErr != nil {
+		return appErr
+	}
+
 	rctx.Logger().Warn("Attempting to permanently delete account", mlog.String("user_id", user.Id), mlog.String("user_email", user.Email))
 	if user.IsInRole(model.SystemAdminRoleId) {
 		rctx.Logger().Warn("You are deleting a user that is a system administrator.  You may need to set another account as the system administrator using the command line tools.", mlog.String("user_email", user.Email))
@@ -1792,6 +1871,14 @@ func (a *App) PermanentDeleteUser(rctx request.CTX, user *model.User) *model.App
 		return model.NewAppError("PermanentDeleteUser", "app.user_access_token.delete.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
 	}
 
+	if err := a.Srv().Store().WebAuthnCredential().DeleteAllForUser(user.Id); err != nil {
+		return model.NewAppError("PermanentDeleteUser", "app.webauthn_credential.delete.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
+	if err := a.Srv().Store().MfaRecoveryCode().DeleteAllForUser(user.Id); err != nil {
+		return model.NewAppError("PermanentDeleteUser", "mfa.recovery_codes.delete.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
 	if err := a.Srv().Store().OAuth().PermanentDeleteAuthDataByUser(user.Id); err != nil {
 		return model.NewAppError("PermanentDeleteUser", "app.oauth.permanent_delete_auth_data_by_user.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
 	}
@@ -1847,6 +1934,12 @@ func (a *App) PermanentDeleteUser(rctx request.CTX, user *model.User) *model.App
 		rctx.Logger().Warn("Error getting file list for user from FileInfoStore", mlog.Err(err))
 	}
 
+	// The files covered by a legal hold are kept.
+	infos, appErr := a.filterFilesNotUnderLegalHold(infos)
+	if appErr != nil {
+		return appErr
+	}
+
 	a.RemoveFilesFromFileStore(rctx, infos)
 
 	// delete directory containing user's profile image
@@ -1882,6 +1975,8 @@ func (a *App) PermanentDeleteUser(rctx request.CTX, user *model.User) *model.App
 		return model.NewAppError("PermanentDeleteUser", "app.file_info.permanent_delete_by_user.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
 	}
 
+	a.RemoveUnreferencedFileBlobs(rctx, infos)
+
 	if err := a.Srv().Store().User().PermanentDelete(rctx, user.Id); err != nil {
 		return model.NewAppError("PermanentDeleteUser", "app.user.permanent_delete.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
 	}
@@ -0,0 +1,189 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package app
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-webauthn/webauthn/protocol"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+	"github.com/mattermost/mattermost/server/v8/platform/shared/mfa"
+)
+
+func (a *App) webAuthn() (*mfa.WebAuthn, *model.AppError) {
+	if !*a.Config().ServiceSettings.EnableMultifactorAuthentication {
+		return nil, model.NewAppError("webAuthn", "mfa.mfa_disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	if !*a.Config().ServiceSettings.EnableWebAuthn {
+		return nil, model.NewAppError("webAuthn", "mfa.webauthn.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	w, err := mfa.NewWebAuthn(a.Srv().Store().WebAuthnCredential(), a.Srv().Store().Token(), a.GetSiteURL(), *a.Config().TeamSettings.SiteName)
+	if err != nil {
+		return nil, model.NewAppError("webAuthn", "mfa.webauthn.configure.app_error", nil, "", http.StatusNotImplemented).Wrap(err)
+	}
+
+	return w, nil
+}
+
+// BeginWebAuthnRegistration returns the options the client passes to
+// navigator.credentials.create() to register a new security key.
+func (a *App) BeginWebAuthnRegistration(userID string) (*protocol.CredentialCreation, *model.AppError) {
+	user, appErr := a.GetUser(userID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if user.AuthService != "" && user.AuthService != model.UserAuthServiceLdap {
+		return nil, model.NewAppError("BeginWebAuthnRegistration", "api.user.activate_mfa.email_and_ldap_only.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	w, appErr := a.webAuthn()
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	creation, err := w.BeginRegistration(user)
+	if err != nil {
+		return nil, model.NewAppError("BeginWebAuthnRegistration", "mfa.webauthn.register.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
+	return creation, nil
+}
+
+// FinishWebAuthnRegistration verifies and stores a new security key. Registering
+// a key turns on multi-factor authentication for the user.
+func (a *App) FinishWebAuthnRegistration(userID, name string, response []byte) (*model.WebAuthnCredential, *model.AppError) {
+	user, appErr := a.GetUser(userID)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	w, appErr := a.webAuthn()
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	credential, err := w.FinishRegistration(user, name, response)
+	if err != nil {
+		var invErr *model.AppError
+		var cErr *store.ErrConflict
+		switch {
+		case errors.Is(err, mfa.InvalidWebAuthnResponse):
+			return nil, model.NewAppError("FinishWebAuthnRegistration", "mfa.webauthn.invalid_response.app_error", nil, "", http.StatusBadRequest).Wrap(err)
+		case errors.As(err, &invErr):
+			return nil, invErr
+		case errors.As(err, &cErr):
+			return nil, model.NewAppError("FinishWebAuthnRegistration", "mfa.webauthn.register.exists.app_error", nil, "", http.StatusBadRequest).Wrap(err)
+		default:
+			return nil, model.NewAppError("FinishWebAuthnRegistration", "mfa.webauthn.register.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+		}
+	}
+
+	// The first security key turns MFA on, so the user gets recovery codes as with an
+	// authenticator app, or losing the key would lock them out.
+	var recoveryCodes *model.MfaRecoveryCodes
+	if !user.MfaActive {
+		recoveryCodes, appErr = a.generateMfaRecoveryCodes(user.Id)
+		if appErr != nil {
+			if err := a.Srv().Store().WebAuthnCredential().Delete(credential.Id); err != nil {
+				mlog.Error("Failed to roll back the registration of the security key", mlog.String("user_id", user.Id), mlog.Err(err))
+			}
+			return nil, appErr
+		}
+
+		if err := a.Srv().Store().User().UpdateMfaActive(user.Id, true); err != nil {
+			return nil, model.NewAppError("FinishWebAuthnRegistration", "mfa.activate.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+		}
+	}
+
+	// Make sure old MFA status is not cached locally or in cluster nodes.
+	a.InvalidateCacheForUser(user.Id)
+
+	credential.Sanitize()
+	if recoveryCodes != nil {
+		credential.RecoveryCodes = recoveryCodes.RecoveryCodes
+	}
+	return credential, nil
+}
+
+func (a *App) GetWebAuthnCredentials(userID string) ([]*model.WebAuthnCredential, *model.AppError) {
+	credentials, err := a.Srv().Store().WebAuthnCredential().GetForUser(userID)
+	if err != nil {
+		return nil, model.NewAppError("GetWebAuthnCredentials", "app.webauthn_credential.get_for_user.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
+	for _, credential := range credentials {
+		credential.Sanitize()
+	}
+
+	return credentials, nil
+}
+
+// DeleteWebAuthnCredential revokes one of the user's security keys. Revoking the
+// last key of a user without an authenticator app turns off multi-factor authentication.
+func (a *App) DeleteWebAuthnCredential(userID, credentialID string) *model.AppError {
+	user, appErr := a.GetUser(userID)
+	if appErr != nil {
+		return appErr
+	}
+
+	credential, err := a.Srv().Store().WebAuthnCredential().Get(credentialID)
+	if err != nil {
+		var nfErr *store.ErrNotFound
+		switch {
+		case errors.As(err, &nfErr):
+			return model.NewAppError("DeleteWebAuthnCredential", "app.webauthn_credential.get.app_error", nil, "", http.StatusNotFound).Wrap(err)
+		default:
+			return model.NewAppError("DeleteWebAuthnCredential", "app.webauthn_credential.get.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+		}
+	}
+
+	if credential.UserId != user.Id {
+		return model.NewAppError("DeleteWebAuthnCredential", "app.webauthn_credential.get.app_error", nil, "", http.StatusNotFound)
+	}
+
+	if err := a.Srv().Store().WebAuthnCredential().Delete(credential.Id); err != nil {
+		return model.NewAppError("DeleteWebAuthnCredential", "app.webauthn_credential.delete.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
+	remaining, err := a.Srv().Store().WebAuthnCredential().GetForUser(user.Id)
+	if err != nil {
+		return model.NewAppError("DeleteWebAuthnCredential", "app.webauthn_credential.get_for_user.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
+	if len(remaining) == 0 && user.MfaActive && user.MfaSecret == "" {
+		if err := a.Srv().Store().User().UpdateMfaActive(user.Id, false); err != nil {
+			return model.NewAppError("DeleteWebAuthnCredential", "mfa.deactivate.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+		}
+	}
+
+	// Make sure old MFA status is not cached locally or in cluster nodes.
+	a.InvalidateCacheForUser(user.Id)
+
+	return nil
+}
+
+func (a *App) checkUserWebAuthnAssertion(user *model.User, assertion string) *model.AppError {
+	w, appErr := a.webAuthn()
+	if appErr != nil {
+		return appErr
+	}
+
+	ok, err := w.ValidateAssertion(user, assertion)
+	if err != nil {
+		return model.NewAppError("checkUserWebAuthnAssertion", "mfa.validate_token.authenticate.app_error", nil, "", http.StatusBadRequest).Wrap(err)
+	}
+
+	if !ok {
+		return model.NewAppError("checkUserWebAuthnAssertion", "api.user.check_user_mfa.bad_code.app_error", nil, "", http.StatusUnauthorized)
+	}
+
+	return nil
+}
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS WebAuthnCredentials (
+    Id varchar(26) NOT NULL,
+    UserId varchar(26) NOT NULL,
+    Name varchar(64) NOT NULL,
+    CredentialId varchar(512) NOT NULL,
+    PublicKey text NOT NULL,
+    AttestationType varchar(32),
+    Transports varchar(256),
+    AAGUID varchar(64),
+    SignCount bigint(20) DEFAULT 0,
+    CreateAt bigint(20),
+    LastUsedAt bigint(20) DEFAULT 0,
+    PRIMARY KEY (Id),
+    UNIQUE KEY idx_webauthncredentials_credentialid_unique (CredentialId),
+    KEY idx_webauthncredentials_userid (UserId)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
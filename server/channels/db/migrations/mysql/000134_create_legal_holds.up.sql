@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS LegalHolds (
+    Id varchar(26) NOT NULL,
+    DisplayName varchar(64) NOT NULL,
+    Description text,
+    CreatorId varchar(26) NOT NULL,
+    StartAt bigint NOT NULL DEFAULT 0,
+    EndAt bigint NOT NULL DEFAULT 0,
+    CreateAt bigint NOT NULL,
+    UpdateAt bigint NOT NULL,
+    ReleasedAt bigint NOT NULL DEFAULT 0,
+    PRIMARY KEY (Id),
+    KEY IDX_LegalHolds_ReleasedAt (ReleasedAt)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS LegalHoldUsers (
+    LegalHoldId varchar(26) NOT NULL,
+    UserId varchar(26) NOT NULL,
+    PRIMARY KEY (LegalHoldId, UserId),
+    KEY IDX_LegalHoldUsers_UserId (UserId),
+    CONSTRAINT FK_LegalHoldUsers_LegalHolds FOREIGN KEY (LegalHoldId) REFERENCES LegalHolds (Id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS LegalHoldChannels (
+    LegalHoldId varchar(26) NOT NULL,
+    ChannelId varchar(26) NOT NULL,
+    PRIMARY KEY (LegalHoldId, ChannelId),
+    KEY IDX_LegalHoldChannels_ChannelId (ChannelId),
+    CONSTRAINT FK_LegalHoldChannels_LegalHolds FOREIGN KEY (LegalHoldId) REFERENCES LegalHolds (Id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS BatchedEmailNotifications (
+    Id varchar(26) NOT NULL,
+    UserId varchar(26) NOT NULL,
+    PostId varchar(26) NOT NULL,
+    TeamName varchar(64) NOT NULL,
+    CreateAt bigint NOT NULL,
+    PRIMARY KEY (Id),
+    KEY IDX_BatchedEmailNotifications_UserId_CreateAt (UserId, CreateAt)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
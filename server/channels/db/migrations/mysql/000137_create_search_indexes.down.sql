@@ -0,0 +1 @@
+-- Only applicable to Postgres
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_webauthncredentials_userid;
+DROP INDEX IF EXISTS idx_webauthncredentials_credentialid_unique;
+
+DROP TABLE IF EXISTS webauthncredentials;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS webauthncredentials (
+    id varchar(26) PRIMARY KEY,
+    userid varchar(26) NOT NULL,
+    name varchar(64) NOT NULL,
+    credentialid varchar(512) NOT NULL,
+    publickey text NOT NULL,
+    attestationtype varchar(32),
+    transports varchar(256),
+    aaguid varchar(64),
+    signcount bigint DEFAULT 0,
+    createat bigint,
+    lastusedat bigint DEFAULT 0
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webauthncredentials_credentialid_unique ON webauthncredentials (credentialid);
+CREATE INDEX IF NOT EXISTS idx_webauthncredentials_userid ON webauthncredentials (userid);
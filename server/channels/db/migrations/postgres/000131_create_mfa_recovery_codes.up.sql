@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS mfarecoverycodes (
+    userid varchar(26) NOT NULL,
+    codehash varchar(64) NOT NULL,
+    createat bigint,
+    PRIMARY KEY (userid, codehash)
+);
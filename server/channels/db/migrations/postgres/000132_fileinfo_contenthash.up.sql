@@ -0,0 +1 @@
+ALTER TABLE fileinfo ADD COLUMN IF NOT EXISTS contenthash varchar(64) NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+ALTER TABLE fileinfo DROP COLUMN IF EXISTS quarantinereason;
+ALTER TABLE fileinfo DROP COLUMN IF EXISTS quarantinedat;
@@ -0,0 +1,2 @@
+ALTER TABLE fileinfo ADD COLUMN IF NOT EXISTS quarantinedat bigint NOT NULL DEFAULT 0;
+ALTER TABLE fileinfo ADD COLUMN IF NOT EXISTS quarantinereason varchar(256) NOT NULL DEFAULT '';
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_legalholdchannels_channelid;
+DROP INDEX IF EXISTS idx_legalholdusers_userid;
+DROP INDEX IF EXISTS idx_legalholds_releasedat;
+
+DROP TABLE IF EXISTS legalholdchannels;
+DROP TABLE IF EXISTS legalholdusers;
+DROP TABLE IF EXISTS legalholds;
@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS legalholds (
+    id varchar(26) PRIMARY KEY,
+    displayname varchar(64) NOT NULL,
+    description varchar(1024) NOT NULL DEFAULT '',
+    creatorid varchar(26) NOT NULL,
+    startat bigint NOT NULL DEFAULT 0,
+    endat bigint NOT NULL DEFAULT 0,
+    createat bigint NOT NULL,
+    updateat bigint NOT NULL,
+    releasedat bigint NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS legalholdusers (
+    legalholdid varchar(26) NOT NULL,
+    userid varchar(26) NOT NULL,
+    PRIMARY KEY (legalholdid, userid),
+    CONSTRAINT fk_legalholdusers_legalholds FOREIGN KEY (legalholdid) REFERENCES legalholds (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS legalholdchannels (
+    legalholdid varchar(26) NOT NULL,
+    channelid varchar(26) NOT NULL,
+    PRIMARY KEY (legalholdid, channelid),
+    CONSTRAINT fk_legalholdchannels_legalholds FOREIGN KEY (legalholdid) REFERENCES legalholds (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_legalholds_releasedat ON legalholds (releasedat);
+CREATE INDEX IF NOT EXISTS idx_legalholdusers_userid ON legalholdusers (userid);
+CREATE INDEX IF NOT EXISTS idx_legalholdchannels_channelid ON legalholdchannels (channelid);
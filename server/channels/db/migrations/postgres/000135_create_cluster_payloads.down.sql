@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_clusterpayloads_createat;
+
+DROP TABLE IF EXISTS clusterpayloads;
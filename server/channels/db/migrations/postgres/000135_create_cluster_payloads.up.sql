@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS clusterpayloads (
+    id varchar(26) PRIMARY KEY,
+    data bytea NOT NULL,
+    createat bigint NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_clusterpayloads_createat ON clusterpayloads (createat);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_batchedemailnotifications_userid_createat;
+
+DROP TABLE IF EXISTS batchedemailnotifications;
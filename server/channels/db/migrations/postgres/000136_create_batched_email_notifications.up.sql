@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS batchedemailnotifications (
+    id varchar(26) PRIMARY KEY,
+    userid varchar(26) NOT NULL,
+    postid varchar(26) NOT NULL,
+    teamname varchar(64) NOT NULL,
+    createat bigint NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_batchedemailnotifications_userid_createat ON batchedemailnotifications (userid, createat);
@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_filesearchindex_postid;
+DROP INDEX IF EXISTS idx_filesearchindex_creatorid;
+DROP INDEX IF EXISTS idx_filesearchindex_channelid_createat;
+DROP INDEX IF EXISTS idx_filesearchindex_content;
+
+DROP TABLE IF EXISTS filesearchindex;
+
+DROP INDEX IF EXISTS idx_postsearchindex_userid;
+DROP INDEX IF EXISTS idx_postsearchindex_channelid_createat;
+DROP INDEX IF EXISTS idx_postsearchindex_hashtags;
+DROP INDEX IF EXISTS idx_postsearchindex_message;
+
+DROP TABLE IF EXISTS postsearchindex;
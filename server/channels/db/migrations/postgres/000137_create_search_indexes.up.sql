@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS postsearchindex (
+    postid varchar(26) PRIMARY KEY,
+    teamid varchar(26) NOT NULL,
+    channelid varchar(26) NOT NULL,
+    userid varchar(26) NOT NULL,
+    type varchar(26) NOT NULL,
+    createat bigint NOT NULL,
+    hashtags text[] NOT NULL,
+    message tsvector NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_postsearchindex_message ON postsearchindex USING gin (message);
+CREATE INDEX IF NOT EXISTS idx_postsearchindex_hashtags ON postsearchindex USING gin (hashtags);
+CREATE INDEX IF NOT EXISTS idx_postsearchindex_channelid_createat ON postsearchindex (channelid, createat);
+CREATE INDEX IF NOT EXISTS idx_postsearchindex_userid ON postsearchindex (userid);
+
+CREATE TABLE IF NOT EXISTS filesearchindex (
+    fileid varchar(26) PRIMARY KEY,
+    postid varchar(26) NOT NULL,
+    channelid varchar(26) NOT NULL,
+    creatorid varchar(26) NOT NULL,
+    extension varchar(64) NOT NULL,
+    createat bigint NOT NULL,
+    content tsvector NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_filesearchindex_content ON filesearchindex USING gin (content);
+CREATE INDEX IF NOT EXISTS idx_filesearchindex_channelid_createat ON filesearchindex (channelid, createat);
+CREATE INDEX IF NOT EXISTS idx_filesearchindex_creatorid ON filesearchindex (creatorid);
+CREATE INDEX IF NOT EXISTS idx_filesearchindex_postid ON filesearchindex (postid);
@@ -0,0 +1,22 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package antivirus_rescan
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/v8/channels/jobs"
+)
+
+// The job only rescans the files when the signatures have changed since the
+// last run, so checking frequently is cheap.
+const schedFreq = 1 * time.Hour
+
+func MakeScheduler(jobServer *jobs.JobServer) *jobs.PeriodicScheduler {
+	isEnabled := func(cfg *model.Config) bool {
+		return *cfg.FileSettings.EnableAntivirusScan
+	}
+	return jobs.NewPeriodicScheduler(jobServer, model.JobTypeAntivirusRescan, schedFreq, isEnabled)
+}
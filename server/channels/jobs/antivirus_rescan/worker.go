@@ -0,0 +1,153 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package antivirus_rescan
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/channels/jobs"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+	"github.com/mattermost/mattermost/server/v8/platform/shared/antivirus"
+)
+
+const batchSize = 100
+
+type AppIface interface {
+	AntivirusScanner() antivirus.Scanner
+	ScanFile(rctx request.CTX, scanner antivirus.Scanner, info *model.FileInfo) (string, *model.AppError)
+	NotifyAdminsOfQuarantinedFiles(rctx request.CTX, infos []*model.FileInfo)
+}
+
+// MakeWorker returns a worker that scans the stored files again whenever the
+// antivirus signatures have been updated, quarantining the files detected by
+// the new signatures. Setting "force" to "true" in the job data rescans the
+// files regardless of the signature version.
+func MakeWorker(jobServer *jobs.JobServer, store store.Store, app AppIface) *jobs.SimpleWorker {
+	const workerName = "AntivirusRescan"
+
+	isEnabled := func(cfg *model.Config) bool {
+		return *cfg.FileSettings.EnableAntivirusScan
+	}
+	execute := func(logger mlog.LoggerIFace, job *model.Job) error {
+		defer jobServer.HandleJobPanic(logger, job)
+
+		scanner := app.AntivirusScanner()
+		if scanner == nil {
+			return errors.New("antivirus scanning is disabled")
+		}
+
+		rctx := request.EmptyContext(logger)
+		version, err := scanner.SignatureVersion(rctx.Context())
+		if err != nil {
+			return err
+		}
+
+		if job.Data == nil {
+			job.Data = make(model.StringMap)
+		}
+		job.Data["signature_version"] = version
+
+		if job.Data["force"] != "true" {
+			if system, sErr := store.System().GetByName(model.SystemAntivirusSignatureVersion); sErr == nil && system.Value == version {
+				logger.Debug("Antivirus signatures unchanged, skipping the rescan", mlog.String("signature_version", version))
+				return nil
+			}
+		}
+
+		total, err := store.FileInfo().CountAll()
+		if err != nil {
+			return err
+		}
+
+		var (
+			nScanned, nErrs int
+			quarantined     []*model.FileInfo
+			startTime       int64
+			startID         string
+		)
+		// Deduplicated files sharing a blob only need to be scanned once.
+		results := make(map[string]string)
+		for {
+			files, err := store.FileInfo().GetFilesBatchForIndexing(startTime, startID, false, batchSize)
+			if err != nil {
+				return err
+			}
+			if len(files) == 0 {
+				break
+			}
+			startTime = files[len(files)-1].CreateAt
+			startID = files[len(files)-1].Id
+
+			for _, file := range files {
+				info := &file.FileInfo
+				// Files uploaded for an import are not attachments.
+				if info.Path == "" || info.IsQuarantined() || (info.PostId == "" && file.ChannelId == "") {
+					continue
+				}
+
+				var signature string
+				cached := false
+				if info.ContentHash != "" {
+					signature, cached = results[info.ContentHash]
+				}
+				if !cached {
+					var appErr *model.AppError
+					signature, appErr = app.ScanFile(rctx, scanner, info)
+					if appErr != nil {
+						logger.Warn("Failed to scan file", mlog.String("file_id", info.Id), mlog.String("path", info.Path), mlog.Err(appErr))
+						nErrs++
+						continue
+					}
+					if info.ContentHash != "" {
+						results[info.ContentHash] = signature
+					}
+				}
+				nScanned++
+
+				if signature == "" {
+					continue
+				}
+
+				logger.Warn("Stored file flagged by the antivirus scan", mlog.String("file_id", info.Id), mlog.String("path", info.Path), mlog.String("signature", signature))
+				if err := store.FileInfo().Quarantine(rctx, info.Id, signature); err != nil {
+					logger.Error("Failed to quarantine file", mlog.String("file_id", info.Id), mlog.Err(err))
+					nErrs++
+					continue
+				}
+				store.FileInfo().InvalidateFileInfosForPostCache(info.PostId, false)
+
+				info.QuarantineReason = signature
+				quarantined = append(quarantined, info)
+			}
+
+			if total > 0 {
+				if err := jobServer.SetJobProgress(job, min(int64(nScanned+nErrs)*100/total, 99)); err != nil {
+					logger.Error("Worker: Failed to set job progress", mlog.Err(err))
+				}
+			}
+		}
+
+		app.NotifyAdminsOfQuarantinedFiles(rctx, quarantined)
+
+		job.Data["scanned"] = strconv.Itoa(nScanned)
+		job.Data["quarantined"] = strconv.Itoa(len(quarantined))
+		job.Data["errors"] = strconv.Itoa(nErrs)
+		if err := jobServer.UpdateInProgressJobData(job); err != nil {
+			logger.Error("Worker: Failed to update job data", mlog.Err(err))
+		}
+
+		if nErrs > 0 {
+			return errors.New("some files could not be scanned, they will be scanned again on the next run")
+		}
+
+		// The version is only recorded once every file was scanned with it.
+		return store.System().SaveOrUpdate(&model.System{Name: model.SystemAntivirusSignatureVersion, Value: version})
+	}
+	worker := jobs.NewSimpleWorker(workerName, jobServer, execute, isEnabled)
+	return worker
+}
@@ -0,0 +1,37 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package data_retention
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/v8/channels/jobs"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+)
+
+func MakeScheduler(jobServer *jobs.JobServer, store store.Store) *jobs.DailyScheduler {
+	startTime := func(cfg *model.Config) *time.Time {
+		parsedTime, err := time.Parse("15:04", *cfg.DataRetentionSettings.DeletionJobStartTime)
+		if err == nil {
+			return &parsedTime
+		}
+		return nil
+	}
+	isEnabled := func(cfg *model.Config) bool {
+		if *cfg.DataRetentionSettings.EnableMessageDeletion || *cfg.DataRetentionSettings.EnableFileDeletion {
+			return true
+		}
+
+		// The granular policies are enforced even when the global policy is disabled.
+		count, err := store.RetentionPolicy().GetCount()
+		if err != nil {
+			jobServer.Logger().Warn("Failed to count the data retention policies", mlog.Err(err))
+			return false
+		}
+		return count > 0
+	}
+	return jobs.NewDailyScheduler(jobServer, model.JobTypeDataRetention, startTime, isEnabled)
+}
@@ -0,0 +1,293 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package data_retention
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/channels/jobs"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+)
+
+type AppIface interface {
+	RemoveFilesFromFileStore(rctx request.CTX, fileInfos []*model.FileInfo)
+	RemoveUnreferencedFileBlobs(rctx request.CTX, fileInfos []*model.FileInfo)
+}
+
+// MakeWorker returns a worker enforcing the global retention policy of the
+// DataRetentionSettings and the team and channel retention policies. Setting
+// "dry_run" to "true" in the job data only counts the records to delete.
+func MakeWorker(jobServer *jobs.JobServer, store store.Store, app AppIface) *jobs.SimpleWorker {
+	const workerName = "DataRetention"
+
+	isEnabled := func(_ *model.Config) bool {
+		return true
+	}
+	execute := func(logger mlog.LoggerIFace, job *model.Job) error {
+		defer jobServer.HandleJobPanic(logger, job)
+
+		r := &retention{
+			store:    store,
+			app:      app,
+			rctx:     request.EmptyContext(logger),
+			settings: jobServer.Config().DataRetentionSettings,
+			now:      model.GetMillis(),
+			setProgress: func(progress int64) {
+				if err := jobServer.SetJobProgress(job, progress); err != nil {
+					logger.Error("Worker: Failed to set job progress", mlog.Err(err))
+				}
+			},
+		}
+
+		counts, err := r.count()
+		if err != nil {
+			return err
+		}
+
+		if job.Data == nil {
+			job.Data = make(model.StringMap)
+		}
+		job.Data["posts_to_delete"] = strconv.FormatInt(counts.posts, 10)
+		job.Data["post_files_to_delete"] = strconv.FormatInt(counts.postFiles, 10)
+		job.Data["expired_files_to_delete"] = strconv.FormatInt(counts.expiredFiles, 10)
+
+		if job.Data["dry_run"] != "true" {
+			result, runErr := r.run(counts)
+			job.Data["posts_deleted"] = strconv.FormatInt(result.posts, 10)
+			job.Data["reactions_deleted"] = strconv.FormatInt(result.reactions, 10)
+			job.Data["files_deleted"] = strconv.FormatInt(result.files, 10)
+			err = runErr
+		}
+
+		if appErr := jobServer.UpdateInProgressJobData(job); appErr != nil {
+			logger.Error("Worker: Failed to update job data", mlog.Err(appErr))
+		}
+
+		return err
+	}
+	worker := jobs.NewSimpleWorker(workerName, jobServer, execute, isEnabled)
+	return worker
+}
+
+type retentionCounts struct {
+	posts        int64
+	postFiles    int64
+	expiredFiles int64
+}
+
+type retentionResult struct {
+	posts     int64
+	reactions int64
+	files     int64
+}
+
+type retention struct {
+	store       store.Store
+	app         AppIface
+	rctx        request.CTX
+	settings    model.DataRetentionSettings
+	now         int64
+	setProgress func(progress int64)
+
+	total     int64
+	processed int64
+}
+
+// messageEndTime returns the time before which the global policy deletes the
+// posts, or 0 when message deletion is disabled.
+func (r *retention) messageEndTime() int64 {
+	if !*r.settings.EnableMessageDeletion {
+		return 0
+	}
+	return r.now - int64(r.settings.GetMessageRetentionHours())*time.Hour.Milliseconds()
+}
+
+// fileEndTime returns the time before which the global policy deletes the
+// files, or 0 when file deletion is disabled.
+func (r *retention) fileEndTime() int64 {
+	if !*r.settings.EnableFileDeletion {
+		return 0
+	}
+	return r.now - int64(r.settings.GetFileRetentionHours())*time.Hour.Milliseconds()
+}
+
+func (r *retention) count() (*retentionCounts, error) {
+	var (
+		counts retentionCounts
+		err    error
+	)
+
+	if counts.posts, err = r.store.Post().CountForRetentionPolicies(r.now, r.messageEndTime()); err != nil {
+		return nil, errors.Wrap(err, "failed to count the posts to delete")
+	}
+	if counts.postFiles, err = r.store.FileInfo().CountForRetentionPolicies(r.now, r.messageEndTime()); err != nil {
+		return nil, errors.Wrap(err, "failed to count the files attached to the posts to delete")
+	}
+	if fileEndTime := r.fileEndTime(); fileEndTime > 0 {
+		if counts.expiredFiles, err = r.store.FileInfo().CountForRetention(fileEndTime); err != nil {
+			return nil, errors.Wrap(err, "failed to count the files to delete")
+		}
+	}
+
+	return &counts, nil
+}
+
+func (r *retention) sleep() {
+	time.Sleep(time.Duration(*r.settings.TimeBetweenBatchesMilliseconds) * time.Millisecond)
+}
+
+func (r *retention) progress(processed int64) {
+	if r.total == 0 {
+		return
+	}
+	r.processed += processed
+	r.setProgress(min(r.processed*100/r.total, 100))
+}
+
+// run deletes the posts and files in the scope of the retention policies. The
+// result holds the records deleted before an error occurred.
+func (r *retention) run(counts *retentionCounts) (*retentionResult, error) {
+	r.total = counts.posts + counts.postFiles + counts.expiredFiles
+	result := &retentionResult{}
+
+	// Deleting the posts records their ids so that the reactions and files
+	// attached to them are deleted afterwards, even if the job is interrupted.
+	var err error
+	if result.posts, err = r.deleteBatches(r.store.Post().PermanentDeleteBatchForRetentionPolicies, true); err != nil {
+		return result, errors.Wrap(err, "failed to delete posts")
+	}
+	if _, err = r.deleteBatches(r.store.Thread().PermanentDeleteBatchForRetentionPolicies, false); err != nil {
+		return result, errors.Wrap(err, "failed to delete threads")
+	}
+	if _, err = r.deleteBatches(r.store.Thread().PermanentDeleteBatchThreadMembershipsForRetentionPolicies, false); err != nil {
+		return result, errors.Wrap(err, "failed to delete thread memberships")
+	}
+
+	if err = r.deleteOrphanedRows(result); err != nil {
+		return result, err
+	}
+
+	if err = r.deleteExpiredFiles(result); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (r *retention) deleteBatches(deleteBatch func(now, globalPolicyEndTime, limit int64, cursor model.RetentionPolicyCursor) (int64, model.RetentionPolicyCursor, error), trackProgress bool) (int64, error) {
+	var (
+		cursor model.RetentionPolicyCursor
+		total  int64
+	)
+	for {
+		deleted, nextCursor, err := deleteBatch(r.now, r.messageEndTime(), int64(*r.settings.BatchSize), cursor)
+		if err != nil {
+			return total, err
+		}
+		cursor = nextCursor
+		total += deleted
+		if trackProgress {
+			r.progress(deleted)
+		}
+
+		if cursor.ChannelPoliciesDone && cursor.TeamPoliciesDone && cursor.GlobalPoliciesDone {
+			return total, nil
+		}
+		r.sleep()
+	}
+}
+
+// deleteOrphanedRows deletes the reactions and files attached to the deleted posts.
+func (r *retention) deleteOrphanedRows(result *retentionResult) error {
+	for {
+		rows, err := r.store.RetentionPolicy().GetIdsForDeletionByTableName("Posts", *r.settings.RetentionIdsBatchSize)
+		if err != nil {
+			return errors.Wrap(err, "failed to get the ids of the deleted posts")
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		for _, row := range rows {
+			infos, err := r.store.FileInfo().GetByPostIds(row.Ids)
+			if err != nil {
+				return errors.Wrap(err, "failed to get the files of the deleted posts")
+			}
+
+			deleted, err := r.deleteFiles(infos)
+			result.files += deleted
+			if err != nil {
+				return err
+			}
+
+			// Deleting the reactions also deletes the row.
+			deleted, err = r.store.Reaction().DeleteOrphanedRowsByIds(row)
+			if err != nil {
+				return errors.Wrap(err, "failed to delete the reactions of the deleted posts")
+			}
+			result.reactions += deleted
+		}
+		r.sleep()
+	}
+}
+
+// deleteExpiredFiles deletes the files older than the global file retention period.
+func (r *retention) deleteExpiredFiles(result *retentionResult) error {
+	fileEndTime := r.fileEndTime()
+	if fileEndTime <= 0 {
+		return nil
+	}
+
+	for {
+		infos, err := r.store.FileInfo().GetBatchForRetention(fileEndTime, *r.settings.BatchSize)
+		if err != nil {
+			return errors.Wrap(err, "failed to get the files to delete")
+		}
+		if len(infos) == 0 {
+			return nil
+		}
+
+		deleted, err := r.deleteFiles(infos)
+		result.files += deleted
+		if err != nil {
+			return err
+		}
+
+		invalidated := make(map[string]bool)
+		for _, info := range infos {
+			if info.PostId != "" && !invalidated[info.PostId] {
+				invalidated[info.PostId] = true
+				r.store.FileInfo().InvalidateFileInfosForPostCache(info.PostId, info.DeleteAt != 0)
+			}
+		}
+		r.sleep()
+	}
+}
+
+func (r *retention) deleteFiles(infos []*model.FileInfo) (int64, error) {
+	if len(infos) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]string, 0, len(infos))
+	for _, info := range infos {
+		ids = append(ids, info.Id)
+	}
+
+	r.app.RemoveFilesFromFileStore(r.rctx, infos)
+	deleted, err := r.store.FileInfo().PermanentDeleteByIds(r.rctx, ids)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to delete files")
+	}
+	r.app.RemoveUnreferencedFileBlobs(r.rctx, infos)
+	r.progress(deleted)
+
+	return deleted, nil
+}
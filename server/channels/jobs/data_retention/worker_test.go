@@ -0,0 +1,132 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package data_retention
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/channels/store/storetest"
+)
+
+type fakeApp struct {
+	removed []*model.FileInfo
+	blobs   []*model.FileInfo
+}
+
+func (a *fakeApp) RemoveFilesFromFileStore(_ request.CTX, fileInfos []*model.FileInfo) {
+	a.removed = append(a.removed, fileInfos...)
+}
+
+func (a *fakeApp) RemoveUnreferencedFileBlobs(_ request.CTX, fileInfos []*model.FileInfo) {
+	a.blobs = append(a.blobs, fileInfos...)
+}
+
+func TestRetention(t *testing.T) {
+	now := model.GetMillis()
+	hour := time.Hour.Milliseconds()
+
+	newRetention := func(mockStore *storetest.Store, app *fakeApp, enableMessageDeletion, enableFileDeletion bool) (*retention, *[]int64) {
+		settings := model.DataRetentionSettings{}
+		settings.SetDefaults()
+		*settings.EnableMessageDeletion = enableMessageDeletion
+		*settings.EnableFileDeletion = enableFileDeletion
+		*settings.MessageRetentionHours = 10
+		*settings.FileRetentionHours = 5
+		*settings.TimeBetweenBatchesMilliseconds = 0
+
+		var progress []int64
+		return &retention{
+			store:    mockStore,
+			app:      app,
+			rctx:     request.TestContext(t),
+			settings: settings,
+			now:      now,
+			setProgress: func(p int64) {
+				progress = append(progress, p)
+			},
+		}, &progress
+	}
+
+	t.Run("count", func(t *testing.T) {
+		mockStore := &storetest.Store{}
+		t.Cleanup(func() {
+			mockStore.AssertExpectations(t)
+		})
+		r, _ := newRetention(mockStore, &fakeApp{}, true, true)
+
+		mockStore.PostStore.On("CountForRetentionPolicies", now, now-10*hour).Return(int64(4), nil)
+		mockStore.FileInfoStore.On("CountForRetentionPolicies", now, now-10*hour).Return(int64(2), nil)
+		mockStore.FileInfoStore.On("CountForRetention", now-5*hour).Return(int64(3), nil)
+
+		counts, err := r.count()
+		require.NoError(t, err)
+		assert.Equal(t, &retentionCounts{posts: 4, postFiles: 2, expiredFiles: 3}, counts)
+	})
+
+	t.Run("count with the global policy disabled", func(t *testing.T) {
+		mockStore := &storetest.Store{}
+		t.Cleanup(func() {
+			mockStore.AssertExpectations(t)
+		})
+		r, _ := newRetention(mockStore, &fakeApp{}, false, false)
+
+		mockStore.PostStore.On("CountForRetentionPolicies", now, int64(0)).Return(int64(1), nil)
+		mockStore.FileInfoStore.On("CountForRetentionPolicies", now, int64(0)).Return(int64(0), nil)
+
+		counts, err := r.count()
+		require.NoError(t, err)
+		assert.Equal(t, &retentionCounts{posts: 1}, counts)
+	})
+
+	t.Run("run", func(t *testing.T) {
+		mockStore := &storetest.Store{}
+		t.Cleanup(func() {
+			mockStore.AssertExpectations(t)
+		})
+		app := &fakeApp{}
+		r, progress := newRetention(mockStore, app, true, true)
+		batchSize := int64(*r.settings.BatchSize)
+
+		done := model.RetentionPolicyCursor{ChannelPoliciesDone: true, TeamPoliciesDone: true, GlobalPoliciesDone: true}
+		mockStore.PostStore.On("PermanentDeleteBatchForRetentionPolicies", now, now-10*hour, batchSize, model.RetentionPolicyCursor{}).
+			Return(int64(2), model.RetentionPolicyCursor{ChannelPoliciesDone: true}, nil).Once()
+		mockStore.PostStore.On("PermanentDeleteBatchForRetentionPolicies", now, now-10*hour, batchSize, model.RetentionPolicyCursor{ChannelPoliciesDone: true}).
+			Return(int64(1), done, nil).Once()
+		mockStore.ThreadStore.On("PermanentDeleteBatchForRetentionPolicies", now, now-10*hour, batchSize, model.RetentionPolicyCursor{}).
+			Return(int64(1), done, nil).Once()
+		mockStore.ThreadStore.On("PermanentDeleteBatchThreadMembershipsForRetentionPolicies", now, now-10*hour, batchSize, model.RetentionPolicyCursor{}).
+			Return(int64(1), done, nil).Once()
+
+		row := &model.RetentionIdsForDeletion{Id: "row", TableName: "Posts", Ids: []string{"post1", "post2", "post3"}}
+		attached := []*model.FileInfo{{Id: "file1", PostId: "post1", Path: "file1.txt"}}
+		mockStore.RetentionPolicyStore.On("GetIdsForDeletionByTableName", "Posts", *r.settings.RetentionIdsBatchSize).
+			Return([]*model.RetentionIdsForDeletion{row}, nil).Once()
+		mockStore.RetentionPolicyStore.On("GetIdsForDeletionByTableName", "Posts", *r.settings.RetentionIdsBatchSize).
+			Return([]*model.RetentionIdsForDeletion{}, nil).Once()
+		mockStore.FileInfoStore.On("GetByPostIds", row.Ids).Return(attached, nil).Once()
+		mockStore.FileInfoStore.On("PermanentDeleteByIds", mock.Anything, []string{"file1"}).Return(int64(1), nil).Once()
+		mockStore.ReactionStore.On("DeleteOrphanedRowsByIds", row).Return(int64(5), nil).Once()
+
+		expired := []*model.FileInfo{{Id: "file2", PostId: "post4", Path: "file2.txt"}}
+		mockStore.FileInfoStore.On("GetBatchForRetention", now-5*hour, *r.settings.BatchSize).Return(expired, nil).Once()
+		mockStore.FileInfoStore.On("GetBatchForRetention", now-5*hour, *r.settings.BatchSize).Return([]*model.FileInfo{}, nil).Once()
+		mockStore.FileInfoStore.On("PermanentDeleteByIds", mock.Anything, []string{"file2"}).Return(int64(1), nil).Once()
+		mockStore.FileInfoStore.On("InvalidateFileInfosForPostCache", "post4", false).Return().Once()
+
+		result, err := r.run(&retentionCounts{posts: 3, postFiles: 1, expiredFiles: 1})
+		require.NoError(t, err)
+		assert.Equal(t, &retentionResult{posts: 3, reactions: 5, files: 2}, result)
+
+		assert.Equal(t, append(attached, expired...), app.removed)
+		assert.Equal(t, append(attached, expired...), app.blobs)
+		assert.Equal(t, []int64{40, 60, 80, 100}, *progress)
+	})
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package email_batching
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/channels/jobs"
+)
+
+type Scheduler struct {
+	*jobs.PeriodicScheduler
+	jobServer *jobs.JobServer
+}
+
+func (scheduler *Scheduler) NextScheduleTime(cfg *model.Config, _ time.Time, _ bool, _ *model.Job) *time.Time {
+	nextTime := time.Now().Add(time.Duration(*cfg.EmailSettings.EmailBatchingInterval) * time.Second)
+	return &nextTime
+}
+
+func (scheduler *Scheduler) ScheduleJob(c request.CTX, cfg *model.Config, pendingJobs bool, lastSuccessfulJob *model.Job) (*model.Job, *model.AppError) {
+	// The notifications of a batch are deleted once sent, so two jobs running at the
+	// same time on different nodes could send the same notifications twice.
+	if pendingJobs {
+		return nil, nil
+	}
+	count, err := scheduler.jobServer.Store.Job().GetCountByStatusAndType(model.JobStatusInProgress, model.JobTypeEmailBatching)
+	if err != nil {
+		return nil, model.NewAppError("ScheduleJob", "app.job.get_count_by_status_and_type.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	if count > 0 {
+		return nil, nil
+	}
+
+	// There is no need for a job when there are no notifications to send.
+	pending, nErr := scheduler.jobServer.Store.BatchedEmailNotification().Count()
+	if nErr != nil {
+		return nil, model.NewAppError("ScheduleJob", "jobs.email_batching.count_notifications.app_error", nil, "", http.StatusInternalServerError).Wrap(nErr)
+	}
+	if pending == 0 {
+		return nil, nil
+	}
+
+	return scheduler.PeriodicScheduler.ScheduleJob(c, cfg, pendingJobs, lastSuccessfulJob)
+}
+
+func MakeScheduler(jobServer *jobs.JobServer) *Scheduler {
+	enabledFunc := func(cfg *model.Config) bool {
+		return *cfg.EmailSettings.EnableEmailBatching
+	}
+	return &Scheduler{
+		PeriodicScheduler: jobs.NewPeriodicScheduler(jobServer, model.JobTypeEmailBatching, 0, enabledFunc),
+		jobServer:         jobServer,
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package file_deduplication_migration
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/channels/jobs"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+	"github.com/mattermost/mattermost/server/v8/platform/shared/filestore"
+)
+
+const (
+	timeBetweenBatches = 1 * time.Second
+	batchSize          = 100
+)
+
+type AppIface interface {
+	jobs.BatchMigrationWorkerAppIFace
+	FileBackend() filestore.FileBackend
+}
+
+// MakeWorker creates a batch migration worker moving the content of the files
+// uploaded before deduplication was enabled to content addressed blobs.
+func MakeWorker(jobServer *jobs.JobServer, ss store.Store, app AppIface) model.Worker {
+	rctx := request.EmptyContext(jobServer.Logger())
+	return jobs.MakeBatchMigrationWorker(
+		jobServer,
+		ss,
+		app,
+		model.MigrationKeyFileDeduplication,
+		timeBetweenBatches,
+		func(data model.StringMap, store store.Store) (model.StringMap, bool, error) {
+			return doFileDeduplicationMigrationBatch(rctx, data, store, app.FileBackend())
+		},
+	)
+}
+
+// doFileDeduplicationMigrationBatch deduplicates the content of a batch of
+// FileInfos keyed by their id. The FileInfos sharing a path, such as the copies
+// made when a post is forwarded, may span several batches: the file at the
+// path is only removed once none of them references it anymore.
+func doFileDeduplicationMigrationBatch(rctx request.CTX, data model.StringMap, store store.Store, backend filestore.FileBackend) (model.StringMap, bool, error) {
+	infos, err := store.FileInfo().GetFilesBatchForDeduplication(data["file_id"], batchSize)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "failed to get the next batch (file_id=%v)", data["file_id"])
+	}
+
+	if len(infos) == 0 {
+		return nil, true, nil
+	}
+
+	var paths []string
+	idsByPath := make(map[string][]string)
+	for _, info := range infos {
+		if _, ok := idsByPath[info.Path]; !ok {
+			paths = append(paths, info.Path)
+		}
+		idsByPath[info.Path] = append(idsByPath[info.Path], info.Id)
+	}
+
+	for _, path := range paths {
+		exists, err := backend.FileExists(path)
+		if err != nil {
+			return nil, false, errors.Wrapf(err, "failed to check if file %s exists", path)
+		}
+		if !exists {
+			continue
+		}
+
+		hash, err := filestore.HashFile(backend, path)
+		if err != nil {
+			return nil, false, errors.Wrapf(err, "failed to hash file %s", path)
+		}
+
+		// The file is copied rather than moved so that it remains readable
+		// until no FileInfo references it anymore.
+		blobPath := filestore.BlobPath(hash)
+		blobExists, err := backend.FileExists(blobPath)
+		if err != nil {
+			return nil, false, errors.Wrapf(err, "failed to check if blob %s exists", blobPath)
+		}
+		if !blobExists {
+			if err := backend.CopyFile(path, blobPath); err != nil {
+				return nil, false, errors.Wrapf(err, "failed to copy file %s to blob", path)
+			}
+		}
+
+		if err := store.FileInfo().UpdateContentHash(rctx, idsByPath[path], blobPath, hash); err != nil {
+			return nil, false, errors.Wrapf(err, "failed to update FileInfos stored at %s", path)
+		}
+
+		remaining, err := store.FileInfo().CountByPath(path)
+		if err != nil {
+			return nil, false, errors.Wrapf(err, "failed to count FileInfos stored at %s", path)
+		}
+		if remaining > 0 {
+			continue
+		}
+
+		if err := filestore.CommitBlob(backend, path, hash); err != nil {
+			return nil, false, errors.Wrapf(err, "failed to remove deduplicated file %s", path)
+		}
+	}
+
+	// The cached FileInfos of the posts still point to the previous paths.
+	store.FileInfo().ClearCaches()
+
+	return model.StringMap{"file_id": infos[len(infos)-1].Id}, false, nil
+}
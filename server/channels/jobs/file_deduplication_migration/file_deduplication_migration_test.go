@@ -0,0 +1,114 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package file_deduplication_migration
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/channels/store/storetest"
+	"github.com/mattermost/mattermost/server/v8/platform/shared/filestore"
+)
+
+func TestDoFileDeduplicationMigrationBatch(t *testing.T) {
+	rctx := request.TestContext(t)
+
+	newBackend := func(t *testing.T) filestore.FileBackend {
+		backend, err := filestore.NewFileBackend(filestore.FileBackendSettings{
+			DriverName: model.ImageDriverLocal,
+			Directory:  t.TempDir(),
+		})
+		require.NoError(t, err)
+		return backend
+	}
+
+	t.Run("failure getting batch", func(t *testing.T) {
+		mockStore := &storetest.Store{}
+		t.Cleanup(func() {
+			mockStore.AssertExpectations(t)
+		})
+
+		mockStore.FileInfoStore.On("GetFilesBatchForDeduplication", "file_id_1", batchSize).Return(nil, errors.New("failure"))
+
+		data, done, err := doFileDeduplicationMigrationBatch(rctx, model.StringMap{"file_id": "file_id_1"}, mockStore, newBackend(t))
+		require.EqualError(t, err, "failed to get the next batch (file_id=file_id_1): failure")
+		assert.False(t, done)
+		assert.Nil(t, data)
+	})
+
+	t.Run("done", func(t *testing.T) {
+		mockStore := &storetest.Store{}
+		t.Cleanup(func() {
+			mockStore.AssertExpectations(t)
+		})
+
+		mockStore.FileInfoStore.On("GetFilesBatchForDeduplication", "", batchSize).Return([]*model.FileInfo{}, nil)
+
+		data, done, err := doFileDeduplicationMigrationBatch(rctx, nil, mockStore, newBackend(t))
+		require.NoError(t, err)
+		assert.True(t, done)
+		assert.Nil(t, data)
+	})
+
+	t.Run("deduplicate batch", func(t *testing.T) {
+		mockStore := &storetest.Store{}
+		t.Cleanup(func() {
+			mockStore.AssertExpectations(t)
+		})
+		backend := newBackend(t)
+
+		content := []byte("identical content")
+		hash, err := filestore.HashContent(bytes.NewReader(content))
+		require.NoError(t, err)
+		blobPath := filestore.BlobPath(hash)
+
+		for _, path := range []string{"a/file.txt", "b/file.txt", "d/file.txt"} {
+			_, err = backend.WriteFile(bytes.NewReader(content), path)
+			require.NoError(t, err)
+		}
+
+		infos := []*model.FileInfo{
+			{Id: "file_id_1", Path: "a/file.txt"},
+			{Id: "file_id_2", Path: "a/file.txt"},
+			{Id: "file_id_3", Path: "b/file.txt"},
+			{Id: "file_id_4", Path: "c/missing.txt"},
+			{Id: "file_id_5", Path: "d/file.txt"},
+		}
+		mockStore.FileInfoStore.On("GetFilesBatchForDeduplication", "", batchSize).Return(infos, nil)
+		mockStore.FileInfoStore.On("UpdateContentHash", mock.Anything, []string{"file_id_1", "file_id_2"}, blobPath, hash).Return(nil).Once()
+		mockStore.FileInfoStore.On("UpdateContentHash", mock.Anything, []string{"file_id_3"}, blobPath, hash).Return(nil).Once()
+		mockStore.FileInfoStore.On("UpdateContentHash", mock.Anything, []string{"file_id_5"}, blobPath, hash).Return(nil).Once()
+		mockStore.FileInfoStore.On("CountByPath", "a/file.txt").Return(int64(0), nil).Once()
+		mockStore.FileInfoStore.On("CountByPath", "b/file.txt").Return(int64(0), nil).Once()
+		// A copy of d/file.txt is left for a later batch.
+		mockStore.FileInfoStore.On("CountByPath", "d/file.txt").Return(int64(1), nil).Once()
+		mockStore.FileInfoStore.On("ClearCaches").Return()
+
+		data, done, err := doFileDeduplicationMigrationBatch(rctx, model.StringMap{}, mockStore, backend)
+		require.NoError(t, err)
+		assert.False(t, done)
+		assert.Equal(t, model.StringMap{"file_id": "file_id_5"}, data)
+
+		for _, path := range []string{"a/file.txt", "b/file.txt"} {
+			exists, err := backend.FileExists(path)
+			require.NoError(t, err)
+			assert.False(t, exists)
+		}
+
+		exists, err := backend.FileExists("d/file.txt")
+		require.NoError(t, err)
+		assert.True(t, exists)
+
+		stored, err := backend.ReadFile(blobPath)
+		require.NoError(t, err)
+		assert.Equal(t, content, stored)
+	})
+}
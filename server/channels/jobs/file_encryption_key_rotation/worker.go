@@ -0,0 +1,83 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package file_encryption_key_rotation
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/configservice"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/v8/channels/jobs"
+	"github.com/mattermost/mattermost/server/v8/platform/shared/filestore"
+)
+
+// progressInterval is the number of files processed between two job progress updates.
+const progressInterval = 1000
+
+type AppIface interface {
+	configservice.ConfigService
+	FileBackend() filestore.FileBackend
+}
+
+// MakeWorker returns a worker that re-encrypts the data keys of the stored files
+// with the current master key, so that the previous keys can be removed from the
+// configuration once the job has succeeded. Files stored before encryption was
+// enabled are encrypted as well.
+func MakeWorker(jobServer *jobs.JobServer, app AppIface) *jobs.SimpleWorker {
+	const workerName = "FileEncryptionKeyRotation"
+
+	isEnabled := func(cfg *model.Config) bool {
+		return *cfg.FileSettings.EnableEncryptionAtRest
+	}
+	execute := func(logger mlog.LoggerIFace, job *model.Job) error {
+		defer jobServer.HandleJobPanic(logger, job)
+
+		backend, ok := app.FileBackend().(*filestore.EncryptedFileBackend)
+		if !ok {
+			return errors.New("the file store is not encrypted, the server must be restarted after enabling encryption at rest")
+		}
+
+		paths, err := backend.ListDirectoryRecursively("")
+		if err != nil {
+			return err
+		}
+
+		var nRewrapped, nErrs int
+		for i, path := range paths {
+			rewrapped, err := backend.RewrapFile(path)
+			if err != nil {
+				logger.Warn("Failed to re-encrypt file", mlog.String("path", path), mlog.Err(err))
+				nErrs++
+			} else if rewrapped {
+				nRewrapped++
+			}
+
+			if (i+1)%progressInterval == 0 {
+				if err := jobServer.SetJobProgress(job, int64((i+1)*100/len(paths))); err != nil {
+					logger.Error("Worker: Failed to set job progress", mlog.Err(err))
+				}
+			}
+		}
+
+		if job.Data == nil {
+			job.Data = make(model.StringMap)
+		}
+		job.Data["processed"] = strconv.Itoa(len(paths))
+		job.Data["rewrapped"] = strconv.Itoa(nRewrapped)
+		job.Data["errors"] = strconv.Itoa(nErrs)
+
+		if err := jobServer.UpdateInProgressJobData(job); err != nil {
+			logger.Error("Worker: Failed to update job data", mlog.Err(err))
+		}
+
+		if nErrs > 0 {
+			return errors.New("some files could not be re-encrypted, the previous keys are still needed")
+		}
+		return nil
+	}
+	worker := jobs.NewSimpleWorker(workerName, jobServer, execute, isEnabled)
+	return worker
+}
@@ -23,6 +23,7 @@ type AppIface interface {
 	ListDirectory(path string) ([]string, *model.AppError)
 	FileModTime(path string) (time.Time, *model.AppError)
 	RemoveFile(path string) *model.AppError
+	RemoveUnreferencedFileBlobs(rctx request.CTX, fileInfos []*model.FileInfo)
 }
 
 func MakeWorker(jobServer *jobs.JobServer, app AppIface, s store.Store) *jobs.SimpleWorker {
@@ -85,6 +86,7 @@ func MakeWorker(jobServer *jobs.JobServer, app AppIface, s store.Store) *jobs.Si
 							multipleErrors.Append(storeErr)
 							continue
 						}
+						app.RemoveUnreferencedFileBlobs(rctx, []*model.FileInfo{info})
 					}
 				}
 
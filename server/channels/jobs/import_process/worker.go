@@ -43,103 +43,109 @@ func MakeWorker(jobServer *jobs.JobServer, app AppIface) *jobs.SimpleWorker {
 	execute := func(logger mlog.LoggerIFace, job *model.Job) error {
 		defer jobServer.HandleJobPanic(logger, job)
 
-		importFileName, ok := job.Data["import_file"]
-		if !ok {
-			return model.NewAppError("ImportProcessWorker", "import_process.worker.do_job.missing_file", nil, "", http.StatusBadRequest)
-		}
+		return processImport(appContext, app, job)
+	}
+	worker := jobs.NewSimpleWorker(workerName, jobServer, execute, isEnabled)
+	return worker
+}
 
-		var importFilePath string
-		var importFileSize int64
-		var importFile filestore.ReadCloseSeeker
-		if job.Data["local_mode"] == "true" {
-			// We simply read the file from the local filesystem.
-			info, err := os.Stat(importFileName)
-			if errors.Is(err, os.ErrNotExist) {
-				return fmt.Errorf("file %s doesn't exist.", importFileName)
-			}
+// processImport imports the bulk import archive of the job, read either from the local
+// filesystem or from the file store.
+func processImport(appContext request.CTX, app AppIface, job *model.Job) error {
+	importFileName, ok := job.Data["import_file"]
+	if !ok {
+		return model.NewAppError("ImportProcessWorker", "import_process.worker.do_job.missing_file", nil, "", http.StatusBadRequest)
+	}
 
-			importFileSize = info.Size()
+	var importFilePath string
+	var importFileSize int64
+	var importFile filestore.ReadCloseSeeker
+	if job.Data["local_mode"] == "true" {
+		// We simply read the file from the local filesystem.
+		info, err := os.Stat(importFileName)
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("file %s doesn't exist.", importFileName)
+		}
 
-			importFile, err = os.Open(importFileName)
-			if err != nil {
-				return err
-			}
-			defer importFile.Close()
-		} else {
-			importFilePath = filepath.Join(*app.Config().ImportSettings.Directory, importFileName)
-			if ok, err := app.FileExists(importFilePath); err != nil {
-				return err
-			} else if !ok {
-				return model.NewAppError("ImportProcessWorker", "import_process.worker.do_job.file_exists", nil, "", http.StatusBadRequest)
-			}
+		importFileSize = info.Size()
 
-			var appErr *model.AppError
-			importFileSize, appErr = app.FileSize(importFilePath)
-			if appErr != nil {
-				return appErr
-			}
+		importFile, err = os.Open(importFileName)
+		if err != nil {
+			return err
+		}
+		defer importFile.Close()
+	} else {
+		importFilePath = filepath.Join(*app.Config().ImportSettings.Directory, importFileName)
+		if ok, err := app.FileExists(importFilePath); err != nil {
+			return err
+		} else if !ok {
+			return model.NewAppError("ImportProcessWorker", "import_process.worker.do_job.file_exists", nil, "", http.StatusBadRequest)
+		}
 
-			importFile, appErr = app.FileReader(importFilePath)
-			if appErr != nil {
-				return appErr
-			}
-			defer importFile.Close()
-
-			// The import is a long running operation, try to cancel any timeouts attached to the reader.
-			type TimeoutCanceler interface{ CancelTimeout() bool }
-			if tc, ok := importFile.(TimeoutCanceler); ok {
-				if !tc.CancelTimeout() {
-					appContext.Logger().Warn("Could not cancel the timeout for the file reader. The import may fail due to a timeout.")
-				}
-			}
+		var appErr *model.AppError
+		importFileSize, appErr = app.FileSize(importFilePath)
+		if appErr != nil {
+			return appErr
 		}
 
-		importZipReader, err := zip.NewReader(importFile.(io.ReaderAt), importFileSize)
-		if err != nil {
-			return model.NewAppError("ImportProcessWorker", "import_process.worker.do_job.open_file", nil, "", http.StatusInternalServerError).Wrap(err)
+		importFile, appErr = app.FileReader(importFilePath)
+		if appErr != nil {
+			return appErr
 		}
+		defer importFile.Close()
 
-		// find JSONL import file.
-		var jsonFile io.ReadCloser
-		for _, f := range importZipReader.File {
-			if filepath.Ext(f.Name) != ".jsonl" {
-				continue
-			}
-			// avoid "zip slip"
-			if strings.Contains(f.Name, "..") {
-				return model.NewAppError("ImportProcessWorker", "import_process.worker.do_job.open_file", nil, "jsonFilePath contains path traversal", http.StatusForbidden)
+		// The import is a long running operation, try to cancel any timeouts attached to the reader.
+		type TimeoutCanceler interface{ CancelTimeout() bool }
+		if tc, ok := importFile.(TimeoutCanceler); ok {
+			if !tc.CancelTimeout() {
+				appContext.Logger().Warn("Could not cancel the timeout for the file reader. The import may fail due to a timeout.")
 			}
+		}
+	}
 
-			jsonFile, err = f.Open()
-			if err != nil {
-				return model.NewAppError("ImportProcessWorker", "import_process.worker.do_job.open_file", nil, "", http.StatusInternalServerError).Wrap(err)
-			}
+	importZipReader, err := zip.NewReader(importFile.(io.ReaderAt), importFileSize)
+	if err != nil {
+		return model.NewAppError("ImportProcessWorker", "import_process.worker.do_job.open_file", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
 
-			defer jsonFile.Close()
-			break
+	// find JSONL import file.
+	var jsonFile io.ReadCloser
+	for _, f := range importZipReader.File {
+		if filepath.Ext(f.Name) != ".jsonl" {
+			continue
 		}
-
-		if jsonFile == nil {
-			return model.NewAppError("ImportProcessWorker", "import_process.worker.do_job.missing_jsonl", nil, "jsonFile was nil", http.StatusBadRequest)
+		// avoid "zip slip"
+		if strings.Contains(f.Name, "..") {
+			return model.NewAppError("ImportProcessWorker", "import_process.worker.do_job.open_file", nil, "jsonFilePath contains path traversal", http.StatusForbidden)
 		}
 
-		extractContent := job.Data["extract_content"] == "true"
-		// do the actual import.
-		appErr, lineNumber := app.BulkImportWithPath(appContext, jsonFile, importZipReader, false, extractContent, runtime.NumCPU(), model.ExportDataDir)
-		if appErr != nil {
-			job.Data["line_number"] = strconv.Itoa(lineNumber)
-			return appErr
+		jsonFile, err = f.Open()
+		if err != nil {
+			return model.NewAppError("ImportProcessWorker", "import_process.worker.do_job.open_file", nil, "", http.StatusInternalServerError).Wrap(err)
 		}
 
-		// No need to remove the file in local mode.
-		if job.Data["local_mode"] != "true" {
-			// remove import file when done.
-			if appErr := app.RemoveFile(importFilePath); appErr != nil {
-				return appErr
-			}
+		defer jsonFile.Close()
+		break
+	}
+
+	if jsonFile == nil {
+		return model.NewAppError("ImportProcessWorker", "import_process.worker.do_job.missing_jsonl", nil, "jsonFile was nil", http.StatusBadRequest)
+	}
+
+	extractContent := job.Data["extract_content"] == "true"
+	// do the actual import.
+	appErr, lineNumber := app.BulkImportWithPath(appContext, jsonFile, importZipReader, false, extractContent, runtime.NumCPU(), model.ExportDataDir)
+	if appErr != nil {
+		job.Data["line_number"] = strconv.Itoa(lineNumber)
+		return appErr
+	}
+
+	// No need to remove the file in local mode.
+	if job.Data["local_mode"] != "true" {
+		// remove import file when done.
+		if appErr := app.RemoveFile(importFilePath); appErr != nil {
+			return appErr
 		}
-		return nil
 	}
-	worker := jobs.NewSimpleWorker(workerName, jobServer, execute, isEnabled)
-	return worker
+	return nil
 }
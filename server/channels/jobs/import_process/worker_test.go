@@ -0,0 +1,129 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package import_process
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/platform/shared/filestore"
+)
+
+type fakeApp struct {
+	cfg     *model.Config
+	backend filestore.FileBackend
+
+	imported    string
+	attachments []string
+}
+
+func (a *fakeApp) Config() *model.Config                                     { return a.cfg }
+func (a *fakeApp) AddConfigListener(func(old, current *model.Config)) string { return "" }
+func (a *fakeApp) RemoveConfigListener(string)                               {}
+func (a *fakeApp) Log() *mlog.Logger                                         { return nil }
+
+func (a *fakeApp) RemoveFile(path string) *model.AppError {
+	if err := a.backend.RemoveFile(path); err != nil {
+		return model.NewAppError("RemoveFile", "api.file.remove_file.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	return nil
+}
+
+func (a *fakeApp) FileExists(path string) (bool, *model.AppError) {
+	ok, err := a.backend.FileExists(path)
+	if err != nil {
+		return false, model.NewAppError("FileExists", "api.file.file_exists.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	return ok, nil
+}
+
+func (a *fakeApp) FileSize(path string) (int64, *model.AppError) {
+	size, err := a.backend.FileSize(path)
+	if err != nil {
+		return 0, model.NewAppError("FileSize", "api.file.file_size.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	return size, nil
+}
+
+func (a *fakeApp) FileReader(path string) (filestore.ReadCloseSeeker, *model.AppError) {
+	reader, err := a.backend.Reader(path)
+	if err != nil {
+		return nil, model.NewAppError("FileReader", "api.file.file_reader.app_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	return reader, nil
+}
+
+func (a *fakeApp) BulkImportWithPath(c request.CTX, jsonlReader io.Reader, attachmentsReader *zip.Reader, dryRun, extractContent bool, workers int, importPath string) (*model.AppError, int) {
+	data, err := io.ReadAll(jsonlReader)
+	if err != nil {
+		return model.NewAppError("BulkImportWithPath", "app.import.bulk_import.file_scan.error", nil, "", http.StatusInternalServerError).Wrap(err), 0
+	}
+	a.imported = string(data)
+	for _, f := range attachmentsReader.File {
+		a.attachments = append(a.attachments, f.Name)
+	}
+	return nil, 0
+}
+
+func TestProcessImportEncryptedFileStore(t *testing.T) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+
+	backend, err := filestore.NewFileBackend(filestore.FileBackendSettings{
+		DriverName:    model.ImageDriverLocal,
+		Directory:     t.TempDir(),
+		EncryptionKey: base64.StdEncoding.EncodeToString(key),
+	})
+	require.NoError(t, err)
+	require.IsType(t, &filestore.EncryptedFileBackend{}, backend)
+
+	cfg := &model.Config{}
+	cfg.SetDefaults()
+	app := &fakeApp{cfg: cfg, backend: backend}
+
+	// Large enough for the archive to span several encrypted segments.
+	attachment := make([]byte, 200*1024)
+	_, err = rand.Read(attachment)
+	require.NoError(t, err)
+
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	for name, content := range map[string][]byte{
+		"data/attachment.bin": attachment,
+		"import.jsonl":        []byte(`{"type":"version","version":1}`),
+	} {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store})
+		require.NoError(t, err)
+		_, err = w.Write(content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+
+	importFile := "import.zip"
+	_, err = backend.WriteFile(&archive, filepath.Join(*cfg.ImportSettings.Directory, importFile))
+	require.NoError(t, err)
+
+	job := &model.Job{Id: model.NewId(), Type: model.JobTypeImportProcess, Data: model.StringMap{"import_file": importFile}}
+	require.NoError(t, processImport(request.TestContext(t), app, job))
+
+	assert.Equal(t, `{"type":"version","version":1}`, app.imported)
+	assert.ElementsMatch(t, []string{"data/attachment.bin", "import.jsonl"}, app.attachments)
+
+	exists, err := backend.FileExists(filepath.Join(*cfg.ImportSettings.Directory, importFile))
+	require.NoError(t, err)
+	assert.False(t, exists, "the import file should be removed once imported")
+}
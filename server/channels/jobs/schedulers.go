@@ -33,6 +33,13 @@ type Schedulers struct {
 	nextRunTimes map[string]*time.Time
 }
 
+const (
+	// schedulersMinWait and schedulersMaxWait bound how long the schedulers sleep
+	// between two checks, so that jobs scheduled more often than once a minute run on time.
+	schedulersMinWait = 1 * time.Second
+	schedulersMaxWait = 1 * time.Minute
+)
+
 var (
 	ErrSchedulersNotRunning    = errors.New("job schedulers are not running")
 	ErrSchedulersRunning       = errors.New("job schedulers are running")
@@ -68,7 +75,7 @@ func (schedulers *Schedulers) Start() {
 		}
 
 		for {
-			timer := time.NewTimer(1 * time.Minute)
+			timer := time.NewTimer(schedulers.nextWait(time.Now()))
 			select {
 			case <-schedulers.stop:
 				mlog.Debug("Schedulers received stop signal.")
@@ -155,6 +162,21 @@ func (schedulers *Schedulers) setNextRunTime(cfg *model.Config, name string, now
 	mlog.Debug("Next run time for scheduler", mlog.String("scheduler_name", name), mlog.String("next_runtime", fmt.Sprintf("%v", schedulers.nextRunTimes[name])))
 }
 
+// nextWait returns how long to wait until the earliest next run time,
+// bounded by schedulersMinWait and schedulersMaxWait.
+func (schedulers *Schedulers) nextWait(now time.Time) time.Duration {
+	wait := schedulersMaxWait
+	for _, nextTime := range schedulers.nextRunTimes {
+		if nextTime != nil && nextTime.Sub(now) < wait {
+			wait = nextTime.Sub(now)
+		}
+	}
+	if wait < schedulersMinWait {
+		wait = schedulersMinWait
+	}
+	return wait
+}
+
 func (schedulers *Schedulers) scheduleJob(c request.CTX, cfg *model.Config, name string, scheduler Scheduler) (*model.Job, *model.AppError) {
 	pendingJobs, err := schedulers.jobs.CheckForPendingJobsByType(name)
 	if err != nil {
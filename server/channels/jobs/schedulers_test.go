@@ -177,3 +177,27 @@ func TestRandomDelay(t *testing.T) {
 		require.Less(t, out.Milliseconds(), c)
 	}
 }
+
+func TestSchedulersNextWait(t *testing.T) {
+	now := time.Now()
+	at := func(d time.Duration) *time.Time {
+		nextTime := now.Add(d)
+		return &nextTime
+	}
+
+	for name, tc := range map[string]struct {
+		nextRunTimes map[string]*time.Time
+		expected     time.Duration
+	}{
+		"no scheduler":            {map[string]*time.Time{}, schedulersMaxWait},
+		"disabled schedulers":     {map[string]*time.Time{"a": nil}, schedulersMaxWait},
+		"after the maximum wait":  {map[string]*time.Time{"a": at(time.Hour)}, schedulersMaxWait},
+		"earliest run time":       {map[string]*time.Time{"a": at(30 * time.Second), "b": at(10 * time.Second), "c": nil}, 10 * time.Second},
+		"run time already passed": {map[string]*time.Time{"a": at(-time.Second)}, schedulersMinWait},
+	} {
+		t.Run(name, func(t *testing.T) {
+			schedulers := &Schedulers{nextRunTimes: tc.nextRunTimes}
+			assert.Equal(t, tc.expected, schedulers.nextWait(now))
+		})
+	}
+}
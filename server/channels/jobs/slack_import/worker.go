@@ -0,0 +1,220 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package slack_import
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/configservice"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/channels/jobs"
+	"github.com/mattermost/mattermost/server/v8/platform/services/slackimport"
+	"github.com/mattermost/mattermost/server/v8/platform/shared/filestore"
+)
+
+const (
+	jobDataImportFile       = "import_file"
+	jobDataTeamId           = "team_id"
+	jobDataLocalMode        = "local_mode"
+	jobDataBotUserId        = "bot_user_id"
+	jobDataImportedChannels = "imported_channels"
+	jobDataChannelsTotal    = "channels_total"
+	jobDataChannelsImported = "channels_imported"
+	jobDataPostsImported    = "posts_imported"
+	jobDataPostsSkipped     = "posts_skipped"
+	jobDataFailedUsers      = "failed_users"
+	jobDataFailedChannels   = "failed_channels"
+)
+
+type AppIface interface {
+	configservice.ConfigService
+	RemoveFile(path string) *model.AppError
+	FileExists(path string) (bool, *model.AppError)
+	FileSize(path string) (int64, *model.AppError)
+	FileReader(path string) (filestore.ReadCloseSeeker, *model.AppError)
+	SlackImportWithOptions(c request.CTX, fileData io.ReaderAt, fileSize int64, teamID string, options slackimport.Options) (*model.AppError, *bytes.Buffer)
+}
+
+// MakeWorker returns a worker that imports a Slack export into a team. The progress of the
+// import is checkpointed in the job data after each channel, and a job importing the same
+// export into the same team resumes from the checkpoint of the last job that failed.
+func MakeWorker(jobServer *jobs.JobServer, app AppIface) *jobs.SimpleWorker {
+	const workerName = "SlackImport"
+
+	appContext := request.EmptyContext(jobServer.Logger())
+	isEnabled := func(cfg *model.Config) bool {
+		return true
+	}
+	execute := func(logger mlog.LoggerIFace, job *model.Job) error {
+		defer jobServer.HandleJobPanic(logger, job)
+
+		importFileName, ok := job.Data[jobDataImportFile]
+		if !ok {
+			return model.NewAppError("SlackImportWorker", "slack_import.worker.do_job.missing_file", nil, "", http.StatusBadRequest)
+		}
+		teamId, ok := job.Data[jobDataTeamId]
+		if !ok {
+			return model.NewAppError("SlackImportWorker", "slack_import.worker.do_job.missing_team", nil, "", http.StatusBadRequest)
+		}
+
+		var importFilePath string
+		var importFileSize int64
+		var importFile filestore.ReadCloseSeeker
+		if job.Data[jobDataLocalMode] == "true" {
+			// We simply read the file from the local filesystem.
+			info, err := os.Stat(importFileName)
+			if errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("file %s doesn't exist.", importFileName)
+			}
+
+			importFileSize = info.Size()
+
+			importFile, err = os.Open(importFileName)
+			if err != nil {
+				return err
+			}
+			defer importFile.Close()
+		} else {
+			importFilePath = filepath.Join(*app.Config().ImportSettings.Directory, importFileName)
+			if ok, err := app.FileExists(importFilePath); err != nil {
+				return err
+			} else if !ok {
+				return model.NewAppError("SlackImportWorker", "slack_import.worker.do_job.file_exists", nil, "", http.StatusBadRequest)
+			}
+
+			var appErr *model.AppError
+			importFileSize, appErr = app.FileSize(importFilePath)
+			if appErr != nil {
+				return appErr
+			}
+
+			importFile, appErr = app.FileReader(importFilePath)
+			if appErr != nil {
+				return appErr
+			}
+			defer importFile.Close()
+
+			// The import is a long running operation, try to cancel any timeouts attached to the reader.
+			type TimeoutCanceler interface{ CancelTimeout() bool }
+			if tc, ok := importFile.(TimeoutCanceler); ok {
+				if !tc.CancelTimeout() {
+					logger.Warn("Could not cancel the timeout for the file reader. The import may fail due to a timeout.")
+				}
+			}
+		}
+
+		if _, ok := job.Data[jobDataImportedChannels]; !ok {
+			if previousJob := lastFailedJob(appContext, jobServer, importFileName, teamId); previousJob != nil {
+				logger.Info("Resuming the Slack import of a previous job", mlog.String("previous_job_id", previousJob.Id))
+				job.Data[jobDataBotUserId] = previousJob.Data[jobDataBotUserId]
+				job.Data[jobDataImportedChannels] = previousJob.Data[jobDataImportedChannels]
+			}
+		}
+
+		var lastProgress slackimport.Progress
+		options := slackimport.Options{
+			Checkpoint: checkpointFromJobData(job.Data),
+			OnProgress: func(progress slackimport.Progress) {
+				lastProgress = progress
+				setProgressJobData(job.Data, progress)
+				if err := jobServer.UpdateInProgressJobData(job); err != nil {
+					logger.Error("Worker: Failed to update job data", mlog.Err(err))
+				}
+				if progress.ChannelsTotal > 0 {
+					if err := jobServer.SetJobProgress(job, int64(len(progress.ImportedChannels)*100/progress.ChannelsTotal)); err != nil {
+						logger.Error("Worker: Failed to set job progress", mlog.Err(err))
+					}
+				}
+			},
+		}
+
+		// The export is a zip archive, which is read at random offsets.
+		importFileReaderAt, ok := importFile.(io.ReaderAt)
+		if !ok {
+			return model.NewAppError("SlackImportWorker", "slack_import.worker.do_job.file_reader", nil, "", http.StatusInternalServerError)
+		}
+
+		// The import log is not kept, as it holds the passwords of the users created by the import.
+		if appErr, _ := app.SlackImportWithOptions(appContext, importFileReaderAt, importFileSize, teamId, options); appErr != nil {
+			return appErr
+		}
+
+		if len(lastProgress.FailedChannels) > 0 {
+			return model.NewAppError("SlackImportWorker", "slack_import.worker.do_job.failed_channels", map[string]any{"Channels": strings.Join(lastProgress.FailedChannels, ", ")}, "", http.StatusInternalServerError)
+		}
+
+		// No need to remove the file in local mode.
+		if job.Data[jobDataLocalMode] != "true" {
+			// remove import file when done.
+			if appErr := app.RemoveFile(importFilePath); appErr != nil {
+				return appErr
+			}
+		}
+		return nil
+	}
+	worker := jobs.NewSimpleWorker(workerName, jobServer, execute, isEnabled)
+	return worker
+}
+
+// lastFailedJob returns the most recent job that failed to import the same file into the same team.
+func lastFailedJob(c request.CTX, jobServer *jobs.JobServer, importFileName, teamId string) *model.Job {
+	var lastJob *model.Job
+	for _, status := range []string{model.JobStatusError, model.JobStatusCanceled} {
+		failedJobs, err := jobServer.GetJobsByTypeAndStatus(c, model.JobTypeSlackImport, status)
+		if err != nil {
+			c.Logger().Warn("Failed to get the previous Slack import jobs", mlog.Err(err))
+			continue
+		}
+		for _, job := range failedJobs {
+			if job.Data[jobDataImportFile] != importFileName || job.Data[jobDataTeamId] != teamId {
+				continue
+			}
+			if lastJob == nil || job.CreateAt > lastJob.CreateAt {
+				lastJob = job
+			}
+		}
+	}
+	return lastJob
+}
+
+func checkpointFromJobData(data model.StringMap) slackimport.Checkpoint {
+	checkpoint := slackimport.Checkpoint{
+		BotUserId: data[jobDataBotUserId],
+	}
+	if importedChannels := data[jobDataImportedChannels]; importedChannels != "" {
+		checkpoint.ImportedChannels = strings.Split(importedChannels, ",")
+	}
+	return checkpoint
+}
+
+func setProgressJobData(data model.StringMap, progress slackimport.Progress) {
+	data[jobDataBotUserId] = progress.BotUserId
+	data[jobDataImportedChannels] = strings.Join(progress.ImportedChannels, ",")
+	data[jobDataChannelsTotal] = strconv.Itoa(progress.ChannelsTotal)
+	data[jobDataChannelsImported] = strconv.Itoa(len(progress.ImportedChannels))
+	data[jobDataPostsImported] = strconv.Itoa(progress.PostsImported)
+	data[jobDataPostsSkipped] = strconv.Itoa(progress.PostsSkipped)
+	data[jobDataFailedUsers] = marshalList(progress.FailedUsers)
+	data[jobDataFailedChannels] = marshalList(progress.FailedChannels)
+}
+
+// marshalList encodes the list as a JSON array, as the names it holds may contain any character.
+func marshalList(list []string) string {
+	if list == nil {
+		list = []string{}
+	}
+	encoded, _ := json.Marshal(list)
+	return string(encoded)
+}
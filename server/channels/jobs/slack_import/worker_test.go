@@ -0,0 +1,49 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package slack_import
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/v8/platform/services/slackimport"
+)
+
+func TestJobDataCheckpoint(t *testing.T) {
+	t.Run("no checkpoint", func(t *testing.T) {
+		checkpoint := checkpointFromJobData(model.StringMap{jobDataImportFile: "slack.zip"})
+		assert.Empty(t, checkpoint.BotUserId)
+		assert.Empty(t, checkpoint.ImportedChannels)
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		data := model.StringMap{jobDataImportFile: "slack.zip"}
+		progress := slackimport.Progress{
+			Checkpoint: slackimport.Checkpoint{
+				BotUserId:        model.NewId(),
+				ImportedChannels: []string{"C0G08DLQH", "C0G04DLQH"},
+			},
+			ChannelsTotal:  4,
+			PostsImported:  120,
+			PostsSkipped:   12,
+			FailedChannels: []string{"design, ux"},
+		}
+		setProgressJobData(data, progress)
+
+		assert.Equal(t, model.StringMap{
+			jobDataImportFile:       "slack.zip",
+			jobDataBotUserId:        progress.BotUserId,
+			jobDataImportedChannels: "C0G08DLQH,C0G04DLQH",
+			jobDataChannelsTotal:    "4",
+			jobDataChannelsImported: "2",
+			jobDataPostsImported:    "120",
+			jobDataPostsSkipped:     "12",
+			jobDataFailedUsers:      "[]",
+			jobDataFailedChannels:   `["design, ux"]`,
+		}, data)
+		assert.Equal(t, progress.Checkpoint, checkpointFromJobData(data))
+	})
+}
@@ -24,11 +24,13 @@ const mySQLDeadlockCode = uint16(1213)
 type RetryLayer struct {
 	store.Store
 	AuditStore                      store.AuditStore
+	BatchedEmailNotificationStore   store.BatchedEmailNotificationStore
 	BotStore                        store.BotStore
 	ChannelStore                    store.ChannelStore
 	ChannelBookmarkStore            store.ChannelBookmarkStore
 	ChannelMemberHistoryStore       store.ChannelMemberHistoryStore
 	ClusterDiscoveryStore           store.ClusterDiscoveryStore
+	ClusterPayloadStore             store.ClusterPayloadStore
 	CommandStore                    store.CommandStore
 	CommandWebhookStore             store.CommandWebhookStore
 	ComplianceStore                 store.ComplianceStore
@@ -38,8 +40,10 @@ type RetryLayer struct {
 	FileInfoStore                   store.FileInfoStore
 	GroupStore                      store.GroupStore
 	JobStore                        store.JobStore
+	LegalHoldStore                  store.LegalHoldStore
 	LicenseStore                    store.LicenseStore
 	LinkMetadataStore               store.LinkMetadataStore
+	MfaRecoveryCodeStore            store.MfaRecoveryCodeStore
 	NotifyAdminStore                store.NotifyAdminStore
 	OAuthStore                      store.OAuthStore
 	OutgoingOAuthConnectionStore    store.OutgoingOAuthConnectionStore
@@ -71,6 +75,7 @@ type RetryLayer struct {
 	UserStore                       store.UserStore
 	UserAccessTokenStore            store.UserAccessTokenStore
 	UserTermsOfServiceStore         store.UserTermsOfServiceStore
+	WebAuthnCredentialStore         store.WebAuthnCredentialStore
 	WebhookStore                    store.WebhookStore
 }
 
@@ -78,6 +83,10 @@ func (s *RetryLayer) Audit() store.AuditStore {
 	return s.AuditStore
 }
 
+func (s *RetryLayer) BatchedEmailNotification() store.BatchedEmailNotificationStore {
+	return s.BatchedEmailNotificationStore
+}
+
 func (s *RetryLayer) Bot() store.BotStore {
 	return s.BotStore
 }
@@ -98,6 +107,10 @@ func (s *RetryLayer) ClusterDiscovery() store.ClusterDiscoveryStore {
 	return s.ClusterDiscoveryStore
 }
 
+func (s *RetryLayer) ClusterPayload() store.ClusterPayloadStore {
+	return s.ClusterPayloadStore
+}
+
 func (s *RetryLayer) Command() store.CommandStore {
 	return s.CommandStore
 }
@@ -134,6 +147,10 @@ func (s *RetryLayer) Job() store.JobStore {
 	return s.JobStore
 }
 
+func (s *RetryLayer) LegalHold() store.LegalHoldStore {
+	return s.LegalHoldStore
+}
+
 func (s *RetryLayer) License() store.LicenseStore {
 	return s.LicenseStore
 }
@@ -142,6 +159,10 @@ func (s *RetryLayer) LinkMetadata() store.LinkMetadataStore {
 	return s.LinkMetadataStore
 }
 
+func (s *RetryLayer) MfaRecoveryCode() store.MfaRecoveryCodeStore {
+	return s.MfaRecoveryCodeStore
+}
+
 func (s *RetryLayer) NotifyAdmin() store.NotifyAdminStore {
 	return s.NotifyAdminStore
 }
@@ -266,6 +287,10 @@ func (s *RetryLayer) UserTermsOfService() store.UserTermsOfServiceStore {
 	return s.UserTermsOfServiceStore
 }
 
+func (s *RetryLayer) WebAuthnCredential() store.WebAuthnCredentialStore {
+	return s.WebAuthnCredentialStore
+}
+
 func (s *RetryLayer) Webhook() store.WebhookStore {
 	return s.WebhookStore
 }
@@ -275,6 +300,11 @@ type RetryLayerAuditStore struct {
 	Root *RetryLayer
 }
 
+type RetryLayerBatchedEmailNotificationStore struct {
+	store.BatchedEmailNotificationStore
+	Root *RetryLayer
+}
+
 type RetryLayerBotStore struct {
 	store.BotStore
 	Root *RetryLayer
@@ -300,6 +330,11 @@ type RetryLayerClusterDiscoveryStore struct {
 	Root *RetryLayer
 }
 
+type RetryLayerClusterPayloadStore struct {
+	store.ClusterPayloadStore
+	Root *RetryLayer
+}
+
 type RetryLayerCommandStore struct {
 	store.CommandStore
 	Root *RetryLayer
@@ -345,6 +380,11 @@ type RetryLayerJobStore struct {
 	Root *RetryLayer
 }
 
+type RetryLayerLegalHoldStore struct {
+	store.LegalHoldStore
+	Root *RetryLayer
+}
+
 type RetryLayerLicenseStore struct {
 	store.LicenseStore
 	Root *RetryLayer
@@ -355,6 +395,11 @@ type RetryLayerLinkMetadataStore struct {
 	Root *RetryLayer
 }
 
+type RetryLayerMfaRecoveryCodeStore struct {
+	store.MfaRecoveryCodeStore
+	Root *RetryLayer
+}
+
 type RetryLayerNotifyAdminStore struct {
 	store.NotifyAdminStore
 	Root *RetryLayer
@@ -510,6 +555,11 @@ type RetryLayerUserTermsOfServiceStore struct {
 	Root *RetryLayer
 }
 
+type RetryLayerWebAuthnCredentialStore struct {
+	store.WebAuthnCredentialStore
+	Root *RetryLayer
+}
+
 type RetryLayerWebhookStore struct {
 	store.WebhookStore
 	Root *RetryLayer
@@ -594,6 +644,90 @@ func (s *RetryLayerAuditStore) Save(audit *model.Audit) error {
 
 }
 
+func (s *RetryLayerBatchedEmailNotificationStore) Count() (int64, error) {
+
+	tries := 0
+	for {
+		result, err := s.BatchedEmailNotificationStore.Count()
+		if err == nil {
+			return result, nil
+		}
+		if !isRepeatableError(err) {
+			return result, err
+		}
+		tries++
+		if tries >= 3 {
+			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
+			return result, err
+		}
+		timepkg.Sleep(100 * timepkg.Millisecond)
+	}
+
+}
+
+func (s *RetryLayerBatchedEmailNotificationStore) Delete(ids []string) error {
+
+	tries := 0
+	for {
+		err := s.BatchedEmailNotificationStore.Delete(ids)
+		if err == nil {
+			return nil
+		}
+		if !isRepeatableError(err) {
+			return err
+		}
+		tries++
+		if tries >= 3 {
+			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
+			return err
+		}
+		timepkg.Sleep(100 * timepkg.Millisecond)
+	}
+
+}
+
+func (s *RetryLayerBatchedEmailNotificationStore) GetAll() ([]*model.BatchedEmailNotification, error) {
+
+	tries := 0
+	for {
+		result, err := s.BatchedEmailNotificationStore.GetAll()
+		if err == nil {
+			return result, nil
+		}
+		if !isRepeatableError(err) {
+			return result, err
+		}
+		tries++
+		if tries >= 3 {
+			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
+			return result, err
+		}
+		timepkg.Sleep(100 * timepkg.Millisecond)
+	}
+
+}
+
+func (s *RetryLayerBatchedEmailNotificationStore) Save(notification *model.BatchedEmailNotification) error {
+
+	tries := 0
+	for {
+		err := s.BatchedEmailNotificationStore.Save(notification)
+		if err == nil {
+			return nil
+		}
+		if !isRepeatableError(err) {
+			return err
+		}
+		tries++
+		if tries >= 3 {
+			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
+			return err
+		}
+		timepkg.Sleep(100 * timepkg.Millisecond)
+	}
+
+}
+
 func (s *RetryLayerBotStore) Get(userID string, includeDeleted bool) (*model.Bot, error) {
 
 	tries := 0
@@ -3552,6 +3686,90 @@ func (s *RetryLayerClusterDiscoveryStore) SetLastPingAt(discovery *model.Cluster
 
 }
 
+func (s *RetryLayerClusterPayloadStore) DeleteOlderThan(createAt int64) (int64, error) {
+
+	tries := 0
+	for {
+		result, err := s.ClusterPayloadStore.DeleteOlderThan(createAt)
+		if err == nil {
+			return result, nil
+		}
+		if !isRepeatableError(err) {
+			return result, err
+		}
+		tries++
+		if tries >= 3 {
+			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
+			return result, err
+		}
+		timepkg.Sleep(100 * timepkg.Millisecond)
+	}
+
+}
+
+func (s *RetryLayerClusterPayloadStore) Get(id string) (*model.ClusterPayload, error) {
+
+	tries := 0
+	for {
+		result, err := s.ClusterPayloadStore.Get(id)
+		if err == nil {
+			return result, nil
+		}
+		if !isRepeatableError(err) {
+			return result, err
+		}
+		tries++
+		if tries >= 3 {
+			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
+			return result, err
+		}
+		timepkg.Sleep(100 * timepkg.Millisecond)
+	}
+
+}
+
+func (s *RetryLayerClusterPayloadStore) Notify(channel string, message string) error {
+
+	tries := 0
+	for {
+		err := s.ClusterPayloadStore.Notify(channel, message)
+		if err == nil {
+			return nil
+		}
+		if !isRepeatableError(err) {
+			return err
+		}
+		tries++
+		if tries >= 3 {
+			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
+			return err
+		}
+		timepkg.Sleep(100 * timepkg.Millisecond)
+	}
+
+}
+
+func (s *RetryLayerClusterPayloadStore) Save(payload *model.ClusterPayload) error {
+
+	tries := 0
+	for {
+		err := s.ClusterPayloadStore.Save(payload)
+		if err == nil {
+			return nil
+		}
+		if !isRepeatableError(err) {
+			return err
+		}
+		tries++
+		if tries >= 3 {
+			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
+			return err
+		}
+		timepkg.Sleep(100 * timepkg.Millisecond)
+	}
+
+}
+
 func (s *RetryLayerCommandStore) AnalyticsCommandCount(teamID string) (int64, error) {
 
 	tries := 0
@@ -4404,11 +4622,11 @@ func (s *RetryLayerFileInfoStore) CountAll() (int64, error) {
 
 }
 
-func (s *RetryLayerFileInfoStore) DeleteForPost(c request.CTX, postID string) (string, error) {
+func (s *RetryLayerFileInfoStore) CountByContentHash(hash string) (int64, error) {
 
 	tries := 0
 	for {
-		result, err := s.FileInfoStore.DeleteForPost(c, postID)
+		result, err := s.FileInfoStore.CountByContentHash(hash)
 		if err == nil {
 			return result, nil
 		}
@@ -4425,32 +4643,32 @@ func (s *RetryLayerFileInfoStore) DeleteForPost(c request.CTX, postID string) (s
 
 }
 
-func (s *RetryLayerFileInfoStore) DeleteForPostByIds(rctx request.CTX, postId string, fileIDs []string) error {
+func (s *RetryLayerFileInfoStore) CountByPath(path string) (int64, error) {
 
 	tries := 0
 	for {
-		err := s.FileInfoStore.DeleteForPostByIds(rctx, postId, fileIDs)
+		result, err := s.FileInfoStore.CountByPath(path)
 		if err == nil {
-			return nil
+			return result, nil
 		}
 		if !isRepeatableError(err) {
-			return err
+			return result, err
 		}
 		tries++
 		if tries >= 3 {
 			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
-			return err
+			return result, err
 		}
 		timepkg.Sleep(100 * timepkg.Millisecond)
 	}
 
 }
 
-func (s *RetryLayerFileInfoStore) Get(id string) (*model.FileInfo, error) {
+func (s *RetryLayerFileInfoStore) CountForRetention(endTime int64) (int64, error) {
 
 	tries := 0
 	for {
-		result, err := s.FileInfoStore.Get(id)
+		result, err := s.FileInfoStore.CountForRetention(endTime)
 		if err == nil {
 			return result, nil
 		}
@@ -4467,11 +4685,11 @@ func (s *RetryLayerFileInfoStore) Get(id string) (*model.FileInfo, error) {
 
 }
 
-func (s *RetryLayerFileInfoStore) GetByIds(ids []string, includeDeleted bool, allowFromCache bool) ([]*model.FileInfo, error) {
+func (s *RetryLayerFileInfoStore) CountForRetentionPolicies(now int64, globalPolicyEndTime int64) (int64, error) {
 
 	tries := 0
 	for {
-		result, err := s.FileInfoStore.GetByIds(ids, includeDeleted, allowFromCache)
+		result, err := s.FileInfoStore.CountForRetentionPolicies(now, globalPolicyEndTime)
 		if err == nil {
 			return result, nil
 		}
@@ -4488,11 +4706,11 @@ func (s *RetryLayerFileInfoStore) GetByIds(ids []string, includeDeleted bool, al
 
 }
 
-func (s *RetryLayerFileInfoStore) GetByPath(path string) (*model.FileInfo, error) {
+func (s *RetryLayerFileInfoStore) DeleteForPost(c request.CTX, postID string) (string, error) {
 
 	tries := 0
 	for {
-		result, err := s.FileInfoStore.GetByPath(path)
+		result, err := s.FileInfoStore.DeleteForPost(c, postID)
 		if err == nil {
 			return result, nil
 		}
@@ -4509,32 +4727,32 @@ func (s *RetryLayerFileInfoStore) GetByPath(path string) (*model.FileInfo, error
 
 }
 
-func (s *RetryLayerFileInfoStore) GetFilesBatchForIndexing(startTime int64, startFileID string, includeDeleted bool, limit int) ([]*model.FileForIndexing, error) {
+func (s *RetryLayerFileInfoStore) DeleteForPostByIds(rctx request.CTX, postId string, fileIDs []string) error {
 
 	tries := 0
 	for {
-		result, err := s.FileInfoStore.GetFilesBatchForIndexing(startTime, startFileID, includeDeleted, limit)
+		err := s.FileInfoStore.DeleteForPostByIds(rctx, postId, fileIDs)
 		if err == nil {
-			return result, nil
+			return nil
 		}
 		if !isRepeatableError(err) {
-			return result, err
+			return err
 		}
 		tries++
 		if tries >= 3 {
 			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
-			return result, err
+			return err
 		}
 		timepkg.Sleep(100 * timepkg.Millisecond)
 	}
 
 }
 
-func (s *RetryLayerFileInfoStore) GetForPost(postID string, readFromMaster bool, includeDeleted bool, allowFromCache bool) ([]*model.FileInfo, error) {
+func (s *RetryLayerFileInfoStore) Get(id string) (*model.FileInfo, error) {
 
 	tries := 0
 	for {
-		result, err := s.FileInfoStore.GetForPost(postID, readFromMaster, includeDeleted, allowFromCache)
+		result, err := s.FileInfoStore.Get(id)
 		if err == nil {
 			return result, nil
 		}
@@ -4551,11 +4769,11 @@ func (s *RetryLayerFileInfoStore) GetForPost(postID string, readFromMaster bool,
 
 }
 
-func (s *RetryLayerFileInfoStore) GetForUser(userID string) ([]*model.FileInfo, error) {
+func (s *RetryLayerFileInfoStore) GetBatchForRetention(endTime int64, limit int) ([]*model.FileInfo, error) {
 
 	tries := 0
 	for {
-		result, err := s.FileInfoStore.GetForUser(userID)
+		result, err := s.FileInfoStore.GetBatchForRetention(endTime, limit)
 		if err == nil {
 			return result, nil
 		}
@@ -4572,11 +4790,11 @@ func (s *RetryLayerFileInfoStore) GetForUser(userID string) ([]*model.FileInfo,
 
 }
 
-func (s *RetryLayerFileInfoStore) GetFromMaster(id string) (*model.FileInfo, error) {
+func (s *RetryLayerFileInfoStore) GetByIds(ids []string, includeDeleted bool, allowFromCache bool) ([]*model.FileInfo, error) {
 
 	tries := 0
 	for {
-		result, err := s.FileInfoStore.GetFromMaster(id)
+		result, err := s.FileInfoStore.GetByIds(ids, includeDeleted, allowFromCache)
 		if err == nil {
 			return result, nil
 		}
@@ -4593,11 +4811,11 @@ func (s *RetryLayerFileInfoStore) GetFromMaster(id string) (*model.FileInfo, err
 
 }
 
-func (s *RetryLayerFileInfoStore) GetStorageUsage(allowFromCache bool, includeDeleted bool) (int64, error) {
+func (s *RetryLayerFileInfoStore) GetByPath(path string) (*model.FileInfo, error) {
 
 	tries := 0
 	for {
-		result, err := s.FileInfoStore.GetStorageUsage(allowFromCache, includeDeleted)
+		result, err := s.FileInfoStore.GetByPath(path)
 		if err == nil {
 			return result, nil
 		}
@@ -4614,11 +4832,11 @@ func (s *RetryLayerFileInfoStore) GetStorageUsage(allowFromCache bool, includeDe
 
 }
 
-func (s *RetryLayerFileInfoStore) GetUptoNSizeFileTime(n int64) (int64, error) {
+func (s *RetryLayerFileInfoStore) GetByPostIds(postIDs []string) ([]*model.FileInfo, error) {
 
 	tries := 0
 	for {
-		result, err := s.FileInfoStore.GetUptoNSizeFileTime(n)
+		result, err := s.FileInfoStore.GetByPostIds(postIDs)
 		if err == nil {
 			return result, nil
 		}
@@ -4635,11 +4853,11 @@ func (s *RetryLayerFileInfoStore) GetUptoNSizeFileTime(n int64) (int64, error) {
 
 }
 
-func (s *RetryLayerFileInfoStore) GetWithOptions(page int, perPage int, opt *model.GetFileInfosOptions) ([]*model.FileInfo, error) {
+func (s *RetryLayerFileInfoStore) GetFilesBatchForDeduplication(afterID string, limit int) ([]*model.FileInfo, error) {
 
 	tries := 0
 	for {
-		result, err := s.FileInfoStore.GetWithOptions(page, perPage, opt)
+		result, err := s.FileInfoStore.GetFilesBatchForDeduplication(afterID, limit)
 		if err == nil {
 			return result, nil
 		}
@@ -4656,38 +4874,32 @@ func (s *RetryLayerFileInfoStore) GetWithOptions(page int, perPage int, opt *mod
 
 }
 
-func (s *RetryLayerFileInfoStore) InvalidateFileInfosForPostCache(postID string, deleted bool) {
-
-	s.FileInfoStore.InvalidateFileInfosForPostCache(postID, deleted)
-
-}
-
-func (s *RetryLayerFileInfoStore) PermanentDelete(c request.CTX, fileID string) error {
+func (s *RetryLayerFileInfoStore) GetFilesBatchForIndexing(startTime int64, startFileID string, includeDeleted bool, limit int) ([]*model.FileForIndexing, error) {
 
 	tries := 0
 	for {
-		err := s.FileInfoStore.PermanentDelete(c, fileID)
+		result, err := s.FileInfoStore.GetFilesBatchForIndexing(startTime, startFileID, includeDeleted, limit)
 		if err == nil {
-			return nil
+			return result, nil
 		}
 		if !isRepeatableError(err) {
-			return err
+			return result, err
 		}
 		tries++
 		if tries >= 3 {
 			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
-			return err
+			return result, err
 		}
 		timepkg.Sleep(100 * timepkg.Millisecond)
 	}
 
 }
 
-func (s *RetryLayerFileInfoStore) PermanentDeleteBatch(ctx request.CTX, endTime int64, limit int64) (int64, error) {
+func (s *RetryLayerFileInfoStore) GetForPost(postID string, readFromMaster bool, includeDeleted bool, allowFromCache bool) ([]*model.FileInfo, error) {
 
 	tries := 0
 	for {
-		result, err := s.FileInfoStore.PermanentDeleteBatch(ctx, endTime, limit)
+		result, err := s.FileInfoStore.GetForPost(postID, readFromMaster, includeDeleted, allowFromCache)
 		if err == nil {
 			return result, nil
 		}
@@ -4704,11 +4916,11 @@ func (s *RetryLayerFileInfoStore) PermanentDeleteBatch(ctx request.CTX, endTime
 
 }
 
-func (s *RetryLayerFileInfoStore) PermanentDeleteByUser(ctx request.CTX, userID string) (int64, error) {
+func (s *RetryLayerFileInfoStore) GetForUser(userID string) ([]*model.FileInfo, error) {
 
 	tries := 0
 	for {
-		result, err := s.FileInfoStore.PermanentDeleteByUser(ctx, userID)
+		result, err := s.FileInfoStore.GetForUser(userID)
 		if err == nil {
 			return result, nil
 		}
@@ -4725,21 +4937,216 @@ func (s *RetryLayerFileInfoStore) PermanentDeleteByUser(ctx request.CTX, userID
 
 }
 
-func (s *RetryLayerFileInfoStore) PermanentDeleteForPost(rctx request.CTX, postID string) error {
+func (s *RetryLayerFileInfoStore) GetFromMaster(id string) (*model.FileInfo, error) {
 
 	tries := 0
 	for {
-		err := s.FileInfoStore.PermanentDeleteForPost(rctx, postID)
+		result, err := s.FileInfoStore.GetFromMaster(id)
 		if err == nil {
-			return nil
+			return result, nil
 		}
 		if !isRepeatableError(err) {
-			return err
+			return result, err
 		}
 		tries++
 		if tries >= 3 {
 			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
-			return err
+			return result, err
+		}
+		timepkg.Sleep(100 * timepkg.Millisecond)
+	}
+
+}
+
+func (s *RetryLayerFileInfoStore) GetStorageUsage(allowFromCache bool, includeDeleted bool) (int64, error) {
+
+	tries := 0
+	for {
+		result, err := s.FileInfoStore.GetStorageUsage(allowFromCache, includeDeleted)
+		if err == nil {
+			return result, nil
+		}
+		if !isRepeatableError(err) {
+			return result, err
+		}
+		tries++
+		if tries >= 3 {
+			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
+			return result, err
+		}
+		timepkg.Sleep(100 * timepkg.Millisecond)
+	}
+
+}
+
+func (s *RetryLayerFileInfoStore) GetUptoNSizeFileTime(n int64) (int64, error) {
+
+	tries := 0
+	for {
+		result, err := s.FileInfoStore.GetUptoNSizeFileTime(n)
+		if err == nil {
+			return result, nil
+		}
+		if !isRepeatableError(err) {
+			return result, err
+		}
+		tries++
+		if tries >= 3 {
+			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
+			return result, err
+		}
+		timepkg.Sleep(100 * timepkg.Millisecond)
+	}
+
+}
+
+func (s *RetryLayerFileInfoStore) GetWithOptions(page int, perPage int, opt *model.GetFileInfosOptions) ([]*model.FileInfo, error) {
+
+	tries := 0
+	for {
+		result, err := s.FileInfoStore.GetWithOptions(page, perPage, opt)
+		if err == nil {
+			return result, nil
+		}
+		if !isRepeatableError(err) {
+			return result, err
+		}
+		tries++
+		if tries >= 3 {
+			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
+			return result, err
+		}
+		timepkg.Sleep(100 * timepkg.Millisecond)
+	}
+
+}
+
+func (s *RetryLayerFileInfoStore) InvalidateFileInfosForPostCache(postID string, deleted bool) {
+
+	s.FileInfoStore.InvalidateFileInfosForPostCache(postID, deleted)
+
+}
+
+func (s *RetryLayerFileInfoStore) PermanentDelete(c request.CTX, fileID string) error {
+
+	tries := 0
+	for {
+		err := s.FileInfoStore.PermanentDelete(c, fileID)
+		if err == nil {
+			return nil
+		}
+		if !isRepeatableError(err) {
+			return err
+		}
+		tries++
+		if tries >= 3 {
+			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
+			return err
+		}
+		timepkg.Sleep(100 * timepkg.Millisecond)
+	}
+
+}
+
+func (s *RetryLayerFileInfoStore) PermanentDeleteBatch(ctx request.CTX, endTime int64, limit int64) (int64, error) {
+
+	tries := 0
+	for {
+		result, err := s.FileInfoStore.PermanentDeleteBatch(ctx, endTime, limit)
+		if err == nil {
+			return result, nil
+		}
+		if !isRepeatableError(err) {
+			return result, err
+		}
+		tries++
+		if tries >= 3 {
+			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
+			return result, err
+		}
+		timepkg.Sleep(100 * timepkg.Millisecond)
+	}
+
+}
+
+func (s *RetryLayerFileInfoStore) PermanentDeleteByIds(rctx request.CTX, fileIDs []string) (int64, error) {
+
+	tries := 0
+	for {
+		result, err := s.FileInfoStore.PermanentDeleteByIds(rctx, fileIDs)
+		if err == nil {
+			return result, nil
+		}
+		if !isRepeatableError(err) {
+			return result, err
+		}
+		tries++
+		if tries >= 3 {
+			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
+			return result, err
+		}
+		timepkg.Sleep(100 * timepkg.Millisecond)
+	}
+
+}
+
+func (s *RetryLayerFileInfoStore) PermanentDeleteByUser(ctx request.CTX, userID string) (int64, error) {
+
+	tries := 0
+	for {
+		result, err := s.FileInfoStore.PermanentDeleteByUser(ctx, userID)
+		if err == nil {
+			return result, nil
+		}
+		if !isRepeatableError(err) {
+			return result, err
+		}
+		tries++
+		if tries >= 3 {
+			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
+			return result, err
+		}
+		timepkg.Sleep(100 * timepkg.Millisecond)
+	}
+
+}
+
+func (s *RetryLayerFileInfoStore) PermanentDeleteForPost(rctx request.CTX, postID string) error {
+
+	tries := 0
+	for {
+		err := s.FileInfoStore.PermanentDeleteForPost(rctx, postID)
+		if err == nil {
+			return nil
+		}
+		if !isRepeatableError(err) {
+			return err
+		}
+		tries++
+		if tries >= 3 {
+			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
+			return err
+		}
+		timepkg.Sleep(100 * timepkg.Millisecond)
+	}
+
+}
+
+func (s *RetryLayerFileInfoStore) Quarantine(rctx request.CTX, fileID string, reason string) error {
+
+	tries := 0
+	for {
+		err := s.FileInfoStore.Quarantine(rctx, fileID, reason)
+		if err == nil {
+			return nil
+		}
+		if !isRepeatableError(err) {
+			return err
+		}
+		tries++
+		if tries >= 3 {
+			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
+			return err
 		}
 		timepkg.Sleep(100 * timepkg.Millisecond)
 	}
@@ -4830,6 +5237,27 @@ func (s *RetryLayerFileInfoStore) SetContent(ctx request.CTX, fileID string, con
 
 }
 
+func (s *RetryLayerFileInfoStore) UpdateContentHash(rctx request.CTX, fileIDs []string, newPath string, hash string) error {
+
+	tries := 0
+	for {
+		err := s.FileInfoStore.UpdateContentHash(rctx, fileIDs, newPath, hash)
+		if err == nil {
+			return nil
+		}
+		if !isRepeatableError(err) {
+			return err
+		}
+		tries++
+		if tries >= 3 {
+			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
+			return err
+		}
+		timepkg.Sleep(100 * timepkg.Millisecond)
+	}
+
+}
+
 func (s *RetryLayerFileInfoStore) Upsert(rctx request.CTX, info *model.FileInfo) (*model.FileInfo, error) {
 
 	tries := 0
@@ -5838,11 +6266,200 @@ func (s *RetryLayerGroupStore) Restore(groupID string) (*model.Group, error) {
 
 }
 
-func (s *RetryLayerGroupStore) TeamMembersMinusGroupMembers(teamID string, groupIDs []string, page int, perPage int) ([]*model.UserWithGroups, error) {
+func (s *RetryLayerGroupStore) TeamMembersMinusGroupMembers(teamID string, groupIDs []string, page int, perPage int) ([]*model.UserWithGroups, error) {
+
+	tries := 0
+	for {
+		result, err := s.GroupStore.TeamMembersMinusGroupMembers(teamID, groupIDs, page, perPage)
+		if err == nil {
+			return result, nil
+		}
+		if !isRepeatableError(err) {
+			return result, err
+		}
+		tries++
+		if tries >= 3 {
+			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
+			return result, err
+		}
+		timepkg.Sleep(100 * timepkg.Millisecond)
+	}
+
+}
+
+func (s *RetryLayerGroupStore) TeamMembersToAdd(since int64, teamID *string, includeRemovedMembers bool) ([]*model.UserTeamIDPair, error) {
+
+	tries := 0
+	for {
+		result, err := s.GroupStore.TeamMembersToAdd(since, teamID, includeRemovedMembers)
+		if err == nil {
+			return result, nil
+		}
+		if !isRepeatableError(err) {
+			return result, err
+		}
+		tries++
+		if tries >= 3 {
+			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
+			return result, err
+		}
+		timepkg.Sleep(100 * timepkg.Millisecond)
+	}
+
+}
+
+func (s *RetryLayerGroupStore) TeamMembersToRemove(teamID *string) ([]*model.TeamMember, error) {
+
+	tries := 0
+	for {
+		result, err := s.GroupStore.TeamMembersToRemove(teamID)
+		if err == nil {
+			return result, nil
+		}
+		if !isRepeatableError(err) {
+			return result, err
+		}
+		tries++
+		if tries >= 3 {
+			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
+			return result, err
+		}
+		timepkg.Sleep(100 * timepkg.Millisecond)
+	}
+
+}
+
+func (s *RetryLayerGroupStore) Update(group *model.Group) (*model.Group, error) {
+
+	tries := 0
+	for {
+		result, err := s.GroupStore.Update(group)
+		if err == nil {
+			return result, nil
+		}
+		if !isRepeatableError(err) {
+			return result, err
+		}
+		tries++
+		if tries >= 3 {
+			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
+			return result, err
+		}
+		timepkg.Sleep(100 * timepkg.Millisecond)
+	}
+
+}
+
+func (s *RetryLayerGroupStore) UpdateGroupSyncable(groupSyncable *model.GroupSyncable) (*model.GroupSyncable, error) {
+
+	tries := 0
+	for {
+		result, err := s.GroupStore.UpdateGroupSyncable(groupSyncable)
+		if err == nil {
+			return result, nil
+		}
+		if !isRepeatableError(err) {
+			return result, err
+		}
+		tries++
+		if tries >= 3 {
+			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
+			return result, err
+		}
+		timepkg.Sleep(100 * timepkg.Millisecond)
+	}
+
+}
+
+func (s *RetryLayerGroupStore) UpsertMember(groupID string, userID string) (*model.GroupMember, error) {
+
+	tries := 0
+	for {
+		result, err := s.GroupStore.UpsertMember(groupID, userID)
+		if err == nil {
+			return result, nil
+		}
+		if !isRepeatableError(err) {
+			return result, err
+		}
+		tries++
+		if tries >= 3 {
+			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
+			return result, err
+		}
+		timepkg.Sleep(100 * timepkg.Millisecond)
+	}
+
+}
+
+func (s *RetryLayerGroupStore) UpsertMembers(groupID string, userIDs []string) ([]*model.GroupMember, error) {
+
+	tries := 0
+	for {
+		result, err := s.GroupStore.UpsertMembers(groupID, userIDs)
+		if err == nil {
+			return result, nil
+		}
+		if !isRepeatableError(err) {
+			return result, err
+		}
+		tries++
+		if tries >= 3 {
+			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
+			return result, err
+		}
+		timepkg.Sleep(100 * timepkg.Millisecond)
+	}
+
+}
+
+func (s *RetryLayerJobStore) Cleanup(expiryTime int64, batchSize int) error {
+
+	tries := 0
+	for {
+		err := s.JobStore.Cleanup(expiryTime, batchSize)
+		if err == nil {
+			return nil
+		}
+		if !isRepeatableError(err) {
+			return err
+		}
+		tries++
+		if tries >= 3 {
+			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
+			return err
+		}
+		timepkg.Sleep(100 * timepkg.Millisecond)
+	}
+
+}
+
+func (s *RetryLayerJobStore) Delete(id string) (string, error) {
+
+	tries := 0
+	for {
+		result, err := s.JobStore.Delete(id)
+		if err == nil {
+			return result, nil
+		}
+		if !isRepeatableError(err) {
+			return result, err
+		}
+		tries++
+		if tries >= 3 {
+			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
+			return result, err
+		}
+		timepkg.Sleep(100 * timepkg.Millisecond)
+	}
+
+}
+
+func (s *RetryLayerJobStore) Get(c request.CTX, id string) (*model.Job, error) {
 
 	tries := 0
 	for {
-		result, err := s.GroupStore.TeamMembersMinusGroupMembers(teamID, groupIDs, page, perPage)
+		result, err := s.JobStore.Get(c, id)
 		if err == nil {
 			return result, nil
 		}
@@ -5859,11 +6476,11 @@ func (s *RetryLayerGroupStore) TeamMembersMinusGroupMembers(teamID string, group
 
 }
 
-func (s *RetryLayerGroupStore) TeamMembersToAdd(since int64, teamID *string, includeRemovedMembers bool) ([]*model.UserTeamIDPair, error) {
+func (s *RetryLayerJobStore) GetAllByStatus(c request.CTX, status string) ([]*model.Job, error) {
 
 	tries := 0
 	for {
-		result, err := s.GroupStore.TeamMembersToAdd(since, teamID, includeRemovedMembers)
+		result, err := s.JobStore.GetAllByStatus(c, status)
 		if err == nil {
 			return result, nil
 		}
@@ -5880,11 +6497,11 @@ func (s *RetryLayerGroupStore) TeamMembersToAdd(since int64, teamID *string, inc
 
 }
 
-func (s *RetryLayerGroupStore) TeamMembersToRemove(teamID *string) ([]*model.TeamMember, error) {
+func (s *RetryLayerJobStore) GetAllByType(c request.CTX, jobType string) ([]*model.Job, error) {
 
 	tries := 0
 	for {
-		result, err := s.GroupStore.TeamMembersToRemove(teamID)
+		result, err := s.JobStore.GetAllByType(c, jobType)
 		if err == nil {
 			return result, nil
 		}
@@ -5901,11 +6518,11 @@ func (s *RetryLayerGroupStore) TeamMembersToRemove(teamID *string) ([]*model.Tea
 
 }
 
-func (s *RetryLayerGroupStore) Update(group *model.Group) (*model.Group, error) {
+func (s *RetryLayerJobStore) GetAllByTypeAndStatus(c request.CTX, jobType string, status string) ([]*model.Job, error) {
 
 	tries := 0
 	for {
-		result, err := s.GroupStore.Update(group)
+		result, err := s.JobStore.GetAllByTypeAndStatus(c, jobType, status)
 		if err == nil {
 			return result, nil
 		}
@@ -5922,11 +6539,11 @@ func (s *RetryLayerGroupStore) Update(group *model.Group) (*model.Group, error)
 
 }
 
-func (s *RetryLayerGroupStore) UpdateGroupSyncable(groupSyncable *model.GroupSyncable) (*model.GroupSyncable, error) {
+func (s *RetryLayerJobStore) GetAllByTypeAndStatusPage(c request.CTX, jobType []string, status string, offset int, limit int) ([]*model.Job, error) {
 
 	tries := 0
 	for {
-		result, err := s.GroupStore.UpdateGroupSyncable(groupSyncable)
+		result, err := s.JobStore.GetAllByTypeAndStatusPage(c, jobType, status, offset, limit)
 		if err == nil {
 			return result, nil
 		}
@@ -5943,11 +6560,11 @@ func (s *RetryLayerGroupStore) UpdateGroupSyncable(groupSyncable *model.GroupSyn
 
 }
 
-func (s *RetryLayerGroupStore) UpsertMember(groupID string, userID string) (*model.GroupMember, error) {
+func (s *RetryLayerJobStore) GetAllByTypePage(c request.CTX, jobType string, offset int, limit int) ([]*model.Job, error) {
 
 	tries := 0
 	for {
-		result, err := s.GroupStore.UpsertMember(groupID, userID)
+		result, err := s.JobStore.GetAllByTypePage(c, jobType, offset, limit)
 		if err == nil {
 			return result, nil
 		}
@@ -5964,11 +6581,11 @@ func (s *RetryLayerGroupStore) UpsertMember(groupID string, userID string) (*mod
 
 }
 
-func (s *RetryLayerGroupStore) UpsertMembers(groupID string, userIDs []string) ([]*model.GroupMember, error) {
+func (s *RetryLayerJobStore) GetAllByTypesPage(c request.CTX, jobTypes []string, offset int, limit int) ([]*model.Job, error) {
 
 	tries := 0
 	for {
-		result, err := s.GroupStore.UpsertMembers(groupID, userIDs)
+		result, err := s.JobStore.GetAllByTypesPage(c, jobTypes, offset, limit)
 		if err == nil {
 			return result, nil
 		}
@@ -5985,32 +6602,32 @@ func (s *RetryLayerGroupStore) UpsertMembers(groupID string, userIDs []string) (
 
 }
 
-func (s *RetryLayerJobStore) Cleanup(expiryTime int64, batchSize int) error {
+func (s *RetryLayerJobStore) GetCountByStatusAndType(status string, jobType string) (int64, error) {
 
 	tries := 0
 	for {
-		err := s.JobStore.Cleanup(expiryTime, batchSize)
+		result, err := s.JobStore.GetCountByStatusAndType(status, jobType)
 		if err == nil {
-			return nil
+			return result, nil
 		}
 		if !isRepeatableError(err) {
-			return err
+			return result, err
 		}
 		tries++
 		if tries >= 3 {
 			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
-			return err
+			return result, err
 		}
 		timepkg.Sleep(100 * timepkg.Millisecond)
 	}
 
 }
 
-func (s *RetryLayerJobStore) Delete(id string) (string, error) {
+func (s *RetryLayerJobStore) GetNewestJobByStatusAndType(status string, jobType string) (*model.Job, error) {
 
 	tries := 0
 	for {
-		result, err := s.JobStore.Delete(id)
+		result, err := s.JobStore.GetNewestJobByStatusAndType(status, jobType)
 		if err == nil {
 			return result, nil
 		}
@@ -6027,11 +6644,11 @@ func (s *RetryLayerJobStore) Delete(id string) (string, error) {
 
 }
 
-func (s *RetryLayerJobStore) Get(c request.CTX, id string) (*model.Job, error) {
+func (s *RetryLayerJobStore) GetNewestJobByStatusesAndType(statuses []string, jobType string) (*model.Job, error) {
 
 	tries := 0
 	for {
-		result, err := s.JobStore.Get(c, id)
+		result, err := s.JobStore.GetNewestJobByStatusesAndType(statuses, jobType)
 		if err == nil {
 			return result, nil
 		}
@@ -6048,11 +6665,11 @@ func (s *RetryLayerJobStore) Get(c request.CTX, id string) (*model.Job, error) {
 
 }
 
-func (s *RetryLayerJobStore) GetAllByStatus(c request.CTX, status string) ([]*model.Job, error) {
+func (s *RetryLayerJobStore) Save(job *model.Job) (*model.Job, error) {
 
 	tries := 0
 	for {
-		result, err := s.JobStore.GetAllByStatus(c, status)
+		result, err := s.JobStore.Save(job)
 		if err == nil {
 			return result, nil
 		}
@@ -6069,11 +6686,11 @@ func (s *RetryLayerJobStore) GetAllByStatus(c request.CTX, status string) ([]*mo
 
 }
 
-func (s *RetryLayerJobStore) GetAllByType(c request.CTX, jobType string) ([]*model.Job, error) {
+func (s *RetryLayerJobStore) SaveOnce(job *model.Job) (*model.Job, error) {
 
 	tries := 0
 	for {
-		result, err := s.JobStore.GetAllByType(c, jobType)
+		result, err := s.JobStore.SaveOnce(job)
 		if err == nil {
 			return result, nil
 		}
@@ -6090,11 +6707,11 @@ func (s *RetryLayerJobStore) GetAllByType(c request.CTX, jobType string) ([]*mod
 
 }
 
-func (s *RetryLayerJobStore) GetAllByTypeAndStatus(c request.CTX, jobType string, status string) ([]*model.Job, error) {
+func (s *RetryLayerJobStore) UpdateOptimistically(job *model.Job, currentStatus string) (bool, error) {
 
 	tries := 0
 	for {
-		result, err := s.JobStore.GetAllByTypeAndStatus(c, jobType, status)
+		result, err := s.JobStore.UpdateOptimistically(job, currentStatus)
 		if err == nil {
 			return result, nil
 		}
@@ -6111,11 +6728,11 @@ func (s *RetryLayerJobStore) GetAllByTypeAndStatus(c request.CTX, jobType string
 
 }
 
-func (s *RetryLayerJobStore) GetAllByTypeAndStatusPage(c request.CTX, jobType []string, status string, offset int, limit int) ([]*model.Job, error) {
+func (s *RetryLayerJobStore) UpdateStatus(id string, status string) (*model.Job, error) {
 
 	tries := 0
 	for {
-		result, err := s.JobStore.GetAllByTypeAndStatusPage(c, jobType, status, offset, limit)
+		result, err := s.JobStore.UpdateStatus(id, status)
 		if err == nil {
 			return result, nil
 		}
@@ -6132,11 +6749,11 @@ func (s *RetryLayerJobStore) GetAllByTypeAndStatusPage(c request.CTX, jobType []
 
 }
 
-func (s *RetryLayerJobStore) GetAllByTypePage(c request.CTX, jobType string, offset int, limit int) ([]*model.Job, error) {
+func (s *RetryLayerJobStore) UpdateStatusOptimistically(id string, currentStatus string, newStatus string) (bool, error) {
 
 	tries := 0
 	for {
-		result, err := s.JobStore.GetAllByTypePage(c, jobType, offset, limit)
+		result, err := s.JobStore.UpdateStatusOptimistically(id, currentStatus, newStatus)
 		if err == nil {
 			return result, nil
 		}
@@ -6153,11 +6770,11 @@ func (s *RetryLayerJobStore) GetAllByTypePage(c request.CTX, jobType string, off
 
 }
 
-func (s *RetryLayerJobStore) GetAllByTypesPage(c request.CTX, jobTypes []string, offset int, limit int) ([]*model.Job, error) {
+func (s *RetryLayerLegalHoldStore) Get(id string) (*model.LegalHold, error) {
 
 	tries := 0
 	for {
-		result, err := s.JobStore.GetAllByTypesPage(c, jobTypes, offset, limit)
+		result, err := s.LegalHoldStore.Get(id)
 		if err == nil {
 			return result, nil
 		}
@@ -6174,11 +6791,11 @@ func (s *RetryLayerJobStore) GetAllByTypesPage(c request.CTX, jobTypes []string,
 
 }
 
-func (s *RetryLayerJobStore) GetCountByStatusAndType(status string, jobType string) (int64, error) {
+func (s *RetryLayerLegalHoldStore) GetActive() ([]*model.LegalHold, error) {
 
 	tries := 0
 	for {
-		result, err := s.JobStore.GetCountByStatusAndType(status, jobType)
+		result, err := s.LegalHoldStore.GetActive()
 		if err == nil {
 			return result, nil
 		}
@@ -6195,11 +6812,11 @@ func (s *RetryLayerJobStore) GetCountByStatusAndType(status string, jobType stri
 
 }
 
-func (s *RetryLayerJobStore) GetNewestJobByStatusAndType(status string, jobType string) (*model.Job, error) {
+func (s *RetryLayerLegalHoldStore) GetAll(offset int, limit int, includeReleased bool) ([]*model.LegalHold, error) {
 
 	tries := 0
 	for {
-		result, err := s.JobStore.GetNewestJobByStatusAndType(status, jobType)
+		result, err := s.LegalHoldStore.GetAll(offset, limit, includeReleased)
 		if err == nil {
 			return result, nil
 		}
@@ -6216,32 +6833,32 @@ func (s *RetryLayerJobStore) GetNewestJobByStatusAndType(status string, jobType
 
 }
 
-func (s *RetryLayerJobStore) GetNewestJobByStatusesAndType(statuses []string, jobType string) (*model.Job, error) {
+func (s *RetryLayerLegalHoldStore) Release(id string, releasedAt int64) error {
 
 	tries := 0
 	for {
-		result, err := s.JobStore.GetNewestJobByStatusesAndType(statuses, jobType)
+		err := s.LegalHoldStore.Release(id, releasedAt)
 		if err == nil {
-			return result, nil
+			return nil
 		}
 		if !isRepeatableError(err) {
-			return result, err
+			return err
 		}
 		tries++
 		if tries >= 3 {
 			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
-			return result, err
+			return err
 		}
 		timepkg.Sleep(100 * timepkg.Millisecond)
 	}
 
 }
 
-func (s *RetryLayerJobStore) Save(job *model.Job) (*model.Job, error) {
+func (s *RetryLayerLegalHoldStore) Save(hold *model.LegalHold) (*model.LegalHold, error) {
 
 	tries := 0
 	for {
-		result, err := s.JobStore.Save(job)
+		result, err := s.LegalHoldStore.Save(hold)
 		if err == nil {
 			return result, nil
 		}
@@ -6258,11 +6875,11 @@ func (s *RetryLayerJobStore) Save(job *model.Job) (*model.Job, error) {
 
 }
 
-func (s *RetryLayerJobStore) SaveOnce(job *model.Job) (*model.Job, error) {
+func (s *RetryLayerLicenseStore) Get(c request.CTX, id string) (*model.LicenseRecord, error) {
 
 	tries := 0
 	for {
-		result, err := s.JobStore.SaveOnce(job)
+		result, err := s.LicenseStore.Get(c, id)
 		if err == nil {
 			return result, nil
 		}
@@ -6279,11 +6896,11 @@ func (s *RetryLayerJobStore) SaveOnce(job *model.Job) (*model.Job, error) {
 
 }
 
-func (s *RetryLayerJobStore) UpdateOptimistically(job *model.Job, currentStatus string) (bool, error) {
+func (s *RetryLayerLicenseStore) GetAll() ([]*model.LicenseRecord, error) {
 
 	tries := 0
 	for {
-		result, err := s.JobStore.UpdateOptimistically(job, currentStatus)
+		result, err := s.LicenseStore.GetAll()
 		if err == nil {
 			return result, nil
 		}
@@ -6300,32 +6917,32 @@ func (s *RetryLayerJobStore) UpdateOptimistically(job *model.Job, currentStatus
 
 }
 
-func (s *RetryLayerJobStore) UpdateStatus(id string, status string) (*model.Job, error) {
+func (s *RetryLayerLicenseStore) Save(license *model.LicenseRecord) error {
 
 	tries := 0
 	for {
-		result, err := s.JobStore.UpdateStatus(id, status)
+		err := s.LicenseStore.Save(license)
 		if err == nil {
-			return result, nil
+			return nil
 		}
 		if !isRepeatableError(err) {
-			return result, err
+			return err
 		}
 		tries++
 		if tries >= 3 {
 			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
-			return result, err
+			return err
 		}
 		timepkg.Sleep(100 * timepkg.Millisecond)
 	}
 
 }
 
-func (s *RetryLayerJobStore) UpdateStatusOptimistically(id string, currentStatus string, newStatus string) (bool, error) {
+func (s *RetryLayerLinkMetadataStore) Get(url string, timestamp int64) (*model.LinkMetadata, error) {
 
 	tries := 0
 	for {
-		result, err := s.JobStore.UpdateStatusOptimistically(id, currentStatus, newStatus)
+		result, err := s.LinkMetadataStore.Get(url, timestamp)
 		if err == nil {
 			return result, nil
 		}
@@ -6342,11 +6959,11 @@ func (s *RetryLayerJobStore) UpdateStatusOptimistically(id string, currentStatus
 
 }
 
-func (s *RetryLayerLicenseStore) Get(c request.CTX, id string) (*model.LicenseRecord, error) {
+func (s *RetryLayerLinkMetadataStore) Save(linkMetadata *model.LinkMetadata) (*model.LinkMetadata, error) {
 
 	tries := 0
 	for {
-		result, err := s.LicenseStore.Get(c, id)
+		result, err := s.LinkMetadataStore.Save(linkMetadata)
 		if err == nil {
 			return result, nil
 		}
@@ -6363,11 +6980,11 @@ func (s *RetryLayerLicenseStore) Get(c request.CTX, id string) (*model.LicenseRe
 
 }
 
-func (s *RetryLayerLicenseStore) GetAll() ([]*model.LicenseRecord, error) {
+func (s *RetryLayerMfaRecoveryCodeStore) Consume(userID string, codeHash string) (bool, error) {
 
 	tries := 0
 	for {
-		result, err := s.LicenseStore.GetAll()
+		result, err := s.MfaRecoveryCodeStore.Consume(userID, codeHash)
 		if err == nil {
 			return result, nil
 		}
@@ -6384,11 +7001,11 @@ func (s *RetryLayerLicenseStore) GetAll() ([]*model.LicenseRecord, error) {
 
 }
 
-func (s *RetryLayerLicenseStore) Save(license *model.LicenseRecord) error {
+func (s *RetryLayerMfaRecoveryCodeStore) DeleteAllForUser(userID string) error {
 
 	tries := 0
 	for {
-		err := s.LicenseStore.Save(license)
+		err := s.MfaRecoveryCodeStore.DeleteAllForUser(userID)
 		if err == nil {
 			return nil
 		}
@@ -6405,11 +7022,11 @@ func (s *RetryLayerLicenseStore) Save(license *model.LicenseRecord) error {
 
 }
 
-func (s *RetryLayerLinkMetadataStore) Get(url string, timestamp int64) (*model.LinkMetadata, error) {
+func (s *RetryLayerMfaRecoveryCodeStore) GetForUser(userID string) ([]string, error) {
 
 	tries := 0
 	for {
-		result, err := s.LinkMetadataStore.Get(url, timestamp)
+		result, err := s.MfaRecoveryCodeStore.GetForUser(userID)
 		if err == nil {
 			return result, nil
 		}
@@ -6426,21 +7043,21 @@ func (s *RetryLayerLinkMetadataStore) Get(url string, timestamp int64) (*model.L
 
 }
 
-func (s *RetryLayerLinkMetadataStore) Save(linkMetadata *model.LinkMetadata) (*model.LinkMetadata, error) {
+func (s *RetryLayerMfaRecoveryCodeStore) ReplaceForUser(userID string, codeHashes []string) error {
 
 	tries := 0
 	for {
-		result, err := s.LinkMetadataStore.Save(linkMetadata)
+		err := s.MfaRecoveryCodeStore.ReplaceForUser(userID, codeHashes)
 		if err == nil {
-			return result, nil
+			return nil
 		}
 		if !isRepeatableError(err) {
-			return result, err
+			return err
 		}
 		tries++
 		if tries >= 3 {
 			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
-			return result, err
+			return err
 		}
 		timepkg.Sleep(100 * timepkg.Millisecond)
 	}
@@ -7356,6 +7973,27 @@ func (s *RetryLayerPostStore) ClearCaches() {
 
 }
 
+func (s *RetryLayerPostStore) CountForRetentionPolicies(now int64, globalPolicyEndTime int64) (int64, error) {
+
+	tries := 0
+	for {
+		result, err := s.PostStore.CountForRetentionPolicies(now, globalPolicyEndTime)
+		if err == nil {
+			return result, nil
+		}
+		if !isRepeatableError(err) {
+			return result, err
+		}
+		tries++
+		if tries >= 3 {
+			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
+			return result, err
+		}
+		timepkg.Sleep(100 * timepkg.Millisecond)
+	}
+
+}
+
 func (s *RetryLayerPostStore) Delete(rctx request.CTX, postID string, timestamp int64, deleteByID string) error {
 
 	tries := 0
@@ -15588,6 +16226,132 @@ func (s *RetryLayerUserTermsOfServiceStore) Save(userTermsOfService *model.UserT
 
 }
 
+func (s *RetryLayerWebAuthnCredentialStore) Delete(id string) error {
+
+	tries := 0
+	for {
+		err := s.WebAuthnCredentialStore.Delete(id)
+		if err == nil {
+			return nil
+		}
+		if !isRepeatableError(err) {
+			return err
+		}
+		tries++
+		if tries >= 3 {
+			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
+			return err
+		}
+		timepkg.Sleep(100 * timepkg.Millisecond)
+	}
+
+}
+
+func (s *RetryLayerWebAuthnCredentialStore) DeleteAllForUser(userID string) error {
+
+	tries := 0
+	for {
+		err := s.WebAuthnCredentialStore.DeleteAllForUser(userID)
+		if err == nil {
+			return nil
+		}
+		if !isRepeatableError(err) {
+			return err
+		}
+		tries++
+		if tries >= 3 {
+			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
+			return err
+		}
+		timepkg.Sleep(100 * timepkg.Millisecond)
+	}
+
+}
+
+func (s *RetryLayerWebAuthnCredentialStore) Get(id string) (*model.WebAuthnCredential, error) {
+
+	tries := 0
+	for {
+		result, err := s.WebAuthnCredentialStore.Get(id)
+		if err == nil {
+			return result, nil
+		}
+		if !isRepeatableError(err) {
+			return result, err
+		}
+		tries++
+		if tries >= 3 {
+			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
+			return result, err
+		}
+		timepkg.Sleep(100 * timepkg.Millisecond)
+	}
+
+}
+
+func (s *RetryLayerWebAuthnCredentialStore) GetForUser(userID string) ([]*model.WebAuthnCredential, error) {
+
+	tries := 0
+	for {
+		result, err := s.WebAuthnCredentialStore.GetForUser(userID)
+		if err == nil {
+			return result, nil
+		}
+		if !isRepeatableError(err) {
+			return result, err
+		}
+		tries++
+		if tries >= 3 {
+			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
+			return result, err
+		}
+		timepkg.Sleep(100 * timepkg.Millisecond)
+	}
+
+}
+
+func (s *RetryLayerWebAuthnCredentialStore) Save(credential *model.WebAuthnCredential) (*model.WebAuthnCredential, error) {
+
+	tries := 0
+	for {
+		result, err := s.WebAuthnCredentialStore.Save(credential)
+		if err == nil {
+			return result, nil
+		}
+		if !isRepeatableError(err) {
+			return result, err
+		}
+		tries++
+		if tries >= 3 {
+			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
+			return result, err
+		}
+		timepkg.Sleep(100 * timepkg.Millisecond)
+	}
+
+}
+
+func (s *RetryLayerWebAuthnCredentialStore) UpdateSignCount(id string, signCount int64, lastUsedAt int64) error {
+
+	tries := 0
+	for {
+		err := s.WebAuthnCredentialStore.UpdateSignCount(id, signCount, lastUsedAt)
+		if err == nil {
+			return nil
+		}
+		if !isRepeatableError(err) {
+			return err
+		}
+		tries++
+		if tries >= 3 {
+			err = errors.Wrap(err, "giving up after 3 consecutive repeatable transaction failures")
+			return err
+		}
+		timepkg.Sleep(100 * timepkg.Millisecond)
+	}
+
+}
+
 func (s *RetryLayerWebhookStore) AnalyticsIncomingCount(teamID string, userID string) (int64, error) {
 
 	tries := 0
@@ -16167,11 +16931,13 @@ func New(childStore store.Store) *RetryLayer {
 	}
 
 	newStore.AuditStore = &RetryLayerAuditStore{AuditStore: childStore.Audit(), Root: &newStore}
+	newStore.BatchedEmailNotificationStore = &RetryLayerBatchedEmailNotificationStore{BatchedEmailNotificationStore: childStore.BatchedEmailNotification(), Root: &newStore}
 	newStore.BotStore = &RetryLayerBotStore{BotStore: childStore.Bot(), Root: &newStore}
 	newStore.ChannelStore = &RetryLayerChannelStore{ChannelStore: childStore.Channel(), Root: &newStore}
 	newStore.ChannelBookmarkStore = &RetryLayerChannelBookmarkStore{ChannelBookmarkStore: childStore.ChannelBookmark(), Root: &newStore}
 	newStore.ChannelMemberHistoryStore = &RetryLayerChannelMemberHistoryStore{ChannelMemberHistoryStore: childStore.ChannelMemberHistory(), Root: &newStore}
 	newStore.ClusterDiscoveryStore = &RetryLayerClusterDiscoveryStore{ClusterDiscoveryStore: childStore.ClusterDiscovery(), Root: &newStore}
+	newStore.ClusterPayloadStore = &RetryLayerClusterPayloadStore{ClusterPayloadStore: childStore.ClusterPayload(), Root: &newStore}
 	newStore.CommandStore = &RetryLayerCommandStore{CommandStore: childStore.Command(), Root: &newStore}
 	newStore.CommandWebhookStore = &RetryLayerCommandWebhookStore{CommandWebhookStore: childStore.CommandWebhook(), Root: &newStore}
 	newStore.ComplianceStore = &RetryLayerComplianceStore{ComplianceStore: childStore.Compliance(), Root: &newStore}
@@ -16181,8 +16947,10 @@ func New(childStore store.Store) *RetryLayer {
 	newStore.FileInfoStore = &RetryLayerFileInfoStore{FileInfoStore: childStore.FileInfo(), Root: &newStore}
 	newStore.GroupStore = &RetryLayerGroupStore{GroupStore: childStore.Group(), Root: &newStore}
 	newStore.JobStore = &RetryLayerJobStore{JobStore: childStore.Job(), Root: &newStore}
+	newStore.LegalHoldStore = &RetryLayerLegalHoldStore{LegalHoldStore: childStore.LegalHold(), Root: &newStore}
 	newStore.LicenseStore = &RetryLayerLicenseStore{LicenseStore: childStore.License(), Root: &newStore}
 	newStore.LinkMetadataStore = &RetryLayerLinkMetadataStore{LinkMetadataStore: childStore.LinkMetadata(), Root: &newStore}
+	newStore.MfaRecoveryCodeStore = &RetryLayerMfaRecoveryCodeStore{MfaRecoveryCodeStore: childStore.MfaRecoveryCode(), Root: &newStore}
 	newStore.NotifyAdminStore = &RetryLayerNotifyAdminStore{NotifyAdminStore: childStore.NotifyAdmin(), Root: &newStore}
 	newStore.OAuthStore = &RetryLayerOAuthStore{OAuthStore: childStore.OAuth(), Root: &newStore}
 	newStore.OutgoingOAuthConnectionStore = &RetryLayerOutgoingOAuthConnectionStore{OutgoingOAuthConnectionStore: childStore.OutgoingOAuthConnection(), Root: &newStore}
@@ -16214,6 +16982,7 @@ func New(childStore store.Store) *RetryLayer {
 	newStore.UserStore = &RetryLayerUserStore{UserStore: childStore.User(), Root: &newStore}
 	newStore.UserAccessTokenStore = &RetryLayerUserAccessTokenStore{UserAccessTokenStore: childStore.UserAccessToken(), Root: &newStore}
 	newStore.UserTermsOfServiceStore = &RetryLayerUserTermsOfServiceStore{UserTermsOfServiceStore: childStore.UserTermsOfService(), Root: &newStore}
+	newStore.WebAuthnCredentialStore = &RetryLayerWebAuthnCredentialStore{WebAuthnCredentialStore: childStore.WebAuthnCredential(), Root: &newStore}
 	newStore.WebhookStore = &RetryLayerWebhookStore{WebhookStore: childStore.Webhook(), Root: &newStore}
 	return &newStore
 }
@@ -22,6 +22,8 @@ func genStore() *mocks.Store {
 	mock.On("ChannelMemberHistory").Return(&mocks.ChannelMemberHistoryStore{})
 	mock.On("ChannelBookmark").Return(&mocks.ChannelBookmarkStore{})
 	mock.On("ClusterDiscovery").Return(&mocks.ClusterDiscoveryStore{})
+	mock.On("ClusterPayload").Return(&mocks.ClusterPayloadStore{})
+	mock.On("BatchedEmailNotification").Return(&mocks.BatchedEmailNotificationStore{})
 	mock.On("RemoteCluster").Return(&mocks.RemoteClusterStore{})
 	mock.On("Command").Return(&mocks.CommandStore{})
 	mock.On("CommandWebhook").Return(&mocks.CommandWebhookStore{})
@@ -31,6 +33,7 @@ func genStore() *mocks.Store {
 	mock.On("UploadSession").Return(&mocks.UploadSessionStore{})
 	mock.On("Group").Return(&mocks.GroupStore{})
 	mock.On("Job").Return(&mocks.JobStore{})
+	mock.On("LegalHold").Return(&mocks.LegalHoldStore{})
 	mock.On("License").Return(&mocks.LicenseStore{})
 	mock.On("LinkMetadata").Return(&mocks.LinkMetadataStore{})
 	mock.On("SharedChannel").Return(&mocks.SharedChannelStore{})
@@ -53,6 +56,8 @@ func genStore() *mocks.Store {
 	mock.On("Token").Return(&mocks.TokenStore{})
 	mock.On("User").Return(&mocks.UserStore{})
 	mock.On("UserAccessToken").Return(&mocks.UserAccessTokenStore{})
+	mock.On("WebAuthnCredential").Return(&mocks.WebAuthnCredentialStore{})
+	mock.On("MfaRecoveryCode").Return(&mocks.MfaRecoveryCodeStore{})
 	mock.On("UserTermsOfService").Return(&mocks.UserTermsOfServiceStore{})
 	mock.On("Webhook").Return(&mocks.WebhookStore{})
 	mock.On("NotifyAdmin").Return(&mocks.NotifyAdminStore{})
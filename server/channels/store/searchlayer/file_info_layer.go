@@ -213,9 +213,16 @@ func (s SearchFileInfoStore) Search(rctx request.CTX, paramsList []*model.Search
 				if nErr != nil {
 					return nil, nErr
 				}
+				// Keep the order returned by the engine, which ranks the results.
+				filesById := make(map[string]*model.FileInfo, len(files))
 				for _, f := range files {
-					filesList.AddFileInfo(f)
-					filesList.AddOrder(f.Id)
+					filesById[f.Id] = f
+				}
+				for _, fileId := range fileIds {
+					if f, ok := filesById[fileId]; ok {
+						filesList.AddFileInfo(f)
+						filesList.AddOrder(f.Id)
+					}
 				}
 			}
 			return filesList, nil
@@ -173,8 +173,13 @@ func (s SearchPostStore) searchPostsForUserByEngine(engine searchengine.SearchEn
 		if err != nil {
 			return nil, err
 		}
+		// Keep the order returned by the engine, which ranks the results.
+		postsById := make(map[string]*model.Post, len(posts))
 		for _, p := range posts {
-			if p.DeleteAt == 0 {
+			postsById[p.Id] = p
+		}
+		for _, postId := range postIds {
+			if p, ok := postsById[postId]; ok && p.DeleteAt == 0 {
 				postList.AddPost(p)
 				postList.AddOrder(p.Id)
 			}
@@ -0,0 +1,112 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package sqlstore
+
+import (
+	sq "github.com/mattermost/squirrel"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+)
+
+type SqlBatchedEmailNotificationStore struct {
+	*SqlStore
+}
+
+func newSqlBatchedEmailNotificationStore(sqlStore *SqlStore) store.BatchedEmailNotificationStore {
+	return &SqlBatchedEmailNotificationStore{sqlStore}
+}
+
+func (s *SqlBatchedEmailNotificationStore) Save(notification *model.BatchedEmailNotification) error {
+	notification.PreSave()
+
+	query := s.getQueryBuilder().
+		Insert("BatchedEmailNotifications").
+		Columns("Id", "UserId", "PostId", "TeamName", "CreateAt").
+		Values(notification.Id, notification.UserId, notification.PostId, notification.TeamName, notification.CreateAt)
+
+	if _, err := s.GetMaster().ExecBuilder(query); err != nil {
+		return errors.Wrapf(err, "failed to save BatchedEmailNotification with id=%s", notification.Id)
+	}
+
+	return nil
+}
+
+// batchedEmailNotificationWithPost is a batched notification read along with its post.
+type batchedEmailNotificationWithPost struct {
+	model.Post
+	NotificationId       string
+	NotificationUserId   string
+	NotificationPostId   string
+	NotificationTeamName string
+	NotificationCreateAt int64
+}
+
+func (s *SqlBatchedEmailNotificationStore) GetAll() ([]*model.BatchedEmailNotification, error) {
+	query := s.getQueryBuilder().
+		Select(
+			postSliceCoalesceQuery(),
+			"BatchedEmailNotifications.Id AS NotificationId",
+			"BatchedEmailNotifications.UserId AS NotificationUserId",
+			"BatchedEmailNotifications.PostId AS NotificationPostId",
+			"BatchedEmailNotifications.TeamName AS NotificationTeamName",
+			"BatchedEmailNotifications.CreateAt AS NotificationCreateAt",
+		).
+		From("BatchedEmailNotifications").
+		LeftJoin("Posts ON Posts.Id = BatchedEmailNotifications.PostId").
+		OrderBy("BatchedEmailNotifications.UserId", "BatchedEmailNotifications.CreateAt", "BatchedEmailNotifications.Id")
+
+	// The notifications are deleted right after being sent, which the replicas may not have caught up with.
+	rows := []*batchedEmailNotificationWithPost{}
+	if err := s.GetMaster().SelectBuilder(&rows, query); err != nil {
+		return nil, errors.Wrap(err, "failed to get the BatchedEmailNotifications")
+	}
+
+	notifications := make([]*model.BatchedEmailNotification, 0, len(rows))
+	for _, row := range rows {
+		notification := &model.BatchedEmailNotification{
+			Id:       row.NotificationId,
+			UserId:   row.NotificationUserId,
+			PostId:   row.NotificationPostId,
+			TeamName: row.NotificationTeamName,
+			CreateAt: row.NotificationCreateAt,
+		}
+		if row.Post.Id != "" && row.Post.DeleteAt == 0 {
+			notification.Post = &row.Post
+		}
+		notifications = append(notifications, notification)
+	}
+
+	return notifications, nil
+}
+
+func (s *SqlBatchedEmailNotificationStore) Count() (int64, error) {
+	query := s.getQueryBuilder().
+		Select("COUNT(*)").
+		From("BatchedEmailNotifications")
+
+	var count int64
+	if err := s.GetMaster().GetBuilder(&count, query); err != nil {
+		return 0, errors.Wrap(err, "failed to count the BatchedEmailNotifications")
+	}
+
+	return count, nil
+}
+
+func (s *SqlBatchedEmailNotificationStore) Delete(ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	query := s.getQueryBuilder().
+		Delete("BatchedEmailNotifications").
+		Where(sq.Eq{"Id": ids})
+
+	if _, err := s.GetMaster().ExecBuilder(query); err != nil {
+		return errors.Wrap(err, "failed to delete BatchedEmailNotifications")
+	}
+
+	return nil
+}
@@ -42,7 +42,8 @@ func (s sqlClusterDiscoveryStore) Delete(ClusterDiscovery *model.ClusterDiscover
 		Delete("ClusterDiscovery").
 		Where(sq.Eq{"Type": ClusterDiscovery.Type}).
 		Where(sq.Eq{"ClusterName": ClusterDiscovery.ClusterName}).
-		Where(sq.Eq{"Hostname": ClusterDiscovery.Hostname})
+		Where(sq.Eq{"Hostname": ClusterDiscovery.Hostname}).
+		Where(sq.Eq{"GossipPort": ClusterDiscovery.GossipPort})
 
 	queryString, args, err := query.ToSql()
 	if err != nil {
@@ -68,7 +69,8 @@ func (s sqlClusterDiscoveryStore) Exists(ClusterDiscovery *model.ClusterDiscover
 		From("ClusterDiscovery").
 		Where(sq.Eq{"Type": ClusterDiscovery.Type}).
 		Where(sq.Eq{"ClusterName": ClusterDiscovery.ClusterName}).
-		Where(sq.Eq{"Hostname": ClusterDiscovery.Hostname})
+		Where(sq.Eq{"Hostname": ClusterDiscovery.Hostname}).
+		Where(sq.Eq{"GossipPort": ClusterDiscovery.GossipPort})
 
 	queryString, args, err := query.ToSql()
 	if err != nil {
@@ -109,7 +111,8 @@ func (s sqlClusterDiscoveryStore) SetLastPingAt(ClusterDiscovery *model.ClusterD
 		Set("LastPingAt", model.GetMillis()).
 		Where(sq.Eq{"Type": ClusterDiscovery.Type}).
 		Where(sq.Eq{"ClusterName": ClusterDiscovery.ClusterName}).
-		Where(sq.Eq{"Hostname": ClusterDiscovery.Hostname})
+		Where(sq.Eq{"Hostname": ClusterDiscovery.Hostname}).
+		Where(sq.Eq{"GossipPort": ClusterDiscovery.GossipPort})
 
 	queryString, args, err := query.ToSql()
 	if err != nil {
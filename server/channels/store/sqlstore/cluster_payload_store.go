@@ -0,0 +1,106 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package sqlstore
+
+import (
+	"database/sql"
+
+	sq "github.com/mattermost/squirrel"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+)
+
+type SqlClusterPayloadStore struct {
+	*SqlStore
+}
+
+func newSqlClusterPayloadStore(sqlStore *SqlStore) store.ClusterPayloadStore {
+	return &SqlClusterPayloadStore{sqlStore}
+}
+
+// requirePostgres fails on MySQL, as the ClusterPayloads table is only
+// used by the database cluster message transport, which requires PostgreSQL.
+func (s *SqlClusterPayloadStore) requirePostgres() error {
+	if s.DriverName() != model.DatabaseDriverPostgres {
+		return store.NewErrNotImplemented("the cluster payloads are only supported by PostgreSQL")
+	}
+	return nil
+}
+
+func (s *SqlClusterPayloadStore) Save(payload *model.ClusterPayload) error {
+	if err := s.requirePostgres(); err != nil {
+		return err
+	}
+
+	payload.PreSave()
+
+	query := s.getQueryBuilder().
+		Insert("ClusterPayloads").
+		Columns("Id", "Data", "CreateAt").
+		Values(payload.Id, payload.Data, payload.CreateAt)
+
+	if _, err := s.GetMaster().ExecBuilder(query); err != nil {
+		return errors.Wrapf(err, "failed to save ClusterPayload with id=%s", payload.Id)
+	}
+
+	return nil
+}
+
+func (s *SqlClusterPayloadStore) Get(id string) (*model.ClusterPayload, error) {
+	if err := s.requirePostgres(); err != nil {
+		return nil, err
+	}
+
+	query := s.getQueryBuilder().
+		Select("Id", "Data", "CreateAt").
+		From("ClusterPayloads").
+		Where(sq.Eq{"Id": id})
+
+	// The payload is read right after being saved, which the replicas may not have caught up with.
+	var payload model.ClusterPayload
+	if err := s.GetMaster().GetBuilder(&payload, query); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.NewErrNotFound("ClusterPayload", id)
+		}
+		return nil, errors.Wrapf(err, "failed to get ClusterPayload with id=%s", id)
+	}
+
+	return &payload, nil
+}
+
+func (s *SqlClusterPayloadStore) DeleteOlderThan(createAt int64) (int64, error) {
+	if err := s.requirePostgres(); err != nil {
+		return 0, err
+	}
+
+	query := s.getQueryBuilder().
+		Delete("ClusterPayloads").
+		Where(sq.Lt{"CreateAt": createAt})
+
+	res, err := s.GetMaster().ExecBuilder(query)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to delete ClusterPayloads")
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get rows affected")
+	}
+
+	return count, nil
+}
+
+func (s *SqlClusterPayloadStore) Notify(channel, message string) error {
+	if err := s.requirePostgres(); err != nil {
+		return err
+	}
+
+	if _, err := s.GetMaster().Exec("SELECT pg_notify($1, $2)", channel, message); err != nil {
+		return errors.Wrapf(err, "failed to notify channel=%s", channel)
+	}
+
+	return nil
+}
@@ -330,6 +330,10 @@ func (s SqlComplianceStore) MessageExport(c request.CTX, cursor model.MessageExp
 		builder = builder.Where(sq.LtOrEq{"Posts.UpdateAt": cursor.UntilUpdateAt})
 	}
 
+	if cursor.LegalHoldId != "" {
+		builder = builder.Where(heldByLegalHold(cursor.LegalHoldId, "Posts.UserId", "Posts.ChannelId", "Posts.CreateAt"))
+	}
+
 	query, args, err := builder.ToSql()
 	if err != nil {
 		return nil, cursor, errors.Wrap(err, "unable to construct query to export messages")
@@ -21,51 +21,57 @@ import (
 )
 
 type fileInfoWithChannelID struct {
-	Id              string
-	CreatorId       string
-	PostId          string
-	ChannelId       string
-	CreateAt        int64
-	UpdateAt        int64
-	DeleteAt        int64
-	Path            string
-	ThumbnailPath   string
-	PreviewPath     string
-	Name            string
-	Extension       string
-	Size            int64
-	MimeType        string
-	Width           int
-	Height          int
-	HasPreviewImage bool
-	MiniPreview     *[]byte
-	Content         string
-	RemoteId        *string
-	Archived        bool
+	Id               string
+	CreatorId        string
+	PostId           string
+	ChannelId        string
+	CreateAt         int64
+	UpdateAt         int64
+	DeleteAt         int64
+	Path             string
+	ThumbnailPath    string
+	PreviewPath      string
+	Name             string
+	Extension        string
+	Size             int64
+	MimeType         string
+	Width            int
+	Height           int
+	HasPreviewImage  bool
+	MiniPreview      *[]byte
+	Content          string
+	RemoteId         *string
+	Archived         bool
+	ContentHash      string
+	QuarantinedAt    int64
+	QuarantineReason string
 }
 
 func (fi fileInfoWithChannelID) ToModel() *model.FileInfo {
 	return &model.FileInfo{
-		Id:              fi.Id,
-		CreatorId:       fi.CreatorId,
-		PostId:          fi.PostId,
-		ChannelId:       fi.ChannelId,
-		CreateAt:        fi.CreateAt,
-		UpdateAt:        fi.UpdateAt,
-		DeleteAt:        fi.DeleteAt,
-		Path:            fi.Path,
-		ThumbnailPath:   fi.ThumbnailPath,
-		PreviewPath:     fi.PreviewPath,
-		Name:            fi.Name,
-		Extension:       fi.Extension,
-		Size:            fi.Size,
-		MimeType:        fi.MimeType,
-		Width:           fi.Width,
-		Height:          fi.Height,
-		HasPreviewImage: fi.HasPreviewImage,
-		MiniPreview:     fi.MiniPreview,
-		Content:         fi.Content,
-		RemoteId:        fi.RemoteId,
+		Id:               fi.Id,
+		CreatorId:        fi.CreatorId,
+		PostId:           fi.PostId,
+		ChannelId:        fi.ChannelId,
+		CreateAt:         fi.CreateAt,
+		UpdateAt:         fi.UpdateAt,
+		DeleteAt:         fi.DeleteAt,
+		Path:             fi.Path,
+		ThumbnailPath:    fi.ThumbnailPath,
+		PreviewPath:      fi.PreviewPath,
+		Name:             fi.Name,
+		Extension:        fi.Extension,
+		Size:             fi.Size,
+		MimeType:         fi.MimeType,
+		Width:            fi.Width,
+		Height:           fi.Height,
+		HasPreviewImage:  fi.HasPreviewImage,
+		MiniPreview:      fi.MiniPreview,
+		Content:          fi.Content,
+		RemoteId:         fi.RemoteId,
+		ContentHash:      fi.ContentHash,
+		QuarantinedAt:    fi.QuarantinedAt,
+		QuarantineReason: fi.QuarantineReason,
 	}
 }
 
@@ -106,6 +112,9 @@ func newSqlFileInfoStore(sqlStore *SqlStore, metrics einterfaces.MetricsInterfac
 		"Coalesce(FileInfo.Content, '') AS Content",
 		"Coalesce(FileInfo.RemoteId, '') AS RemoteId",
 		"FileInfo.Archived",
+		"FileInfo.ContentHash",
+		"FileInfo.QuarantinedAt",
+		"FileInfo.QuarantineReason",
 	}
 
 	return s
@@ -120,10 +129,12 @@ func (fs SqlFileInfoStore) Save(rctx request.CTX, info *model.FileInfo) (*model.
 	query := `
 		INSERT INTO FileInfo
 		(Id, CreatorId, PostId, ChannelId, CreateAt, UpdateAt, DeleteAt, Path, ThumbnailPath, PreviewPath,
-			Name, Extension, Size, MimeType, Width, Height, HasPreviewImage, MiniPreview, Content, RemoteId)
+			Name, Extension, Size, MimeType, Width, Height, HasPreviewImage, MiniPreview, Content, RemoteId, ContentHash,
+			QuarantinedAt, QuarantineReason)
 		VALUES
 		(:Id, :CreatorId, :PostId, :ChannelId, :CreateAt, :UpdateAt, :DeleteAt, :Path, :ThumbnailPath, :PreviewPath,
-			:Name, :Extension, :Size, :MimeType, :Width, :Height, :HasPreviewImage, :MiniPreview, :Content, :RemoteId)
+			:Name, :Extension, :Size, :MimeType, :Width, :Height, :HasPreviewImage, :MiniPreview, :Content, :RemoteId, :ContentHash,
+			:QuarantinedAt, :QuarantineReason)
 	`
 
 	if _, err := fs.GetMaster().NamedExec(query, info); err != nil {
@@ -174,21 +185,24 @@ func (fs SqlFileInfoStore) Upsert(rctx request.CTX, info *model.FileInfo) (*mode
 	queryString, args, err := fs.getQueryBuilder().
 		Update("FileInfo").
 		SetMap(map[string]any{
-			"UpdateAt":        info.UpdateAt,
-			"DeleteAt":        info.DeleteAt,
-			"Path":            info.Path,
-			"ThumbnailPath":   info.ThumbnailPath,
-			"PreviewPath":     info.PreviewPath,
-			"Name":            info.Name,
-			"Extension":       info.Extension,
-			"Size":            info.Size,
-			"MimeType":        info.MimeType,
-			"Width":           info.Width,
-			"Height":          info.Height,
-			"HasPreviewImage": info.HasPreviewImage,
-			"MiniPreview":     info.MiniPreview,
-			"Content":         info.Content,
-			"RemoteId":        info.RemoteId,
+			"UpdateAt":         info.UpdateAt,
+			"DeleteAt":         info.DeleteAt,
+			"Path":             info.Path,
+			"ThumbnailPath":    info.ThumbnailPath,
+			"PreviewPath":      info.PreviewPath,
+			"Name":             info.Name,
+			"Extension":        info.Extension,
+			"Size":             info.Size,
+			"MimeType":         info.MimeType,
+			"Width":            info.Width,
+			"Height":           info.Height,
+			"HasPreviewImage":  info.HasPreviewImage,
+			"MiniPreview":      info.MiniPreview,
+			"Content":          info.Content,
+			"RemoteId":         info.RemoteId,
+			"ContentHash":      info.ContentHash,
+			"QuarantinedAt":    info.QuarantinedAt,
+			"QuarantineReason": info.QuarantineReason,
 		}).
 		Where(sq.Eq{"Id": info.Id}).
 		ToSql()
@@ -515,9 +529,13 @@ func (fs SqlFileInfoStore) PermanentDeleteBatch(rctx request.CTX, endTime int64,
 }
 
 func (fs SqlFileInfoStore) PermanentDeleteByUser(rctx request.CTX, userId string) (int64, error) {
-	query := "DELETE from FileInfo WHERE CreatorId = ?"
+	// The files covered by a legal hold are kept.
+	query := fs.getQueryBuilder().
+		Delete("FileInfo").
+		Where(sq.Eq{"FileInfo.CreatorId": userId}).
+		Where(notHeldByActiveLegalHold("FileInfo.CreatorId", "FileInfo.ChannelId", "FileInfo.CreateAt"))
 
-	sqlResult, err := fs.GetMaster().Exec(query, userId)
+	sqlResult, err := fs.GetMaster().ExecBuilder(query)
 	if err != nil {
 		return 0, errors.Wrapf(err, "failed to delete FileInfo with creatorId=%s", userId)
 	}
@@ -821,6 +839,166 @@ func (fs *SqlFileInfoStore) GetUptoNSizeFileTime(n int64) (int64, error) {
 	return createAt, nil
 }
 
+func (fs SqlFileInfoStore) CountByContentHash(hash string) (int64, error) {
+	query := fs.getQueryBuilder().
+		Select("COUNT(*)").
+		From("FileInfo").
+		Where(sq.Eq{"ContentHash": hash})
+
+	// Reading from the master avoids removing a blob that a FileInfo saved
+	// moments ago still references.
+	var count int64
+	if err := fs.GetMaster().GetBuilder(&count, query); err != nil {
+		return 0, errors.Wrapf(err, "failed to count FileInfos with contentHash=%s", hash)
+	}
+	return count, nil
+}
+
+// GetFilesBatchForDeduplication returns the attachments stored before their
+// content was deduplicated, ordered by id and starting after afterID.
+func (fs SqlFileInfoStore) GetFilesBatchForDeduplication(afterID string, limit int) ([]*model.FileInfo, error) {
+	query := fs.getQueryBuilder().
+		Select(fs.queryFields...).
+		From("FileInfo").
+		Where(sq.Gt{"FileInfo.Id": afterID}).
+		Where(sq.Eq{"FileInfo.ContentHash": ""}).
+		Where(sq.NotEq{"FileInfo.Path": ""}).
+		// Files uploaded for an import are not attachments and are removed
+		// from their own path by the import delete job.
+		Where(sq.Or{
+			sq.NotEq{"FileInfo.PostId": ""},
+			sq.NotEq{"FileInfo.ChannelId": ""},
+		}).
+		OrderBy("FileInfo.Id ASC").
+		Limit(uint64(limit))
+
+	infos := []*model.FileInfo{}
+	if err := fs.GetMaster().SelectBuilder(&infos, query); err != nil {
+		return nil, errors.Wrap(err, "failed to get FileInfos for deduplication")
+	}
+	return infos, nil
+}
+
+func (fs SqlFileInfoStore) CountByPath(path string) (int64, error) {
+	query := fs.getQueryBuilder().
+		Select("COUNT(*)").
+		From("FileInfo").
+		Where(sq.Eq{"Path": path})
+
+	var count int64
+	if err := fs.GetMaster().GetBuilder(&count, query); err != nil {
+		return 0, errors.Wrapf(err, "failed to count FileInfos with path=%s", path)
+	}
+	return count, nil
+}
+
+func (fs SqlFileInfoStore) UpdateContentHash(rctx request.CTX, fileIDs []string, newPath, hash string) error {
+	query := fs.getQueryBuilder().
+		Update("FileInfo").
+		Set("Path", newPath).
+		Set("ContentHash", hash).
+		Where(sq.Eq{"Id": fileIDs})
+
+	if _, err := fs.GetMaster().ExecBuilder(query); err != nil {
+		return errors.Wrapf(err, "failed to update the content hash of FileInfos with ids=%v", fileIDs)
+	}
+	return nil
+}
+
+func (fs SqlFileInfoStore) Quarantine(rctx request.CTX, fileID, reason string) error {
+	query := fs.getQueryBuilder().
+		Update("FileInfo").
+		Set("QuarantinedAt", model.GetMillis()).
+		Set("QuarantineReason", reason).
+		Where(sq.Eq{"Id": fileID})
+
+	if _, err := fs.GetMaster().ExecBuilder(query); err != nil {
+		return errors.Wrapf(err, "failed to quarantine FileInfo with id=%s", fileID)
+	}
+	return nil
+}
+
+// CountForRetentionPolicies counts the files attached to the posts which
+// PermanentDeleteBatchForRetentionPolicies of the post store would delete.
+func (fs SqlFileInfoStore) CountForRetentionPolicies(now, globalPolicyEndTime int64) (int64, error) {
+	builder := fs.getQueryBuilder().
+		Select("FileInfo.Id").
+		From("FileInfo").
+		InnerJoin("Posts ON FileInfo.PostId = Posts.Id").
+		Where(notHeldByActiveLegalHold("Posts.UserId", "Posts.ChannelId", "Posts.CreateAt"))
+	return genericCountForRetentionPolicies(RetentionPolicyBatchDeletionInfo{
+		BaseBuilder:         builder,
+		Table:               "Posts",
+		TimeColumn:          "CreateAt",
+		PrimaryKeys:         []string{"Id"},
+		ChannelIDTable:      "Posts",
+		NowMillis:           now,
+		GlobalPolicyEndTime: globalPolicyEndTime,
+	}, fs.SqlStore)
+}
+
+func (fs SqlFileInfoStore) GetByPostIds(postIDs []string) ([]*model.FileInfo, error) {
+	query := fs.getQueryBuilder().
+		Select(fs.queryFields...).
+		From("FileInfo").
+		Where(sq.Eq{"FileInfo.PostId": postIDs})
+
+	infos := []*model.FileInfo{}
+	if err := fs.GetMaster().SelectBuilder(&infos, query); err != nil {
+		return nil, errors.Wrap(err, "failed to find FileInfos for posts")
+	}
+	return infos, nil
+}
+
+// GetBatchForRetention returns the oldest files created before endTime, deleted
+// or not.
+func (fs SqlFileInfoStore) GetBatchForRetention(endTime int64, limit int) ([]*model.FileInfo, error) {
+	query := fs.getQueryBuilder().
+		Select(fs.queryFields...).
+		From("FileInfo").
+		Where(sq.Lt{"FileInfo.CreateAt": endTime}).
+		Where(notHeldByActiveLegalHold("FileInfo.CreatorId", "FileInfo.ChannelId", "FileInfo.CreateAt")).
+		OrderBy("FileInfo.CreateAt ASC", "FileInfo.Id ASC").
+		Limit(uint64(limit))
+
+	infos := []*model.FileInfo{}
+	if err := fs.GetMaster().SelectBuilder(&infos, query); err != nil {
+		return nil, errors.Wrapf(err, "failed to find FileInfos created before %d", endTime)
+	}
+	return infos, nil
+}
+
+func (fs SqlFileInfoStore) CountForRetention(endTime int64) (int64, error) {
+	query := fs.getQueryBuilder().
+		Select("COUNT(*)").
+		From("FileInfo").
+		Where(sq.Lt{"FileInfo.CreateAt": endTime}).
+		Where(notHeldByActiveLegalHold("FileInfo.CreatorId", "FileInfo.ChannelId", "FileInfo.CreateAt"))
+
+	var count int64
+	if err := fs.GetReplica().GetBuilder(&count, query); err != nil {
+		return 0, errors.Wrapf(err, "failed to count FileInfos created before %d", endTime)
+	}
+	return count, nil
+}
+
+func (fs SqlFileInfoStore) PermanentDeleteByIds(rctx request.CTX, fileIDs []string) (int64, error) {
+	query := fs.getQueryBuilder().
+		Delete("FileInfo").
+		Where(sq.Eq{"Id": fileIDs})
+
+	result, err := fs.GetMaster().ExecBuilder(query)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to delete FileInfos")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "unable to retrieve rows affected")
+	}
+	return rowsAffected, nil
+}
+
 func (fs SqlFileInfoStore) RestoreForPostByIds(rctx request.CTX, postId string, fileIDs []string) error {
 	query := fs.getQueryBuilder().
 		Update("FileInfo").
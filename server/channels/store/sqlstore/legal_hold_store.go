@@ -0,0 +1,248 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package sqlstore
+
+import (
+	"database/sql"
+
+	sq "github.com/mattermost/squirrel"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+)
+
+// legalHoldCoverage returns a subquery selecting the legal holds, matching the
+// given filter, which cover a row given the columns holding the author, the
+// channel and the creation time of the row.
+func legalHoldCoverage(filter, userIDColumn, channelIDColumn, createAtColumn string) string {
+	return `SELECT 1 FROM LegalHolds
+		WHERE ` + filter + `
+		AND LegalHolds.StartAt <= ` + createAtColumn + `
+		AND (LegalHolds.EndAt = 0 OR LegalHolds.EndAt >= ` + createAtColumn + `)
+		AND (
+			EXISTS (SELECT 1 FROM LegalHoldUsers WHERE LegalHoldUsers.LegalHoldId = LegalHolds.Id AND LegalHoldUsers.UserId = ` + userIDColumn + `)
+			OR EXISTS (SELECT 1 FROM LegalHoldChannels WHERE LegalHoldChannels.LegalHoldId = LegalHolds.Id AND LegalHoldChannels.ChannelId = ` + channelIDColumn + `)
+		)`
+}
+
+// heldByActiveLegalHold returns a condition matching the rows covered by an
+// active legal hold.
+func heldByActiveLegalHold(userIDColumn, channelIDColumn, createAtColumn string) sq.Sqlizer {
+	return sq.Expr("EXISTS (" + legalHoldCoverage("LegalHolds.ReleasedAt = 0", userIDColumn, channelIDColumn, createAtColumn) + ")")
+}
+
+// notHeldByActiveLegalHold returns a condition excluding the rows covered by an
+// active legal hold.
+func notHeldByActiveLegalHold(userIDColumn, channelIDColumn, createAtColumn string) sq.Sqlizer {
+	return sq.Expr("NOT EXISTS (" + legalHoldCoverage("LegalHolds.ReleasedAt = 0", userIDColumn, channelIDColumn, createAtColumn) + ")")
+}
+
+// heldByLegalHold returns a condition matching the rows covered by the given
+// legal hold, whether it is active or not.
+func heldByLegalHold(legalHoldID, userIDColumn, channelIDColumn, createAtColumn string) sq.Sqlizer {
+	return sq.Expr("EXISTS ("+legalHoldCoverage("LegalHolds.Id = ?", userIDColumn, channelIDColumn, createAtColumn)+")", legalHoldID)
+}
+
+type SqlLegalHoldStore struct {
+	*SqlStore
+}
+
+func newSqlLegalHoldStore(sqlStore *SqlStore) store.LegalHoldStore {
+	return &SqlLegalHoldStore{
+		SqlStore: sqlStore,
+	}
+}
+
+func (s *SqlLegalHoldStore) columns() []string {
+	return []string{
+		"Id",
+		"DisplayName",
+		"Description",
+		"CreatorId",
+		"StartAt",
+		"EndAt",
+		"CreateAt",
+		"UpdateAt",
+		"ReleasedAt",
+	}
+}
+
+func (s *SqlLegalHoldStore) Save(hold *model.LegalHold) (_ *model.LegalHold, err error) {
+	hold.PreSave()
+	if appErr := hold.IsValid(); appErr != nil {
+		return nil, appErr
+	}
+
+	transaction, err := s.GetMaster().Beginx()
+	if err != nil {
+		return nil, errors.Wrap(err, "begin_transaction")
+	}
+	defer finalizeTransactionX(transaction, &err)
+
+	query := s.getQueryBuilder().
+		Insert("LegalHolds").
+		Columns(s.columns()...).
+		Values(hold.Id, hold.DisplayName, hold.Description, hold.CreatorId, hold.StartAt, hold.EndAt, hold.CreateAt, hold.UpdateAt, hold.ReleasedAt)
+	if _, err = transaction.ExecBuilder(query); err != nil {
+		return nil, errors.Wrap(err, "failed to save LegalHold")
+	}
+
+	if len(hold.UserIds) > 0 {
+		usersQuery := s.getQueryBuilder().Insert("LegalHoldUsers").Columns("LegalHoldId", "UserId")
+		for _, userID := range hold.UserIds {
+			usersQuery = usersQuery.Values(hold.Id, userID)
+		}
+		if _, err = transaction.ExecBuilder(usersQuery); err != nil {
+			return nil, errors.Wrap(err, "failed to save LegalHoldUsers")
+		}
+	}
+
+	if len(hold.ChannelIds) > 0 {
+		channelsQuery := s.getQueryBuilder().Insert("LegalHoldChannels").Columns("LegalHoldId", "ChannelId")
+		for _, channelID := range hold.ChannelIds {
+			channelsQuery = channelsQuery.Values(hold.Id, channelID)
+		}
+		if _, err = transaction.ExecBuilder(channelsQuery); err != nil {
+			return nil, errors.Wrap(err, "failed to save LegalHoldChannels")
+		}
+	}
+
+	if err = transaction.Commit(); err != nil {
+		return nil, errors.Wrap(err, "commit_transaction")
+	}
+
+	return hold, nil
+}
+
+func (s *SqlLegalHoldStore) Get(id string) (*model.LegalHold, error) {
+	query := s.getQueryBuilder().
+		Select(s.columns()...).
+		From("LegalHolds").
+		Where(sq.Eq{"Id": id})
+
+	var hold model.LegalHold
+	if err := s.GetReplica().GetBuilder(&hold, query); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.NewErrNotFound("LegalHold", id)
+		}
+		return nil, errors.Wrapf(err, "failed to get LegalHold with id=%s", id)
+	}
+
+	if err := s.loadMembers([]*model.LegalHold{&hold}); err != nil {
+		return nil, err
+	}
+
+	return &hold, nil
+}
+
+func (s *SqlLegalHoldStore) GetAll(offset, limit int, includeReleased bool) ([]*model.LegalHold, error) {
+	query := s.getQueryBuilder().
+		Select(s.columns()...).
+		From("LegalHolds").
+		OrderBy("CreateAt DESC", "Id").
+		Offset(uint64(offset)).
+		Limit(uint64(limit))
+	if !includeReleased {
+		query = query.Where(sq.Eq{"ReleasedAt": 0})
+	}
+
+	return s.getHolds(query)
+}
+
+func (s *SqlLegalHoldStore) GetActive() ([]*model.LegalHold, error) {
+	query := s.getQueryBuilder().
+		Select(s.columns()...).
+		From("LegalHolds").
+		Where(sq.Eq{"ReleasedAt": 0}).
+		OrderBy("CreateAt DESC", "Id")
+
+	return s.getHolds(query)
+}
+
+func (s *SqlLegalHoldStore) getHolds(query sq.SelectBuilder) ([]*model.LegalHold, error) {
+	holds := []*model.LegalHold{}
+	if err := s.GetReplica().SelectBuilder(&holds, query); err != nil {
+		return nil, errors.Wrap(err, "failed to find LegalHolds")
+	}
+
+	if err := s.loadMembers(holds); err != nil {
+		return nil, err
+	}
+
+	return holds, nil
+}
+
+// loadMembers fills the ids of the users and channels of the given holds.
+func (s *SqlLegalHoldStore) loadMembers(holds []*model.LegalHold) error {
+	if len(holds) == 0 {
+		return nil
+	}
+
+	byID := make(map[string]*model.LegalHold, len(holds))
+	ids := make([]string, 0, len(holds))
+	for _, hold := range holds {
+		hold.UserIds = []string{}
+		hold.ChannelIds = []string{}
+		byID[hold.Id] = hold
+		ids = append(ids, hold.Id)
+	}
+
+	var users []struct {
+		LegalHoldId string
+		UserId      string
+	}
+	usersQuery := s.getQueryBuilder().
+		Select("LegalHoldId", "UserId").
+		From("LegalHoldUsers").
+		Where(sq.Eq{"LegalHoldId": ids}).
+		OrderBy("UserId")
+	if err := s.GetReplica().SelectBuilder(&users, usersQuery); err != nil {
+		return errors.Wrap(err, "failed to find LegalHoldUsers")
+	}
+	for _, user := range users {
+		byID[user.LegalHoldId].UserIds = append(byID[user.LegalHoldId].UserIds, user.UserId)
+	}
+
+	var channels []struct {
+		LegalHoldId string
+		ChannelId   string
+	}
+	channelsQuery := s.getQueryBuilder().
+		Select("LegalHoldId", "ChannelId").
+		From("LegalHoldChannels").
+		Where(sq.Eq{"LegalHoldId": ids}).
+		OrderBy("ChannelId")
+	if err := s.GetReplica().SelectBuilder(&channels, channelsQuery); err != nil {
+		return errors.Wrap(err, "failed to find LegalHoldChannels")
+	}
+	for _, channel := range channels {
+		byID[channel.LegalHoldId].ChannelIds = append(byID[channel.LegalHoldId].ChannelIds, channel.ChannelId)
+	}
+
+	return nil
+}
+
+func (s *SqlLegalHoldStore) Release(id string, releasedAt int64) error {
+	query := s.getQueryBuilder().
+		Update("LegalHolds").
+		Set("ReleasedAt", releasedAt).
+		Set("UpdateAt", releasedAt).
+		Where(sq.Eq{"Id": id, "ReleasedAt": 0})
+
+	result, err := s.GetMaster().ExecBuilder(query)
+	if err != nil {
+		return errors.Wrapf(err, "failed to release LegalHold with id=%s", id)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "unable to retrieve rows affected")
+	}
+	if rowsAffected == 0 {
+		return store.NewErrNotFound("LegalHold", id)
+	}
+
+	return nil
+}
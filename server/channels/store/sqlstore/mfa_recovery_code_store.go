@@ -0,0 +1,100 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package sqlstore
+
+import (
+	sq "github.com/mattermost/squirrel"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+)
+
+type SqlMfaRecoveryCodeStore struct {
+	*SqlStore
+}
+
+func newSqlMfaRecoveryCodeStore(sqlStore *SqlStore) store.MfaRecoveryCodeStore {
+	return &SqlMfaRecoveryCodeStore{sqlStore}
+}
+
+func (s *SqlMfaRecoveryCodeStore) ReplaceForUser(userID string, codeHashes []string) (err error) {
+	transaction, err := s.GetMaster().Beginx()
+	if err != nil {
+		return errors.Wrap(err, "begin_transaction")
+	}
+	defer finalizeTransactionX(transaction, &err)
+
+	query := s.getQueryBuilder().
+		Delete("MfaRecoveryCodes").
+		Where(sq.Eq{"UserId": userID})
+
+	if _, err = transaction.ExecBuilder(query); err != nil {
+		return errors.Wrapf(err, "failed to delete MfaRecoveryCodes with userId=%s", userID)
+	}
+
+	if len(codeHashes) > 0 {
+		createAt := model.GetMillis()
+		insert := s.getQueryBuilder().
+			Insert("MfaRecoveryCodes").
+			Columns("UserId", "CodeHash", "CreateAt")
+		for _, codeHash := range codeHashes {
+			insert = insert.Values(userID, codeHash, createAt)
+		}
+
+		if _, err = transaction.ExecBuilder(insert); err != nil {
+			return errors.Wrapf(err, "failed to save MfaRecoveryCodes with userId=%s", userID)
+		}
+	}
+
+	if err = transaction.Commit(); err != nil {
+		return errors.Wrap(err, "commit_transaction")
+	}
+
+	return nil
+}
+
+func (s *SqlMfaRecoveryCodeStore) GetForUser(userID string) ([]string, error) {
+	query := s.getQueryBuilder().
+		Select("CodeHash").
+		From("MfaRecoveryCodes").
+		Where(sq.Eq{"UserId": userID})
+
+	codeHashes := []string{}
+	if err := s.GetMaster().SelectBuilder(&codeHashes, query); err != nil {
+		return nil, errors.Wrapf(err, "failed to get MfaRecoveryCodes with userId=%s", userID)
+	}
+
+	return codeHashes, nil
+}
+
+func (s *SqlMfaRecoveryCodeStore) Consume(userID, codeHash string) (bool, error) {
+	query := s.getQueryBuilder().
+		Delete("MfaRecoveryCodes").
+		Where(sq.Eq{"UserId": userID, "CodeHash": codeHash})
+
+	res, err := s.GetMaster().ExecBuilder(query)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to delete MfaRecoveryCode with userId=%s", userID)
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get rows affected")
+	}
+
+	return count > 0, nil
+}
+
+func (s *SqlMfaRecoveryCodeStore) DeleteAllForUser(userID string) error {
+	query := s.getQueryBuilder().
+		Delete("MfaRecoveryCodes").
+		Where(sq.Eq{"UserId": userID})
+
+	if _, err := s.GetMaster().ExecBuilder(query); err != nil {
+		return errors.Wrapf(err, "failed to delete MfaRecoveryCodes with userId=%s", userID)
+	}
+
+	return nil
+}
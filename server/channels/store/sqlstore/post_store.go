@@ -1023,12 +1023,18 @@ func (s *SqlPostStore) permanentDeleteAllCommentByUser(userId string) (err error
 	}
 	defer finalizeTransactionX(transaction, &err)
 
-	err = transaction.Select(&results, "Select Id, RootId FROM Posts WHERE UserId = ? AND RootId != ''", userId)
+	// The comments covered by a legal hold are kept.
+	where := sq.And{
+		sq.Eq{"Posts.UserId": userId},
+		sq.NotEq{"Posts.RootId": ""},
+		notHeldByActiveLegalHold("Posts.UserId", "Posts.ChannelId", "Posts.CreateAt"),
+	}
+	err = transaction.SelectBuilder(&results, s.getQueryBuilder().Select("Id", "RootId").From("Posts").Where(where))
 	if err != nil {
 		return errors.Wrapf(err, "failed to fetch Posts with userId=%s", userId)
 	}
 
-	_, err = transaction.Exec("DELETE FROM Posts WHERE UserId = ? AND RootId != ''", userId)
+	_, err = transaction.ExecBuilder(s.getQueryBuilder().Delete("Posts").Where(where))
 	if err != nil {
 		return errors.Wrapf(err, "failed to delete Posts with userId=%s", userId)
 	}
@@ -1068,8 +1074,19 @@ func (s *SqlPostStore) PermanentDeleteByUser(rctx request.CTX, userId string) er
 	const maxLoops = 10
 	count := 0
 	for {
+		// The root posts covered by a legal hold, or with replies covered by
+		// one, are kept.
 		var ids []string
-		err := s.GetMaster().Select(&ids, "SELECT Id FROM Posts WHERE UserId = ? LIMIT 1000", userId)
+		query := s.getQueryBuilder().
+			Select("Posts.Id").
+			From("Posts").
+			Where(sq.And{
+				sq.Eq{"Posts.UserId": userId},
+				notHeldByActiveLegalHold("Posts.UserId", "Posts.ChannelId", "Posts.CreateAt"),
+				sq.Expr("NOT EXISTS (SELECT 1 FROM Posts Replies WHERE Replies.RootId = Posts.Id AND ?)", heldByActiveLegalHold("Replies.UserId", "Replies.ChannelId", "Replies.CreateAt")),
+			}).
+			Limit(1000)
+		err := s.GetMaster().SelectBuilder(&ids, query)
 		if err != nil {
 			return errors.Wrapf(err, "failed to find Posts with userId=%s", userId)
 		}
@@ -1096,6 +1113,7 @@ func (s *SqlPostStore) PermanentDeleteByUser(rctx request.CTX, userId string) er
 // deletes all threads and thread memberships
 // deletes all reactions
 // no thread comment cleanup needed, since we are deleting threads and thread memberships
+// The posts covered by a legal hold, and the root posts with replies covered by one, are kept.
 func (s *SqlPostStore) PermanentDeleteByChannel(rctx request.CTX, channelId string) (err error) {
 	transaction, err := s.GetMaster().Beginx()
 	if err != nil {
@@ -1106,7 +1124,18 @@ func (s *SqlPostStore) PermanentDeleteByChannel(rctx request.CTX, channelId stri
 	id := ""
 	for {
 		ids := []string{}
-		err = transaction.Select(&ids, "SELECT Id FROM Posts WHERE ChannelId = ? AND Id > ? ORDER BY Id ASC LIMIT 500", channelId, id)
+		selectQuery := s.getQueryBuilder().
+			Select("Posts.Id").
+			From("Posts").
+			Where(sq.And{
+				sq.Eq{"Posts.ChannelId": channelId},
+				sq.Gt{"Posts.Id": id},
+				notHeldByActiveLegalHold("Posts.UserId", "Posts.ChannelId", "Posts.CreateAt"),
+				sq.Expr("NOT EXISTS (SELECT 1 FROM Posts Replies WHERE Replies.RootId = Posts.Id AND ?)", heldByActiveLegalHold("Replies.UserId", "Replies.ChannelId", "Replies.CreateAt")),
+			}).
+			OrderBy("Posts.Id ASC").
+			Limit(500)
+		err = transaction.SelectBuilder(&ids, selectQuery)
 		if err != nil {
 			return errors.Wrapf(err, "failed to fetch Posts with channelId=%s", channelId)
 		}
@@ -2537,7 +2566,8 @@ func (s *SqlPostStore) GetPostsBatchForIndexing(startTime int64, startPostID str
 func (s *SqlPostStore) PermanentDeleteBatchForRetentionPolicies(now, globalPolicyEndTime, limit int64, cursor model.RetentionPolicyCursor) (int64, model.RetentionPolicyCursor, error) {
 	builder := s.getQueryBuilder().
 		Select("Posts.Id").
-		From("Posts")
+		From("Posts").
+		Where(notHeldByActiveLegalHold("Posts.UserId", "Posts.ChannelId", "Posts.CreateAt"))
 	return genericPermanentDeleteBatchForRetentionPolicies(RetentionPolicyBatchDeletionInfo{
 		BaseBuilder:         builder,
 		Table:               "Posts",
@@ -2551,6 +2581,24 @@ func (s *SqlPostStore) PermanentDeleteBatchForRetentionPolicies(now, globalPolic
 	}, s.SqlStore, cursor)
 }
 
+// CountForRetentionPolicies counts the posts which PermanentDeleteBatchForRetentionPolicies
+// would delete.
+func (s *SqlPostStore) CountForRetentionPolicies(now, globalPolicyEndTime int64) (int64, error) {
+	builder := s.getQueryBuilder().
+		Select("Posts.Id").
+		From("Posts").
+		Where(notHeldByActiveLegalHold("Posts.UserId", "Posts.ChannelId", "Posts.CreateAt"))
+	return genericCountForRetentionPolicies(RetentionPolicyBatchDeletionInfo{
+		BaseBuilder:         builder,
+		Table:               "Posts",
+		TimeColumn:          "CreateAt",
+		PrimaryKeys:         []string{"Id"},
+		ChannelIDTable:      "Posts",
+		NowMillis:           now,
+		GlobalPolicyEndTime: globalPolicyEndTime,
+	}, s.SqlStore)
+}
+
 func (s *SqlPostStore) PermanentDeleteBatch(endTime int64, limit int64) (int64, error) {
 	var query string
 	if s.DriverName() == "postgres" {
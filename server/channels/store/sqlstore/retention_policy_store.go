@@ -935,13 +935,10 @@ type RetentionPolicyBatchDeletionInfo struct {
 	StoreDeletedIds     bool
 }
 
-// genericPermanentDeleteBatchForRetentionPolicies is a helper function for tables
-// which need to delete records for granular and global policies.
-func genericPermanentDeleteBatchForRetentionPolicies(
-	r RetentionPolicyBatchDeletionInfo,
-	s *SqlStore,
-	cursor model.RetentionPolicyCursor,
-) (int64, model.RetentionPolicyCursor, error) {
+// retentionPolicyBuilders returns the queries selecting the records which fall
+// under the scope of a channel-specific policy, of a team-specific policy and of
+// the global policy, in this order. A record is selected by at most one of them.
+func retentionPolicyBuilders(r RetentionPolicyBatchDeletionInfo) (channelPoliciesBuilder, teamPoliciesBuilder, globalPolicyBuilder sq.SelectBuilder) {
 	baseBuilder := r.BaseBuilder.InnerJoin("Channels ON " + r.ChannelIDTable + ".ChannelId = Channels.Id")
 
 	scopedTimeColumn := r.Table + "." + r.TimeColumn
@@ -955,6 +952,45 @@ func genericPermanentDeleteBatchForRetentionPolicies(
 		sq.Expr(nowStr + " - " + scopedTimeColumn + " > RetentionPolicies.PostDuration * " + strconv.FormatInt(millisecondsInADay, 10)),
 	}
 
+	channelPoliciesBuilder = baseBuilder.
+		InnerJoin("RetentionPoliciesChannels ON " + r.ChannelIDTable + ".ChannelId = RetentionPoliciesChannels.ChannelId").
+		InnerJoin("RetentionPolicies ON RetentionPoliciesChannels.PolicyId = RetentionPolicies.Id").
+		Where(fallsUnderGranularPolicy)
+
+	// Channel-specific policies override team-specific policies.
+	teamPoliciesBuilder = baseBuilder.
+		LeftJoin("RetentionPoliciesChannels ON " + r.ChannelIDTable + ".ChannelId = RetentionPoliciesChannels.ChannelId").
+		InnerJoin("RetentionPoliciesTeams ON Channels.TeamId = RetentionPoliciesTeams.TeamId").
+		InnerJoin("RetentionPolicies ON RetentionPoliciesTeams.PolicyId = RetentionPolicies.Id").
+		Where(sq.And{
+			sq.Eq{"RetentionPoliciesChannels.PolicyId": nil},
+			sq.Expr("RetentionPoliciesTeams.PolicyId = RetentionPolicies.Id"),
+		}).
+		Where(fallsUnderGranularPolicy)
+
+	// Granular policies override the global policy.
+	globalPolicyBuilder = baseBuilder.
+		LeftJoin("RetentionPoliciesChannels ON " + r.ChannelIDTable + ".ChannelId = RetentionPoliciesChannels.ChannelId").
+		LeftJoin("RetentionPoliciesTeams ON Channels.TeamId = RetentionPoliciesTeams.TeamId").
+		LeftJoin("RetentionPolicies ON RetentionPoliciesChannels.PolicyId = RetentionPolicies.Id").
+		Where(sq.And{
+			sq.Eq{"RetentionPoliciesChannels.PolicyId": nil},
+			sq.Eq{"RetentionPoliciesTeams.PolicyId": nil},
+		}).
+		Where(sq.Lt{scopedTimeColumn: r.GlobalPolicyEndTime})
+
+	return channelPoliciesBuilder, teamPoliciesBuilder, globalPolicyBuilder
+}
+
+// genericPermanentDeleteBatchForRetentionPolicies is a helper function for tables
+// which need to delete records for granular and global policies.
+func genericPermanentDeleteBatchForRetentionPolicies(
+	r RetentionPolicyBatchDeletionInfo,
+	s *SqlStore,
+	cursor model.RetentionPolicyCursor,
+) (int64, model.RetentionPolicyCursor, error) {
+	channelPoliciesBuilder, teamPoliciesBuilder, globalPolicyBuilder := retentionPolicyBuilders(r)
+
 	// If the caller wants to disable the global policy from running
 	if r.GlobalPolicyEndTime <= 0 {
 		cursor.GlobalPoliciesDone = true
@@ -969,12 +1005,7 @@ func genericPermanentDeleteBatchForRetentionPolicies(
 
 	// First, delete all of the records which fall under the scope of a channel-specific policy
 	if !cursor.ChannelPoliciesDone {
-		channelPoliciesBuilder := baseBuilder.
-			InnerJoin("RetentionPoliciesChannels ON " + r.ChannelIDTable + ".ChannelId = RetentionPoliciesChannels.ChannelId").
-			InnerJoin("RetentionPolicies ON RetentionPoliciesChannels.PolicyId = RetentionPolicies.Id").
-			Where(fallsUnderGranularPolicy).
-			Limit(uint64(r.Limit))
-		rowsAffected, err := genericRetentionPoliciesDeletion(channelPoliciesBuilder, r, s)
+		rowsAffected, err := genericRetentionPoliciesDeletion(channelPoliciesBuilder.Limit(uint64(r.Limit)), r, s)
 		if err != nil {
 			return 0, cursor, err
 		}
@@ -987,18 +1018,7 @@ func genericPermanentDeleteBatchForRetentionPolicies(
 
 	// Next, delete all of the records which fall under the scope of a team-specific policy
 	if cursor.ChannelPoliciesDone && !cursor.TeamPoliciesDone {
-		// Channel-specific policies override team-specific policies.
-		teamPoliciesBuilder := baseBuilder.
-			LeftJoin("RetentionPoliciesChannels ON " + r.ChannelIDTable + ".ChannelId = RetentionPoliciesChannels.ChannelId").
-			InnerJoin("RetentionPoliciesTeams ON Channels.TeamId = RetentionPoliciesTeams.TeamId").
-			InnerJoin("RetentionPolicies ON RetentionPoliciesTeams.PolicyId = RetentionPolicies.Id").
-			Where(sq.And{
-				sq.Eq{"RetentionPoliciesChannels.PolicyId": nil},
-				sq.Expr("RetentionPoliciesTeams.PolicyId = RetentionPolicies.Id"),
-			}).
-			Where(fallsUnderGranularPolicy).
-			Limit(uint64(r.Limit))
-		rowsAffected, err := genericRetentionPoliciesDeletion(teamPoliciesBuilder, r, s)
+		rowsAffected, err := genericRetentionPoliciesDeletion(teamPoliciesBuilder.Limit(uint64(r.Limit)), r, s)
 		if err != nil {
 			return 0, cursor, err
 		}
@@ -1011,18 +1031,7 @@ func genericPermanentDeleteBatchForRetentionPolicies(
 
 	// Finally, delete all of the records which fall under the scope of the global policy
 	if cursor.ChannelPoliciesDone && cursor.TeamPoliciesDone && !cursor.GlobalPoliciesDone {
-		// Granular policies override the global policy.
-		globalPolicyBuilder := baseBuilder.
-			LeftJoin("RetentionPoliciesChannels ON " + r.ChannelIDTable + ".ChannelId = RetentionPoliciesChannels.ChannelId").
-			LeftJoin("RetentionPoliciesTeams ON Channels.TeamId = RetentionPoliciesTeams.TeamId").
-			LeftJoin("RetentionPolicies ON RetentionPoliciesChannels.PolicyId = RetentionPolicies.Id").
-			Where(sq.And{
-				sq.Eq{"RetentionPoliciesChannels.PolicyId": nil},
-				sq.Eq{"RetentionPoliciesTeams.PolicyId": nil},
-			}).
-			Where(sq.Lt{scopedTimeColumn: r.GlobalPolicyEndTime}).
-			Limit(uint64(r.Limit))
-		rowsAffected, err := genericRetentionPoliciesDeletion(globalPolicyBuilder, r, s)
+		rowsAffected, err := genericRetentionPoliciesDeletion(globalPolicyBuilder.Limit(uint64(r.Limit)), r, s)
 		if err != nil {
 			return 0, cursor, err
 		}
@@ -1035,6 +1044,37 @@ func genericPermanentDeleteBatchForRetentionPolicies(
 	return totalRowsAffected, cursor, nil
 }
 
+// genericCountForRetentionPolicies counts the records which
+// genericPermanentDeleteBatchForRetentionPolicies would delete. The Limit and
+// StoreDeletedIds fields of r are ignored.
+func genericCountForRetentionPolicies(r RetentionPolicyBatchDeletionInfo, s *SqlStore) (int64, error) {
+	channelPoliciesBuilder, teamPoliciesBuilder, globalPolicyBuilder := retentionPolicyBuilders(r)
+
+	var builders []sq.SelectBuilder
+	if r.NowMillis > 0 {
+		builders = append(builders, channelPoliciesBuilder, teamPoliciesBuilder)
+	}
+	if r.GlobalPolicyEndTime > 0 {
+		builders = append(builders, globalPolicyBuilder)
+	}
+
+	var total int64
+	for _, builder := range builders {
+		query, args, err := builder.ToSql()
+		if err != nil {
+			return 0, errors.Wrap(err, r.Table+"_tosql")
+		}
+
+		var count int64
+		if err := s.GetReplica().Get(&count, "SELECT COUNT(*) FROM ("+query+") AS A", args...); err != nil {
+			return 0, errors.Wrap(err, "failed to count "+r.Table)
+		}
+		total += count
+	}
+
+	return total, nil
+}
+
 // genericRetentionPoliciesDeletion actually executes the DELETE query using a sq.SelectBuilder
 // which selects the rows to delete.
 func genericRetentionPoliciesDeletion(
@@ -78,6 +78,8 @@ type SqlStoreStores struct {
 	bot                        store.BotStore
 	audit                      store.AuditStore
 	cluster                    store.ClusterDiscoveryStore
+	clusterPayload             store.ClusterPayloadStore
+	batchedEmailNotification   store.BatchedEmailNotificationStore
 	remoteCluster              store.RemoteClusterStore
 	compliance                 store.ComplianceStore
 	session                    store.SessionStore
@@ -97,6 +99,8 @@ type SqlStoreStores struct {
 	reaction                   store.ReactionStore
 	job                        store.JobStore
 	userAccessToken            store.UserAccessTokenStore
+	webAuthnCredential         store.WebAuthnCredentialStore
+	mfaRecoveryCode            store.MfaRecoveryCodeStore
 	plugin                     store.PluginStore
 	channelMemberHistory       store.ChannelMemberHistoryStore
 	role                       store.RoleStore
@@ -115,6 +119,7 @@ type SqlStoreStores struct {
 	desktopTokens              store.DesktopTokensStore
 	channelBookmarks           store.ChannelBookmarkStore
 	scheduledPost              store.ScheduledPostStore
+	legalHold                  store.LegalHoldStore
 	propertyGroup              store.PropertyGroupStore
 	propertyField              store.PropertyFieldStore
 	propertyValue              store.PropertyValueStore
@@ -222,6 +227,8 @@ func New(settings model.SqlSettings, logger mlog.LoggerIFace, metrics einterface
 	store.stores.bot = newSqlBotStore(store, metrics)
 	store.stores.audit = newSqlAuditStore(store)
 	store.stores.cluster = newSqlClusterDiscoveryStore(store)
+	store.stores.clusterPayload = newSqlClusterPayloadStore(store)
+	store.stores.batchedEmailNotification = newSqlBatchedEmailNotificationStore(store)
 	store.stores.remoteCluster = newSqlRemoteClusterStore(store)
 	store.stores.compliance = newSqlComplianceStore(store)
 	store.stores.session = newSqlSessionStore(store)
@@ -241,6 +248,8 @@ func New(settings model.SqlSettings, logger mlog.LoggerIFace, metrics einterface
 	store.stores.thread = newSqlThreadStore(store)
 	store.stores.job = newSqlJobStore(store)
 	store.stores.userAccessToken = newSqlUserAccessTokenStore(store)
+	store.stores.webAuthnCredential = newSqlWebAuthnCredentialStore(store)
+	store.stores.mfaRecoveryCode = newSqlMfaRecoveryCodeStore(store)
 	store.stores.channelMemberHistory = newSqlChannelMemberHistoryStore(store)
 	store.stores.plugin = newSqlPluginStore(store)
 	store.stores.TermsOfService = newSqlTermsOfServiceStore(store, metrics)
@@ -260,6 +269,7 @@ func New(settings model.SqlSettings, logger mlog.LoggerIFace, metrics einterface
 	store.stores.desktopTokens = newSqlDesktopTokensStore(store, metrics)
 	store.stores.channelBookmarks = newSqlChannelBookmarkStore(store)
 	store.stores.scheduledPost = newScheduledPostStore(store)
+	store.stores.legalHold = newSqlLegalHoldStore(store)
 	store.stores.propertyGroup = newPropertyGroupStore(store)
 	store.stores.propertyField = newPropertyFieldStore(store)
 	store.stores.propertyValue = newPropertyValueStore(store)
@@ -922,6 +932,14 @@ func (ss *SqlStore) ClusterDiscovery() store.ClusterDiscoveryStore {
 	return ss.stores.cluster
 }
 
+func (ss *SqlStore) ClusterPayload() store.ClusterPayloadStore {
+	return ss.stores.clusterPayload
+}
+
+func (ss *SqlStore) BatchedEmailNotification() store.BatchedEmailNotificationStore {
+	return ss.stores.batchedEmailNotification
+}
+
 func (ss *SqlStore) RemoteCluster() store.RemoteClusterStore {
 	return ss.stores.remoteCluster
 }
@@ -994,6 +1012,14 @@ func (ss *SqlStore) UserAccessToken() store.UserAccessTokenStore {
 	return ss.stores.userAccessToken
 }
 
+func (ss *SqlStore) WebAuthnCredential() store.WebAuthnCredentialStore {
+	return ss.stores.webAuthnCredential
+}
+
+func (ss *SqlStore) MfaRecoveryCode() store.MfaRecoveryCodeStore {
+	return ss.stores.mfaRecoveryCode
+}
+
 func (ss *SqlStore) ChannelMemberHistory() store.ChannelMemberHistoryStore {
 	return ss.stores.channelMemberHistory
 }
@@ -1381,3 +1407,7 @@ func (ss *SqlStore) determineMaxColumnSize(tableName, columnName string) (int, e
 func (ss *SqlStore) ScheduledPost() store.ScheduledPostStore {
 	return ss.stores.scheduledPost
 }
+
+func (ss *SqlStore) LegalHold() store.LegalHoldStore {
+	return ss.stores.legalHold
+}
@@ -988,9 +988,12 @@ func (s *SqlThreadStore) maintainMembershipTx(trx *sqlxTxWrapper, userID, postID
 // the global or a granular retention policy.
 // See `genericPermanentDeleteBatchForRetentionPolicies` for details.
 func (s *SqlThreadStore) PermanentDeleteBatchForRetentionPolicies(now, globalPolicyEndTime, limit int64, cursor model.RetentionPolicyCursor) (int64, model.RetentionPolicyCursor, error) {
+	// The threads whose root post is covered by a legal hold are kept with it.
 	builder := s.getQueryBuilder().
 		Select("Threads.PostId").
-		From("Threads")
+		From("Threads").
+		LeftJoin("Posts ON Threads.PostId = Posts.Id").
+		Where(notHeldByActiveLegalHold("Posts.UserId", "Threads.ChannelId", "Posts.CreateAt"))
 	return genericPermanentDeleteBatchForRetentionPolicies(RetentionPolicyBatchDeletionInfo{
 		BaseBuilder:         builder,
 		Table:               "Threads",
@@ -1008,15 +1011,20 @@ func (s *SqlThreadStore) PermanentDeleteBatchForRetentionPolicies(now, globalPol
 // which are affected by the global or a granular retention policy.
 // See `genericPermanentDeleteBatchForRetentionPolicies` for details.
 func (s *SqlThreadStore) PermanentDeleteBatchThreadMembershipsForRetentionPolicies(now, globalPolicyEndTime, limit int64, cursor model.RetentionPolicyCursor) (int64, model.RetentionPolicyCursor, error) {
+	// The memberships of the threads kept for a legal hold, and those of the
+	// users covered by a legal hold, are kept.
 	builder := s.getQueryBuilder().
-		Select("ThreadMemberships.PostId").
+		Select("ThreadMemberships.PostId, ThreadMemberships.UserId").
 		From("ThreadMemberships").
-		InnerJoin("Threads ON ThreadMemberships.PostId = Threads.PostId")
+		InnerJoin("Threads ON ThreadMemberships.PostId = Threads.PostId").
+		LeftJoin("Posts ON Threads.PostId = Posts.Id").
+		Where(notHeldByActiveLegalHold("Posts.UserId", "Threads.ChannelId", "Posts.CreateAt")).
+		Where(notHeldByActiveLegalHold("ThreadMemberships.UserId", "Threads.ChannelId", "ThreadMemberships.LastUpdated"))
 	return genericPermanentDeleteBatchForRetentionPolicies(RetentionPolicyBatchDeletionInfo{
 		BaseBuilder:         builder,
 		Table:               "ThreadMemberships",
 		TimeColumn:          "LastUpdated",
-		PrimaryKeys:         []string{"PostId"},
+		PrimaryKeys:         []string{"PostId", "UserId"},
 		ChannelIDTable:      "Threads",
 		NowMillis:           now,
 		GlobalPolicyEndTime: globalPolicyEndTime,
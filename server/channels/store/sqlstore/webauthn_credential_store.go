@@ -0,0 +1,118 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package sqlstore
+
+import (
+	"database/sql"
+
+	sq "github.com/mattermost/squirrel"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+)
+
+type SqlWebAuthnCredentialStore struct {
+	*SqlStore
+
+	tableSelectQuery sq.SelectBuilder
+}
+
+func newSqlWebAuthnCredentialStore(sqlStore *SqlStore) store.WebAuthnCredentialStore {
+	s := &SqlWebAuthnCredentialStore{
+		SqlStore: sqlStore,
+	}
+
+	s.tableSelectQuery = s.getQueryBuilder().
+		Select("Id", "UserId", "Name", "CredentialId", "PublicKey", "AttestationType", "Transports", "AAGUID", "SignCount", "CreateAt", "LastUsedAt").
+		From("WebAuthnCredentials")
+
+	return s
+}
+
+func (s *SqlWebAuthnCredentialStore) Save(credential *model.WebAuthnCredential) (*model.WebAuthnCredential, error) {
+	credential.PreSave()
+	if err := credential.IsValid(); err != nil {
+		return nil, err
+	}
+
+	query := s.getQueryBuilder().
+		Insert("WebAuthnCredentials").
+		Columns("Id", "UserId", "Name", "CredentialId", "PublicKey", "AttestationType", "Transports", "AAGUID", "SignCount", "CreateAt", "LastUsedAt").
+		Values(credential.Id, credential.UserId, credential.Name, credential.CredentialId, credential.PublicKey, credential.AttestationType, credential.Transports, credential.AAGUID, credential.SignCount, credential.CreateAt, credential.LastUsedAt)
+
+	if _, err := s.GetMaster().ExecBuilder(query); err != nil {
+		if IsUniqueConstraintError(err, []string{"CredentialId", "idx_webauthncredentials_credentialid_unique"}) {
+			return nil, store.NewErrConflict("WebAuthnCredential", err, "credentialid="+credential.CredentialId)
+		}
+		return nil, errors.Wrap(err, "failed to save WebAuthnCredential")
+	}
+
+	return credential, nil
+}
+
+func (s *SqlWebAuthnCredentialStore) Get(id string) (*model.WebAuthnCredential, error) {
+	var credential model.WebAuthnCredential
+
+	if err := s.GetReplica().GetBuilder(&credential, s.tableSelectQuery.Where(sq.Eq{"Id": id})); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.NewErrNotFound("WebAuthnCredential", id)
+		}
+		return nil, errors.Wrapf(err, "failed to get WebAuthnCredential with id=%s", id)
+	}
+
+	return &credential, nil
+}
+
+func (s *SqlWebAuthnCredentialStore) GetForUser(userID string) ([]*model.WebAuthnCredential, error) {
+	credentials := []*model.WebAuthnCredential{}
+
+	query := s.tableSelectQuery.
+		Where(sq.Eq{"UserId": userID}).
+		OrderBy("CreateAt ASC")
+
+	if err := s.GetReplica().SelectBuilder(&credentials, query); err != nil {
+		return nil, errors.Wrapf(err, "failed to find WebAuthnCredentials with userId=%s", userID)
+	}
+
+	return credentials, nil
+}
+
+func (s *SqlWebAuthnCredentialStore) UpdateSignCount(id string, signCount int64, lastUsedAt int64) error {
+	query := s.getQueryBuilder().
+		Update("WebAuthnCredentials").
+		Set("SignCount", signCount).
+		Set("LastUsedAt", lastUsedAt).
+		Where(sq.Eq{"Id": id})
+
+	if _, err := s.GetMaster().ExecBuilder(query); err != nil {
+		return errors.Wrapf(err, "failed to update WebAuthnCredential with id=%s", id)
+	}
+
+	return nil
+}
+
+func (s *SqlWebAuthnCredentialStore) Delete(id string) error {
+	query := s.getQueryBuilder().
+		Delete("WebAuthnCredentials").
+		Where(sq.Eq{"Id": id})
+
+	if _, err := s.GetMaster().ExecBuilder(query); err != nil {
+		return errors.Wrapf(err, "failed to delete WebAuthnCredential with id=%s", id)
+	}
+
+	return nil
+}
+
+func (s *SqlWebAuthnCredentialStore) DeleteAllForUser(userID string) error {
+	query := s.getQueryBuilder().
+		Delete("WebAuthnCredentials").
+		Where(sq.Eq{"UserId": userID})
+
+	if _, err := s.GetMaster().ExecBuilder(query); err != nil {
+		return errors.Wrapf(err, "failed to delete WebAuthnCredentials with userId=%s", userID)
+	}
+
+	return nil
+}
@@ -27,11 +27,14 @@ type Store interface {
 	Channel() ChannelStore
 	Post() PostStore
 	RetentionPolicy() RetentionPolicyStore
+	LegalHold() LegalHoldStore
 	Thread() ThreadStore
 	User() UserStore
 	Bot() BotStore
 	Audit() AuditStore
 	ClusterDiscovery() ClusterDiscoveryStore
+	ClusterPayload() ClusterPayloadStore
+	BatchedEmailNotification() BatchedEmailNotificationStore
 	RemoteCluster() RemoteClusterStore
 	Compliance() ComplianceStore
 	Session() SessionStore
@@ -53,6 +56,8 @@ type Store interface {
 	Scheme() SchemeStore
 	Job() JobStore
 	UserAccessToken() UserAccessTokenStore
+	WebAuthnCredential() WebAuthnCredentialStore
+	MfaRecoveryCode() MfaRecoveryCodeStore
 	ChannelMemberHistory() ChannelMemberHistoryStore
 	Plugin() PluginStore
 	TermsOfService() TermsOfServiceStore
@@ -396,6 +401,8 @@ type PostStore interface {
 	GetEditHistoryForPost(postID string) ([]*model.Post, error)
 	GetPostsBatchForIndexing(startTime int64, startPostID string, limit int) ([]*model.PostForIndexing, error)
 	PermanentDeleteBatchForRetentionPolicies(now, globalPolicyEndTime, limit int64, cursor model.RetentionPolicyCursor) (int64, model.RetentionPolicyCursor, error)
+	// CountForRetentionPolicies counts the posts which PermanentDeleteBatchForRetentionPolicies would delete.
+	CountForRetentionPolicies(now, globalPolicyEndTime int64) (int64, error)
 	PermanentDeleteBatch(endTime int64, limit int64) (int64, error)
 	GetOldest() (*model.Post, error)
 	GetMaxPostSize() int
@@ -545,6 +552,23 @@ type ClusterDiscoveryStore interface {
 	Cleanup() error
 }
 
+type ClusterPayloadStore interface {
+	Save(payload *model.ClusterPayload) error
+	Get(id string) (*model.ClusterPayload, error)
+	DeleteOlderThan(createAt int64) (int64, error)
+	// Notify sends a notification on the given channel to the listening database sessions.
+	// It's only supported by PostgreSQL.
+	Notify(channel, message string) error
+}
+
+type BatchedEmailNotificationStore interface {
+	Save(notification *model.BatchedEmailNotification) error
+	// GetAll returns the batched notifications with their post, grouped by user, the oldest first.
+	GetAll() ([]*model.BatchedEmailNotification, error)
+	Count() (int64, error)
+	Delete(ids []string) error
+}
+
 type RemoteClusterStore interface {
 	Save(rc *model.RemoteCluster) (*model.RemoteCluster, error)
 	Update(rc *model.RemoteCluster) (*model.RemoteCluster, error)
@@ -743,6 +767,22 @@ type FileInfoStore interface {
 	GetStorageUsage(allowFromCache, includeDeleted bool) (int64, error)
 	// GetUptoNSizeFileTime returns the CreateAt time of the last accessible file with a running-total size upto n bytes.
 	GetUptoNSizeFileTime(n int64) (int64, error)
+	// CountByContentHash returns the number of FileInfos, deleted or not, referencing the blob with the given content hash.
+	CountByContentHash(hash string) (int64, error)
+	GetFilesBatchForDeduplication(afterID string, limit int) ([]*model.FileInfo, error)
+	// CountByPath returns the number of FileInfos, deleted or not, stored at the given path.
+	CountByPath(path string) (int64, error)
+	// UpdateContentHash points the given FileInfos to the blob stored at newPath.
+	UpdateContentHash(rctx request.CTX, fileIDs []string, newPath, hash string) error
+	// Quarantine marks the FileInfo as flagged by the antivirus scan for the given reason.
+	Quarantine(rctx request.CTX, fileID, reason string) error
+	// CountForRetentionPolicies counts the files attached to the posts which the retention policies would delete.
+	CountForRetentionPolicies(now, globalPolicyEndTime int64) (int64, error)
+	// GetByPostIds returns the FileInfos, deleted or not, attached to the given posts.
+	GetByPostIds(postIDs []string) ([]*model.FileInfo, error)
+	GetBatchForRetention(endTime int64, limit int) ([]*model.FileInfo, error)
+	CountForRetention(endTime int64) (int64, error)
+	PermanentDeleteByIds(rctx request.CTX, fileIDs []string) (int64, error)
 }
 
 type UploadSessionStore interface {
@@ -804,6 +844,22 @@ type UserAccessTokenStore interface {
 	UpdateTokenDisable(tokenID string) error
 }
 
+type WebAuthnCredentialStore interface {
+	Save(credential *model.WebAuthnCredential) (*model.WebAuthnCredential, error)
+	Get(id string) (*model.WebAuthnCredential, error)
+	GetForUser(userID string) ([]*model.WebAuthnCredential, error)
+	UpdateSignCount(id string, signCount int64, lastUsedAt int64) error
+	Delete(id string) error
+	DeleteAllForUser(userID string) error
+}
+
+type MfaRecoveryCodeStore interface {
+	ReplaceForUser(userID string, codeHashes []string) error
+	GetForUser(userID string) ([]string, error)
+	Consume(userID, codeHash string) (bool, error)
+	DeleteAllForUser(userID string) error
+}
+
 type PluginStore interface {
 	SaveOrUpdate(keyVal *model.PluginKeyValue) (*model.PluginKeyValue, error)
 	CompareAndSet(keyVal *model.PluginKeyValue, oldValue []byte) (bool, error)
@@ -1061,6 +1117,16 @@ type ChannelBookmarkStore interface {
 	GetBookmarksForChannelSince(channelID string, since int64) ([]*model.ChannelBookmarkWithFileInfo, error)
 }
 
+// LegalHoldStore persists the legal holds. The other stores exclude the content
+// covered by an active hold from their bulk deletions.
+type LegalHoldStore interface {
+	Save(hold *model.LegalHold) (*model.LegalHold, error)
+	Get(id string) (*model.LegalHold, error)
+	GetAll(offset, limit int, includeReleased bool) ([]*model.LegalHold, error)
+	GetActive() ([]*model.LegalHold, error)
+	Release(id string, releasedAt int64) error
+}
+
 type ScheduledPostStore interface {
 	GetMaxMessageSize() int
 	CreateScheduledPost(scheduledPost *model.ScheduledPost) (*model.ScheduledPost, error)
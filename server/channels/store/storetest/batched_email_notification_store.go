@@ -0,0 +1,98 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package storetest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+)
+
+func TestBatchedEmailNotificationStore(t *testing.T, rctx request.CTX, ss store.Store) {
+	t.Run("SaveGet", func(t *testing.T) { testBatchedEmailNotificationStoreSaveGet(t, rctx, ss) })
+	t.Run("Delete", func(t *testing.T) { testBatchedEmailNotificationStoreDelete(t, rctx, ss) })
+}
+
+func testBatchedEmailNotificationStoreSaveGet(t *testing.T, rctx request.CTX, ss store.Store) {
+	userID := model.NewId()
+	otherUserID := model.NewId()
+
+	savePost := func() *model.Post {
+		post, err := ss.Post().Save(rctx, &model.Post{ChannelId: model.NewId(), UserId: model.NewId(), Message: "message"})
+		require.NoError(t, err)
+		return post
+	}
+	post := savePost()
+	deletedPost := savePost()
+	require.NoError(t, ss.Post().Delete(rctx, deletedPost.Id, model.GetMillis(), ""))
+
+	second := &model.BatchedEmailNotification{UserId: userID, PostId: post.Id, TeamName: "team", CreateAt: 2000}
+	first := &model.BatchedEmailNotification{UserId: userID, PostId: deletedPost.Id, TeamName: "team", CreateAt: 1000}
+	other := &model.BatchedEmailNotification{UserId: otherUserID, PostId: model.NewId(), TeamName: "other"}
+	for _, notification := range []*model.BatchedEmailNotification{second, first, other} {
+		require.NoError(t, ss.BatchedEmailNotification().Save(notification))
+		require.NotEmpty(t, notification.Id)
+	}
+	t.Cleanup(func() {
+		require.NoError(t, ss.BatchedEmailNotification().Delete([]string{first.Id, second.Id, other.Id}))
+	})
+	assert.NotZero(t, other.CreateAt)
+
+	count, err := ss.BatchedEmailNotification().Count()
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, count, int64(3))
+
+	all, err := ss.BatchedEmailNotification().GetAll()
+	require.NoError(t, err)
+	notifications := map[string][]*model.BatchedEmailNotification{}
+	for _, notification := range all {
+		notifications[notification.UserId] = append(notifications[notification.UserId], notification)
+	}
+
+	require.Len(t, notifications[userID], 2)
+	assert.Equal(t, first.Id, notifications[userID][0].Id)
+	assert.Equal(t, deletedPost.Id, notifications[userID][0].PostId)
+	assert.Nil(t, notifications[userID][0].Post, "the post was deleted")
+	assert.Equal(t, second.Id, notifications[userID][1].Id)
+	assert.Equal(t, "team", notifications[userID][1].TeamName)
+	assert.EqualValues(t, 2000, notifications[userID][1].CreateAt)
+	require.NotNil(t, notifications[userID][1].Post)
+	assert.Equal(t, post.Id, notifications[userID][1].Post.Id)
+	assert.Equal(t, post.Message, notifications[userID][1].Post.Message)
+
+	require.Len(t, notifications[otherUserID], 1)
+	assert.Nil(t, notifications[otherUserID][0].Post, "the post doesn't exist")
+}
+
+func testBatchedEmailNotificationStoreDelete(t *testing.T, rctx request.CTX, ss store.Store) {
+	userID := model.NewId()
+	kept := &model.BatchedEmailNotification{UserId: userID, PostId: model.NewId(), TeamName: "team"}
+	deleted := &model.BatchedEmailNotification{UserId: userID, PostId: model.NewId(), TeamName: "team"}
+	require.NoError(t, ss.BatchedEmailNotification().Save(kept))
+	require.NoError(t, ss.BatchedEmailNotification().Save(deleted))
+
+	require.NoError(t, ss.BatchedEmailNotification().Delete([]string{deleted.Id}))
+	require.NoError(t, ss.BatchedEmailNotification().Delete(nil))
+
+	ids := func() []string {
+		all, err := ss.BatchedEmailNotification().GetAll()
+		require.NoError(t, err)
+		var ids []string
+		for _, notification := range all {
+			if notification.UserId == userID {
+				ids = append(ids, notification.Id)
+			}
+		}
+		return ids
+	}
+	assert.Equal(t, []string{kept.Id}, ids())
+
+	require.NoError(t, ss.BatchedEmailNotification().Delete([]string{kept.Id}))
+	assert.Empty(t, ids())
+}
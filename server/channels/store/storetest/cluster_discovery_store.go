@@ -20,6 +20,7 @@ func TestClusterDiscoveryStore(t *testing.T, rctx request.CTX, ss store.Store) {
 	t.Run("Delete", func(t *testing.T) { testClusterDiscoveryStoreDelete(t, rctx, ss) })
 	t.Run("LastPing", func(t *testing.T) { testClusterDiscoveryStoreLastPing(t, rctx, ss) })
 	t.Run("Exists", func(t *testing.T) { testClusterDiscoveryStoreExists(t, rctx, ss) })
+	t.Run("SameHostname", func(t *testing.T) { testClusterDiscoveryStoreSameHostname(t, rctx, ss) })
 	t.Run("ClusterDiscoveryGetStore", func(t *testing.T) { testClusterDiscoveryGetStore(t, rctx, ss) })
 }
 
@@ -108,6 +109,38 @@ func testClusterDiscoveryStoreExists(t *testing.T, rctx request.CTX, ss store.St
 	assert.False(t, val)
 }
 
+func testClusterDiscoveryStoreSameHostname(t *testing.T, rctx request.CTX, ss store.Store) {
+	hostname := "hostname" + model.NewId()
+	clusterType := "test_test_SameHostname" + model.NewId()
+	discovery1 := &model.ClusterDiscovery{
+		ClusterName: "cluster_name_SameHostname",
+		Hostname:    hostname,
+		GossipPort:  8074,
+		Type:        clusterType,
+	}
+	discovery2 := &model.ClusterDiscovery{
+		ClusterName: "cluster_name_SameHostname",
+		Hostname:    hostname,
+		GossipPort:  8075,
+		Type:        clusterType,
+	}
+
+	require.NoError(t, ss.ClusterDiscovery().Save(discovery1))
+	require.NoError(t, ss.ClusterDiscovery().Save(discovery2))
+
+	deleted, err := ss.ClusterDiscovery().Delete(discovery1)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+
+	val, err := ss.ClusterDiscovery().Exists(discovery1)
+	require.NoError(t, err)
+	assert.False(t, val)
+
+	val, err = ss.ClusterDiscovery().Exists(discovery2)
+	require.NoError(t, err)
+	assert.True(t, val)
+}
+
 func testClusterDiscoveryGetStore(t *testing.T, rctx request.CTX, ss store.Store) {
 	testType1 := model.NewId()
 
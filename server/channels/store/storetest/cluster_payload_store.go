@@ -0,0 +1,62 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package storetest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+)
+
+func TestClusterPayloadStore(t *testing.T, rctx request.CTX, ss store.Store, s SqlStore) {
+	// The database cluster message transport requires PostgreSQL.
+	if s.DriverName() != model.DatabaseDriverPostgres {
+		t.Skip("The cluster payloads are only supported by PostgreSQL")
+	}
+
+	t.Run("SaveGet", func(t *testing.T) { testClusterPayloadStoreSaveGet(t, rctx, ss) })
+	t.Run("DeleteOlderThan", func(t *testing.T) { testClusterPayloadStoreDeleteOlderThan(t, rctx, ss) })
+	t.Run("Notify", func(t *testing.T) { testClusterPayloadStoreNotify(t, rctx, ss) })
+}
+
+func testClusterPayloadStoreSaveGet(t *testing.T, rctx request.CTX, ss store.Store) {
+	payload := &model.ClusterPayload{Data: []byte{0, 1, 2, 255}}
+	require.NoError(t, ss.ClusterPayload().Save(payload))
+	require.NotEmpty(t, payload.Id)
+	require.NotZero(t, payload.CreateAt)
+
+	saved, err := ss.ClusterPayload().Get(payload.Id)
+	require.NoError(t, err)
+	assert.Equal(t, payload, saved)
+
+	_, err = ss.ClusterPayload().Get(model.NewId())
+	var nfErr *store.ErrNotFound
+	require.True(t, errors.As(err, &nfErr))
+}
+
+func testClusterPayloadStoreDeleteOlderThan(t *testing.T, rctx request.CTX, ss store.Store) {
+	old := &model.ClusterPayload{Data: []byte("old"), CreateAt: 1000}
+	recent := &model.ClusterPayload{Data: []byte("recent")}
+	require.NoError(t, ss.ClusterPayload().Save(old))
+	require.NoError(t, ss.ClusterPayload().Save(recent))
+
+	deleted, err := ss.ClusterPayload().DeleteOlderThan(2000)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, deleted)
+
+	_, err = ss.ClusterPayload().Get(old.Id)
+	require.Error(t, err)
+	_, err = ss.ClusterPayload().Get(recent.Id)
+	require.NoError(t, err)
+}
+
+func testClusterPayloadStoreNotify(t *testing.T, rctx request.CTX, ss store.Store) {
+	require.NoError(t, ss.ClusterPayload().Notify("cluster_test", "message"))
+}
@@ -41,6 +41,10 @@ func TestFileInfoStore(t *testing.T, rctx request.CTX, ss store.Store, s SqlStor
 	t.Run("FileInfoGetByIds", func(t *testing.T) { testGetByIds(t, rctx, ss) })
 	t.Run("FileInfoDeleteForPostByIds", func(t *testing.T) { testDeleteForPostByIds(t, rctx, ss) })
 	t.Run("FileInfoRestoreForPostByIds", func(t *testing.T) { testRestoreUndeleteForPostByIds(t, rctx, ss) })
+	t.Run("FileInfoCountByContentHash", func(t *testing.T) { testFileInfoCountByContentHash(t, rctx, ss) })
+	t.Run("FileInfoDeduplication", func(t *testing.T) { testFileInfoDeduplication(t, rctx, ss) })
+	t.Run("FileInfoQuarantine", func(t *testing.T) { testFileInfoQuarantine(t, rctx, ss) })
+	t.Run("FileInfoRetention", func(t *testing.T) { testFileInfoRetention(t, rctx, ss) })
 }
 
 func testFileInfoSaveGet(t *testing.T, rctx request.CTX, ss store.Store) {
@@ -1533,3 +1537,221 @@ func testRestoreUndeleteForPostByIds(t *testing.T, rctx request.CTX, ss store.St
 		}
 	})
 }
+
+func testFileInfoCountByContentHash(t *testing.T, rctx request.CTX, ss store.Store) {
+	hash := model.NewRandomString(64)
+
+	count, err := ss.FileInfo().CountByContentHash(hash)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+
+	info1, err := ss.FileInfo().Save(rctx, &model.FileInfo{
+		CreatorId:   model.NewId(),
+		Path:        "blobs/" + hash,
+		ContentHash: hash,
+	})
+	require.NoError(t, err)
+	defer ss.FileInfo().PermanentDelete(rctx, info1.Id)
+
+	info2, err := ss.FileInfo().Save(rctx, &model.FileInfo{
+		CreatorId:   model.NewId(),
+		Path:        "blobs/" + hash,
+		ContentHash: hash,
+		DeleteAt:    model.GetMillis(),
+	})
+	require.NoError(t, err)
+
+	got, err := ss.FileInfo().Get(info1.Id)
+	require.NoError(t, err)
+	assert.Equal(t, hash, got.ContentHash)
+
+	// Deleted FileInfos still reference the blob.
+	count, err = ss.FileInfo().CountByContentHash(hash)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	require.NoError(t, ss.FileInfo().PermanentDelete(rctx, info2.Id))
+
+	count, err = ss.FileInfo().CountByContentHash(hash)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func testFileInfoDeduplication(t *testing.T, rctx request.CTX, ss store.Store) {
+	path := "20240101/teams/noteam/channels/" + model.NewId() + "/file.txt"
+
+	original, err := ss.FileInfo().Save(rctx, &model.FileInfo{
+		CreatorId: model.NewId(),
+		PostId:    model.NewId(),
+		Path:      path,
+	})
+	require.NoError(t, err)
+	defer ss.FileInfo().PermanentDelete(rctx, original.Id)
+
+	copied, err := ss.FileInfo().Save(rctx, &model.FileInfo{
+		CreatorId: model.NewId(),
+		ChannelId: model.NewId(),
+		Path:      path,
+	})
+	require.NoError(t, err)
+	defer ss.FileInfo().PermanentDelete(rctx, copied.Id)
+
+	imported, err := ss.FileInfo().Save(rctx, &model.FileInfo{
+		CreatorId: model.NewId(),
+		Path:      "import/" + model.NewId() + "_file.zip",
+	})
+	require.NoError(t, err)
+	defer ss.FileInfo().PermanentDelete(rctx, imported.Id)
+
+	infos, err := ss.FileInfo().GetFilesBatchForDeduplication("", 10000)
+	require.NoError(t, err)
+	ids := make([]string, 0, len(infos))
+	for _, info := range infos {
+		ids = append(ids, info.Id)
+	}
+	assert.Contains(t, ids, original.Id)
+	assert.Contains(t, ids, copied.Id)
+	assert.NotContains(t, ids, imported.Id)
+
+	count, err := ss.FileInfo().CountByPath(path)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+
+	hash := model.NewRandomString(64)
+	err = ss.FileInfo().UpdateContentHash(rctx, []string{original.Id}, "blobs/"+hash, hash)
+	require.NoError(t, err)
+
+	count, err = ss.FileInfo().CountByPath(path)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+
+	err = ss.FileInfo().UpdateContentHash(rctx, []string{copied.Id}, "blobs/"+hash, hash)
+	require.NoError(t, err)
+
+	count, err = ss.FileInfo().CountByPath(path)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	for _, id := range []string{original.Id, copied.Id} {
+		info, err := ss.FileInfo().Get(id)
+		require.NoError(t, err)
+		assert.Equal(t, "blobs/"+hash, info.Path)
+		assert.Equal(t, hash, info.ContentHash)
+	}
+
+	infos, err = ss.FileInfo().GetFilesBatchForDeduplication("", 10000)
+	require.NoError(t, err)
+	for _, info := range infos {
+		assert.NotEqual(t, original.Id, info.Id)
+		assert.NotEqual(t, copied.Id, info.Id)
+	}
+}
+
+func testFileInfoQuarantine(t *testing.T, rctx request.CTX, ss store.Store) {
+	info, err := ss.FileInfo().Save(rctx, &model.FileInfo{
+		CreatorId: model.NewId(),
+		PostId:    model.NewId(),
+		Path:      "file.txt",
+	})
+	require.NoError(t, err)
+	defer ss.FileInfo().PermanentDelete(rctx, info.Id)
+	assert.False(t, info.IsQuarantined())
+
+	err = ss.FileInfo().Quarantine(rctx, info.Id, "Eicar-Signature")
+	require.NoError(t, err)
+
+	got, err := ss.FileInfo().Get(info.Id)
+	require.NoError(t, err)
+	assert.True(t, got.IsQuarantined())
+	assert.Equal(t, "Eicar-Signature", got.QuarantineReason)
+
+	got.QuarantinedAt = 0
+	got.QuarantineReason = ""
+	_, err = ss.FileInfo().Upsert(rctx, got)
+	require.NoError(t, err)
+
+	got, err = ss.FileInfo().Get(info.Id)
+	require.NoError(t, err)
+	assert.False(t, got.IsQuarantined())
+}
+
+func testFileInfoRetention(t *testing.T, rctx request.CTX, ss store.Store) {
+	team, err := ss.Team().Save(&model.Team{
+		DisplayName: "DisplayName",
+		Name:        "team" + model.NewId(),
+		Email:       MakeEmail(),
+		Type:        model.TeamOpen,
+	})
+	require.NoError(t, err)
+	defer ss.Team().PermanentDelete(team.Id)
+
+	channel, err := ss.Channel().Save(rctx, &model.Channel{
+		TeamId:      team.Id,
+		DisplayName: "DisplayName",
+		Name:        "channel" + model.NewId(),
+		Type:        model.ChannelTypeOpen,
+	}, -1)
+	require.NoError(t, err)
+	defer ss.Channel().PermanentDelete(rctx, channel.Id)
+
+	post, err := ss.Post().Save(rctx, &model.Post{
+		ChannelId: channel.Id,
+		UserId:    model.NewId(),
+		Message:   NewTestID(),
+		CreateAt:  1000,
+	})
+	require.NoError(t, err)
+	defer ss.Post().PermanentDelete(rctx, post.Id)
+
+	attached, err := ss.FileInfo().Save(rctx, &model.FileInfo{
+		CreatorId: post.UserId,
+		PostId:    post.Id,
+		ChannelId: channel.Id,
+		Path:      "attached.txt",
+		CreateAt:  1000,
+	})
+	require.NoError(t, err)
+	defer ss.FileInfo().PermanentDelete(rctx, attached.Id)
+
+	deleted, err := ss.FileInfo().Save(rctx, &model.FileInfo{
+		CreatorId: post.UserId,
+		PostId:    post.Id,
+		ChannelId: channel.Id,
+		Path:      "deleted.txt",
+		CreateAt:  1500,
+		DeleteAt:  2000,
+	})
+	require.NoError(t, err)
+	defer ss.FileInfo().PermanentDelete(rctx, deleted.Id)
+
+	count, err := ss.FileInfo().CountForRetentionPolicies(0, 2000)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	infos, err := ss.FileInfo().GetByPostIds([]string{post.Id})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{attached.Id, deleted.Id}, []string{infos[0].Id, infos[1].Id})
+
+	countBefore, err := ss.FileInfo().CountForRetention(1200)
+	require.NoError(t, err)
+	countAfter, err := ss.FileInfo().CountForRetention(1600)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), countAfter-countBefore)
+
+	infos, err = ss.FileInfo().GetBatchForRetention(1200, 1000)
+	require.NoError(t, err)
+	ids := make([]string, 0, len(infos))
+	for _, info := range infos {
+		ids = append(ids, info.Id)
+	}
+	assert.Contains(t, ids, attached.Id)
+	assert.NotContains(t, ids, deleted.Id)
+
+	removed, err := ss.FileInfo().PermanentDeleteByIds(rctx, []string{attached.Id, deleted.Id})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), removed)
+
+	infos, err = ss.FileInfo().GetByPostIds([]string{post.Id})
+	require.NoError(t, err)
+	assert.Empty(t, infos)
+}
@@ -0,0 +1,318 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package storetest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+)
+
+func TestLegalHoldStore(t *testing.T, rctx request.CTX, ss store.Store, s SqlStore) {
+	t.Run("SaveAndGet", func(t *testing.T) { testLegalHoldStoreSaveAndGet(t, rctx, ss) })
+	t.Run("GetAllAndRelease", func(t *testing.T) { testLegalHoldStoreGetAllAndRelease(t, rctx, ss) })
+	t.Run("ExcludedFromDeletion", func(t *testing.T) { testLegalHoldStoreExcludedFromDeletion(t, rctx, ss) })
+	t.Run("ExcludedFromChannelDeletion", func(t *testing.T) { testLegalHoldStoreExcludedFromChannelDeletion(t, rctx, ss) })
+	t.Run("ThreadsExcludedFromRetention", func(t *testing.T) { testLegalHoldStoreThreadsExcludedFromRetention(t, rctx, ss) })
+	t.Run("MessageExport", func(t *testing.T) { testLegalHoldStoreMessageExport(t, rctx, ss) })
+}
+
+func testLegalHoldStoreSaveAndGet(t *testing.T, rctx request.CTX, ss store.Store) {
+	userID := model.NewId()
+	channelID := model.NewId()
+	hold, err := ss.LegalHold().Save(&model.LegalHold{
+		DisplayName: "Case 1",
+		Description: "Description",
+		CreatorId:   model.NewId(),
+		UserIds:     []string{userID, userID},
+		ChannelIds:  []string{channelID},
+		StartAt:     1000,
+	})
+	require.NoError(t, err)
+	defer ss.LegalHold().Release(hold.Id, model.GetMillis())
+
+	got, err := ss.LegalHold().Get(hold.Id)
+	require.NoError(t, err)
+	assert.Equal(t, hold, got)
+	assert.Equal(t, []string{userID}, got.UserIds)
+
+	_, err = ss.LegalHold().Get(model.NewId())
+	var nfErr *store.ErrNotFound
+	assert.ErrorAs(t, err, &nfErr)
+
+	_, err = ss.LegalHold().Save(&model.LegalHold{DisplayName: "Empty", CreatorId: model.NewId()})
+	assert.Error(t, err)
+}
+
+func testLegalHoldStoreGetAllAndRelease(t *testing.T, rctx request.CTX, ss store.Store) {
+	hold1, err := ss.LegalHold().Save(&model.LegalHold{DisplayName: "Case 1", CreatorId: model.NewId(), UserIds: []string{model.NewId()}})
+	require.NoError(t, err)
+	hold2, err := ss.LegalHold().Save(&model.LegalHold{DisplayName: "Case 2", CreatorId: model.NewId(), ChannelIds: []string{model.NewId()}})
+	require.NoError(t, err)
+
+	ids := func(holds []*model.LegalHold) []string {
+		var ids []string
+		for _, hold := range holds {
+			ids = append(ids, hold.Id)
+		}
+		return ids
+	}
+
+	active, err := ss.LegalHold().GetActive()
+	require.NoError(t, err)
+	assert.Subset(t, ids(active), []string{hold1.Id, hold2.Id})
+
+	require.NoError(t, ss.LegalHold().Release(hold1.Id, model.GetMillis()))
+	defer ss.LegalHold().Release(hold2.Id, model.GetMillis())
+
+	var nfErr *store.ErrNotFound
+	assert.ErrorAs(t, ss.LegalHold().Release(hold1.Id, model.GetMillis()), &nfErr)
+
+	released, err := ss.LegalHold().Get(hold1.Id)
+	require.NoError(t, err)
+	assert.False(t, released.IsActive())
+
+	active, err = ss.LegalHold().GetActive()
+	require.NoError(t, err)
+	assert.NotContains(t, ids(active), hold1.Id)
+	assert.Contains(t, ids(active), hold2.Id)
+
+	all, err := ss.LegalHold().GetAll(0, 1000, false)
+	require.NoError(t, err)
+	assert.NotContains(t, ids(all), hold1.Id)
+
+	all, err = ss.LegalHold().GetAll(0, 1000, true)
+	require.NoError(t, err)
+	assert.Subset(t, ids(all), []string{hold1.Id, hold2.Id})
+}
+
+func testLegalHoldStoreExcludedFromDeletion(t *testing.T, rctx request.CTX, ss store.Store) {
+	team, err := ss.Team().Save(&model.Team{
+		DisplayName: "DisplayName",
+		Name:        "team" + model.NewId(),
+		Email:       MakeEmail(),
+		Type:        model.TeamOpen,
+	})
+	require.NoError(t, err)
+	defer ss.Team().PermanentDelete(team.Id)
+
+	channel, err := ss.Channel().Save(rctx, &model.Channel{
+		TeamId:      team.Id,
+		DisplayName: "DisplayName",
+		Name:        "channel" + model.NewId(),
+		Type:        model.ChannelTypeOpen,
+	}, -1)
+	require.NoError(t, err)
+	defer ss.Channel().PermanentDelete(rctx, channel.Id)
+
+	userID := model.NewId()
+	held, err := ss.Post().Save(rctx, &model.Post{ChannelId: channel.Id, UserId: userID, Message: NewTestID(), CreateAt: 1000})
+	require.NoError(t, err)
+	notHeld, err := ss.Post().Save(rctx, &model.Post{ChannelId: channel.Id, UserId: userID, Message: NewTestID(), CreateAt: 500})
+	require.NoError(t, err)
+	heldFile, err := ss.FileInfo().Save(rctx, &model.FileInfo{CreatorId: userID, PostId: held.Id, ChannelId: channel.Id, Path: "held.txt", CreateAt: 1000})
+	require.NoError(t, err)
+	defer ss.FileInfo().PermanentDelete(rctx, heldFile.Id)
+
+	postCount, err := ss.Post().CountForRetentionPolicies(0, 2000)
+	require.NoError(t, err)
+	fileCount, err := ss.FileInfo().CountForRetention(2000)
+	require.NoError(t, err)
+
+	hold, err := ss.LegalHold().Save(&model.LegalHold{
+		DisplayName: "Case 1",
+		CreatorId:   model.NewId(),
+		ChannelIds:  []string{channel.Id},
+		StartAt:     900,
+	})
+	require.NoError(t, err)
+
+	heldPostCount, err := ss.Post().CountForRetentionPolicies(0, 2000)
+	require.NoError(t, err)
+	assert.Equal(t, postCount-1, heldPostCount)
+	heldFileCount, err := ss.FileInfo().CountForRetention(2000)
+	require.NoError(t, err)
+	assert.Equal(t, fileCount-1, heldFileCount)
+
+	infos, err := ss.FileInfo().GetBatchForRetention(2000, 10000)
+	require.NoError(t, err)
+	for _, info := range infos {
+		assert.NotEqual(t, heldFile.Id, info.Id)
+	}
+
+	require.NoError(t, ss.Post().PermanentDeleteByUser(rctx, userID))
+	deletedFiles, err := ss.FileInfo().PermanentDeleteByUser(rctx, userID)
+	require.NoError(t, err)
+	assert.Zero(t, deletedFiles)
+
+	_, err = ss.Post().GetSingle(rctx, held.Id, true)
+	assert.NoError(t, err, "the held post should be kept")
+	_, err = ss.Post().GetSingle(rctx, notHeld.Id, true)
+	assert.Error(t, err, "the post outside of the hold should be deleted")
+	_, err = ss.FileInfo().Get(heldFile.Id)
+	assert.NoError(t, err, "the held file should be kept")
+
+	require.NoError(t, ss.LegalHold().Release(hold.Id, model.GetMillis()))
+
+	releasedPostCount, err := ss.Post().CountForRetentionPolicies(0, 2000)
+	require.NoError(t, err)
+	assert.Equal(t, heldPostCount, releasedPostCount)
+
+	require.NoError(t, ss.Post().PermanentDeleteByUser(rctx, userID))
+	_, err = ss.Post().GetSingle(rctx, held.Id, true)
+	assert.Error(t, err, "the post should be deleted once the hold is released")
+}
+
+func testLegalHoldStoreThreadsExcludedFromRetention(t *testing.T, rctx request.CTX, ss store.Store) {
+	team, err := ss.Team().Save(&model.Team{
+		DisplayName: "DisplayName",
+		Name:        "team" + model.NewId(),
+		Email:       MakeEmail(),
+		Type:        model.TeamOpen,
+	})
+	require.NoError(t, err)
+	defer ss.Team().PermanentDelete(team.Id)
+
+	channel, err := ss.Channel().Save(rctx, &model.Channel{
+		TeamId:      team.Id,
+		DisplayName: "DisplayName",
+		Name:        "channel" + model.NewId(),
+		Type:        model.ChannelTypeOpen,
+	}, -1)
+	require.NoError(t, err)
+	defer ss.Channel().PermanentDelete(rctx, channel.Id)
+
+	authorID := model.NewId()
+	memberID := model.NewId()
+	createThread := func(createAt int64) *model.Post {
+		root, err := ss.Post().Save(rctx, &model.Post{ChannelId: channel.Id, UserId: authorID, Message: NewTestID(), CreateAt: createAt})
+		require.NoError(t, err)
+		_, err = ss.Post().Save(rctx, &model.Post{ChannelId: channel.Id, UserId: memberID, RootId: root.Id, Message: NewTestID(), CreateAt: createAt + 10})
+		require.NoError(t, err)
+		_, err = ss.Thread().MaintainMembership(memberID, root.Id, store.ThreadMembershipOpts{Following: true, UpdateFollowing: true})
+		require.NoError(t, err)
+		return root
+	}
+	held := createThread(1000)
+	notHeld := createThread(500)
+
+	hold, err := ss.LegalHold().Save(&model.LegalHold{
+		DisplayName: "Case 1",
+		CreatorId:   model.NewId(),
+		UserIds:     []string{authorID},
+		StartAt:     900,
+	})
+	require.NoError(t, err)
+	defer ss.LegalHold().Release(hold.Id, model.GetMillis())
+
+	globalPolicyEndTime := model.GetMillis() + 1000
+	_, _, err = ss.Thread().PermanentDeleteBatchThreadMembershipsForRetentionPolicies(0, globalPolicyEndTime, 1000, model.RetentionPolicyCursor{})
+	require.NoError(t, err)
+	_, _, err = ss.Thread().PermanentDeleteBatchForRetentionPolicies(0, globalPolicyEndTime, 1000, model.RetentionPolicyCursor{})
+	require.NoError(t, err)
+
+	thread, err := ss.Thread().Get(held.Id)
+	require.NoError(t, err)
+	assert.NotNil(t, thread, "the thread of the held post should be kept")
+	_, err = ss.Thread().GetMembershipForUser(memberID, held.Id)
+	assert.NoError(t, err, "the membership of the held thread should be kept")
+
+	thread, err = ss.Thread().Get(notHeld.Id)
+	require.NoError(t, err)
+	assert.Nil(t, thread, "the thread outside of the hold should be deleted")
+	_, err = ss.Thread().GetMembershipForUser(memberID, notHeld.Id)
+	assert.Error(t, err, "the membership of the thread outside of the hold should be deleted")
+}
+
+func testLegalHoldStoreExcludedFromChannelDeletion(t *testing.T, rctx request.CTX, ss store.Store) {
+	channelID := model.NewId()
+	heldUserID := model.NewId()
+	otherUserID := model.NewId()
+
+	held, err := ss.Post().Save(rctx, &model.Post{ChannelId: channelID, UserId: heldUserID, Message: NewTestID(), CreateAt: 1000})
+	require.NoError(t, err)
+	notHeld, err := ss.Post().Save(rctx, &model.Post{ChannelId: channelID, UserId: otherUserID, Message: NewTestID(), CreateAt: 1000})
+	require.NoError(t, err)
+	root, err := ss.Post().Save(rctx, &model.Post{ChannelId: channelID, UserId: otherUserID, Message: NewTestID(), CreateAt: 1000})
+	require.NoError(t, err)
+	heldReply, err := ss.Post().Save(rctx, &model.Post{ChannelId: channelID, UserId: heldUserID, RootId: root.Id, Message: NewTestID(), CreateAt: 1001})
+	require.NoError(t, err)
+
+	hold, err := ss.LegalHold().Save(&model.LegalHold{
+		DisplayName: "Case 1",
+		CreatorId:   model.NewId(),
+		UserIds:     []string{heldUserID},
+		StartAt:     500,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, ss.Post().PermanentDeleteByChannel(rctx, channelID))
+
+	_, err = ss.Post().GetSingle(rctx, held.Id, true)
+	assert.NoError(t, err, "the held post should be kept")
+	_, err = ss.Post().GetSingle(rctx, heldReply.Id, true)
+	assert.NoError(t, err, "the held reply should be kept")
+	_, err = ss.Post().GetSingle(rctx, root.Id, true)
+	assert.NoError(t, err, "the root post of a held reply should be kept")
+	_, err = ss.Post().GetSingle(rctx, notHeld.Id, true)
+	assert.Error(t, err, "the post outside of the hold should be deleted")
+
+	require.NoError(t, ss.LegalHold().Release(hold.Id, model.GetMillis()))
+
+	require.NoError(t, ss.Post().PermanentDeleteByChannel(rctx, channelID))
+	for _, post := range []*model.Post{held, root, heldReply} {
+		_, err = ss.Post().GetSingle(rctx, post.Id, true)
+		assert.Error(t, err, "the posts should be deleted once the hold is released")
+	}
+}
+
+func testLegalHoldStoreMessageExport(t *testing.T, rctx request.CTX, ss store.Store) {
+	team, err := ss.Team().Save(&model.Team{
+		DisplayName: "DisplayName",
+		Name:        "team" + model.NewId(),
+		Email:       MakeEmail(),
+		Type:        model.TeamOpen,
+	})
+	require.NoError(t, err)
+	defer ss.Team().PermanentDelete(team.Id)
+
+	channel, err := ss.Channel().Save(rctx, &model.Channel{
+		TeamId:      team.Id,
+		DisplayName: "DisplayName",
+		Name:        "channel" + model.NewId(),
+		Type:        model.ChannelTypeOpen,
+	}, -1)
+	require.NoError(t, err)
+	defer ss.Channel().PermanentDelete(rctx, channel.Id)
+
+	heldUserID := model.NewId()
+	held, err := ss.Post().Save(rctx, &model.Post{ChannelId: channel.Id, UserId: heldUserID, Message: NewTestID(), CreateAt: 1000})
+	require.NoError(t, err)
+	defer ss.Post().PermanentDelete(rctx, held.Id)
+	tooOld, err := ss.Post().Save(rctx, &model.Post{ChannelId: channel.Id, UserId: heldUserID, Message: NewTestID(), CreateAt: 100})
+	require.NoError(t, err)
+	defer ss.Post().PermanentDelete(rctx, tooOld.Id)
+	otherUser, err := ss.Post().Save(rctx, &model.Post{ChannelId: channel.Id, UserId: model.NewId(), Message: NewTestID(), CreateAt: 1000})
+	require.NoError(t, err)
+	defer ss.Post().PermanentDelete(rctx, otherUser.Id)
+
+	hold, err := ss.LegalHold().Save(&model.LegalHold{
+		DisplayName: "Case 1",
+		CreatorId:   model.NewId(),
+		UserIds:     []string{heldUserID},
+		StartAt:     500,
+	})
+	require.NoError(t, err)
+	require.NoError(t, ss.LegalHold().Release(hold.Id, model.GetMillis()))
+
+	messages, _, err := ss.Compliance().MessageExport(rctx, model.MessageExportCursor{LegalHoldId: hold.Id}, 100)
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, held.Id, *messages[0].PostId)
+}
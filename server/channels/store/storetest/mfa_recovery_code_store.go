@@ -0,0 +1,100 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package storetest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+)
+
+func TestMfaRecoveryCodeStore(t *testing.T, rctx request.CTX, ss store.Store) {
+	t.Run("ReplaceForUser", func(t *testing.T) { testMfaRecoveryCodeReplaceForUser(t, rctx, ss) })
+	t.Run("GetForUser", func(t *testing.T) { testMfaRecoveryCodeGetForUser(t, rctx, ss) })
+	t.Run("Consume", func(t *testing.T) { testMfaRecoveryCodeConsume(t, rctx, ss) })
+	t.Run("DeleteAllForUser", func(t *testing.T) { testMfaRecoveryCodeDeleteAllForUser(t, rctx, ss) })
+}
+
+func testMfaRecoveryCodeReplaceForUser(t *testing.T, rctx request.CTX, ss store.Store) {
+	userID := model.NewId()
+	old := []string{model.NewId(), model.NewId()}
+	replacement := []string{model.NewId(), model.NewId()}
+
+	require.NoError(t, ss.MfaRecoveryCode().ReplaceForUser(userID, old))
+	require.NoError(t, ss.MfaRecoveryCode().ReplaceForUser(userID, replacement))
+
+	ok, err := ss.MfaRecoveryCode().Consume(userID, old[0])
+	require.NoError(t, err)
+	require.False(t, ok, "replaced codes should no longer be valid")
+
+	ok, err = ss.MfaRecoveryCode().Consume(userID, replacement[0])
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	require.NoError(t, ss.MfaRecoveryCode().ReplaceForUser(userID, nil))
+
+	ok, err = ss.MfaRecoveryCode().Consume(userID, replacement[1])
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func testMfaRecoveryCodeGetForUser(t *testing.T, rctx request.CTX, ss store.Store) {
+	userID := model.NewId()
+	hashes := []string{model.NewId(), model.NewId()}
+
+	codeHashes, err := ss.MfaRecoveryCode().GetForUser(userID)
+	require.NoError(t, err)
+	require.Empty(t, codeHashes)
+
+	require.NoError(t, ss.MfaRecoveryCode().ReplaceForUser(userID, hashes))
+	require.NoError(t, ss.MfaRecoveryCode().ReplaceForUser(model.NewId(), []string{model.NewId()}))
+
+	codeHashes, err = ss.MfaRecoveryCode().GetForUser(userID)
+	require.NoError(t, err)
+	require.ElementsMatch(t, hashes, codeHashes)
+}
+
+func testMfaRecoveryCodeConsume(t *testing.T, rctx request.CTX, ss store.Store) {
+	userID := model.NewId()
+	otherUserID := model.NewId()
+	hash := model.NewId()
+
+	require.NoError(t, ss.MfaRecoveryCode().ReplaceForUser(userID, []string{hash}))
+	require.NoError(t, ss.MfaRecoveryCode().ReplaceForUser(otherUserID, []string{hash}))
+
+	ok, err := ss.MfaRecoveryCode().Consume(userID, hash)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = ss.MfaRecoveryCode().Consume(userID, hash)
+	require.NoError(t, err)
+	require.False(t, ok, "codes should only be consumed once")
+
+	ok, err = ss.MfaRecoveryCode().Consume(otherUserID, hash)
+	require.NoError(t, err)
+	require.True(t, ok, "consuming a code should not affect other users")
+}
+
+func testMfaRecoveryCodeDeleteAllForUser(t *testing.T, rctx request.CTX, ss store.Store) {
+	userID := model.NewId()
+	otherUserID := model.NewId()
+	hash := model.NewId()
+
+	require.NoError(t, ss.MfaRecoveryCode().ReplaceForUser(userID, []string{hash}))
+	require.NoError(t, ss.MfaRecoveryCode().ReplaceForUser(otherUserID, []string{hash}))
+
+	require.NoError(t, ss.MfaRecoveryCode().DeleteAllForUser(userID))
+
+	ok, err := ss.MfaRecoveryCode().Consume(userID, hash)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	ok, err = ss.MfaRecoveryCode().Consume(otherUserID, hash)
+	require.NoError(t, err)
+	require.True(t, ok)
+}
@@ -66,6 +66,118 @@ func (_m *FileInfoStore) CountAll() (int64, error) {
 	return r0, r1
 }
 
+// CountByContentHash provides a mock function with given fields: hash
+func (_m *FileInfoStore) CountByContentHash(hash string) (int64, error) {
+	ret := _m.Called(hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountByContentHash")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (int64, error)); ok {
+		return rf(hash)
+	}
+	if rf, ok := ret.Get(0).(func(string) int64); ok {
+		r0 = rf(hash)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CountByPath provides a mock function with given fields: path
+func (_m *FileInfoStore) CountByPath(path string) (int64, error) {
+	ret := _m.Called(path)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountByPath")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (int64, error)); ok {
+		return rf(path)
+	}
+	if rf, ok := ret.Get(0).(func(string) int64); ok {
+		r0 = rf(path)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(path)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CountForRetention provides a mock function with given fields: endTime
+func (_m *FileInfoStore) CountForRetention(endTime int64) (int64, error) {
+	ret := _m.Called(endTime)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountForRetention")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64) (int64, error)); ok {
+		return rf(endTime)
+	}
+	if rf, ok := ret.Get(0).(func(int64) int64); ok {
+		r0 = rf(endTime)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(endTime)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CountForRetentionPolicies provides a mock function with given fields: now, globalPolicyEndTime
+func (_m *FileInfoStore) CountForRetentionPolicies(now int64, globalPolicyEndTime int64) (int64, error) {
+	ret := _m.Called(now, globalPolicyEndTime)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountForRetentionPolicies")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64, int64) (int64, error)); ok {
+		return rf(now, globalPolicyEndTime)
+	}
+	if rf, ok := ret.Get(0).(func(int64, int64) int64); ok {
+		r0 = rf(now, globalPolicyEndTime)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(int64, int64) error); ok {
+		r1 = rf(now, globalPolicyEndTime)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteForPost provides a mock function with given fields: c, postID
 func (_m *FileInfoStore) DeleteForPost(c request.CTX, postID string) (string, error) {
 	ret := _m.Called(c, postID)
@@ -142,6 +254,36 @@ func (_m *FileInfoStore) Get(id string) (*model.FileInfo, error) {
 	return r0, r1
 }
 
+// GetBatchForRetention provides a mock function with given fields: endTime, limit
+func (_m *FileInfoStore) GetBatchForRetention(endTime int64, limit int) ([]*model.FileInfo, error) {
+	ret := _m.Called(endTime, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBatchForRetention")
+	}
+
+	var r0 []*model.FileInfo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64, int) ([]*model.FileInfo, error)); ok {
+		return rf(endTime, limit)
+	}
+	if rf, ok := ret.Get(0).(func(int64, int) []*model.FileInfo); ok {
+		r0 = rf(endTime, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.FileInfo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64, int) error); ok {
+		r1 = rf(endTime, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetByIds provides a mock function with given fields: ids, includeDeleted, allowFromCache
 func (_m *FileInfoStore) GetByIds(ids []string, includeDeleted bool, allowFromCache bool) ([]*model.FileInfo, error) {
 	ret := _m.Called(ids, includeDeleted, allowFromCache)
@@ -202,6 +344,66 @@ func (_m *FileInfoStore) GetByPath(path string) (*model.FileInfo, error) {
 	return r0, r1
 }
 
+// GetByPostIds provides a mock function with given fields: postIDs
+func (_m *FileInfoStore) GetByPostIds(postIDs []string) ([]*model.FileInfo, error) {
+	ret := _m.Called(postIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByPostIds")
+	}
+
+	var r0 []*model.FileInfo
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]string) ([]*model.FileInfo, error)); ok {
+		return rf(postIDs)
+	}
+	if rf, ok := ret.Get(0).(func([]string) []*model.FileInfo); ok {
+		r0 = rf(postIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.FileInfo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]string) error); ok {
+		r1 = rf(postIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetFilesBatchForDeduplication provides a mock function with given fields: afterID, limit
+func (_m *FileInfoStore) GetFilesBatchForDeduplication(afterID string, limit int) ([]*model.FileInfo, error) {
+	ret := _m.Called(afterID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFilesBatchForDeduplication")
+	}
+
+	var r0 []*model.FileInfo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, int) ([]*model.FileInfo, error)); ok {
+		return rf(afterID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(string, int) []*model.FileInfo); ok {
+		r0 = rf(afterID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.FileInfo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int) error); ok {
+		r1 = rf(afterID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetFilesBatchForIndexing provides a mock function with given fields: startTime, startFileID, includeDeleted, limit
 func (_m *FileInfoStore) GetFilesBatchForIndexing(startTime int64, startFileID string, includeDeleted bool, limit int) ([]*model.FileForIndexing, error) {
 	ret := _m.Called(startTime, startFileID, includeDeleted, limit)
@@ -459,6 +661,34 @@ func (_m *FileInfoStore) PermanentDeleteBatch(ctx request.CTX, endTime int64, li
 	return r0, r1
 }
 
+// PermanentDeleteByIds provides a mock function with given fields: rctx, fileIDs
+func (_m *FileInfoStore) PermanentDeleteByIds(rctx request.CTX, fileIDs []string) (int64, error) {
+	ret := _m.Called(rctx, fileIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PermanentDeleteByIds")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(request.CTX, []string) (int64, error)); ok {
+		return rf(rctx, fileIDs)
+	}
+	if rf, ok := ret.Get(0).(func(request.CTX, []string) int64); ok {
+		r0 = rf(rctx, fileIDs)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(request.CTX, []string) error); ok {
+		r1 = rf(rctx, fileIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // PermanentDeleteByUser provides a mock function with given fields: ctx, userID
 func (_m *FileInfoStore) PermanentDeleteByUser(ctx request.CTX, userID string) (int64, error) {
 	ret := _m.Called(ctx, userID)
@@ -505,6 +735,24 @@ func (_m *FileInfoStore) PermanentDeleteForPost(rctx request.CTX, postID string)
 	return r0
 }
 
+// Quarantine provides a mock function with given fields: rctx, fileID, reason
+func (_m *FileInfoStore) Quarantine(rctx request.CTX, fileID string, reason string) error {
+	ret := _m.Called(rctx, fileID, reason)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Quarantine")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(request.CTX, string, string) error); ok {
+		r0 = rf(rctx, fileID, reason)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // RestoreForPostByIds provides a mock function with given fields: rctx, postId, fileIDs
 func (_m *FileInfoStore) RestoreForPostByIds(rctx request.CTX, postId string, fileIDs []string) error {
 	ret := _m.Called(rctx, postId, fileIDs)
@@ -601,6 +849,24 @@ func (_m *FileInfoStore) SetContent(ctx request.CTX, fileID string, content stri
 	return r0
 }
 
+// UpdateContentHash provides a mock function with given fields: rctx, fileIDs, newPath, hash
+func (_m *FileInfoStore) UpdateContentHash(rctx request.CTX, fileIDs []string, newPath string, hash string) error {
+	ret := _m.Called(rctx, fileIDs, newPath, hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateContentHash")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(request.CTX, []string, string, string) error); ok {
+		r0 = rf(rctx, fileIDs, newPath, hash)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Upsert provides a mock function with given fields: rctx, info
 func (_m *FileInfoStore) Upsert(rctx request.CTX, info *model.FileInfo) (*model.FileInfo, error) {
 	ret := _m.Called(rctx, info)
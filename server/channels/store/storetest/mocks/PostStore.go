@@ -113,6 +113,34 @@ func (_m *PostStore) ClearCaches() {
 	_m.Called()
 }
 
+// CountForRetentionPolicies provides a mock function with given fields: now, globalPolicyEndTime
+func (_m *PostStore) CountForRetentionPolicies(now int64, globalPolicyEndTime int64) (int64, error) {
+	ret := _m.Called(now, globalPolicyEndTime)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountForRetentionPolicies")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64, int64) (int64, error)); ok {
+		return rf(now, globalPolicyEndTime)
+	}
+	if rf, ok := ret.Get(0).(func(int64, int64) int64); ok {
+		r0 = rf(now, globalPolicyEndTime)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(int64, int64) error); ok {
+		r1 = rf(now, globalPolicyEndTime)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Delete provides a mock function with given fields: rctx, postID, timestamp, deleteByID
 func (_m *PostStore) Delete(rctx request.CTX, postID string, timestamp int64, deleteByID string) error {
 	ret := _m.Called(rctx, postID, timestamp, deleteByID)
@@ -44,6 +44,26 @@ func (_m *Store) Audit() store.AuditStore {
 	return r0
 }
 
+// BatchedEmailNotification provides a mock function with given fields:
+func (_m *Store) BatchedEmailNotification() store.BatchedEmailNotificationStore {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for BatchedEmailNotification")
+	}
+
+	var r0 store.BatchedEmailNotificationStore
+	if rf, ok := ret.Get(0).(func() store.BatchedEmailNotificationStore); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.BatchedEmailNotificationStore)
+		}
+	}
+
+	return r0
+}
+
 // Bot provides a mock function with given fields:
 func (_m *Store) Bot() store.BotStore {
 	ret := _m.Called()
@@ -169,6 +189,26 @@ func (_m *Store) ClusterDiscovery() store.ClusterDiscoveryStore {
 	return r0
 }
 
+// ClusterPayload provides a mock function with given fields:
+func (_m *Store) ClusterPayload() store.ClusterPayloadStore {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClusterPayload")
+	}
+
+	var r0 store.ClusterPayloadStore
+	if rf, ok := ret.Get(0).(func() store.ClusterPayloadStore); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.ClusterPayloadStore)
+		}
+	}
+
+	return r0
+}
+
 // Command provides a mock function with given fields:
 func (_m *Store) Command() store.CommandStore {
 	ret := _m.Called()
@@ -528,6 +568,26 @@ func (_m *Store) Job() store.JobStore {
 	return r0
 }
 
+// LegalHold provides a mock function with given fields:
+func (_m *Store) LegalHold() store.LegalHoldStore {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for LegalHold")
+	}
+
+	var r0 store.LegalHoldStore
+	if rf, ok := ret.Get(0).(func() store.LegalHoldStore); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.LegalHoldStore)
+		}
+	}
+
+	return r0
+}
+
 // License provides a mock function with given fields:
 func (_m *Store) License() store.LicenseStore {
 	ret := _m.Called()
@@ -598,6 +658,26 @@ func (_m *Store) MarkSystemRanUnitTests() {
 	_m.Called()
 }
 
+// MfaRecoveryCode provides a mock function with given fields:
+func (_m *Store) MfaRecoveryCode() store.MfaRecoveryCodeStore {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for MfaRecoveryCode")
+	}
+
+	var r0 store.MfaRecoveryCodeStore
+	if rf, ok := ret.Get(0).(func() store.MfaRecoveryCodeStore); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.MfaRecoveryCodeStore)
+		}
+	}
+
+	return r0
+}
+
 // NotifyAdmin provides a mock function with given fields:
 func (_m *Store) NotifyAdmin() store.NotifyAdminStore {
 	ret := _m.Called()
@@ -1323,6 +1403,26 @@ func (_m *Store) UserTermsOfService() store.UserTermsOfServiceStore {
 	return r0
 }
 
+// WebAuthnCredential provides a mock function with given fields:
+func (_m *Store) WebAuthnCredential() store.WebAuthnCredentialStore {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for WebAuthnCredential")
+	}
+
+	var r0 store.WebAuthnCredentialStore
+	if rf, ok := ret.Get(0).(func() store.WebAuthnCredentialStore); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.WebAuthnCredentialStore)
+		}
+	}
+
+	return r0
+}
+
 // Webhook provides a mock function with given fields:
 func (_m *Store) Webhook() store.WebhookStore {
 	ret := _m.Called()
@@ -50,6 +50,7 @@ func TestPostStore(t *testing.T, rctx request.CTX, ss store.Store, s SqlStore) {
 	t.Run("GetPostsByIds", func(t *testing.T) { testPostStoreGetPostsByIds(t, rctx, ss) })
 	t.Run("GetPostsBatchForIndexing", func(t *testing.T) { testPostStoreGetPostsBatchForIndexing(t, rctx, ss) })
 	t.Run("PermanentDeleteBatch", func(t *testing.T) { testPostStorePermanentDeleteBatch(t, rctx, ss) })
+	t.Run("CountForRetentionPolicies", func(t *testing.T) { testPostStoreCountForRetentionPolicies(t, rctx, ss) })
 	t.Run("GetOldest", func(t *testing.T) { testPostStoreGetOldest(t, rctx, ss) })
 	t.Run("TestGetMaxPostSize", func(t *testing.T) { testGetMaxPostSize(t, rctx, ss) })
 	t.Run("GetParentsForExportAfter", func(t *testing.T) { testPostStoreGetParentsForExportAfter(t, rctx, ss) })
@@ -4302,6 +4303,69 @@ func testPostStorePermanentDeleteBatch(t *testing.T, rctx request.CTX, ss store.
 	})
 }
 
+func testPostStoreCountForRetentionPolicies(t *testing.T, rctx request.CTX, ss store.Store) {
+	team, err := ss.Team().Save(&model.Team{
+		DisplayName: "DisplayName",
+		Name:        "team" + model.NewId(),
+		Email:       MakeEmail(),
+		Type:        model.TeamOpen,
+	})
+	require.NoError(t, err)
+	defer ss.Team().PermanentDelete(team.Id)
+
+	channel, err := ss.Channel().Save(rctx, &model.Channel{
+		TeamId:      team.Id,
+		DisplayName: "DisplayName",
+		Name:        "channel" + model.NewId(),
+		Type:        model.ChannelTypeOpen,
+	}, -1)
+	require.NoError(t, err)
+	defer ss.Channel().PermanentDelete(rctx, channel.Id)
+
+	globalCount, err := ss.Post().CountForRetentionPolicies(0, 2000)
+	require.NoError(t, err)
+
+	for _, createAt := range []int64{1000, 1000, 100000} {
+		_, err = ss.Post().Save(rctx, &model.Post{ChannelId: channel.Id, UserId: model.NewId(), Message: NewTestID(), CreateAt: createAt})
+		require.NoError(t, err)
+	}
+	defer ss.Post().PermanentDeleteByChannel(rctx, channel.Id)
+
+	t.Run("global policy", func(t *testing.T) {
+		count, err := ss.Post().CountForRetentionPolicies(0, 2000)
+		require.NoError(t, err)
+		assert.Equal(t, globalCount+2, count)
+
+		count, err = ss.Post().CountForRetentionPolicies(0, 0)
+		require.NoError(t, err)
+		assert.Zero(t, count, "nothing should be counted without any policy")
+	})
+
+	t.Run("channel policy", func(t *testing.T) {
+		nowMillis := int64(100000) + model.DayInMilliseconds + 1
+		policiesCount, err := ss.Post().CountForRetentionPolicies(nowMillis, 0)
+		require.NoError(t, err)
+
+		policy, err := ss.RetentionPolicy().Save(&model.RetentionPolicyWithTeamAndChannelIDs{
+			RetentionPolicy: model.RetentionPolicy{
+				DisplayName:      "DisplayName",
+				PostDurationDays: model.NewPointer(int64(1)),
+			},
+			ChannelIDs: []string{channel.Id},
+		})
+		require.NoError(t, err)
+		defer ss.RetentionPolicy().Delete(policy.ID)
+
+		count, err := ss.Post().CountForRetentionPolicies(0, 2000)
+		require.NoError(t, err)
+		assert.Equal(t, globalCount, count, "the global policy should be ignored due to the channel policy")
+
+		count, err = ss.Post().CountForRetentionPolicies(nowMillis, 0)
+		require.NoError(t, err)
+		assert.Equal(t, policiesCount+3, count)
+	})
+}
+
 func testPostStoreGetOldest(t *testing.T, rctx request.CTX, ss store.Store) {
 	teamID := model.NewId()
 	channel1, err := ss.Channel().Save(rctx, &model.Channel{
@@ -26,6 +26,8 @@ type Store struct {
 	BotStore                        mocks.BotStore
 	AuditStore                      mocks.AuditStore
 	ClusterDiscoveryStore           mocks.ClusterDiscoveryStore
+	ClusterPayloadStore             mocks.ClusterPayloadStore
+	BatchedEmailNotificationStore   mocks.BatchedEmailNotificationStore
 	RemoteClusterStore              mocks.RemoteClusterStore
 	ComplianceStore                 mocks.ComplianceStore
 	SessionStore                    mocks.SessionStore
@@ -46,6 +48,8 @@ type Store struct {
 	ReactionStore                   mocks.ReactionStore
 	JobStore                        mocks.JobStore
 	UserAccessTokenStore            mocks.UserAccessTokenStore
+	WebAuthnCredentialStore         mocks.WebAuthnCredentialStore
+	MfaRecoveryCodeStore            mocks.MfaRecoveryCodeStore
 	PluginStore                     mocks.PluginStore
 	ChannelMemberHistoryStore       mocks.ChannelMemberHistoryStore
 	RoleStore                       mocks.RoleStore
@@ -66,6 +70,7 @@ type Store struct {
 	DesktopTokensStore              mocks.DesktopTokensStore
 	ChannelBookmarkStore            mocks.ChannelBookmarkStore
 	ScheduledPostStore              mocks.ScheduledPostStore
+	LegalHoldStore                  mocks.LegalHoldStore
 	PropertyGroupStore              mocks.PropertyGroupStore
 	PropertyFieldStore              mocks.PropertyFieldStore
 	PropertyValueStore              mocks.PropertyValueStore
@@ -83,6 +88,7 @@ func (s *Store) Bot() store.BotStore                           { return &s.BotSt
 func (s *Store) ProductNotices() store.ProductNoticesStore     { return &s.ProductNoticesStore }
 func (s *Store) Audit() store.AuditStore                       { return &s.AuditStore }
 func (s *Store) ClusterDiscovery() store.ClusterDiscoveryStore { return &s.ClusterDiscoveryStore }
+func (s *Store) ClusterPayload() store.ClusterPayloadStore     { return &s.ClusterPayloadStore }
 func (s *Store) RemoteCluster() store.RemoteClusterStore       { return &s.RemoteClusterStore }
 func (s *Store) Compliance() store.ComplianceStore             { return &s.ComplianceStore }
 func (s *Store) Session() store.SessionStore                   { return &s.SessionStore }
@@ -90,6 +96,9 @@ func (s *Store) OAuth() store.OAuthStore                       { return &s.OAuth
 func (s *Store) OutgoingOAuthConnection() store.OutgoingOAuthConnectionStore {
 	return &s.OutgoingOAuthConnectionStore
 }
+func (s *Store) BatchedEmailNotification() store.BatchedEmailNotificationStore {
+	return &s.BatchedEmailNotificationStore
+}
 func (s *Store) System() store.SystemStore                         { return &s.SystemStore }
 func (s *Store) Webhook() store.WebhookStore                       { return &s.WebhookStore }
 func (s *Store) Command() store.CommandStore                       { return &s.CommandStore }
@@ -105,6 +114,8 @@ func (s *Store) UploadSession() store.UploadSessionStore           { return &s.U
 func (s *Store) Reaction() store.ReactionStore                     { return &s.ReactionStore }
 func (s *Store) Job() store.JobStore                               { return &s.JobStore }
 func (s *Store) UserAccessToken() store.UserAccessTokenStore       { return &s.UserAccessTokenStore }
+func (s *Store) WebAuthnCredential() store.WebAuthnCredentialStore { return &s.WebAuthnCredentialStore }
+func (s *Store) MfaRecoveryCode() store.MfaRecoveryCodeStore       { return &s.MfaRecoveryCodeStore }
 func (s *Store) Plugin() store.PluginStore                         { return &s.PluginStore }
 func (s *Store) Role() store.RoleStore                             { return &s.RoleStore }
 func (s *Store) Scheme() store.SchemeStore                         { return &s.SchemeStore }
@@ -122,6 +133,7 @@ func (s *Store) LinkMetadata() store.LinkMetadataStore       { return &s.LinkMet
 func (s *Store) SharedChannel() store.SharedChannelStore     { return &s.SharedChannelStore }
 func (s *Store) PostPriority() store.PostPriorityStore       { return &s.PostPriorityStore }
 func (s *Store) ScheduledPost() store.ScheduledPostStore     { return &s.ScheduledPostStore }
+func (s *Store) LegalHold() store.LegalHoldStore             { return &s.LegalHoldStore }
 func (s *Store) PropertyGroup() store.PropertyGroupStore     { return &s.PropertyGroupStore }
 func (s *Store) PropertyField() store.PropertyFieldStore     { return &s.PropertyFieldStore }
 func (s *Store) PropertyValue() store.PropertyValueStore     { return &s.PropertyValueStore }
@@ -164,6 +176,8 @@ func (s *Store) AssertExpectations(t mock.TestingT) bool {
 		&s.BotStore,
 		&s.AuditStore,
 		&s.ClusterDiscoveryStore,
+		&s.ClusterPayloadStore,
+		&s.BatchedEmailNotificationStore,
 		&s.RemoteClusterStore,
 		&s.ComplianceStore,
 		&s.SessionStore,
@@ -182,6 +196,8 @@ func (s *Store) AssertExpectations(t mock.TestingT) bool {
 		&s.ReactionStore,
 		&s.JobStore,
 		&s.UserAccessTokenStore,
+		&s.WebAuthnCredentialStore,
+		&s.MfaRecoveryCodeStore,
 		&s.ChannelMemberHistoryStore,
 		&s.PluginStore,
 		&s.RoleStore,
@@ -197,5 +213,6 @@ func (s *Store) AssertExpectations(t mock.TestingT) bool {
 		&s.DesktopTokensStore,
 		&s.ChannelBookmarkStore,
 		&s.ScheduledPostStore,
+		&s.LegalHoldStore,
 	)
 }
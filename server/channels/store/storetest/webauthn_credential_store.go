@@ -0,0 +1,133 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package storetest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+)
+
+func TestWebAuthnCredentialStore(t *testing.T, rctx request.CTX, ss store.Store) {
+	t.Run("SaveGet", func(t *testing.T) { testWebAuthnCredentialSaveGet(t, rctx, ss) })
+	t.Run("SaveDuplicateCredentialId", func(t *testing.T) { testWebAuthnCredentialSaveDuplicate(t, rctx, ss) })
+	t.Run("GetForUser", func(t *testing.T) { testWebAuthnCredentialGetForUser(t, rctx, ss) })
+	t.Run("UpdateSignCount", func(t *testing.T) { testWebAuthnCredentialUpdateSignCount(t, rctx, ss) })
+	t.Run("Delete", func(t *testing.T) { testWebAuthnCredentialDelete(t, rctx, ss) })
+}
+
+func newTestWebAuthnCredential(userID string) *model.WebAuthnCredential {
+	return &model.WebAuthnCredential{
+		UserId:          userID,
+		Name:            "security key",
+		CredentialId:    model.NewId(),
+		PublicKey:       model.NewId(),
+		AttestationType: "none",
+		Transports:      model.StringArray{"usb", "nfc"},
+	}
+}
+
+func testWebAuthnCredentialSaveGet(t *testing.T, rctx request.CTX, ss store.Store) {
+	credential, err := ss.WebAuthnCredential().Save(newTestWebAuthnCredential(model.NewId()))
+	require.NoError(t, err)
+	require.NotEmpty(t, credential.Id)
+	require.NotZero(t, credential.CreateAt)
+
+	received, err := ss.WebAuthnCredential().Get(credential.Id)
+	require.NoError(t, err)
+	assert.Equal(t, credential, received)
+
+	_, err = ss.WebAuthnCredential().Get(model.NewId())
+	var nfErr *store.ErrNotFound
+	require.ErrorAs(t, err, &nfErr)
+
+	_, err = ss.WebAuthnCredential().Save(&model.WebAuthnCredential{UserId: model.NewId()})
+	var appErr *model.AppError
+	require.ErrorAs(t, err, &appErr)
+}
+
+func testWebAuthnCredentialSaveDuplicate(t *testing.T, rctx request.CTX, ss store.Store) {
+	credential, err := ss.WebAuthnCredential().Save(newTestWebAuthnCredential(model.NewId()))
+	require.NoError(t, err)
+
+	duplicate := newTestWebAuthnCredential(model.NewId())
+	duplicate.CredentialId = credential.CredentialId
+	_, err = ss.WebAuthnCredential().Save(duplicate)
+	var cErr *store.ErrConflict
+	require.ErrorAs(t, err, &cErr)
+}
+
+func testWebAuthnCredentialGetForUser(t *testing.T, rctx request.CTX, ss store.Store) {
+	userID := model.NewId()
+
+	first := newTestWebAuthnCredential(userID)
+	first.CreateAt = 1000
+	_, err := ss.WebAuthnCredential().Save(first)
+	require.NoError(t, err)
+
+	second := newTestWebAuthnCredential(userID)
+	second.CreateAt = 2000
+	_, err = ss.WebAuthnCredential().Save(second)
+	require.NoError(t, err)
+
+	_, err = ss.WebAuthnCredential().Save(newTestWebAuthnCredential(model.NewId()))
+	require.NoError(t, err)
+
+	credentials, err := ss.WebAuthnCredential().GetForUser(userID)
+	require.NoError(t, err)
+	require.Len(t, credentials, 2)
+	assert.Equal(t, first.Id, credentials[0].Id)
+	assert.Equal(t, second.Id, credentials[1].Id)
+
+	credentials, err = ss.WebAuthnCredential().GetForUser(model.NewId())
+	require.NoError(t, err)
+	require.Empty(t, credentials)
+}
+
+func testWebAuthnCredentialUpdateSignCount(t *testing.T, rctx request.CTX, ss store.Store) {
+	credential, err := ss.WebAuthnCredential().Save(newTestWebAuthnCredential(model.NewId()))
+	require.NoError(t, err)
+
+	err = ss.WebAuthnCredential().UpdateSignCount(credential.Id, 42, 12345)
+	require.NoError(t, err)
+
+	received, err := ss.WebAuthnCredential().Get(credential.Id)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), received.SignCount)
+	assert.Equal(t, int64(12345), received.LastUsedAt)
+}
+
+func testWebAuthnCredentialDelete(t *testing.T, rctx request.CTX, ss store.Store) {
+	userID := model.NewId()
+
+	credential, err := ss.WebAuthnCredential().Save(newTestWebAuthnCredential(userID))
+	require.NoError(t, err)
+	_, err = ss.WebAuthnCredential().Save(newTestWebAuthnCredential(userID))
+	require.NoError(t, err)
+	_, err = ss.WebAuthnCredential().Save(newTestWebAuthnCredential(userID))
+	require.NoError(t, err)
+
+	err = ss.WebAuthnCredential().Delete(credential.Id)
+	require.NoError(t, err)
+
+	_, err = ss.WebAuthnCredential().Get(credential.Id)
+	var nfErr *store.ErrNotFound
+	require.ErrorAs(t, err, &nfErr)
+
+	credentials, err := ss.WebAuthnCredential().GetForUser(userID)
+	require.NoError(t, err)
+	require.Len(t, credentials, 2)
+
+	err = ss.WebAuthnCredential().DeleteAllForUser(userID)
+	require.NoError(t, err)
+
+	credentials, err = ss.WebAuthnCredential().GetForUser(userID)
+	require.NoError(t, err)
+	require.Empty(t, credentials)
+}
@@ -20,11 +20,13 @@ type TimerLayer struct {
 	store.Store
 	Metrics                         einterfaces.MetricsInterface
 	AuditStore                      store.AuditStore
+	BatchedEmailNotificationStore   store.BatchedEmailNotificationStore
 	BotStore                        store.BotStore
 	ChannelStore                    store.ChannelStore
 	ChannelBookmarkStore            store.ChannelBookmarkStore
 	ChannelMemberHistoryStore       store.ChannelMemberHistoryStore
 	ClusterDiscoveryStore           store.ClusterDiscoveryStore
+	ClusterPayloadStore             store.ClusterPayloadStore
 	CommandStore                    store.CommandStore
 	CommandWebhookStore             store.CommandWebhookStore
 	ComplianceStore                 store.ComplianceStore
@@ -34,8 +36,10 @@ type TimerLayer struct {
 	FileInfoStore                   store.FileInfoStore
 	GroupStore                      store.GroupStore
 	JobStore                        store.JobStore
+	LegalHoldStore                  store.LegalHoldStore
 	LicenseStore                    store.LicenseStore
 	LinkMetadataStore               store.LinkMetadataStore
+	MfaRecoveryCodeStore            store.MfaRecoveryCodeStore
 	NotifyAdminStore                store.NotifyAdminStore
 	OAuthStore                      store.OAuthStore
 	OutgoingOAuthConnectionStore    store.OutgoingOAuthConnectionStore
@@ -67,6 +71,7 @@ type TimerLayer struct {
 	UserStore                       store.UserStore
 	UserAccessTokenStore            store.UserAccessTokenStore
 	UserTermsOfServiceStore         store.UserTermsOfServiceStore
+	WebAuthnCredentialStore         store.WebAuthnCredentialStore
 	WebhookStore                    store.WebhookStore
 }
 
@@ -74,6 +79,10 @@ func (s *TimerLayer) Audit() store.AuditStore {
 	return s.AuditStore
 }
 
+func (s *TimerLayer) BatchedEmailNotification() store.BatchedEmailNotificationStore {
+	return s.BatchedEmailNotificationStore
+}
+
 func (s *TimerLayer) Bot() store.BotStore {
 	return s.BotStore
 }
@@ -94,6 +103,10 @@ func (s *TimerLayer) ClusterDiscovery() store.ClusterDiscoveryStore {
 	return s.ClusterDiscoveryStore
 }
 
+func (s *TimerLayer) ClusterPayload() store.ClusterPayloadStore {
+	return s.ClusterPayloadStore
+}
+
 func (s *TimerLayer) Command() store.CommandStore {
 	return s.CommandStore
 }
@@ -130,6 +143,10 @@ func (s *TimerLayer) Job() store.JobStore {
 	return s.JobStore
 }
 
+func (s *TimerLayer) LegalHold() store.LegalHoldStore {
+	return s.LegalHoldStore
+}
+
 func (s *TimerLayer) License() store.LicenseStore {
 	return s.LicenseStore
 }
@@ -138,6 +155,10 @@ func (s *TimerLayer) LinkMetadata() store.LinkMetadataStore {
 	return s.LinkMetadataStore
 }
 
+func (s *TimerLayer) MfaRecoveryCode() store.MfaRecoveryCodeStore {
+	return s.MfaRecoveryCodeStore
+}
+
 func (s *TimerLayer) NotifyAdmin() store.NotifyAdminStore {
 	return s.NotifyAdminStore
 }
@@ -262,6 +283,10 @@ func (s *TimerLayer) UserTermsOfService() store.UserTermsOfServiceStore {
 	return s.UserTermsOfServiceStore
 }
 
+func (s *TimerLayer) WebAuthnCredential() store.WebAuthnCredentialStore {
+	return s.WebAuthnCredentialStore
+}
+
 func (s *TimerLayer) Webhook() store.WebhookStore {
 	return s.WebhookStore
 }
@@ -271,6 +296,11 @@ type TimerLayerAuditStore struct {
 	Root *TimerLayer
 }
 
+type TimerLayerBatchedEmailNotificationStore struct {
+	store.BatchedEmailNotificationStore
+	Root *TimerLayer
+}
+
 type TimerLayerBotStore struct {
 	store.BotStore
 	Root *TimerLayer
@@ -296,6 +326,11 @@ type TimerLayerClusterDiscoveryStore struct {
 	Root *TimerLayer
 }
 
+type TimerLayerClusterPayloadStore struct {
+	store.ClusterPayloadStore
+	Root *TimerLayer
+}
+
 type TimerLayerCommandStore struct {
 	store.CommandStore
 	Root *TimerLayer
@@ -341,6 +376,11 @@ type TimerLayerJobStore struct {
 	Root *TimerLayer
 }
 
+type TimerLayerLegalHoldStore struct {
+	store.LegalHoldStore
+	Root *TimerLayer
+}
+
 type TimerLayerLicenseStore struct {
 	store.LicenseStore
 	Root *TimerLayer
@@ -351,6 +391,11 @@ type TimerLayerLinkMetadataStore struct {
 	Root *TimerLayer
 }
 
+type TimerLayerMfaRecoveryCodeStore struct {
+	store.MfaRecoveryCodeStore
+	Root *TimerLayer
+}
+
 type TimerLayerNotifyAdminStore struct {
 	store.NotifyAdminStore
 	Root *TimerLayer
@@ -506,6 +551,11 @@ type TimerLayerUserTermsOfServiceStore struct {
 	Root *TimerLayer
 }
 
+type TimerLayerWebAuthnCredentialStore struct {
+	store.WebAuthnCredentialStore
+	Root *TimerLayer
+}
+
 type TimerLayerWebhookStore struct {
 	store.WebhookStore
 	Root *TimerLayer
@@ -559,6 +609,70 @@ func (s *TimerLayerAuditStore) Save(audit *model.Audit) error {
 	return err
 }
 
+func (s *TimerLayerBatchedEmailNotificationStore) Count() (int64, error) {
+	start := time.Now()
+
+	result, err := s.BatchedEmailNotificationStore.Count()
+
+	elapsed := float64(time.Since(start)) / float64(time.Second)
+	if s.Root.Metrics != nil {
+		success := "false"
+		if err == nil {
+			success = "true"
+		}
+		s.Root.Metrics.ObserveStoreMethodDuration("BatchedEmailNotificationStore.Count", success, elapsed)
+	}
+	return result, err
+}
+
+func (s *TimerLayerBatchedEmailNotificationStore) Delete(ids []string) error {
+	start := time.Now()
+
+	err := s.BatchedEmailNotificationStore.Delete(ids)
+
+	elapsed := float64(time.Since(start)) / float64(time.Second)
+	if s.Root.Metrics != nil {
+		success := "false"
+		if err == nil {
+			success = "true"
+		}
+		s.Root.Metrics.ObserveStoreMethodDuration("BatchedEmailNotificationStore.Delete", success, elapsed)
+	}
+	return err
+}
+
+func (s *TimerLayerBatchedEmailNotificationStore) GetAll() ([]*model.BatchedEmailNotification, error) {
+	start := time.Now()
+
+	result, err := s.BatchedEmailNotificationStore.GetAll()
+
+	elapsed := float64(time.Since(start)) / float64(time.Second)
+	if s.Root.Metrics != nil {
+		success := "false"
+		if err == nil {
+			success = "true"
+		}
+		s.Root.Metrics.ObserveStoreMethodDuration("BatchedEmailNotificationStore.GetAll", success, elapsed)
+	}
+	return result, err
+}
+
+func (s *TimerLayerBatchedEmailNotificationStore) Save(notification *model.BatchedEmailNotification) error {
+	start := time.Now()
+
+	err := s.BatchedEmailNotificationStore.Save(notification)
+
+	elapsed := float64(time.Since(start)) / float64(time.Second)
+	if s.Root.Metrics != nil {
+		success := "false"
+		if err == nil {
+			success = "true"
+		}
+		s.Root.Metrics.ObserveStoreMethodDuration("BatchedEmailNotificationStore.Save", success, elapsed)
+	}
+	return err
+}
+
 func (s *TimerLayerBotStore) Get(userID string, includeDeleted bool) (*model.Bot, error) {
 	start := time.Now()
 
@@ -2918,6 +3032,70 @@ func (s *TimerLayerClusterDiscoveryStore) SetLastPingAt(discovery *model.Cluster
 	return err
 }
 
+func (s *TimerLayerClusterPayloadStore) DeleteOlderThan(createAt int64) (int64, error) {
+	start := time.Now()
+
+	result, err := s.ClusterPayloadStore.DeleteOlderThan(createAt)
+
+	elapsed := float64(time.Since(start)) / float64(time.Second)
+	if s.Root.Metrics != nil {
+		success := "false"
+		if err == nil {
+			success = "true"
+		}
+		s.Root.Metrics.ObserveStoreMethodDuration("ClusterPayloadStore.DeleteOlderThan", success, elapsed)
+	}
+	return result, err
+}
+
+func (s *TimerLayerClusterPayloadStore) Get(id string) (*model.ClusterPayload, error) {
+	start := time.Now()
+
+	result, err := s.ClusterPayloadStore.Get(id)
+
+	elapsed := float64(time.Since(start)) / float64(time.Second)
+	if s.Root.Metrics != nil {
+		success := "false"
+		if err == nil {
+			success = "true"
+		}
+		s.Root.Metrics.ObserveStoreMethodDuration("ClusterPayloadStore.Get", success, elapsed)
+	}
+	return result, err
+}
+
+func (s *TimerLayerClusterPayloadStore) Notify(channel string, message string) error {
+	start := time.Now()
+
+	err := s.ClusterPayloadStore.Notify(channel, message)
+
+	elapsed := float64(time.Since(start)) / float64(time.Second)
+	if s.Root.Metrics != nil {
+		success := "false"
+		if err == nil {
+			success = "true"
+		}
+		s.Root.Metrics.ObserveStoreMethodDuration("ClusterPayloadStore.Notify", success, elapsed)
+	}
+	return err
+}
+
+func (s *TimerLayerClusterPayloadStore) Save(payload *model.ClusterPayload) error {
+	start := time.Now()
+
+	err := s.ClusterPayloadStore.Save(payload)
+
+	elapsed := float64(time.Since(start)) / float64(time.Second)
+	if s.Root.Metrics != nil {
+		success := "false"
+		if err == nil {
+			success = "true"
+		}
+		s.Root.Metrics.ObserveStoreMethodDuration("ClusterPayloadStore.Save", success, elapsed)
+	}
+	return err
+}
+
 func (s *TimerLayerCommandStore) AnalyticsCommandCount(teamID string) (int64, error) {
 	start := time.Now()
 
@@ -3588,6 +3766,70 @@ func (s *TimerLayerFileInfoStore) CountAll() (int64, error) {
 	return result, err
 }
 
+func (s *TimerLayerFileInfoStore) CountByContentHash(hash string) (int64, error) {
+	start := time.Now()
+
+	result, err := s.FileInfoStore.CountByContentHash(hash)
+
+	elapsed := float64(time.Since(start)) / float64(time.Second)
+	if s.Root.Metrics != nil {
+		success := "false"
+		if err == nil {
+			success = "true"
+		}
+		s.Root.Metrics.ObserveStoreMethodDuration("FileInfoStore.CountByContentHash", success, elapsed)
+	}
+	return result, err
+}
+
+func (s *TimerLayerFileInfoStore) CountByPath(path string) (int64, error) {
+	start := time.Now()
+
+	result, err := s.FileInfoStore.CountByPath(path)
+
+	elapsed := float64(time.Since(start)) / float64(time.Second)
+	if s.Root.Metrics != nil {
+		success := "false"
+		if err == nil {
+			success = "true"
+		}
+		s.Root.Metrics.ObserveStoreMethodDuration("FileInfoStore.CountByPath", success, elapsed)
+	}
+	return result, err
+}
+
+func (s *TimerLayerFileInfoStore) CountForRetention(endTime int64) (int64, error) {
+	start := time.Now()
+
+	result, err := s.FileInfoStore.CountForRetention(endTime)
+
+	elapsed := float64(time.Since(start)) / float64(time.Second)
+	if s.Root.Metrics != nil {
+		success := "false"
+		if err == nil {
+			success = "true"
+		}
+		s.Root.Metrics.ObserveStoreMethodDuration("FileInfoStore.CountForRetention", success, elapsed)
+	}
+	return result, err
+}
+
+func (s *TimerLayerFileInfoStore) CountForRetentionPolicies(now int64, globalPolicyEndTime int64) (int64, error) {
+	start := time.Now()
+
+	result, err := s.FileInfoStore.CountForRetentionPolicies(now, globalPolicyEndTime)
+
+	elapsed := float64(time.Since(start)) / float64(time.Second)
+	if s.Root.Metrics != nil {
+		success := "false"
+		if err == nil {
+			success = "true"
+		}
+		s.Root.Metrics.ObserveStoreMethodDuration("FileInfoStore.CountForRetentionPolicies", success, elapsed)
+	}
+	return result, err
+}
+
 func (s *TimerLayerFileInfoStore) DeleteForPost(c request.CTX, postID string) (string, error) {
 	start := time.Now()
 
@@ -3636,6 +3878,22 @@ func (s *TimerLayerFileInfoStore) Get(id string) (*model.FileInfo, error) {
 	return result, err
 }
 
+func (s *TimerLayerFileInfoStore) GetBatchForRetention(endTime int64, limit int) ([]*model.FileInfo, error) {
+	start := time.Now()
+
+	result, err := s.FileInfoStore.GetBatchForRetention(endTime, limit)
+
+	elapsed := float64(time.Since(start)) / float64(time.Second)
+	if s.Root.Metrics != nil {
+		success := "false"
+		if err == nil {
+			success = "true"
+		}
+		s.Root.Metrics.ObserveStoreMethodDuration("FileInfoStore.GetBatchForRetention", success, elapsed)
+	}
+	return result, err
+}
+
 func (s *TimerLayerFileInfoStore) GetByIds(ids []string, includeDeleted bool, allowFromCache bool) ([]*model.FileInfo, error) {
 	start := time.Now()
 
@@ -3668,6 +3926,38 @@ func (s *TimerLayerFileInfoStore) GetByPath(path string) (*model.FileInfo, error
 	return result, err
 }
 
+func (s *TimerLayerFileInfoStore) GetByPostIds(postIDs []string) ([]*model.FileInfo, error) {
+	start := time.Now()
+
+	result, err := s.FileInfoStore.GetByPostIds(postIDs)
+
+	elapsed := float64(time.Since(start)) / float64(time.Second)
+	if s.Root.Metrics != nil {
+		success := "false"
+		if err == nil {
+			success = "true"
+		}
+		s.Root.Metrics.ObserveStoreMethodDuration("FileInfoStore.GetByPostIds", success, elapsed)
+	}
+	return result, err
+}
+
+func (s *TimerLayerFileInfoStore) GetFilesBatchForDeduplication(afterID string, limit int) ([]*model.FileInfo, error) {
+	start := time.Now()
+
+	result, err := s.FileInfoStore.GetFilesBatchForDeduplication(afterID, limit)
+
+	elapsed := float64(time.Since(start)) / float64(time.Second)
+	if s.Root.Metrics != nil {
+		success := "false"
+		if err == nil {
+			success = "true"
+		}
+		s.Root.Metrics.ObserveStoreMethodDuration("FileInfoStore.GetFilesBatchForDeduplication", success, elapsed)
+	}
+	return result, err
+}
+
 func (s *TimerLayerFileInfoStore) GetFilesBatchForIndexing(startTime int64, startFileID string, includeDeleted bool, limit int) ([]*model.FileForIndexing, error) {
 	start := time.Now()
 
@@ -3827,6 +4117,22 @@ func (s *TimerLayerFileInfoStore) PermanentDeleteBatch(ctx request.CTX, endTime
 	return result, err
 }
 
+func (s *TimerLayerFileInfoStore) PermanentDeleteByIds(rctx request.CTX, fileIDs []string) (int64, error) {
+	start := time.Now()
+
+	result, err := s.FileInfoStore.PermanentDeleteByIds(rctx, fileIDs)
+
+	elapsed := float64(time.Since(start)) / float64(time.Second)
+	if s.Root.Metrics != nil {
+		success := "false"
+		if err == nil {
+			success = "true"
+		}
+		s.Root.Metrics.ObserveStoreMethodDuration("FileInfoStore.PermanentDeleteByIds", success, elapsed)
+	}
+	return result, err
+}
+
 func (s *TimerLayerFileInfoStore) PermanentDeleteByUser(ctx request.CTX, userID string) (int64, error) {
 	start := time.Now()
 
@@ -3859,6 +4165,22 @@ func (s *TimerLayerFileInfoStore) PermanentDeleteForPost(rctx request.CTX, postI
 	return err
 }
 
+func (s *TimerLayerFileInfoStore) Quarantine(rctx request.CTX, fileID string, reason string) error {
+	start := time.Now()
+
+	err := s.FileInfoStore.Quarantine(rctx, fileID, reason)
+
+	elapsed := float64(time.Since(start)) / float64(time.Second)
+	if s.Root.Metrics != nil {
+		success := "false"
+		if err == nil {
+			success = "true"
+		}
+		s.Root.Metrics.ObserveStoreMethodDuration("FileInfoStore.Quarantine", success, elapsed)
+	}
+	return err
+}
+
 func (s *TimerLayerFileInfoStore) RestoreForPostByIds(rctx request.CTX, postId string, fileIDs []string) error {
 	start := time.Now()
 
@@ -3923,6 +4245,22 @@ func (s *TimerLayerFileInfoStore) SetContent(ctx request.CTX, fileID string, con
 	return err
 }
 
+func (s *TimerLayerFileInfoStore) UpdateContentHash(rctx request.CTX, fileIDs []string, newPath string, hash string) error {
+	start := time.Now()
+
+	err := s.FileInfoStore.UpdateContentHash(rctx, fileIDs, newPath, hash)
+
+	elapsed := float64(time.Since(start)) / float64(time.Second)
+	if s.Root.Metrics != nil {
+		success := "false"
+		if err == nil {
+			success = "true"
+		}
+		s.Root.Metrics.ObserveStoreMethodDuration("FileInfoStore.UpdateContentHash", success, elapsed)
+	}
+	return err
+}
+
 func (s *TimerLayerFileInfoStore) Upsert(rctx request.CTX, info *model.FileInfo) (*model.FileInfo, error) {
 	start := time.Now()
 
@@ -5075,6 +5413,86 @@ func (s *TimerLayerJobStore) UpdateStatusOptimistically(id string, currentStatus
 	return result, err
 }
 
+func (s *TimerLayerLegalHoldStore) Get(id string) (*model.LegalHold, error) {
+	start := time.Now()
+
+	result, err := s.LegalHoldStore.Get(id)
+
+	elapsed := float64(time.Since(start)) / float64(time.Second)
+	if s.Root.Metrics != nil {
+		success := "false"
+		if err == nil {
+			success = "true"
+		}
+		s.Root.Metrics.ObserveStoreMethodDuration("LegalHoldStore.Get", success, elapsed)
+	}
+	return result, err
+}
+
+func (s *TimerLayerLegalHoldStore) GetActive() ([]*model.LegalHold, error) {
+	start := time.Now()
+
+	result, err := s.LegalHoldStore.GetActive()
+
+	elapsed := float64(time.Since(start)) / float64(time.Second)
+	if s.Root.Metrics != nil {
+		success := "false"
+		if err == nil {
+			success = "true"
+		}
+		s.Root.Metrics.ObserveStoreMethodDuration("LegalHoldStore.GetActive", success, elapsed)
+	}
+	return result, err
+}
+
+func (s *TimerLayerLegalHoldStore) GetAll(offset int, limit int, includeReleased bool) ([]*model.LegalHold, error) {
+	start := time.Now()
+
+	result, err := s.LegalHoldStore.GetAll(offset, limit, includeReleased)
+
+	elapsed := float64(time.Since(start)) / float64(time.Second)
+	if s.Root.Metrics != nil {
+		success := "false"
+		if err == nil {
+			success = "true"
+		}
+		s.Root.Metrics.ObserveStoreMethodDuration("LegalHoldStore.GetAll", success, elapsed)
+	}
+	return result, err
+}
+
+func (s *TimerLayerLegalHoldStore) Release(id string, releasedAt int64) error {
+	start := time.Now()
+
+	err := s.LegalHoldStore.Release(id, releasedAt)
+
+	elapsed := float64(time.Since(start)) / float64(time.Second)
+	if s.Root.Metrics != nil {
+		success := "false"
+		if err == nil {
+			success = "true"
+		}
+		s.Root.Metrics.ObserveStoreMethodDuration("LegalHoldStore.Release", success, elapsed)
+	}
+	return err
+}
+
+func (s *TimerLayerLegalHoldStore) Save(hold *model.LegalHold) (*model.LegalHold, error) {
+	start := time.Now()
+
+	result, err := s.LegalHoldStore.Save(hold)
+
+	elapsed := float64(time.Since(start)) / float64(time.Second)
+	if s.Root.Metrics != nil {
+		success := "false"
+		if err == nil {
+			success = "true"
+		}
+		s.Root.Metrics.ObserveStoreMethodDuration("LegalHoldStore.Save", success, elapsed)
+	}
+	return result, err
+}
+
 func (s *TimerLayerLicenseStore) Get(c request.CTX, id string) (*model.LicenseRecord, error) {
 	start := time.Now()
 
@@ -5155,6 +5573,70 @@ func (s *TimerLayerLinkMetadataStore) Save(linkMetadata *model.LinkMetadata) (*m
 	return result, err
 }
 
+func (s *TimerLayerMfaRecoveryCodeStore) Consume(userID string, codeHash string) (bool, error) {
+	start := time.Now()
+
+	result, err := s.MfaRecoveryCodeStore.Consume(userID, codeHash)
+
+	elapsed := float64(time.Since(start)) / float64(time.Second)
+	if s.Root.Metrics != nil {
+		success := "false"
+		if err == nil {
+			success = "true"
+		}
+		s.Root.Metrics.ObserveStoreMethodDuration("MfaRecoveryCodeStore.Consume", success, elapsed)
+	}
+	return result, err
+}
+
+func (s *TimerLayerMfaRecoveryCodeStore) DeleteAllForUser(userID string) error {
+	start := time.Now()
+
+	err := s.MfaRecoveryCodeStore.DeleteAllForUser(userID)
+
+	elapsed := float64(time.Since(start)) / float64(time.Second)
+	if s.Root.Metrics != nil {
+		success := "false"
+		if err == nil {
+			success = "true"
+		}
+		s.Root.Metrics.ObserveStoreMethodDuration("MfaRecoveryCodeStore.DeleteAllForUser", success, elapsed)
+	}
+	return err
+}
+
+func (s *TimerLayerMfaRecoveryCodeStore) GetForUser(userID string) ([]string, error) {
+	start := time.Now()
+
+	result, err := s.MfaRecoveryCodeStore.GetForUser(userID)
+
+	elapsed := float64(time.Since(start)) / float64(time.Second)
+	if s.Root.Metrics != nil {
+		success := "false"
+		if err == nil {
+			success = "true"
+		}
+		s.Root.Metrics.ObserveStoreMethodDuration("MfaRecoveryCodeStore.GetForUser", success, elapsed)
+	}
+	return result, err
+}
+
+func (s *TimerLayerMfaRecoveryCodeStore) ReplaceForUser(userID string, codeHashes []string) error {
+	start := time.Now()
+
+	err := s.MfaRecoveryCodeStore.ReplaceForUser(userID, codeHashes)
+
+	elapsed := float64(time.Since(start)) / float64(time.Second)
+	if s.Root.Metrics != nil {
+		success := "false"
+		if err == nil {
+			success = "true"
+		}
+		s.Root.Metrics.ObserveStoreMethodDuration("MfaRecoveryCodeStore.ReplaceForUser", success, elapsed)
+	}
+	return err
+}
+
 func (s *TimerLayerNotifyAdminStore) DeleteBefore(trial bool, now int64) error {
 	start := time.Now()
 
@@ -5858,6 +6340,22 @@ func (s *TimerLayerPostStore) ClearCaches() {
 	}
 }
 
+func (s *TimerLayerPostStore) CountForRetentionPolicies(now int64, globalPolicyEndTime int64) (int64, error) {
+	start := time.Now()
+
+	result, err := s.PostStore.CountForRetentionPolicies(now, globalPolicyEndTime)
+
+	elapsed := float64(time.Since(start)) / float64(time.Second)
+	if s.Root.Metrics != nil {
+		success := "false"
+		if err == nil {
+			success = "true"
+		}
+		s.Root.Metrics.ObserveStoreMethodDuration("PostStore.CountForRetentionPolicies", success, elapsed)
+	}
+	return result, err
+}
+
 func (s *TimerLayerPostStore) Delete(rctx request.CTX, postID string, timestamp int64, deleteByID string) error {
 	start := time.Now()
 
@@ -12282,6 +12780,102 @@ func (s *TimerLayerUserTermsOfServiceStore) Save(userTermsOfService *model.UserT
 	return result, err
 }
 
+func (s *TimerLayerWebAuthnCredentialStore) Delete(id string) error {
+	start := time.Now()
+
+	err := s.WebAuthnCredentialStore.Delete(id)
+
+	elapsed := float64(time.Since(start)) / float64(time.Second)
+	if s.Root.Metrics != nil {
+		success := "false"
+		if err == nil {
+			success = "true"
+		}
+		s.Root.Metrics.ObserveStoreMethodDuration("WebAuthnCredentialStore.Delete", success, elapsed)
+	}
+	return err
+}
+
+func (s *TimerLayerWebAuthnCredentialStore) DeleteAllForUser(userID string) error {
+	start := time.Now()
+
+	err := s.WebAuthnCredentialStore.DeleteAllForUser(userID)
+
+	elapsed := float64(time.Since(start)) / float64(time.Second)
+	if s.Root.Metrics != nil {
+		success := "false"
+		if err == nil {
+			success = "true"
+		}
+		s.Root.Metrics.ObserveStoreMethodDuration("WebAuthnCredentialStore.DeleteAllForUser", success, elapsed)
+	}
+	return err
+}
+
+func (s *TimerLayerWebAuthnCredentialStore) Get(id string) (*model.WebAuthnCredential, error) {
+	start := time.Now()
+
+	result, err := s.WebAuthnCredentialStore.Get(id)
+
+	elapsed := float64(time.Since(start)) / float64(time.Second)
+	if s.Root.Metrics != nil {
+		success := "false"
+		if err == nil {
+			success = "true"
+		}
+		s.Root.Metrics.ObserveStoreMethodDuration("WebAuthnCredentialStore.Get", success, elapsed)
+	}
+	return result, err
+}
+
+func (s *TimerLayerWebAuthnCredentialStore) GetForUser(userID string) ([]*model.WebAuthnCredential, error) {
+	start := time.Now()
+
+	result, err := s.WebAuthnCredentialStore.GetForUser(userID)
+
+	elapsed := float64(time.Since(start)) / float64(time.Second)
+	if s.Root.Metrics != nil {
+		success := "false"
+		if err == nil {
+			success = "true"
+		}
+		s.Root.Metrics.ObserveStoreMethodDuration("WebAuthnCredentialStore.GetForUser", success, elapsed)
+	}
+	return result, err
+}
+
+func (s *TimerLayerWebAuthnCredentialStore) Save(credential *model.WebAuthnCredential) (*model.WebAuthnCredential, error) {
+	start := time.Now()
+
+	result, err := s.WebAuthnCredentialStore.Save(credential)
+
+	elapsed := float64(time.Since(start)) / float64(time.Second)
+	if s.Root.Metrics != nil {
+		success := "false"
+		if err == nil {
+			success = "true"
+		}
+		s.Root.Metrics.ObserveStoreMethodDuration("WebAuthnCredentialStore.Save", success, elapsed)
+	}
+	return result, err
+}
+
+func (s *TimerLayerWebAuthnCredentialStore) UpdateSignCount(id string, signCount int64, lastUsedAt int64) error {
+	start := time.Now()
+
+	err := s.WebAuthnCredentialStore.UpdateSignCount(id, signCount, lastUsedAt)
+
+	elapsed := float64(time.Since(start)) / float64(time.Second)
+	if s.Root.Metrics != nil {
+		success := "false"
+		if err == nil {
+			success = "true"
+		}
+		s.Root.Metrics.ObserveStoreMethodDuration("WebAuthnCredentialStore.UpdateSignCount", success, elapsed)
+	}
+	return err
+}
+
 func (s *TimerLayerWebhookStore) AnalyticsIncomingCount(teamID string, userID string) (int64, error) {
 	start := time.Now()
 
@@ -12755,11 +13349,13 @@ func New(childStore store.Store, metrics einterfaces.MetricsInterface) *TimerLay
 	}
 
 	newStore.AuditStore = &TimerLayerAuditStore{AuditStore: childStore.Audit(), Root: &newStore}
+	newStore.BatchedEmailNotificationStore = &TimerLayerBatchedEmailNotificationStore{BatchedEmailNotificationStore: childStore.BatchedEmailNotification(), Root: &newStore}
 	newStore.BotStore = &TimerLayerBotStore{BotStore: childStore.Bot(), Root: &newStore}
 	newStore.ChannelStore = &TimerLayerChannelStore{ChannelStore: childStore.Channel(), Root: &newStore}
 	newStore.ChannelBookmarkStore = &TimerLayerChannelBookmarkStore{ChannelBookmarkStore: childStore.ChannelBookmark(), Root: &newStore}
 	newStore.ChannelMemberHistoryStore = &TimerLayerChannelMemberHistoryStore{ChannelMemberHistoryStore: childStore.ChannelMemberHistory(), Root: &newStore}
 	newStore.ClusterDiscoveryStore = &TimerLayerClusterDiscoveryStore{ClusterDiscoveryStore: childStore.ClusterDiscovery(), Root: &newStore}
+	newStore.ClusterPayloadStore = &TimerLayerClusterPayloadStore{ClusterPayloadStore: childStore.ClusterPayload(), Root: &newStore}
 	newStore.CommandStore = &TimerLayerCommandStore{CommandStore: childStore.Command(), Root: &newStore}
 	newStore.CommandWebhookStore = &TimerLayerCommandWebhookStore{CommandWebhookStore: childStore.CommandWebhook(), Root: &newStore}
 	newStore.ComplianceStore = &TimerLayerComplianceStore{ComplianceStore: childStore.Compliance(), Root: &newStore}
@@ -12769,8 +13365,10 @@ func New(childStore store.Store, metrics einterfaces.MetricsInterface) *TimerLay
 	newStore.FileInfoStore = &TimerLayerFileInfoStore{FileInfoStore: childStore.FileInfo(), Root: &newStore}
 	newStore.GroupStore = &TimerLayerGroupStore{GroupStore: childStore.Group(), Root: &newStore}
 	newStore.JobStore = &TimerLayerJobStore{JobStore: childStore.Job(), Root: &newStore}
+	newStore.LegalHoldStore = &TimerLayerLegalHoldStore{LegalHoldStore: childStore.LegalHold(), Root: &newStore}
 	newStore.LicenseStore = &TimerLayerLicenseStore{LicenseStore: childStore.License(), Root: &newStore}
 	newStore.LinkMetadataStore = &TimerLayerLinkMetadataStore{LinkMetadataStore: childStore.LinkMetadata(), Root: &newStore}
+	newStore.MfaRecoveryCodeStore = &TimerLayerMfaRecoveryCodeStore{MfaRecoveryCodeStore: childStore.MfaRecoveryCode(), Root: &newStore}
 	newStore.NotifyAdminStore = &TimerLayerNotifyAdminStore{NotifyAdminStore: childStore.NotifyAdmin(), Root: &newStore}
 	newStore.OAuthStore = &TimerLayerOAuthStore{OAuthStore: childStore.OAuth(), Root: &newStore}
 	newStore.OutgoingOAuthConnectionStore = &TimerLayerOutgoingOAuthConnectionStore{OutgoingOAuthConnectionStore: childStore.OutgoingOAuthConnection(), Root: &newStore}
@@ -12802,6 +13400,7 @@ func New(childStore store.Store, metrics einterfaces.MetricsInterface) *TimerLay
 	newStore.UserStore = &TimerLayerUserStore{UserStore: childStore.User(), Root: &newStore}
 	newStore.UserAccessTokenStore = &TimerLayerUserAccessTokenStore{UserAccessTokenStore: childStore.UserAccessToken(), Root: &newStore}
 	newStore.UserTermsOfServiceStore = &TimerLayerUserTermsOfServiceStore{UserTermsOfServiceStore: childStore.UserTermsOfService(), Root: &newStore}
+	newStore.WebAuthnCredentialStore = &TimerLayerWebAuthnCredentialStore{WebAuthnCredentialStore: childStore.WebAuthnCredential(), Root: &newStore}
 	newStore.WebhookStore = &TimerLayerWebhookStore{WebhookStore: childStore.Webhook(), Root: &newStore}
 	return &newStore
 }
@@ -451,6 +451,17 @@ func (c *Context) RequirePolicyId() *Context {
 	return c
 }
 
+func (c *Context) RequireLegalHoldId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if !model.IsValidId(c.Params.LegalHoldId) {
+		c.SetInvalidURLParam("legal_hold_id")
+	}
+	return c
+}
+
 func (c *Context) RequireAppId() *Context {
 	if c.Err != nil {
 		return c
@@ -696,6 +707,17 @@ func (c *Context) RequireFieldId() *Context {
 	return c
 }
 
+func (c *Context) RequireCredentialId() *Context {
+	if c.Err != nil {
+		return c
+	}
+
+	if !model.IsValidId(c.Params.CredentialId) {
+		c.SetInvalidURLParam("credential_id")
+	}
+	return c
+}
+
 func (c *Context) RequireSchemeId() *Context {
 	if c.Err != nil {
 		return c
@@ -319,6 +319,14 @@ func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	)
 	c.AppContext = c.AppContext.WithLogger(c.Logger)
 
+	// Rate limit by the policy of the route, now that the session is known
+	if c.Err == nil && c.App.Srv().RateLimiter != nil {
+		rateLimitExceeded = c.App.Srv().RateLimiter.RouteRateLimit(r, c.AppContext.Session(), w)
+		if rateLimitExceeded {
+			return
+		}
+	}
+
 	if c.Err == nil && h.RequireSession {
 		c.SessionRequired()
 	}
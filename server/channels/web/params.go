@@ -34,6 +34,7 @@ type Params struct {
 	ChannelId                 string
 	PostId                    string
 	PolicyId                  string
+	LegalHoldId               string
 	FileId                    string
 	Filename                  string
 	UploadId                  string
@@ -114,6 +115,8 @@ type Params struct {
 
 	// Custom Profile Attributes
 	FieldId string
+	// WebAuthn
+	CredentialId string
 }
 
 func ParamsFromRequest(r *http.Request) *Params {
@@ -137,6 +140,7 @@ func ParamsFromRequest(r *http.Request) *Params {
 
 	params.PostId = props["post_id"]
 	params.PolicyId = props["policy_id"]
+	params.LegalHoldId = props["legal_hold_id"]
 	params.FileId = props["file_id"]
 	params.Filename = query.Get("filename")
 	params.UploadId = props["upload_id"]
@@ -182,6 +186,7 @@ func ParamsFromRequest(r *http.Request) *Params {
 	params.ExcludeRemote, _ = strconv.ParseBool(query.Get("exclude_remote"))
 	params.ChannelBookmarkId = props["bookmark_id"]
 	params.FieldId = props["field_id"]
+	params.CredentialId = props["credential_id"]
 	params.Scope = query.Get("scope")
 
 	if val, err := strconv.Atoi(query.Get("page")); err != nil || val < 0 {
@@ -329,6 +329,9 @@ func ConfigToFileBackendSettings(s *model.FileSettings, enableComplianceFeature
 			Directory:  *s.Directory,
 		}
 	}
+	if *s.DriverName == model.ImageDriverGCS || *s.DriverName == model.ImageDriverAzure {
+		return filestore.NewFileBackendSettingsFromConfig(s, enableComplianceFeature, skipVerify)
+	}
 	return filestore.FileBackendSettings{
 		DriverName:                         *s.DriverName,
 		AmazonS3AccessKeyId:                *s.AmazonS3AccessKeyId,
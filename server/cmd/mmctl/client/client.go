@@ -162,4 +162,9 @@ type Client interface {
 	DeletePreferences(ctx context.Context, userId string, preferences model.Preferences) (*model.Response, error)
 	PermanentDeletePost(ctx context.Context, postID string) (*model.Response, error)
 	DeletePost(ctx context.Context, postId string) (*model.Response, error)
+	CreateLegalHold(ctx context.Context, hold *model.LegalHold) (*model.LegalHold, *model.Response, error)
+	GetLegalHold(ctx context.Context, legalHoldID string) (*model.LegalHold, *model.Response, error)
+	GetLegalHolds(ctx context.Context, page, perPage int, includeReleased bool) ([]*model.LegalHold, *model.Response, error)
+	ReleaseLegalHold(ctx context.Context, legalHoldID string) (*model.LegalHold, *model.Response, error)
+	ExportLegalHold(ctx context.Context, legalHoldID string) (*model.Job, *model.Response, error)
 }
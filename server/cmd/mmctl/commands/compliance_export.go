@@ -0,0 +1,150 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+
+	"github.com/mattermost/mattermost/server/v8/cmd/mmctl/client"
+	"github.com/mattermost/mattermost/server/v8/cmd/mmctl/printer"
+
+	"github.com/spf13/cobra"
+)
+
+var ComplianceExportCmd = &cobra.Command{
+	Use:   "compliance-export",
+	Short: "Management of compliance exports",
+}
+
+var ComplianceExportListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List compliance export jobs",
+	Long:    "List the compliance export jobs, along with the delivery of their batches to the archive if one is configured.",
+	Example: `  compliance-export list
+  compliance-export list --status error`,
+	Args: cobra.NoArgs,
+	RunE: withClient(complianceExportListCmdF),
+}
+
+var ComplianceExportShowCmd = &cobra.Command{
+	Use:     "show [complianceExportJobID]",
+	Short:   "Show compliance export job",
+	Example: "  compliance-export show o98rj3ur83dp5dppfyk5yk6osy",
+	Args:    cobra.ExactArgs(1),
+	RunE:    withClient(complianceExportShowCmdF),
+}
+
+func init() {
+	ComplianceExportListCmd.Flags().Int("page", 0, "Page number to fetch for the list of compliance export jobs")
+	ComplianceExportListCmd.Flags().Int("per-page", DefaultPageSize, "Number of compliance export jobs to be fetched")
+	ComplianceExportListCmd.Flags().Bool("all", false, "Fetch all compliance export jobs. --page flag will be ignored if provided")
+	ComplianceExportListCmd.Flags().String("status", "", "Filter by job status")
+
+	ComplianceExportCmd.AddCommand(
+		ComplianceExportListCmd,
+		ComplianceExportShowCmd,
+	)
+	RootCmd.AddCommand(ComplianceExportCmd)
+}
+
+func complianceExportListCmdF(c client.Client, command *cobra.Command, args []string) error {
+	page, err := command.Flags().GetInt("page")
+	if err != nil {
+		return err
+	}
+	perPage, err := command.Flags().GetInt("per-page")
+	if err != nil {
+		return err
+	}
+	showAll, err := command.Flags().GetBool("all")
+	if err != nil {
+		return err
+	}
+	status, err := command.Flags().GetString("status")
+	if err != nil {
+		return err
+	}
+
+	if status != "" && !model.IsValidJobStatus(status) {
+		return fmt.Errorf("invalid job status: %s", status)
+	}
+
+	if showAll {
+		page = 0
+	}
+
+	for {
+		jobs, _, err := c.GetJobs(context.TODO(), model.JobTypeMessageExport, status, page, perPage)
+		if err != nil {
+			return fmt.Errorf("failed to get compliance export jobs: %w", err)
+		}
+
+		if len(jobs) == 0 {
+			if !showAll || page == 0 {
+				printer.Print("No compliance export jobs found")
+			}
+			return nil
+		}
+
+		for _, job := range jobs {
+			printComplianceExportJob(job)
+		}
+
+		if !showAll {
+			break
+		}
+
+		page++
+	}
+
+	return nil
+}
+
+func complianceExportShowCmdF(c client.Client, command *cobra.Command, args []string) error {
+	job, _, err := c.GetJob(context.TODO(), args[0])
+	if err != nil {
+		return fmt.Errorf("failed to get compliance export job: %w", err)
+	}
+
+	if job.Type != model.JobTypeMessageExport {
+		return fmt.Errorf("job %s is not a compliance export job", job.Id)
+	}
+
+	printComplianceExportJob(job)
+
+	return nil
+}
+
+func printComplianceExportJob(job *model.Job) {
+	template := fmt.Sprintf(`  ID: {{.Id}}
+  Status: {{.Status}}
+  Created: %s
+`, time.Unix(job.CreateAt/1000, 0))
+	if job.StartAt > 0 {
+		template += fmt.Sprintf("  Started: %s\n", time.Unix(job.StartAt/1000, 0))
+	}
+	template += `  Export type: {{index .Data "export_type"}}
+  Messages exported: {{index .Data "messages_exported"}}
+`
+	if job.Status == model.JobStatusError {
+		template += "  Error: {{index .Data \"error\"}}\n"
+	}
+
+	if job.Data[model.MessageExportJobDataDeliveryTarget] != "" {
+		template += fmt.Sprintf(`  Delivery target: {{index .Data %q}}
+  Delivery status: {{index .Data %q}}
+  Batches delivered: {{index .Data %q}}
+`, model.MessageExportJobDataDeliveryTarget, model.MessageExportJobDataDeliveryStatus, model.MessageExportJobDataBatchesDelivered)
+		if job.Data[model.MessageExportJobDataDeliveryError] != "" {
+			template += fmt.Sprintf("  Delivery error: {{index .Data %q}}\n", model.MessageExportJobDataDeliveryError)
+		}
+	}
+
+	printer.PrintT(template, job)
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mattermost/mattermost/server/public/model"
+
+	"github.com/mattermost/mattermost/server/v8/cmd/mmctl/printer"
+
+	"github.com/spf13/cobra"
+)
+
+func (s *MmctlUnitTestSuite) TestComplianceExportListCmdF() {
+	newCmd := func(status string) *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().Int("page", 0, "")
+		cmd.Flags().Int("per-page", 2, "")
+		cmd.Flags().Bool("all", false, "")
+		cmd.Flags().String("status", status, "")
+		return cmd
+	}
+
+	s.Run("no jobs found", func() {
+		printer.Clean()
+
+		s.client.
+			EXPECT().
+			GetJobs(context.TODO(), model.JobTypeMessageExport, "", 0, 2).
+			Return([]*model.Job{}, &model.Response{}, nil).
+			Times(1)
+
+		err := complianceExportListCmdF(s.client, newCmd(""), nil)
+		s.Require().NoError(err)
+		s.Require().Len(printer.GetLines(), 1)
+		s.Equal("No compliance export jobs found", printer.GetLines()[0])
+	})
+
+	s.Run("jobs with a failed delivery", func() {
+		printer.Clean()
+		mockJobs := []*model.Job{
+			{
+				Id:     model.NewId(),
+				Type:   model.JobTypeMessageExport,
+				Status: model.JobStatusError,
+				Data: map[string]string{
+					model.MessageExportJobDataDeliveryTarget: model.MessageExportDeliveryTargetSFTP,
+					model.MessageExportJobDataDeliveryStatus: model.MessageExportDeliveryStatusFailed,
+					model.MessageExportJobDataDeliveryError:  "connection refused",
+				},
+			},
+			{
+				Id:     model.NewId(),
+				Type:   model.JobTypeMessageExport,
+				Status: model.JobStatusError,
+			},
+		}
+
+		s.client.
+			EXPECT().
+			GetJobs(context.TODO(), model.JobTypeMessageExport, model.JobStatusError, 0, 2).
+			Return(mockJobs, &model.Response{}, nil).
+			Times(1)
+
+		err := complianceExportListCmdF(s.client, newCmd(model.JobStatusError), nil)
+		s.Require().NoError(err)
+		s.Require().Len(printer.GetLines(), 2)
+		s.Empty(printer.GetErrorLines())
+		for i, line := range printer.GetLines() {
+			s.Equal(mockJobs[i], line.(*model.Job))
+		}
+	})
+
+	s.Run("invalid status", func() {
+		printer.Clean()
+
+		err := complianceExportListCmdF(s.client, newCmd("delivered"), nil)
+		s.Require().EqualError(err, "invalid job status: delivered")
+	})
+
+	s.Run("failed to get the jobs", func() {
+		printer.Clean()
+
+		s.client.
+			EXPECT().
+			GetJobs(context.TODO(), model.JobTypeMessageExport, "", 0, 2).
+			Return(nil, &model.Response{}, errors.New("mock error")).
+			Times(1)
+
+		err := complianceExportListCmdF(s.client, newCmd(""), nil)
+		s.Require().EqualError(err, "failed to get compliance export jobs: mock error")
+	})
+}
+
+func (s *MmctlUnitTestSuite) TestComplianceExportShowCmdF() {
+	s.Run("show a compliance export job", func() {
+		printer.Clean()
+		mockJob := &model.Job{
+			Id:   model.NewId(),
+			Type: model.JobTypeMessageExport,
+			Data: map[string]string{
+				model.MessageExportJobDataDeliveryTarget:   model.MessageExportDeliveryTargetS3,
+				model.MessageExportJobDataDeliveryStatus:   model.MessageExportDeliveryStatusDelivered,
+				model.MessageExportJobDataBatchesDelivered: "3",
+			},
+		}
+
+		s.client.
+			EXPECT().
+			GetJob(context.TODO(), mockJob.Id).
+			Return(mockJob, &model.Response{}, nil).
+			Times(1)
+
+		err := complianceExportShowCmdF(s.client, &cobra.Command{}, []string{mockJob.Id})
+		s.Require().NoError(err)
+		s.Require().Len(printer.GetLines(), 1)
+		s.Equal(mockJob, printer.GetLines()[0])
+	})
+
+	s.Run("not a compliance export job", func() {
+		printer.Clean()
+		mockJob := &model.Job{
+			Id:   model.NewId(),
+			Type: model.JobTypeExportProcess,
+		}
+
+		s.client.
+			EXPECT().
+			GetJob(context.TODO(), mockJob.Id).
+			Return(mockJob, &model.Response{}, nil).
+			Times(1)
+
+		err := complianceExportShowCmdF(s.client, &cobra.Command{}, []string{mockJob.Id})
+		s.Require().EqualError(err, "job "+mockJob.Id+" is not a compliance export job")
+		s.Empty(printer.GetLines())
+	})
+}
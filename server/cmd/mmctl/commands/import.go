@@ -90,6 +90,15 @@ var ImportProcessCmd = &cobra.Command{
 	RunE:    withClient(importProcessCmdF),
 }
 
+var ImportSlackCmd = &cobra.Command{
+	Use:     "slack [importname] [team]",
+	Example: "  import slack 35uy6cwrqfnhdx3genrhqqznxc_slack_export.zip myteam",
+	Short:   "Start a Slack import job",
+	Long:    "Start a job importing an uploaded Slack export into a team. Starting it again for the same export and team resumes the import from where the last failed job stopped.",
+	Args:    cobra.ExactArgs(2),
+	RunE:    withClient(importSlackCmdF),
+}
+
 var ImportValidateCmd = &cobra.Command{
 	Use:     "validate [filepath]",
 	Example: "  import validate import_file.zip --team myteam --team myotherteam",
@@ -128,6 +137,7 @@ func init() {
 		ImportUploadCmd,
 		ImportListCmd,
 		ImportProcessCmd,
+		ImportSlackCmd,
 		ImportJobCmd,
 		ImportValidateCmd,
 	)
@@ -308,6 +318,31 @@ func importProcessCmdF(c client.Client, command *cobra.Command, args []string) e
 	return nil
 }
 
+func importSlackCmdF(c client.Client, command *cobra.Command, args []string) error {
+	importFile := args[0]
+
+	team := getTeamFromTeamArg(c, args[1])
+	if team == nil {
+		return fmt.Errorf("unable to find team %q", args[1])
+	}
+
+	job, _, err := c.CreateJob(context.TODO(), &model.Job{
+		Type: model.JobTypeSlackImport,
+		Data: map[string]string{
+			"import_file": importFile,
+			"team_id":     team.Id,
+			"local_mode":  "false",
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create Slack import job: %w", err)
+	}
+
+	printer.PrintT("Slack import job successfully created, ID: {{.Id}}", job)
+
+	return nil
+}
+
 func importJobShowCmdF(c client.Client, command *cobra.Command, args []string) error {
 	job, _, err := c.GetJob(context.TODO(), args[0])
 	if err != nil {
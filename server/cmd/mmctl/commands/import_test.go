@@ -235,6 +235,64 @@ func (s *MmctlUnitTestSuite) TestImportProcessCmdF() {
 	s.Equal(mockJob, printer.GetLines()[0].(*model.Job))
 }
 
+func (s *MmctlUnitTestSuite) TestImportSlackCmdF() {
+	importFile := "slack_export.zip"
+	team := &model.Team{Id: model.NewId(), Name: "myteam"}
+
+	s.Run("create a Slack import job", func() {
+		printer.Clean()
+		mockJob := &model.Job{
+			Type: model.JobTypeSlackImport,
+			Data: map[string]string{
+				"import_file": importFile,
+				"team_id":     team.Id,
+				"local_mode":  "false",
+			},
+		}
+
+		s.client.
+			EXPECT().
+			GetTeam(context.TODO(), team.Name, "").
+			Return(nil, &model.Response{StatusCode: http.StatusNotFound}, errors.New("mock error")).
+			Times(1)
+		s.client.
+			EXPECT().
+			GetTeamByName(context.TODO(), team.Name, "").
+			Return(team, &model.Response{}, nil).
+			Times(1)
+		s.client.
+			EXPECT().
+			CreateJob(context.TODO(), mockJob).
+			Return(mockJob, &model.Response{}, nil).
+			Times(1)
+
+		err := importSlackCmdF(s.client, &cobra.Command{}, []string{importFile, team.Name})
+		s.Require().NoError(err)
+		s.Len(printer.GetLines(), 1)
+		s.Empty(printer.GetErrorLines())
+		s.Equal(mockJob, printer.GetLines()[0].(*model.Job))
+	})
+
+	s.Run("team not found", func() {
+		printer.Clean()
+
+		s.client.
+			EXPECT().
+			GetTeam(context.TODO(), "unknown", "").
+			Return(nil, &model.Response{StatusCode: http.StatusNotFound}, errors.New("mock error")).
+			Times(1)
+		s.client.
+			EXPECT().
+			GetTeamByName(context.TODO(), "unknown", "").
+			Return(nil, &model.Response{StatusCode: http.StatusNotFound}, errors.New("mock error")).
+			Times(1)
+
+		err := importSlackCmdF(s.client, &cobra.Command{}, []string{importFile, "unknown"})
+		s.Require().EqualError(err, `unable to find team "unknown"`)
+		s.Empty(printer.GetLines())
+	})
+}
+
 func (s *MmctlUnitTestSuite) TestImportValidateCmdF() {
 	importFilePath := filepath.Join(os.TempDir(), "import.zip")
 
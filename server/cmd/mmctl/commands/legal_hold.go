@@ -0,0 +1,250 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+
+	"github.com/mattermost/mattermost/server/v8/cmd/mmctl/client"
+	"github.com/mattermost/mattermost/server/v8/cmd/mmctl/printer"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var LegalHoldCmd = &cobra.Command{
+	Use:   "legalhold",
+	Short: "Management of legal holds",
+	Long:  "Management of legal holds. The content of the users and channels of an active legal hold is excluded from data retention and permanent deletion.",
+}
+
+var LegalHoldCreateCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "Create a legal hold",
+	Long:  "Create a legal hold freezing the content posted by a set of users or in a set of channels.",
+	Example: `  legalhold create "Case 42" --users john.doe,jane@example.com --channels myteam:town-square
+  legalhold create "Case 42" --users john.doe --start 2023-01-01T00:00:00+00:00 --end 2023-06-30T23:59:59+00:00`,
+	Args: cobra.ExactArgs(1),
+	RunE: withClient(legalHoldCreateCmdF),
+}
+
+var LegalHoldListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List legal holds",
+	Long:    "List the active legal holds, and optionally the released ones.",
+	Example: "  legalhold list --all",
+	Args:    cobra.NoArgs,
+	RunE:    withClient(legalHoldListCmdF),
+}
+
+var LegalHoldShowCmd = &cobra.Command{
+	Use:     "show [legalHoldID]",
+	Short:   "Show a legal hold",
+	Example: "  legalhold show hc1pehx5dbrtbjt3x8hjc7nw7a",
+	Args:    cobra.ExactArgs(1),
+	RunE:    withClient(legalHoldShowCmdF),
+}
+
+var LegalHoldReleaseCmd = &cobra.Command{
+	Use:     "release [legalHoldID]",
+	Short:   "Release a legal hold",
+	Long:    "Release a legal hold. The content it covered becomes subject to data retention and deletion again, unless another active legal hold covers it.",
+	Example: "  legalhold release hc1pehx5dbrtbjt3x8hjc7nw7a",
+	Args:    cobra.ExactArgs(1),
+	RunE:    withClient(legalHoldReleaseCmdF),
+}
+
+var LegalHoldExportCmd = &cobra.Command{
+	Use:     "export [legalHoldID]",
+	Short:   "Export the content of a legal hold",
+	Long:    "Start a job exporting the content covered by a legal hold in the configured compliance export format.",
+	Example: "  legalhold export hc1pehx5dbrtbjt3x8hjc7nw7a",
+	Args:    cobra.ExactArgs(1),
+	RunE:    withClient(legalHoldExportCmdF),
+}
+
+func init() {
+	LegalHoldCreateCmd.Flags().String("description", "", "Description of the legal hold")
+	LegalHoldCreateCmd.Flags().StringSlice("users", nil, "Comma-separated list of the users whose content is held")
+	LegalHoldCreateCmd.Flags().StringSlice("channels", nil, "Comma-separated list of the channels whose content is held, in the team:channel format or as channel IDs")
+	LegalHoldCreateCmd.Flags().String("start", "", "Hold content created from this time on, in ISO 8601 format (e.g. 2023-01-01T00:00:00+00:00)")
+	LegalHoldCreateCmd.Flags().String("end", "", "Hold content created until this time, in ISO 8601 format. Content is held with no end date if not set")
+
+	LegalHoldListCmd.Flags().Int("page", 0, "Page number to fetch for the list of legal holds")
+	LegalHoldListCmd.Flags().Int("per-page", DefaultPageSize, "Number of legal holds to be fetched")
+	LegalHoldListCmd.Flags().Bool("all", false, "Include the released legal holds")
+
+	LegalHoldCmd.AddCommand(
+		LegalHoldCreateCmd,
+		LegalHoldListCmd,
+		LegalHoldShowCmd,
+		LegalHoldReleaseCmd,
+		LegalHoldExportCmd,
+	)
+
+	RootCmd.AddCommand(LegalHoldCmd)
+}
+
+func parseLegalHoldTime(command *cobra.Command, flag string) (int64, error) {
+	value, err := command.Flags().GetString(flag)
+	if err != nil || value == "" {
+		return 0, err
+	}
+
+	t, err := time.Parse(ISO8601Layout, value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s time %q, expected the %s format", flag, value, ISO8601Layout)
+	}
+
+	return model.GetMillisForTime(t), nil
+}
+
+func legalHoldCreateCmdF(c client.Client, command *cobra.Command, args []string) error {
+	description, err := command.Flags().GetString("description")
+	if err != nil {
+		return err
+	}
+	userArgs, err := command.Flags().GetStringSlice("users")
+	if err != nil {
+		return err
+	}
+	channelArgs, err := command.Flags().GetStringSlice("channels")
+	if err != nil {
+		return err
+	}
+	if len(userArgs) == 0 && len(channelArgs) == 0 {
+		return errors.New("at least one user or channel must be specified")
+	}
+
+	startAt, err := parseLegalHoldTime(command, "start")
+	if err != nil {
+		return err
+	}
+	endAt, err := parseLegalHoldTime(command, "end")
+	if err != nil {
+		return err
+	}
+
+	hold := &model.LegalHold{
+		DisplayName: args[0],
+		Description: description,
+		StartAt:     startAt,
+		EndAt:       endAt,
+	}
+
+	for i, user := range getUsersFromUserArgs(c, userArgs) {
+		if user == nil {
+			return fmt.Errorf("unable to find user %q", userArgs[i])
+		}
+		hold.UserIds = append(hold.UserIds, user.Id)
+	}
+
+	for i, channel := range getChannelsFromChannelArgs(c, channelArgs) {
+		if channel == nil {
+			return fmt.Errorf("unable to find channel %q", channelArgs[i])
+		}
+		hold.ChannelIds = append(hold.ChannelIds, channel.Id)
+	}
+
+	newHold, _, err := c.CreateLegalHold(context.TODO(), hold)
+	if err != nil {
+		return errors.Wrap(err, "failed to create legal hold")
+	}
+
+	printer.PrintT("Legal hold {{.DisplayName}} created with id {{.Id}}", newHold)
+
+	return nil
+}
+
+func legalHoldListCmdF(c client.Client, command *cobra.Command, args []string) error {
+	page, err := command.Flags().GetInt("page")
+	if err != nil {
+		return err
+	}
+	perPage, err := command.Flags().GetInt("per-page")
+	if err != nil {
+		return err
+	}
+	includeReleased, err := command.Flags().GetBool("all")
+	if err != nil {
+		return err
+	}
+
+	holds, _, err := c.GetLegalHolds(context.TODO(), page, perPage, includeReleased)
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch legal holds")
+	}
+
+	if len(holds) == 0 {
+		printer.Print("No legal holds found")
+		return nil
+	}
+
+	for _, hold := range holds {
+		status := "active"
+		if !hold.IsActive() {
+			status = "released"
+		}
+		printer.PrintT(fmt.Sprintf("{{.Id}}: {{.DisplayName}} (%s)", status), hold)
+	}
+
+	return nil
+}
+
+func legalHoldShowCmdF(c client.Client, command *cobra.Command, args []string) error {
+	hold, _, err := c.GetLegalHold(context.TODO(), args[0])
+	if err != nil {
+		return errors.Wrapf(err, "failed to get legal hold %s", args[0])
+	}
+
+	printLegalHold(hold)
+
+	return nil
+}
+
+func legalHoldReleaseCmdF(c client.Client, command *cobra.Command, args []string) error {
+	hold, _, err := c.ReleaseLegalHold(context.TODO(), args[0])
+	if err != nil {
+		return errors.Wrapf(err, "failed to release legal hold %s", args[0])
+	}
+
+	printer.PrintT("Legal hold {{.DisplayName}} released", hold)
+
+	return nil
+}
+
+func legalHoldExportCmdF(c client.Client, command *cobra.Command, args []string) error {
+	job, _, err := c.ExportLegalHold(context.TODO(), args[0])
+	if err != nil {
+		return errors.Wrapf(err, "failed to export legal hold %s", args[0])
+	}
+
+	printer.PrintT("Export job {{.Id}} created", job)
+
+	return nil
+}
+
+func printLegalHold(hold *model.LegalHold) {
+	formatTime := func(millis int64) string {
+		if millis == 0 {
+			return "-"
+		}
+		return model.GetTimeForMillis(millis).Format(ISO8601Layout)
+	}
+
+	printer.PrintT(fmt.Sprintf(`  ID: {{.Id}}
+  Name: {{.DisplayName}}
+  Description: {{.Description}}
+  Users: {{.UserIds}}
+  Channels: {{.ChannelIds}}
+  Start: %s
+  End: %s
+  Released: %s
+`,
+		formatTime(hold.StartAt), formatTime(hold.EndAt), formatTime(hold.ReleasedAt)), hold)
+}
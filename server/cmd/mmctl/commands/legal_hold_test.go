@@ -0,0 +1,208 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package commands
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mattermost/mattermost/server/public/model"
+
+	"github.com/mattermost/mattermost/server/v8/cmd/mmctl/printer"
+
+	"github.com/spf13/cobra"
+)
+
+func (s *MmctlUnitTestSuite) TestLegalHoldCreateCmdF() {
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().String("description", "", "")
+		cmd.Flags().StringSlice("users", nil, "")
+		cmd.Flags().StringSlice("channels", nil, "")
+		cmd.Flags().String("start", "", "")
+		cmd.Flags().String("end", "", "")
+		return cmd
+	}
+
+	s.Run("Create a legal hold", func() {
+		printer.Clean()
+
+		user := &model.User{Id: model.NewId(), Username: "john.doe"}
+		channel := &model.Channel{Id: model.NewId()}
+		expectedHold := &model.LegalHold{
+			DisplayName: "Case 42",
+			Description: "description",
+			UserIds:     []string{user.Id},
+			ChannelIds:  []string{channel.Id},
+			StartAt:     1672531200000,
+		}
+		mockHold := *expectedHold
+		mockHold.Id = model.NewId()
+
+		cmd := newCmd()
+		s.Require().NoError(cmd.Flags().Set("description", "description"))
+		s.Require().NoError(cmd.Flags().Set("users", user.Username))
+		s.Require().NoError(cmd.Flags().Set("channels", channel.Id))
+		s.Require().NoError(cmd.Flags().Set("start", "2023-01-01T00:00:00+00:00"))
+
+		s.client.
+			EXPECT().
+			GetUserByUsername(context.TODO(), user.Username, "").
+			Return(user, &model.Response{}, nil).
+			Times(1)
+
+		s.client.
+			EXPECT().
+			GetChannel(context.TODO(), channel.Id, "").
+			Return(channel, &model.Response{}, nil).
+			Times(1)
+
+		s.client.
+			EXPECT().
+			CreateLegalHold(context.TODO(), expectedHold).
+			Return(&mockHold, &model.Response{}, nil).
+			Times(1)
+
+		err := legalHoldCreateCmdF(s.client, cmd, []string{"Case 42"})
+		s.Require().NoError(err)
+		s.Require().Len(printer.GetLines(), 1)
+		s.Require().Equal(&mockHold, printer.GetLines()[0])
+		s.Require().Empty(printer.GetErrorLines())
+	})
+
+	s.Run("Fail without users and channels", func() {
+		printer.Clean()
+
+		err := legalHoldCreateCmdF(s.client, newCmd(), []string{"Case 42"})
+		s.Require().EqualError(err, "at least one user or channel must be specified")
+	})
+
+	s.Run("Fail with an invalid start time", func() {
+		printer.Clean()
+
+		cmd := newCmd()
+		s.Require().NoError(cmd.Flags().Set("users", "john.doe"))
+		s.Require().NoError(cmd.Flags().Set("start", "yesterday"))
+
+		err := legalHoldCreateCmdF(s.client, cmd, []string{"Case 42"})
+		s.Require().Error(err)
+		s.Require().Contains(err.Error(), "invalid start time")
+	})
+
+	s.Run("Fail with an unknown channel", func() {
+		printer.Clean()
+
+		channelID := model.NewId()
+		cmd := newCmd()
+		s.Require().NoError(cmd.Flags().Set("channels", channelID))
+
+		s.client.
+			EXPECT().
+			GetChannel(context.TODO(), channelID, "").
+			Return(nil, &model.Response{}, errors.New("not found")).
+			Times(1)
+
+		err := legalHoldCreateCmdF(s.client, cmd, []string{"Case 42"})
+		s.Require().EqualError(err, `unable to find channel "`+channelID+`"`)
+		s.Require().Empty(printer.GetLines())
+	})
+}
+
+func (s *MmctlUnitTestSuite) TestLegalHoldListCmdF() {
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().Int("page", 0, "")
+		cmd.Flags().Int("per-page", 200, "")
+		cmd.Flags().Bool("all", false, "")
+		return cmd
+	}
+
+	s.Run("List the active legal holds", func() {
+		printer.Clean()
+
+		mockHold := &model.LegalHold{Id: model.NewId(), DisplayName: "Case 42"}
+
+		s.client.
+			EXPECT().
+			GetLegalHolds(context.TODO(), 0, 200, false).
+			Return([]*model.LegalHold{mockHold}, &model.Response{}, nil).
+			Times(1)
+
+		err := legalHoldListCmdF(s.client, newCmd(), []string{})
+		s.Require().NoError(err)
+		s.Require().Len(printer.GetLines(), 1)
+		s.Require().Equal(mockHold, printer.GetLines()[0])
+	})
+
+	s.Run("List all the legal holds", func() {
+		printer.Clean()
+
+		cmd := newCmd()
+		s.Require().NoError(cmd.Flags().Set("all", "true"))
+
+		s.client.
+			EXPECT().
+			GetLegalHolds(context.TODO(), 0, 200, true).
+			Return([]*model.LegalHold{}, &model.Response{}, nil).
+			Times(1)
+
+		err := legalHoldListCmdF(s.client, cmd, []string{})
+		s.Require().NoError(err)
+		s.Require().Len(printer.GetLines(), 1)
+		s.Require().Equal("No legal holds found", printer.GetLines()[0])
+	})
+}
+
+func (s *MmctlUnitTestSuite) TestLegalHoldReleaseCmdF() {
+	s.Run("Release a legal hold", func() {
+		printer.Clean()
+
+		mockHold := &model.LegalHold{Id: model.NewId(), DisplayName: "Case 42", ReleasedAt: 1}
+
+		s.client.
+			EXPECT().
+			ReleaseLegalHold(context.TODO(), mockHold.Id).
+			Return(mockHold, &model.Response{}, nil).
+			Times(1)
+
+		err := legalHoldReleaseCmdF(s.client, &cobra.Command{}, []string{mockHold.Id})
+		s.Require().NoError(err)
+		s.Require().Len(printer.GetLines(), 1)
+		s.Require().Equal(mockHold, printer.GetLines()[0])
+	})
+
+	s.Run("Fail to release a legal hold", func() {
+		printer.Clean()
+
+		holdID := model.NewId()
+
+		s.client.
+			EXPECT().
+			ReleaseLegalHold(context.TODO(), holdID).
+			Return(nil, &model.Response{}, errors.New("mock error")).
+			Times(1)
+
+		err := legalHoldReleaseCmdF(s.client, &cobra.Command{}, []string{holdID})
+		s.Require().EqualError(err, "failed to release legal hold "+holdID+": mock error")
+		s.Require().Empty(printer.GetLines())
+	})
+}
+
+func (s *MmctlUnitTestSuite) TestLegalHoldExportCmdF() {
+	printer.Clean()
+
+	holdID := model.NewId()
+	mockJob := &model.Job{Id: model.NewId(), Type: model.JobTypeLegalHoldExport}
+
+	s.client.
+		EXPECT().
+		ExportLegalHold(context.TODO(), holdID).
+		Return(mockJob, &model.Response{}, nil).
+		Times(1)
+
+	err := legalHoldExportCmdF(s.client, &cobra.Command{}, []string{holdID})
+	s.Require().NoError(err)
+	s.Require().Len(printer.GetLines(), 1)
+	s.Require().Equal(mockJob, printer.GetLines()[0])
+}
@@ -246,6 +246,22 @@ func (mr *MockClientMockRecorder) CreateJob(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateJob", reflect.TypeOf((*MockClient)(nil).CreateJob), arg0, arg1)
 }
 
+// CreateLegalHold mocks base method.
+func (m *MockClient) CreateLegalHold(arg0 context.Context, arg1 *model.LegalHold) (*model.LegalHold, *model.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateLegalHold", arg0, arg1)
+	ret0, _ := ret[0].(*model.LegalHold)
+	ret1, _ := ret[1].(*model.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateLegalHold indicates an expected call of CreateLegalHold.
+func (mr *MockClientMockRecorder) CreateLegalHold(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLegalHold", reflect.TypeOf((*MockClient)(nil).CreateLegalHold), arg0, arg1)
+}
+
 // CreateOutgoingWebhook mocks base method.
 func (m *MockClient) CreateOutgoingWebhook(arg0 context.Context, arg1 *model.OutgoingWebhook) (*model.OutgoingWebhook, *model.Response, error) {
 	m.ctrl.T.Helper()
@@ -555,6 +571,22 @@ func (mr *MockClientMockRecorder) EnablePlugin(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnablePlugin", reflect.TypeOf((*MockClient)(nil).EnablePlugin), arg0, arg1)
 }
 
+// ExportLegalHold mocks base method.
+func (m *MockClient) ExportLegalHold(arg0 context.Context, arg1 string) (*model.Job, *model.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportLegalHold", arg0, arg1)
+	ret0, _ := ret[0].(*model.Job)
+	ret1, _ := ret[1].(*model.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ExportLegalHold indicates an expected call of ExportLegalHold.
+func (mr *MockClientMockRecorder) ExportLegalHold(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportLegalHold", reflect.TypeOf((*MockClient)(nil).ExportLegalHold), arg0, arg1)
+}
+
 // GeneratePresignedURL mocks base method.
 func (m *MockClient) GeneratePresignedURL(arg0 context.Context, arg1 string) (*model.PresignURLResponse, *model.Response, error) {
 	m.ctrl.T.Helper()
@@ -942,6 +974,38 @@ func (mr *MockClientMockRecorder) GetLdapGroups(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLdapGroups", reflect.TypeOf((*MockClient)(nil).GetLdapGroups), arg0)
 }
 
+// GetLegalHold mocks base method.
+func (m *MockClient) GetLegalHold(arg0 context.Context, arg1 string) (*model.LegalHold, *model.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLegalHold", arg0, arg1)
+	ret0, _ := ret[0].(*model.LegalHold)
+	ret1, _ := ret[1].(*model.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetLegalHold indicates an expected call of GetLegalHold.
+func (mr *MockClientMockRecorder) GetLegalHold(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLegalHold", reflect.TypeOf((*MockClient)(nil).GetLegalHold), arg0, arg1)
+}
+
+// GetLegalHolds mocks base method.
+func (m *MockClient) GetLegalHolds(arg0 context.Context, arg1, arg2 int, arg3 bool) ([]*model.LegalHold, *model.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLegalHolds", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]*model.LegalHold)
+	ret1, _ := ret[1].(*model.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetLegalHolds indicates an expected call of GetLegalHolds.
+func (mr *MockClientMockRecorder) GetLegalHolds(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLegalHolds", reflect.TypeOf((*MockClient)(nil).GetLegalHolds), arg0, arg1, arg2, arg3)
+}
+
 // GetLogs mocks base method.
 func (m *MockClient) GetLogs(arg0 context.Context, arg1, arg2 int) ([]string, *model.Response, error) {
 	m.ctrl.T.Helper()
@@ -1858,6 +1922,22 @@ func (mr *MockClientMockRecorder) RegenOutgoingHookToken(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegenOutgoingHookToken", reflect.TypeOf((*MockClient)(nil).RegenOutgoingHookToken), arg0, arg1)
 }
 
+// ReleaseLegalHold mocks base method.
+func (m *MockClient) ReleaseLegalHold(arg0 context.Context, arg1 string) (*model.LegalHold, *model.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseLegalHold", arg0, arg1)
+	ret0, _ := ret[0].(*model.LegalHold)
+	ret1, _ := ret[1].(*model.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ReleaseLegalHold indicates an expected call of ReleaseLegalHold.
+func (mr *MockClientMockRecorder) ReleaseLegalHold(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseLegalHold", reflect.TypeOf((*MockClient)(nil).ReleaseLegalHold), arg0, arg1)
+}
+
 // ReloadConfig mocks base method.
 func (m *MockClient) ReloadConfig(arg0 context.Context) (*model.Response, error) {
 	m.ctrl.T.Helper()
@@ -40,6 +40,12 @@ var configSensitivePaths = map[string]bool{
 	"LdapSettings.BindPassword":                              true,
 	"FileSettings.PublicLinkSalt":                            true,
 	"FileSettings.AmazonS3SecretAccessKey":                   true,
+	"FileSettings.GoogleCloudStorageCredentialsJSON":         true,
+	"FileSettings.AzureStorageAccountKey":                    true,
+	"FileSettings.ExportGoogleCloudStorageCredentialsJSON":   true,
+	"FileSettings.ExportAzureStorageAccountKey":              true,
+	"FileSettings.EncryptionAtRestKey":                       true,
+	"FileSettings.EncryptionAtRestPreviousKeys":              true,
 	"SqlSettings.DataSource":                                 true,
 	"SqlSettings.AtRestEncryptKey":                           true,
 	"SqlSettings.DataSourceReplicas":                         true,
@@ -33,6 +33,22 @@ func desanitize(actual, target *model.Config) {
 	if *target.FileSettings.AmazonS3SecretAccessKey == model.FakeSetting {
 		target.FileSettings.AmazonS3SecretAccessKey = actual.FileSettings.AmazonS3SecretAccessKey
 	}
+	if target.FileSettings.GoogleCloudStorageCredentialsJSON != nil && *target.FileSettings.GoogleCloudStorageCredentialsJSON == model.FakeSetting {
+		target.FileSettings.GoogleCloudStorageCredentialsJSON = actual.FileSettings.GoogleCloudStorageCredentialsJSON
+	}
+	if target.FileSettings.AzureStorageAccountKey != nil && *target.FileSettings.AzureStorageAccountKey == model.FakeSetting {
+		target.FileSettings.AzureStorageAccountKey = actual.FileSettings.AzureStorageAccountKey
+	}
+	if target.FileSettings.ExportGoogleCloudStorageCredentialsJSON != nil && *target.FileSettings.ExportGoogleCloudStorageCredentialsJSON == model.FakeSetting {
+		target.FileSettings.ExportGoogleCloudStorageCredentialsJSON = actual.FileSettings.ExportGoogleCloudStorageCredentialsJSON
+	}
+	if target.FileSettings.ExportAzureStorageAccountKey != nil && *target.FileSettings.ExportAzureStorageAccountKey == model.FakeSetting {
+		target.FileSettings.ExportAzureStorageAccountKey = actual.FileSettings.ExportAzureStorageAccountKey
+	}
+	target.FileSettings.EncryptionAtRestPreviousKeys = desanitizePreviousKeys(actual, target)
+	if target.FileSettings.EncryptionAtRestKey != nil && *target.FileSettings.EncryptionAtRestKey == model.FakeSetting {
+		target.FileSettings.EncryptionAtRestKey = actual.FileSettings.EncryptionAtRestKey
+	}
 
 	if *target.EmailSettings.SMTPPassword == model.FakeSetting {
 		target.EmailSettings.SMTPPassword = actual.EmailSettings.SMTPPassword
@@ -85,6 +101,19 @@ func desanitize(actual, target *model.Config) {
 		*target.MessageExportSettings.GlobalRelaySettings.SMTPPassword = *actual.MessageExportSettings.GlobalRelaySettings.SMTPPassword
 	}
 
+	if target.MessageExportSettings.DeliverySettings != nil && actual.MessageExportSettings.DeliverySettings != nil {
+		targetDelivery, actualDelivery := target.MessageExportSettings.DeliverySettings, actual.MessageExportSettings.DeliverySettings
+		if targetDelivery.SFTPPassword != nil && *targetDelivery.SFTPPassword == model.FakeSetting {
+			targetDelivery.SFTPPassword = actualDelivery.SFTPPassword
+		}
+		if targetDelivery.SFTPPrivateKey != nil && *targetDelivery.SFTPPrivateKey == model.FakeSetting {
+			targetDelivery.SFTPPrivateKey = actualDelivery.SFTPPrivateKey
+		}
+		if targetDelivery.S3SecretAccessKey != nil && *targetDelivery.S3SecretAccessKey == model.FakeSetting {
+			targetDelivery.S3SecretAccessKey = actualDelivery.S3SecretAccessKey
+		}
+	}
+
 	if *target.ServiceSettings.SplitKey == model.FakeSetting {
 		*target.ServiceSettings.SplitKey = *actual.ServiceSettings.SplitKey
 	}
@@ -98,6 +127,40 @@ func desanitize(actual, target *model.Config) {
 	}
 }
 
+// desanitizePreviousKeys restores each sanitized entry of
+// FileSettings.EncryptionAtRestPreviousKeys by position. When the current key
+// is being rotated, the System Console moves the old key to the front of the
+// list, so placeholders are matched against the old current key followed by
+// the old previous keys. Placeholders with no counterpart are dropped rather
+// than saved as keys.
+func desanitizePreviousKeys(actual, target *model.Config) []string {
+	if target.FileSettings.EncryptionAtRestPreviousKeys == nil {
+		return nil
+	}
+
+	known := actual.FileSettings.EncryptionAtRestPreviousKeys
+	currentKey := target.FileSettings.EncryptionAtRestKey
+	actualKey := actual.FileSettings.EncryptionAtRestKey
+	rotated := currentKey != nil && *currentKey != model.FakeSetting &&
+		actualKey != nil && *actualKey != "" && *currentKey != *actualKey
+	if rotated {
+		known = append([]string{*actualKey}, known...)
+	}
+
+	keys := make([]string, 0, len(target.FileSettings.EncryptionAtRestPreviousKeys))
+	for i, value := range target.FileSettings.EncryptionAtRestPreviousKeys {
+		if value != model.FakeSetting {
+			keys = append(keys, value)
+			continue
+		}
+		if i < len(known) {
+			keys = append(keys, known[i])
+		}
+	}
+
+	return keys
+}
+
 // fixConfig patches invalid or missing data in the configuration.
 func fixConfig(cfg *model.Config) {
 	// Ensure SiteURL has no trailing slash.
@@ -31,6 +31,9 @@ func TestDesanitize(t *testing.T) {
 	actual.SqlSettings.DataSource = model.NewPointer("data_source")
 	actual.SqlSettings.AtRestEncryptKey = model.NewPointer("at_rest_encrypt_key")
 	actual.ElasticsearchSettings.Password = model.NewPointer("password")
+	actual.MessageExportSettings.DeliverySettings.SFTPPassword = model.NewPointer("sftp_password")
+	actual.MessageExportSettings.DeliverySettings.SFTPPrivateKey = model.NewPointer("sftp_private_key")
+	actual.MessageExportSettings.DeliverySettings.S3SecretAccessKey = model.NewPointer("s3_secret_access_key")
 	actual.SqlSettings.DataSourceReplicas = append(actual.SqlSettings.DataSourceReplicas, "replica0")
 	actual.SqlSettings.DataSourceReplicas = append(actual.SqlSettings.DataSourceReplicas, "replica1")
 	actual.SqlSettings.DataSourceSearchReplicas = append(actual.SqlSettings.DataSourceSearchReplicas, "search_replica0")
@@ -59,6 +62,9 @@ func TestDesanitize(t *testing.T) {
 	target.SqlSettings.DataSource = model.NewPointer(model.FakeSetting)
 	target.SqlSettings.AtRestEncryptKey = model.NewPointer(model.FakeSetting)
 	target.ElasticsearchSettings.Password = model.NewPointer(model.FakeSetting)
+	target.MessageExportSettings.DeliverySettings.SFTPPassword = model.NewPointer(model.FakeSetting)
+	target.MessageExportSettings.DeliverySettings.SFTPPrivateKey = model.NewPointer(model.FakeSetting)
+	target.MessageExportSettings.DeliverySettings.S3SecretAccessKey = model.NewPointer(model.FakeSetting)
 	target.SqlSettings.DataSourceReplicas = []string{model.FakeSetting, model.FakeSetting}
 	target.SqlSettings.DataSourceSearchReplicas = []string{model.FakeSetting, model.FakeSetting}
 	target.PluginSettings.Plugins = map[string]map[string]any{
@@ -86,12 +92,62 @@ func TestDesanitize(t *testing.T) {
 	assert.Equal(t, *actual.SqlSettings.DataSource, *target.SqlSettings.DataSource)
 	assert.Equal(t, *actual.SqlSettings.AtRestEncryptKey, *target.SqlSettings.AtRestEncryptKey)
 	assert.Equal(t, *actual.ElasticsearchSettings.Password, *target.ElasticsearchSettings.Password)
+	assert.Equal(t, *actual.MessageExportSettings.DeliverySettings.SFTPPassword, *target.MessageExportSettings.DeliverySettings.SFTPPassword)
+	assert.Equal(t, *actual.MessageExportSettings.DeliverySettings.SFTPPrivateKey, *target.MessageExportSettings.DeliverySettings.SFTPPrivateKey)
+	assert.Equal(t, *actual.MessageExportSettings.DeliverySettings.S3SecretAccessKey, *target.MessageExportSettings.DeliverySettings.S3SecretAccessKey)
 	assert.Equal(t, actual.SqlSettings.DataSourceReplicas, target.SqlSettings.DataSourceReplicas)
 	assert.Equal(t, actual.SqlSettings.DataSourceSearchReplicas, target.SqlSettings.DataSourceSearchReplicas)
 	assert.Equal(t, actual.ServiceSettings.SplitKey, target.ServiceSettings.SplitKey)
 	assert.Equal(t, actual.PluginSettings.Plugins, target.PluginSettings.Plugins)
 }
 
+func TestDesanitizeEncryptionAtRestPreviousKeys(t *testing.T) {
+	newConfigs := func(actualPrevious, targetPrevious []string, targetKey string) (*model.Config, *model.Config) {
+		actual := &model.Config{}
+		actual.SetDefaults()
+		actual.FileSettings.EncryptionAtRestKey = model.NewPointer("current_key")
+		actual.FileSettings.EncryptionAtRestPreviousKeys = actualPrevious
+
+		target := &model.Config{}
+		target.SetDefaults()
+		target.FileSettings.EncryptionAtRestKey = model.NewPointer(targetKey)
+		target.FileSettings.EncryptionAtRestPreviousKeys = targetPrevious
+		return actual, target
+	}
+
+	t.Run("unchanged keys", func(t *testing.T) {
+		actual, target := newConfigs([]string{"key0", "key1"}, []string{model.FakeSetting, model.FakeSetting}, model.FakeSetting)
+		desanitize(actual, target)
+		assert.Equal(t, "current_key", *target.FileSettings.EncryptionAtRestKey)
+		assert.Equal(t, []string{"key0", "key1"}, target.FileSettings.EncryptionAtRestPreviousKeys)
+	})
+
+	t.Run("rotating the current key", func(t *testing.T) {
+		actual, target := newConfigs([]string{"key0"}, []string{model.FakeSetting, model.FakeSetting}, "new_key")
+		desanitize(actual, target)
+		assert.Equal(t, "new_key", *target.FileSettings.EncryptionAtRestKey)
+		assert.Equal(t, []string{"current_key", "key0"}, target.FileSettings.EncryptionAtRestPreviousKeys)
+	})
+
+	t.Run("adding a key", func(t *testing.T) {
+		actual, target := newConfigs([]string{"key0"}, []string{model.FakeSetting, "key1"}, model.FakeSetting)
+		desanitize(actual, target)
+		assert.Equal(t, []string{"key0", "key1"}, target.FileSettings.EncryptionAtRestPreviousKeys)
+	})
+
+	t.Run("removing a key", func(t *testing.T) {
+		actual, target := newConfigs([]string{"key0", "key1"}, []string{model.FakeSetting}, model.FakeSetting)
+		desanitize(actual, target)
+		assert.Equal(t, []string{"key0"}, target.FileSettings.EncryptionAtRestPreviousKeys)
+	})
+
+	t.Run("placeholder without a counterpart", func(t *testing.T) {
+		actual, target := newConfigs(nil, []string{model.FakeSetting}, model.FakeSetting)
+		desanitize(actual, target)
+		assert.Empty(t, target.FileSettings.EncryptionAtRestPreviousKeys)
+	})
+}
+
 func TestFixInvalidLocales(t *testing.T) {
 	// utils.TranslationsPreInit errors when TestFixInvalidLocales is run as part of testing the package,
 	// but doesn't error when the test is run individually.
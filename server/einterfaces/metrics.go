@@ -24,6 +24,7 @@ type MetricsInterface interface {
 
 	IncrementHTTPRequest()
 	IncrementHTTPError()
+	IncrementHTTPRateLimitRejection(policy string)
 
 	IncrementClusterRequest()
 	ObserveClusterRequestDuration(elapsed float64)
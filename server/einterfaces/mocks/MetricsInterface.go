@@ -118,6 +118,11 @@ func (_m *MetricsInterface) IncrementHTTPError() {
 	_m.Called()
 }
 
+// IncrementHTTPRateLimitRejection provides a mock function with given fields: policy
+func (_m *MetricsInterface) IncrementHTTPRateLimitRejection(policy string) {
+	_m.Called(policy)
+}
+
 // IncrementHTTPRequest provides a mock function with given fields:
 func (_m *MetricsInterface) IncrementHTTPRequest() {
 	_m.Called()
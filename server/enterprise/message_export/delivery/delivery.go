@@ -0,0 +1,149 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.enterprise for license information.
+
+package delivery
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/platform/shared/filestore"
+)
+
+// ManifestExtension is appended to the path of a delivered batch to name its manifest, which
+// holds its SHA-256 checksum in the format of the sha256sum tool.
+const ManifestExtension = ".sha256"
+
+// ErrDeliveryFailed is returned once a batch could not be delivered in any of the attempts.
+var ErrDeliveryFailed = errors.New("unable to deliver the batch")
+
+// Target is an archive the batches are copied to.
+type Target interface {
+	// WriteFile writes the content of the reader to the path, relative to the root of the
+	// archive, and returns the number of bytes written.
+	WriteFile(ctx context.Context, r io.Reader, path string) (int64, error)
+	Close() error
+}
+
+// Deliverer copies the batches from the export file store to a target, along with a manifest
+// holding their checksum.
+type Deliverer struct {
+	target        string
+	connect       func() (Target, error)
+	maxRetries    int
+	retryInterval time.Duration
+}
+
+// New returns the deliverer for the archive configured in
+// MessageExportSettings.DeliverySettings, or nil if there is none.
+func New(config *model.Config, skipVerify bool) *Deliverer {
+	settings := config.MessageExportSettings.DeliverySettings
+	if settings == nil || *settings.Target == model.MessageExportDeliveryTargetNone {
+		return nil
+	}
+
+	var connect func() (Target, error)
+	switch *settings.Target {
+	case model.MessageExportDeliveryTargetSFTP:
+		connect = func() (Target, error) { return newSFTPTarget(settings) }
+	case model.MessageExportDeliveryTargetS3:
+		connect = func() (Target, error) { return newS3Target(settings, skipVerify) }
+	default:
+		return nil
+	}
+
+	return &Deliverer{
+		target:        *settings.Target,
+		connect:       connect,
+		maxRetries:    *settings.MaxRetries,
+		retryInterval: time.Duration(*settings.RetryIntervalSeconds) * time.Second,
+	}
+}
+
+func (d *Deliverer) Target() string {
+	return d.target
+}
+
+// Deliver copies the batch at batchPath in the export file store to the target, then writes
+// its manifest next to it. The path of the batch in the target is its path in the export file
+// store, less the compliance export directory. A failed attempt is retried after a wait which
+// doubles on every retry. It returns the number of attempts made.
+func (d *Deliverer) Deliver(rctx request.CTX, backend filestore.FileBackend, batchPath string) (int, error) {
+	targetPath := strings.TrimPrefix(batchPath, model.ComplianceExportPath+"/")
+	wait := d.retryInterval
+
+	for attempt := 1; ; attempt++ {
+		err := d.deliverOnce(rctx.Context(), backend, batchPath, targetPath)
+		if err == nil {
+			return attempt, nil
+		}
+
+		if attempt > d.maxRetries {
+			return attempt, fmt.Errorf("%w %s to %s after %d attempts: %w", ErrDeliveryFailed, batchPath, d.target, attempt, err)
+		}
+
+		rctx.Logger().Warn("Failed to deliver the message export batch, retrying",
+			mlog.String("batch_path", batchPath),
+			mlog.String("target", d.target),
+			mlog.Int("attempt", attempt),
+			mlog.Duration("wait", wait),
+			mlog.Err(err),
+		)
+
+		select {
+		case <-time.After(wait):
+		case <-rctx.Context().Done():
+			return attempt, fmt.Errorf("%w %s to %s: %w", ErrDeliveryFailed, batchPath, d.target, rctx.Context().Err())
+		}
+		wait *= 2
+	}
+}
+
+func (d *Deliverer) deliverOnce(ctx context.Context, backend filestore.FileBackend, batchPath, targetPath string) error {
+	size, err := backend.FileSize(batchPath)
+	if err != nil {
+		return fmt.Errorf("unable to get the size of the batch: %w", err)
+	}
+
+	r, err := backend.Reader(batchPath)
+	if err != nil {
+		return fmt.Errorf("unable to read the batch: %w", err)
+	}
+	defer r.Close()
+
+	target, err := d.connect()
+	if err != nil {
+		return fmt.Errorf("unable to connect to the target: %w", err)
+	}
+	defer target.Close()
+
+	// The checksum is computed on the bytes sent, so that the manifest matches what the target
+	// received even if the batch changed in the export file store in the meantime.
+	hash := sha256.New()
+	written, err := target.WriteFile(ctx, io.TeeReader(r, hash), targetPath)
+	if err != nil {
+		return fmt.Errorf("unable to write the batch: %w", err)
+	}
+	if written != size {
+		return fmt.Errorf("the batch is %d bytes but %d were written", size, written)
+	}
+
+	// The manifest is written last, so that a complete batch is one with a manifest.
+	manifest := fmt.Sprintf("%s  %s\n", hex.EncodeToString(hash.Sum(nil)), path.Base(targetPath))
+	if _, err = target.WriteFile(ctx, bytes.NewReader([]byte(manifest)), targetPath+ManifestExtension); err != nil {
+		return fmt.Errorf("unable to write the manifest: %w", err)
+	}
+
+	return nil
+}
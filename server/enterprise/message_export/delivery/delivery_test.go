@@ -0,0 +1,130 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.enterprise for license information.
+
+package delivery
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/platform/shared/filestore"
+)
+
+type testTarget struct {
+	files    map[string]string
+	failures int
+	closed   int
+}
+
+func (t *testTarget) WriteFile(_ context.Context, r io.Reader, path string) (int64, error) {
+	if t.failures > 0 {
+		t.failures--
+		// Read part of the batch, as a connection lost in the middle of the upload would.
+		_, _ = io.CopyN(io.Discard, r, 2)
+		return 0, errors.New("connection reset")
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+	t.files[path] = string(data)
+	return int64(len(data)), nil
+}
+
+func (t *testTarget) Close() error {
+	t.closed++
+	return nil
+}
+
+func newTestDeliverer(target *testTarget, maxRetries int) *Deliverer {
+	return &Deliverer{
+		target:        model.MessageExportDeliveryTargetSFTP,
+		connect:       func() (Target, error) { return target, nil },
+		maxRetries:    maxRetries,
+		retryInterval: time.Millisecond,
+	}
+}
+
+func TestDeliver(t *testing.T) {
+	rctx := request.TestContext(t)
+
+	backend, err := filestore.NewFileBackend(filestore.FileBackendSettings{
+		DriverName: model.ImageDriverLocal,
+		Directory:  t.TempDir(),
+	})
+	require.NoError(t, err)
+
+	content := "batch content"
+	batchPath := "export/compliance-export-2024-08-01-01h00m-0-1000/batch001-0-1000.zip"
+	_, err = backend.WriteFile(strings.NewReader(content), batchPath)
+	require.NoError(t, err)
+
+	sum := sha256.Sum256([]byte(content))
+	expectedManifest := hex.EncodeToString(sum[:]) + "  batch001-0-1000.zip\n"
+
+	t.Run("delivers the batch and its manifest", func(t *testing.T) {
+		target := &testTarget{files: map[string]string{}}
+
+		attempts, err := newTestDeliverer(target, 3).Deliver(rctx, backend, batchPath)
+		require.NoError(t, err)
+		assert.Equal(t, 1, attempts)
+		assert.Equal(t, map[string]string{
+			"compliance-export-2024-08-01-01h00m-0-1000/batch001-0-1000.zip":        content,
+			"compliance-export-2024-08-01-01h00m-0-1000/batch001-0-1000.zip.sha256": expectedManifest,
+		}, target.files)
+		assert.Equal(t, 1, target.closed)
+	})
+
+	t.Run("retries a failed delivery", func(t *testing.T) {
+		target := &testTarget{files: map[string]string{}, failures: 2}
+
+		attempts, err := newTestDeliverer(target, 3).Deliver(rctx, backend, batchPath)
+		require.NoError(t, err)
+		assert.Equal(t, 3, attempts)
+		// The manifest is computed on the bytes of the successful attempt only.
+		assert.Equal(t, expectedManifest, target.files["compliance-export-2024-08-01-01h00m-0-1000/batch001-0-1000.zip.sha256"])
+		assert.Equal(t, 3, target.closed)
+	})
+
+	t.Run("fails once the retries are exhausted", func(t *testing.T) {
+		target := &testTarget{files: map[string]string{}, failures: 3}
+
+		attempts, err := newTestDeliverer(target, 2).Deliver(rctx, backend, batchPath)
+		require.ErrorIs(t, err, ErrDeliveryFailed)
+		assert.Contains(t, err.Error(), "connection reset")
+		assert.Equal(t, 3, attempts)
+		assert.Empty(t, target.files)
+	})
+
+	t.Run("fails when the batch is missing", func(t *testing.T) {
+		target := &testTarget{files: map[string]string{}}
+
+		_, err := newTestDeliverer(target, 0).Deliver(rctx, backend, "export/missing.zip")
+		require.ErrorIs(t, err, ErrDeliveryFailed)
+		assert.Empty(t, target.files)
+	})
+}
+
+func TestNew(t *testing.T) {
+	config := &model.Config{}
+	config.SetDefaults()
+	assert.Nil(t, New(config, false))
+
+	*config.MessageExportSettings.DeliverySettings.Target = model.MessageExportDeliveryTargetS3
+	deliverer := New(config, false)
+	require.NotNil(t, deliverer)
+	assert.Equal(t, model.MessageExportDeliveryTargetS3, deliverer.Target())
+	assert.Equal(t, 3, deliverer.maxRetries)
+	assert.Equal(t, 30*time.Second, deliverer.retryInterval)
+}
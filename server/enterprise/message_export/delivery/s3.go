@@ -0,0 +1,45 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.enterprise for license information.
+
+package delivery
+
+import (
+	"context"
+	"io"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/v8/platform/shared/filestore"
+)
+
+type s3Target struct {
+	backend *filestore.S3FileBackend
+}
+
+func newS3Target(settings *model.MessageExportDeliverySettings, skipVerify bool) (*s3Target, error) {
+	backend, err := filestore.NewS3FileBackendWithoutBifrost(filestore.FileBackendSettings{
+		DriverName:                         model.ImageDriverS3,
+		AmazonS3AccessKeyId:                *settings.S3AccessKeyId,
+		AmazonS3SecretAccessKey:            *settings.S3SecretAccessKey,
+		AmazonS3Bucket:                     *settings.S3Bucket,
+		AmazonS3PathPrefix:                 *settings.S3PathPrefix,
+		AmazonS3Region:                     *settings.S3Region,
+		AmazonS3Endpoint:                   *settings.S3Endpoint,
+		AmazonS3SSL:                        *settings.S3SSL,
+		AmazonS3RequestTimeoutMilliseconds: 30000,
+		AmazonS3UploadPartSizeBytes:        model.FileSettingsDefaultS3ExportUploadPartSizeBytes,
+		SkipVerify:                         skipVerify,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &s3Target{backend: backend}, nil
+}
+
+func (t *s3Target) WriteFile(ctx context.Context, r io.Reader, path string) (int64, error) {
+	return t.backend.WriteFileContext(ctx, r, path)
+}
+
+func (t *s3Target) Close() error {
+	return nil
+}
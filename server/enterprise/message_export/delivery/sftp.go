@@ -0,0 +1,103 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.enterprise for license information.
+
+package delivery
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+const sftpDialTimeout = 30 * time.Second
+
+type sftpTarget struct {
+	conn      *ssh.Client
+	client    *sftp.Client
+	directory string
+}
+
+func newSFTPTarget(settings *model.MessageExportDeliverySettings) (*sftpTarget, error) {
+	hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(*settings.SFTPHostKey))
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the host key: %w", err)
+	}
+
+	var auth []ssh.AuthMethod
+	if *settings.SFTPPrivateKey != "" {
+		signer, err := ssh.ParsePrivateKey([]byte(*settings.SFTPPrivateKey))
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse the private key: %w", err)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if *settings.SFTPPassword != "" {
+		auth = append(auth, ssh.Password(*settings.SFTPPassword))
+	}
+
+	address := net.JoinHostPort(*settings.SFTPHost, strconv.Itoa(*settings.SFTPPort))
+	conn, err := ssh.Dial("tcp", address, &ssh.ClientConfig{
+		User:            *settings.SFTPUsername,
+		Auth:            auth,
+		HostKeyCallback: ssh.FixedHostKey(hostKey),
+		Timeout:         sftpDialTimeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to %s: %w", address, err)
+	}
+
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("unable to start the sftp session: %w", err)
+	}
+
+	return &sftpTarget{conn: conn, client: client, directory: *settings.SFTPDirectory}, nil
+}
+
+// WriteFile uploads to a temporary file renamed once complete, so that an interrupted upload
+// never leaves a truncated file at the path.
+func (t *sftpTarget) WriteFile(ctx context.Context, r io.Reader, filePath string) (int64, error) {
+	filePath = path.Join(t.directory, filePath)
+	if err := t.client.MkdirAll(path.Dir(filePath)); err != nil {
+		return 0, fmt.Errorf("unable to create the directory of %s: %w", filePath, err)
+	}
+
+	// The upload can't be interrupted otherwise.
+	stop := context.AfterFunc(ctx, func() { t.conn.Close() })
+	defer stop()
+
+	tempPath := filePath + ".part"
+	f, err := t.client.Create(tempPath)
+	if err != nil {
+		return 0, fmt.Errorf("unable to create %s: %w", tempPath, err)
+	}
+	written, err := f.ReadFrom(r)
+	if err != nil {
+		f.Close()
+		return written, fmt.Errorf("unable to write %s: %w", tempPath, err)
+	}
+	if err = f.Close(); err != nil {
+		return written, fmt.Errorf("unable to close %s: %w", tempPath, err)
+	}
+
+	if err = t.client.PosixRename(tempPath, filePath); err != nil {
+		return written, fmt.Errorf("unable to rename %s to %s: %w", tempPath, filePath, err)
+	}
+
+	return written, nil
+}
+
+func (t *sftpTarget) Close() error {
+	t.client.Close()
+	return t.conn.Close()
+}
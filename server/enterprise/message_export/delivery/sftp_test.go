@@ -0,0 +1,170 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.enterprise for license information.
+
+package delivery
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/platform/shared/filestore"
+)
+
+// startSFTPServer starts an SFTP server serving the directory, accepting the password, and
+// returns its address and host key.
+func startSFTPServer(t *testing.T, directory, password string) (string, ssh.PublicKey) {
+	t.Helper()
+
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(private)
+	require.NoError(t, err)
+
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(_ ssh.ConnMetadata, given []byte) (*ssh.Permissions, error) {
+			if string(given) != password {
+				return nil, errors.New("wrong password")
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSFTP(conn, config, directory)
+		}
+	}()
+
+	return listener.Addr().String(), signer.PublicKey()
+}
+
+func serveSFTP(conn net.Conn, config *ssh.ServerConfig, directory string) {
+	serverConn, channels, requests, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	defer serverConn.Close()
+	go ssh.DiscardRequests(requests)
+
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		channel, channelRequests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			for req := range channelRequests {
+				// The payload of a subsystem request is the length-prefixed name of the subsystem.
+				_ = req.Reply(req.Type == "subsystem" && string(req.Payload[4:]) == "sftp", nil)
+			}
+		}()
+
+		server, err := sftp.NewServer(channel, sftp.WithServerWorkingDirectory(directory))
+		if err != nil {
+			return
+		}
+		_ = server.Serve()
+		server.Close()
+	}
+}
+
+func TestSFTPTarget(t *testing.T) {
+	rctx := request.TestContext(t)
+
+	archiveDir := t.TempDir()
+	address, hostKey := startSFTPServer(t, archiveDir, "secret")
+	host, port, err := net.SplitHostPort(address)
+	require.NoError(t, err)
+	portNumber, err := strconv.Atoi(port)
+	require.NoError(t, err)
+
+	backend, err := filestore.NewFileBackend(filestore.FileBackendSettings{
+		DriverName: model.ImageDriverLocal,
+		Directory:  t.TempDir(),
+	})
+	require.NoError(t, err)
+	batchPath := "export/compliance-export-2024-08-01-01h00m-0-1000/batch001-0-1000.zip"
+	_, err = backend.WriteFile(strings.NewReader("batch content"), batchPath)
+	require.NoError(t, err)
+
+	newConfig := func() *model.Config {
+		config := &model.Config{}
+		config.SetDefaults()
+		settings := config.MessageExportSettings.DeliverySettings
+		*settings.Target = model.MessageExportDeliveryTargetSFTP
+		*settings.MaxRetries = 0
+		*settings.SFTPHost = host
+		*settings.SFTPPort = portNumber
+		*settings.SFTPUsername = "mattermost"
+		*settings.SFTPPassword = "secret"
+		*settings.SFTPHostKey = string(ssh.MarshalAuthorizedKey(hostKey))
+		*settings.SFTPDirectory = "archive"
+		return config
+	}
+
+	t.Run("delivers the batch and its manifest", func(t *testing.T) {
+		attempts, err := New(newConfig(), false).Deliver(rctx, backend, batchPath)
+		require.NoError(t, err)
+		assert.Equal(t, 1, attempts)
+
+		delivered := filepath.Join(archiveDir, "archive", "compliance-export-2024-08-01-01h00m-0-1000", "batch001-0-1000.zip")
+		data, err := os.ReadFile(delivered)
+		require.NoError(t, err)
+		assert.Equal(t, "batch content", string(data))
+
+		manifest, err := os.ReadFile(delivered + ManifestExtension)
+		require.NoError(t, err)
+		assert.True(t, strings.HasSuffix(string(manifest), "  batch001-0-1000.zip\n"))
+
+		// The temporary files the uploads are written to are renamed once complete.
+		_, err = os.Stat(delivered + ".part")
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("rejects an unknown host key", func(t *testing.T) {
+		_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		otherSigner, err := ssh.NewSignerFromKey(otherKey)
+		require.NoError(t, err)
+
+		config := newConfig()
+		*config.MessageExportSettings.DeliverySettings.SFTPHostKey = string(ssh.MarshalAuthorizedKey(otherSigner.PublicKey()))
+
+		_, err = New(config, false).Deliver(rctx, backend, batchPath)
+		require.ErrorIs(t, err, ErrDeliveryFailed)
+	})
+
+	t.Run("rejects a wrong password", func(t *testing.T) {
+		config := newConfig()
+		*config.MessageExportSettings.DeliverySettings.SFTPPassword = "wrong"
+
+		_, err := New(config, false).Deliver(rctx, backend, batchPath)
+		require.ErrorIs(t, err, ErrDeliveryFailed)
+	})
+}
@@ -0,0 +1,462 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.enterprise for license information.
+
+package eml_export
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	gomail "gopkg.in/mail.v2"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/enterprise/internal/file"
+	"github.com/mattermost/mattermost/server/v8/enterprise/message_export/shared"
+	"github.com/mattermost/mattermost/server/v8/platform/shared/filestore"
+)
+
+const (
+	EmlWarningFilename   = "warning.txt"
+	TeamNameHeader       = "X-Mattermost-TeamName"
+	ChannelIDHeader      = "X-Mattermost-ChannelID"
+	ChannelNameHeader    = "X-Mattermost-ChannelName"
+	ChannelTypeHeader    = "X-Mattermost-ChannelType"
+	PostIDHeader         = "X-Mattermost-PostID"
+	UpdateTypeHeader     = "X-Mattermost-UpdateType"
+	EditedByPostIDHeader = "X-Mattermost-EditedByPostID"
+
+	defaultMessageIDHost = "mattermost"
+	channelDayLayout     = "2006-01-02"
+	transcriptTimeLayout = "15:04:05 MST"
+)
+
+// entry is an event of a channel written to a channel-day message: a post, a file upload or
+// deletion, or a member joining or leaving the channel.
+type entry struct {
+	time   int64
+	postId string
+	text   string
+	files  []*model.FileInfo
+	sender *sender
+}
+
+type sender struct {
+	username string
+	email    string
+}
+
+// emlExporter writes the messages of a batch to a zip file, one .eml file per message.
+type emlExporter struct {
+	rctx          request.CTX
+	p             shared.ExportParams
+	zipFile       *zip.Writer
+	messageIDHost string
+	missingFiles  []string
+}
+
+// EmlExport writes the batch as RFC 5322 messages, which most archiving tools ingest, in a zip
+// file. Depending on the configuration, each post gets its own message, or each channel gets a
+// message per UTC day holding a transcript of its activity. The attachments of the posts are
+// embedded in the messages.
+func EmlExport(rctx request.CTX, p shared.ExportParams) (shared.RunExportResults, error) {
+	exportData, err := shared.GetGenericExportData(p)
+	results := exportData.Results
+	if err != nil {
+		return results, err
+	}
+
+	temp, err := os.CreateTemp("", "compliance-export-batch-*.zip")
+	if err != nil {
+		return results, fmt.Errorf("unable to create temporary EML export file: %w", err)
+	}
+	defer file.DeleteTemp(rctx.Logger(), temp)
+
+	e := &emlExporter{
+		rctx:          rctx,
+		p:             p,
+		zipFile:       zip.NewWriter(temp),
+		messageIDHost: getMessageIDHost(p.Config),
+	}
+
+	grouping := model.EmlExportGroupingPost
+	if p.Config != nil && p.Config.MessageExportSettings.EmlSettings != nil && p.Config.MessageExportSettings.EmlSettings.Grouping != nil {
+		grouping = *p.Config.MessageExportSettings.EmlSettings.Grouping
+	}
+
+	for _, channel := range exportData.Exports {
+		if grouping == model.EmlExportGroupingChannelDay {
+			err = e.exportChannelDays(channel)
+		} else {
+			err = e.exportPosts(channel)
+		}
+		if err != nil {
+			return results, err
+		}
+	}
+
+	results.NumWarnings = len(e.missingFiles)
+	if results.NumWarnings > 0 {
+		warningFile, err := e.zipFile.Create(EmlWarningFilename)
+		if err != nil {
+			return results, fmt.Errorf("unable to create the warning file: %w", err)
+		}
+		for _, value := range e.missingFiles {
+			if _, err = warningFile.Write([]byte(value + "\n")); err != nil {
+				return results, fmt.Errorf("unable to create the warning file: %w", err)
+			}
+		}
+	}
+
+	metadataFile, err := e.zipFile.Create("metadata.json")
+	if err != nil {
+		return results, fmt.Errorf("unable to create the zip file: %w", err)
+	}
+	data, err := json.MarshalIndent(exportData.Metadata, "", "  ")
+	if err != nil {
+		return results, fmt.Errorf("unable to convert metadata to json: %w", err)
+	}
+	if _, err = metadataFile.Write(data); err != nil {
+		return results, fmt.Errorf("unable to add metadata file to the zip file: %w", err)
+	}
+
+	if err = e.zipFile.Close(); err != nil {
+		return results, fmt.Errorf("unable to close the zip file: %w", err)
+	}
+
+	if _, err = temp.Seek(0, 0); err != nil {
+		return results, fmt.Errorf("unable to seek to start of export file: %w", err)
+	}
+
+	// Try to write the file without a timeout due to the potential size of the file.
+	if _, err = filestore.TryWriteFileContext(rctx.Context(), p.ExportBackend, temp, p.BatchPath); err != nil {
+		return results, fmt.Errorf("unable to write the eml file: %w", err)
+	}
+
+	return results, nil
+}
+
+// exportPosts writes a message for each post of the channel. Edits and deletions are written
+// as messages of their own, referencing the message of the post they changed.
+func (e *emlExporter) exportPosts(channel shared.ChannelExport) error {
+	for _, post := range channel.Posts {
+		postId := model.SafeDereference(post.PostId)
+		sentAt := postTime(post)
+
+		m := e.newMessage(channel, participantEmails(channel.JoinEvents, sentAt, sentAt, post))
+		m.SetAddressHeader("From", model.SafeDereference(post.UserEmail), model.SafeDereference(post.Username))
+		m.SetHeader("Subject", fmt.Sprintf("%s in %s", postSubject(post.UpdatedType), channel.DisplayName))
+		m.SetDateHeader("Date", time.UnixMilli(sentAt).UTC())
+		m.SetHeader(PostIDHeader, postId)
+
+		var references []string
+		if rootId := model.SafeDereference(post.PostRootId); rootId != "" {
+			m.SetHeader("In-Reply-To", e.messageID(rootId))
+			references = append(references, e.messageID(rootId))
+		}
+
+		switch post.UpdatedType {
+		case "":
+			m.SetHeader("Message-ID", e.messageID(postId))
+		case shared.EditedOriginalMsg:
+			// The original message of an edited post is saved under a new id.
+			m.SetHeader("Message-ID", e.messageID(postId))
+			m.SetHeader(EditedByPostIDHeader, post.EditedNewMsgId)
+			references = append(references, e.messageID(post.EditedNewMsgId))
+		default:
+			m.SetHeader("Message-ID", e.messageID(fmt.Sprintf("%s.%d", postId, post.UpdateAt)))
+			references = append(references, e.messageID(postId))
+		}
+		if post.UpdatedType != "" {
+			m.SetHeader(UpdateTypeHeader, string(post.UpdatedType))
+		}
+		if len(references) > 0 {
+			m.SetHeader("References", strings.Join(references, " "))
+		}
+
+		body := []string{postMessage(post)}
+		if previewId := post.PreviewID(); previewId != "" {
+			body = append(body, "", "Previews post "+previewId)
+		}
+		for _, deleted := range post.AttachmentDeletes {
+			body = append(body, "", "Deleted file "+deleted.FileInfo.Name)
+		}
+		m.SetBody("text/plain", strings.Join(body, "\n"))
+
+		for _, upload := range post.AttachmentCreates {
+			e.attach(m, postId, upload.FileInfo)
+		}
+
+		name := fmt.Sprintf("%d-%s", sentAt, postId)
+		if post.UpdatedType != "" {
+			name += "-" + string(post.UpdatedType)
+		}
+		if err := e.write(m, path.Join(channel.ChannelId, name+".eml")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// exportChannelDays writes a message for each UTC day with activity in the channel, holding a
+// transcript of the posts, file uploads and deletions, joins and leaves of that day.
+func (e *emlExporter) exportChannelDays(channel shared.ChannelExport) error {
+	entriesByDay := make(map[string][]entry)
+	var days []string
+	addEntry := func(en entry) {
+		day := time.UnixMilli(en.time).UTC().Format(channelDayLayout)
+		if _, ok := entriesByDay[day]; !ok {
+			days = append(days, day)
+		}
+		entriesByDay[day] = append(entriesByDay[day], en)
+	}
+
+	for _, join := range channel.JoinEvents {
+		text := fmt.Sprintf("%s (%s) joined the channel", join.Username, join.UserEmail)
+		joinTime := join.JoinTime
+		if joinTime <= channel.StartTime {
+			text = fmt.Sprintf("%s (%s) was already in the channel", join.Username, join.UserEmail)
+			joinTime = channel.StartTime
+		}
+		addEntry(entry{time: joinTime, text: text})
+	}
+	for _, leave := range channel.LeaveEvents {
+		if leave.ClosedOut {
+			// The leaves closing out the channel at the end of the batch are not real events.
+			continue
+		}
+		addEntry(entry{time: leave.LeaveTime, text: fmt.Sprintf("%s (%s) left the channel", leave.Username, leave.UserEmail)})
+	}
+	for _, post := range channel.Posts {
+		postSender := &sender{username: model.SafeDereference(post.Username), email: model.SafeDereference(post.UserEmail)}
+		postId := model.SafeDereference(post.PostId)
+
+		addEntry(entry{
+			time:   postTime(post),
+			postId: postId,
+			text:   fmt.Sprintf("%s (%s): %s%s", postSender.username, postSender.email, postMessage(post), postAnnotation(post)),
+			sender: postSender,
+		})
+		for _, upload := range post.AttachmentCreates {
+			addEntry(entry{
+				time:   model.SafeDereference(post.PostCreateAt),
+				postId: postId,
+				text:   fmt.Sprintf("%s (%s) uploaded file %s", postSender.username, postSender.email, upload.FileInfo.Name),
+				files:  []*model.FileInfo{upload.FileInfo},
+				sender: postSender,
+			})
+		}
+		for _, deleted := range post.AttachmentDeletes {
+			addEntry(entry{
+				time:   deleted.FileInfo.DeleteAt,
+				postId: postId,
+				text:   fmt.Sprintf("%s (%s) deleted file %s", postSender.username, postSender.email, deleted.FileInfo.Name),
+				sender: postSender,
+			})
+		}
+	}
+
+	slices.Sort(days)
+	for _, day := range days {
+		entries := entriesByDay[day]
+		slices.SortStableFunc(entries, func(a, b entry) int {
+			if a.time == b.time {
+				return strings.Compare(a.postId, b.postId)
+			}
+			return int(a.time - b.time)
+		})
+
+		dayStart, err := time.Parse(channelDayLayout, day)
+		if err != nil {
+			return fmt.Errorf("unable to parse the export day %s: %w", day, err)
+		}
+		from := dayStart.UnixMilli()
+		to := dayStart.Add(24*time.Hour).UnixMilli() - 1
+
+		participants := participantEmails(channel.JoinEvents, from, to, shared.PostExport{})
+		m := e.newMessage(channel, participants)
+		m.SetHeader("Subject", fmt.Sprintf("Mattermost Compliance Export: %s - %s", channel.DisplayName, day))
+		m.SetDateHeader("Date", time.UnixMilli(entries[len(entries)-1].time).UTC())
+		m.SetHeader("Message-ID", e.messageID(fmt.Sprintf("%s.%s.%d", channel.ChannelId, day, e.p.BatchEndTime)))
+
+		body := make([]string, 0, len(entries)+2)
+		body = append(body, fmt.Sprintf("%s, %s", channel.DisplayName, day), "")
+		var fromSet bool
+		for _, en := range entries {
+			body = append(body, fmt.Sprintf("[%s] %s", time.UnixMilli(en.time).UTC().Format(transcriptTimeLayout), en.text))
+			if en.sender != nil && !fromSet {
+				m.SetAddressHeader("From", en.sender.email, en.sender.username)
+				fromSet = true
+			}
+			for _, fileInfo := range en.files {
+				e.attach(m, en.postId, fileInfo)
+			}
+		}
+		if !fromSet && len(participants) > 0 {
+			// Nobody posted on a day with joins and leaves only, so use the first participant.
+			m.SetHeader("From", participants[0])
+		}
+		m.SetBody("text/plain", strings.Join(body, "\n"))
+
+		if err := e.write(m, path.Join(channel.ChannelId, day+".eml")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *emlExporter) newMessage(channel shared.ChannelExport, to []string) *gomail.Message {
+	m := gomail.NewMessage(gomail.SetCharset("UTF-8"))
+	m.SetHeaders(map[string][]string{
+		"Auto-Submitted":  {"auto-generated"},
+		ChannelIDHeader:   {channel.ChannelId},
+		ChannelNameHeader: {channel.DisplayName},
+		ChannelTypeHeader: {shared.ChannelTypeDisplayName(channel.ChannelType)},
+	})
+	if channel.TeamName != "" {
+		m.SetHeader(TeamNameHeader, channel.TeamName)
+	}
+	if len(to) > 0 {
+		m.SetHeader("To", to...)
+	}
+	return m
+}
+
+// attach embeds a file in the message. The files missing from the file store are recorded as
+// warnings rather than failing the export, as every following export would fail on them too.
+func (e *emlExporter) attach(m *gomail.Message, postId string, fileInfo *model.FileInfo) {
+	filePath := fileInfo.Path
+	if exists, err := e.p.FileAttachmentBackend.FileExists(filePath); err != nil || !exists {
+		e.addMissingFile(shared.MissingFileMessageDuringBackendRead, postId, filePath, err)
+		return
+	}
+
+	m.Attach(fileInfo.Name, gomail.SetCopyFunc(func(w io.Writer) error {
+		r, err := e.p.FileAttachmentBackend.Reader(filePath)
+		if err != nil {
+			e.addMissingFile(shared.MissingFileMessageDuringBackendRead, postId, filePath, err)
+			return nil
+		}
+		defer r.Close()
+
+		if _, err = io.Copy(w, r); err != nil {
+			e.addMissingFile(shared.MissingFileMessageDuringCopy, postId, filePath, err)
+		}
+		return nil
+	}))
+}
+
+func (e *emlExporter) addMissingFile(message, postId, filePath string, err error) {
+	e.missingFiles = append(e.missingFiles, "Warning:"+message+" - Post: "+postId+" - "+filePath)
+	e.rctx.Logger().Warn(message,
+		mlog.String("post_id", postId),
+		mlog.String("filename", filePath),
+		mlog.Err(err),
+	)
+}
+
+func (e *emlExporter) write(m *gomail.Message, name string) error {
+	w, err := e.zipFile.Create(name)
+	if err != nil {
+		return fmt.Errorf("unable to create the eml file: %w", err)
+	}
+
+	if _, err = m.WriteTo(w); err != nil {
+		return fmt.Errorf("unable to generate eml file data: %w", err)
+	}
+
+	return nil
+}
+
+func (e *emlExporter) messageID(id string) string {
+	return fmt.Sprintf("<%s@%s>", id, e.messageIDHost)
+}
+
+// getMessageIDHost returns the host of the site URL, which makes the message ids unique
+// across servers.
+func getMessageIDHost(config *model.Config) string {
+	if config == nil || config.ServiceSettings.SiteURL == nil {
+		return defaultMessageIDHost
+	}
+
+	siteURL, err := url.Parse(*config.ServiceSettings.SiteURL)
+	if err != nil || siteURL.Hostname() == "" {
+		return defaultMessageIDHost
+	}
+
+	return siteURL.Hostname()
+}
+
+// participantEmails returns the emails of the members of the channel between from and to,
+// along with the author of the post if they were not a member.
+func participantEmails(joins []shared.JoinExport, from, to int64, post shared.PostExport) []string {
+	var emails []string
+	for _, join := range joins {
+		if join.JoinTime <= to && join.LeaveTime >= from && join.UserEmail != "" && !slices.Contains(emails, join.UserEmail) {
+			emails = append(emails, join.UserEmail)
+		}
+	}
+
+	if email := model.SafeDereference(post.UserEmail); email != "" && !slices.Contains(emails, email) {
+		emails = append(emails, email)
+	}
+
+	return emails
+}
+
+// postTime returns when the post was created, or for an update, when it was updated.
+func postTime(post shared.PostExport) int64 {
+	if post.UpdatedType != "" && post.UpdateAt > 0 {
+		return post.UpdateAt
+	}
+	return model.SafeDereference(post.PostCreateAt)
+}
+
+// postMessage returns the message of the post. The deleted posts keep the message they had.
+func postMessage(post shared.PostExport) string {
+	if post.UpdatedType == shared.Deleted {
+		return model.SafeDereference(post.PostMessage)
+	}
+	return post.Message
+}
+
+func postSubject(updatedType shared.PostUpdatedType) string {
+	switch updatedType {
+	case shared.EditedOriginalMsg:
+		return "Original message"
+	case shared.EditedNewMsg:
+		return "Edited message"
+	case shared.Deleted:
+		return "Deleted message"
+	case shared.UpdatedNoMsgChange:
+		return "Updated message"
+	default:
+		return "Message"
+	}
+}
+
+func postAnnotation(post shared.PostExport) string {
+	switch post.UpdatedType {
+	case shared.EditedOriginalMsg:
+		return fmt.Sprintf(" (original message, edited by post %s)", post.EditedNewMsgId)
+	case shared.EditedNewMsg:
+		return " (edited)"
+	case shared.Deleted:
+		return " (deleted)"
+	case shared.UpdatedNoMsgChange:
+		return " (updated)"
+	default:
+		return ""
+	}
+}
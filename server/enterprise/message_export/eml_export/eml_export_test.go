@@ -0,0 +1,305 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.enterprise for license information.
+
+package eml_export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/channels/store/storetest"
+	"github.com/mattermost/mattermost/server/v8/enterprise/message_export/shared"
+	"github.com/mattermost/mattermost/server/v8/platform/shared/filestore"
+)
+
+// 2024-01-01T10:00:00Z and 2024-01-02T10:00:00Z
+const (
+	day1 = int64(1704103200000)
+	day2 = int64(1704189600000)
+)
+
+func newTestPost(id string, createAt int64, message string) *model.MessageExport {
+	chanType := model.ChannelTypeOpen
+	return &model.MessageExport{
+		PostId:             model.NewPointer(id),
+		PostOriginalId:     model.NewPointer(""),
+		TeamId:             model.NewPointer("team-id"),
+		TeamName:           model.NewPointer("team-name"),
+		TeamDisplayName:    model.NewPointer("team-display-name"),
+		ChannelId:          model.NewPointer("channel-id"),
+		ChannelName:        model.NewPointer("channel-name"),
+		ChannelDisplayName: model.NewPointer("Channel Display Name"),
+		ChannelType:        &chanType,
+		PostCreateAt:       model.NewPointer(createAt),
+		PostUpdateAt:       model.NewPointer(createAt),
+		PostMessage:        model.NewPointer(message),
+		PostProps:          model.NewPointer("{}"),
+		UserEmail:          model.NewPointer("user1@example.com"),
+		UserId:             model.NewPointer("user1"),
+		Username:           model.NewPointer("user1"),
+		PostFileIds:        []string{},
+	}
+}
+
+func runTestEmlExport(t *testing.T, grouping string, posts []*model.MessageExport, attachments map[string][]*model.FileInfo, files map[string]string) (map[string]*mail.Message, shared.RunExportResults) {
+	t.Helper()
+	rctx := request.TestContext(t)
+
+	backend, err := filestore.NewFileBackend(filestore.FileBackendSettings{
+		DriverName: model.ImageDriverLocal,
+		Directory:  t.TempDir(),
+	})
+	require.NoError(t, err)
+	for filePath, content := range files {
+		_, err = backend.WriteFile(strings.NewReader(content), filePath)
+		require.NoError(t, err)
+	}
+
+	mockStore := &storetest.Store{}
+	defer mockStore.AssertExpectations(t)
+	for postId, fileInfos := range attachments {
+		mockStore.FileInfoStore.On("GetForPost", postId, true, true, false).Return(fileInfos, nil)
+	}
+
+	cfg := &model.Config{}
+	cfg.SetDefaults()
+	*cfg.ServiceSettings.SiteURL = "https://chat.example.com"
+	*cfg.MessageExportSettings.EmlSettings.Grouping = grouping
+
+	batchPath := "export/batch001.zip"
+	results, err := EmlExport(rctx, shared.ExportParams{
+		ChannelMetadata: map[string]*shared.MetadataChannel{
+			"channel-id": {
+				TeamId:             model.NewPointer("team-id"),
+				ChannelId:          "channel-id",
+				ChannelName:        "channel-name",
+				ChannelDisplayName: "Channel Display Name",
+				ChannelType:        model.ChannelTypeOpen,
+			},
+		},
+		ChannelMemberHistories: map[string][]*model.ChannelMemberHistoryResult{
+			"channel-id": {
+				{JoinTime: 0, UserId: "user1", UserEmail: "user1@example.com", Username: "user1"},
+				{JoinTime: day2, UserId: "user2", UserEmail: "user2@example.com", Username: "user2"},
+			},
+		},
+		Posts:                 posts,
+		JobStartTime:          day1 - 1000,
+		BatchPath:             batchPath,
+		BatchStartTime:        day1 - 1000,
+		BatchEndTime:          day2 + 1000,
+		Config:                cfg,
+		Db:                    shared.NewMessageExportStore(mockStore),
+		FileAttachmentBackend: backend,
+		ExportBackend:         backend,
+	})
+	require.NoError(t, err)
+
+	zipBytes, err := backend.ReadFile(batchPath)
+	require.NoError(t, err)
+	zipReader, err := zip.NewReader(bytes.NewReader(zipBytes), int64(len(zipBytes)))
+	require.NoError(t, err)
+
+	messages := make(map[string]*mail.Message)
+	for _, f := range zipReader.File {
+		if !strings.HasSuffix(f.Name, ".eml") {
+			continue
+		}
+		r, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.NoError(t, r.Close())
+
+		msg, err := mail.ReadMessage(bytes.NewReader(data))
+		require.NoError(t, err)
+		messages[f.Name] = msg
+	}
+
+	return messages, results
+}
+
+func decodePart(t *testing.T, header map[string][]string, body io.Reader) string {
+	t.Helper()
+	switch mail.Header(header).Get("Content-Transfer-Encoding") {
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	}
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+	return strings.ReplaceAll(string(data), "\r\n", "\n")
+}
+
+// readParts returns the text body of the message and the content of its attachments by name.
+func readParts(t *testing.T, msg *mail.Message) (string, map[string]string) {
+	t.Helper()
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		return decodePart(t, msg.Header, msg.Body), nil
+	}
+
+	var body string
+	attachments := make(map[string]string)
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		if name := part.FileName(); name != "" {
+			attachments[name] = decodePart(t, part.Header, part)
+		} else {
+			body = decodePart(t, part.Header, part)
+		}
+	}
+	return body, attachments
+}
+
+func TestEmlExportPerPost(t *testing.T) {
+	created := newTestPost("post1", day1, "hello")
+
+	reply := newTestPost("post2", day1+1000, "a reply")
+	reply.PostRootId = model.NewPointer("post1")
+
+	edited := newTestPost("post3", day1+2000, "edited message")
+	edited.PostUpdateAt = model.NewPointer(day1 + 3000)
+	edited.PostEditAt = model.NewPointer(day1 + 3000)
+
+	original := newTestPost("post4", day1+2000, "original message")
+	original.PostUpdateAt = model.NewPointer(day1 + 3000)
+	original.PostDeleteAt = model.NewPointer(day1 + 3000)
+	original.PostOriginalId = model.NewPointer("post3")
+
+	deleted := newTestPost("post5", day1-5000, "deleted message")
+	deleted.PostUpdateAt = model.NewPointer(day1 + 4000)
+	deleted.PostDeleteAt = model.NewPointer(day1 + 4000)
+	deleted.PostProps = model.NewPointer(`{"deleteBy":"user1"}`)
+
+	messages, results := runTestEmlExport(t, model.EmlExportGroupingPost, []*model.MessageExport{created, reply, edited, original, deleted}, nil, nil)
+	assert.Equal(t, 0, results.NumWarnings)
+	require.Len(t, messages, 5)
+
+	msg := messages["channel-id/1704103200000-post1.eml"]
+	require.NotNil(t, msg)
+	assert.Equal(t, `"user1" <user1@example.com>`, msg.Header.Get("From"))
+	assert.Equal(t, "user1@example.com", msg.Header.Get("To"))
+	assert.Equal(t, "Message in Channel Display Name", msg.Header.Get("Subject"))
+	assert.Equal(t, "<post1@chat.example.com>", msg.Header.Get("Message-ID"))
+	assert.Equal(t, "channel-id", msg.Header.Get(ChannelIDHeader))
+	assert.Equal(t, "team-name", msg.Header.Get(TeamNameHeader))
+	assert.Empty(t, msg.Header.Get(UpdateTypeHeader))
+	date, err := msg.Header.Date()
+	require.NoError(t, err)
+	assert.Equal(t, day1, date.UnixMilli())
+	body, _ := readParts(t, msg)
+	assert.Equal(t, "hello", body)
+
+	msg = messages["channel-id/1704103201000-post2.eml"]
+	require.NotNil(t, msg)
+	assert.Equal(t, "<post1@chat.example.com>", msg.Header.Get("In-Reply-To"))
+	assert.Equal(t, "<post1@chat.example.com>", msg.Header.Get("References"))
+
+	msg = messages["channel-id/1704103203000-post3-EditedNewMsg.eml"]
+	require.NotNil(t, msg)
+	assert.Equal(t, "Edited message in Channel Display Name", msg.Header.Get("Subject"))
+	assert.Equal(t, "<post3.1704103203000@chat.example.com>", msg.Header.Get("Message-ID"))
+	assert.Equal(t, "<post3@chat.example.com>", msg.Header.Get("References"))
+	assert.Equal(t, string(shared.EditedNewMsg), msg.Header.Get(UpdateTypeHeader))
+
+	msg = messages["channel-id/1704103203000-post4-EditedOriginalMsg.eml"]
+	require.NotNil(t, msg)
+	assert.Equal(t, "<post4@chat.example.com>", msg.Header.Get("Message-ID"))
+	assert.Equal(t, "post3", msg.Header.Get(EditedByPostIDHeader))
+	body, _ = readParts(t, msg)
+	assert.Equal(t, "original message", body)
+
+	msg = messages["channel-id/1704103204000-post5-Deleted.eml"]
+	require.NotNil(t, msg)
+	assert.Equal(t, "Deleted message in Channel Display Name", msg.Header.Get("Subject"))
+	assert.Equal(t, string(shared.Deleted), msg.Header.Get(UpdateTypeHeader))
+	body, _ = readParts(t, msg)
+	assert.Equal(t, "deleted message", body)
+}
+
+func TestEmlExportPerChannelDay(t *testing.T) {
+	post1 := newTestPost("post1", day1, "hello")
+	post2 := newTestPost("post2", day2+500, "hello again")
+	post2.UserEmail = model.NewPointer("user2@example.com")
+	post2.UserId = model.NewPointer("user2")
+	post2.Username = model.NewPointer("user2")
+
+	messages, results := runTestEmlExport(t, model.EmlExportGroupingChannelDay, []*model.MessageExport{post1, post2}, nil, nil)
+	assert.Equal(t, 0, results.NumWarnings)
+	require.Len(t, messages, 2)
+
+	msg := messages["channel-id/2024-01-01.eml"]
+	require.NotNil(t, msg)
+	assert.Equal(t, "Mattermost Compliance Export: Channel Display Name - 2024-01-01", msg.Header.Get("Subject"))
+	assert.Equal(t, `"user1" <user1@example.com>`, msg.Header.Get("From"))
+	assert.Equal(t, "user1@example.com", msg.Header.Get("To"))
+	assert.Equal(t, "<channel-id.2024-01-01.1704189601000@chat.example.com>", msg.Header.Get("Message-ID"))
+	body, _ := readParts(t, msg)
+	assert.Equal(t, strings.Join([]string{
+		"Channel Display Name, 2024-01-01",
+		"",
+		"[09:59:59 UTC] user1 (user1@example.com) was already in the channel",
+		"[10:00:00 UTC] user1 (user1@example.com): hello",
+	}, "\n"), body)
+
+	msg = messages["channel-id/2024-01-02.eml"]
+	require.NotNil(t, msg)
+	assert.Equal(t, `"user2" <user2@example.com>`, msg.Header.Get("From"))
+	assert.Equal(t, "user1@example.com, user2@example.com", msg.Header.Get("To"))
+	body, _ = readParts(t, msg)
+	assert.Equal(t, strings.Join([]string{
+		"Channel Display Name, 2024-01-02",
+		"",
+		"[10:00:00 UTC] user2 (user2@example.com) joined the channel",
+		"[10:00:00 UTC] user2 (user2@example.com): hello again",
+	}, "\n"), body)
+}
+
+func TestEmlExportAttachments(t *testing.T) {
+	post := newTestPost("post1", day1, "see attached")
+	post.PostFileIds = []string{"file1", "file2"}
+
+	attachments := map[string][]*model.FileInfo{
+		"post1": {
+			{Id: "file1", Name: "report.txt", Path: "data/report.txt"},
+			{Id: "file2", Name: "missing.txt", Path: "data/missing.txt"},
+		},
+	}
+
+	for grouping, name := range map[string]string{
+		model.EmlExportGroupingPost:       "channel-id/1704103200000-post1.eml",
+		model.EmlExportGroupingChannelDay: "channel-id/2024-01-01.eml",
+	} {
+		t.Run(grouping, func(t *testing.T) {
+			messages, results := runTestEmlExport(t, grouping, []*model.MessageExport{post}, attachments, map[string]string{"data/report.txt": "report content"})
+			assert.Equal(t, 1, results.NumWarnings)
+
+			msg := messages[name]
+			require.NotNil(t, msg)
+			_, files := readParts(t, msg)
+			assert.Equal(t, "report content", files["report.txt"])
+			assert.NotContains(t, files, "missing.txt")
+		})
+	}
+}
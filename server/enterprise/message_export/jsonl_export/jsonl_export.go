@@ -0,0 +1,445 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.enterprise for license information.
+
+package jsonl_export
+
+import (
+	"archive/zip"
+	"cmp"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/enterprise/internal/file"
+	"github.com/mattermost/mattermost/server/v8/enterprise/message_export/shared"
+	"github.com/mattermost/mattermost/server/v8/platform/shared/filestore"
+)
+
+const (
+	RecordsFilename      = "records.jsonl"
+	SchemaFilename       = "schema.json"
+	JsonlWarningFilename = "warning.txt"
+)
+
+// Schema is the JSON Schema the records of the export conform to. It is written to every
+// export, along with the records.
+//
+//go:embed schema.json
+var Schema []byte
+
+// record is a record of the export, along with what it is sorted by.
+type record struct {
+	time  int64
+	id    string
+	value any
+}
+
+// postExtras holds the data of the posts of a batch that is not part of the message export
+// query: their reactions, priority and acknowledgements, by post id.
+type postExtras struct {
+	reactions        map[string][]Reaction
+	priorities       map[string]*Priority
+	acknowledgements map[string][]Acknowledgement
+}
+
+// JsonlExport writes the batch as a JSON Lines file of typed records, one per post, join,
+// leave and file, in a zip file along with the content of the uploaded files and the JSON
+// Schema of the records.
+func JsonlExport(rctx request.CTX, p shared.ExportParams) (shared.RunExportResults, error) {
+	exportData, err := shared.GetGenericExportData(p)
+	results := exportData.Results
+	if err != nil {
+		return results, err
+	}
+
+	extras, err := getPostExtras(p.Db, p.Posts)
+	if err != nil {
+		return results, err
+	}
+
+	temp, err := os.CreateTemp("", "compliance-export-batch-*.zip")
+	if err != nil {
+		return results, fmt.Errorf("unable to create temporary JSONL export file: %w", err)
+	}
+	defer file.DeleteTemp(rctx.Logger(), temp)
+
+	zipFile := zip.NewWriter(temp)
+
+	// The files are archived first, so that their records can point to them.
+	archivePaths, missingFiles := writeAttachments(rctx, zipFile, p.FileAttachmentBackend, exportData.Exports)
+
+	var records []record
+	for _, channel := range exportData.Exports {
+		records = append(records, membershipRecords(channel)...)
+
+		for _, post := range channel.Posts {
+			records = append(records, postRecord(channel, post, extras))
+
+			for _, upload := range post.AttachmentCreates {
+				records = append(records, fileRecord(channel, post, upload.FileInfo, model.SafeDereference(post.PostCreateAt), false, archivePaths[upload.FileInfo.Id]))
+			}
+			for _, deleted := range post.AttachmentDeletes {
+				records = append(records, fileRecord(channel, post, deleted.FileInfo, deleted.FileInfo.DeleteAt, true, ""))
+			}
+		}
+	}
+
+	slices.SortStableFunc(records, func(a, b record) int {
+		if a.time == b.time {
+			return strings.Compare(a.id, b.id)
+		}
+		return cmp.Compare(a.time, b.time)
+	})
+
+	recordsFile, err := zipFile.Create(RecordsFilename)
+	if err != nil {
+		return results, fmt.Errorf("unable to create the records file: %w", err)
+	}
+	encoder := json.NewEncoder(recordsFile)
+	encoder.SetEscapeHTML(false)
+	for _, r := range records {
+		if err = encoder.Encode(r.value); err != nil {
+			return results, fmt.Errorf("unable to export a record: %w", err)
+		}
+	}
+
+	schemaFile, err := zipFile.Create(SchemaFilename)
+	if err != nil {
+		return results, fmt.Errorf("unable to create the schema file: %w", err)
+	}
+	if _, err = schemaFile.Write(Schema); err != nil {
+		return results, fmt.Errorf("unable to add the schema file to the zip file: %w", err)
+	}
+
+	results.NumWarnings = len(missingFiles)
+	if results.NumWarnings > 0 {
+		warningFile, err := zipFile.Create(JsonlWarningFilename)
+		if err != nil {
+			return results, fmt.Errorf("unable to create the warning file: %w", err)
+		}
+		for _, value := range missingFiles {
+			if _, err = warningFile.Write([]byte(value + "\n")); err != nil {
+				return results, fmt.Errorf("unable to create the warning file: %w", err)
+			}
+		}
+	}
+
+	metadataFile, err := zipFile.Create("metadata.json")
+	if err != nil {
+		return results, fmt.Errorf("unable to create the zip file: %w", err)
+	}
+	data, err := json.MarshalIndent(exportData.Metadata, "", "  ")
+	if err != nil {
+		return results, fmt.Errorf("unable to convert metadata to json: %w", err)
+	}
+	if _, err = metadataFile.Write(data); err != nil {
+		return results, fmt.Errorf("unable to add metadata file to the zip file: %w", err)
+	}
+
+	if err = zipFile.Close(); err != nil {
+		return results, fmt.Errorf("unable to close the zip file: %w", err)
+	}
+
+	if _, err = temp.Seek(0, 0); err != nil {
+		return results, fmt.Errorf("unable to seek to start of export file: %w", err)
+	}
+
+	// Try to write the file without a timeout due to the potential size of the file.
+	if _, err = filestore.TryWriteFileContext(rctx.Context(), p.ExportBackend, temp, p.BatchPath); err != nil {
+		return results, fmt.Errorf("unable to write the jsonl file: %w", err)
+	}
+
+	return results, nil
+}
+
+func getPostExtras(db shared.MessageExportStore, posts []*model.MessageExport) (postExtras, error) {
+	extras := postExtras{
+		reactions:        make(map[string][]Reaction),
+		priorities:       make(map[string]*Priority),
+		acknowledgements: make(map[string][]Acknowledgement),
+	}
+
+	// The original messages of the edited posts take the extras of the edited post.
+	seen := make(map[string]bool, len(posts))
+	postIds := make([]string, 0, len(posts))
+	for _, post := range posts {
+		for _, postId := range []string{model.SafeDereference(post.PostId), model.SafeDereference(post.PostOriginalId)} {
+			if postId != "" && !seen[postId] {
+				seen[postId] = true
+				postIds = append(postIds, postId)
+			}
+		}
+	}
+	if len(postIds) == 0 {
+		return extras, nil
+	}
+
+	reactions, err := db.Reaction().BulkGetForPosts(postIds)
+	if err != nil {
+		return extras, fmt.Errorf("unable to get the reactions of the posts: %w", err)
+	}
+	for _, reaction := range reactions {
+		extras.reactions[reaction.PostId] = append(extras.reactions[reaction.PostId], Reaction{
+			UserId:    reaction.UserId,
+			EmojiName: reaction.EmojiName,
+			CreateAt:  reaction.CreateAt,
+		})
+	}
+
+	priorities, err := db.PostPriority().GetForPosts(postIds)
+	if err != nil {
+		return extras, fmt.Errorf("unable to get the priority of the posts: %w", err)
+	}
+	for _, priority := range priorities {
+		extras.priorities[priority.PostId] = &Priority{
+			Priority:                model.SafeDereference(priority.Priority),
+			RequestedAck:            model.SafeDereference(priority.RequestedAck),
+			PersistentNotifications: model.SafeDereference(priority.PersistentNotifications),
+		}
+	}
+
+	acknowledgements, err := db.PostAcknowledgement().GetForPosts(postIds)
+	if err != nil {
+		return extras, fmt.Errorf("unable to get the acknowledgements of the posts: %w", err)
+	}
+	for _, ack := range acknowledgements {
+		extras.acknowledgements[ack.PostId] = append(extras.acknowledgements[ack.PostId], Acknowledgement{
+			UserId:         ack.UserId,
+			AcknowledgedAt: ack.AcknowledgedAt,
+		})
+	}
+
+	return extras, nil
+}
+
+// writeAttachments copies the uploaded files of the batch to the zip file, and returns the
+// path they were archived at by file id. The files missing from the file store are returned
+// as warnings rather than failing the export, as every following export would fail on them too.
+func writeAttachments(rctx request.CTX, zipFile *zip.Writer, backend filestore.FileBackend, exports []shared.ChannelExport) (map[string]string, []string) {
+	archivePaths := make(map[string]string)
+	var missingFiles []string
+
+	for _, channel := range exports {
+		for _, post := range channel.Posts {
+			postId := model.SafeDereference(post.PostId)
+			for _, upload := range post.AttachmentCreates {
+				fileInfo := upload.FileInfo
+				if _, ok := archivePaths[fileInfo.Id]; ok {
+					continue
+				}
+
+				r, err := backend.Reader(fileInfo.Path)
+				if err != nil {
+					missingFiles = append(missingFiles, "Warning:"+shared.MissingFileMessageDuringBackendRead+" - Post: "+postId+" - "+fileInfo.Path)
+					rctx.Logger().Warn(shared.MissingFileMessageDuringBackendRead,
+						mlog.String("post_id", postId),
+						mlog.String("filename", fileInfo.Path),
+						mlog.Err(err),
+					)
+					continue
+				}
+
+				archivePath := path.Join("files", postId, fmt.Sprintf("%s-%s", fileInfo.Id, path.Base(fileInfo.Path)))
+				if err = func() error {
+					defer r.Close()
+					w, err := zipFile.Create(archivePath)
+					if err != nil {
+						return err
+					}
+					_, err = io.Copy(w, r)
+					return err
+				}(); err != nil {
+					missingFiles = append(missingFiles, "Warning:"+shared.MissingFileMessageDuringCopy+" - Post: "+postId+" - "+fileInfo.Path)
+					rctx.Logger().Warn(shared.MissingFileMessageDuringCopy,
+						mlog.String("post_id", postId),
+						mlog.String("filename", fileInfo.Path),
+						mlog.Err(err),
+					)
+					continue
+				}
+
+				archivePaths[fileInfo.Id] = archivePath
+			}
+		}
+	}
+
+	return archivePaths, missingFiles
+}
+
+func newRecordHeader(recordType string, time int64, channel shared.ChannelExport, user User) RecordHeader {
+	header := RecordHeader{
+		Type:          recordType,
+		SchemaVersion: SchemaVersion,
+		Time:          time,
+		Channel: Channel{
+			Id:          channel.ChannelId,
+			Name:        channel.ChannelName,
+			DisplayName: channel.DisplayName,
+			Type:        shared.ChannelTypeDisplayName(channel.ChannelType),
+		},
+		User: user,
+	}
+
+	// Direct and group messages have no team.
+	if channel.TeamId != "" {
+		header.Team = &Team{
+			Id:          channel.TeamId,
+			Name:        channel.TeamName,
+			DisplayName: channel.TeamDisplayName,
+		}
+	}
+
+	return header
+}
+
+func postUser(post shared.PostExport) User {
+	return User{
+		Id:       model.SafeDereference(post.UserId),
+		Username: model.SafeDereference(post.Username),
+		Email:    model.SafeDereference(post.UserEmail),
+		Type:     string(post.UserType),
+	}
+}
+
+func membershipRecords(channel shared.ChannelExport) []record {
+	records := make([]record, 0, len(channel.JoinEvents)+len(channel.LeaveEvents))
+
+	for _, join := range channel.JoinEvents {
+		user := User{Id: join.UserId, Username: join.Username, Email: join.UserEmail, Type: string(join.UserType)}
+		records = append(records, record{
+			time: join.JoinTime,
+			value: MembershipRecord{
+				RecordHeader:     newRecordHeader(RecordTypeJoin, join.JoinTime, channel, user),
+				PreviouslyJoined: join.JoinTime <= channel.StartTime,
+			},
+		})
+	}
+
+	for _, leave := range channel.LeaveEvents {
+		if leave.ClosedOut {
+			// The leaves closing out the channel at the end of the batch are not real events.
+			continue
+		}
+		user := User{Id: leave.UserId, Username: leave.Username, Email: leave.UserEmail, Type: string(leave.UserType)}
+		records = append(records, record{
+			time:  leave.LeaveTime,
+			value: MembershipRecord{RecordHeader: newRecordHeader(RecordTypeLeave, leave.LeaveTime, channel, user)},
+		})
+	}
+
+	return records
+}
+
+func postRecord(channel shared.ChannelExport, post shared.PostExport, extras postExtras) record {
+	postId := model.SafeDereference(post.PostId)
+
+	// An update is recorded at the time of the update, and a new post at its creation time.
+	recordTime := model.SafeDereference(post.PostCreateAt)
+	if post.UpdatedType != "" && post.UpdateAt > 0 {
+		recordTime = post.UpdateAt
+	}
+
+	var props map[string]any
+	if post.PostProps != nil && *post.PostProps != "" {
+		if err := json.Unmarshal([]byte(*post.PostProps), &props); err != nil {
+			props = nil
+		}
+	}
+
+	// The original message of an edited post is saved under a new id, and points to the
+	// edited post. The reactions, priority and acknowledgements belong to the edited post.
+	extrasId := postId
+	if post.UpdatedType == shared.EditedOriginalMsg {
+		extrasId = post.EditedNewMsgId
+	}
+
+	fileIds := []string(post.PostFileIds)
+	if fileIds == nil {
+		fileIds = []string{}
+	}
+	reactions := extras.reactions[extrasId]
+	if reactions == nil {
+		reactions = []Reaction{}
+	}
+	acknowledgements := extras.acknowledgements[extrasId]
+	if acknowledgements == nil {
+		acknowledgements = []Acknowledgement{}
+	}
+
+	return record{
+		time: recordTime,
+		id:   postId,
+		value: PostRecord{
+			RecordHeader:     newRecordHeader(RecordTypePost, recordTime, channel, postUser(post)),
+			Id:               postId,
+			RootId:           model.SafeDereference(post.PostRootId),
+			OriginalId:       originalId(post),
+			CreateAt:         model.SafeDereference(post.PostCreateAt),
+			UpdateAt:         model.SafeDereference(post.PostUpdateAt),
+			EditAt:           model.SafeDereference(post.PostEditAt),
+			DeleteAt:         model.SafeDereference(post.PostDeleteAt),
+			UpdateType:       updateType(post.UpdatedType),
+			PostType:         model.SafeDereference(post.PostType),
+			Message:          model.SafeDereference(post.PostMessage),
+			Props:            props,
+			PreviewsPostId:   post.PreviewID(),
+			FileIds:          fileIds,
+			Reactions:        reactions,
+			Priority:         extras.priorities[extrasId],
+			Acknowledgements: acknowledgements,
+		},
+	}
+}
+
+func fileRecord(channel shared.ChannelExport, post shared.PostExport, fileInfo *model.FileInfo, time int64, deleted bool, archivePath string) record {
+	return record{
+		time: time,
+		id:   model.SafeDereference(post.PostId),
+		value: FileRecord{
+			RecordHeader: newRecordHeader(RecordTypeFile, time, channel, postUser(post)),
+			PostId:       model.SafeDereference(post.PostId),
+			Deleted:      deleted,
+			File: File{
+				Id:        fileInfo.Id,
+				Name:      fileInfo.Name,
+				Extension: fileInfo.Extension,
+				Size:      fileInfo.Size,
+				MimeType:  fileInfo.MimeType,
+				CreateAt:  fileInfo.CreateAt,
+				DeleteAt:  fileInfo.DeleteAt,
+			},
+			ArchivePath: archivePath,
+		},
+	}
+}
+
+// originalId returns the id of the edited post whose original message the post holds.
+func originalId(post shared.PostExport) string {
+	if post.UpdatedType == shared.EditedOriginalMsg {
+		return post.EditedNewMsgId
+	}
+	return ""
+}
+
+func updateType(updatedType shared.PostUpdatedType) string {
+	switch updatedType {
+	case shared.EditedOriginalMsg:
+		return UpdateTypeEditedOriginal
+	case shared.EditedNewMsg:
+		return UpdateTypeEdited
+	case shared.UpdatedNoMsgChange:
+		return UpdateTypeUpdated
+	case shared.Deleted:
+		return UpdateTypeDeleted
+	default:
+		return UpdateTypeCreated
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.enterprise for license information.
+
+package jsonl_export
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/channels/store/storetest"
+	"github.com/mattermost/mattermost/server/v8/enterprise/message_export/shared"
+	"github.com/mattermost/mattermost/server/v8/platform/shared/filestore"
+)
+
+func newTestPost(id string, createAt int64, message string) *model.MessageExport {
+	chanType := model.ChannelTypeOpen
+	return &model.MessageExport{
+		PostId:             model.NewPointer(id),
+		PostOriginalId:     model.NewPointer(""),
+		TeamId:             model.NewPointer("team-id"),
+		TeamName:           model.NewPointer("team-name"),
+		TeamDisplayName:    model.NewPointer("Team"),
+		ChannelId:          model.NewPointer("channel-id"),
+		ChannelName:        model.NewPointer("channel-name"),
+		ChannelDisplayName: model.NewPointer("Channel"),
+		ChannelType:        &chanType,
+		PostCreateAt:       model.NewPointer(createAt),
+		PostUpdateAt:       model.NewPointer(createAt),
+		PostMessage:        model.NewPointer(message),
+		PostType:           model.NewPointer(""),
+		PostProps:          model.NewPointer("{}"),
+		UserEmail:          model.NewPointer("user1@example.com"),
+		UserId:             model.NewPointer("user1"),
+		Username:           model.NewPointer("user1"),
+		PostFileIds:        []string{},
+	}
+}
+
+func readZipFile(t *testing.T, zipReader *zip.Reader, name string) []byte {
+	t.Helper()
+	f, err := zipReader.Open(name)
+	require.NoError(t, err)
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	require.NoError(t, err)
+	return data
+}
+
+// checkSchema checks that the fields of the record and of its nested objects are the ones
+// its definition in the schema allows and requires.
+func checkSchema(t *testing.T, defs map[string]map[string]any, defName string, value map[string]any) {
+	t.Helper()
+	def, ok := defs[defName]
+	require.True(t, ok, "unknown definition %s", defName)
+
+	properties := def["properties"].(map[string]any)
+	required, _ := def["required"].([]any)
+	if _, ok := def["allOf"]; ok {
+		header := defs["header"]
+		required = append(required, header["required"].([]any)...)
+	}
+
+	for _, field := range required {
+		assert.Contains(t, value, field.(string), "%s record misses the required %s field", defName, field)
+	}
+	for field := range value {
+		assert.Contains(t, properties, field, "%s record has the unknown %s field", defName, field)
+	}
+
+	for _, nested := range []string{"team", "channel", "user"} {
+		if nestedValue, ok := value[nested].(map[string]any); ok && defName != nested {
+			checkSchema(t, defs, nested, nestedValue)
+		}
+	}
+}
+
+func TestJsonlExport(t *testing.T) {
+	rctx := request.TestContext(t)
+
+	backend, err := filestore.NewFileBackend(filestore.FileBackendSettings{
+		DriverName: model.ImageDriverLocal,
+		Directory:  t.TempDir(),
+	})
+	require.NoError(t, err)
+	_, err = backend.WriteFile(strings.NewReader("report content"), "data/report.txt")
+	require.NoError(t, err)
+
+	root := newTestPost("post1", 100, "hello <world>")
+	root.PostFileIds = []string{"file1", "file2"}
+	root.PostProps = model.NewPointer(`{"from_webhook":"true"}`)
+
+	reply := newTestPost("post2", 200, "a reply")
+	reply.PostRootId = model.NewPointer("post1")
+
+	edited := newTestPost("post3", 300, "edited message")
+	edited.PostUpdateAt = model.NewPointer(int64(400))
+	edited.PostEditAt = model.NewPointer(int64(400))
+
+	original := newTestPost("post4", 300, "original message")
+	original.PostUpdateAt = model.NewPointer(int64(400))
+	original.PostDeleteAt = model.NewPointer(int64(400))
+	original.PostOriginalId = model.NewPointer("post3")
+
+	mockStore := &storetest.Store{}
+	defer mockStore.AssertExpectations(t)
+	mockStore.FileInfoStore.On("GetForPost", "post1", true, true, false).Return([]*model.FileInfo{
+		{Id: "file1", Name: "report.txt", Extension: "txt", Size: 14, MimeType: "text/plain", Path: "data/report.txt", CreateAt: 100},
+		{Id: "file2", Name: "missing.txt", Extension: "txt", Path: "data/missing.txt", CreateAt: 100},
+	}, nil)
+	mockStore.ReactionStore.On("BulkGetForPosts", mock.Anything).Return([]*model.Reaction{
+		{UserId: "user2", PostId: "post1", EmojiName: "smile", CreateAt: 150},
+		{UserId: "user2", PostId: "post3", EmojiName: "+1", CreateAt: 350},
+	}, nil)
+	mockStore.PostPriorityStore.On("GetForPosts", mock.Anything).Return([]*model.PostPriority{
+		{PostId: "post1", Priority: model.NewPointer(model.PostPriorityUrgent), RequestedAck: model.NewPointer(true), PersistentNotifications: model.NewPointer(false)},
+	}, nil)
+	mockStore.PostAcknowledgementStore.On("GetForPosts", mock.Anything).Return([]*model.PostAcknowledgement{
+		{UserId: "user2", PostId: "post1", AcknowledgedAt: 160},
+	}, nil)
+
+	batchPath := "export/batch001.zip"
+	results, err := JsonlExport(rctx, shared.ExportParams{
+		ChannelMetadata: map[string]*shared.MetadataChannel{
+			"channel-id": {
+				TeamId:             model.NewPointer("team-id"),
+				ChannelId:          "channel-id",
+				ChannelName:        "channel-name",
+				ChannelDisplayName: "Channel",
+				ChannelType:        model.ChannelTypeOpen,
+			},
+		},
+		ChannelMemberHistories: map[string][]*model.ChannelMemberHistoryResult{
+			"channel-id": {
+				{JoinTime: 0, UserId: "user1", UserEmail: "user1@example.com", Username: "user1"},
+				{JoinTime: 50, LeaveTime: model.NewPointer(int64(500)), UserId: "user2", UserEmail: "user2@example.com", Username: "user2"},
+			},
+		},
+		Posts:                 []*model.MessageExport{root, reply, edited, original},
+		JobStartTime:          1,
+		BatchStartTime:        1,
+		BatchEndTime:          1000,
+		BatchPath:             batchPath,
+		Db:                    shared.NewMessageExportStore(mockStore),
+		FileAttachmentBackend: backend,
+		ExportBackend:         backend,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, results.NumWarnings)
+
+	zipBytes, err := backend.ReadFile(batchPath)
+	require.NoError(t, err)
+	zipReader, err := zip.NewReader(bytes.NewReader(zipBytes), int64(len(zipBytes)))
+	require.NoError(t, err)
+
+	assert.Equal(t, Schema, readZipFile(t, zipReader, SchemaFilename))
+	assert.Equal(t, "report content", string(readZipFile(t, zipReader, "files/post1/file1-report.txt")))
+	assert.Contains(t, string(readZipFile(t, zipReader, JsonlWarningFilename)), "data/missing.txt")
+
+	var schema struct {
+		Defs map[string]map[string]any `json:"$defs"`
+	}
+	require.NoError(t, json.Unmarshal(Schema, &schema))
+
+	var records []map[string]any
+	scanner := bufio.NewScanner(bytes.NewReader(readZipFile(t, zipReader, RecordsFilename)))
+	for scanner.Scan() {
+		var r map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &r))
+		checkSchema(t, schema.Defs, r["type"].(string), r)
+		records = append(records, r)
+	}
+	require.NoError(t, scanner.Err())
+
+	summary := make([]string, 0, len(records))
+	for _, r := range records {
+		s := r["type"].(string)
+		if id, ok := r["id"]; ok {
+			s += ":" + id.(string) + ":" + r["update_type"].(string)
+		} else if f, ok := r["file"].(map[string]any); ok {
+			s += ":" + f["id"].(string)
+		} else {
+			s += ":" + r["user"].(map[string]any)["id"].(string)
+		}
+		summary = append(summary, s)
+	}
+	assert.Equal(t, []string{
+		"join:user1",
+		"join:user2",
+		"post:post1:created",
+		"file:file1",
+		"file:file2",
+		"post:post2:created",
+		"post:post3:edited",
+		"post:post4:edited_original",
+		"leave:user2",
+	}, summary)
+
+	post1 := records[2]
+	assert.Equal(t, "hello <world>", post1["message"])
+	assert.Equal(t, SchemaVersion, post1["schema_version"])
+	assert.Equal(t, map[string]any{"id": "team-id", "name": "team-name", "display_name": "Team"}, post1["team"])
+	assert.Equal(t, map[string]any{"from_webhook": "true"}, post1["props"])
+	assert.Equal(t, []any{map[string]any{"user_id": "user2", "emoji_name": "smile", "create_at": float64(150)}}, post1["reactions"])
+	assert.Equal(t, map[string]any{"priority": "urgent", "requested_ack": true, "persistent_notifications": false}, post1["priority"])
+	assert.Equal(t, []any{map[string]any{"user_id": "user2", "acknowledged_at": float64(160)}}, post1["acknowledgements"])
+
+	assert.Equal(t, "files/post1/file1-report.txt", records[3]["archive_path"])
+	assert.NotContains(t, records[4], "archive_path")
+
+	assert.Equal(t, "post1", records[5]["root_id"])
+
+	// The original message of an edited post takes the reactions of the edited post.
+	original4 := records[7]
+	assert.Equal(t, "post3", original4["original_id"])
+	assert.Equal(t, "original message", original4["message"])
+	assert.Equal(t, records[6]["reactions"], original4["reactions"])
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.enterprise for license information.
+
+package jsonl_export
+
+// SchemaVersion is the version of the JSON Schema of the records, in schema.json. It changes
+// whenever a record changes in a way that is not backwards compatible.
+const SchemaVersion = "1"
+
+const (
+	RecordTypePost  = "post"
+	RecordTypeJoin  = "join"
+	RecordTypeLeave = "leave"
+	RecordTypeFile  = "file"
+)
+
+const (
+	UpdateTypeCreated        = "created"
+	UpdateTypeEdited         = "edited"
+	UpdateTypeEditedOriginal = "edited_original"
+	UpdateTypeUpdated        = "updated"
+	UpdateTypeDeleted        = "deleted"
+)
+
+type Team struct {
+	Id          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+}
+
+type Channel struct {
+	Id          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	Type        string `json:"type"`
+}
+
+type User struct {
+	Id       string `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Type     string `json:"type"`
+}
+
+// RecordHeader holds the fields common to all the records.
+type RecordHeader struct {
+	Type          string  `json:"type"`
+	SchemaVersion string  `json:"schema_version"`
+	Time          int64   `json:"time"`
+	Team          *Team   `json:"team,omitempty"`
+	Channel       Channel `json:"channel"`
+	User          User    `json:"user"`
+}
+
+type Reaction struct {
+	UserId    string `json:"user_id"`
+	EmojiName string `json:"emoji_name"`
+	CreateAt  int64  `json:"create_at"`
+}
+
+type Priority struct {
+	Priority                string `json:"priority"`
+	RequestedAck            bool   `json:"requested_ack"`
+	PersistentNotifications bool   `json:"persistent_notifications"`
+}
+
+type Acknowledgement struct {
+	UserId         string `json:"user_id"`
+	AcknowledgedAt int64  `json:"acknowledged_at"`
+}
+
+// PostRecord is a post being created, edited, updated or deleted.
+type PostRecord struct {
+	RecordHeader
+	Id               string            `json:"id"`
+	RootId           string            `json:"root_id,omitempty"`
+	OriginalId       string            `json:"original_id,omitempty"`
+	CreateAt         int64             `json:"create_at"`
+	UpdateAt         int64             `json:"update_at"`
+	EditAt           int64             `json:"edit_at"`
+	DeleteAt         int64             `json:"delete_at"`
+	UpdateType       string            `json:"update_type"`
+	PostType         string            `json:"post_type"`
+	Message          string            `json:"message"`
+	Props            map[string]any    `json:"props,omitempty"`
+	PreviewsPostId   string            `json:"previews_post_id,omitempty"`
+	FileIds          []string          `json:"file_ids"`
+	Reactions        []Reaction        `json:"reactions"`
+	Priority         *Priority         `json:"priority,omitempty"`
+	Acknowledgements []Acknowledgement `json:"acknowledgements"`
+}
+
+// MembershipRecord is a member joining or leaving a channel.
+type MembershipRecord struct {
+	RecordHeader
+	PreviouslyJoined bool `json:"previously_joined,omitempty"`
+}
+
+type File struct {
+	Id        string `json:"id"`
+	Name      string `json:"name"`
+	Extension string `json:"extension"`
+	Size      int64  `json:"size"`
+	MimeType  string `json:"mime_type"`
+	CreateAt  int64  `json:"create_at"`
+	DeleteAt  int64  `json:"delete_at"`
+}
+
+// FileRecord is a file being uploaded to or deleted from a post.
+type FileRecord struct {
+	RecordHeader
+	PostId      string `json:"post_id"`
+	Deleted     bool   `json:"deleted"`
+	File        File   `json:"file"`
+	ArchivePath string `json:"archive_path,omitempty"`
+}
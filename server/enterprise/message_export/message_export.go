@@ -17,7 +17,9 @@ import (
 	ejobs "github.com/mattermost/mattermost/server/v8/einterfaces/jobs"
 	"github.com/mattermost/mattermost/server/v8/enterprise/message_export/actiance_export"
 	"github.com/mattermost/mattermost/server/v8/enterprise/message_export/csv_export"
+	"github.com/mattermost/mattermost/server/v8/enterprise/message_export/eml_export"
 	"github.com/mattermost/mattermost/server/v8/enterprise/message_export/global_relay_export"
+	"github.com/mattermost/mattermost/server/v8/enterprise/message_export/jsonl_export"
 	"github.com/mattermost/mattermost/server/v8/enterprise/message_export/shared"
 )
 
@@ -92,6 +94,20 @@ func RunBatch(rctx request.CTX, data shared.JobData, params shared.BackendParams
 		return res, data, err
 	}
 
+	if params.Deliverer != nil {
+		data.DeliveryTarget = params.Deliverer.Target()
+		data.DeliveryAttempts, err = params.Deliverer.Deliver(rctx, params.ExportBackend, data.BatchPath)
+		if err != nil {
+			data.DeliveryStatus = model.MessageExportDeliveryStatusFailed
+			data.DeliveryError = err.Error()
+			return res, data, err
+		}
+		data.DeliveryStatus = model.MessageExportDeliveryStatusDelivered
+		data.DeliveryError = ""
+		data.BatchesDelivered++
+		data.LastDeliveredPath = data.BatchPath
+	}
+
 	data.ProcessingPostsMs = append(data.ProcessingPostsMs, res.ProcessingPostsMs)
 	data.ProcessingXmlMs = append(data.ProcessingXmlMs, res.ProcessingXmlMs)
 	data.TransferringFilesMs = append(data.TransferringFilesMs, res.TransferringFilesMs)
@@ -198,6 +214,14 @@ func RunExportByType(rctx request.CTX, p ExportParams, b shared.BackendParams) (
 		rctx.Logger().Debug("Exporting GlobalRelay")
 		return global_relay_export.GlobalRelayExport(rctx, exportParams)
 
+	case model.ComplianceExportTypeEml:
+		rctx.Logger().Debug("Exporting EML")
+		return eml_export.EmlExport(rctx, exportParams)
+
+	case model.ComplianceExportTypeJsonl:
+		rctx.Logger().Debug("Exporting JSONL")
+		return jsonl_export.JsonlExport(rctx, exportParams)
+
 	default:
 		return results, errors.New("Unknown output format: " + p.ExportType)
 	}
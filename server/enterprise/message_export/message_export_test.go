@@ -8,6 +8,7 @@ import (
 	"bytes"
 	_ "embed"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -292,6 +293,136 @@ func testRunExportByType(t *testing.T, exportBackend filestore.FileBackend, expo
 	})
 }
 
+type testDeliverer struct {
+	delivered []string
+	err       error
+}
+
+func (d *testDeliverer) Target() string {
+	return model.MessageExportDeliveryTargetS3
+}
+
+func (d *testDeliverer) Deliver(_ request.CTX, _ filestore.FileBackend, batchPath string) (int, error) {
+	if d.err != nil {
+		return 4, d.err
+	}
+	d.delivered = append(d.delivered, batchPath)
+	return 1, nil
+}
+
+func TestRunBatchDelivery(t *testing.T) {
+	rctx := request.TestContext(t)
+
+	exportBackend, err := filestore.NewFileBackend(filestore.FileBackendSettings{
+		DriverName: model.ImageDriverLocal,
+		Directory:  t.TempDir(),
+	})
+	require.NoError(t, err)
+
+	chanTypeOpen := model.ChannelTypeOpen
+	posts := []*model.MessageExport{
+		{
+			PostId:             model.NewPointer("post-id"),
+			PostOriginalId:     model.NewPointer(""),
+			TeamId:             model.NewPointer("team-id"),
+			TeamName:           model.NewPointer("team-name"),
+			TeamDisplayName:    model.NewPointer("team-display-name"),
+			ChannelId:          model.NewPointer("channel-id"),
+			ChannelName:        model.NewPointer("channel-name"),
+			ChannelDisplayName: model.NewPointer("channel-display-name"),
+			ChannelType:        &chanTypeOpen,
+			PostCreateAt:       model.NewPointer(int64(1)),
+			PostUpdateAt:       model.NewPointer(int64(1)),
+			PostMessage:        model.NewPointer("message"),
+			UserEmail:          model.NewPointer("test@example.com"),
+			Username:           model.NewPointer("test"),
+			UserId:             model.NewPointer("user-id"),
+			PostFileIds:        []string{},
+		},
+	}
+
+	newJobData := func() shared.JobData {
+		return shared.JobData{
+			JobDataExported: shared.JobDataExported{
+				ExportType: model.ComplianceExportTypeCsv,
+				ExportDir:  "export/delivery",
+				BatchSize:  10,
+				JobEndTime: 2,
+			},
+			ChannelMetadata: map[string]*shared.MetadataChannel{
+				"channel-id": {
+					TeamId:             model.NewPointer("team-id"),
+					ChannelId:          "channel-id",
+					ChannelName:        "channel-name",
+					ChannelDisplayName: "channel-display-name",
+					ChannelType:        chanTypeOpen,
+				},
+			},
+			ChannelMemberHistories: map[string][]*model.ChannelMemberHistoryResult{},
+		}
+	}
+
+	newStore := func(t *testing.T) *storetest.Store {
+		mockStore := &storetest.Store{}
+		t.Cleanup(func() { mockStore.AssertExpectations(t) })
+		mockStore.ComplianceStore.On("MessageExport", mock.Anything, mock.Anything, 11).
+			Return(posts, model.MessageExportCursor{LastPostUpdateAt: 1, LastPostId: "post-id"}, nil)
+		return mockStore
+	}
+
+	t.Run("records the delivered batch", func(t *testing.T) {
+		deliverer := &testDeliverer{}
+
+		_, data, err := RunBatch(rctx, newJobData(), shared.BackendParams{
+			Store:         shared.NewMessageExportStore(newStore(t)),
+			ExportBackend: exportBackend,
+			Deliverer:     deliverer,
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, []string{data.BatchPath}, deliverer.delivered)
+		assert.Equal(t, model.MessageExportDeliveryTargetS3, data.DeliveryTarget)
+		assert.Equal(t, model.MessageExportDeliveryStatusDelivered, data.DeliveryStatus)
+		assert.Equal(t, 1, data.BatchesDelivered)
+		assert.Equal(t, 1, data.DeliveryAttempts)
+		assert.Equal(t, data.BatchPath, data.LastDeliveredPath)
+	})
+
+	t.Run("records the failed delivery", func(t *testing.T) {
+		deliverer := &testDeliverer{err: errors.New("connection refused")}
+
+		_, data, err := RunBatch(rctx, newJobData(), shared.BackendParams{
+			Store:         shared.NewMessageExportStore(newStore(t)),
+			ExportBackend: exportBackend,
+			Deliverer:     deliverer,
+		})
+		require.Error(t, err)
+
+		assert.Equal(t, model.MessageExportDeliveryStatusFailed, data.DeliveryStatus)
+		assert.Equal(t, "connection refused", data.DeliveryError)
+		assert.Equal(t, 0, data.BatchesDelivered)
+		assert.Equal(t, 4, data.DeliveryAttempts)
+
+		job := &model.Job{Data: map[string]string{}}
+		setJobDataDelivery(job, data)
+		assert.Equal(t, model.MessageExportDeliveryStatusFailed, job.Data[model.MessageExportJobDataDeliveryStatus])
+		assert.Equal(t, "connection refused", job.Data[model.MessageExportJobDataDeliveryError])
+	})
+
+	t.Run("no delivery without a deliverer", func(t *testing.T) {
+		_, data, err := RunBatch(rctx, newJobData(), shared.BackendParams{
+			Store:         shared.NewMessageExportStore(newStore(t)),
+			ExportBackend: exportBackend,
+		})
+		require.NoError(t, err)
+		assert.Empty(t, data.DeliveryTarget)
+
+		job := &model.Job{Data: map[string]string{}}
+		setJobDataDelivery(job, data)
+		assert.Empty(t, job.Data)
+	})
+}
+
 func TestRunExportJobE2EByType(t *testing.T) {
 	t.Run("no dedicated export filestore", func(t *testing.T) {
 		exportTempDir, err := os.MkdirTemp("", "")
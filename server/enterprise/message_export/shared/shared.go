@@ -51,6 +51,16 @@ const (
 	JobDataExportDir               = "export_dir"
 	JobDataBatchNumber             = "job_batch_number"
 	JobDataTotalPostsExpected      = "total_posts_expected"
+
+	// JobDataLegalHoldId restricts the export to the posts covered by the legal hold with this id.
+	JobDataLegalHoldId = "legal_hold_id"
+
+	JobDataDeliveryTarget    = model.MessageExportJobDataDeliveryTarget
+	JobDataDeliveryStatus    = model.MessageExportJobDataDeliveryStatus
+	JobDataBatchesDelivered  = model.MessageExportJobDataBatchesDelivered
+	JobDataDeliveryAttempts  = model.MessageExportJobDataDeliveryAttempts
+	JobDataDeliveryError     = model.MessageExportJobDataDeliveryError
+	JobDataLastDeliveredPath = model.MessageExportJobDataLastDeliveredPath
 )
 
 type PostUpdatedType string
@@ -102,6 +112,15 @@ type JobDataExported struct {
 	MessagesExported        int
 	WarningCount            int
 	IsDownloadable          bool
+	LegalHoldId             string
+
+	// The state of the delivery of the batches to the archive, if one is configured.
+	DeliveryTarget    string
+	DeliveryStatus    string
+	BatchesDelivered  int
+	DeliveryAttempts  int
+	DeliveryError     string
+	LastDeliveredPath string
 }
 
 func JobDataToStringMap(jd JobData) map[string]string {
@@ -121,6 +140,13 @@ func JobDataToStringMap(jd JobData) map[string]string {
 	ret[JobDataMessagesExported] = strconv.Itoa(jd.MessagesExported)
 	ret[JobDataWarningCount] = strconv.Itoa(jd.WarningCount)
 	ret[JobDataIsDownloadable] = strconv.FormatBool(jd.IsDownloadable)
+	ret[JobDataLegalHoldId] = jd.LegalHoldId
+	ret[JobDataDeliveryTarget] = jd.DeliveryTarget
+	ret[JobDataDeliveryStatus] = jd.DeliveryStatus
+	ret[JobDataBatchesDelivered] = strconv.Itoa(jd.BatchesDelivered)
+	ret[JobDataDeliveryAttempts] = strconv.Itoa(jd.DeliveryAttempts)
+	ret[JobDataDeliveryError] = jd.DeliveryError
+	ret[JobDataLastDeliveredPath] = jd.LastDeliveredPath
 	return ret
 }
 
@@ -223,15 +249,48 @@ func StringMapToJobDataWithZeroValues(sm map[string]string) (JobData, error) {
 		return jd, errors.Wrap(err, "error converting JobDataIsDownloadable")
 	}
 
+	jd.LegalHoldId = sm[JobDataLegalHoldId]
+
+	jd.DeliveryTarget = sm[JobDataDeliveryTarget]
+	jd.DeliveryStatus = sm[JobDataDeliveryStatus]
+	jd.DeliveryError = sm[JobDataDeliveryError]
+	jd.LastDeliveredPath = sm[JobDataLastDeliveredPath]
+
+	batchesDelivered, ok := sm[JobDataBatchesDelivered]
+	if !ok {
+		batchesDelivered = "0"
+	}
+	if jd.BatchesDelivered, err = strconv.Atoi(batchesDelivered); err != nil {
+		return jd, errors.Wrap(err, "error converting JobDataBatchesDelivered")
+	}
+
+	deliveryAttempts, ok := sm[JobDataDeliveryAttempts]
+	if !ok {
+		deliveryAttempts = "0"
+	}
+	if jd.DeliveryAttempts, err = strconv.Atoi(deliveryAttempts); err != nil {
+		return jd, errors.Wrap(err, "error converting JobDataDeliveryAttempts")
+	}
+
 	return jd, nil
 }
 
+// Deliverer copies the batches written to the export file store to an archive.
+type Deliverer interface {
+	// Target is the kind of archive the batches are copied to, such as sftp or s3.
+	Target() string
+	// Deliver copies the batch, retrying on failure, and returns the number of attempts it took.
+	Deliver(rctx request.CTX, backend filestore.FileBackend, batchPath string) (int, error)
+}
+
 type BackendParams struct {
 	Config                *model.Config
 	Store                 MessageExportStore
 	FileAttachmentBackend filestore.FileBackend
 	ExportBackend         filestore.FileBackend
 	HtmlTemplates         *templates.Container
+	// Deliverer is nil when no archive is configured.
+	Deliverer Deliverer
 }
 
 type ExportParams struct {
@@ -366,6 +425,7 @@ func GetInitialExportPeriodData(rctx request.CTX, store MessageExportStore, data
 		LastPostUpdateAt: data.BatchStartTime,
 		LastPostId:       data.BatchStartId,
 		UntilUpdateAt:    data.JobEndTime,
+		LegalHoldId:      data.LegalHoldId,
 	}
 
 	return data, nil
@@ -223,6 +223,12 @@ func TestJobDataToStringMap_and_StringMapToJobData(t *testing.T) {
 			TotalPostsExpected:      999999,
 			MessagesExported:        343499,
 			WarningCount:            39,
+			LegalHoldId:             "hc1pehx5dbrtbjt3x8hjc7nw7a",
+			DeliveryTarget:          model.MessageExportDeliveryTargetSFTP,
+			DeliveryStatus:          model.MessageExportDeliveryStatusDelivered,
+			BatchesDelivered:        3,
+			DeliveryAttempts:        2,
+			LastDeliveredPath:       "/here/there/34234-123/batch003.zip",
 		},
 		ExportPeriodStartTime: 123456,                  // not exported
 		BatchEndTime:          999999999,               // not exported
@@ -247,6 +253,13 @@ func TestJobDataToStringMap_and_StringMapToJobData(t *testing.T) {
 	expected[JobDataMessagesExported] = "343499"
 	expected[JobDataWarningCount] = "39"
 	expected[JobDataIsDownloadable] = "false"
+	expected[JobDataLegalHoldId] = "hc1pehx5dbrtbjt3x8hjc7nw7a"
+	expected[JobDataDeliveryTarget] = "sftp"
+	expected[JobDataDeliveryStatus] = "delivered"
+	expected[JobDataBatchesDelivered] = "3"
+	expected[JobDataDeliveryAttempts] = "2"
+	expected[JobDataDeliveryError] = ""
+	expected[JobDataLastDeliveredPath] = "/here/there/34234-123/batch003.zip"
 
 	for k, v := range expected {
 		val, ok := strMap[k]
@@ -277,6 +290,9 @@ func TestJobDataToStringMap_and_StringMapToJobData(t *testing.T) {
 	badStrMap = map[string]string{JobDataJobEndTime: "blah blah"}
 	_, err = StringMapToJobDataWithZeroValues(badStrMap)
 	assert.Error(t, err)
+	badStrMap = map[string]string{JobDataBatchesDelivered: "three"}
+	_, err = StringMapToJobDataWithZeroValues(badStrMap)
+	assert.Error(t, err)
 
 	// test that zero values are used when not present
 	emptyStrMap := make(map[string]string)
@@ -14,6 +14,9 @@ type MessageExportStore interface {
 	Channel() store.ChannelStore
 	Compliance() store.ComplianceStore
 	FileInfo() MEFileInfoStore
+	Reaction() store.ReactionStore
+	PostPriority() store.PostPriorityStore
+	PostAcknowledgement() store.PostAcknowledgementStore
 }
 
 type MEFileInfoStore interface {
@@ -18,6 +18,7 @@ import (
 	"github.com/mattermost/mattermost/server/public/shared/request"
 	"github.com/mattermost/mattermost/server/v8/channels/jobs"
 	"github.com/mattermost/mattermost/server/v8/channels/utils/fileutils"
+	"github.com/mattermost/mattermost/server/v8/enterprise/message_export/delivery"
 	"github.com/mattermost/mattermost/server/v8/enterprise/message_export/shared"
 	"github.com/mattermost/mattermost/server/v8/platform/shared/templates"
 )
@@ -190,6 +191,13 @@ func (w *MessageExportWorker) DoJob(job *model.Job) {
 		w.setJobError(logger, job, model.NewAppError("GetExportBackend", "api.file.no_driver.app_error", nil, "", http.StatusInternalServerError).Wrap(err))
 		return
 	}
+	// Global Relay exports are sent by email rather than written to the export file store.
+	if data.ExportType != model.ComplianceExportTypeGlobalrelay {
+		insecure := w.jobServer.Config().ServiceSettings.EnableInsecureOutgoingConnections
+		if deliverer := delivery.New(w.jobServer.Config(), insecure != nil && *insecure); deliverer != nil {
+			jobParams.Deliverer = deliverer
+		}
+	}
 
 	data, err = shared.GetInitialExportPeriodData(rctx, jobParams.Store, data, reportProgress)
 	if err != nil {
@@ -219,6 +227,9 @@ func (w *MessageExportWorker) DoJob(job *model.Job) {
 				if errors.Is(w.context.Err(), context.Canceled) {
 					logger.Debug("Worker: Job has been canceled via worker's context. Setting the job back to pending")
 					w.SetJobPending(logger, job)
+				} else if errors.Is(err, delivery.ErrDeliveryFailed) {
+					setJobDataDelivery(job, data)
+					w.setJobError(logger, job, model.NewAppError("DoJob", "ent.message_export.deliver.app_error", nil, "", http.StatusInternalServerError).Wrap(err))
 				} else {
 					w.setJobError(logger, job, model.NewAppError("DoJob", "ent.message_export.run_export.app_error", nil, "", http.StatusInternalServerError).Wrap(err))
 				}
@@ -307,6 +318,10 @@ func (w *MessageExportWorker) initJobData(logger mlog.LoggerIFace, job *model.Jo
 		job.Data[shared.JobDataJobEndTime] = millis
 	}
 
+	if job.Type == model.JobTypeLegalHoldExport {
+		w.initLegalHoldJobData(logger, job, now)
+	}
+
 	if _, exists := job.Data[shared.JobDataBatchStartTime]; !exists {
 		previousJob, err := w.jobServer.Store.Job().GetNewestJobByStatusesAndType([]string{model.JobStatusWarning, model.JobStatusSuccess}, model.JobTypeMessageExport)
 		if err != nil {
@@ -357,6 +372,30 @@ func (w *MessageExportWorker) initJobData(logger mlog.LoggerIFace, job *model.Jo
 	job.Data[shared.JobDataExportDir] = getJobExportDir(logger, job.Data, job.Data[shared.JobDataJobStartTime], job.Data[shared.JobDataJobEndTime])
 }
 
+// initLegalHoldJobData starts a legal hold export at the start of the hold rather than where the previous
+// compliance export left off, and writes it to its own directory.
+func (w *MessageExportWorker) initLegalHoldJobData(logger mlog.LoggerIFace, job *model.Job, now time.Time) {
+	legalHoldID := job.Data[shared.JobDataLegalHoldId]
+
+	if _, exists := job.Data[shared.JobDataBatchStartTime]; !exists {
+		startTime := "0"
+		hold, err := w.jobServer.Store.LegalHold().Get(legalHoldID)
+		if err != nil {
+			logger.Warn("Worker: Failed to get the legal hold, exporting from the beginning", mlog.String("legal_hold_id", legalHoldID), mlog.Err(err))
+		} else {
+			startTime = strconv.FormatInt(hold.StartAt, 10)
+		}
+		job.Data[shared.JobDataBatchStartTime] = startTime
+		job.Data[shared.JobDataJobStartTime] = startTime
+		job.Data[shared.JobDataBatchStartId] = ""
+		job.Data[shared.JobDataJobStartId] = ""
+	}
+
+	if _, exists := job.Data[shared.JobDataExportDir]; !exists {
+		job.Data[shared.JobDataExportDir] = path.Join(model.ComplianceExportPath, fmt.Sprintf("%s-legal-hold-%s", now.Format(model.ComplianceExportDirectoryFormat), legalHoldID))
+	}
+}
+
 func extractJobData(logger *mlog.Logger, strmap map[string]string) (shared.JobData, error) {
 	data, err := shared.StringMapToJobDataWithZeroValues(strmap)
 	if err != nil {
@@ -395,6 +434,20 @@ func setJobDataEndOfBatch(job *model.Job, data shared.JobData) {
 	job.Data[shared.JobDataBatchStartId] = data.Cursor.LastPostId
 	job.Data[shared.JobDataMessagesExported] = strconv.Itoa(data.MessagesExported)
 	job.Data[shared.JobDataBatchNumber] = strconv.Itoa(data.BatchNumber)
+	setJobDataDelivery(job, data)
+}
+
+// setJobDataDelivery records the state of the delivery of the batches, if an archive is configured.
+func setJobDataDelivery(job *model.Job, data shared.JobData) {
+	if data.DeliveryTarget == "" {
+		return
+	}
+	job.Data[shared.JobDataDeliveryTarget] = data.DeliveryTarget
+	job.Data[shared.JobDataDeliveryStatus] = data.DeliveryStatus
+	job.Data[shared.JobDataBatchesDelivered] = strconv.Itoa(data.BatchesDelivered)
+	job.Data[shared.JobDataDeliveryAttempts] = strconv.Itoa(data.DeliveryAttempts)
+	job.Data[shared.JobDataDeliveryError] = data.DeliveryError
+	job.Data[shared.JobDataLastDeliveredPath] = data.LastDeliveredPath
 }
 
 // getJobExportDir will use the existing JobDataExportDir if available. If it's not available, this is the first run
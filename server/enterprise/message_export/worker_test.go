@@ -188,6 +188,62 @@ func TestInitJobDataPreviousJobWithJobData(t *testing.T) {
 	assert.Equal(t, expectedDir, job.Data[shared.JobDataExportDir])
 }
 
+func TestInitJobDataLegalHold(t *testing.T) {
+	logger := mlog.CreateConsoleTestLogger(t)
+	mockStore := &storetest.Store{}
+	defer mockStore.AssertExpectations(t)
+
+	hold := &model.LegalHold{
+		Id:      st.NewTestID(),
+		StartAt: 12345,
+	}
+
+	job := &model.Job{
+		Id:       st.NewTestID(),
+		CreateAt: model.GetMillis(),
+		Status:   model.JobStatusPending,
+		Type:     model.JobTypeLegalHoldExport,
+		Data:     map[string]string{shared.JobDataLegalHoldId: hold.Id},
+	}
+
+	// the previous compliance exports are ignored, the export starts with the hold
+	mockStore.LegalHoldStore.On("Get", hold.Id).Return(hold, nil)
+
+	worker := &MessageExportWorker{
+		jobServer: &jobs.JobServer{
+			Store: mockStore,
+			ConfigService: &testutils.StaticConfigService{
+				Cfg: &model.Config{
+					// mock config
+					MessageExportSettings: model.MessageExportSettings{
+						EnableExport:            model.NewPointer(true),
+						ExportFormat:            model.NewPointer(model.ComplianceExportTypeActiance),
+						DailyRunTime:            model.NewPointer("01:00"),
+						ExportFromTimestamp:     model.NewPointer(int64(0)),
+						BatchSize:               model.NewPointer(10000),
+						ChannelBatchSize:        model.NewPointer(100),
+						ChannelHistoryBatchSize: model.NewPointer(100),
+					},
+				},
+			},
+		},
+		logger: logger,
+	}
+
+	now := time.Now()
+	worker.initJobData(logger, job, now)
+
+	assert.Equal(t, "12345", job.Data[shared.JobDataBatchStartTime])
+	assert.Equal(t, "12345", job.Data[shared.JobDataJobStartTime])
+	assert.Equal(t, strconv.FormatInt(now.UnixMilli(), 10), job.Data[shared.JobDataJobEndTime])
+	expectedDir := path.Join(model.ComplianceExportPath, fmt.Sprintf("%s-legal-hold-%s", now.Format(model.ComplianceExportDirectoryFormat), hold.Id))
+	assert.Equal(t, expectedDir, job.Data[shared.JobDataExportDir])
+
+	data, err := extractJobData(logger, job.Data)
+	require.NoError(t, err)
+	assert.Equal(t, hold.Id, data.LegalHoldId)
+}
+
 func TestDoJobNoPostsToExport(t *testing.T) {
 	logger := mlog.CreateConsoleTestLogger(t)
 
@@ -68,9 +68,10 @@ type MetricsInterfaceImpl struct {
 	PostBroadcastCounter  prometheus.Counter
 	PostFileAttachCounter prometheus.Counter
 
-	HTTPRequestsCounter prometheus.Counter
-	HTTPErrorsCounter   prometheus.Counter
-	HTTPWebsocketsGauge *prometheus.GaugeVec
+	HTTPRequestsCounter            prometheus.Counter
+	HTTPErrorsCounter              prometheus.Counter
+	HTTPRateLimitRejectionCounters *prometheus.CounterVec
+	HTTPWebsocketsGauge            *prometheus.GaugeVec
 
 	ClusterRequestsDuration prometheus.Histogram
 	ClusterRequestsCounter  prometheus.Counter
@@ -426,6 +427,15 @@ func New(ps *platform.PlatformService, driver, dataSource string) *MetricsInterf
 	})
 	m.Registry.MustRegister(m.HTTPErrorsCounter)
 
+	m.HTTPRateLimitRejectionCounters = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   MetricsNamespace,
+		Subsystem:   MetricsSubsystemHTTP,
+		Name:        "rate_limit_rejections_total",
+		Help:        "The total number of http API requests denied by a rate limit policy.",
+		ConstLabels: additionalLabels,
+	}, []string{"policy"})
+	m.Registry.MustRegister(m.HTTPRateLimitRejectionCounters)
+
 	// Cluster Subsystem
 
 	m.ClusterHealthGauge = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
@@ -510,6 +520,7 @@ func New(ps *platform.PlatformService, driver, dataSource string) *MetricsInterf
 		model.ClusterEventPluginEvent,
 		model.ClusterEventInvalidateCacheForTermsOfService,
 		model.ClusterEventBusyStateChanged,
+		model.ClusterEventBleveIndexSync,
 	} {
 		m.ClusterEventMap[event] = m.ClusterEventTypeCounters.With(prometheus.Labels{"name": string(event)})
 	}
@@ -1697,6 +1708,10 @@ func (mi *MetricsInterfaceImpl) IncrementHTTPError() {
 	mi.HTTPErrorsCounter.Inc()
 }
 
+func (mi *MetricsInterfaceImpl) IncrementHTTPRateLimitRejection(policy string) {
+	mi.HTTPRateLimitRejectionCounters.With(prometheus.Labels{"policy": policy}).Inc()
+}
+
 func (mi *MetricsInterfaceImpl) IncrementClusterRequest() {
 	mi.ClusterRequestsCounter.Inc()
 }
@@ -54,6 +54,7 @@ func TestMetrics(t *testing.T) {
 
 	mi.IncrementHTTPRequest()
 	mi.IncrementHTTPError()
+	mi.IncrementHTTPRateLimitRejection("global")
 
 	mi.IncrementPostFileAttachment(5)
 	mi.IncrementPostCreate()
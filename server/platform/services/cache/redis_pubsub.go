@@ -0,0 +1,42 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package cache
+
+import (
+	"context"
+	"errors"
+
+	"github.com/redis/rueidis"
+)
+
+// RedisPubSub publishes and receives messages on Redis pub/sub channels, using the
+// connection of a Redis cache provider.
+type RedisPubSub struct {
+	client rueidis.Client
+}
+
+// NewRedisPubSub creates a pub/sub client using the connection of the given Redis cache provider.
+func NewRedisPubSub(provider Provider) (*RedisPubSub, error) {
+	rp, ok := provider.(*redisProvider)
+	if !ok {
+		return nil, errors.New("pub/sub requires the redis cache provider")
+	}
+
+	return &RedisPubSub{
+		client: rp.client,
+	}, nil
+}
+
+// Publish sends the message to all the current subscribers of the channel.
+func (ps *RedisPubSub) Publish(ctx context.Context, channel string, message []byte) error {
+	return ps.client.Do(ctx, ps.client.B().Publish().Channel(channel).Message(rueidis.BinaryString(message)).Build()).Error()
+}
+
+// Subscribe passes the messages published on the channels to receive, until the context is
+// canceled or the connection is lost. It blocks for as long as it is subscribed.
+func (ps *RedisPubSub) Subscribe(ctx context.Context, channels []string, receive func(channel string, message []byte)) error {
+	return ps.client.Receive(ctx, ps.client.B().Subscribe().Channel(channels...).Build(), func(msg rueidis.PubSubMessage) {
+		receive(msg.Channel, []byte(msg.Message))
+	})
+}
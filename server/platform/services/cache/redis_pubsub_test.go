@@ -0,0 +1,51 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+func TestRedisPubSub(t *testing.T) {
+	p := newTestRedisProvider(t)
+
+	ps, err := NewRedisPubSub(p)
+	require.NoError(t, err)
+
+	channel := "pubsub-test-" + model.NewId()
+	received := make(chan []byte, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = ps.Subscribe(ctx, []string{channel}, func(ch string, message []byte) {
+			assert.Equal(t, channel, ch)
+			received <- message
+		})
+	}()
+
+	// The subscription is set up asynchronously, so publish until it receives the message.
+	require.Eventually(t, func() bool {
+		require.NoError(t, ps.Publish(context.Background(), channel, []byte{0, 1, 2}))
+		select {
+		case message := <-received:
+			assert.Equal(t, []byte{0, 1, 2}, message)
+			return true
+		case <-time.After(100 * time.Millisecond):
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestNewRedisPubSubRequiresRedis(t *testing.T) {
+	_, err := NewRedisPubSub(NewProvider())
+	require.Error(t, err)
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/rueidis"
+)
+
+// rateLimitCASScript swaps the value of a key if it still holds the old value, as a single
+// atomic operation. A missing key is not swapped.
+var rateLimitCASScript = rueidis.NewLuaScript(`
+local v = redis.call('get', KEYS[1])
+if v == false or v ~= ARGV[1] then
+  return 0
+end
+redis.call('set', KEYS[1], ARGV[2], 'PX', ARGV[3])
+return 1
+`)
+
+// RedisRateLimitStore keeps the state of a GCRA rate limiter in Redis, so that all the nodes
+// of a cluster share the same limits. It implements throttled.GCRAStore.
+type RedisRateLimitStore struct {
+	client rueidis.Client
+	prefix string
+}
+
+// NewRedisRateLimitStore creates a rate limit store using the connection of the given Redis
+// cache provider, with all its keys starting with prefix.
+func NewRedisRateLimitStore(provider Provider, prefix string) (*RedisRateLimitStore, error) {
+	rp, ok := provider.(*redisProvider)
+	if !ok {
+		return nil, errors.New("the rate limit store requires the redis cache provider")
+	}
+
+	return &RedisRateLimitStore{
+		client: rp.client,
+		prefix: prefix,
+	}, nil
+}
+
+// GetWithTime returns the value of the key, or -1 if it does not exist, along with the time
+// of the Redis server, which acts as the shared clock of the cluster.
+func (s *RedisRateLimitStore) GetWithTime(key string) (int64, time.Time, error) {
+	ctx := context.Background()
+	resps := s.client.DoMulti(ctx,
+		s.client.B().Time().Build(),
+		s.client.B().Get().Key(s.prefix+key).Build(),
+	)
+
+	serverTime, err := resps[0].AsStrSlice()
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	if len(serverTime) != 2 {
+		return 0, time.Time{}, errors.New("unexpected reply to the TIME command")
+	}
+	seconds, err := strconv.ParseInt(serverTime[0], 10, 64)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	microseconds, err := strconv.ParseInt(serverTime[1], 10, 64)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	now := time.Unix(seconds, microseconds*int64(time.Microsecond))
+
+	value, err := resps[1].AsInt64()
+	if rueidis.IsRedisNil(err) {
+		return -1, now, nil
+	} else if err != nil {
+		return 0, now, err
+	}
+
+	return value, now, nil
+}
+
+// SetIfNotExistsWithTTL sets the value of the key if it does not exist yet, and reports
+// whether it did.
+func (s *RedisRateLimitStore) SetIfNotExistsWithTTL(key string, value int64, ttl time.Duration) (bool, error) {
+	err := s.client.Do(context.Background(),
+		s.client.B().Set().
+			Key(s.prefix+key).
+			Value(strconv.FormatInt(value, 10)).
+			Nx().
+			PxMilliseconds(ttlMilliseconds(ttl)).
+			Build(),
+	).Error()
+	if rueidis.IsRedisNil(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// CompareAndSwapWithTTL sets the value of the key to new if it currently holds old, and
+// reports whether it did.
+func (s *RedisRateLimitStore) CompareAndSwapWithTTL(key string, old, new int64, ttl time.Duration) (bool, error) {
+	swapped, err := rateLimitCASScript.Exec(context.Background(), s.client,
+		[]string{s.prefix + key},
+		[]string{strconv.FormatInt(old, 10), strconv.FormatInt(new, 10), strconv.FormatInt(ttlMilliseconds(ttl), 10)},
+	).AsInt64()
+	if err != nil {
+		return false, err
+	}
+
+	return swapped == 1, nil
+}
+
+// ttlMilliseconds rounds the TTL up to at least a millisecond, as Redis rejects a zero expiry.
+func ttlMilliseconds(ttl time.Duration) int64 {
+	if ms := ttl.Milliseconds(); ms > 0 {
+		return ms
+	}
+	return 1
+}
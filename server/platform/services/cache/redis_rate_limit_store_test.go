@@ -0,0 +1,47 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package cache
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/throttled/throttled/store/storetest"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+func newTestRedisProvider(t *testing.T) Provider {
+	redisHost := "localhost"
+	if os.Getenv("IS_CI") == "true" {
+		redisHost = "redis"
+	}
+
+	p, err := NewRedisProvider(&RedisOptions{
+		RedisAddr:    redisHost + ":6379",
+		DisableCache: true,
+	})
+	if err != nil {
+		t.Skipf("redis is not available: %v", err)
+	}
+	t.Cleanup(func() { p.Close() })
+
+	return p
+}
+
+func TestRedisRateLimitStore(t *testing.T) {
+	p := newTestRedisProvider(t)
+
+	st, err := NewRedisRateLimitStore(p, "ratelimit-test-"+model.NewId()+":")
+	require.NoError(t, err)
+
+	storetest.TestGCRAStore(t, st)
+	storetest.TestGCRAStoreTTL(t, st)
+}
+
+func TestNewRedisRateLimitStoreRequiresRedis(t *testing.T) {
+	_, err := NewRedisRateLimitStore(NewProvider(), "ratelimit:")
+	require.Error(t, err)
+}
@@ -0,0 +1,700 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+// Package cluster is a built-in implementation of einterfaces.ClusterInterface. The nodes of
+// a cluster find each other through the ClusterDiscovery table, and exchange messages either
+// directly over HTTP or, when Redis is the cache provider, through Redis pub/sub channels.
+package cluster
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+	"github.com/mattermost/mattermost/server/v8/einterfaces"
+	"github.com/mattermost/mattermost/server/v8/platform/services/cache"
+)
+
+const (
+	// PingInterval is how often a node records in the ClusterDiscovery table that it is alive,
+	// and reads the table to learn about the other nodes.
+	PingInterval = 5 * time.Second
+	// A node that missed this many pings is no longer considered part of the cluster.
+	missedPingsLimit = 4
+
+	requestTimeout = 30 * time.Second
+	sendTimeout    = 30 * time.Second
+	sendRetries    = 3
+	sendQueueSize  = 5000
+	sendWorkers    = 8
+)
+
+type ServerIface interface {
+	Config() *model.Config
+	Log() mlog.LoggerIFace
+	GetStore() store.Store
+	CacheProvider() cache.Provider
+	Metrics() einterfaces.MetricsInterface
+	InvokeClusterLeaderChangedListeners()
+	SaveConfig(newCfg *model.Config, sendConfigChangeClusterMessage bool) (*model.Config, *model.Config, *model.AppError)
+	GetPluginStatuses() (model.PluginStatuses, *model.AppError)
+	GetLogsSkipSend(rctx request.CTX, page, perPage int, logFilter *model.LogFilter) ([]string, *model.AppError)
+	GenerateSupportPacket(rctx request.CTX, options *model.SupportPacketOptions) ([]model.FileData, error)
+	TotalWebsocketConnections() int
+	WebConnCountForUser(userID string) int
+	GetWSQueues(userID, connectionID string, seqNum int64) (*model.WSQueues, error)
+}
+
+// envelope is what the nodes exchange. A message with a request id expects a reply from
+// each node it is sent to, which carries the same request id.
+type envelope struct {
+	From      string                `json:"from"`
+	RequestId string                `json:"request_id,omitempty"`
+	Reply     bool                  `json:"reply,omitempty"`
+	Error     string                `json:"error,omitempty"`
+	Message   *model.ClusterMessage `json:"message"`
+}
+
+type outgoing struct {
+	nodes     []*model.ClusterDiscovery
+	frame     []byte
+	reliable  bool
+	broadcast bool
+}
+
+type Cluster struct {
+	server       ServerIface
+	id           string
+	pingInterval time.Duration
+
+	handlersMut    sync.RWMutex
+	handlers       map[model.ClusterEvent]einterfaces.ClusterMessageHandler
+	gossipHandlers map[model.ClusterEvent]gossipHandler
+
+	requestsMut sync.Mutex
+	requests    map[string]chan *envelope
+
+	// lifecycleMut serializes starting and stopping the inter-node communication.
+	lifecycleMut sync.Mutex
+	wg           sync.WaitGroup
+
+	mut       sync.RWMutex
+	started   bool
+	self      *model.ClusterDiscovery
+	hostname  string
+	members   map[string]*model.ClusterDiscovery
+	leaderId  string
+	failing   map[string]bool
+	transport transport
+	codec     *codec
+	queue     chan *outgoing
+	stop      chan struct{}
+}
+
+// New creates the cluster implementation of the given server. It doesn't communicate with the
+// other nodes until StartInterNodeCommunication is called.
+func New(server ServerIface) *Cluster {
+	c := &Cluster{
+		server:       server,
+		id:           model.NewId(),
+		pingInterval: PingInterval,
+		handlers:     map[model.ClusterEvent]einterfaces.ClusterMessageHandler{},
+		requests:     map[string]chan *envelope{},
+		members:      map[string]*model.ClusterDiscovery{},
+		failing:      map[string]bool{},
+	}
+	c.handlers[model.ClusterGossipEventRequestSaveConfig] = c.saveConfigHandler
+	c.registerGossipHandlers()
+
+	return c
+}
+
+func (c *Cluster) StartInterNodeCommunication() {
+	if err := c.start(); err != nil {
+		c.server.Log().Error("Failed to start the cluster inter-node communication", mlog.Err(err))
+	}
+}
+
+func (c *Cluster) start() error {
+	c.lifecycleMut.Lock()
+	defer c.lifecycleMut.Unlock()
+
+	if c.isStarted() {
+		return nil
+	}
+
+	logger := c.server.Log()
+	settings := c.server.Config().ClusterSettings
+
+	secret, err := c.clusterSecret()
+	if err != nil {
+		return fmt.Errorf("failed to get the cluster key: %w", err)
+	}
+	codec, err := newCodec(secret)
+	if err != nil {
+		return err
+	}
+
+	transport, err := c.newTransport()
+	if err != nil {
+		return err
+	}
+
+	self := &model.ClusterDiscovery{
+		Id:          c.id,
+		Type:        model.CDSTypeApp,
+		ClusterName: *settings.ClusterName,
+		Hostname:    *settings.AdvertiseAddress,
+	}
+	if self.Hostname == "" {
+		self.Hostname = *settings.OverrideHostname
+	}
+	if *settings.UseIPAddress {
+		self.AutoFillIPAddress(*settings.NetworkInterface, "")
+	}
+	self.AutoFillHostname()
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = self.Hostname
+	}
+
+	c.mut.Lock()
+	c.codec = codec
+	c.transport = transport
+	c.mut.Unlock()
+
+	if err = transport.start(c.receive); err != nil {
+		return err
+	}
+	self.GossipPort = int32(transport.port())
+	if self.GossipPort == 0 {
+		self.GossipPort = int32(*settings.GossipPort)
+	}
+
+	discovery := c.server.GetStore().ClusterDiscovery()
+	if err = discovery.Cleanup(); err != nil {
+		logger.Warn("Failed to clean up the outdated cluster discovery information", mlog.Err(err))
+	}
+	if err = discovery.Save(self); err != nil {
+		transport.stop()
+		return fmt.Errorf("failed to register the node in the cluster discovery: %w", err)
+	}
+
+	c.mut.Lock()
+	c.started = true
+	c.self = self
+	c.hostname = hostname
+	c.queue = make(chan *outgoing, sendQueueSize)
+	c.stop = make(chan struct{})
+	c.mut.Unlock()
+
+	c.refreshMembers()
+
+	c.wg.Add(1 + sendWorkers)
+	go c.watchMembers()
+	for range sendWorkers {
+		go c.sendWorker()
+	}
+
+	logger.Info("Started the cluster inter-node communication",
+		mlog.String("cluster_name", self.ClusterName),
+		mlog.String("node_id", c.id),
+		mlog.String("hostname", self.Hostname),
+		mlog.Int("gossip_port", self.GossipPort),
+	)
+
+	return nil
+}
+
+// newTransport creates the transport of the configured type. By default, the nodes exchange
+// messages through Redis when it's the cache, and directly otherwise.
+func (c *Cluster) newTransport() (transport, error) {
+	logger := c.server.Log()
+	config := c.server.Config()
+	settings := config.ClusterSettings
+
+	messageTransport := *settings.MessageTransport
+	provider := c.server.CacheProvider()
+	if messageTransport == model.ClusterMessageTransportAuto {
+		messageTransport = model.ClusterMessageTransportHTTP
+		if provider != nil && provider.Type() == model.CacheTypeRedis {
+			messageTransport = model.ClusterMessageTransportRedis
+		}
+	}
+
+	switch messageTransport {
+	case model.ClusterMessageTransportRedis:
+		pubsub, err := cache.NewRedisPubSub(provider)
+		if err != nil {
+			return nil, err
+		}
+		return newRedisTransport(logger, pubsub, *settings.ClusterName, c.id), nil
+	case model.ClusterMessageTransportDatabase:
+		if *config.SqlSettings.DriverName != model.DatabaseDriverPostgres {
+			return nil, errors.New("the database cluster message transport requires PostgreSQL")
+		}
+		return newDatabaseTransport(logger, c.server.GetStore().ClusterPayload(), *config.SqlSettings.DataSource, *settings.ClusterName, c.id), nil
+	default:
+		return newHTTPTransport(logger, *settings.BindAddress, *settings.GossipPort), nil
+	}
+}
+
+func (c *Cluster) StopInterNodeCommunication() {
+	c.lifecycleMut.Lock()
+	defer c.lifecycleMut.Unlock()
+
+	if !c.isStarted() {
+		return
+	}
+
+	c.mut.Lock()
+	c.started = false
+	close(c.stop)
+	c.mut.Unlock()
+
+	c.wg.Wait()
+	c.transport.stop()
+
+	if _, err := c.server.GetStore().ClusterDiscovery().Delete(c.self); err != nil {
+		c.server.Log().Warn("Failed to remove the node from the cluster discovery", mlog.Err(err))
+	}
+
+	c.mut.Lock()
+	c.members = map[string]*model.ClusterDiscovery{}
+	c.failing = map[string]bool{}
+	c.leaderId = ""
+	c.mut.Unlock()
+}
+
+func (c *Cluster) isStarted() bool {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+	return c.started
+}
+
+// clusterSecret returns the key shared by all the nodes, creating it if this is the first node.
+func (c *Cluster) clusterSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	system, err := c.server.GetStore().System().InsertIfExists(&model.System{
+		Name:  model.SystemClusterEncryptionKey,
+		Value: base64.StdEncoding.EncodeToString(secret),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return system.Value, nil
+}
+
+func (c *Cluster) watchMembers() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.server.GetStore().ClusterDiscovery().SetLastPingAt(c.self); err != nil {
+				c.server.Log().Error("Failed to record the cluster discovery ping", mlog.Err(err))
+			}
+			c.refreshMembers()
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// refreshMembers reads the nodes alive from the cluster discovery table. The node that has
+// been alive the longest is the leader.
+func (c *Cluster) refreshMembers() {
+	discovery := c.server.GetStore().ClusterDiscovery()
+	nodes, err := discovery.GetAll(model.CDSTypeApp, c.self.ClusterName)
+	if err != nil {
+		c.server.Log().Warn("Failed to get the cluster members", mlog.Err(err))
+		return
+	}
+
+	cutoff := model.GetMillis() - missedPingsLimit*c.pingInterval.Milliseconds()
+	members := make(map[string]*model.ClusterDiscovery, len(nodes))
+	leader := c.self
+	registered := false
+	for _, node := range nodes {
+		if node.Id == c.id {
+			registered = true
+			continue
+		}
+		if node.LastPingAt < cutoff {
+			continue
+		}
+
+		members[node.Id] = node
+		if node.CreateAt < leader.CreateAt || (node.CreateAt == leader.CreateAt && node.Id < leader.Id) {
+			leader = node
+		}
+	}
+
+	// The row of this node may have been cleaned up, for instance after losing the database
+	// for a while, so it is saved again for the other nodes to find it.
+	if !registered {
+		if err := discovery.Save(c.self); err != nil {
+			c.server.Log().Warn("Failed to register the node in the cluster discovery again", mlog.Err(err))
+		}
+	}
+
+	c.mut.Lock()
+	c.members = members
+	for id := range c.failing {
+		if _, ok := members[id]; !ok {
+			delete(c.failing, id)
+		}
+	}
+	leaderChanged := c.leaderId != leader.Id
+	c.leaderId = leader.Id
+	c.mut.Unlock()
+
+	if leaderChanged {
+		c.server.InvokeClusterLeaderChangedListeners()
+	}
+}
+
+// peers returns the other nodes of the cluster, ordered by id.
+func (c *Cluster) peers() []*model.ClusterDiscovery {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+
+	nodes := make([]*model.ClusterDiscovery, 0, len(c.members))
+	for _, node := range c.members {
+		nodes = append(nodes, node)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Id < nodes[j].Id })
+
+	return nodes
+}
+
+func (c *Cluster) member(nodeID string) *model.ClusterDiscovery {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+	return c.members[nodeID]
+}
+
+func (c *Cluster) RegisterClusterMessageHandler(event model.ClusterEvent, crm einterfaces.ClusterMessageHandler) {
+	c.handlersMut.Lock()
+	defer c.handlersMut.Unlock()
+	c.handlers[event] = crm
+}
+
+func (c *Cluster) GetClusterId() string {
+	return c.id
+}
+
+func (c *Cluster) IsLeader() bool {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+	return c.started && c.leaderId == c.id
+}
+
+// HealthScore returns the number of nodes the last message sent to could not reach.
+func (c *Cluster) HealthScore() int {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+	return len(c.failing)
+}
+
+func (c *Cluster) GetMyClusterInfo() *model.ClusterInfo {
+	c.mut.RLock()
+	self, hostname := c.self, c.hostname
+	c.mut.RUnlock()
+	if self == nil {
+		return nil
+	}
+
+	var schemaVersion string
+	if version, err := c.server.GetStore().GetDBSchemaVersion(); err != nil {
+		c.server.Log().Warn("Failed to get the database schema version", mlog.Err(err))
+	} else {
+		schemaVersion = strconv.Itoa(version)
+	}
+
+	var configHash string
+	if data, err := json.Marshal(c.server.Config()); err != nil {
+		c.server.Log().Warn("Failed to hash the configuration", mlog.Err(err))
+	} else {
+		sum := sha256.Sum256(data)
+		configHash = hex.EncodeToString(sum[:])
+	}
+
+	return &model.ClusterInfo{
+		Id:            c.id,
+		Version:       model.CurrentVersion,
+		SchemaVersion: schemaVersion,
+		ConfigHash:    configHash,
+		IPAddress:     net.JoinHostPort(self.Hostname, strconv.Itoa(int(self.GossipPort))),
+		Hostname:      hostname,
+	}
+}
+
+func (c *Cluster) SendClusterMessage(msg *model.ClusterMessage) {
+	if metrics := c.server.Metrics(); metrics != nil {
+		metrics.IncrementClusterEventType(msg.Event)
+	}
+
+	nodes := c.peers()
+	if len(nodes) == 0 {
+		return
+	}
+
+	frame, err := c.encode(&envelope{From: c.id, Message: msg})
+	if err != nil {
+		c.server.Log().Error("Failed to encode the cluster message", mlog.String("event", string(msg.Event)), mlog.Err(err))
+		return
+	}
+
+	item := &outgoing{
+		nodes:     nodes,
+		frame:     frame,
+		reliable:  msg.SendType == model.ClusterSendReliable,
+		broadcast: true,
+	}
+	if msg.WaitForAllToSend {
+		c.deliver(item)
+		return
+	}
+	c.enqueue(item)
+}
+
+func (c *Cluster) SendClusterMessageToNode(nodeID string, msg *model.ClusterMessage) error {
+	if metrics := c.server.Metrics(); metrics != nil {
+		metrics.IncrementClusterEventType(msg.Event)
+	}
+
+	node := c.member(nodeID)
+	if node == nil {
+		return fmt.Errorf("node %s is not a member of the cluster", nodeID)
+	}
+
+	frame, err := c.encode(&envelope{From: c.id, Message: msg})
+	if err != nil {
+		return err
+	}
+
+	failed := c.deliver(&outgoing{
+		nodes:    []*model.ClusterDiscovery{node},
+		frame:    frame,
+		reliable: msg.SendType == model.ClusterSendReliable,
+	})
+	return failed[nodeID]
+}
+
+func (c *Cluster) encode(env *envelope) ([]byte, error) {
+	payload, err := json.Marshal(env)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mut.RLock()
+	codec := c.codec
+	c.mut.RUnlock()
+	if codec == nil {
+		return nil, errors.New("the cluster communication is not started")
+	}
+
+	settings := c.server.Config().ClusterSettings
+	return codec.encode(payload, *settings.EnableGossipCompression, *settings.EnableExperimentalGossipEncryption)
+}
+
+func (c *Cluster) enqueue(item *outgoing) {
+	c.mut.RLock()
+	queue, stop := c.queue, c.stop
+	c.mut.RUnlock()
+
+	if !item.reliable {
+		select {
+		case queue <- item:
+		default:
+			c.server.Log().Warn("Dropped a best effort cluster message as the send queue is full")
+		}
+		return
+	}
+
+	select {
+	case queue <- item:
+	case <-stop:
+	}
+}
+
+func (c *Cluster) sendWorker() {
+	defer c.wg.Done()
+
+	for {
+		select {
+		case item := <-c.queue:
+			c.deliver(item)
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// deliver sends the frame to the nodes, retrying the nodes it failed to reach when the
+// message is reliable. It returns the errors of the nodes that never received it.
+func (c *Cluster) deliver(item *outgoing) map[string]error {
+	attempts := 1
+	if item.reliable {
+		attempts = sendRetries
+	}
+
+	nodes := item.nodes
+	var failed map[string]error
+	for attempt := range attempts {
+		if attempt > 0 {
+			time.Sleep(time.Duration(attempt) * 100 * time.Millisecond)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		failed = c.transmit(ctx, nodes, item.frame, item.broadcast)
+		cancel()
+		if len(failed) == 0 {
+			break
+		}
+
+		retry := make([]*model.ClusterDiscovery, 0, len(failed))
+		for _, node := range nodes {
+			if _, ok := failed[node.Id]; ok {
+				retry = append(retry, node)
+			}
+		}
+		nodes = retry
+	}
+
+	c.mut.Lock()
+	for _, node := range item.nodes {
+		if err, ok := failed[node.Id]; ok {
+			c.failing[node.Id] = true
+			c.server.Log().Warn("Failed to send the cluster message", mlog.String("node_id", node.Id), mlog.Err(err))
+		} else {
+			delete(c.failing, node.Id)
+		}
+	}
+	c.mut.Unlock()
+
+	return failed
+}
+
+func (c *Cluster) transmit(ctx context.Context, nodes []*model.ClusterDiscovery, frame []byte, broadcast bool) map[string]error {
+	if broadcast {
+		return c.transport.broadcast(ctx, nodes, frame)
+	}
+
+	failed := map[string]error{}
+	for _, node := range nodes {
+		if err := c.transport.send(ctx, node, frame); err != nil {
+			failed[node.Id] = err
+		}
+	}
+	return failed
+}
+
+func (c *Cluster) NotifyMsg(buf []byte) {
+	if err := c.receive(buf); err != nil {
+		c.server.Log().Warn("Rejected cluster message", mlog.Err(err))
+	}
+}
+
+func (c *Cluster) receive(frame []byte) error {
+	c.mut.RLock()
+	codec := c.codec
+	c.mut.RUnlock()
+	if codec == nil {
+		return errors.New("the cluster communication is not started")
+	}
+
+	payload, err := codec.decode(frame, *c.server.Config().ClusterSettings.EnableExperimentalGossipEncryption)
+	if err != nil {
+		return err
+	}
+
+	var env envelope
+	if err := json.Unmarshal(payload, &env); err != nil {
+		return fmt.Errorf("failed to decode the cluster message: %w", err)
+	}
+	if env.Message == nil {
+		return errors.New("the cluster message is empty")
+	}
+	// Broadcasts through Redis are received by the sender as well.
+	if env.From == c.id {
+		return nil
+	}
+
+	switch {
+	case env.Reply:
+		c.handleReply(&env)
+	case env.RequestId != "":
+		go c.handleRequest(&env)
+	default:
+		c.handlersMut.RLock()
+		handler := c.handlers[env.Message.Event]
+		c.handlersMut.RUnlock()
+
+		if handler == nil {
+			c.server.Log().Debug("No handler for the cluster message", mlog.String("event", string(env.Message.Event)))
+			return nil
+		}
+		handler(env.Message)
+	}
+
+	return nil
+}
+
+func (c *Cluster) saveConfigHandler(msg *model.ClusterMessage) {
+	var cfg *model.Config
+	if err := json.Unmarshal(msg.Data, &cfg); err != nil {
+		c.server.Log().Warn("Failed to decode the configuration from the cluster", mlog.Err(err))
+		return
+	}
+
+	if _, _, appErr := c.server.SaveConfig(cfg, false); appErr != nil {
+		c.server.Log().Error("Failed to save the configuration from the cluster", mlog.Err(appErr))
+	}
+}
+
+func (c *Cluster) ConfigChanged(previousConfig *model.Config, newConfig *model.Config, sendToOtherServer bool) *model.AppError {
+	if !sendToOtherServer {
+		return nil
+	}
+
+	data, err := json.Marshal(newConfig)
+	if err != nil {
+		return model.NewAppError("ConfigChanged", "ent.cluster.json_encode.error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
+	c.SendClusterMessage(&model.ClusterMessage{
+		Event:            model.ClusterGossipEventRequestSaveConfig,
+		SendType:         model.ClusterSendReliable,
+		WaitForAllToSend: true,
+		Data:             data,
+	})
+
+	return nil
+}
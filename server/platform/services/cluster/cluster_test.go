@@ -0,0 +1,272 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package cluster
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+)
+
+const testPingInterval = 50 * time.Millisecond
+
+type testNode struct {
+	*Cluster
+	server *mockServer
+}
+
+func startTestNodes(t *testing.T, discovery *discoveryStore, count int, configure func(*model.Config)) []*testNode {
+	nodes := make([]*testNode, 0, count)
+	for i := range count {
+		if i > 0 {
+			// The leader is the oldest node, so make sure no two nodes start within the same millisecond.
+			time.Sleep(5 * time.Millisecond)
+		}
+		server := newMockServer(t, discovery, configure)
+		c := New(server)
+		c.pingInterval = testPingInterval
+		require.NoError(t, c.start())
+		t.Cleanup(c.StopInterNodeCommunication)
+
+		nodes = append(nodes, &testNode{Cluster: c, server: server})
+	}
+
+	require.Eventually(t, func() bool {
+		for _, node := range nodes {
+			if len(node.peers()) != count-1 {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+
+	return nodes
+}
+
+func TestClusterMembership(t *testing.T) {
+	discovery := newDiscoveryStore()
+	nodes := startTestNodes(t, discovery, 3, nil)
+
+	t.Run("the first node is the leader", func(t *testing.T) {
+		assert.True(t, nodes[0].IsLeader())
+		assert.False(t, nodes[1].IsLeader())
+		assert.False(t, nodes[2].IsLeader())
+	})
+
+	t.Run("cluster infos include every node", func(t *testing.T) {
+		infos := nodes[1].GetClusterInfos()
+		require.Len(t, infos, 3)
+
+		ids := make([]string, 0, len(infos))
+		for _, info := range infos {
+			ids = append(ids, info.Id)
+			assert.Equal(t, model.CurrentVersion, info.Version)
+			assert.Equal(t, "134", info.SchemaVersion)
+			assert.NotEmpty(t, info.ConfigHash)
+		}
+		assert.ElementsMatch(t, []string{nodes[0].GetClusterId(), nodes[1].GetClusterId(), nodes[2].GetClusterId()}, ids)
+	})
+
+	t.Run("the next node becomes the leader when the leader stops", func(t *testing.T) {
+		nodes[0].StopInterNodeCommunication()
+		assert.False(t, nodes[0].IsLeader())
+		assert.Equal(t, 2, discovery.count())
+
+		require.Eventually(t, func() bool {
+			return nodes[1].IsLeader() && len(nodes[1].peers()) == 1 && len(nodes[2].peers()) == 1
+		}, 5*time.Second, 10*time.Millisecond)
+		assert.False(t, nodes[2].IsLeader())
+
+		nodes[1].server.mut.Lock()
+		defer nodes[1].server.mut.Unlock()
+		assert.Equal(t, 2, nodes[1].server.leaderChanges)
+	})
+}
+
+func TestClusterMessages(t *testing.T) {
+	for name, configure := range map[string]func(*model.Config){
+		"signed": func(cfg *model.Config) {
+			*cfg.ClusterSettings.EnableGossipCompression = false
+		},
+		"compressed": nil,
+		"encrypted": func(cfg *model.Config) {
+			*cfg.ClusterSettings.EnableExperimentalGossipEncryption = true
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			nodes := startTestNodes(t, newDiscoveryStore(), 3, configure)
+
+			var (
+				mut      sync.Mutex
+				received = map[string][]string{}
+			)
+			for _, node := range nodes {
+				node.RegisterClusterMessageHandler(model.ClusterEventPublish, func(msg *model.ClusterMessage) {
+					mut.Lock()
+					defer mut.Unlock()
+					received[node.GetClusterId()] = append(received[node.GetClusterId()], string(msg.Data))
+				})
+			}
+			receivedBy := func(id string) []string {
+				mut.Lock()
+				defer mut.Unlock()
+				return received[id]
+			}
+
+			nodes[0].SendClusterMessage(&model.ClusterMessage{
+				Event:    model.ClusterEventPublish,
+				SendType: model.ClusterSendReliable,
+				Data:     []byte("broadcast"),
+			})
+			require.Eventually(t, func() bool {
+				return len(receivedBy(nodes[1].GetClusterId())) == 1 && len(receivedBy(nodes[2].GetClusterId())) == 1
+			}, 5*time.Second, 10*time.Millisecond)
+			assert.Empty(t, receivedBy(nodes[0].GetClusterId()))
+
+			err := nodes[0].SendClusterMessageToNode(nodes[2].GetClusterId(), &model.ClusterMessage{
+				Event: model.ClusterEventPublish,
+				Data:  []byte("direct"),
+			})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"broadcast", "direct"}, receivedBy(nodes[2].GetClusterId()))
+			assert.Equal(t, []string{"broadcast"}, receivedBy(nodes[1].GetClusterId()))
+			assert.Zero(t, nodes[0].HealthScore())
+
+			err = nodes[0].SendClusterMessageToNode(model.NewId(), &model.ClusterMessage{Event: model.ClusterEventPublish})
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestClusterRejectsForeignMessages(t *testing.T) {
+	nodes := startTestNodes(t, newDiscoveryStore(), 1, nil)
+
+	foreign, err := newCodec("another-cluster-key")
+	require.NoError(t, err)
+	payload, err := json.Marshal(&envelope{
+		From:    model.NewId(),
+		Message: &model.ClusterMessage{Event: model.ClusterEventPublish},
+	})
+	require.NoError(t, err)
+	frame, err := foreign.encode(payload, false, false)
+	require.NoError(t, err)
+
+	require.Error(t, nodes[0].receive(frame))
+}
+
+func TestClusterConfigChanged(t *testing.T) {
+	nodes := startTestNodes(t, newDiscoveryStore(), 2, nil)
+
+	cfg := nodes[0].server.Config().Clone()
+	*cfg.ServiceSettings.SiteURL = "http://cluster.example.com"
+
+	require.Nil(t, nodes[0].ConfigChanged(nodes[0].server.Config(), cfg, false))
+	assert.Empty(t, nodes[1].server.configs())
+
+	require.Nil(t, nodes[0].ConfigChanged(nodes[0].server.Config(), cfg, true))
+	require.Eventually(t, func() bool {
+		return len(nodes[1].server.configs()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "http://cluster.example.com", *nodes[1].server.configs()[0].ServiceSettings.SiteURL)
+	assert.Empty(t, nodes[0].server.configs())
+}
+
+func TestClusterGossip(t *testing.T) {
+	nodes := startTestNodes(t, newDiscoveryStore(), 3, nil)
+	rctx := request.TestContext(t)
+	for i, node := range nodes {
+		node.server.webConns = 10 * (i + 1)
+		node.server.userConns = i + 1
+		node.server.pluginStatuses = model.PluginStatuses{{PluginId: "plugin", ClusterId: node.GetClusterId()}}
+	}
+
+	t.Run("cluster stats", func(t *testing.T) {
+		stats, appErr := nodes[0].GetClusterStats(rctx)
+		require.Nil(t, appErr)
+		require.Len(t, stats, 2)
+
+		connections := map[string]int{}
+		for _, stat := range stats {
+			connections[stat.Id] = stat.TotalWebsocketConnections
+			assert.Equal(t, 3, stat.TotalReadDbConnections)
+			assert.Equal(t, 5, stat.TotalMasterDbConnections)
+		}
+		assert.Equal(t, map[string]int{nodes[1].GetClusterId(): 20, nodes[2].GetClusterId(): 30}, connections)
+	})
+
+	t.Run("plugin statuses", func(t *testing.T) {
+		statuses, appErr := nodes[1].GetPluginStatuses()
+		require.Nil(t, appErr)
+		require.Len(t, statuses, 2)
+		assert.ElementsMatch(t, []string{nodes[0].GetClusterId(), nodes[2].GetClusterId()}, []string{statuses[0].ClusterId, statuses[1].ClusterId})
+	})
+
+	t.Run("websocket connections", func(t *testing.T) {
+		count, appErr := nodes[0].WebConnCountForUser(model.NewId())
+		require.Nil(t, appErr)
+		assert.Equal(t, 5, count)
+
+		queues, err := nodes[0].GetWSQueues(model.NewId(), model.NewId(), 0)
+		require.NoError(t, err)
+		assert.Len(t, queues, 2)
+		assert.Contains(t, queues, nodes[1].GetClusterId())
+		assert.Contains(t, queues, nodes[2].GetClusterId())
+	})
+
+	t.Run("logs", func(t *testing.T) {
+		hostname, err := os.Hostname()
+		require.NoError(t, err)
+
+		logs, appErr := nodes[0].QueryLogs(rctx, 0, 10)
+		require.Nil(t, appErr)
+		require.Contains(t, logs, hostname)
+
+		lines, appErr := nodes[0].GetLogs(rctx, 0, 10)
+		require.Nil(t, appErr)
+		assert.Contains(t, lines, hostname)
+	})
+
+	t.Run("support packet", func(t *testing.T) {
+		hostname, err := os.Hostname()
+		require.NoError(t, err)
+
+		files, err := nodes[0].GenerateSupportPacket(rctx, &model.SupportPacketOptions{})
+		require.NoError(t, err)
+		require.Contains(t, files, hostname)
+		require.Len(t, files[hostname], 1)
+		assert.Equal(t, path.Join(hostname, "diagnostics.yaml"), files[hostname][0].Filename)
+	})
+
+	t.Run("no peers", func(t *testing.T) {
+		single := startTestNodes(t, newDiscoveryStore(), 1, nil)
+
+		stats, appErr := single[0].GetClusterStats(rctx)
+		require.Nil(t, appErr)
+		assert.Empty(t, stats)
+	})
+}
+
+func TestClusterRequestTimeout(t *testing.T) {
+	discovery := newDiscoveryStore()
+	nodes := startTestNodes(t, discovery, 2, nil)
+
+	// The second node doesn't answer anymore, but is still in the discovery table.
+	nodes[1].gossipHandlers[model.ClusterGossipEventRequestGetClusterStats] = func(_ []byte) ([]byte, error) {
+		time.Sleep(time.Second)
+		return nil, nil
+	}
+
+	_, appErr := nodes[0].GetClusterStats(request.TestContext(t))
+	require.NotNil(t, appErr)
+	assert.Equal(t, "ent.cluster.timeout.error", appErr.Id)
+}
@@ -0,0 +1,173 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package cluster
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+const (
+	frameCompressed byte = 1 << iota
+	frameEncrypted
+)
+
+const (
+	// frameMaxSkew is how far the time a frame was sent at may be from the time it is
+	// received at, which bounds how long a captured frame could be replayed.
+	frameMaxSkew = time.Minute
+	// seenNoncesSize is the number of nonces remembered to reject the frames received twice
+	// within frameMaxSkew.
+	seenNoncesSize = 16384
+)
+
+// codec turns messages into frames that only the nodes sharing the cluster key can read or
+// forge. A frame starts with a header made of a flags byte, the time it was sent at in Unix
+// milliseconds and a random nonce. The header is followed either by the AES-GCM sealed payload,
+// or by the HMAC-SHA256 of the header and payload followed by the plain payload. Frames sent
+// too long ago, or whose nonce was already seen, are rejected as replays.
+type codec struct {
+	macKey     []byte
+	aead       cipher.AEAD
+	seenNonces *lru.Cache[string, struct{}]
+	now        func() time.Time
+}
+
+func newCodec(secret string) (*codec, error) {
+	encryptionKey := sha256.Sum256([]byte("encryption:" + secret))
+	macKey := sha256.Sum256([]byte("signature:" + secret))
+
+	block, err := aes.NewCipher(encryptionKey[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	seenNonces, err := lru.New[string, struct{}](seenNoncesSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &codec{
+		macKey:     macKey[:],
+		aead:       aead,
+		seenNonces: seenNonces,
+		now:        time.Now,
+	}, nil
+}
+
+// headerSize is the size of the flags, the sent at time and the nonce starting a frame.
+func (c *codec) headerSize() int {
+	return 1 + 8 + c.aead.NonceSize()
+}
+
+func (c *codec) encode(payload []byte, compress, encrypt bool) ([]byte, error) {
+	var flags byte
+	if compress {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(payload); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+		payload = buf.Bytes()
+		flags |= frameCompressed
+	}
+
+	if encrypt {
+		flags |= frameEncrypted
+	}
+
+	header := make([]byte, c.headerSize())
+	header[0] = flags
+	binary.BigEndian.PutUint64(header[1:9], uint64(c.now().UnixMilli()))
+	if _, err := rand.Read(header[9:]); err != nil {
+		return nil, err
+	}
+
+	if encrypt {
+		frame := make([]byte, 0, len(header)+len(payload)+c.aead.Overhead())
+		frame = append(frame, header...)
+		return c.aead.Seal(frame, header[9:], payload, header[:9]), nil
+	}
+
+	frame := make([]byte, 0, len(header)+sha256.Size+len(payload))
+	frame = append(frame, header...)
+	frame = append(frame, c.mac(header, payload)...)
+	return append(frame, payload...), nil
+}
+
+// decode returns the payload of a frame, failing if it wasn't produced with the same key,
+// if it is a replay or, when requireEncryption is set, if it isn't encrypted.
+func (c *codec) decode(frame []byte, requireEncryption bool) ([]byte, error) {
+	headerSize := c.headerSize()
+	if len(frame) < headerSize {
+		return nil, errors.New("truncated frame")
+	}
+	header := frame[:headerSize]
+	flags := header[0]
+
+	var payload []byte
+	if flags&frameEncrypted != 0 {
+		var err error
+		payload, err = c.aead.Open(nil, header[9:], frame[headerSize:], header[:9])
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt frame: %w", err)
+		}
+	} else {
+		if requireEncryption {
+			return nil, errors.New("frame is not encrypted")
+		}
+		if len(frame) < headerSize+sha256.Size {
+			return nil, errors.New("truncated frame")
+		}
+		payload = frame[headerSize+sha256.Size:]
+		if !hmac.Equal(frame[headerSize:headerSize+sha256.Size], c.mac(header, payload)) {
+			return nil, errors.New("invalid frame signature")
+		}
+	}
+
+	// The header is authenticated at this point, so it can be trusted.
+	sentAt := time.UnixMilli(int64(binary.BigEndian.Uint64(header[1:9])))
+	if skew := c.now().Sub(sentAt); skew > frameMaxSkew || skew < -frameMaxSkew {
+		return nil, fmt.Errorf("frame sent at %s is outside of the accepted window", sentAt.UTC().Format(time.RFC3339))
+	}
+	if seen, _ := c.seenNonces.ContainsOrAdd(string(header[9:]), struct{}{}); seen {
+		return nil, errors.New("frame was already received")
+	}
+
+	if flags&frameCompressed != 0 {
+		zr, err := gzip.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		return io.ReadAll(zr)
+	}
+
+	return payload, nil
+}
+
+func (c *codec) mac(header, payload []byte) []byte {
+	h := hmac.New(sha256.New, c.macKey)
+	h.Write(header)
+	h.Write(payload)
+	return h.Sum(nil)
+}
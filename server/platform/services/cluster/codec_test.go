@@ -0,0 +1,117 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodec(t *testing.T) {
+	c, err := newCodec("cluster-key")
+	require.NoError(t, err)
+	payload := []byte(`{"event":"publish","data":"some data that compresses, some data that compresses"}`)
+
+	for name, tc := range map[string]struct {
+		compress bool
+		encrypt  bool
+	}{
+		"signed":                {},
+		"compressed":            {compress: true},
+		"encrypted":             {encrypt: true},
+		"compressed, encrypted": {compress: true, encrypt: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			frame, err := c.encode(payload, tc.compress, tc.encrypt)
+			require.NoError(t, err)
+
+			decoded, err := c.decode(frame, tc.encrypt)
+			require.NoError(t, err)
+			assert.Equal(t, payload, decoded)
+
+			if tc.encrypt {
+				assert.NotContains(t, string(frame), "publish")
+			}
+
+			t.Run("tampered", func(t *testing.T) {
+				tampered := append([]byte{}, frame...)
+				tampered[len(tampered)-1] ^= 0xff
+				_, err := c.decode(tampered, false)
+				require.Error(t, err)
+			})
+
+			t.Run("other key", func(t *testing.T) {
+				other, err := newCodec("other-key")
+				require.NoError(t, err)
+				_, err = other.decode(frame, false)
+				require.Error(t, err)
+			})
+		})
+	}
+
+	t.Run("encryption required", func(t *testing.T) {
+		frame, err := c.encode(payload, false, false)
+		require.NoError(t, err)
+		_, err = c.decode(frame, true)
+		require.Error(t, err)
+	})
+
+	t.Run("flags can't be changed", func(t *testing.T) {
+		frame, err := c.encode(payload, false, true)
+		require.NoError(t, err)
+		frame[0] |= frameCompressed
+		_, err = c.decode(frame, false)
+		require.Error(t, err)
+	})
+
+	t.Run("truncated frames", func(t *testing.T) {
+		for _, frame := range [][]byte{nil, {0}, {frameEncrypted, 1, 2}, make([]byte, c.headerSize()+1)} {
+			_, err := c.decode(frame, false)
+			require.Error(t, err)
+		}
+	})
+
+	t.Run("replayed frame", func(t *testing.T) {
+		for _, encrypt := range []bool{false, true} {
+			frame, err := c.encode(payload, false, encrypt)
+			require.NoError(t, err)
+			_, err = c.decode(frame, false)
+			require.NoError(t, err)
+			_, err = c.decode(frame, false)
+			require.Error(t, err)
+		}
+	})
+
+	t.Run("frame outside of the accepted window", func(t *testing.T) {
+		sender, err := newCodec("cluster-key")
+		require.NoError(t, err)
+
+		for _, offset := range []time.Duration{-2 * frameMaxSkew, 2 * frameMaxSkew} {
+			sender.now = func() time.Time { return time.Now().Add(offset) }
+			frame, err := sender.encode(payload, false, true)
+			require.NoError(t, err)
+			_, err = c.decode(frame, true)
+			require.Error(t, err)
+		}
+
+		sender.now = func() time.Time { return time.Now().Add(-frameMaxSkew / 2) }
+		frame, err := sender.encode(payload, false, true)
+		require.NoError(t, err)
+		_, err = c.decode(frame, true)
+		require.NoError(t, err)
+	})
+
+	t.Run("sent at time can't be changed", func(t *testing.T) {
+		for _, encrypt := range []bool{false, true} {
+			frame, err := c.encode(payload, false, encrypt)
+			require.NoError(t, err)
+			frame[8] ^= 0x01
+			_, err = c.decode(frame, false)
+			require.Error(t, err)
+		}
+	})
+}
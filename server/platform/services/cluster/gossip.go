@@ -0,0 +1,448 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+)
+
+const logSeparator = "-----------------------------------------------------------------------------------------------------------"
+
+// gossipHandler answers a request of another node, returning the data of the response.
+type gossipHandler func(data []byte) ([]byte, error)
+
+type gossipResponse struct {
+	node *model.ClusterDiscovery
+	data []byte
+}
+
+type logsRequest struct {
+	Page    int `json:"page"`
+	PerPage int `json:"per_page"`
+}
+
+type logsResponse struct {
+	Hostname string   `json:"hostname"`
+	Lines    []string `json:"lines"`
+}
+
+type supportPacketResponse struct {
+	Hostname string           `json:"hostname"`
+	Files    []model.FileData `json:"files"`
+}
+
+type wsQueuesRequest struct {
+	UserId       string `json:"user_id"`
+	ConnectionId string `json:"connection_id"`
+	SeqNum       int64  `json:"seq_num"`
+}
+
+var responseEvents = map[model.ClusterEvent]model.ClusterEvent{
+	model.ClusterGossipEventRequestGetLogs:               model.ClusterGossipEventResponseGetLogs,
+	model.ClusterGossipEventRequestGenerateSupportPacket: model.ClusterGossipEventResponseGenerateSupportPacket,
+	model.ClusterGossipEventRequestGetClusterStats:       model.ClusterGossipEventResponseGetClusterStats,
+	model.ClusterGossipEventRequestGetPluginStatuses:     model.ClusterGossipEventResponseGetPluginStatuses,
+	model.ClusterGossipEventRequestWebConnCount:          model.ClusterGossipEventResponseWebConnCount,
+	model.ClusterGossipEventRequestWSQueues:              model.ClusterGossipEventResponseWSQueues,
+	model.ClusterGossipEventRequestGetClusterInfo:        model.ClusterGossipEventResponseGetClusterInfo,
+}
+
+func (c *Cluster) registerGossipHandlers() {
+	c.gossipHandlers = map[model.ClusterEvent]gossipHandler{
+		model.ClusterGossipEventRequestGetLogs:               c.getLogsHandler,
+		model.ClusterGossipEventRequestGenerateSupportPacket: c.generateSupportPacketHandler,
+		model.ClusterGossipEventRequestGetClusterStats:       c.getClusterStatsHandler,
+		model.ClusterGossipEventRequestGetPluginStatuses:     c.getPluginStatusesHandler,
+		model.ClusterGossipEventRequestWebConnCount:          c.webConnCountHandler,
+		model.ClusterGossipEventRequestWSQueues:              c.wsQueuesHandler,
+		model.ClusterGossipEventRequestGetClusterInfo:        c.getClusterInfoHandler,
+	}
+}
+
+// request sends a request to all the other nodes and waits for their responses.
+func (c *Cluster) request(rctx request.CTX, event model.ClusterEvent, data []byte) ([]gossipResponse, *model.AppError) {
+	nodes := c.peers()
+	if len(nodes) == 0 {
+		return nil, nil
+	}
+
+	start := time.Now()
+	if metrics := c.server.Metrics(); metrics != nil {
+		metrics.IncrementClusterRequest()
+		defer func() {
+			metrics.ObserveClusterRequestDuration(time.Since(start).Seconds())
+		}()
+	}
+
+	requestId := model.NewId()
+	frame, err := c.encode(&envelope{
+		From:      c.id,
+		RequestId: requestId,
+		Message:   &model.ClusterMessage{Event: event, Data: data},
+	})
+	if err != nil {
+		return nil, model.NewAppError("request", "ent.cluster.json_encode.error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
+	replies := make(chan *envelope, len(nodes))
+	c.requestsMut.Lock()
+	c.requests[requestId] = replies
+	c.requestsMut.Unlock()
+	defer func() {
+		c.requestsMut.Lock()
+		delete(c.requests, requestId)
+		c.requestsMut.Unlock()
+	}()
+
+	failed := c.deliver(&outgoing{nodes: nodes, frame: frame, broadcast: true})
+
+	pending := make(map[string]*model.ClusterDiscovery, len(nodes))
+	for _, node := range nodes {
+		if _, ok := failed[node.Id]; !ok {
+			pending[node.Id] = node
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(rctx.Context(), c.requestTimeout())
+	defer cancel()
+
+	responses := make([]gossipResponse, 0, len(pending))
+	for len(pending) > 0 {
+		select {
+		case reply := <-replies:
+			node, ok := pending[reply.From]
+			if !ok {
+				continue
+			}
+			delete(pending, reply.From)
+
+			if reply.Error != "" {
+				return responses, model.NewAppError("request", "ent.cluster.request_failed.app_error", map[string]any{"NodeId": reply.From}, reply.Error, http.StatusInternalServerError)
+			}
+			responses = append(responses, gossipResponse{node: node, data: reply.Message.Data})
+		case <-ctx.Done():
+			return responses, model.NewAppError("request", "ent.cluster.timeout.error", nil, "", http.StatusInternalServerError).Wrap(ctx.Err())
+		}
+	}
+
+	return responses, nil
+}
+
+func (c *Cluster) requestTimeout() time.Duration {
+	if c.pingInterval < PingInterval {
+		// Tests ping faster, and don't wait that long either.
+		return 2 * missedPingsLimit * c.pingInterval
+	}
+	return requestTimeout
+}
+
+func (c *Cluster) handleReply(env *envelope) {
+	c.requestsMut.Lock()
+	replies, ok := c.requests[env.RequestId]
+	c.requestsMut.Unlock()
+	if !ok {
+		return
+	}
+
+	select {
+	case replies <- env:
+	default:
+	}
+}
+
+func (c *Cluster) handleRequest(env *envelope) {
+	logger := c.server.Log().With(mlog.String("event", string(env.Message.Event)), mlog.String("node_id", env.From))
+
+	handler, ok := c.gossipHandlers[env.Message.Event]
+	if !ok {
+		logger.Warn("Received a cluster request for an unknown event")
+		return
+	}
+
+	reply := &envelope{
+		From:      c.id,
+		RequestId: env.RequestId,
+		Reply:     true,
+		Message:   &model.ClusterMessage{Event: responseEvents[env.Message.Event]},
+	}
+	data, err := handler(env.Message.Data)
+	if err != nil {
+		reply.Error = err.Error()
+	} else {
+		reply.Message.Data = data
+	}
+
+	// The requesting node may have joined the cluster since the members were last read.
+	node := c.member(env.From)
+	if node == nil {
+		c.refreshMembers()
+		if node = c.member(env.From); node == nil {
+			logger.Warn("Received a cluster request from an unknown node")
+			return
+		}
+	}
+
+	frame, err := c.encode(reply)
+	if err != nil {
+		logger.Error("Failed to encode the cluster response", mlog.Err(err))
+		return
+	}
+	c.deliver(&outgoing{nodes: []*model.ClusterDiscovery{node}, frame: frame})
+}
+
+// GetClusterInfos returns the information of all the nodes, including this one. The nodes that
+// failed to answer are logged and left out.
+func (c *Cluster) GetClusterInfos() []*model.ClusterInfo {
+	infos := []*model.ClusterInfo{c.GetMyClusterInfo()}
+
+	responses, appErr := c.request(request.EmptyContext(c.server.Log()), model.ClusterGossipEventRequestGetClusterInfo, nil)
+	if appErr != nil {
+		c.server.Log().Warn("Failed to get the information of some cluster nodes", mlog.Err(appErr))
+	}
+	for _, response := range responses {
+		var info *model.ClusterInfo
+		if err := json.Unmarshal(response.data, &info); err != nil {
+			c.server.Log().Warn("Failed to decode the cluster node information", mlog.String("node_id", response.node.Id), mlog.Err(err))
+			continue
+		}
+		infos = append(infos, info)
+	}
+
+	return infos
+}
+
+func (c *Cluster) getClusterInfoHandler(_ []byte) ([]byte, error) {
+	return json.Marshal(c.GetMyClusterInfo())
+}
+
+func (c *Cluster) GetClusterStats(rctx request.CTX) ([]*model.ClusterStats, *model.AppError) {
+	responses, appErr := c.request(rctx, model.ClusterGossipEventRequestGetClusterStats, nil)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	stats := make([]*model.ClusterStats, 0, len(responses))
+	for _, response := range responses {
+		var stat *model.ClusterStats
+		if err := json.Unmarshal(response.data, &stat); err != nil {
+			return nil, model.NewAppError("GetClusterStats", "ent.cluster.json_encode.error", nil, "", http.StatusInternalServerError).Wrap(err)
+		}
+		stats = append(stats, stat)
+	}
+
+	return stats, nil
+}
+
+func (c *Cluster) getClusterStatsHandler(_ []byte) ([]byte, error) {
+	store := c.server.GetStore()
+	return json.Marshal(&model.ClusterStats{
+		Id:                        c.id,
+		TotalWebsocketConnections: c.server.TotalWebsocketConnections(),
+		TotalReadDbConnections:    store.TotalReadDbConnections(),
+		TotalMasterDbConnections:  store.TotalMasterDbConnections(),
+	})
+}
+
+func (c *Cluster) GetLogs(rctx request.CTX, page, perPage int) ([]string, *model.AppError) {
+	logs, appErr := c.QueryLogs(rctx, page, perPage)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	hostnames := make([]string, 0, len(logs))
+	for hostname := range logs {
+		hostnames = append(hostnames, hostname)
+	}
+	sort.Strings(hostnames)
+
+	// Each node's lines are preceded by the same header as the ones of this node.
+	var lines []string
+	for _, hostname := range hostnames {
+		lines = append(lines, logSeparator, logSeparator, hostname, logSeparator, logSeparator)
+		lines = append(lines, logs[hostname]...)
+	}
+
+	return lines, nil
+}
+
+func (c *Cluster) QueryLogs(rctx request.CTX, page, perPage int) (map[string][]string, *model.AppError) {
+	data, err := json.Marshal(&logsRequest{Page: page, PerPage: perPage})
+	if err != nil {
+		return nil, model.NewAppError("QueryLogs", "ent.cluster.json_encode.error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
+	responses, appErr := c.request(rctx, model.ClusterGossipEventRequestGetLogs, data)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	logs := make(map[string][]string, len(responses))
+	for _, response := range responses {
+		var logsResp logsResponse
+		if err := json.Unmarshal(response.data, &logsResp); err != nil {
+			return nil, model.NewAppError("QueryLogs", "ent.cluster.json_encode.error", nil, "", http.StatusInternalServerError).Wrap(err)
+		}
+		logs[logsResp.Hostname] = logsResp.Lines
+	}
+
+	return logs, nil
+}
+
+func (c *Cluster) getLogsHandler(data []byte) ([]byte, error) {
+	var logsReq logsRequest
+	if err := json.Unmarshal(data, &logsReq); err != nil {
+		return nil, err
+	}
+
+	rctx := request.EmptyContext(c.server.Log())
+	lines, appErr := c.server.GetLogsSkipSend(rctx, logsReq.Page, logsReq.PerPage, &model.LogFilter{})
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	return json.Marshal(&logsResponse{Hostname: c.myHostname(), Lines: lines})
+}
+
+func (c *Cluster) GenerateSupportPacket(rctx request.CTX, options *model.SupportPacketOptions) (map[string][]model.FileData, error) {
+	data, err := json.Marshal(options)
+	if err != nil {
+		return nil, err
+	}
+
+	responses, appErr := c.request(rctx, model.ClusterGossipEventRequestGenerateSupportPacket, data)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	files := make(map[string][]model.FileData, len(responses))
+	for _, response := range responses {
+		var packet supportPacketResponse
+		if err := json.Unmarshal(response.data, &packet); err != nil {
+			return nil, err
+		}
+		for i := range packet.Files {
+			packet.Files[i].Filename = path.Join(packet.Hostname, packet.Files[i].Filename)
+		}
+		files[packet.Hostname] = packet.Files
+	}
+
+	return files, nil
+}
+
+func (c *Cluster) generateSupportPacketHandler(data []byte) ([]byte, error) {
+	var options *model.SupportPacketOptions
+	if err := json.Unmarshal(data, &options); err != nil {
+		return nil, err
+	}
+
+	rctx := request.EmptyContext(c.server.Log())
+	files, err := c.server.GenerateSupportPacket(rctx, options)
+	if err != nil {
+		// Some files may still have been generated, which are worth sending.
+		rctx.Logger().Warn("Failed to generate parts of the support packet", mlog.Err(err))
+	}
+
+	return json.Marshal(&supportPacketResponse{Hostname: c.myHostname(), Files: files})
+}
+
+func (c *Cluster) GetPluginStatuses() (model.PluginStatuses, *model.AppError) {
+	responses, appErr := c.request(request.EmptyContext(c.server.Log()), model.ClusterGossipEventRequestGetPluginStatuses, nil)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	var statuses model.PluginStatuses
+	for _, response := range responses {
+		var nodeStatuses model.PluginStatuses
+		if err := json.Unmarshal(response.data, &nodeStatuses); err != nil {
+			return nil, model.NewAppError("GetPluginStatuses", "ent.cluster.json_encode.error", nil, "", http.StatusInternalServerError).Wrap(err)
+		}
+		statuses = append(statuses, nodeStatuses...)
+	}
+
+	return statuses, nil
+}
+
+func (c *Cluster) getPluginStatusesHandler(_ []byte) ([]byte, error) {
+	statuses, appErr := c.server.GetPluginStatuses()
+	if appErr != nil {
+		return nil, appErr
+	}
+	return json.Marshal(statuses)
+}
+
+func (c *Cluster) WebConnCountForUser(userID string) (int, *model.AppError) {
+	responses, appErr := c.request(request.EmptyContext(c.server.Log()), model.ClusterGossipEventRequestWebConnCount, []byte(userID))
+	if appErr != nil {
+		return 0, appErr
+	}
+
+	count := 0
+	for _, response := range responses {
+		var nodeCount int
+		if err := json.Unmarshal(response.data, &nodeCount); err != nil {
+			return 0, model.NewAppError("WebConnCountForUser", "ent.cluster.json_encode.error", nil, "", http.StatusInternalServerError).Wrap(err)
+		}
+		count += nodeCount
+	}
+
+	return count, nil
+}
+
+func (c *Cluster) webConnCountHandler(data []byte) ([]byte, error) {
+	return json.Marshal(c.server.WebConnCountForUser(string(data)))
+}
+
+func (c *Cluster) GetWSQueues(userID, connectionID string, seqNum int64) (map[string]*model.WSQueues, error) {
+	data, err := json.Marshal(&wsQueuesRequest{UserId: userID, ConnectionId: connectionID, SeqNum: seqNum})
+	if err != nil {
+		return nil, err
+	}
+
+	responses, appErr := c.request(request.EmptyContext(c.server.Log()), model.ClusterGossipEventRequestWSQueues, data)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	queues := make(map[string]*model.WSQueues, len(responses))
+	for _, response := range responses {
+		var queue *model.WSQueues
+		if err := json.Unmarshal(response.data, &queue); err != nil {
+			return nil, err
+		}
+		queues[response.node.Id] = queue
+	}
+
+	return queues, nil
+}
+
+func (c *Cluster) wsQueuesHandler(data []byte) ([]byte, error) {
+	var wsReq wsQueuesRequest
+	if err := json.Unmarshal(data, &wsReq); err != nil {
+		return nil, err
+	}
+
+	queue, err := c.server.GetWSQueues(wsReq.UserId, wsReq.ConnectionId, wsReq.SeqNum)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the websocket queues: %w", err)
+	}
+	return json.Marshal(queue)
+}
+
+func (c *Cluster) myHostname() string {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+	return strings.TrimSpace(c.hostname)
+}
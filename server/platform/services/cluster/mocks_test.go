@@ -0,0 +1,188 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package cluster
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/plugin/plugintest/mock"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+	"github.com/mattermost/mattermost/server/v8/channels/store/storetest/mocks"
+	"github.com/mattermost/mattermost/server/v8/einterfaces"
+	"github.com/mattermost/mattermost/server/v8/platform/services/cache"
+)
+
+// discoveryStore keeps the cluster discovery rows in memory, to be shared by the nodes of a test.
+type discoveryStore struct {
+	mut   sync.Mutex
+	nodes map[string]*model.ClusterDiscovery
+}
+
+func newDiscoveryStore() *discoveryStore {
+	return &discoveryStore{nodes: map[string]*model.ClusterDiscovery{}}
+}
+
+func (ds *discoveryStore) find(cd *model.ClusterDiscovery) *model.ClusterDiscovery {
+	for _, node := range ds.nodes {
+		if node.Type == cd.Type && node.ClusterName == cd.ClusterName && node.Hostname == cd.Hostname && node.GossipPort == cd.GossipPort {
+			return node
+		}
+	}
+	return nil
+}
+
+func (ds *discoveryStore) Save(cd *model.ClusterDiscovery) error {
+	cd.PreSave()
+	if err := cd.IsValid(); err != nil {
+		return err
+	}
+
+	ds.mut.Lock()
+	defer ds.mut.Unlock()
+	saved := *cd
+	ds.nodes[cd.Id] = &saved
+	return nil
+}
+
+func (ds *discoveryStore) Delete(cd *model.ClusterDiscovery) (bool, error) {
+	ds.mut.Lock()
+	defer ds.mut.Unlock()
+	if node := ds.find(cd); node != nil {
+		delete(ds.nodes, node.Id)
+		return true, nil
+	}
+	return false, nil
+}
+
+func (ds *discoveryStore) Exists(cd *model.ClusterDiscovery) (bool, error) {
+	ds.mut.Lock()
+	defer ds.mut.Unlock()
+	return ds.find(cd) != nil, nil
+}
+
+func (ds *discoveryStore) GetAll(clusterType, clusterName string) ([]*model.ClusterDiscovery, error) {
+	ds.mut.Lock()
+	defer ds.mut.Unlock()
+	var nodes []*model.ClusterDiscovery
+	for _, node := range ds.nodes {
+		if node.Type == clusterType && node.ClusterName == clusterName {
+			found := *node
+			nodes = append(nodes, &found)
+		}
+	}
+	return nodes, nil
+}
+
+func (ds *discoveryStore) SetLastPingAt(cd *model.ClusterDiscovery) error {
+	ds.mut.Lock()
+	defer ds.mut.Unlock()
+	if node := ds.find(cd); node != nil {
+		node.LastPingAt = model.GetMillis()
+	}
+	return nil
+}
+
+func (ds *discoveryStore) Cleanup() error {
+	return nil
+}
+
+func (ds *discoveryStore) count() int {
+	ds.mut.Lock()
+	defer ds.mut.Unlock()
+	return len(ds.nodes)
+}
+
+type mockServer struct {
+	config    *model.Config
+	logger    *mlog.Logger
+	store     store.Store
+	hostname  string
+	webConns  int
+	userConns int
+
+	mut            sync.Mutex
+	savedConfigs   []*model.Config
+	leaderChanges  int
+	pluginStatuses model.PluginStatuses
+}
+
+func newMockServer(t *testing.T, discovery *discoveryStore, configure func(*model.Config)) *mockServer {
+	config := &model.Config{}
+	config.SetDefaults()
+	*config.ClusterSettings.Enable = true
+	*config.ClusterSettings.ClusterName = "test"
+	*config.ClusterSettings.BindAddress = "127.0.0.1"
+	*config.ClusterSettings.AdvertiseAddress = "127.0.0.1"
+	*config.ClusterSettings.GossipPort = 0
+	if configure != nil {
+		configure(config)
+	}
+
+	systemStore := &mocks.SystemStore{}
+	systemStore.On("InsertIfExists", mock.AnythingOfType("*model.System")).Return(&model.System{
+		Name:  model.SystemClusterEncryptionKey,
+		Value: "test-cluster-key",
+	}, nil)
+
+	storeMock := &mocks.Store{}
+	storeMock.On("ClusterDiscovery").Return(discovery)
+	storeMock.On("System").Return(systemStore)
+	storeMock.On("GetDBSchemaVersion").Return(134, nil)
+	storeMock.On("TotalReadDbConnections").Return(3)
+	storeMock.On("TotalMasterDbConnections").Return(5)
+
+	return &mockServer{
+		config:   config,
+		logger:   mlog.CreateConsoleTestLogger(t),
+		store:    storeMock,
+		hostname: "host-" + model.NewId(),
+	}
+}
+
+func (ms *mockServer) Config() *model.Config                 { return ms.config }
+func (ms *mockServer) Log() mlog.LoggerIFace                 { return ms.logger }
+func (ms *mockServer) GetStore() store.Store                 { return ms.store }
+func (ms *mockServer) CacheProvider() cache.Provider         { return cache.NewProvider() }
+func (ms *mockServer) Metrics() einterfaces.MetricsInterface { return nil }
+func (ms *mockServer) TotalWebsocketConnections() int        { return ms.webConns }
+func (ms *mockServer) WebConnCountForUser(userID string) int { return ms.userConns }
+
+func (ms *mockServer) InvokeClusterLeaderChangedListeners() {
+	ms.mut.Lock()
+	defer ms.mut.Unlock()
+	ms.leaderChanges++
+}
+
+func (ms *mockServer) SaveConfig(newCfg *model.Config, sendConfigChangeClusterMessage bool) (*model.Config, *model.Config, *model.AppError) {
+	ms.mut.Lock()
+	defer ms.mut.Unlock()
+	ms.savedConfigs = append(ms.savedConfigs, newCfg)
+	return ms.config, newCfg, nil
+}
+
+func (ms *mockServer) GetPluginStatuses() (model.PluginStatuses, *model.AppError) {
+	return ms.pluginStatuses, nil
+}
+
+func (ms *mockServer) GetLogsSkipSend(rctx request.CTX, page, perPage int, logFilter *model.LogFilter) ([]string, *model.AppError) {
+	return []string{"log line of " + ms.hostname}, nil
+}
+
+func (ms *mockServer) GenerateSupportPacket(rctx request.CTX, options *model.SupportPacketOptions) ([]model.FileData, error) {
+	return []model.FileData{{Filename: "diagnostics.yaml", Body: []byte(ms.hostname)}}, nil
+}
+
+func (ms *mockServer) GetWSQueues(userID, connectionID string, seqNum int64) (*model.WSQueues, error) {
+	return &model.WSQueues{}, nil
+}
+
+func (ms *mockServer) configs() []*model.Config {
+	ms.mut.Lock()
+	defer ms.mut.Unlock()
+	return ms.savedConfigs
+}
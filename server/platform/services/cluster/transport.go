@@ -0,0 +1,161 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+const (
+	messagePath    = "/cluster/message"
+	maxMessageSize = 512 * 1024 * 1024
+)
+
+// transport carries the frames between the nodes of the cluster. It knows nothing about
+// their content, which the cluster authenticates itself.
+type transport interface {
+	// start starts passing the frames sent to this node to receive.
+	start(receive func(frame []byte) error) error
+	stop()
+	// port returns the port other nodes reach this node on, or zero if they don't connect to it.
+	port() int
+	// send delivers the frame to a single node.
+	send(ctx context.Context, node *model.ClusterDiscovery, frame []byte) error
+	// broadcast delivers the frame to all the nodes, returning the errors of the nodes it failed to reach.
+	broadcast(ctx context.Context, nodes []*model.ClusterDiscovery, frame []byte) map[string]error
+}
+
+// httpTransport sends the frames directly to the other nodes, each node serving HTTP on its
+// gossip port.
+type httpTransport struct {
+	logger   mlog.LoggerIFace
+	address  string
+	client   *http.Client
+	listener net.Listener
+	server   *http.Server
+}
+
+func newHTTPTransport(logger mlog.LoggerIFace, bindAddress string, port int) *httpTransport {
+	return &httpTransport{
+		logger:  logger,
+		address: net.JoinHostPort(bindAddress, strconv.Itoa(port)),
+		client:  &http.Client{},
+	}
+}
+
+func (t *httpTransport) start(receive func(frame []byte) error) error {
+	listener, err := net.Listen("tcp", t.address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", t.address, err)
+	}
+	t.listener = listener
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(messagePath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		frame, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxMessageSize))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if err := receive(frame); err != nil {
+			t.logger.Warn("Rejected cluster message", mlog.String("remote_addr", r.RemoteAddr), mlog.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	t.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		if err := t.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			t.logger.Error("Cluster message server stopped", mlog.Err(err))
+		}
+	}()
+
+	return nil
+}
+
+func (t *httpTransport) stop() {
+	if t.server == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := t.server.Shutdown(ctx); err != nil {
+		t.logger.Warn("Failed to shut down the cluster message server", mlog.Err(err))
+	}
+	t.client.CloseIdleConnections()
+}
+
+func (t *httpTransport) port() int {
+	if t.listener == nil {
+		return 0
+	}
+	return t.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (t *httpTransport) send(ctx context.Context, node *model.ClusterDiscovery, frame []byte) error {
+	url := "http://" + net.JoinHostPort(node.Hostname, strconv.Itoa(int(node.GossipPort))) + messagePath
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(frame))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("node %s responded with status %d", node.Id, resp.StatusCode)
+	}
+	return nil
+}
+
+func (t *httpTransport) broadcast(ctx context.Context, nodes []*model.ClusterDiscovery, frame []byte) map[string]error {
+	var (
+		wg     sync.WaitGroup
+		mut    sync.Mutex
+		failed = map[string]error{}
+	)
+	for _, node := range nodes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := t.send(ctx, node, frame); err != nil {
+				mut.Lock()
+				failed[node.Id] = err
+				mut.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return failed
+}
@@ -0,0 +1,226 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package cluster
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+)
+
+const (
+	// PostgreSQL rejects notifications with a payload of 8000 bytes or more.
+	maxNotificationSize = 7999
+
+	notificationInline  = 'i'
+	notificationPayload = 'p'
+
+	// notificationQueueSize is the number of notifications received but not handled yet.
+	notificationQueueSize = 1000
+
+	listenerMinReconnectInterval = time.Second
+	listenerMaxReconnectInterval = 30 * time.Second
+
+	payloadCleanupInterval = time.Minute
+	payloadRetention       = 5 * time.Minute
+)
+
+// listener is the part of pq.Listener the database transport uses.
+type listener interface {
+	Listen(channel string) error
+	NotificationChannel() <-chan *pq.Notification
+	Close() error
+}
+
+// databaseTransport sends the frames through PostgreSQL notifications, on one channel shared
+// by the whole cluster for broadcasts and one channel per node. Frames too large for a
+// notification are saved in the ClusterPayloads table, the notification carrying their id.
+type databaseTransport struct {
+	logger      mlog.LoggerIFace
+	payloads    store.ClusterPayloadStore
+	newListener func(callback pq.EventCallbackType) listener
+	prefix      string
+	nodeId      string
+
+	listener listener
+	stopping chan struct{}
+	stopped  sync.WaitGroup
+}
+
+func newDatabaseTransport(logger mlog.LoggerIFace, payloads store.ClusterPayloadStore, dataSource, clusterName, nodeId string) *databaseTransport {
+	// Channel names are identifiers, limited to 63 characters, so the cluster name is hashed.
+	hash := sha256.Sum256([]byte(clusterName))
+
+	return &databaseTransport{
+		logger:   logger,
+		payloads: payloads,
+		newListener: func(callback pq.EventCallbackType) listener {
+			return pq.NewListener(dataSource, listenerMinReconnectInterval, listenerMaxReconnectInterval, callback)
+		},
+		prefix: "cluster_" + hex.EncodeToString(hash[:8]) + "_",
+		nodeId: nodeId,
+	}
+}
+
+func (t *databaseTransport) start(receive func(frame []byte) error) error {
+	t.listener = t.newListener(func(event pq.ListenerEventType, err error) {
+		switch event {
+		case pq.ListenerEventDisconnected:
+			t.logger.Warn("Lost the cluster database notifications connection", mlog.Err(err))
+		case pq.ListenerEventConnectionAttemptFailed:
+			t.logger.Warn("Failed to connect to the database for the cluster notifications", mlog.Err(err))
+		case pq.ListenerEventReconnected:
+			t.logger.Info("Reconnected to the database for the cluster notifications")
+		}
+	})
+
+	for _, channel := range []string{t.broadcastChannel(), t.nodeChannel(t.nodeId)} {
+		if err := t.listener.Listen(channel); err != nil {
+			t.listener.Close()
+			return fmt.Errorf("failed to listen to the %s channel: %w", channel, err)
+		}
+	}
+
+	// The notifications are handled by a worker, as fetching the large frames from the database
+	// and handling the messages must not hold up the listener connection.
+	notifications := make(chan *pq.Notification, notificationQueueSize)
+
+	t.stopping = make(chan struct{})
+	t.stopped.Add(2)
+	go func() {
+		defer t.stopped.Done()
+
+		for {
+			select {
+			case notification := <-t.listener.NotificationChannel():
+				// The listener sends nil after reconnecting, as notifications may have been missed.
+				if notification == nil {
+					t.logger.Warn("Cluster messages may have been lost while reconnecting to the database")
+					continue
+				}
+
+				select {
+				case notifications <- notification:
+				case <-t.stopping:
+					return
+				}
+			case <-t.stopping:
+				return
+			}
+		}
+	}()
+
+	go func() {
+		defer t.stopped.Done()
+
+		cleanup := time.NewTicker(payloadCleanupInterval)
+		defer cleanup.Stop()
+
+		for {
+			select {
+			case notification := <-notifications:
+				frame, err := t.frame(notification.Extra)
+				if err != nil {
+					t.logger.Warn("Failed to read the cluster notification", mlog.String("channel", notification.Channel), mlog.Err(err))
+					continue
+				}
+				if err := receive(frame); err != nil {
+					t.logger.Warn("Rejected cluster message", mlog.Err(err))
+				}
+			case <-cleanup.C:
+				if _, err := t.payloads.DeleteOlderThan(model.GetMillis() - payloadRetention.Milliseconds()); err != nil {
+					t.logger.Warn("Failed to delete the old cluster payloads", mlog.Err(err))
+				}
+			case <-t.stopping:
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (t *databaseTransport) stop() {
+	if t.stopping == nil {
+		return
+	}
+
+	close(t.stopping)
+	t.stopped.Wait()
+	if err := t.listener.Close(); err != nil {
+		t.logger.Warn("Failed to close the cluster notifications listener", mlog.Err(err))
+	}
+}
+
+func (t *databaseTransport) port() int {
+	return 0
+}
+
+func (t *databaseTransport) send(_ context.Context, node *model.ClusterDiscovery, frame []byte) error {
+	return t.notify(t.nodeChannel(node.Id), frame)
+}
+
+func (t *databaseTransport) broadcast(_ context.Context, nodes []*model.ClusterDiscovery, frame []byte) map[string]error {
+	if err := t.notify(t.broadcastChannel(), frame); err != nil {
+		failed := make(map[string]error, len(nodes))
+		for _, node := range nodes {
+			failed[node.Id] = err
+		}
+		return failed
+	}
+
+	return nil
+}
+
+func (t *databaseTransport) notify(channel string, frame []byte) error {
+	message := string(notificationInline) + base64.StdEncoding.EncodeToString(frame)
+	if len(message) > maxNotificationSize {
+		payload := &model.ClusterPayload{Data: frame}
+		if err := t.payloads.Save(payload); err != nil {
+			return err
+		}
+		message = string(notificationPayload) + payload.Id
+	}
+
+	return t.payloads.Notify(channel, message)
+}
+
+// frame returns the frame carried by a notification.
+func (t *databaseTransport) frame(message string) ([]byte, error) {
+	if message == "" {
+		return nil, errors.New("empty notification")
+	}
+
+	switch message[0] {
+	case notificationInline:
+		return base64.StdEncoding.DecodeString(message[1:])
+	case notificationPayload:
+		payload, err := t.payloads.Get(message[1:])
+		if err != nil {
+			return nil, err
+		}
+		return payload.Data, nil
+	default:
+		return nil, fmt.Errorf("unknown notification type %q", message[0])
+	}
+}
+
+func (t *databaseTransport) broadcastChannel() string {
+	return t.prefix + "all"
+}
+
+func (t *databaseTransport) nodeChannel(nodeId string) string {
+	return t.prefix + nodeId
+}
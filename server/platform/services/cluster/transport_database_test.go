@@ -0,0 +1,228 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+)
+
+// fakeDatabase delivers the notifications to its listeners, and keeps the payloads in memory.
+type fakeDatabase struct {
+	mut           sync.Mutex
+	payloads      map[string]*model.ClusterPayload
+	listeners     []*fakeListener
+	notifications []string
+}
+
+func newFakeDatabase() *fakeDatabase {
+	return &fakeDatabase{payloads: map[string]*model.ClusterPayload{}}
+}
+
+func (db *fakeDatabase) Save(payload *model.ClusterPayload) error {
+	payload.PreSave()
+	db.mut.Lock()
+	defer db.mut.Unlock()
+	db.payloads[payload.Id] = payload
+	return nil
+}
+
+func (db *fakeDatabase) Get(id string) (*model.ClusterPayload, error) {
+	db.mut.Lock()
+	defer db.mut.Unlock()
+	payload, ok := db.payloads[id]
+	if !ok {
+		return nil, store.NewErrNotFound("ClusterPayload", id)
+	}
+	return payload, nil
+}
+
+func (db *fakeDatabase) DeleteOlderThan(createAt int64) (int64, error) {
+	db.mut.Lock()
+	defer db.mut.Unlock()
+	var count int64
+	for id, payload := range db.payloads {
+		if payload.CreateAt < createAt {
+			delete(db.payloads, id)
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (db *fakeDatabase) Notify(channel, message string) error {
+	db.mut.Lock()
+	defer db.mut.Unlock()
+	db.notifications = append(db.notifications, message)
+	for _, l := range db.listeners {
+		if l.channels[channel] {
+			l.notifications <- &pq.Notification{Channel: channel, Extra: message}
+		}
+	}
+	return nil
+}
+
+func (db *fakeDatabase) newListener(_ pq.EventCallbackType) listener {
+	db.mut.Lock()
+	defer db.mut.Unlock()
+	l := &fakeListener{db: db, channels: map[string]bool{}, notifications: make(chan *pq.Notification, 100)}
+	db.listeners = append(db.listeners, l)
+	return l
+}
+
+type fakeListener struct {
+	db            *fakeDatabase
+	channels      map[string]bool
+	notifications chan *pq.Notification
+}
+
+func (l *fakeListener) Listen(channel string) error {
+	l.db.mut.Lock()
+	defer l.db.mut.Unlock()
+	l.channels[channel] = true
+	return nil
+}
+
+func (l *fakeListener) NotificationChannel() <-chan *pq.Notification {
+	return l.notifications
+}
+
+func (l *fakeListener) Close() error {
+	return nil
+}
+
+type receivedFrames struct {
+	mut    sync.Mutex
+	frames [][]byte
+}
+
+func (r *receivedFrames) receive(frame []byte) error {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	r.frames = append(r.frames, frame)
+	return nil
+}
+
+func (r *receivedFrames) get() [][]byte {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	return r.frames
+}
+
+func TestDatabaseTransport(t *testing.T) {
+	logger := mlog.CreateConsoleTestLogger(t)
+	db := newFakeDatabase()
+
+	nodes := make([]*model.ClusterDiscovery, 3)
+	transports := make([]*databaseTransport, 3)
+	received := make([]*receivedFrames, 3)
+	for i := range nodes {
+		nodes[i] = &model.ClusterDiscovery{Id: model.NewId()}
+		transports[i] = newDatabaseTransport(logger, db, "", "test", nodes[i].Id)
+		transports[i].newListener = db.newListener
+		received[i] = &receivedFrames{}
+		require.NoError(t, transports[i].start(received[i].receive))
+		t.Cleanup(transports[i].stop)
+	}
+
+	t.Run("channel names are valid identifiers", func(t *testing.T) {
+		assert.LessOrEqual(t, len(transports[0].nodeChannel(nodes[0].Id)), 63)
+		assert.Equal(t, transports[0].broadcastChannel(), transports[1].broadcastChannel())
+		other := newDatabaseTransport(logger, db, "", "other", nodes[0].Id)
+		assert.NotEqual(t, transports[0].broadcastChannel(), other.broadcastChannel())
+	})
+
+	t.Run("broadcast", func(t *testing.T) {
+		failed := transports[0].broadcast(context.Background(), nodes[1:], []byte("broadcast"))
+		require.Empty(t, failed)
+
+		// The sending node is listening to the broadcasts too.
+		for i := range nodes {
+			require.Eventually(t, func() bool { return len(received[i].get()) == 1 }, 5*time.Second, 10*time.Millisecond)
+			assert.Equal(t, []byte("broadcast"), received[i].get()[0])
+		}
+	})
+
+	t.Run("send", func(t *testing.T) {
+		require.NoError(t, transports[0].send(context.Background(), nodes[2], []byte("direct")))
+
+		require.Eventually(t, func() bool { return len(received[2].get()) == 2 }, 5*time.Second, 10*time.Millisecond)
+		assert.Equal(t, []byte("direct"), received[2].get()[1])
+		assert.Len(t, received[1].get(), 1)
+	})
+
+	t.Run("large frames spill over to the payloads table", func(t *testing.T) {
+		frame := bytes.Repeat([]byte{0xff}, 3*maxNotificationSize)
+		require.NoError(t, transports[0].send(context.Background(), nodes[1], frame))
+
+		require.Eventually(t, func() bool { return len(received[1].get()) == 2 }, 5*time.Second, 10*time.Millisecond)
+		assert.Equal(t, frame, received[1].get()[1])
+
+		db.mut.Lock()
+		defer db.mut.Unlock()
+		assert.Len(t, db.payloads, 1)
+		for _, notification := range db.notifications {
+			assert.LessOrEqual(t, len(notification), maxNotificationSize)
+		}
+		assert.True(t, strings.HasPrefix(db.notifications[len(db.notifications)-1], string(notificationPayload)))
+	})
+
+	t.Run("reconnections and invalid notifications are skipped", func(t *testing.T) {
+		db.listeners[2].notifications <- nil
+		db.listeners[2].notifications <- &pq.Notification{Extra: "x"}
+		db.listeners[2].notifications <- &pq.Notification{Extra: string(notificationPayload) + model.NewId()}
+		require.NoError(t, transports[1].send(context.Background(), nodes[2], []byte("after")))
+
+		require.Eventually(t, func() bool { return len(received[2].get()) == 3 }, 5*time.Second, 10*time.Millisecond)
+		assert.Equal(t, []byte("after"), received[2].get()[2])
+	})
+}
+
+func TestDatabaseTransportSlowReceiver(t *testing.T) {
+	logger := mlog.CreateConsoleTestLogger(t)
+	db := newFakeDatabase()
+
+	node := &model.ClusterDiscovery{Id: model.NewId()}
+	transport := newDatabaseTransport(logger, db, "", "test", node.Id)
+	transport.newListener = db.newListener
+
+	unblock := make(chan struct{})
+	received := &receivedFrames{}
+	require.NoError(t, transport.start(func(frame []byte) error {
+		<-unblock
+		return received.receive(frame)
+	}))
+	t.Cleanup(transport.stop)
+
+	// The notifications keep being read from the listener while the messages are handled,
+	// so sending more of them than the listener buffers doesn't block.
+	const count = 300
+	sent := make(chan struct{})
+	go func() {
+		defer close(sent)
+		for range count {
+			assert.NoError(t, transport.send(context.Background(), node, []byte("slow")))
+		}
+	}()
+	select {
+	case <-sent:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "the listener was held up by the slow receiver")
+	}
+
+	close(unblock)
+	require.Eventually(t, func() bool { return len(received.get()) == count }, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package cluster
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/v8/platform/services/cache"
+)
+
+const redisResubscribeDelay = time.Second
+
+// redisTransport sends the frames through Redis pub/sub channels: one channel shared by the
+// whole cluster for broadcasts, and one channel per node for the frames sent to that node.
+type redisTransport struct {
+	logger  mlog.LoggerIFace
+	pubsub  *cache.RedisPubSub
+	prefix  string
+	nodeId  string
+	cancel  context.CancelFunc
+	stopped sync.WaitGroup
+}
+
+func newRedisTransport(logger mlog.LoggerIFace, pubsub *cache.RedisPubSub, clusterName, nodeId string) *redisTransport {
+	return &redisTransport{
+		logger: logger,
+		pubsub: pubsub,
+		prefix: "cluster:" + clusterName + ":",
+		nodeId: nodeId,
+	}
+}
+
+func (t *redisTransport) start(receive func(frame []byte) error) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+
+	channels := []string{t.broadcastChannel(), t.nodeChannel(t.nodeId)}
+	t.stopped.Add(1)
+	go func() {
+		defer t.stopped.Done()
+		for {
+			err := t.pubsub.Subscribe(ctx, channels, func(_ string, frame []byte) {
+				if err := receive(frame); err != nil {
+					t.logger.Warn("Rejected cluster message", mlog.Err(err))
+				}
+			})
+			if ctx.Err() != nil {
+				return
+			}
+			t.logger.Warn("Lost the cluster Redis subscription, subscribing again", mlog.Err(err))
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(redisResubscribeDelay):
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (t *redisTransport) stop() {
+	if t.cancel == nil {
+		return
+	}
+	t.cancel()
+	t.stopped.Wait()
+}
+
+func (t *redisTransport) port() int {
+	return 0
+}
+
+func (t *redisTransport) send(ctx context.Context, node *model.ClusterDiscovery, frame []byte) error {
+	return t.pubsub.Publish(ctx, t.nodeChannel(node.Id), frame)
+}
+
+func (t *redisTransport) broadcast(ctx context.Context, nodes []*model.ClusterDiscovery, frame []byte) map[string]error {
+	if err := t.pubsub.Publish(ctx, t.broadcastChannel(), frame); err != nil {
+		failed := make(map[string]error, len(nodes))
+		for _, node := range nodes {
+			failed[node.Id] = err
+		}
+		return failed
+	}
+
+	return nil
+}
+
+func (t *redisTransport) broadcastChannel() string {
+	return t.prefix + "all"
+}
+
+func (t *redisTransport) nodeChannel(nodeId string) string {
+	return t.prefix + "node:" + nodeId
+}
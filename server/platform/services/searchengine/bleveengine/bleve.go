@@ -20,6 +20,7 @@ import (
 	"github.com/mattermost/mattermost/server/public/model"
 	"github.com/mattermost/mattermost/server/public/shared/mlog"
 	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/einterfaces"
 )
 
 const (
@@ -39,6 +40,9 @@ type BleveEngine struct {
 	ready        int32
 	cfg          *model.Config
 	indexSync    bool
+	cluster      einterfaces.ClusterInterface
+	catchUp      func(startTime int64)
+	lastSyncAt   int64
 }
 
 var keywordMapping *mapping.FieldMapping
@@ -155,6 +159,7 @@ func (b *BleveEngine) openIndexes() *model.AppError {
 	if err != nil {
 		return model.NewAppError("Bleveengine.Start", "bleveengine.create_post_index.error", nil, "", http.StatusInternalServerError).Wrap(err)
 	}
+	b.loadLastSyncAt()
 
 	b.FileIndex, err = b.createOrOpenIndex(FileIndex, getFileIndexMapping())
 	if err != nil {
@@ -278,9 +283,23 @@ func (b *BleveEngine) PurgeIndexes(rctx request.CTX) *model.AppError {
 	}
 
 	b.Mutex.Lock()
-	defer b.Mutex.Unlock()
-
 	rctx.Logger().Info("PurgeIndexes Bleve")
+	err := b.purgeIndexes()
+	cluster := b.syncCluster()
+	b.Mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	// The purge is sent once the lock is released, so that the searches of this node aren't
+	// blocked while the other nodes receive it.
+	if cluster != nil {
+		sendClusterOperation(cluster, &clusterOperation{Op: clusterOpPurge, SentAt: model.GetMillis()})
+	}
+	return nil
+}
+
+func (b *BleveEngine) purgeIndexes() *model.AppError {
 	if err := b.closeIndexes(); err != nil {
 		return err
 	}
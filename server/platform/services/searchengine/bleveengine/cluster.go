@@ -0,0 +1,367 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package bleveengine
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/blevesearch/bleve/v2"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/v8/einterfaces"
+)
+
+// The operations sent to the other nodes of the cluster, so that every node applies the
+// changes of the indexes to its local copy.
+const (
+	clusterOpIndex            = "index"
+	clusterOpDelete           = "delete"
+	clusterOpDeleteByTerm     = "delete_by_term"
+	clusterOpDeleteFilesBatch = "delete_files_batch"
+	clusterOpPurge            = "purge"
+	clusterOpCatchUp          = "catch_up"
+)
+
+const (
+	// lastSyncAtKey is the key of the internal storage of the post index holding the time of
+	// the last change of the indexes sent or received through the cluster.
+	lastSyncAtKey = "cluster_last_sync_at"
+	// lastSyncAtInterval is how often the time of the last change is saved. The saved time
+	// may be older than the last change, so a node catching up indexes a bit more than needed.
+	lastSyncAtInterval = time.Minute
+)
+
+type clusterOperation struct {
+	Op       string        `json:"op"`
+	Index    string        `json:"index,omitempty"`
+	Ids      []string      `json:"ids,omitempty"`
+	Field    string        `json:"field,omitempty"`
+	Term     string        `json:"term,omitempty"`
+	EndTime  int64         `json:"end_time,omitempty"`
+	Limit    int64         `json:"limit,omitempty"`
+	Posts    []*BLVPost    `json:"posts,omitempty"`
+	Files    []*BLVFile    `json:"files,omitempty"`
+	Channels []*BLVChannel `json:"channels,omitempty"`
+	Users    []*BLVUser    `json:"users,omitempty"`
+
+	// SentAt is the time the operation was sent, and StartTime the time a node catching up
+	// needs the indexes from.
+	SentAt    int64 `json:"sent_at,omitempty"`
+	StartTime int64 `json:"start_time,omitempty"`
+}
+
+// SetCluster sets the cluster the changes of the indexes are sent to when the cluster sync
+// is enabled. The handler of the messages must be registered for the
+// model.ClusterEventBleveIndexSync event.
+func (b *BleveEngine) SetCluster(cluster einterfaces.ClusterInterface) {
+	b.Mutex.Lock()
+	defer b.Mutex.Unlock()
+	b.cluster = cluster
+}
+
+// SetCatchUpHandler sets the function the owner of the indexes runs when a node asks to catch
+// up on the changes it missed since the given time, usually by starting an indexing job.
+func (b *BleveEngine) SetCatchUpHandler(handler func(startTime int64)) {
+	b.Mutex.Lock()
+	defer b.Mutex.Unlock()
+	b.catchUp = handler
+}
+
+// IsClusterSyncEnabled returns whether the indexes of the nodes of the cluster are kept in sync.
+func (b *BleveEngine) IsClusterSyncEnabled() bool {
+	return b.cluster != nil && *b.cfg.ClusterSettings.Enable && *b.cfg.BleveSettings.EnableClusterSync
+}
+
+// IsIndexOwner returns whether this node runs the indexing jobs. When the indexes are kept in
+// sync, it's the cluster leader, which sends the indexed batches to the other nodes.
+func (b *BleveEngine) IsIndexOwner() bool {
+	b.Mutex.RLock()
+	defer b.Mutex.RUnlock()
+
+	return !b.IsClusterSyncEnabled() || b.cluster.IsLeader()
+}
+
+// RequestCatchUp asks the owner of the indexes to index again the changes this node may have
+// missed while it was out of the cluster, since the last change it sent or received. It's meant
+// to be called when the node joins the cluster, or when the leader of the cluster changes.
+func (b *BleveEngine) RequestCatchUp() {
+	b.Mutex.RLock()
+	cluster := b.syncCluster()
+	if !b.IsActive() || cluster == nil || cluster.IsLeader() {
+		b.Mutex.RUnlock()
+		return
+	}
+	op := &clusterOperation{Op: clusterOpCatchUp, SentAt: model.GetMillis(), StartTime: atomic.LoadInt64(&b.lastSyncAt)}
+	b.Mutex.RUnlock()
+
+	mlog.Info("Asking the owner of the Bleve indexes to catch up", mlog.Int("start_time", op.StartTime))
+	sendClusterOperation(cluster, op)
+}
+
+// SyncPosts sends the posts indexed and deleted by a bulk indexing job to the other nodes.
+func (b *BleveEngine) SyncPosts(posts []*BLVPost, deletedIds []string) {
+	b.syncBatch(PostIndex, &clusterOperation{Op: clusterOpIndex, Index: PostIndex, Posts: posts}, deletedIds)
+}
+
+// SyncFiles sends the files indexed and deleted by a bulk indexing job to the other nodes.
+func (b *BleveEngine) SyncFiles(files []*BLVFile, deletedIds []string) {
+	b.syncBatch(FileIndex, &clusterOperation{Op: clusterOpIndex, Index: FileIndex, Files: files}, deletedIds)
+}
+
+// SyncChannels sends the channels indexed and deleted by a bulk indexing job to the other nodes.
+func (b *BleveEngine) SyncChannels(channels []*BLVChannel, deletedIds []string) {
+	b.syncBatch(ChannelIndex, &clusterOperation{Op: clusterOpIndex, Index: ChannelIndex, Channels: channels}, deletedIds)
+}
+
+// SyncUsers sends the users indexed and deleted by a bulk indexing job to the other nodes.
+func (b *BleveEngine) SyncUsers(users []*BLVUser, deletedIds []string) {
+	b.syncBatch(UserIndex, &clusterOperation{Op: clusterOpIndex, Index: UserIndex, Users: users}, deletedIds)
+}
+
+func (b *BleveEngine) syncBatch(index string, op *clusterOperation, deletedIds []string) {
+	var ops []*clusterOperation
+	if len(op.Posts)+len(op.Files)+len(op.Channels)+len(op.Users) > 0 {
+		ops = append(ops, op)
+	}
+	if len(deletedIds) > 0 {
+		ops = append(ops, &clusterOperation{Op: clusterOpDelete, Index: index, Ids: deletedIds})
+	}
+
+	b.Mutex.RLock()
+	cluster := b.prepareSync(ops...)
+	b.Mutex.RUnlock()
+
+	if cluster != nil {
+		for _, op := range ops {
+			sendClusterOperation(cluster, op)
+		}
+	}
+}
+
+// writeAndSync applies a change of the indexes under the mutex, and sends the operation it
+// returns to the other nodes once the mutex is released, so that the searches of this node
+// aren't blocked while the other nodes receive it.
+func (b *BleveEngine) writeAndSync(change func() (*clusterOperation, *model.AppError)) *model.AppError {
+	op, cluster, appErr := b.writeLocked(change)
+	if appErr != nil {
+		return appErr
+	}
+
+	if cluster != nil {
+		sendClusterOperation(cluster, op)
+	}
+	return nil
+}
+
+func (b *BleveEngine) writeLocked(change func() (*clusterOperation, *model.AppError)) (*clusterOperation, einterfaces.ClusterInterface, *model.AppError) {
+	b.Mutex.RLock()
+	defer b.Mutex.RUnlock()
+
+	op, appErr := change()
+	if appErr != nil {
+		return nil, nil, appErr
+	}
+	return op, b.prepareSync(op), nil
+}
+
+// prepareSync stamps the operations with the time they are sent at, returning the cluster to
+// send them to, or nil if the cluster sync is disabled. The caller must hold the mutex, and
+// send the operations once it's released.
+func (b *BleveEngine) prepareSync(ops ...*clusterOperation) einterfaces.ClusterInterface {
+	cluster := b.syncCluster()
+	if cluster == nil || len(ops) == 0 {
+		return nil
+	}
+
+	sentAt := model.GetMillis()
+	for _, op := range ops {
+		op.SentAt = sentAt
+	}
+	b.recordSync(sentAt)
+	return cluster
+}
+
+// syncCluster returns the cluster the changes are sent to, or nil if the cluster sync is
+// disabled. The caller must hold the mutex.
+func (b *BleveEngine) syncCluster() einterfaces.ClusterInterface {
+	if !b.IsClusterSyncEnabled() {
+		return nil
+	}
+	return b.cluster
+}
+
+func sendClusterOperation(cluster einterfaces.ClusterInterface, op *clusterOperation) {
+	data, err := json.Marshal(op)
+	if err != nil {
+		mlog.Error("Failed to encode the Bleve index operation", mlog.String("op", op.Op), mlog.Err(err))
+		return
+	}
+
+	cluster.SendClusterMessage(&model.ClusterMessage{
+		Event:    model.ClusterEventBleveIndexSync,
+		SendType: model.ClusterSendReliable,
+		Data:     data,
+	})
+}
+
+// recordSync saves the time of the last change sent or received, for the node to know where
+// to catch up from. The caller must hold the mutex.
+func (b *BleveEngine) recordSync(at int64) {
+	last := atomic.LoadInt64(&b.lastSyncAt)
+	if !b.IsActive() || at-last < lastSyncAtInterval.Milliseconds() || !atomic.CompareAndSwapInt64(&b.lastSyncAt, last, at) {
+		return
+	}
+
+	if err := b.PostIndex.SetInternal([]byte(lastSyncAtKey), []byte(strconv.FormatInt(at, 10))); err != nil {
+		mlog.Warn("Failed to save the time of the last Bleve index sync", mlog.Err(err))
+	}
+}
+
+// loadLastSyncAt reads the time of the last change sent or received from the post index,
+// which must be open. The caller must hold the write lock.
+func (b *BleveEngine) loadLastSyncAt() {
+	var lastSyncAt int64
+	if value, err := b.PostIndex.GetInternal([]byte(lastSyncAtKey)); err != nil {
+		mlog.Warn("Failed to read the time of the last Bleve index sync", mlog.Err(err))
+	} else if value != nil {
+		if lastSyncAt, err = strconv.ParseInt(string(value), 10, 64); err != nil {
+			mlog.Warn("Failed to parse the time of the last Bleve index sync", mlog.Err(err))
+		}
+	}
+	atomic.StoreInt64(&b.lastSyncAt, lastSyncAt)
+}
+
+// HandleClusterMessage applies an operation received from another node to the local indexes.
+func (b *BleveEngine) HandleClusterMessage(msg *model.ClusterMessage) {
+	var op clusterOperation
+	if err := json.Unmarshal(msg.Data, &op); err != nil {
+		mlog.Warn("Failed to decode the Bleve index operation", mlog.Err(err))
+		return
+	}
+
+	if err := b.apply(&op); err != nil {
+		mlog.Error("Failed to apply the Bleve index operation from the cluster", mlog.String("op", op.Op), mlog.String("index", op.Index), mlog.Err(err))
+	}
+}
+
+func (b *BleveEngine) apply(op *clusterOperation) error {
+	switch op.Op {
+	case clusterOpCatchUp:
+		b.Mutex.RLock()
+		cluster := b.syncCluster()
+		isOwner := b.IsActive() && cluster != nil && cluster.IsLeader()
+		catchUp := b.catchUp
+		b.Mutex.RUnlock()
+
+		if isOwner && catchUp != nil {
+			catchUp(op.StartTime)
+		}
+		return nil
+	case clusterOpPurge:
+		b.Mutex.Lock()
+		defer b.Mutex.Unlock()
+
+		if !b.IsActive() || !*b.cfg.BleveSettings.EnableClusterSync {
+			return nil
+		}
+		if appErr := b.purgeIndexes(); appErr != nil {
+			return appErr
+		}
+		b.recordSync(op.SentAt)
+		return nil
+	}
+
+	b.Mutex.RLock()
+	defer b.Mutex.RUnlock()
+
+	if !b.IsActive() || !*b.cfg.BleveSettings.EnableClusterSync {
+		return nil
+	}
+
+	if err := b.applyChange(op); err != nil {
+		return err
+	}
+	b.recordSync(op.SentAt)
+	return nil
+}
+
+// applyChange applies a change of the indexes. The caller must hold the mutex.
+func (b *BleveEngine) applyChange(op *clusterOperation) error {
+	switch op.Op {
+	case clusterOpIndex:
+		index, err := b.index(op.Index)
+		if err != nil {
+			return err
+		}
+		batch := index.NewBatch()
+		for _, post := range op.Posts {
+			if err := batch.Index(post.Id, post); err != nil {
+				return err
+			}
+		}
+		for _, file := range op.Files {
+			if err := batch.Index(file.Id, file); err != nil {
+				return err
+			}
+		}
+		for _, channel := range op.Channels {
+			if err := batch.Index(channel.Id, channel); err != nil {
+				return err
+			}
+		}
+		for _, user := range op.Users {
+			if err := batch.Index(user.Id, user); err != nil {
+				return err
+			}
+		}
+		return index.Batch(batch)
+	case clusterOpDelete:
+		index, err := b.index(op.Index)
+		if err != nil {
+			return err
+		}
+		batch := index.NewBatch()
+		for _, id := range op.Ids {
+			batch.Delete(id)
+		}
+		return index.Batch(batch)
+	case clusterOpDeleteByTerm:
+		query := bleve.NewTermQuery(op.Term)
+		query.SetField(op.Field)
+		search := bleve.NewSearchRequest(query)
+		switch op.Index {
+		case PostIndex:
+			_, err := b.deletePosts(search, DeletePostsBatchSize)
+			return err
+		case FileIndex:
+			_, err := b.deleteFiles(search, DeleteFilesBatchSize)
+			return err
+		}
+		return fmt.Errorf("unsupported index %q", op.Index)
+	case clusterOpDeleteFilesBatch:
+		_, err := b.deleteFilesBatch(op.EndTime, op.Limit)
+		return err
+	}
+
+	return fmt.Errorf("unknown operation %q", op.Op)
+}
+
+func (b *BleveEngine) index(name string) (bleve.Index, error) {
+	switch name {
+	case PostIndex:
+		return b.PostIndex, nil
+	case FileIndex:
+		return b.FileIndex, nil
+	case ChannelIndex:
+		return b.ChannelIndex, nil
+	case UserIndex:
+		return b.UserIndex, nil
+	}
+	return nil, fmt.Errorf("unknown index %q", name)
+}
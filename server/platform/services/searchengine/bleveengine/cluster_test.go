@@ -0,0 +1,204 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package bleveengine
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/einterfaces/mocks"
+)
+
+// startClusterNode starts an engine whose cluster messages are applied by the given engine.
+func startClusterNode(t *testing.T, peer **BleveEngine, leader bool) (*BleveEngine, *mocks.ClusterInterface) {
+	cfg := &model.Config{}
+	cfg.SetDefaults()
+	cfg.ClusterSettings.Enable = model.NewPointer(true)
+	cfg.BleveSettings.EnableIndexing = model.NewPointer(true)
+	cfg.BleveSettings.EnableSearching = model.NewPointer(true)
+	cfg.BleveSettings.EnableClusterSync = model.NewPointer(true)
+	cfg.BleveSettings.IndexDir = model.NewPointer(t.TempDir())
+
+	cluster := &mocks.ClusterInterface{}
+	cluster.On("IsLeader").Return(leader).Maybe()
+	cluster.On("SendClusterMessage", mock.AnythingOfType("*model.ClusterMessage")).Run(func(args mock.Arguments) {
+		msg := args.Get(0).(*model.ClusterMessage)
+		require.Equal(t, model.ClusterEventBleveIndexSync, msg.Event)
+		require.Equal(t, model.ClusterSendReliable, msg.SendType)
+		(*peer).HandleClusterMessage(msg)
+	}).Maybe()
+
+	engine := NewBleveEngine(cfg)
+	require.Nil(t, engine.Start())
+	t.Cleanup(func() {
+		engine.Stop()
+	})
+	engine.SetCluster(cluster)
+
+	return engine, cluster
+}
+
+func TestClusterSync(t *testing.T) {
+	var leader, follower *BleveEngine
+	leader, _ = startClusterNode(t, &follower, true)
+	follower, followerCluster := startClusterNode(t, &leader, false)
+
+	rctx := request.TestContext(t)
+	teamID := model.NewId()
+	userID := model.NewId()
+	channelID := model.NewId()
+
+	docCount := func(t *testing.T, engine *BleveEngine, index string) uint64 {
+		t.Helper()
+		idx, err := engine.index(index)
+		require.NoError(t, err)
+		count, err := idx.DocCount()
+		require.NoError(t, err)
+		return count
+	}
+
+	t.Run("the owner of the indexes is the leader", func(t *testing.T) {
+		require.True(t, leader.IsIndexOwner())
+		require.False(t, follower.IsIndexOwner())
+	})
+
+	t.Run("indexed posts are searchable on every node", func(t *testing.T) {
+		post := createPost(userID, channelID)
+		post.Message = "replicated message"
+		require.Nil(t, follower.IndexPost(post, teamID))
+
+		ids, _, appErr := leader.SearchPosts(model.ChannelList{{Id: channelID}}, []*model.SearchParams{{Terms: "replicated"}}, 0, 20)
+		require.Nil(t, appErr)
+		require.Equal(t, []string{post.Id}, ids)
+
+		require.Nil(t, leader.DeletePost(post))
+		require.Zero(t, docCount(t, follower, PostIndex))
+	})
+
+	t.Run("deletions by term are applied on every node", func(t *testing.T) {
+		for range 3 {
+			require.Nil(t, leader.IndexPost(createPost(userID, channelID), teamID))
+		}
+		postToKeep := createPost(userID, model.NewId())
+		require.Nil(t, leader.IndexPost(postToKeep, teamID))
+		require.Equal(t, uint64(4), docCount(t, follower, PostIndex))
+
+		require.Nil(t, leader.DeleteChannelPosts(rctx, channelID))
+		require.Equal(t, uint64(1), docCount(t, follower, PostIndex))
+
+		require.Nil(t, follower.DeleteUserPosts(rctx, userID))
+		require.Zero(t, docCount(t, leader, PostIndex))
+	})
+
+	t.Run("bulk indexed batches are applied on every node", func(t *testing.T) {
+		channel := &model.Channel{Id: model.NewId(), TeamId: teamID, Type: model.ChannelTypeOpen, Name: "town-square", DisplayName: "Town Square"}
+		deletedChannel := &model.Channel{Id: model.NewId(), TeamId: teamID, Type: model.ChannelTypeOpen, Name: "off-topic", DisplayName: "Off Topic"}
+		require.Nil(t, leader.IndexChannel(rctx, deletedChannel, nil, nil))
+		require.Equal(t, uint64(1), docCount(t, follower, ChannelIndex))
+
+		leader.SyncChannels([]*BLVChannel{BLVChannelFromChannel(channel, nil, nil)}, []string{deletedChannel.Id})
+
+		doc, err := follower.ChannelIndex.Document(channel.Id)
+		require.NoError(t, err)
+		require.NotNil(t, doc)
+		require.Equal(t, uint64(1), docCount(t, follower, ChannelIndex))
+	})
+
+	t.Run("nothing is sent when the cluster sync is disabled", func(t *testing.T) {
+		cfg := follower.cfg.Clone()
+		cfg.BleveSettings.EnableClusterSync = model.NewPointer(false)
+		follower.UpdateConfig(cfg)
+		defer func() {
+			cfg := follower.cfg.Clone()
+			cfg.BleveSettings.EnableClusterSync = model.NewPointer(true)
+			follower.UpdateConfig(cfg)
+		}()
+
+		require.True(t, follower.IsIndexOwner())
+		require.Nil(t, follower.IndexPost(createPost(userID, channelID), teamID))
+		followerCluster.AssertNumberOfCalls(t, "SendClusterMessage", 2)
+	})
+
+	t.Run("purging the indexes is applied on every node", func(t *testing.T) {
+		require.Nil(t, leader.IndexPost(createPost(userID, channelID), teamID))
+		require.Nil(t, leader.IndexUser(rctx, &model.User{Id: model.NewId(), Username: "replicated"}, []string{teamID}, nil))
+
+		require.Nil(t, leader.PurgeIndexes(rctx))
+		require.Zero(t, docCount(t, follower, PostIndex))
+		require.Zero(t, docCount(t, follower, UserIndex))
+		require.Zero(t, docCount(t, follower, ChannelIndex))
+	})
+
+	t.Run("a node catching up asks the owner for the changes since its last sync", func(t *testing.T) {
+		var requested []int64
+		leader.SetCatchUpHandler(func(startTime int64) {
+			requested = append(requested, startTime)
+		})
+		defer leader.SetCatchUpHandler(nil)
+
+		lastSyncAt := atomic.LoadInt64(&follower.lastSyncAt)
+		require.NotZero(t, lastSyncAt)
+
+		follower.RequestCatchUp()
+		require.Equal(t, []int64{lastSyncAt}, requested)
+
+		// The owner has nothing to catch up on.
+		leader.RequestCatchUp()
+		require.Len(t, requested, 1)
+	})
+
+	t.Run("the time of the last sync is kept when the node restarts", func(t *testing.T) {
+		lastSyncAt := atomic.LoadInt64(&follower.lastSyncAt)
+
+		require.Nil(t, follower.Stop())
+		atomic.StoreInt64(&follower.lastSyncAt, 0)
+		require.Nil(t, follower.Start())
+
+		require.Equal(t, lastSyncAt, atomic.LoadInt64(&follower.lastSyncAt))
+	})
+}
+
+func TestClusterSyncSendsWithoutLock(t *testing.T) {
+	var peer *BleveEngine
+	engine, _ := startClusterNode(t, &peer, true)
+
+	// The operations must be sent once the mutex is released, so that a slow cluster doesn't
+	// block the searches and the purges of this node.
+	cluster := &mocks.ClusterInterface{}
+	cluster.On("IsLeader").Return(true).Maybe()
+	cluster.On("SendClusterMessage", mock.AnythingOfType("*model.ClusterMessage")).Run(func(args mock.Arguments) {
+		locked := engine.Mutex.TryLock()
+		if locked {
+			engine.Mutex.Unlock()
+		}
+		require.True(t, locked, "the operation should be sent without holding the mutex")
+	})
+	engine.SetCluster(cluster)
+
+	post := createPost(model.NewId(), model.NewId())
+	require.Nil(t, engine.IndexPost(post, model.NewId()))
+	require.Nil(t, engine.DeletePost(post))
+	engine.SyncPosts([]*BLVPost{BLVPostFromPost(post, model.NewId())}, []string{model.NewId()})
+	cluster.AssertNumberOfCalls(t, "SendClusterMessage", 4)
+}
+
+func TestClusterSyncPurgeWithoutLock(t *testing.T) {
+	// The node receives its own messages, so sending the purge while holding the lock would
+	// deadlock when applying it.
+	var engine *BleveEngine
+	engine, cluster := startClusterNode(t, &engine, true)
+
+	require.Nil(t, engine.IndexPost(createPost(model.NewId(), model.NewId()), model.NewId()))
+	require.Nil(t, engine.PurgeIndexes(request.TestContext(t)))
+	cluster.AssertNumberOfCalls(t, "SendClusterMessage", 2)
+
+	count, err := engine.PostIndex.DocCount()
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
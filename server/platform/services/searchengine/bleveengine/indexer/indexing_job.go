@@ -20,6 +20,10 @@ import (
 const (
 	timeBetweenBatches = 100 * time.Millisecond
 
+	// orphanedJobTimeout is how long an indexing job in progress must have been inactive to be
+	// considered left by the previous owner of the indexes, rather than still running on it.
+	orphanedJobTimeout = 2 * time.Minute
+
 	estimatedPostCount    = 10000000
 	estimatedFilesCount   = 100000
 	estimatedChannelCount = 100000
@@ -38,6 +42,12 @@ type BleveIndexerWorker struct {
 	logger    mlog.LoggerIFace
 	engine    *bleveengine.BleveEngine
 	stopped   bool
+
+	// runningJobMut protects runningJobId, the id of the job being run by the worker, and
+	// resumeTimer, which checks the jobs in progress again once they may be orphaned.
+	runningJobMut sync.Mutex
+	runningJobId  string
+	resumeTimer   *time.Timer
 }
 
 func MakeWorker(jobServer *jobs.JobServer, engine *bleveengine.BleveEngine) *BleveIndexerWorker {
@@ -133,6 +143,8 @@ func (worker *BleveIndexerWorker) Run() {
 }
 
 func (worker *BleveIndexerWorker) Stop() {
+	worker.stopResumeTimer()
+
 	worker.stateMut.Lock()
 	defer worker.stateMut.Unlock()
 
@@ -150,6 +162,13 @@ func (worker *BleveIndexerWorker) DoJob(job *model.Job) {
 	logger := worker.logger.With(jobs.JobLoggerFields(job)...)
 	logger.Debug("Worker: Received a new candidate job.")
 
+	// When the indexes of the cluster are kept in sync, only the owner of the indexes runs the
+	// jobs, and sends the indexed batches to the other nodes.
+	if !worker.engine.IsIndexOwner() {
+		logger.Debug("Worker: Skipping the job as this node doesn't own the indexes.")
+		return
+	}
+
 	claimed, err := worker.jobServer.ClaimJob(job)
 	if err != nil {
 		logger.Warn("Worker: Error occurred while trying to claim job", mlog.Err(err))
@@ -161,6 +180,9 @@ func (worker *BleveIndexerWorker) DoJob(job *model.Job) {
 
 	logger.Info("Worker: Indexing job claimed by worker")
 
+	worker.setRunningJobId(job.Id)
+	defer worker.setRunningJobId("")
+
 	if !worker.engine.IsActive() {
 		appError := model.NewAppError("BleveIndexerWorker", "bleveengine.indexer.do_job.engine_inactive", nil, "", http.StatusInternalServerError)
 		if err := worker.jobServer.SetJobError(job, appError); err != nil {
@@ -403,22 +425,28 @@ func (worker *BleveIndexerWorker) IndexPostsBatch(logger mlog.LoggerIFace, progr
 
 func (worker *BleveIndexerWorker) BulkIndexPosts(posts []*model.PostForIndexing, progress IndexingProgress) (*model.Post, *model.AppError) {
 	batch := worker.engine.PostIndex.NewBatch()
+	searchPosts := make([]*bleveengine.BLVPost, 0, len(posts))
+	var deletedIds []string
 
 	for _, post := range posts {
 		if post.DeleteAt == 0 {
 			searchPost := bleveengine.BLVPostFromPostForIndexing(post)
 			batch.Index(searchPost.Id, searchPost)
+			searchPosts = append(searchPosts, searchPost)
 		} else {
 			batch.Delete(post.Id)
+			deletedIds = append(deletedIds, post.Id)
 		}
 	}
 
 	worker.engine.Mutex.RLock()
-	defer worker.engine.Mutex.RUnlock()
-
-	if err := worker.engine.PostIndex.Batch(batch); err != nil {
+	err := worker.engine.PostIndex.Batch(batch)
+	worker.engine.Mutex.RUnlock()
+	if err != nil {
 		return nil, model.NewAppError("BleveIndexerWorker.BulkIndexPosts", "bleveengine.indexer.do_job.bulk_index_posts.batch_error", nil, "", http.StatusInternalServerError).Wrap(err)
 	}
+
+	worker.engine.SyncPosts(searchPosts, deletedIds)
 	return &posts[len(posts)-1].Post, nil
 }
 
@@ -470,22 +498,28 @@ func (worker *BleveIndexerWorker) IndexFilesBatch(logger mlog.LoggerIFace, progr
 
 func (worker *BleveIndexerWorker) BulkIndexFiles(files []*model.FileForIndexing, progress IndexingProgress) (*model.FileInfo, *model.AppError) {
 	batch := worker.engine.FileIndex.NewBatch()
+	searchFiles := make([]*bleveengine.BLVFile, 0, len(files))
+	var deletedIds []string
 
 	for _, file := range files {
 		if file.ShouldIndex() {
 			searchFile := bleveengine.BLVFileFromFileForIndexing(file)
 			batch.Index(searchFile.Id, searchFile)
+			searchFiles = append(searchFiles, searchFile)
 		} else {
 			batch.Delete(file.Id)
+			deletedIds = append(deletedIds, file.Id)
 		}
 	}
 
 	worker.engine.Mutex.RLock()
-	defer worker.engine.Mutex.RUnlock()
-
-	if err := worker.engine.FileIndex.Batch(batch); err != nil {
+	err := worker.engine.FileIndex.Batch(batch)
+	worker.engine.Mutex.RUnlock()
+	if err != nil {
 		return nil, model.NewAppError("BleveIndexerWorker.BulkIndexPosts", "bleveengine.indexer.do_job.bulk_index_files.batch_error", nil, "", http.StatusInternalServerError).Wrap(err)
 	}
+
+	worker.engine.SyncFiles(searchFiles, deletedIds)
 	return &files[len(files)-1].FileInfo, nil
 }
 
@@ -537,6 +571,8 @@ func (worker *BleveIndexerWorker) IndexChannelsBatch(logger mlog.LoggerIFace, pr
 
 func (worker *BleveIndexerWorker) BulkIndexChannels(logger mlog.LoggerIFace, channels []*model.Channel, progress IndexingProgress) (*model.Channel, *model.AppError) {
 	batch := worker.engine.ChannelIndex.NewBatch()
+	searchChannels := make([]*bleveengine.BLVChannel, 0, len(channels))
+	var deletedIds []string
 
 	for _, channel := range channels {
 		if channel.DeleteAt == 0 {
@@ -557,17 +593,21 @@ func (worker *BleveIndexerWorker) BulkIndexChannels(logger mlog.LoggerIFace, cha
 
 			searchChannel := bleveengine.BLVChannelFromChannel(channel, userIDs, teamMemberIDs)
 			batch.Index(searchChannel.Id, searchChannel)
+			searchChannels = append(searchChannels, searchChannel)
 		} else {
 			batch.Delete(channel.Id)
+			deletedIds = append(deletedIds, channel.Id)
 		}
 	}
 
 	worker.engine.Mutex.RLock()
-	defer worker.engine.Mutex.RUnlock()
-
-	if err := worker.engine.ChannelIndex.Batch(batch); err != nil {
+	err := worker.engine.ChannelIndex.Batch(batch)
+	worker.engine.Mutex.RUnlock()
+	if err != nil {
 		return nil, model.NewAppError("BleveIndexerWorker.BulkIndexChannels", "bleveengine.indexer.do_job.bulk_index_channels.batch_error", nil, "", http.StatusInternalServerError).Wrap(err)
 	}
+
+	worker.engine.SyncChannels(searchChannels, deletedIds)
 	return channels[len(channels)-1], nil
 }
 
@@ -618,21 +658,132 @@ func (worker *BleveIndexerWorker) IndexUsersBatch(logger mlog.LoggerIFace, progr
 
 func (worker *BleveIndexerWorker) BulkIndexUsers(logger mlog.LoggerIFace, users []*model.UserForIndexing, progress IndexingProgress) (*model.UserForIndexing, *model.AppError) {
 	batch := worker.engine.UserIndex.NewBatch()
+	searchUsers := make([]*bleveengine.BLVUser, 0, len(users))
+	var deletedIds []string
 
 	for _, user := range users {
 		if user.DeleteAt == 0 {
 			searchUser := bleveengine.BLVUserFromUserForIndexing(user)
 			batch.Index(searchUser.Id, searchUser)
+			searchUsers = append(searchUsers, searchUser)
 		} else {
 			batch.Delete(user.Id)
+			deletedIds = append(deletedIds, user.Id)
 		}
 	}
 
 	worker.engine.Mutex.RLock()
-	defer worker.engine.Mutex.RUnlock()
-
-	if err := worker.engine.UserIndex.Batch(batch); err != nil {
+	err := worker.engine.UserIndex.Batch(batch)
+	worker.engine.Mutex.RUnlock()
+	if err != nil {
 		return nil, model.NewAppError("BleveIndexerWorker.BulkIndexUsers", "bleveengine.indexer.do_job.bulk_index_users.batch_error", nil, "", http.StatusInternalServerError).Wrap(err)
 	}
+
+	worker.engine.SyncUsers(searchUsers, deletedIds)
 	return users[len(users)-1], nil
 }
+
+func (worker *BleveIndexerWorker) setRunningJobId(jobId string) {
+	worker.runningJobMut.Lock()
+	defer worker.runningJobMut.Unlock()
+	worker.runningJobId = jobId
+}
+
+func (worker *BleveIndexerWorker) getRunningJobId() string {
+	worker.runningJobMut.Lock()
+	defer worker.runningJobMut.Unlock()
+	return worker.runningJobId
+}
+
+// ResumeOrphanedJobs hands the indexing jobs left in progress by the previous owner of the
+// indexes over to this node, when it becomes the owner. The jobs are set back to pending, and
+// resume from the last batch indexed, which every node of the cluster received. The jobs which
+// were active recently may still be running on the previous owner, so they are checked again
+// once they have been inactive for orphanedJobTimeout.
+func (worker *BleveIndexerWorker) ResumeOrphanedJobs() {
+	if !worker.engine.IsClusterSyncEnabled() || !worker.engine.IsIndexOwner() {
+		return
+	}
+
+	rctx := request.EmptyContext(worker.logger)
+	inProgressJobs, err := worker.jobServer.Store.Job().GetAllByTypeAndStatus(rctx, model.JobTypeBlevePostIndexing, model.JobStatusInProgress)
+	if err != nil {
+		worker.logger.Error("Worker: Failed to get the indexing jobs in progress", mlog.Err(err))
+		return
+	}
+
+	now := model.GetMillis()
+	runningJobId := worker.getRunningJobId()
+	var nextCheck time.Duration
+	for _, job := range inProgressJobs {
+		if job.Id == runningJobId {
+			continue
+		}
+
+		if inactive := time.Duration(now-job.LastActivityAt) * time.Millisecond; inactive < orphanedJobTimeout {
+			if wait := orphanedJobTimeout - inactive; nextCheck == 0 || wait < nextCheck {
+				nextCheck = wait
+			}
+			continue
+		}
+
+		worker.logger.Info("Worker: Resuming the indexing job of the previous owner of the indexes", jobs.JobLoggerFields(job)...)
+		if appErr := worker.jobServer.SetJobPending(job); appErr != nil {
+			worker.logger.Error("Worker: Failed to set the indexing job back to pending", mlog.String("job_id", job.Id), mlog.Err(appErr))
+		}
+	}
+
+	if nextCheck > 0 {
+		worker.runningJobMut.Lock()
+		defer worker.runningJobMut.Unlock()
+		if worker.resumeTimer != nil {
+			worker.resumeTimer.Stop()
+		}
+		worker.resumeTimer = time.AfterFunc(nextCheck, worker.ResumeOrphanedJobs)
+	}
+}
+
+func (worker *BleveIndexerWorker) stopResumeTimer() {
+	worker.runningJobMut.Lock()
+	defer worker.runningJobMut.Unlock()
+	if worker.resumeTimer != nil {
+		worker.resumeTimer.Stop()
+		worker.resumeTimer = nil
+	}
+}
+
+// CatchUp starts an indexing job from the given time, for a node of the cluster which may have
+// missed the changes of the indexes since then. The batches of the job are sent to every node.
+// Nothing is started if a pending job already covers the time.
+func (worker *BleveIndexerWorker) CatchUp(startTime int64) {
+	rctx := request.EmptyContext(worker.logger)
+	pendingJobs, err := worker.jobServer.Store.Job().GetAllByTypeAndStatus(rctx, model.JobTypeBlevePostIndexing, model.JobStatusPending)
+	if err != nil {
+		worker.logger.Error("Worker: Failed to get the pending indexing jobs", mlog.Err(err))
+		return
+	}
+
+	for _, job := range pendingJobs {
+		if _, ok := job.Data["end_time"]; ok {
+			continue
+		}
+		startString, ok := job.Data["start_time"]
+		if !ok {
+			return
+		}
+		if pendingStartTime, err := strconv.ParseInt(startString, 10, 64); err == nil && pendingStartTime <= startTime {
+			return
+		}
+	}
+
+	data := map[string]string{}
+	if startTime > 0 {
+		data["start_time"] = strconv.FormatInt(startTime, 10)
+	}
+	job, appErr := worker.jobServer.CreateJob(rctx, model.JobTypeBlevePostIndexing, data)
+	if appErr != nil {
+		worker.logger.Error("Worker: Failed to create the indexing job catching up a node", mlog.Err(appErr))
+		return
+	}
+	worker.logger.Info("Worker: Created an indexing job catching up a node of the cluster", jobs.JobLoggerFields(job)...)
+}
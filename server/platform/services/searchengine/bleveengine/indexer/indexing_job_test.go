@@ -7,7 +7,9 @@ import (
 	"errors"
 	"os"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"github.com/mattermost/mattermost/server/public/model"
@@ -15,6 +17,7 @@ import (
 	"github.com/mattermost/mattermost/server/v8/channels/jobs"
 	"github.com/mattermost/mattermost/server/v8/channels/store/storetest"
 	"github.com/mattermost/mattermost/server/v8/channels/utils/testutils"
+	"github.com/mattermost/mattermost/server/v8/einterfaces/mocks"
 	"github.com/mattermost/mattermost/server/v8/platform/services/searchengine/bleveengine"
 )
 
@@ -68,3 +71,102 @@ func TestBleveIndexer(t *testing.T) {
 		worker.DoJob(job)
 	})
 }
+
+func TestBleveIndexerClusterSync(t *testing.T) {
+	setup := func(t *testing.T, leader bool) (*BleveIndexerWorker, *storetest.Store) {
+		mockStore := &storetest.Store{}
+		t.Cleanup(func() {
+			mockStore.AssertExpectations(t)
+		})
+
+		cfg := &model.Config{}
+		cfg.SetDefaults()
+		cfg.ClusterSettings.Enable = model.NewPointer(true)
+		cfg.BleveSettings.EnableIndexing = model.NewPointer(true)
+		cfg.BleveSettings.EnableClusterSync = model.NewPointer(true)
+		cfg.BleveSettings.IndexDir = model.NewPointer(t.TempDir())
+
+		cluster := &mocks.ClusterInterface{}
+		cluster.On("IsLeader").Return(leader)
+
+		bleveEngine := bleveengine.NewBleveEngine(cfg)
+		require.Nil(t, bleveEngine.Start())
+		t.Cleanup(func() {
+			bleveEngine.Stop()
+		})
+		bleveEngine.SetCluster(cluster)
+
+		worker := &BleveIndexerWorker{
+			jobServer: &jobs.JobServer{
+				Store: mockStore,
+				ConfigService: &testutils.StaticConfigService{
+					Cfg: cfg,
+				},
+			},
+			engine: bleveEngine,
+			logger: mlog.CreateConsoleTestLogger(t),
+		}
+
+		return worker, mockStore
+	}
+
+	t.Run("Don't claim the job when this node doesn't own the indexes", func(t *testing.T) {
+		worker, mockStore := setup(t, false)
+
+		job := &model.Job{
+			Id:     model.NewId(),
+			Status: model.JobStatusPending,
+			Type:   model.JobTypeBlevePostIndexing,
+		}
+		worker.DoJob(job)
+
+		mockStore.JobStore.AssertNotCalled(t, "UpdateStatusOptimistically", mock.Anything, mock.Anything, mock.Anything)
+		require.Equal(t, model.JobStatusPending, job.Status)
+	})
+
+	t.Run("Set the jobs of the previous owner back to pending", func(t *testing.T) {
+		worker, mockStore := setup(t, true)
+
+		inactiveSince := model.GetMillis() - (orphanedJobTimeout + time.Minute).Milliseconds()
+		orphanedJob := &model.Job{Id: model.NewId(), Status: model.JobStatusInProgress, Type: model.JobTypeBlevePostIndexing, LastActivityAt: inactiveSince}
+		runningJob := &model.Job{Id: model.NewId(), Status: model.JobStatusInProgress, Type: model.JobTypeBlevePostIndexing, LastActivityAt: inactiveSince}
+		worker.setRunningJobId(runningJob.Id)
+
+		mockStore.JobStore.On("GetAllByTypeAndStatus", mock.Anything, model.JobTypeBlevePostIndexing, model.JobStatusInProgress).Return([]*model.Job{orphanedJob, runningJob}, nil)
+		mockStore.JobStore.On("UpdateStatus", orphanedJob.Id, model.JobStatusPending).Return(orphanedJob, nil).Once()
+
+		worker.ResumeOrphanedJobs()
+	})
+
+	t.Run("Leave the jobs still active on the previous owner", func(t *testing.T) {
+		worker, mockStore := setup(t, true)
+		t.Cleanup(worker.stopResumeTimer)
+
+		activeJob := &model.Job{Id: model.NewId(), Status: model.JobStatusInProgress, Type: model.JobTypeBlevePostIndexing, LastActivityAt: model.GetMillis()}
+		mockStore.JobStore.On("GetAllByTypeAndStatus", mock.Anything, model.JobTypeBlevePostIndexing, model.JobStatusInProgress).Return([]*model.Job{activeJob}, nil)
+
+		worker.ResumeOrphanedJobs()
+
+		mockStore.JobStore.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything)
+		require.NotNil(t, worker.resumeTimer, "should check the job again once it may be orphaned")
+	})
+
+	t.Run("Leave the jobs when this node doesn't own the indexes", func(t *testing.T) {
+		worker, mockStore := setup(t, false)
+
+		worker.ResumeOrphanedJobs()
+
+		mockStore.JobStore.AssertNotCalled(t, "GetAllByTypeAndStatus", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Don't start a catch up job when a pending job covers it", func(t *testing.T) {
+		worker, mockStore := setup(t, true)
+
+		pendingJob := &model.Job{Id: model.NewId(), Status: model.JobStatusPending, Type: model.JobTypeBlevePostIndexing, Data: model.StringMap{"start_time": "1000"}}
+		mockStore.JobStore.On("GetAllByTypeAndStatus", mock.Anything, model.JobTypeBlevePostIndexing, model.JobStatusPending).Return([]*model.Job{pendingJob}, nil)
+
+		worker.CatchUp(2000)
+
+		mockStore.JobStore.AssertNotCalled(t, "Save", mock.Anything)
+	})
+}
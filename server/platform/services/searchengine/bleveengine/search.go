@@ -19,14 +19,14 @@ const DeletePostsBatchSize = 500
 const DeleteFilesBatchSize = 500
 
 func (b *BleveEngine) IndexPost(post *model.Post, teamId string) *model.AppError {
-	b.Mutex.RLock()
-	defer b.Mutex.RUnlock()
+	return b.writeAndSync(func() (*clusterOperation, *model.AppError) {
+		blvPost := BLVPostFromPost(post, teamId)
+		if err := b.PostIndex.Index(blvPost.Id, blvPost); err != nil {
+			return nil, model.NewAppError("Bleveengine.IndexPost", "bleveengine.index_post.error", nil, "", http.StatusInternalServerError).Wrap(err)
+		}
 
-	blvPost := BLVPostFromPost(post, teamId)
-	if err := b.PostIndex.Index(blvPost.Id, blvPost); err != nil {
-		return model.NewAppError("Bleveengine.IndexPost", "bleveengine.index_post.error", nil, "", http.StatusInternalServerError).Wrap(err)
-	}
-	return nil
+		return &clusterOperation{Op: clusterOpIndex, Index: PostIndex, Posts: []*BLVPost{blvPost}}, nil
+	})
 }
 
 func (b *BleveEngine) SearchPosts(channels model.ChannelList, searchParams []*model.SearchParams, page, perPage int) ([]string, model.PostSearchMatches, *model.AppError) {
@@ -257,62 +257,60 @@ func (b *BleveEngine) deletePosts(searchRequest *bleve.SearchRequest, batchSize
 }
 
 func (b *BleveEngine) DeleteChannelPosts(rctx request.CTX, channelID string) *model.AppError {
-	b.Mutex.RLock()
-	defer b.Mutex.RUnlock()
-
-	query := bleve.NewTermQuery(channelID)
-	query.SetField("ChannelId")
-	search := bleve.NewSearchRequest(query)
-	deleted, err := b.deletePosts(search, DeletePostsBatchSize)
-	if err != nil {
-		return model.NewAppError("Bleveengine.DeleteChannelPosts",
-			"bleveengine.delete_channel_posts.error", nil,
-			err.Error(), http.StatusInternalServerError)
-	}
+	return b.writeAndSync(func() (*clusterOperation, *model.AppError) {
+		query := bleve.NewTermQuery(channelID)
+		query.SetField("ChannelId")
+		search := bleve.NewSearchRequest(query)
+		deleted, err := b.deletePosts(search, DeletePostsBatchSize)
+		if err != nil {
+			return nil, model.NewAppError("Bleveengine.DeleteChannelPosts",
+				"bleveengine.delete_channel_posts.error", nil,
+				err.Error(), http.StatusInternalServerError)
+		}
 
-	rctx.Logger().Info("Posts for channel deleted", mlog.String("channel_id", channelID), mlog.Int("deleted", deleted))
+		rctx.Logger().Info("Posts for channel deleted", mlog.String("channel_id", channelID), mlog.Int("deleted", deleted))
 
-	return nil
+		return &clusterOperation{Op: clusterOpDeleteByTerm, Index: PostIndex, Field: "ChannelId", Term: channelID}, nil
+	})
 }
 
 func (b *BleveEngine) DeleteUserPosts(rctx request.CTX, userID string) *model.AppError {
-	b.Mutex.RLock()
-	defer b.Mutex.RUnlock()
-
-	query := bleve.NewTermQuery(userID)
-	query.SetField("UserId")
-	search := bleve.NewSearchRequest(query)
-	deleted, err := b.deletePosts(search, DeletePostsBatchSize)
-	if err != nil {
-		return model.NewAppError("Bleveengine.DeleteUserPosts",
-			"bleveengine.delete_user_posts.error", nil,
-			err.Error(), http.StatusInternalServerError)
-	}
+	return b.writeAndSync(func() (*clusterOperation, *model.AppError) {
+		query := bleve.NewTermQuery(userID)
+		query.SetField("UserId")
+		search := bleve.NewSearchRequest(query)
+		deleted, err := b.deletePosts(search, DeletePostsBatchSize)
+		if err != nil {
+			return nil, model.NewAppError("Bleveengine.DeleteUserPosts",
+				"bleveengine.delete_user_posts.error", nil,
+				err.Error(), http.StatusInternalServerError)
+		}
 
-	rctx.Logger().Info("Posts for user deleted", mlog.String("user_id", userID), mlog.Int("deleted", deleted))
+		rctx.Logger().Info("Posts for user deleted", mlog.String("user_id", userID), mlog.Int("deleted", deleted))
 
-	return nil
+		return &clusterOperation{Op: clusterOpDeleteByTerm, Index: PostIndex, Field: "UserId", Term: userID}, nil
+	})
 }
 
 func (b *BleveEngine) DeletePost(post *model.Post) *model.AppError {
-	b.Mutex.RLock()
-	defer b.Mutex.RUnlock()
+	return b.writeAndSync(func() (*clusterOperation, *model.AppError) {
+		if err := b.PostIndex.Delete(post.Id); err != nil {
+			return nil, model.NewAppError("Bleveengine.DeletePost", "bleveengine.delete_post.error", nil, "", http.StatusInternalServerError).Wrap(err)
+		}
 
-	if err := b.PostIndex.Delete(post.Id); err != nil {
-		return model.NewAppError("Bleveengine.DeletePost", "bleveengine.delete_post.error", nil, "", http.StatusInternalServerError).Wrap(err)
-	}
-	return nil
+		return &clusterOperation{Op: clusterOpDelete, Index: PostIndex, Ids: []string{post.Id}}, nil
+	})
 }
 
 func (b *BleveEngine) IndexChannel(_ request.CTX, channel *model.Channel, userIDs, teamMemberIDs []string) *model.AppError {
-	b.Mutex.RLock()
-	defer b.Mutex.RUnlock()
+	return b.writeAndSync(func() (*clusterOperation, *model.AppError) {
+		blvChannel := BLVChannelFromChannel(channel, userIDs, teamMemberIDs)
+		if err := b.ChannelIndex.Index(blvChannel.Id, blvChannel); err != nil {
+			return nil, model.NewAppError("Bleveengine.IndexChannel", "bleveengine.index_channel.error", nil, "", http.StatusInternalServerError).Wrap(err)
+		}
 
-	blvChannel := BLVChannelFromChannel(channel, userIDs, teamMemberIDs)
-	if err := b.ChannelIndex.Index(blvChannel.Id, blvChannel); err != nil {
-		return model.NewAppError("Bleveengine.IndexChannel", "bleveengine.index_channel.error", nil, "", http.StatusInternalServerError).Wrap(err)
-	}
-	return nil
+		return &clusterOperation{Op: clusterOpIndex, Index: ChannelIndex, Channels: []*BLVChannel{blvChannel}}, nil
+	})
 }
 
 func (b *BleveEngine) SearchChannels(teamId, userID, term string, isGuest, _ bool) ([]string, *model.AppError) {
@@ -394,24 +392,24 @@ func (b *BleveEngine) SearchChannels(teamId, userID, term string, isGuest, _ boo
 }
 
 func (b *BleveEngine) DeleteChannel(channel *model.Channel) *model.AppError {
-	b.Mutex.RLock()
-	defer b.Mutex.RUnlock()
+	return b.writeAndSync(func() (*clusterOperation, *model.AppError) {
+		if err := b.ChannelIndex.Delete(channel.Id); err != nil {
+			return nil, model.NewAppError("Bleveengine.DeleteChannel", "bleveengine.delete_channel.error", nil, "", http.StatusInternalServerError).Wrap(err)
+		}
 
-	if err := b.ChannelIndex.Delete(channel.Id); err != nil {
-		return model.NewAppError("Bleveengine.DeleteChannel", "bleveengine.delete_channel.error", nil, "", http.StatusInternalServerError).Wrap(err)
-	}
-	return nil
+		return &clusterOperation{Op: clusterOpDelete, Index: ChannelIndex, Ids: []string{channel.Id}}, nil
+	})
 }
 
 func (b *BleveEngine) IndexUser(_ request.CTX, user *model.User, teamsIds, channelsIds []string) *model.AppError {
-	b.Mutex.RLock()
-	defer b.Mutex.RUnlock()
+	return b.writeAndSync(func() (*clusterOperation, *model.AppError) {
+		blvUser := BLVUserFromUserAndTeams(user, teamsIds, channelsIds)
+		if err := b.UserIndex.Index(blvUser.Id, blvUser); err != nil {
+			return nil, model.NewAppError("Bleveengine.IndexUser", "bleveengine.index_user.error", nil, "", http.StatusInternalServerError).Wrap(err)
+		}
 
-	blvUser := BLVUserFromUserAndTeams(user, teamsIds, channelsIds)
-	if err := b.UserIndex.Index(blvUser.Id, blvUser); err != nil {
-		return model.NewAppError("Bleveengine.IndexUser", "bleveengine.index_user.error", nil, "", http.StatusInternalServerError).Wrap(err)
-	}
-	return nil
+		return &clusterOperation{Op: clusterOpIndex, Index: UserIndex, Users: []*BLVUser{blvUser}}, nil
+	})
 }
 
 func (b *BleveEngine) SearchUsersInChannel(teamId, channelId string, restrictedToChannels []string, term string, options *model.UserSearchOptions) ([]string, []string, *model.AppError) {
@@ -553,24 +551,24 @@ func (b *BleveEngine) SearchUsersInTeam(teamId string, restrictedToChannels []st
 }
 
 func (b *BleveEngine) DeleteUser(user *model.User) *model.AppError {
-	b.Mutex.RLock()
-	defer b.Mutex.RUnlock()
+	return b.writeAndSync(func() (*clusterOperation, *model.AppError) {
+		if err := b.UserIndex.Delete(user.Id); err != nil {
+			return nil, model.NewAppError("Bleveengine.DeleteUser", "bleveengine.delete_user.error", nil, "", http.StatusInternalServerError).Wrap(err)
+		}
 
-	if err := b.UserIndex.Delete(user.Id); err != nil {
-		return model.NewAppError("Bleveengine.DeleteUser", "bleveengine.delete_user.error", nil, "", http.StatusInternalServerError).Wrap(err)
-	}
-	return nil
+		return &clusterOperation{Op: clusterOpDelete, Index: UserIndex, Ids: []string{user.Id}}, nil
+	})
 }
 
 func (b *BleveEngine) IndexFile(file *model.FileInfo, channelId string) *model.AppError {
-	b.Mutex.RLock()
-	defer b.Mutex.RUnlock()
+	return b.writeAndSync(func() (*clusterOperation, *model.AppError) {
+		blvFile := BLVFileFromFileInfo(file, channelId)
+		if err := b.FileIndex.Index(blvFile.Id, blvFile); err != nil {
+			return nil, model.NewAppError("Bleveengine.IndexFile", "bleveengine.index_file.error", nil, "", http.StatusInternalServerError).Wrap(err)
+		}
 
-	blvFile := BLVFileFromFileInfo(file, channelId)
-	if err := b.FileIndex.Index(blvFile.Id, blvFile); err != nil {
-		return model.NewAppError("Bleveengine.IndexFile", "bleveengine.index_file.error", nil, "", http.StatusInternalServerError).Wrap(err)
-	}
-	return nil
+		return &clusterOperation{Op: clusterOpIndex, Index: FileIndex, Files: []*BLVFile{blvFile}}, nil
+	})
 }
 
 func (b *BleveEngine) SearchFiles(channels model.ChannelList, searchParams []*model.SearchParams, page, perPage int) ([]string, *model.AppError) {
@@ -782,13 +780,13 @@ func (b *BleveEngine) SearchFiles(channels model.ChannelList, searchParams []*mo
 }
 
 func (b *BleveEngine) DeleteFile(fileID string) *model.AppError {
-	b.Mutex.RLock()
-	defer b.Mutex.RUnlock()
+	return b.writeAndSync(func() (*clusterOperation, *model.AppError) {
+		if err := b.FileIndex.Delete(fileID); err != nil {
+			return nil, model.NewAppError("Bleveengine.DeleteFile", "bleveengine.delete_file.error", nil, "", http.StatusInternalServerError).Wrap(err)
+		}
 
-	if err := b.FileIndex.Delete(fileID); err != nil {
-		return model.NewAppError("Bleveengine.DeleteFile", "bleveengine.delete_file.error", nil, "", http.StatusInternalServerError).Wrap(err)
-	}
-	return nil
+		return &clusterOperation{Op: clusterOpDelete, Index: FileIndex, Ids: []string{fileID}}, nil
+	})
 }
 
 func (b *BleveEngine) deleteFiles(searchRequest *bleve.SearchRequest, batchSize int) (int64, error) {
@@ -820,61 +818,62 @@ func (b *BleveEngine) deleteFiles(searchRequest *bleve.SearchRequest, batchSize
 }
 
 func (b *BleveEngine) DeleteUserFiles(rctx request.CTX, userID string) *model.AppError {
-	b.Mutex.RLock()
-	defer b.Mutex.RUnlock()
-
-	query := bleve.NewTermQuery(userID)
-	query.SetField("CreatorId")
-	search := bleve.NewSearchRequest(query)
-	deleted, err := b.deleteFiles(search, DeleteFilesBatchSize)
-	if err != nil {
-		return model.NewAppError("Bleveengine.DeleteUserFiles",
-			"bleveengine.delete_user_files.error", nil,
-			err.Error(), http.StatusInternalServerError)
-	}
+	return b.writeAndSync(func() (*clusterOperation, *model.AppError) {
+		query := bleve.NewTermQuery(userID)
+		query.SetField("CreatorId")
+		search := bleve.NewSearchRequest(query)
+		deleted, err := b.deleteFiles(search, DeleteFilesBatchSize)
+		if err != nil {
+			return nil, model.NewAppError("Bleveengine.DeleteUserFiles",
+				"bleveengine.delete_user_files.error", nil,
+				err.Error(), http.StatusInternalServerError)
+		}
 
-	rctx.Logger().Info("Files for user deleted", mlog.String("user_id", userID), mlog.Int("deleted", deleted))
+		rctx.Logger().Info("Files for user deleted", mlog.String("user_id", userID), mlog.Int("deleted", deleted))
 
-	return nil
+		return &clusterOperation{Op: clusterOpDeleteByTerm, Index: FileIndex, Field: "CreatorId", Term: userID}, nil
+	})
 }
 
 func (b *BleveEngine) DeletePostFiles(rctx request.CTX, postID string) *model.AppError {
-	b.Mutex.RLock()
-	defer b.Mutex.RUnlock()
-
-	query := bleve.NewTermQuery(postID)
-	query.SetField("PostId")
-	search := bleve.NewSearchRequest(query)
-	deleted, err := b.deleteFiles(search, DeleteFilesBatchSize)
-	if err != nil {
-		return model.NewAppError("Bleveengine.DeletePostFiles",
-			"bleveengine.delete_post_files.error", nil,
-			err.Error(), http.StatusInternalServerError)
-	}
+	return b.writeAndSync(func() (*clusterOperation, *model.AppError) {
+		query := bleve.NewTermQuery(postID)
+		query.SetField("PostId")
+		search := bleve.NewSearchRequest(query)
+		deleted, err := b.deleteFiles(search, DeleteFilesBatchSize)
+		if err != nil {
+			return nil, model.NewAppError("Bleveengine.DeletePostFiles",
+				"bleveengine.delete_post_files.error", nil,
+				err.Error(), http.StatusInternalServerError)
+		}
 
-	rctx.Logger().Info("Files for post deleted", mlog.String("post_id", postID), mlog.Int("deleted", deleted))
+		rctx.Logger().Info("Files for post deleted", mlog.String("post_id", postID), mlog.Int("deleted", deleted))
 
-	return nil
+		return &clusterOperation{Op: clusterOpDeleteByTerm, Index: FileIndex, Field: "PostId", Term: postID}, nil
+	})
 }
 
 func (b *BleveEngine) DeleteFilesBatch(rctx request.CTX, endTime, limit int64) *model.AppError {
-	b.Mutex.RLock()
-	defer b.Mutex.RUnlock()
+	return b.writeAndSync(func() (*clusterOperation, *model.AppError) {
+		deleted, err := b.deleteFilesBatch(endTime, limit)
+		if err != nil {
+			return nil, model.NewAppError("Bleveengine.DeleteFilesBatch",
+				"bleveengine.delete_files_batch.error", nil,
+				err.Error(), http.StatusInternalServerError)
+		}
+
+		rctx.Logger().Info("Files in batch deleted", mlog.Int("endTime", endTime), mlog.Int("limit", limit), mlog.Int("deleted", deleted))
 
+		return &clusterOperation{Op: clusterOpDeleteFilesBatch, EndTime: endTime, Limit: limit}, nil
+	})
+}
+
+func (b *BleveEngine) deleteFilesBatch(endTime, limit int64) (int64, error) {
 	endTimeFloat := float64(endTime)
 	query := bleve.NewNumericRangeQuery(nil, &endTimeFloat)
 	query.SetField("CreateAt")
 	search := bleve.NewSearchRequestOptions(query, int(limit), 0, false)
 	search.SortBy([]string{"-CreateAt"})
 
-	deleted, err := b.deleteFiles(search, DeleteFilesBatchSize)
-	if err != nil {
-		return model.NewAppError("Bleveengine.DeleteFilesBatch",
-			"bleveengine.delete_files_batch.error", nil,
-			err.Error(), http.StatusInternalServerError)
-	}
-
-	rctx.Logger().Info("Files in batch deleted", mlog.Int("endTime", endTime), mlog.Int("limit", limit), mlog.Int("deleted", deleted))
-
-	return nil
+	return b.deleteFiles(search, DeleteFilesBatchSize)
 }
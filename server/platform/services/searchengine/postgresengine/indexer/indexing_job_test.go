@@ -0,0 +1,74 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package indexer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/v8/channels/jobs"
+	"github.com/mattermost/mattermost/server/v8/channels/store/storetest"
+	"github.com/mattermost/mattermost/server/v8/channels/utils/testutils"
+	"github.com/mattermost/mattermost/server/v8/platform/services/searchengine/postgresengine"
+)
+
+func TestPostgresIndexer(t *testing.T) {
+	mockStore := &storetest.Store{}
+	defer mockStore.AssertExpectations(t)
+
+	t.Run("Fail the job when the engine isn't active", func(t *testing.T) {
+		job := &model.Job{
+			Id:       model.NewId(),
+			CreateAt: model.GetMillis(),
+			Status:   model.JobStatusPending,
+			Type:     model.JobTypePostgresPostIndexing,
+		}
+
+		mockStore.JobStore.On("UpdateStatusOptimistically", job.Id, model.JobStatusPending, model.JobStatusInProgress).Return(true, nil)
+		mockStore.JobStore.On("UpdateOptimistically", job, model.JobStatusInProgress).Return(true, nil)
+
+		cfg := &model.Config{}
+		cfg.SetDefaults()
+
+		jobServer := &jobs.JobServer{
+			Store: mockStore,
+			ConfigService: &testutils.StaticConfigService{
+				Cfg: cfg,
+			},
+		}
+
+		worker := &PostgresIndexerWorker{
+			jobServer: jobServer,
+			engine:    postgresengine.NewPostgresEngine(cfg, mlog.CreateConsoleTestLogger(t)),
+			logger:    mlog.CreateConsoleTestLogger(t),
+		}
+
+		worker.DoJob(job)
+
+		assert.Equal(t, model.JobStatusError, job.Status)
+		mockStore.PostStore.AssertNotCalled(t, "GetOldestEntityCreationTime")
+	})
+}
+
+func TestIndexingProgress(t *testing.T) {
+	progress := IndexingProgress{TotalPostsCount: 30, TotalFilesCount: 10}
+	assert.Equal(t, int64(0), progress.CurrentProgress())
+	assert.False(t, progress.IsDone())
+
+	progress.DonePostsCount = 30
+	progress.DonePosts = true
+	assert.Equal(t, int64(75), progress.CurrentProgress())
+	assert.False(t, progress.IsDone())
+
+	progress.DoneFilesCount = 10
+	progress.DoneFiles = true
+	assert.Equal(t, int64(100), progress.CurrentProgress())
+	assert.True(t, progress.IsDone())
+
+	empty := IndexingProgress{}
+	assert.Equal(t, int64(100), empty.CurrentProgress())
+}
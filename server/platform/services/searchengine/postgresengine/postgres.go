@@ -0,0 +1,229 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package postgresengine
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	sq "github.com/mattermost/squirrel"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	sqlUtils "github.com/mattermost/mattermost/server/public/utils/sql"
+)
+
+const (
+	EngineName = "postgres"
+	PostIndex  = "postsearchindex"
+	FileIndex  = "filesearchindex"
+
+	connectionPingAttempts = 5
+
+	// The engine shares the database with the store, so its pool is kept smaller than the
+	// one of the SqlSettings, which is sized for the store.
+	maxOpenConnections = 10
+	maxIdleConnections = 2
+)
+
+// PostgresEngine indexes the posts and the files in dedicated tables of the PostgreSQL
+// database, using tsvector columns with GIN indexes, and ranks the results with ts_rank.
+// The channels and the users are still searched by the database layer.
+type PostgresEngine struct {
+	db        *sql.DB
+	mutex     sync.RWMutex
+	ready     int32
+	cfg       *model.Config
+	logger    mlog.LoggerIFace
+	indexSync bool
+}
+
+func NewPostgresEngine(cfg *model.Config, logger mlog.LoggerIFace) *PostgresEngine {
+	return &PostgresEngine{
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+func (p *PostgresEngine) open() *model.AppError {
+	if atomic.LoadInt32(&p.ready) != 0 {
+		return model.NewAppError("PostgresEngine.Start", "postgresengine.already_started.error", nil, "", http.StatusInternalServerError)
+	}
+
+	db, err := sqlUtils.SetupConnection(p.logger, "search", *p.cfg.SqlSettings.DataSource, &p.cfg.SqlSettings, connectionPingAttempts)
+	if err != nil {
+		return model.NewAppError("PostgresEngine.Start", "postgresengine.connect.error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	db.SetMaxOpenConns(min(*p.cfg.SqlSettings.MaxOpenConns, maxOpenConnections))
+	db.SetMaxIdleConns(min(*p.cfg.SqlSettings.MaxIdleConns, maxIdleConnections))
+	p.db = db
+
+	atomic.StoreInt32(&p.ready, 1)
+	return nil
+}
+
+func (p *PostgresEngine) close() *model.AppError {
+	if p.IsActive() {
+		if err := p.db.Close(); err != nil {
+			return model.NewAppError("PostgresEngine.Stop", "postgresengine.close.error", nil, "", http.StatusInternalServerError).Wrap(err)
+		}
+		p.db = nil
+	}
+
+	atomic.StoreInt32(&p.ready, 0)
+	return nil
+}
+
+func (p *PostgresEngine) Start() *model.AppError {
+	if !p.isEnabled(p.cfg) {
+		return nil
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.logger.Info("Starting the Postgres search engine")
+
+	return p.open()
+}
+
+func (p *PostgresEngine) Stop() *model.AppError {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.logger.Info("Stopping the Postgres search engine")
+
+	return p.close()
+}
+
+// isEnabled returns whether the engine can be used with the given config, as it needs
+// the database to be PostgreSQL.
+func (p *PostgresEngine) isEnabled(cfg *model.Config) bool {
+	return *cfg.PostgresSearchSettings.EnableIndexing && *cfg.SqlSettings.DriverName == model.DatabaseDriverPostgres
+}
+
+func (p *PostgresEngine) IsEnabled() bool {
+	return p.isEnabled(p.cfg)
+}
+
+func (p *PostgresEngine) IsActive() bool {
+	return atomic.LoadInt32(&p.ready) == 1
+}
+
+func (p *PostgresEngine) IsIndexingSync() bool {
+	return p.indexSync
+}
+
+func (p *PostgresEngine) RefreshIndexes(_ request.CTX) *model.AppError {
+	return nil
+}
+
+func (p *PostgresEngine) GetVersion() int {
+	return 0
+}
+
+func (p *PostgresEngine) GetFullVersion() string {
+	return "0"
+}
+
+func (p *PostgresEngine) GetPlugins() []string {
+	return []string{}
+}
+
+func (p *PostgresEngine) GetName() string {
+	return EngineName
+}
+
+func (p *PostgresEngine) TestConfig(rctx request.CTX, cfg *model.Config) *model.AppError {
+	return nil
+}
+
+func (p *PostgresEngine) PurgeIndexes(rctx request.CTX) *model.AppError {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if !p.IsActive() {
+		return nil
+	}
+
+	rctx.Logger().Info("PurgeIndexes Postgres")
+
+	ctx, cancel := p.context()
+	defer cancel()
+
+	if _, err := p.db.ExecContext(ctx, "TRUNCATE TABLE "+PostIndex+", "+FileIndex); err != nil {
+		return model.NewAppError("PostgresEngine.PurgeIndexes", "postgresengine.purge_indexes.error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	return nil
+}
+
+func (p *PostgresEngine) PurgeIndexList(rctx request.CTX, indexes []string) *model.AppError {
+	return model.NewAppError("PostgresEngine.PurgeIndexList", "postgresengine.purge_list.not_implemented", nil, "not implemented", http.StatusNotFound)
+}
+
+func (p *PostgresEngine) DataRetentionDeleteIndexes(rctx request.CTX, cutoff time.Time) *model.AppError {
+	return nil
+}
+
+func (p *PostgresEngine) IsAutocompletionEnabled() bool {
+	return false
+}
+
+func (p *PostgresEngine) IsIndexingEnabled() bool {
+	return *p.cfg.PostgresSearchSettings.EnableIndexing
+}
+
+func (p *PostgresEngine) IsSearchEnabled() bool {
+	return *p.cfg.PostgresSearchSettings.EnableSearching
+}
+
+func (p *PostgresEngine) UpdateConfig(cfg *model.Config) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if reflect.DeepEqual(cfg.PostgresSearchSettings, p.cfg.PostgresSearchSettings) {
+		return
+	}
+
+	p.logger.Info("UpdateConf Postgres search engine")
+
+	wasEnabled := p.isEnabled(p.cfg)
+	p.cfg = cfg
+	if wasEnabled == p.isEnabled(cfg) {
+		return
+	}
+
+	if err := p.close(); err != nil {
+		p.logger.Error("Error closing the Postgres search engine to update the config", mlog.Err(err))
+		return
+	}
+	if p.isEnabled(cfg) {
+		if err := p.open(); err != nil {
+			p.logger.Error("Error opening the Postgres search engine after updating the config", mlog.Err(err))
+		}
+	}
+}
+
+func (p *PostgresEngine) IsChannelsIndexVerified() bool {
+	return true
+}
+
+// context returns the context for a query, with the timeout of the database settings.
+func (p *PostgresEngine) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), time.Duration(*p.cfg.SqlSettings.QueryTimeout)*time.Second)
+}
+
+func (p *PostgresEngine) getQueryBuilder() sq.StatementBuilderType {
+	return sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+}
+
+func (p *PostgresEngine) textSearchConfig() string {
+	return *p.cfg.PostgresSearchSettings.TextSearchConfig
+}
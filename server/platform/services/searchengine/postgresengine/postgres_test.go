@@ -0,0 +1,178 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package postgresengine
+
+import (
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+	"github.com/mattermost/mattermost/server/v8/channels/store/searchlayer"
+	"github.com/mattermost/mattermost/server/v8/channels/store/searchtest"
+	"github.com/mattermost/mattermost/server/v8/channels/store/sqlstore"
+	"github.com/mattermost/mattermost/server/v8/channels/store/storetest"
+	"github.com/mattermost/mattermost/server/v8/channels/testlib"
+	"github.com/mattermost/mattermost/server/v8/platform/services/searchengine"
+)
+
+type PostgresEngineTestSuite struct {
+	suite.Suite
+
+	SQLSettings    *model.SqlSettings
+	SQLStore       *sqlstore.SqlStore
+	SearchEngine   *searchengine.Broker
+	Store          *searchlayer.SearchStore
+	PostgresEngine *PostgresEngine
+	Context        request.CTX
+}
+
+func TestPostgresEngineTestSuite(t *testing.T) {
+	if driverName := os.Getenv("MM_SQLSETTINGS_DRIVERNAME"); driverName != "" && driverName != model.DatabaseDriverPostgres {
+		t.Skip("The Postgres search engine requires a Postgres database")
+	}
+
+	suite.Run(t, &PostgresEngineTestSuite{
+		Context: request.TestContext(t),
+	})
+}
+
+func (s *PostgresEngineTestSuite) SetupSuite() {
+	s.SQLSettings = storetest.MakeSqlSettings(model.DatabaseDriverPostgres, false)
+
+	var err error
+	s.SQLStore, err = sqlstore.New(*s.SQLSettings, s.Context.Logger(), nil)
+	if err != nil {
+		s.Require().FailNow("Cannot initialize store: %s", err.Error())
+	}
+
+	cfg := &model.Config{}
+	cfg.SetDefaults()
+	cfg.SqlSettings = *s.SQLSettings
+	cfg.PostgresSearchSettings.EnableIndexing = model.NewPointer(true)
+	cfg.PostgresSearchSettings.EnableSearching = model.NewPointer(true)
+	cfg.SqlSettings.DisableDatabaseSearch = model.NewPointer(true)
+
+	s.SearchEngine = searchengine.NewBroker(cfg)
+	s.Store = searchlayer.NewSearchLayer(&testlib.TestStore{Store: s.SQLStore}, s.SearchEngine, cfg)
+
+	s.PostgresEngine = NewPostgresEngine(cfg, s.Context.Logger())
+	s.PostgresEngine.indexSync = true
+	s.SearchEngine.RegisterPostgresEngine(s.PostgresEngine)
+	if err := s.PostgresEngine.Start(); err != nil {
+		s.Require().FailNow("Cannot start postgresengine: %s", err.Error())
+	}
+}
+
+func (s *PostgresEngineTestSuite) TearDownSuite() {
+	s.PostgresEngine.Stop()
+	s.SQLStore.Close()
+	storetest.CleanupSqlSettings(s.SQLSettings)
+}
+
+func (s *PostgresEngineTestSuite) TestConnectionPool() {
+	stats := s.PostgresEngine.db.Stats()
+	require.Equal(s.T(), min(*s.SQLSettings.MaxOpenConns, maxOpenConnections), stats.MaxOpenConnections)
+	require.LessOrEqual(s.T(), stats.MaxOpenConnections, maxOpenConnections)
+}
+
+func (s *PostgresEngineTestSuite) TestPostgresSearchStoreTests() {
+	searchTestEngine := &searchtest.SearchTestEngine{
+		Driver: searchtest.EnginePostgres,
+	}
+
+	s.Run("TestSearchPostStore", func() {
+		searchtest.TestSearchPostStore(s.T(), s.Store, searchTestEngine)
+	})
+
+	s.Run("TestSearchFileInfoStore", func() {
+		searchtest.TestSearchFileInfoStore(s.T(), s.Store, searchTestEngine)
+	})
+}
+
+func (s *PostgresEngineTestSuite) TestDeleteChannelPosts() {
+	s.PostgresEngine.PurgeIndexes(s.Context)
+	teamID := model.NewId()
+	userID := model.NewId()
+	channelID := model.NewId()
+	channelToAvoidID := model.NewId()
+	for range 10 {
+		appErr := s.PostgresEngine.IndexPost(createPost(userID, channelID), teamID)
+		require.Nil(s.T(), appErr)
+	}
+	postToAvoid := createPost(userID, channelToAvoidID)
+	appErr := s.PostgresEngine.IndexPost(postToAvoid, teamID)
+	require.Nil(s.T(), appErr)
+
+	appErr = s.PostgresEngine.DeleteChannelPosts(s.Context, channelID)
+	require.Nil(s.T(), appErr)
+
+	ids, err := s.PostgresEngine.selectIds(s.PostgresEngine.getQueryBuilder().Select("postid").From(PostIndex))
+	require.NoError(s.T(), err)
+	require.Equal(s.T(), []string{postToAvoid.Id}, ids)
+}
+
+func (s *PostgresEngineTestSuite) TestSearchPostsRanking() {
+	s.PostgresEngine.PurgeIndexes(s.Context)
+	teamID := model.NewId()
+	userID := model.NewId()
+	channelID := model.NewId()
+
+	weakMatch := createPost(userID, channelID)
+	weakMatch.Message = "the deployment finished without any issue"
+	strongMatch := createPost(userID, channelID)
+	strongMatch.Message = "deployment failed, rolling back the deployment"
+	for _, post := range []*model.Post{weakMatch, strongMatch} {
+		appErr := s.PostgresEngine.IndexPost(post, teamID)
+		require.Nil(s.T(), appErr)
+	}
+
+	ids, _, appErr := s.PostgresEngine.SearchPosts(model.ChannelList{{Id: channelID}}, []*model.SearchParams{{Terms: "deployment"}}, 0, 20)
+	require.Nil(s.T(), appErr)
+	require.Equal(s.T(), []string{strongMatch.Id, weakMatch.Id}, ids)
+}
+
+func (s *PostgresEngineTestSuite) TestSearchPostsFiltersPerParams() {
+	s.PostgresEngine.PurgeIndexes(s.Context)
+	teamID := model.NewId()
+	userID := model.NewId()
+	otherUserID := model.NewId()
+	channelID := model.NewId()
+
+	posts := map[string]*model.Post{}
+	for _, postUserID := range []string{userID, otherUserID} {
+		for _, message := range []string{"alpha", "beta"} {
+			post := createPost(postUserID, channelID)
+			post.Message = message
+			appErr := s.PostgresEngine.IndexPost(post, teamID)
+			require.Nil(s.T(), appErr)
+			posts[postUserID+message] = post
+		}
+	}
+
+	// Every search params only matches the posts of its own user.
+	ids, _, appErr := s.PostgresEngine.SearchPosts(model.ChannelList{{Id: channelID}}, []*model.SearchParams{
+		{Terms: "alpha", FromUsers: []string{userID}},
+		{Terms: "beta", FromUsers: []string{otherUserID}},
+	}, 0, 20)
+	require.Nil(s.T(), appErr)
+	require.ElementsMatch(s.T(), []string{posts[userID+"alpha"].Id, posts[otherUserID+"beta"].Id}, ids)
+}
+
+func createPost(userID string, channelID string) *model.Post {
+	post := &model.Post{
+		Message:       model.NewRandomString(15),
+		ChannelId:     channelID,
+		PendingPostId: model.NewId() + ":" + strconv.FormatInt(model.GetMillis(), 10),
+		UserId:        userID,
+		CreateAt:      model.GetMillis(),
+	}
+	post.PreSave()
+
+	return post
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package postgresengine
+
+import (
+	"regexp"
+	"strings"
+
+	sq "github.com/mattermost/squirrel"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+var (
+	quotedStringsRegex = regexp.MustCompile(`("[^"]*")`)
+	wildcardRegex      = regexp.MustCompile(`\*($|[ "])`)
+)
+
+// specialSearchChars are the tsquery operators, which are removed from the search terms.
+var specialSearchChars = []string{"<", ">", "+", "-", "(", ")", "~", ":", "&", "|", "!", "'", "\\"}
+
+// tsQueryWords splits the terms in words, dropping the ones left without a lexeme.
+func tsQueryWords(terms string) []string {
+	words := []string{}
+	for _, word := range strings.Fields(terms) {
+		word = strings.TrimLeft(word, "*")
+		if word == "" || strings.HasPrefix(word, ":") {
+			continue
+		}
+		words = append(words, word)
+	}
+	return words
+}
+
+// toTSQuery converts the search terms to a tsquery. The words are joined by the given
+// operator, the quoted phrases use the followed by operator, and the words ending with
+// a star are prefix matches.
+func toTSQuery(terms, operator string) string {
+	for _, c := range specialSearchChars {
+		terms = strings.ReplaceAll(terms, c, " ")
+	}
+
+	terms = wildcardRegex.ReplaceAllString(terms, ":*$1")
+	terms = quotedStringsRegex.ReplaceAllStringFunc(terms, func(phrase string) string {
+		return strings.Join(tsQueryWords(strings.Trim(phrase, `"`)), "<->")
+	})
+
+	return strings.Join(tsQueryWords(strings.ReplaceAll(terms, `"`, " ")), operator)
+}
+
+// buildTSQuery returns the tsqueries matching the terms and the excluded terms of the
+// search params. The hashtags are only included when they aren't searched separately.
+func buildTSQuery(paramsList []*model.SearchParams, includeHashtags bool) (string, string) {
+	operator := " & "
+	if paramsList[0].OrTerms {
+		operator = " | "
+	}
+
+	var terms, excludedTerms []string
+	for _, params := range paramsList {
+		if params.IsHashtag && !includeHashtags {
+			continue
+		}
+
+		if query := toTSQuery(params.Terms, operator); query != "" {
+			terms = append(terms, "("+query+")")
+		}
+		if query := toTSQuery(params.ExcludedTerms, " | "); query != "" {
+			excludedTerms = append(excludedTerms, "("+query+")")
+		}
+	}
+
+	return strings.Join(terms, operator), strings.Join(excludedTerms, " | ")
+}
+
+// buildHashtags returns the hashtags and the excluded hashtags of the search params.
+func buildHashtags(paramsList []*model.SearchParams) ([]string, []string) {
+	var hashtags, excludedHashtags []string
+	for _, params := range paramsList {
+		if params.IsHashtag {
+			hashtags = append(hashtags, strings.Fields(strings.ToLower(params.Terms))...)
+			excludedHashtags = append(excludedHashtags, strings.Fields(strings.ToLower(params.ExcludedTerms))...)
+		}
+	}
+	return hashtags, excludedHashtags
+}
+
+// buildFilters returns the channels, users and dates filters of the search params.
+func buildFilters(params *model.SearchParams, userColumn string) sq.And {
+	filters := sq.And{}
+
+	if len(params.InChannels) > 0 {
+		filters = append(filters, sq.Eq{"channelid": params.InChannels})
+	}
+
+	if len(params.ExcludedChannels) > 0 {
+		filters = append(filters, sq.NotEq{"channelid": params.ExcludedChannels})
+	}
+
+	if len(params.FromUsers) > 0 {
+		filters = append(filters, sq.Eq{userColumn: params.FromUsers})
+	}
+
+	if len(params.ExcludedUsers) > 0 {
+		filters = append(filters, sq.NotEq{userColumn: params.ExcludedUsers})
+	}
+
+	if params.OnDate != "" {
+		onDateStart, onDateEnd := params.GetOnDateMillis()
+		filters = append(filters, sq.Expr("createat BETWEEN ? AND ?", onDateStart, onDateEnd))
+		return filters
+	}
+
+	if params.ExcludedDate != "" {
+		excludedDateStart, excludedDateEnd := params.GetExcludedDateMillis()
+		filters = append(filters, sq.Expr("createat NOT BETWEEN ? AND ?", excludedDateStart, excludedDateEnd))
+	}
+
+	if params.AfterDate != "" {
+		filters = append(filters, sq.GtOrEq{"createat": params.GetAfterDateMillis()})
+	}
+
+	if params.BeforeDate != "" {
+		filters = append(filters, sq.LtOrEq{"createat": params.GetBeforeDateMillis()})
+	}
+
+	if params.ExcludedAfterDate != "" {
+		filters = append(filters, sq.Lt{"createat": params.GetExcludedAfterDateMillis()})
+	}
+
+	if params.ExcludedBeforeDate != "" {
+		filters = append(filters, sq.Gt{"createat": params.GetExcludedBeforeDateMillis()})
+	}
+
+	return filters
+}
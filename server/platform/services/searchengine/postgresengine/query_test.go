@@ -0,0 +1,123 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package postgresengine
+
+import (
+	"testing"
+
+	sq "github.com/mattermost/squirrel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+func TestToTSQuery(t *testing.T) {
+	for name, tc := range map[string]struct {
+		terms    string
+		operator string
+		expected string
+	}{
+		"empty":                   {terms: "", operator: " & ", expected: ""},
+		"words":                   {terms: "hello  world", operator: " & ", expected: "hello & world"},
+		"or":                      {terms: "hello world", operator: " | ", expected: "hello | world"},
+		"prefix":                  {terms: "hel* world", operator: " & ", expected: "hel:* & world"},
+		"phrase":                  {terms: `"hello big world" again`, operator: " & ", expected: "hello<->big<->world & again"},
+		"prefix in a phrase":      {terms: `"hello wor*"`, operator: " & ", expected: "hello<->wor:*"},
+		"unbalanced quote":        {terms: `"hello world`, operator: " & ", expected: "hello & world"},
+		"operators":               {terms: "a&b | !c (d) <e> f:g 'h' i\\j", operator: " & ", expected: "a & b & c & d & e & f & g & h & i & j"},
+		"dashes":                  {terms: "foo-bar", operator: " & ", expected: "foo & bar"},
+		"lone stars":              {terms: "* ** hello", operator: " & ", expected: "hello"},
+		"email":                   {terms: "test@example.com", operator: " & ", expected: "test@example.com"},
+		"empty phrase":            {terms: `"" hello`, operator: " & ", expected: "hello"},
+		"spaces inside a phrase":  {terms: `" hello  world "`, operator: " & ", expected: "hello<->world"},
+		"only special characters": {terms: "() <> !", operator: " & ", expected: ""},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, toTSQuery(tc.terms, tc.operator))
+		})
+	}
+}
+
+func TestBuildTSQuery(t *testing.T) {
+	t.Run("terms and excluded terms", func(t *testing.T) {
+		terms, excludedTerms := buildTSQuery([]*model.SearchParams{
+			{Terms: "hello world", ExcludedTerms: "foo bar"},
+		}, false)
+		assert.Equal(t, "(hello & world)", terms)
+		assert.Equal(t, "(foo | bar)", excludedTerms)
+	})
+
+	t.Run("or terms", func(t *testing.T) {
+		terms, excludedTerms := buildTSQuery([]*model.SearchParams{
+			{Terms: "hello world", OrTerms: true},
+			{Terms: "again", OrTerms: true},
+		}, false)
+		assert.Equal(t, "(hello | world) | (again)", terms)
+		assert.Empty(t, excludedTerms)
+	})
+
+	t.Run("hashtags", func(t *testing.T) {
+		paramsList := []*model.SearchParams{
+			{Terms: "hello"},
+			{Terms: "#tag", ExcludedTerms: "#other", IsHashtag: true},
+		}
+
+		terms, excludedTerms := buildTSQuery(paramsList, false)
+		assert.Equal(t, "(hello)", terms)
+		assert.Empty(t, excludedTerms)
+
+		terms, excludedTerms = buildTSQuery(paramsList, true)
+		assert.Equal(t, "(hello) & (#tag)", terms)
+		assert.Equal(t, "(#other)", excludedTerms)
+
+		hashtags, excludedHashtags := buildHashtags(paramsList)
+		assert.Equal(t, []string{"#tag"}, hashtags)
+		assert.Equal(t, []string{"#other"}, excludedHashtags)
+	})
+}
+
+func TestBuildFilters(t *testing.T) {
+	build := func(params *model.SearchParams) (string, []any) {
+		query := sq.Select("postid").From(PostIndex).Where(buildFilters(params, "userid"))
+		sql, args, err := query.ToSql()
+		require.NoError(t, err)
+		return sql, args
+	}
+
+	t.Run("no filters", func(t *testing.T) {
+		sql, args := build(&model.SearchParams{})
+		assert.Equal(t, "SELECT postid FROM postsearchindex WHERE (1=1)", sql)
+		assert.Empty(t, args)
+	})
+
+	t.Run("channels and users", func(t *testing.T) {
+		sql, args := build(&model.SearchParams{
+			InChannels:       []string{"channel1"},
+			ExcludedChannels: []string{"channel2"},
+			FromUsers:        []string{"user1"},
+			ExcludedUsers:    []string{"user2"},
+		})
+		assert.Equal(t, "SELECT postid FROM postsearchindex WHERE (channelid IN (?) AND channelid NOT IN (?) AND userid IN (?) AND userid NOT IN (?))", sql)
+		assert.Equal(t, []any{"channel1", "channel2", "user1", "user2"}, args)
+	})
+
+	t.Run("on date", func(t *testing.T) {
+		params := &model.SearchParams{OnDate: "2024-05-01", AfterDate: "2024-01-01"}
+		start, end := params.GetOnDateMillis()
+
+		sql, args := build(params)
+		assert.Equal(t, "SELECT postid FROM postsearchindex WHERE (createat BETWEEN ? AND ?)", sql)
+		assert.Equal(t, []any{start, end}, args)
+	})
+
+	t.Run("date ranges", func(t *testing.T) {
+		params := &model.SearchParams{AfterDate: "2024-01-01", BeforeDate: "2024-02-01", ExcludedDate: "2024-01-15"}
+		excludedStart, excludedEnd := params.GetExcludedDateMillis()
+
+		sql, args := build(params)
+		assert.Equal(t, "SELECT postid FROM postsearchindex WHERE (createat NOT BETWEEN ? AND ? AND createat >= ? AND createat <= ?)", sql)
+		assert.Equal(t, []any{excludedStart, excludedEnd, params.GetAfterDateMillis(), params.GetBeforeDateMillis()}, args)
+	})
+}
@@ -0,0 +1,483 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package postgresengine
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/lib/pq"
+	sq "github.com/mattermost/squirrel"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+	"github.com/mattermost/mattermost/server/public/shared/request"
+)
+
+// insertBatchSize keeps the number of parameters of the insert statements under the
+// PostgreSQL limit.
+const insertBatchSize = 1000
+
+func (p *PostgresEngine) IndexPost(post *model.Post, teamId string) *model.AppError {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	postForIndexing := &model.PostForIndexing{
+		TeamId: teamId,
+	}
+	post.ShallowCopy(&postForIndexing.Post)
+
+	if err := p.bulkIndexPosts([]*model.PostForIndexing{postForIndexing}); err != nil {
+		return model.NewAppError("PostgresEngine.IndexPost", "postgresengine.index_post.error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	return nil
+}
+
+// BulkIndexPosts indexes the given posts, and removes the deleted ones from the index.
+func (p *PostgresEngine) BulkIndexPosts(posts []*model.PostForIndexing) error {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.bulkIndexPosts(posts)
+}
+
+func (p *PostgresEngine) bulkIndexPosts(posts []*model.PostForIndexing) error {
+	if !p.IsActive() {
+		return errors.New("the Postgres search engine is not active")
+	}
+
+	var deletedIds []string
+	query := p.getQueryBuilder().
+		Insert(PostIndex).
+		Columns("postid", "teamid", "channelid", "userid", "type", "createat", "hashtags", "message").
+		Suffix(`ON CONFLICT (postid) DO UPDATE SET
+			teamid = EXCLUDED.teamid,
+			channelid = EXCLUDED.channelid,
+			userid = EXCLUDED.userid,
+			type = EXCLUDED.type,
+			createat = EXCLUDED.createat,
+			hashtags = EXCLUDED.hashtags,
+			message = EXCLUDED.message`)
+
+	var values [][]any
+	for _, post := range posts {
+		if post.DeleteAt != 0 {
+			deletedIds = append(deletedIds, post.Id)
+			continue
+		}
+
+		hashtags := []string{}
+		hashtags = append(hashtags, strings.Fields(strings.ToLower(post.Hashtags))...)
+		values = append(values, []any{
+			post.Id,
+			post.TeamId,
+			post.ChannelId,
+			post.UserId,
+			post.Type,
+			post.CreateAt,
+			pq.Array(hashtags),
+			sq.Expr("to_tsvector(?::regconfig, ?)", p.textSearchConfig(), post.Message),
+		})
+	}
+
+	return p.write(query, values, PostIndex, "postid", deletedIds)
+}
+
+func (p *PostgresEngine) SearchPosts(channels model.ChannelList, searchParams []*model.SearchParams, page, perPage int) ([]string, model.PostSearchMatches, *model.AppError) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	channelIds := make([]string, 0, len(channels))
+	for _, channel := range channels {
+		channelIds = append(channelIds, channel.Id)
+	}
+
+	query := p.getQueryBuilder().
+		Select("postid").
+		From(PostIndex).
+		Where("channelid = ANY(?)", pq.Array(channelIds)).
+		Where(fmt.Sprintf("type NOT LIKE '%s%%'", model.PostSystemMessagePrefix)).
+		Limit(uint64(perPage)).
+		Offset(uint64(page * perPage))
+
+	// Like the database search, every search params is searched with its own filters,
+	// and the posts matching any of them are returned.
+	conditions := sq.Or{}
+	for _, params := range searchParams {
+		conditions = append(conditions, p.postsCondition(params))
+	}
+	query = query.Where(conditions)
+
+	if terms, _ := buildTSQuery(searchParams, false); terms != "" {
+		query = query.OrderByClause("ts_rank(message, to_tsquery(?::regconfig, ?)) DESC", p.textSearchConfig(), terms)
+	}
+	query = query.OrderBy("createat DESC")
+
+	postIds, err := p.selectIds(query)
+	if err != nil {
+		return nil, nil, model.NewAppError("PostgresEngine.SearchPosts", "postgresengine.search_posts.error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
+	return postIds, model.PostSearchMatches{}, nil
+}
+
+// postsCondition returns the condition matching the posts of one search params.
+func (p *PostgresEngine) postsCondition(params *model.SearchParams) sq.And {
+	condition := buildFilters(params, "userid")
+
+	paramsList := []*model.SearchParams{params}
+	terms, excludedTerms := buildTSQuery(paramsList, false)
+	hashtags, excludedHashtags := buildHashtags(paramsList)
+
+	if terms != "" {
+		condition = append(condition, sq.Expr("message @@ to_tsquery(?::regconfig, ?)", p.textSearchConfig(), terms))
+	}
+	if len(hashtags) > 0 {
+		if params.OrTerms {
+			condition = append(condition, sq.Expr("hashtags && ?", pq.Array(hashtags)))
+		} else {
+			condition = append(condition, sq.Expr("hashtags @> ?", pq.Array(hashtags)))
+		}
+	}
+
+	if excludedTerms != "" {
+		condition = append(condition, sq.Expr("NOT message @@ to_tsquery(?::regconfig, ?)", p.textSearchConfig(), excludedTerms))
+	}
+	if len(excludedHashtags) > 0 {
+		condition = append(condition, sq.Expr("NOT hashtags && ?", pq.Array(excludedHashtags)))
+	}
+
+	return condition
+}
+
+func (p *PostgresEngine) DeleteChannelPosts(rctx request.CTX, channelID string) *model.AppError {
+	deleted, err := p.delete(PostIndex, sq.Eq{"channelid": channelID})
+	if err != nil {
+		return model.NewAppError("PostgresEngine.DeleteChannelPosts", "postgresengine.delete_channel_posts.error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
+	rctx.Logger().Info("Posts for channel deleted", mlog.String("channel_id", channelID), mlog.Int("deleted", deleted))
+
+	return nil
+}
+
+func (p *PostgresEngine) DeleteUserPosts(rctx request.CTX, userID string) *model.AppError {
+	deleted, err := p.delete(PostIndex, sq.Eq{"userid": userID})
+	if err != nil {
+		return model.NewAppError("PostgresEngine.DeleteUserPosts", "postgresengine.delete_user_posts.error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
+	rctx.Logger().Info("Posts for user deleted", mlog.String("user_id", userID), mlog.Int("deleted", deleted))
+
+	return nil
+}
+
+func (p *PostgresEngine) DeletePost(post *model.Post) *model.AppError {
+	if _, err := p.delete(PostIndex, sq.Eq{"postid": post.Id}); err != nil {
+		return model.NewAppError("PostgresEngine.DeletePost", "postgresengine.delete_post.error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	return nil
+}
+
+func (p *PostgresEngine) IndexChannel(_ request.CTX, channel *model.Channel, userIDs, teamMemberIDs []string) *model.AppError {
+	return nil
+}
+
+func (p *PostgresEngine) SearchChannels(teamId, userID, term string, isGuest, _ bool) ([]string, *model.AppError) {
+	return nil, model.NewAppError("PostgresEngine.SearchChannels", "postgresengine.search_channels.not_implemented", nil, "not implemented", http.StatusNotImplemented)
+}
+
+func (p *PostgresEngine) DeleteChannel(channel *model.Channel) *model.AppError {
+	return nil
+}
+
+func (p *PostgresEngine) IndexUser(_ request.CTX, user *model.User, teamsIds, channelsIds []string) *model.AppError {
+	return nil
+}
+
+func (p *PostgresEngine) SearchUsersInChannel(teamId, channelId string, restrictedToChannels []string, term string, options *model.UserSearchOptions) ([]string, []string, *model.AppError) {
+	return nil, nil, model.NewAppError("PostgresEngine.SearchUsersInChannel", "postgresengine.search_users.not_implemented", nil, "not implemented", http.StatusNotImplemented)
+}
+
+func (p *PostgresEngine) SearchUsersInTeam(teamId string, restrictedToChannels []string, term string, options *model.UserSearchOptions) ([]string, *model.AppError) {
+	return nil, model.NewAppError("PostgresEngine.SearchUsersInTeam", "postgresengine.search_users.not_implemented", nil, "not implemented", http.StatusNotImplemented)
+}
+
+func (p *PostgresEngine) DeleteUser(user *model.User) *model.AppError {
+	return nil
+}
+
+func (p *PostgresEngine) IndexFile(file *model.FileInfo, channelId string) *model.AppError {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	fileForIndexing := &model.FileForIndexing{
+		FileInfo:  *file,
+		ChannelId: channelId,
+		Content:   file.Content,
+	}
+
+	if err := p.bulkIndexFiles([]*model.FileForIndexing{fileForIndexing}); err != nil {
+		return model.NewAppError("PostgresEngine.IndexFile", "postgresengine.index_file.error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	return nil
+}
+
+// BulkIndexFiles indexes the given files, and removes the ones which shouldn't be
+// searchable anymore from the index.
+func (p *PostgresEngine) BulkIndexFiles(files []*model.FileForIndexing) error {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.bulkIndexFiles(files)
+}
+
+func (p *PostgresEngine) bulkIndexFiles(files []*model.FileForIndexing) error {
+	if !p.IsActive() {
+		return errors.New("the Postgres search engine is not active")
+	}
+
+	var deletedIds []string
+	query := p.getQueryBuilder().
+		Insert(FileIndex).
+		Columns("fileid", "postid", "channelid", "creatorid", "extension", "createat", "content").
+		Suffix(`ON CONFLICT (fileid) DO UPDATE SET
+			postid = EXCLUDED.postid,
+			channelid = EXCLUDED.channelid,
+			creatorid = EXCLUDED.creatorid,
+			extension = EXCLUDED.extension,
+			createat = EXCLUDED.createat,
+			content = EXCLUDED.content`)
+
+	var values [][]any
+	for _, file := range files {
+		if !file.ShouldIndex() {
+			deletedIds = append(deletedIds, file.Id)
+			continue
+		}
+
+		// The file names are searchable as a whole and by their parts, the name
+		// matches being ranked above the content ones.
+		name := file.Name + " " + splitFilenameWords(file.Name)
+		values = append(values, []any{
+			file.Id,
+			file.PostId,
+			file.ChannelId,
+			file.CreatorId,
+			file.Extension,
+			file.CreateAt,
+			sq.Expr("setweight(to_tsvector(?::regconfig, ?), 'A') || setweight(to_tsvector(?::regconfig, ?), 'B')", p.textSearchConfig(), name, p.textSearchConfig(), file.Content),
+		})
+	}
+
+	return p.write(query, values, FileIndex, "fileid", deletedIds)
+}
+
+func (p *PostgresEngine) SearchFiles(channels model.ChannelList, searchParams []*model.SearchParams, page, perPage int) ([]string, *model.AppError) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	channelIds := make([]string, 0, len(channels))
+	for _, channel := range channels {
+		channelIds = append(channelIds, channel.Id)
+	}
+
+	query := p.getQueryBuilder().
+		Select("fileid").
+		From(FileIndex).
+		Where("channelid = ANY(?)", pq.Array(channelIds)).
+		Limit(uint64(perPage)).
+		Offset(uint64(page * perPage))
+
+	conditions := sq.Or{}
+	for _, params := range searchParams {
+		conditions = append(conditions, p.filesCondition(params))
+	}
+	query = query.Where(conditions)
+
+	if terms, _ := buildTSQuery(searchParams, true); terms != "" {
+		query = query.OrderByClause("ts_rank(content, to_tsquery(?::regconfig, ?)) DESC", p.textSearchConfig(), terms)
+	}
+	query = query.OrderBy("createat DESC")
+
+	fileIds, err := p.selectIds(query)
+	if err != nil {
+		return nil, model.NewAppError("PostgresEngine.SearchFiles", "postgresengine.search_files.error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
+	return fileIds, nil
+}
+
+// filesCondition returns the condition matching the files of one search params.
+func (p *PostgresEngine) filesCondition(params *model.SearchParams) sq.And {
+	condition := buildFilters(params, "creatorid")
+
+	if len(params.Extensions) > 0 {
+		condition = append(condition, sq.Eq{"extension": params.Extensions})
+	}
+	if len(params.ExcludedExtensions) > 0 {
+		condition = append(condition, sq.NotEq{"extension": params.ExcludedExtensions})
+	}
+
+	terms, excludedTerms := buildTSQuery([]*model.SearchParams{params}, true)
+	if terms != "" {
+		condition = append(condition, sq.Expr("content @@ to_tsquery(?::regconfig, ?)", p.textSearchConfig(), terms))
+	}
+	if excludedTerms != "" {
+		condition = append(condition, sq.Expr("NOT content @@ to_tsquery(?::regconfig, ?)", p.textSearchConfig(), excludedTerms))
+	}
+
+	return condition
+}
+
+func (p *PostgresEngine) DeleteFile(fileID string) *model.AppError {
+	if _, err := p.delete(FileIndex, sq.Eq{"fileid": fileID}); err != nil {
+		return model.NewAppError("PostgresEngine.DeleteFile", "postgresengine.delete_file.error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	return nil
+}
+
+func (p *PostgresEngine) DeleteUserFiles(rctx request.CTX, userID string) *model.AppError {
+	deleted, err := p.delete(FileIndex, sq.Eq{"creatorid": userID})
+	if err != nil {
+		return model.NewAppError("PostgresEngine.DeleteUserFiles", "postgresengine.delete_user_files.error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
+	rctx.Logger().Info("Files for user deleted", mlog.String("user_id", userID), mlog.Int("deleted", deleted))
+
+	return nil
+}
+
+func (p *PostgresEngine) DeletePostFiles(rctx request.CTX, postID string) *model.AppError {
+	deleted, err := p.delete(FileIndex, sq.Eq{"postid": postID})
+	if err != nil {
+		return model.NewAppError("PostgresEngine.DeletePostFiles", "postgresengine.delete_post_files.error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
+	rctx.Logger().Info("Files for post deleted", mlog.String("post_id", postID), mlog.Int("deleted", deleted))
+
+	return nil
+}
+
+func (p *PostgresEngine) DeleteFilesBatch(rctx request.CTX, endTime, limit int64) *model.AppError {
+	// The placeholders of the subquery are replaced along with the ones of the delete query.
+	batch := sq.Select("fileid").
+		From(FileIndex).
+		Where(sq.LtOrEq{"createat": endTime}).
+		OrderBy("createat DESC").
+		Limit(uint64(limit))
+
+	deleted, err := p.delete(FileIndex, sq.Expr("fileid IN (?)", batch))
+	if err != nil {
+		return model.NewAppError("PostgresEngine.DeleteFilesBatch", "postgresengine.delete_files_batch.error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+
+	rctx.Logger().Info("Files in batch deleted", mlog.Int("endTime", endTime), mlog.Int("limit", limit), mlog.Int("deleted", deleted))
+
+	return nil
+}
+
+// write inserts or updates the given rows of an index, and deletes the given ids from it,
+// in a single transaction.
+func (p *PostgresEngine) write(insert sq.InsertBuilder, values [][]any, index, idColumn string, deletedIds []string) error {
+	ctx, cancel := p.context()
+	defer cancel()
+
+	transaction, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin the transaction")
+	}
+	defer transaction.Rollback()
+
+	for len(values) > 0 {
+		query := insert
+		for _, row := range values[:min(len(values), insertBatchSize)] {
+			query = query.Values(row...)
+		}
+		values = values[min(len(values), insertBatchSize):]
+
+		queryString, args, err := query.ToSql()
+		if err != nil {
+			return errors.Wrap(err, "failed to build the insert query")
+		}
+		if _, err := transaction.ExecContext(ctx, queryString, args...); err != nil {
+			return errors.Wrapf(err, "failed to write to the %s index", index)
+		}
+	}
+
+	if len(deletedIds) > 0 {
+		if _, err := transaction.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE %s = ANY($1)", index, idColumn), pq.Array(deletedIds)); err != nil {
+			return errors.Wrapf(err, "failed to delete from the %s index", index)
+		}
+	}
+
+	return transaction.Commit()
+}
+
+// selectIds runs a search query, returning the ids it selects.
+func (p *PostgresEngine) selectIds(query sq.SelectBuilder) ([]string, error) {
+	if !p.IsActive() {
+		return nil, errors.New("the Postgres search engine is not active")
+	}
+
+	queryString, args, err := query.ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build the search query")
+	}
+
+	ctx, cancel := p.context()
+	defer cancel()
+
+	rows, err := p.db.QueryContext(ctx, queryString, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to search")
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, errors.Wrap(err, "failed to scan the search results")
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// delete removes the rows matching the condition from the given index, returning how many
+// were deleted.
+func (p *PostgresEngine) delete(index string, condition sq.Sqlizer) (int64, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if !p.IsActive() {
+		return 0, errors.New("the Postgres search engine is not active")
+	}
+
+	queryString, args, err := p.getQueryBuilder().Delete(index).Where(condition).ToSql()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to build the delete query")
+	}
+
+	ctx, cancel := p.context()
+	defer cancel()
+
+	result, err := p.db.ExecContext(ctx, queryString, args...)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to delete from the index")
+	}
+
+	return result.RowsAffected()
+}
+
+func splitFilenameWords(name string) string {
+	result := name
+	result = strings.ReplaceAll(result, "-", " ")
+	result = strings.ReplaceAll(result, ".", " ")
+	return result
+}
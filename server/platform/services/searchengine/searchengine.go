@@ -21,10 +21,15 @@ func (seb *Broker) RegisterBleveEngine(be SearchEngineInterface) {
 	seb.BleveEngine = be
 }
 
+func (seb *Broker) RegisterPostgresEngine(pe SearchEngineInterface) {
+	seb.PostgresEngine = pe
+}
+
 type Broker struct {
 	cfg                 *model.Config
 	ElasticsearchEngine SearchEngineInterface
 	BleveEngine         SearchEngineInterface
+	PostgresEngine      SearchEngineInterface
 }
 
 func (seb *Broker) UpdateConfig(cfg *model.Config) *model.AppError {
@@ -37,6 +42,10 @@ func (seb *Broker) UpdateConfig(cfg *model.Config) *model.AppError {
 		seb.BleveEngine.UpdateConfig(cfg)
 	}
 
+	if seb.PostgresEngine != nil {
+		seb.PostgresEngine.UpdateConfig(cfg)
+	}
+
 	return nil
 }
 
@@ -45,6 +54,9 @@ func (seb *Broker) GetActiveEngines() []SearchEngineInterface {
 	if seb.ElasticsearchEngine != nil && seb.ElasticsearchEngine.IsActive() {
 		engines = append(engines, seb.ElasticsearchEngine)
 	}
+	if seb.PostgresEngine != nil && seb.PostgresEngine.IsActive() && seb.PostgresEngine.IsIndexingEnabled() {
+		engines = append(engines, seb.PostgresEngine)
+	}
 	if seb.BleveEngine != nil && seb.BleveEngine.IsActive() && seb.BleveEngine.IsIndexingEnabled() {
 		engines = append(engines, seb.BleveEngine)
 	}
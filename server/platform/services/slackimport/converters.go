@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/mattermost/mattermost/server/public/model"
 	"github.com/mattermost/mattermost/server/public/shared/mlog"
 )
 
@@ -22,6 +23,46 @@ func slackConvertTimeStamp(ts string) int64 {
 	return timeStamp * 1000 // Convert to milliseconds
 }
 
+// slackConvertEditTimeStamp returns the time a message was last edited, or 0 if it never was.
+func slackConvertEditTimeStamp(edited *slackEdited) int64 {
+	if edited == nil || edited.TimeStamp == "" {
+		return 0
+	}
+	return slackConvertTimeStamp(edited.TimeStamp)
+}
+
+// slackEmojiNames maps the names of Slack emojis to the names of the matching Mattermost emojis,
+// where they differ.
+var slackEmojiNames = map[string]string{
+	"simple_smile": "slightly_smiling_face",
+	"facepalm":     "face_palm",
+	"squirrel":     "chipmunk",
+	"shipit":       "chipmunk",
+}
+
+// slackSkinTones maps the skin tone modifiers of Slack emojis to the suffixes of the Mattermost emojis.
+var slackSkinTones = map[string]string{
+	"skin-tone-2": "light_skin_tone",
+	"skin-tone-3": "medium_light_skin_tone",
+	"skin-tone-4": "medium_skin_tone",
+	"skin-tone-5": "medium_dark_skin_tone",
+	"skin-tone-6": "dark_skin_tone",
+}
+
+// slackConvertEmojiName converts the name of a Slack emoji, such as "thumbsup::skin-tone-2", to the
+// name of the Mattermost emoji. The skin tone is dropped if Mattermost has no such variant.
+func slackConvertEmojiName(name string) string {
+	name, skinTone, _ := strings.Cut(name, "::")
+	if mattermostName, ok := slackEmojiNames[name]; ok {
+		name = mattermostName
+	}
+
+	if suffix, ok := slackSkinTones[skinTone]; ok && model.IsSystemEmojiName(name+"_"+suffix) {
+		return name + "_" + suffix
+	}
+	return name
+}
+
 func slackConvertChannelName(channelName string, channelId string) string {
 	newName := strings.Trim(channelName, "_-")
 	if len(newName) == 1 {
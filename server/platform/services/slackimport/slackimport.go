@@ -13,6 +13,7 @@ import (
 	"net/http"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -71,12 +72,28 @@ type slackPost struct {
 	File        *slackFile               `json:"file"`
 	Files       []*slackFile             `json:"files"`
 	Attachments []*model.SlackAttachment `json:"attachments"`
+	Reactions   []*slackReaction         `json:"reactions"`
+	Edited      *slackEdited             `json:"edited"`
+	PinnedTo    []string                 `json:"pinned_to"`
+}
+
+type slackReaction struct {
+	Name  string   `json:"name"`
+	Users []string `json:"users"`
+}
+
+type slackEdited struct {
+	User      string `json:"user"`
+	TimeStamp string `json:"ts"`
 }
 
 var isValidChannelNameCharacters = regexp.MustCompile(`^[a-zA-Z0-9\-_]+$`).MatchString
 
 const slackImportMaxFileSize = 1024 * 1024 * 70
 
+// slackTimestampProp is the post prop holding the Slack timestamp of the imported message.
+const slackTimestampProp = "slack_ts"
+
 type slackComment struct {
 	User    string `json:"user"`
 	Comment string `json:"comment"`
@@ -98,11 +115,40 @@ type Actions struct {
 	PrepareImage           func(fileData []byte) (image.Image, string, func(), error)
 }
 
+// Checkpoint is the state of an import, which lets an interrupted import be resumed.
+type Checkpoint struct {
+	// BotUserId is the id of the user the bot messages are imported as.
+	BotUserId string
+	// ImportedChannels are the ids of the Slack channels whose posts have all been imported.
+	ImportedChannels []string
+}
+
+// Progress is the progress of an import.
+type Progress struct {
+	Checkpoint
+	ChannelsTotal int
+	PostsImported int
+	// PostsSkipped is the number of posts skipped as they were imported by a previous run of the import.
+	PostsSkipped   int
+	FailedUsers    []string
+	FailedChannels []string
+}
+
+// Options are the options of an import.
+type Options struct {
+	// Checkpoint is the state of a previous, interrupted import of the same export to resume from.
+	Checkpoint Checkpoint
+	// OnProgress, if set, is called each time the import of a channel is complete.
+	OnProgress func(progress Progress)
+}
+
 // SlackImporter is a service that allows to import slack dumps into mattermost
 type SlackImporter struct {
-	store   store.Store
-	actions Actions
-	config  *model.Config
+	store    store.Store
+	actions  Actions
+	config   *model.Config
+	options  Options
+	progress Progress
 }
 
 // New creates a new SlackImporter service instance. It receive a store, a set of actions and the current config.
@@ -116,6 +162,16 @@ func New(store store.Store, actions Actions, config *model.Config) *SlackImporte
 }
 
 func (si *SlackImporter) SlackImport(rctx request.CTX, fileData multipart.File, fileSize int64, teamID string) (*model.AppError, *bytes.Buffer) {
+	return si.SlackImportWithOptions(rctx, fileData, fileSize, teamID, Options{})
+}
+
+// SlackImportWithOptions imports the Slack export like SlackImport, resuming from the checkpoint
+// of the options and reporting its progress. Posts already imported by a previous run of the import
+// are skipped, so an import can safely be run again.
+func (si *SlackImporter) SlackImportWithOptions(rctx request.CTX, fileData io.ReaderAt, fileSize int64, teamID string, options Options) (*model.AppError, *bytes.Buffer) {
+	si.options = options
+	si.progress = Progress{Checkpoint: options.Checkpoint}
+
 	// Create log file
 	log := bytes.NewBufferString(i18n.T("api.slackimport.slack_import.log"))
 
@@ -279,6 +335,7 @@ func (si *SlackImporter) slackAddUsers(rctx request.CTX, teamId string, slackuse
 		mUser := si.oldImportUser(rctx, team, &newUser)
 		if mUser == nil {
 			importerLog.WriteString(i18n.T("api.slackimport.slack_add_users.unable_import", map[string]any{"Username": sUser.Username}))
+			si.progress.FailedUsers = append(si.progress.FailedUsers, sUser.Username)
 			continue
 		}
 		addedUsers[sUser.Id] = mUser
@@ -295,8 +352,31 @@ func (si *SlackImporter) slackAddBotUser(rctx request.CTX, teamId string, log *b
 		return nil
 	}
 
+	// Resumed imports keep importing the bot messages as the same user, so that
+	// the messages already imported can be recognized.
+	if si.progress.BotUserId != "" {
+		if botUser, err := si.store.User().Get(rctx.Context(), si.progress.BotUserId); err == nil {
+			if botUser, appErr := si.actions.UpdateActive(botUser, true); appErr == nil {
+				return botUser
+			}
+		}
+		rctx.Logger().Warn("Slack Import: Unable to reuse the user account of the previous import for the bot.", mlog.String("user_id", si.progress.BotUserId))
+	}
+
+	// The bot user is the same for all the imports into a team, so that importing
+	// an export again doesn't duplicate the bot messages.
+	username := "slackimportuser_" + teamId
+	if botUser, err := si.store.User().GetByUsername(username); err == nil {
+		if activeUser, appErr := si.actions.UpdateActive(botUser, true); appErr != nil {
+			rctx.Logger().Warn("Slack Import: Unable to reactivate the user account of a previous import for the bot.", mlog.String("user_id", botUser.Id), mlog.Err(appErr))
+		} else {
+			botUser = activeUser
+		}
+		si.progress.BotUserId = botUser.Id
+		return botUser
+	}
+
 	password := model.NewId()
-	username := "slackimportuser_" + model.NewId()
 	email := username + "@localhost"
 
 	botUser := model.User{
@@ -314,6 +394,7 @@ func (si *SlackImporter) slackAddBotUser(rctx request.CTX, teamId string, log *b
 	}
 
 	log.WriteString(i18n.T("api.slackimport.slack_add_bot_user.email_pwd", map[string]any{"Email": botUser.Email, "Password": password}))
+	si.progress.BotUserId = mUser.Id
 	return mUser
 }
 
@@ -324,7 +405,7 @@ func (si *SlackImporter) slackAddPosts(rctx request.CTX, teamId string, channel
 	threads := make(map[string]string)
 	for _, sPost := range posts {
 		switch {
-		case sPost.Type == "message" && (sPost.SubType == "" || sPost.SubType == "file_share"):
+		case sPost.Type == "message" && (sPost.SubType == "" || sPost.SubType == "file_share" || sPost.SubType == "thread_broadcast"):
 			if sPost.User == "" {
 				rctx.Logger().Debug("Slack Import: Unable to import the message as the user field is missing.")
 				continue
@@ -338,6 +419,21 @@ func (si *SlackImporter) slackAddPosts(rctx request.CTX, teamId string, channel
 				ChannelId: channel.Id,
 				Message:   sPost.Text,
 				CreateAt:  slackConvertTimeStamp(sPost.TimeStamp),
+				Props:     model.StringInterface{slackTimestampProp: sPost.TimeStamp},
+				EditAt:    slackConvertEditTimeStamp(sPost.Edited),
+				IsPinned:  len(sPost.PinnedTo) > 0,
+			}
+			// If post in thread
+			if sPost.ThreadTS != "" && sPost.ThreadTS != sPost.TimeStamp {
+				newPost.RootId = threads[sPost.ThreadTS]
+			}
+			// Avoid uploading the files of a post imported by a previous run again.
+			if postId := si.importedPostId(&newPost); postId != "" {
+				si.progress.PostsSkipped++
+				if sPost.ThreadTS == sPost.TimeStamp {
+					threads[sPost.ThreadTS] = postId
+				}
+				continue
 			}
 			if sPost.Upload {
 				if sPost.File != nil {
@@ -352,15 +448,12 @@ func (si *SlackImporter) slackAddPosts(rctx request.CTX, teamId string, channel
 					}
 				}
 			}
-			// If post in thread
-			if sPost.ThreadTS != "" && sPost.ThreadTS != sPost.TimeStamp {
-				newPost.RootId = threads[sPost.ThreadTS]
-			}
-			postId := si.oldImportPost(rctx, &newPost)
+			postId := si.oldSavePost(rctx, &newPost)
 			// If post is thread starter
 			if sPost.ThreadTS == sPost.TimeStamp {
 				threads[sPost.ThreadTS] = postId
 			}
+			si.slackAddReactions(rctx, channel, postId, slackConvertTimeStamp(sPost.TimeStamp), sPost.Reactions, users)
 		case sPost.Type == "message" && sPost.SubType == "file_comment":
 			if sPost.Comment == nil {
 				rctx.Logger().Debug("Slack Import: Unable to import the message as it has no comments.")
@@ -379,6 +472,7 @@ func (si *SlackImporter) slackAddPosts(rctx request.CTX, teamId string, channel
 				ChannelId: channel.Id,
 				Message:   sPost.Comment.Comment,
 				CreateAt:  slackConvertTimeStamp(sPost.TimeStamp),
+				Props:     model.StringInterface{slackTimestampProp: sPost.TimeStamp},
 			}
 			si.oldImportPost(rctx, &newPost)
 		case sPost.Type == "message" && sPost.SubType == "bot_message":
@@ -401,15 +495,23 @@ func (si *SlackImporter) slackAddPosts(rctx request.CTX, teamId string, channel
 				UserId:    botUser.Id,
 				ChannelId: channel.Id,
 				CreateAt:  slackConvertTimeStamp(sPost.TimeStamp),
+				Props:     model.StringInterface{slackTimestampProp: sPost.TimeStamp},
+				EditAt:    slackConvertEditTimeStamp(sPost.Edited),
+				IsPinned:  len(sPost.PinnedTo) > 0,
 				Message:   sPost.Text,
 				Type:      model.PostTypeSlackAttachment,
 			}
+			// If post in thread
+			if sPost.ThreadTS != "" && sPost.ThreadTS != sPost.TimeStamp {
+				post.RootId = threads[sPost.ThreadTS]
+			}
 
 			postId := si.oldImportIncomingWebhookPost(rctx, post, props)
 			// If post is thread starter
 			if sPost.ThreadTS == sPost.TimeStamp {
 				threads[sPost.ThreadTS] = postId
 			}
+			si.slackAddReactions(rctx, channel, postId, slackConvertTimeStamp(sPost.TimeStamp), sPost.Reactions, users)
 		case sPost.Type == "message" && (sPost.SubType == "channel_join" || sPost.SubType == "channel_leave"):
 			if sPost.User == "" {
 				rctx.Logger().Debug("Slack Import: Unable to import the message as the user field is missing.")
@@ -434,7 +536,8 @@ func (si *SlackImporter) slackAddPosts(rctx request.CTX, teamId string, channel
 				CreateAt:  slackConvertTimeStamp(sPost.TimeStamp),
 				Type:      postType,
 				Props: model.StringInterface{
-					"username": users[sPost.User].Username,
+					"username":         users[sPost.User].Username,
+					slackTimestampProp: sPost.TimeStamp,
 				},
 			}
 			si.oldImportPost(rctx, &newPost)
@@ -452,12 +555,20 @@ func (si *SlackImporter) slackAddPosts(rctx request.CTX, teamId string, channel
 				ChannelId: channel.Id,
 				Message:   "*" + sPost.Text + "*",
 				CreateAt:  slackConvertTimeStamp(sPost.TimeStamp),
+				Props:     model.StringInterface{slackTimestampProp: sPost.TimeStamp},
+				EditAt:    slackConvertEditTimeStamp(sPost.Edited),
+				IsPinned:  len(sPost.PinnedTo) > 0,
+			}
+			// If post in thread
+			if sPost.ThreadTS != "" && sPost.ThreadTS != sPost.TimeStamp {
+				newPost.RootId = threads[sPost.ThreadTS]
 			}
 			postId := si.oldImportPost(rctx, &newPost)
 			// If post is thread starter
 			if sPost.ThreadTS == sPost.TimeStamp {
 				threads[sPost.ThreadTS] = postId
 			}
+			si.slackAddReactions(rctx, channel, postId, slackConvertTimeStamp(sPost.TimeStamp), sPost.Reactions, users)
 		case sPost.Type == "message" && sPost.SubType == "channel_topic":
 			if sPost.User == "" {
 				rctx.Logger().Debug("Slack Import: Unable to import the message as the user field is missing.")
@@ -472,6 +583,7 @@ func (si *SlackImporter) slackAddPosts(rctx request.CTX, teamId string, channel
 				ChannelId: channel.Id,
 				Message:   sPost.Text,
 				CreateAt:  slackConvertTimeStamp(sPost.TimeStamp),
+				Props:     model.StringInterface{slackTimestampProp: sPost.TimeStamp},
 				Type:      model.PostTypeHeaderChange,
 			}
 			si.oldImportPost(rctx, &newPost)
@@ -489,6 +601,7 @@ func (si *SlackImporter) slackAddPosts(rctx request.CTX, teamId string, channel
 				ChannelId: channel.Id,
 				Message:   sPost.Text,
 				CreateAt:  slackConvertTimeStamp(sPost.TimeStamp),
+				Props:     model.StringInterface{slackTimestampProp: sPost.TimeStamp},
 				Type:      model.PostTypePurposeChange,
 			}
 			si.oldImportPost(rctx, &newPost)
@@ -506,6 +619,7 @@ func (si *SlackImporter) slackAddPosts(rctx request.CTX, teamId string, channel
 				ChannelId: channel.Id,
 				Message:   sPost.Text,
 				CreateAt:  slackConvertTimeStamp(sPost.TimeStamp),
+				Props:     model.StringInterface{slackTimestampProp: sPost.TimeStamp},
 				Type:      model.PostTypeDisplaynameChange,
 			}
 			si.oldImportPost(rctx, &newPost)
@@ -519,6 +633,40 @@ func (si *SlackImporter) slackAddPosts(rctx request.CTX, teamId string, channel
 	}
 }
 
+func (si *SlackImporter) slackAddReactions(rctx request.CTX, channel *model.Channel, postId string, createAt int64, reactions []*slackReaction, users map[string]*model.User) {
+	// The post failed to import, so there is nothing to react to.
+	if postId == "" {
+		return
+	}
+
+	for _, sReaction := range reactions {
+		emojiName := slackConvertEmojiName(sReaction.Name)
+		if !model.IsSystemEmojiName(emojiName) {
+			if _, err := si.store.Emoji().GetByName(rctx, emojiName, true); err != nil {
+				rctx.Logger().Debug("Slack Import: Unable to add the reaction as the emoji does not exist in Mattermost.", mlog.String("emoji_name", sReaction.Name))
+				continue
+			}
+		}
+
+		for _, slackUserId := range sReaction.Users {
+			if users[slackUserId] == nil {
+				rctx.Logger().Debug("Slack Import: Unable to add the reaction as the Slack user does not exist in Mattermost.", mlog.String("user", slackUserId))
+				continue
+			}
+			reaction := &model.Reaction{
+				UserId:    users[slackUserId].Id,
+				PostId:    postId,
+				ChannelId: channel.Id,
+				EmojiName: emojiName,
+				CreateAt:  createAt,
+			}
+			if _, err := si.store.Reaction().Save(reaction); err != nil {
+				rctx.Logger().Warn("Slack Import: An error occurred when adding the reaction.", mlog.String("post_id", postId), mlog.String("emoji_name", emojiName), mlog.Err(err))
+			}
+		}
+	}
+}
+
 func (si *SlackImporter) slackUploadFile(rctx request.CTX, slackPostFile *slackFile, uploads map[string]*zip.File, teamId string, channelId string, userId string, slackTimestamp string) (*model.FileInfo, bool) {
 	if slackPostFile == nil {
 		rctx.Logger().Warn("Slack Import: Unable to attach the file to the post as the latter has no file section present in Slack export.")
@@ -597,7 +745,13 @@ func (si *SlackImporter) slackAddChannels(rctx request.CTX, teamId string, slack
 	importerLog.WriteString("=================\r\n\r\n")
 
 	addedChannels := make(map[string]*model.Channel)
+	si.progress.ChannelsTotal = len(slackchannels)
 	for _, sChannel := range slackchannels {
+		if slices.Contains(si.progress.ImportedChannels, sChannel.Id) {
+			rctx.Logger().Debug("Slack Import: Skipping the Slack channel as it was imported by a previous run of the import.", mlog.String("channel_id", sChannel.Id))
+			continue
+		}
+
 		newChannel := model.Channel{
 			TeamId:      teamId,
 			Type:        sChannel.Type,
@@ -631,6 +785,8 @@ func (si *SlackImporter) slackAddChannels(rctx request.CTX, teamId string, slack
 			if mChannel == nil {
 				rctx.Logger().Warn("Slack Import: Unable to import Slack channel.", mlog.String("channel_display_name", newChannel.DisplayName))
 				importerLog.WriteString(i18n.T("api.slackimport.slack_add_channels.import_failed", map[string]any{"DisplayName": newChannel.DisplayName}))
+				si.progress.FailedChannels = append(si.progress.FailedChannels, newChannel.DisplayName)
+				si.reportProgress()
 				continue
 			}
 		}
@@ -642,11 +798,25 @@ func (si *SlackImporter) slackAddChannels(rctx request.CTX, teamId string, slack
 		importerLog.WriteString(newChannel.DisplayName + "\r\n")
 		addedChannels[sChannel.Id] = mChannel
 		si.slackAddPosts(rctx, teamId, mChannel, posts[sChannel.Name], users, uploads, botUser)
+		si.progress.ImportedChannels = append(si.progress.ImportedChannels, sChannel.Id)
+		si.reportProgress()
 	}
 
 	return addedChannels
 }
 
+func (si *SlackImporter) reportProgress() {
+	if si.options.OnProgress == nil {
+		return
+	}
+
+	progress := si.progress
+	progress.ImportedChannels = slices.Clone(si.progress.ImportedChannels)
+	progress.FailedUsers = slices.Clone(si.progress.FailedUsers)
+	progress.FailedChannels = slices.Clone(si.progress.FailedChannels)
+	si.options.OnProgress(progress)
+}
+
 //
 // -- Old SlackImport Functions --
 // Import functions are suitable for entering posts and users into the database without
@@ -654,6 +824,47 @@ func (si *SlackImporter) slackAddChannels(rctx request.CTX, teamId string, slack
 //
 
 func (si *SlackImporter) oldImportPost(rctx request.CTX, post *model.Post) string {
+	if postId := si.importedPostId(post); postId != "" {
+		si.progress.PostsSkipped++
+		return postId
+	}
+
+	return si.oldSavePost(rctx, post)
+}
+
+// importedPostId returns the id of the post if it was imported by a previous run of the import,
+// or an empty string otherwise.
+func (si *SlackImporter) importedPostId(post *model.Post) string {
+	posts, err := si.store.Post().GetPostsCreatedAt(post.ChannelId, post.CreateAt)
+	if err != nil {
+		return ""
+	}
+
+	// The post creation time only keeps the second of the Slack timestamp, so identical messages
+	// sent within the same second are told apart by the original Slack timestamp.
+	slackTimestamp, _ := post.GetProp(slackTimestampProp).(string)
+
+	// Long messages are split in several posts, the first one holding the beginning of the message.
+	message := truncateRunes(post.Message, si.actions.MaxPostSize())
+	for _, existingPost := range posts {
+		if existingPost.UserId != post.UserId || existingPost.RootId != post.RootId {
+			continue
+		}
+		// Posts imported before the Slack timestamp was stored can only be matched by their message.
+		if existingTimestamp, ok := existingPost.GetProp(slackTimestampProp).(string); ok && slackTimestamp != "" {
+			if existingTimestamp == slackTimestamp {
+				return existingPost.Id
+			}
+			continue
+		}
+		if existingPost.Message == message {
+			return existingPost.Id
+		}
+	}
+	return ""
+}
+
+func (si *SlackImporter) oldSavePost(rctx request.CTX, post *model.Post) string {
 	// Workaround for empty messages, which may be the case if they are webhook posts.
 	firstIteration := true
 	firstPostId := ""
@@ -680,6 +891,9 @@ func (si *SlackImporter) oldImportPost(rctx request.CTX, post *model.Post) strin
 		}
 
 		if firstIteration {
+			if err == nil {
+				si.progress.PostsImported++
+			}
 			if firstPostId == "" {
 				firstPostId = post.Id
 			}
@@ -6,6 +6,7 @@ package slackimport
 import (
 	"archive/zip"
 	"bytes"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -13,9 +14,11 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/i18n"
 	"github.com/mattermost/mattermost/server/public/shared/request"
 	"github.com/mattermost/mattermost/server/v8/channels/store/storetest/mocks"
 )
@@ -459,3 +462,339 @@ func TestSlackUploadFile(t *testing.T) {
 		require.False(t, ok)
 	})
 }
+
+func TestSlackConvertEmojiName(t *testing.T) {
+	for _, tc := range []struct {
+		input  string
+		output string
+	}{
+		{"tada", "tada"},
+		{"simple_smile", "slightly_smiling_face"},
+		{"thumbsup::skin-tone-2", "thumbsup_light_skin_tone"},
+		{"+1::skin-tone-6", "+1_dark_skin_tone"},
+		{"tada::skin-tone-3", "tada"},
+		{"partyparrot", "partyparrot"},
+	} {
+		assert.Equal(t, tc.output, slackConvertEmojiName(tc.input), "input = %v", tc.input)
+	}
+}
+
+func TestSlackAddPosts(t *testing.T) {
+	file, err := openTestFile(t, "slack-import-test-threads.json")
+	require.NoError(t, err)
+	defer file.Close()
+
+	posts, err := slackParsePosts(file)
+	require.NoError(t, err)
+
+	rctx := request.TestContext(t)
+	config := &model.Config{}
+	config.SetDefaults()
+
+	users := map[string]*model.User{
+		"U07Q4MHCP": {Id: model.NewId(), Username: "lindy"},
+		"U15FUR1QS": {Id: model.NewId(), Username: "igor"},
+	}
+	botUser := &model.User{Id: model.NewId(), Username: "slackimportuser"}
+	channel := &model.Channel{Id: model.NewId()}
+
+	var savedPosts []*model.Post
+	var savedReactions []*model.Reaction
+
+	postStore := &mocks.PostStore{}
+	postStore.On("GetPostsCreatedAt", channel.Id, mock.AnythingOfType("int64")).Return(nil, nil)
+	postStore.On("Save", rctx, mock.AnythingOfType("*model.Post")).Return(func(_ request.CTX, post *model.Post) (*model.Post, error) {
+		post.Id = model.NewId()
+		savedPosts = append(savedPosts, post.Clone())
+		return post, nil
+	})
+	reactionStore := &mocks.ReactionStore{}
+	reactionStore.On("Save", mock.AnythingOfType("*model.Reaction")).Return(func(reaction *model.Reaction) (*model.Reaction, error) {
+		savedReactions = append(savedReactions, reaction)
+		return reaction, nil
+	})
+	emojiStore := &mocks.EmojiStore{}
+	emojiStore.On("GetByName", rctx, "partyparrot", true).Return(nil, errors.New("not found"))
+
+	store := &mocks.Store{}
+	store.On("Post").Return(postStore)
+	store.On("Reaction").Return(reactionStore)
+	store.On("Emoji").Return(emojiStore)
+
+	importer := New(store, Actions{
+		MaxPostSize: func() int { return model.PostMessageMaxRunesV2 },
+	}, config)
+	importer.slackAddPosts(rctx, "team-id", channel, posts, users, nil, botUser)
+
+	require.Len(t, savedPosts, 5)
+
+	root := savedPosts[0]
+	assert.Equal(t, "Where should we go for the monthly outing?", root.Message)
+	assert.Empty(t, root.RootId)
+	assert.True(t, root.IsPinned)
+	assert.Zero(t, root.EditAt)
+
+	unrelated := savedPosts[1]
+	assert.Equal(t, "Unrelated message", unrelated.Message)
+	assert.Empty(t, unrelated.RootId)
+	assert.False(t, unrelated.IsPinned)
+
+	reply := savedPosts[2]
+	assert.Equal(t, "Bowling!", reply.Message)
+	assert.Equal(t, root.Id, reply.RootId)
+	assert.Equal(t, int64(1472932600000), reply.EditAt)
+
+	broadcast := savedPosts[3]
+	assert.Equal(t, "Bowling it is, see you all there.", broadcast.Message)
+	assert.Equal(t, root.Id, broadcast.RootId)
+
+	botReply := savedPosts[4]
+	assert.Equal(t, "Reminder set.", botReply.Message)
+	assert.Equal(t, botUser.Id, botReply.UserId)
+	assert.Equal(t, root.Id, botReply.RootId)
+
+	require.Len(t, savedReactions, 3)
+	for _, reaction := range savedReactions {
+		assert.Equal(t, root.Id, reaction.PostId)
+		assert.Equal(t, channel.Id, reaction.ChannelId)
+		assert.Equal(t, root.CreateAt, reaction.CreateAt)
+	}
+	assert.Equal(t, users["U07Q4MHCP"].Id, savedReactions[0].UserId)
+	assert.Equal(t, "thumbsup_light_skin_tone", savedReactions[0].EmojiName)
+	assert.Equal(t, users["U15FUR1QS"].Id, savedReactions[1].UserId)
+	assert.Equal(t, "thumbsup_light_skin_tone", savedReactions[1].EmojiName)
+	assert.Equal(t, users["U15FUR1QS"].Id, savedReactions[2].UserId)
+	assert.Equal(t, "slightly_smiling_face", savedReactions[2].EmojiName)
+
+	assert.Equal(t, 5, importer.progress.PostsImported)
+	assert.Zero(t, importer.progress.PostsSkipped)
+}
+
+func TestSlackAddPostsAlreadyImported(t *testing.T) {
+	file, err := openTestFile(t, "slack-import-test-threads.json")
+	require.NoError(t, err)
+	defer file.Close()
+
+	posts, err := slackParsePosts(file)
+	require.NoError(t, err)
+
+	rctx := request.TestContext(t)
+	config := &model.Config{}
+	config.SetDefaults()
+
+	users := map[string]*model.User{
+		"U07Q4MHCP": {Id: model.NewId(), Username: "lindy"},
+		"U15FUR1QS": {Id: model.NewId(), Username: "igor"},
+	}
+	channel := &model.Channel{Id: model.NewId()}
+
+	// The thread starter and the unrelated message were imported by a previous run.
+	root := &model.Post{
+		Id:        model.NewId(),
+		UserId:    users["U07Q4MHCP"].Id,
+		ChannelId: channel.Id,
+		Message:   "Where should we go for the monthly outing?",
+		CreateAt:  1472932439000,
+	}
+	unrelated := &model.Post{
+		Id:        model.NewId(),
+		UserId:    users["U15FUR1QS"].Id,
+		ChannelId: channel.Id,
+		Message:   "Unrelated message",
+		CreateAt:  1472932450000,
+	}
+	otherUserPost := &model.Post{
+		Id:        model.NewId(),
+		UserId:    model.NewId(),
+		ChannelId: channel.Id,
+		Message:   "Bowling!",
+		CreateAt:  1472932500000,
+	}
+
+	var savedPosts []*model.Post
+
+	postStore := &mocks.PostStore{}
+	postStore.On("GetPostsCreatedAt", channel.Id, root.CreateAt).Return([]*model.Post{root}, nil)
+	postStore.On("GetPostsCreatedAt", channel.Id, unrelated.CreateAt).Return([]*model.Post{unrelated}, nil)
+	postStore.On("GetPostsCreatedAt", channel.Id, otherUserPost.CreateAt).Return([]*model.Post{otherUserPost}, nil)
+	postStore.On("GetPostsCreatedAt", channel.Id, mock.AnythingOfType("int64")).Return(nil, nil)
+	postStore.On("Save", rctx, mock.AnythingOfType("*model.Post")).Return(func(_ request.CTX, post *model.Post) (*model.Post, error) {
+		post.Id = model.NewId()
+		savedPosts = append(savedPosts, post.Clone())
+		return post, nil
+	})
+	reactionStore := &mocks.ReactionStore{}
+	reactionStore.On("Save", mock.AnythingOfType("*model.Reaction")).Return(func(reaction *model.Reaction) (*model.Reaction, error) {
+		return reaction, nil
+	})
+	emojiStore := &mocks.EmojiStore{}
+	emojiStore.On("GetByName", rctx, "partyparrot", true).Return(nil, errors.New("not found"))
+
+	store := &mocks.Store{}
+	store.On("Post").Return(postStore)
+	store.On("Reaction").Return(reactionStore)
+	store.On("Emoji").Return(emojiStore)
+
+	importer := New(store, Actions{
+		MaxPostSize: func() int { return model.PostMessageMaxRunesV2 },
+	}, config)
+	importer.slackAddPosts(rctx, "team-id", channel, posts, users, nil, &model.User{Id: model.NewId()})
+
+	require.Len(t, savedPosts, 3)
+	for _, post := range savedPosts {
+		assert.Equal(t, root.Id, post.RootId, "message = %v", post.Message)
+	}
+	assert.Equal(t, "Bowling!", savedPosts[0].Message)
+	assert.Equal(t, 3, importer.progress.PostsImported)
+	assert.Equal(t, 2, importer.progress.PostsSkipped)
+}
+
+func TestSlackImportedPostIdMatchesSlackTimestamp(t *testing.T) {
+	config := &model.Config{}
+	config.SetDefaults()
+
+	channelId := model.NewId()
+	userId := model.NewId()
+	legacy := &model.Post{
+		Id:        model.NewId(),
+		UserId:    userId,
+		ChannelId: channelId,
+		Message:   "Legacy",
+		CreateAt:  1472932439000,
+	}
+	imported := &model.Post{
+		Id:        model.NewId(),
+		UserId:    userId,
+		ChannelId: channelId,
+		Message:   "+1",
+		CreateAt:  1472932439000,
+		Props:     model.StringInterface{slackTimestampProp: "1472932439.000002"},
+	}
+
+	postStore := &mocks.PostStore{}
+	postStore.On("GetPostsCreatedAt", channelId, int64(1472932439000)).Return([]*model.Post{legacy, imported}, nil)
+	store := &mocks.Store{}
+	store.On("Post").Return(postStore)
+
+	importer := New(store, Actions{
+		MaxPostSize: func() int { return model.PostMessageMaxRunesV2 },
+	}, config)
+
+	newPost := func(message, slackTimestamp string) *model.Post {
+		return &model.Post{
+			UserId:    userId,
+			ChannelId: channelId,
+			Message:   message,
+			CreateAt:  1472932439000,
+			Props:     model.StringInterface{slackTimestampProp: slackTimestamp},
+		}
+	}
+
+	t.Run("same Slack timestamp", func(t *testing.T) {
+		assert.Equal(t, imported.Id, importer.importedPostId(newPost("+1", "1472932439.000002")))
+	})
+
+	t.Run("identical message sent in the same second", func(t *testing.T) {
+		assert.Empty(t, importer.importedPostId(newPost("+1", "1472932439.000003")))
+	})
+
+	t.Run("post imported without a Slack timestamp", func(t *testing.T) {
+		assert.Equal(t, legacy.Id, importer.importedPostId(newPost("Legacy", "1472932439.000001")))
+	})
+}
+
+func TestSlackAddReactionsWithoutPost(t *testing.T) {
+	rctx := request.TestContext(t)
+	config := &model.Config{}
+	config.SetDefaults()
+
+	reactionStore := &mocks.ReactionStore{}
+	store := &mocks.Store{}
+	store.On("Reaction").Return(reactionStore)
+
+	users := map[string]*model.User{
+		"U07Q4MHCP": {Id: model.NewId(), Username: "lindy"},
+	}
+	reactions := []*slackReaction{
+		{Name: "smile", Users: []string{"U07Q4MHCP"}},
+	}
+
+	importer := New(store, Actions{}, config)
+	importer.slackAddReactions(rctx, &model.Channel{Id: model.NewId()}, "", 1472932439000, reactions, users)
+
+	reactionStore.AssertNotCalled(t, "Save", mock.Anything)
+}
+
+func TestSlackAddChannelsProgress(t *testing.T) {
+	prevT := i18n.T
+	i18n.T = i18n.IdentityTfunc()
+	defer func() { i18n.T = prevT }()
+
+	rctx := request.TestContext(t)
+	config := &model.Config{}
+	config.SetDefaults()
+
+	existingChannel := &model.Channel{Id: model.NewId(), Name: "general"}
+
+	channelStore := &mocks.ChannelStore{}
+	channelStore.On("GetByName", "team-id", "general", true).Return(existingChannel, nil)
+	store := &mocks.Store{}
+	store.On("Channel").Return(channelStore)
+
+	var reported []Progress
+	importer := New(store, Actions{}, config)
+	importer.options = Options{
+		OnProgress: func(progress Progress) {
+			reported = append(reported, progress)
+		},
+	}
+	importer.progress = Progress{
+		Checkpoint: Checkpoint{ImportedChannels: []string{"C0G08DLQH"}},
+	}
+
+	slackChannels := []slackChannel{
+		{Id: "C0G08DLQH", Name: "random", Type: model.ChannelTypeOpen},
+		{Id: "C0G04DLQH", Name: "general", Type: model.ChannelTypeOpen},
+	}
+	importer.slackAddChannels(rctx, "team-id", slackChannels, nil, nil, nil, nil, new(bytes.Buffer))
+
+	// The channel imported by a previous run is skipped altogether.
+	channelStore.AssertNotCalled(t, "GetByName", "team-id", "random", true)
+	require.Len(t, reported, 1)
+	assert.Equal(t, 2, reported[0].ChannelsTotal)
+	assert.Equal(t, []string{"C0G08DLQH", "C0G04DLQH"}, reported[0].ImportedChannels)
+	assert.Empty(t, reported[0].FailedChannels)
+}
+
+func TestSlackAddBotUserReusesTeamBot(t *testing.T) {
+	rctx := request.TestContext(t)
+	config := &model.Config{}
+	config.SetDefaults()
+
+	team := &model.Team{Id: model.NewId()}
+	existingBot := &model.User{Id: model.NewId(), Username: "slackimportuser_" + team.Id, DeleteAt: model.GetMillis()}
+
+	teamStore := &mocks.TeamStore{}
+	teamStore.On("Get", team.Id).Return(team, nil)
+	userStore := &mocks.UserStore{}
+	userStore.On("GetByUsername", existingBot.Username).Return(existingBot, nil)
+	store := &mocks.Store{}
+	store.On("Team").Return(teamStore)
+	store.On("User").Return(userStore)
+
+	importer := New(store, Actions{
+		UpdateActive: func(user *model.User, active bool) (*model.User, *model.AppError) {
+			require.True(t, active)
+			activeUser := user.DeepCopy()
+			activeUser.DeleteAt = 0
+			return activeUser, nil
+		},
+	}, config)
+
+	botUser := importer.slackAddBotUser(rctx, team.Id, new(bytes.Buffer))
+	require.NotNil(t, botUser)
+	assert.Equal(t, existingBot.Id, botUser.Id)
+	assert.Zero(t, botUser.DeleteAt)
+	assert.Equal(t, existingBot.Id, importer.progress.BotUserId)
+	userStore.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
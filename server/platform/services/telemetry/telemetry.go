@@ -75,6 +75,7 @@ const (
 	TrackConfigGuestAccounts       = "config_guest_accounts"
 	TrackConfigImageProxy          = "config_image_proxy"
 	TrackConfigBleve               = "config_bleve"
+	TrackConfigPostgresSearch      = "config_postgres_search"
 	TrackConfigExport              = "config_export"
 	TrackConfigWrangler            = "config_wrangler"
 	TrackConfigConnectedWorkspaces = "config_connected_workspaces"
@@ -496,6 +497,7 @@ func (ts *TelemetryService) trackConfig() {
 		"enable_client_performance_debugging":                     *cfg.ServiceSettings.EnableClientPerformanceDebugging,
 		"enable_multifactor_authentication":                       *cfg.ServiceSettings.EnableMultifactorAuthentication,
 		"enforce_multifactor_authentication":                      *cfg.ServiceSettings.EnforceMultifactorAuthentication,
+		"enable_webauthn":                                         *cfg.ServiceSettings.EnableWebAuthn,
 		"enable_oauth_service_provider":                           cfg.ServiceSettings.EnableOAuthServiceProvider,
 		"connection_security":                                     *cfg.ServiceSettings.ConnectionSecurity,
 		"tls_strict_transport":                                    *cfg.ServiceSettings.TLSStrictTransport,
@@ -667,6 +669,9 @@ func (ts *TelemetryService) trackConfig() {
 		"amazon_s3_sse":                 *cfg.FileSettings.AmazonS3SSE,
 		"amazon_s3_signv2":              *cfg.FileSettings.AmazonS3SignV2,
 		"amazon_s3_trace":               *cfg.FileSettings.AmazonS3Trace,
+		"enable_encryption_at_rest":     *cfg.FileSettings.EnableEncryptionAtRest,
+		"enable_file_deduplication":     *cfg.FileSettings.EnableFileDeduplication,
+		"enable_antivirus_scan":         *cfg.FileSettings.EnableAntivirusScan,
 		"max_file_size":                 *cfg.FileSettings.MaxFileSize,
 		"max_image_resolution":          *cfg.FileSettings.MaxImageResolution,
 		"max_image_decoder_concurrency": *cfg.FileSettings.MaxImageDecoderConcurrency,
@@ -710,6 +715,8 @@ func (ts *TelemetryService) trackConfig() {
 		"max_burst":                *cfg.RateLimitSettings.MaxBurst,
 		"memory_store_size":        *cfg.RateLimitSettings.MemoryStoreSize,
 		"isdefault_vary_by_header": isDefault(cfg.RateLimitSettings.VaryByHeader, ""),
+		"store_type":               *cfg.RateLimitSettings.StoreType,
+		"policies":                 len(cfg.RateLimitSettings.Policies),
 	}
 
 	configs[TrackConfigPrivacy] = map[string]any{
@@ -826,6 +833,7 @@ func (ts *TelemetryService) trackConfig() {
 		"enable_experimental_gossip_encryption": *cfg.ClusterSettings.EnableExperimentalGossipEncryption,
 		"enable_gossip_compression":             *cfg.ClusterSettings.EnableGossipCompression,
 		"read_only_config":                      *cfg.ClusterSettings.ReadOnlyConfig,
+		"message_transport":                     *cfg.ClusterSettings.MessageTransport,
 	}
 
 	configs[TrackConfigMetrics] = map[string]any{
@@ -918,6 +926,10 @@ func (ts *TelemetryService) trackConfig() {
 		"is_default_global_relay_smtp_password": isDefault(*cfg.MessageExportSettings.GlobalRelaySettings.SMTPPassword, ""),
 		"is_default_global_relay_email_address": isDefault(*cfg.MessageExportSettings.GlobalRelaySettings.EmailAddress, ""),
 		"global_relay_smtp_server_timeout":      *cfg.MessageExportSettings.GlobalRelaySettings.SMTPServerTimeout,
+		"eml_grouping":                          *cfg.MessageExportSettings.EmlSettings.Grouping,
+		"delivery_target":                       *cfg.MessageExportSettings.DeliverySettings.Target,
+		"delivery_max_retries":                  *cfg.MessageExportSettings.DeliverySettings.MaxRetries,
+		"delivery_retry_interval_seconds":       *cfg.MessageExportSettings.DeliverySettings.RetryIntervalSeconds,
 		"download_export_results":               *cfg.MessageExportSettings.DownloadExportResults,
 	}
 
@@ -946,6 +958,14 @@ func (ts *TelemetryService) trackConfig() {
 		"enable_searching":         *cfg.BleveSettings.EnableSearching,
 		"enable_autocomplete":      *cfg.BleveSettings.EnableAutocomplete,
 		"bulk_indexing_batch_size": *cfg.BleveSettings.BatchSize,
+		"enable_cluster_sync":      *cfg.BleveSettings.EnableClusterSync,
+	}
+
+	configs[TrackConfigPostgresSearch] = map[string]any{
+		"enable_indexing":              *cfg.PostgresSearchSettings.EnableIndexing,
+		"enable_searching":             *cfg.PostgresSearchSettings.EnableSearching,
+		"isdefault_text_search_config": isDefault(*cfg.PostgresSearchSettings.TextSearchConfig, model.PostgresSearchSettingsDefaultTextSearchConfig),
+		"bulk_indexing_batch_size":     *cfg.PostgresSearchSettings.BatchSize,
 	}
 
 	configs[TrackConfigExport] = map[string]any{
@@ -0,0 +1,20 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package antivirus
+
+import (
+	"context"
+	"io"
+)
+
+// Scanner checks file content for malware.
+type Scanner interface {
+	// Scan reads the content from r and returns the name of the detected
+	// signature, or an empty string when the content is clean.
+	Scan(ctx context.Context, r io.Reader) (string, error)
+
+	// SignatureVersion returns the version of the signature database, which
+	// changes whenever the signatures are updated.
+	SignatureVersion(ctx context.Context) (string, error)
+}
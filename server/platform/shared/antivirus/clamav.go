@@ -0,0 +1,168 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package antivirus
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// clamdChunkSize is the size of the chunks streamed to clamd. It must stay
+	// below the StreamMaxLength of the daemon.
+	clamdChunkSize = 64 * 1024
+
+	clamdFoundSuffix = " FOUND"
+	clamdErrorSuffix = " ERROR"
+)
+
+// ClamAVScanner scans content with a clamd daemon reached over TCP.
+//
+// The content is sent with the INSTREAM command, so the StreamMaxLength of
+// the daemon must be at least the maximum size of the uploaded files.
+type ClamAVScanner struct {
+	address string
+	timeout time.Duration
+}
+
+var _ Scanner = (*ClamAVScanner)(nil)
+
+// NewClamAVScanner returns a scanner using the clamd daemon listening at address.
+// Each command must complete within the given timeout.
+func NewClamAVScanner(address string, timeout time.Duration) *ClamAVScanner {
+	return &ClamAVScanner{
+		address: address,
+		timeout: timeout,
+	}
+}
+
+func (s *ClamAVScanner) dial(ctx context.Context) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", s.address)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to connect to clamd at %s", s.address)
+	}
+
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return nil, errors.Wrap(err, "unable to set the clamd connection deadline")
+	}
+
+	return conn, nil
+}
+
+// command sends a null terminated command and returns the reply of clamd.
+func (s *ClamAVScanner) command(ctx context.Context, name string, body func(w io.Writer) error) (string, error) {
+	conn, err := s.dial(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Now())
+	})
+	defer stop()
+
+	w := bufio.NewWriter(conn)
+	writeErr := func() error {
+		if _, err := w.WriteString("z" + name + "\x00"); err != nil {
+			return err
+		}
+		if body != nil {
+			if err := body(w); err != nil {
+				return err
+			}
+		}
+		return w.Flush()
+	}()
+
+	// clamd replies with an error and closes the connection when it rejects the
+	// stream, in which case the reply is more useful than the write error.
+	reply, readErr := bufio.NewReader(conn).ReadString('\x00')
+	reply = strings.TrimRight(reply, "\x00\n")
+	if reply == "" {
+		if writeErr != nil {
+			return "", errors.Wrapf(writeErr, "unable to send the %s command to clamd", name)
+		}
+		if readErr != nil {
+			return "", errors.Wrapf(readErr, "unable to read the reply of clamd to %s", name)
+		}
+	}
+
+	if strings.HasSuffix(reply, clamdErrorSuffix) {
+		return "", errors.Errorf("clamd failed to process %s: %s", name, strings.TrimSuffix(reply, clamdErrorSuffix))
+	}
+
+	return reply, nil
+}
+
+func (s *ClamAVScanner) Scan(ctx context.Context, r io.Reader) (string, error) {
+	reply, err := s.command(ctx, "INSTREAM", func(w io.Writer) error {
+		buf := make([]byte, clamdChunkSize)
+		var size [4]byte
+		for {
+			n, err := io.ReadFull(r, buf)
+			if n > 0 {
+				binary.BigEndian.PutUint32(size[:], uint32(n))
+				if _, werr := w.Write(size[:]); werr != nil {
+					return werr
+				}
+				if _, werr := w.Write(buf[:n]); werr != nil {
+					return werr
+				}
+			}
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				break
+			}
+			if err != nil {
+				return errors.Wrap(err, "unable to read the content to scan")
+			}
+		}
+
+		// A chunk of length zero terminates the stream.
+		binary.BigEndian.PutUint32(size[:], 0)
+		_, err := w.Write(size[:])
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	result := strings.TrimPrefix(reply, "stream: ")
+	if result == "OK" {
+		return "", nil
+	}
+	if strings.HasSuffix(result, clamdFoundSuffix) {
+		return strings.TrimSuffix(result, clamdFoundSuffix), nil
+	}
+
+	return "", errors.Errorf("unexpected reply from clamd: %s", reply)
+}
+
+// SignatureVersion returns the version of the signature database loaded by
+// clamd, taken from a reply such as "ClamAV 1.2.1/27100/Tue Nov 21 09:37:44 2023".
+func (s *ClamAVScanner) SignatureVersion(ctx context.Context) (string, error) {
+	reply, err := s.command(ctx, "VERSION", nil)
+	if err != nil {
+		return "", err
+	}
+
+	parts := strings.Split(reply, "/")
+	if len(parts) < 2 {
+		return reply, nil
+	}
+	return parts[1], nil
+}
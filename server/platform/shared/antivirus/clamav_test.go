@@ -0,0 +1,126 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package antivirus
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const eicar = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// fakeClamd implements the subset of the clamd protocol used by the scanner,
+// flagging the streams containing the EICAR test string.
+func fakeClamd(t *testing.T, maxStreamLength int) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func(conn net.Conn) {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+
+				command, err := r.ReadString('\x00')
+				if err != nil {
+					return
+				}
+
+				switch command {
+				case "zVERSION\x00":
+					conn.Write([]byte("ClamAV 1.2.1/27100/Tue Nov 21 09:37:44 2023\x00"))
+				case "zINSTREAM\x00":
+					var content bytes.Buffer
+					for {
+						var size uint32
+						if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+							return
+						}
+						if size == 0 {
+							break
+						}
+						if content.Len()+int(size) > maxStreamLength {
+							conn.Write([]byte("INSTREAM size limit exceeded. ERROR\x00"))
+							return
+						}
+						if _, err := io.CopyN(&content, r, int64(size)); err != nil {
+							return
+						}
+					}
+
+					if strings.Contains(content.String(), eicar) {
+						conn.Write([]byte("stream: Eicar-Signature FOUND\x00"))
+					} else {
+						conn.Write([]byte("stream: OK\x00"))
+					}
+				default:
+					conn.Write([]byte("UNKNOWN COMMAND\x00"))
+				}
+			}(conn)
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func TestClamAVScanner(t *testing.T) {
+	scanner := NewClamAVScanner(fakeClamd(t, 1024*1024), 5*time.Second)
+
+	t.Run("clean content", func(t *testing.T) {
+		signature, err := scanner.Scan(context.Background(), strings.NewReader("hello world"))
+		require.NoError(t, err)
+		assert.Empty(t, signature)
+	})
+
+	t.Run("empty content", func(t *testing.T) {
+		signature, err := scanner.Scan(context.Background(), strings.NewReader(""))
+		require.NoError(t, err)
+		assert.Empty(t, signature)
+	})
+
+	t.Run("infected content spanning several chunks", func(t *testing.T) {
+		content := strings.Repeat("a", clamdChunkSize-10) + eicar
+		signature, err := scanner.Scan(context.Background(), strings.NewReader(content))
+		require.NoError(t, err)
+		assert.Equal(t, "Eicar-Signature", signature)
+	})
+
+	t.Run("content over the stream limit", func(t *testing.T) {
+		_, err := scanner.Scan(context.Background(), bytes.NewReader(make([]byte, 2*1024*1024)))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "size limit exceeded")
+	})
+
+	t.Run("signature version", func(t *testing.T) {
+		version, err := scanner.SignatureVersion(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "27100", version)
+	})
+
+	t.Run("unreachable daemon", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		address := listener.Addr().String()
+		listener.Close()
+
+		_, err = NewClamAVScanner(address, time.Second).Scan(context.Background(), strings.NewReader("hello"))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,616 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package filestore
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blockblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/sas"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+const (
+	// azureCopyPollInterval is how often the status of a pending server-side copy is checked.
+	azureCopyPollInterval = 100 * time.Millisecond
+
+	// azureBlockIDLength is the length of the block ids used by the azblob SDK, which
+	// must be the same for every block of a blob.
+	azureBlockIDLength = 64
+)
+
+// AzureFileBackend contains all necessary information to communicate with
+// an Azure Blob Storage container.
+type AzureFileBackend struct {
+	accountName    string
+	containerName  string
+	pathPrefix     string
+	endpoint       string
+	credential     *azblob.SharedKeyCredential
+	container      *container.Client
+	timeout        time.Duration
+	presignExpires time.Duration
+}
+
+// AzureFileBackendNoContainerError is returned when testing a connection and no container is found
+type AzureFileBackendNoContainerError struct{}
+
+var _ FileBackendWithLinkGenerator = (*AzureFileBackend)(nil)
+
+func (s *AzureFileBackendNoContainerError) Error() string {
+	return "no such container"
+}
+
+// NewAzureFileBackend returns an instance of an AzureFileBackend authenticated
+// with the storage account shared key. When no endpoint is given, the public
+// Azure endpoint of the account is used; Azurite is reached through an endpoint
+// such as http://localhost:10000/devstoreaccount1.
+func NewAzureFileBackend(settings FileBackendSettings) (*AzureFileBackend, error) {
+	if settings.AzureStorageAccountName == "" || settings.AzureStorageContainer == "" {
+		return nil, errors.New("missing azure storage account or container settings")
+	}
+
+	backend := &AzureFileBackend{
+		accountName:    settings.AzureStorageAccountName,
+		containerName:  settings.AzureStorageContainer,
+		pathPrefix:     settings.AzureStoragePathPrefix,
+		endpoint:       settings.AzureStorageEndpoint,
+		timeout:        time.Duration(settings.AzureStorageRequestTimeoutMilliseconds) * time.Millisecond,
+		presignExpires: time.Duration(settings.AzureStoragePresignExpiresSeconds) * time.Second,
+	}
+	if backend.endpoint == "" {
+		backend.endpoint = fmt.Sprintf("https://%s.blob.core.windows.net/", backend.accountName)
+	}
+
+	credential, err := azblob.NewSharedKeyCredential(settings.AzureStorageAccountName, settings.AzureStorageAccountKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid azure storage credentials")
+	}
+	backend.credential = credential
+
+	client, err := azblob.NewClientWithSharedKeyCredential(backend.endpoint, credential, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create the azure storage client")
+	}
+	backend.container = client.ServiceClient().NewContainerClient(backend.containerName)
+
+	return backend, nil
+}
+
+func (b *AzureFileBackend) DriverName() string {
+	return driverAzure
+}
+
+func (b *AzureFileBackend) TestConnection() error {
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+
+	if _, err := b.container.GetProperties(ctx, nil); err != nil {
+		if bloberror.HasCode(err, bloberror.ContainerNotFound) {
+			return &AzureFileBackendNoContainerError{}
+		}
+		return errors.Wrap(err, "unable to check if the azure storage container exists")
+	}
+
+	mlog.Debug("Connection to Azure Blob Storage is good. Container exists.")
+	return nil
+}
+
+// MakeContainer creates the container.
+func (b *AzureFileBackend) MakeContainer() error {
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+
+	if _, err := b.container.Create(ctx, nil); err != nil {
+		return errors.Wrap(err, "unable to create the azure storage container")
+	}
+	return nil
+}
+
+func (b *AzureFileBackend) prefixedPath(s string) string {
+	return filepath.Join(b.pathPrefix, s)
+}
+
+func (b *AzureFileBackend) blob(path string) *blockblob.Client {
+	return b.container.NewBlockBlobClient(path)
+}
+
+// Caller must close the first return value
+func (b *AzureFileBackend) Reader(path string) (ReadCloseSeeker, error) {
+	path = b.prefixedPath(path)
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+	props, err := b.blob(path).GetProperties(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to open file %s", path)
+	}
+
+	var size int64
+	if props.ContentLength != nil {
+		size = *props.ContentLength
+	}
+
+	return newRangeReader(size, b.timeout, func(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+		// A zero count reads up to the end of the blob.
+		resp, err := b.blob(path).DownloadStream(ctx, &blob.DownloadStreamOptions{
+			Range: blob.HTTPRange{Offset: offset, Count: max(length, 0)},
+			AccessConditions: &blob.AccessConditions{
+				ModifiedAccessConditions: &blob.ModifiedAccessConditions{IfMatch: props.ETag},
+			},
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to open file %s", path)
+		}
+		return resp.Body, nil
+	}), nil
+}
+
+func (b *AzureFileBackend) ReadFile(path string) ([]byte, error) {
+	path = b.prefixedPath(path)
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+	resp, err := b.blob(path).DownloadStream(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to open file %s", path)
+	}
+	defer resp.Body.Close()
+
+	f, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read file %s", path)
+	}
+	return f, nil
+}
+
+func (b *AzureFileBackend) FileExists(path string) (bool, error) {
+	path = b.prefixedPath(path)
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+	_, err := b.blob(path).GetProperties(ctx, nil)
+	if err == nil {
+		return true, nil
+	}
+
+	if bloberror.HasCode(err, bloberror.BlobNotFound) {
+		return false, nil
+	}
+
+	return false, errors.Wrapf(err, "unable to know if file %s exists", path)
+}
+
+func (b *AzureFileBackend) FileSize(path string) (int64, error) {
+	path = b.prefixedPath(path)
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+	props, err := b.blob(path).GetProperties(ctx, nil)
+	if err != nil {
+		return 0, errors.Wrapf(err, "unable to get file size for %s", path)
+	}
+
+	if props.ContentLength == nil {
+		return 0, nil
+	}
+	return *props.ContentLength, nil
+}
+
+func (b *AzureFileBackend) FileModTime(path string) (time.Time, error) {
+	path = b.prefixedPath(path)
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+	props, err := b.blob(path).GetProperties(ctx, nil)
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "unable to get modification time for file %s", path)
+	}
+
+	if props.LastModified == nil {
+		return time.Time{}, nil
+	}
+	return *props.LastModified, nil
+}
+
+func (b *AzureFileBackend) CopyFile(oldPath, newPath string) error {
+	oldPath = b.prefixedPath(oldPath)
+	newPath = b.prefixedPath(newPath)
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+
+	// Copies within the same storage account are authorized by the shared key of the
+	// destination request, so the source URL does not need a SAS token.
+	resp, err := b.blob(newPath).StartCopyFromURL(ctx, b.blob(oldPath).URL(), nil)
+	if err != nil {
+		return errors.Wrapf(err, "unable to copy file from %s to %s", oldPath, newPath)
+	}
+
+	status := resp.CopyStatus
+	for status != nil && *status == blob.CopyStatusTypePending {
+		select {
+		case <-ctx.Done():
+			return errors.Wrapf(ctx.Err(), "unable to copy file from %s to %s", oldPath, newPath)
+		case <-time.After(azureCopyPollInterval):
+		}
+
+		props, err := b.blob(newPath).GetProperties(ctx, nil)
+		if err != nil {
+			return errors.Wrapf(err, "unable to copy file from %s to %s", oldPath, newPath)
+		}
+		status = props.CopyStatus
+	}
+
+	if status != nil && *status != blob.CopyStatusTypeSuccess {
+		return errors.Errorf("unable to copy file from %s to %s: copy status %s", oldPath, newPath, *status)
+	}
+
+	return nil
+}
+
+func (b *AzureFileBackend) MoveFile(oldPath, newPath string) error {
+	if err := b.CopyFile(oldPath, newPath); err != nil {
+		return err
+	}
+
+	if err := b.RemoveFile(oldPath); err != nil {
+		return errors.Wrapf(err, "unable to remove the file old file %s", oldPath)
+	}
+
+	return nil
+}
+
+func (b *AzureFileBackend) WriteFile(fr io.Reader, path string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+
+	return b.WriteFileContext(ctx, fr, path)
+}
+
+func (b *AzureFileBackend) WriteFileContext(ctx context.Context, fr io.Reader, path string) (int64, error) {
+	path = b.prefixedPath(path)
+
+	cr := &countingReader{r: fr}
+	contentType := contentTypeForPath(path)
+	_, err := b.blob(path).UploadStream(ctx, cr, &blockblob.UploadStreamOptions{
+		HTTPHeaders: &blob.HTTPHeaders{BlobContentType: &contentType},
+	})
+	if err != nil {
+		return cr.n, errors.Wrapf(err, "unable write the data in the file %s", path)
+	}
+
+	return cr.n, nil
+}
+
+// AppendFile stages the data as a new block of the blob and commits it after
+// the blocks already committed, so the existing content is not transferred again.
+func (b *AzureFileBackend) AppendFile(fr io.Reader, path string) (int64, error) {
+	fp := b.prefixedPath(path)
+	client := b.blob(fp)
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+
+	props, err := client.GetProperties(ctx, nil)
+	if err != nil {
+		return 0, errors.Wrapf(err, "unable to find the file %s to append the data", path)
+	}
+
+	list, err := client.GetBlockList(ctx, blockblob.BlockListTypeCommitted, nil)
+	if err != nil {
+		return 0, errors.Wrapf(err, "unable append the data in the file %s", path)
+	}
+
+	var blockIDs []string
+	for _, block := range list.CommittedBlocks {
+		blockIDs = append(blockIDs, *block.Name)
+	}
+
+	// Blobs uploaded in a single request have no blocks, in which case the
+	// current content is staged as the first block.
+	if len(blockIDs) == 0 && props.ContentLength != nil && *props.ContentLength > 0 {
+		resp, err := client.DownloadStream(ctx, nil)
+		if err != nil {
+			return 0, errors.Wrapf(err, "unable append the data in the file %s", path)
+		}
+		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return 0, errors.Wrapf(err, "unable append the data in the file %s", path)
+		}
+
+		blockID, err := b.stageBlock(ctx, client, data, azureBlockIDLength)
+		if err != nil {
+			return 0, errors.Wrapf(err, "unable append the data in the file %s", path)
+		}
+		blockIDs = append(blockIDs, blockID)
+	}
+
+	data, err := io.ReadAll(fr)
+	if err != nil {
+		return 0, errors.Wrapf(err, "unable to read the data to append to the file %s", path)
+	}
+
+	idLength := azureBlockIDLength
+	if len(blockIDs) > 0 {
+		if decoded, err := base64.StdEncoding.DecodeString(blockIDs[0]); err == nil {
+			idLength = len(decoded)
+		}
+	}
+
+	blockID, err := b.stageBlock(ctx, client, data, idLength)
+	if err != nil {
+		return 0, errors.Wrapf(err, "unable append the data in the file %s", path)
+	}
+	blockIDs = append(blockIDs, blockID)
+
+	if _, err := client.CommitBlockList(ctx, blockIDs, &blockblob.CommitBlockListOptions{
+		HTTPHeaders: &blob.HTTPHeaders{BlobContentType: props.ContentType},
+		AccessConditions: &blob.AccessConditions{
+			ModifiedAccessConditions: &blob.ModifiedAccessConditions{IfMatch: props.ETag},
+		},
+	}); err != nil {
+		return 0, errors.Wrapf(err, "unable append the data in the file %s", path)
+	}
+
+	return int64(len(data)), nil
+}
+
+func (b *AzureFileBackend) stageBlock(ctx context.Context, client *blockblob.Client, data []byte, idLength int) (string, error) {
+	id := make([]byte, idLength)
+	copy(id, model.NewId())
+	blockID := base64.StdEncoding.EncodeToString(id)
+
+	if _, err := client.StageBlock(ctx, blockID, readSeekNopCloser{bytes.NewReader(data)}, nil); err != nil {
+		return "", err
+	}
+	return blockID, nil
+}
+
+func (b *AzureFileBackend) RemoveFile(path string) error {
+	path = b.prefixedPath(path)
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+	if _, err := b.blob(path).Delete(ctx, nil); err != nil && !bloberror.HasCode(err, bloberror.BlobNotFound) {
+		return errors.Wrapf(err, "unable to remove the file %s", path)
+	}
+
+	return nil
+}
+
+func (b *AzureFileBackend) listDirectory(path string, recursion bool) ([]string, error) {
+	path = b.prefixedPath(path)
+	if !strings.HasSuffix(path, "/") && path != "" {
+		// Appending "/" to make it consistent across all filestores
+		path = path + "/"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+
+	var names []string
+	if recursion {
+		pager := b.container.NewListBlobsFlatPager(&container.ListBlobsFlatOptions{Prefix: &path})
+		for pager.More() {
+			page, err := pager.NextPage(ctx)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unable to list the directory %s", path)
+			}
+			for _, item := range page.Segment.BlobItems {
+				names = append(names, *item.Name)
+			}
+		}
+	} else {
+		pager := b.container.NewListBlobsHierarchyPager("/", &container.ListBlobsHierarchyOptions{Prefix: &path})
+		for pager.More() {
+			page, err := pager.NextPage(ctx)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unable to list the directory %s", path)
+			}
+			for _, prefix := range page.Segment.BlobPrefixes {
+				names = append(names, *prefix.Name)
+			}
+			for _, item := range page.Segment.BlobItems {
+				names = append(names, *item.Name)
+			}
+		}
+	}
+
+	var paths []string
+	for _, name := range names {
+		// We strip the path prefix that gets applied,
+		// so that it remains transparent to the application.
+		trimmed := strings.Trim(strings.TrimPrefix(name, b.pathPrefix), "/")
+		if trimmed != "" {
+			paths = append(paths, trimmed)
+		}
+	}
+
+	return paths, nil
+}
+
+func (b *AzureFileBackend) ListDirectory(path string) ([]string, error) {
+	return b.listDirectory(path, false)
+}
+
+func (b *AzureFileBackend) ListDirectoryRecursively(path string) ([]string, error) {
+	return b.listDirectory(path, true)
+}
+
+func (b *AzureFileBackend) RemoveDirectory(path string) error {
+	path = b.prefixedPath(path)
+	if !strings.HasSuffix(path, "/") && path != "" {
+		path = path + "/"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+
+	pager := b.container.NewListBlobsFlatPager(&container.ListBlobsFlatOptions{Prefix: &path})
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return errors.Wrapf(err, "unable to remove the directory %s", path)
+		}
+
+		for _, item := range page.Segment.BlobItems {
+			if _, err := b.blob(*item.Name).Delete(ctx, nil); err != nil && !bloberror.HasCode(err, bloberror.BlobNotFound) {
+				return errors.Wrapf(err, "unable to remove the directory %s", path)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ZipReader will create a zip of path. If path is a single file, it will zip the single file.
+// If deflate is true, the contents will be compressed. It will stream the zip to io.ReadCloser.
+func (b *AzureFileBackend) ZipReader(path string, deflate bool) (io.ReadCloser, error) {
+	deflateMethod := zip.Store
+	if deflate {
+		deflateMethod = zip.Deflate
+	}
+
+	path = b.prefixedPath(path)
+
+	pr, pw := io.Pipe()
+
+	go func() {
+		defer pw.Close()
+
+		zipWriter := zip.NewWriter(pw)
+		defer zipWriter.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+		defer cancel()
+
+		// Is path a single file?
+		if props, err := b.blob(path).GetProperties(ctx, nil); err == nil {
+			// We want the zipped file to be at the root of the zip.
+			stripPath := filepath.Dir(path)
+			if stripPath != "" {
+				stripPath += "/"
+			}
+			if err := b.copyBlobToZipWriter(zipWriter, path, props.LastModified, stripPath, deflateMethod); err != nil {
+				pw.CloseWithError(err)
+			}
+			return
+		}
+
+		// Is path a directory?
+		path = path + "/"
+		pager := b.container.NewListBlobsFlatPager(&container.ListBlobsFlatOptions{Prefix: &path})
+		for pager.More() {
+			page, err := pager.NextPage(ctx)
+			if err != nil {
+				pw.CloseWithError(errors.Wrapf(err, "unable to list the directory %s", path))
+				return
+			}
+
+			for _, item := range page.Segment.BlobItems {
+				var modified *time.Time
+				if item.Properties != nil {
+					modified = item.Properties.LastModified
+				}
+				if err := b.copyBlobToZipWriter(zipWriter, *item.Name, modified, path, deflateMethod); err != nil {
+					pw.CloseWithError(err)
+					return
+				}
+			}
+		}
+	}()
+
+	return pr, nil
+}
+
+func (b *AzureFileBackend) copyBlobToZipWriter(zipWriter *zip.Writer, name string, modified *time.Time, stripPath string, deflateMethod uint16) error {
+	header := &zip.FileHeader{
+		Name:   strings.TrimPrefix(name, stripPath),
+		Method: deflateMethod,
+	}
+	if modified != nil {
+		header.Modified = *modified
+	}
+	header.SetMode(0644) // rw-r--r-- permissions
+
+	writer, err := zipWriter.CreateHeader(header)
+	if err != nil {
+		return errors.Wrapf(err, "unable to create zip entry for %s", name)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+	resp, err := b.blob(name).DownloadStream(ctx, nil)
+	if err != nil {
+		return errors.Wrapf(err, "unable to create reader for %s", name)
+	}
+	defer resp.Body.Close()
+
+	if _, err := io.Copy(writer, resp.Body); err != nil {
+		return errors.Wrapf(err, "unable to copy content for %s", name)
+	}
+
+	return nil
+}
+
+func (b *AzureFileBackend) GeneratePublicLink(path string) (string, time.Duration, error) {
+	path = b.prefixedPath(path)
+
+	values := sas.BlobSignatureValues{
+		Protocol:           sas.ProtocolHTTPSandHTTP,
+		ExpiryTime:         time.Now().UTC().Add(b.presignExpires),
+		Permissions:        (&sas.BlobPermissions{Read: true}).String(),
+		ContainerName:      b.containerName,
+		BlobName:           path,
+		ContentDisposition: "attachment",
+	}
+	if strings.HasPrefix(b.endpoint, "https://") {
+		values.Protocol = sas.ProtocolHTTPS
+	}
+
+	query, err := values.SignWithSharedKey(b.credential)
+	if err != nil {
+		return "", 0, errors.Wrapf(err, "unable to generate public link for %s", path)
+	}
+
+	return b.blob(path).URL() + "?" + query.Encode(), b.presignExpires, nil
+}
+
+// countingReader counts the bytes read through it, as the upload responses
+// of Azure do not include the size of the written blob.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+type readSeekNopCloser struct {
+	io.ReadSeeker
+}
+
+func (readSeekNopCloser) Close() error {
+	return nil
+}
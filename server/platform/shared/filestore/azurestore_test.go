@@ -0,0 +1,54 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package filestore
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAzureFileBackend(t *testing.T) {
+	_, err := NewAzureFileBackend(FileBackendSettings{DriverName: driverAzure, AzureStorageAccountName: "devstoreaccount1"})
+	require.Error(t, err)
+	require.Equal(t, "missing azure storage account or container settings", err.Error())
+
+	backend, err := NewAzureFileBackend(FileBackendSettings{
+		DriverName:              driverAzure,
+		AzureStorageAccountName: "mattermost",
+		AzureStorageAccountKey:  azuriteAccountKey,
+		AzureStorageContainer:   "mattermost-test",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "https://mattermost.blob.core.windows.net/", backend.endpoint)
+}
+
+func TestAzureGeneratePublicLink(t *testing.T) {
+	backend, err := NewAzureFileBackend(FileBackendSettings{
+		DriverName:                             driverAzure,
+		AzureStorageAccountName:                "devstoreaccount1",
+		AzureStorageAccountKey:                 azuriteAccountKey,
+		AzureStorageContainer:                  "mattermost-test",
+		AzureStoragePathPrefix:                 "prefix",
+		AzureStorageEndpoint:                   "http://localhost:10000/devstoreaccount1/",
+		AzureStoragePresignExpiresSeconds:      60,
+		AzureStorageRequestTimeoutMilliseconds: 5000,
+	})
+	require.NoError(t, err)
+
+	link, expires, err := backend.GeneratePublicLink("exports/export.zip")
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, expires)
+
+	u, err := url.Parse(link)
+	require.NoError(t, err)
+	assert.Equal(t, "/devstoreaccount1/mattermost-test/prefix/exports/export.zip", u.Path)
+	assert.Equal(t, "attachment", u.Query().Get("rscd"))
+	assert.Equal(t, "r", u.Query().Get("sp"))
+	assert.Equal(t, "https,http", u.Query().Get("spr"))
+	assert.NotEmpty(t, u.Query().Get("sig"))
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package filestore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"path"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+// BlobsDirectory is the directory holding the content addressed blobs of
+// deduplicated files.
+const BlobsDirectory = "blobs"
+
+// BlobPath returns the path of the blob storing the content with the given hash.
+// Blobs are spread over two levels of directories to keep their size reasonable.
+func BlobPath(hash string) string {
+	if len(hash) < 4 {
+		return path.Join(BlobsDirectory, hash)
+	}
+	return path.Join(BlobsDirectory, hash[:2], hash[2:4], hash)
+}
+
+// HashContent returns the hex encoded SHA-256 of the content read from r.
+func HashContent(r io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// HashFile returns the hex encoded SHA-256 of the file stored at path.
+func HashFile(backend FileBackend, path string) (string, error) {
+	r, err := backend.Reader(path)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	hash, err := HashContent(r)
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to hash file %s", path)
+	}
+	return hash, nil
+}
+
+// StoreBlob moves the file at path to the blob of the given content hash and
+// returns the blob path. When an identical blob is already stored, the file is
+// kept and reported as a duplicate, to be passed to CommitBlob once a reference
+// to the blob has been saved.
+func StoreBlob(backend FileBackend, path, hash string) (string, bool, error) {
+	blobPath := BlobPath(hash)
+
+	exists, err := backend.FileExists(blobPath)
+	if err != nil {
+		return "", false, err
+	}
+	if exists {
+		return blobPath, true, nil
+	}
+
+	if err := backend.MoveFile(path, blobPath); err != nil {
+		return "", false, err
+	}
+	return blobPath, false, nil
+}
+
+// CommitBlob removes the duplicate stored at path of the blob with the given
+// content hash, once a reference to the blob has been saved. Should the blob
+// have been removed along with its last previous reference in the meantime,
+// the duplicate is moved to the blob instead.
+func CommitBlob(backend FileBackend, path, hash string) error {
+	blobPath := BlobPath(hash)
+
+	exists, err := backend.FileExists(blobPath)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return backend.RemoveFile(path)
+	}
+	return backend.MoveFile(path, blobPath)
+}
+
+// RemoveBlob removes the blob with the given content hash unless isReferenced
+// reports that it is still referenced. The blob is moved aside before the
+// references are checked again, so that a reference saved concurrently either
+// is seen and the blob restored, or finds the blob missing in CommitBlob and
+// stores its duplicate in its place.
+func RemoveBlob(backend FileBackend, hash string, isReferenced func() (bool, error)) error {
+	referenced, err := isReferenced()
+	if err != nil || referenced {
+		return err
+	}
+
+	blobPath := BlobPath(hash)
+	removedPath := blobPath + "." + model.NewId() + ".removed"
+	if err := backend.MoveFile(blobPath, removedPath); err != nil {
+		// The blob may have been removed by a concurrent call.
+		if exists, existsErr := backend.FileExists(blobPath); existsErr == nil && !exists {
+			return nil
+		}
+		return err
+	}
+
+	referenced, err = isReferenced()
+	if err != nil || referenced {
+		if restoreErr := backend.MoveFile(removedPath, blobPath); restoreErr != nil {
+			return restoreErr
+		}
+		return err
+	}
+	return backend.RemoveFile(removedPath)
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package filestore
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlobPath(t *testing.T) {
+	assert.Equal(t, "blobs/ab/cd/abcdef", BlobPath("abcdef"))
+	assert.Equal(t, "blobs/ab", BlobPath("ab"))
+}
+
+func TestHashContent(t *testing.T) {
+	hash, err := HashContent(strings.NewReader("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", hash)
+}
+
+func TestStoreBlob(t *testing.T) {
+	backend := &LocalFileBackend{directory: t.TempDir()}
+	data := []byte("some content")
+
+	hash, err := HashContent(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	_, err = backend.WriteFile(bytes.NewReader(data), "first/file.txt")
+	require.NoError(t, err)
+
+	fileHash, err := HashFile(backend, "first/file.txt")
+	require.NoError(t, err)
+	assert.Equal(t, hash, fileHash)
+
+	blobPath, duplicate, err := StoreBlob(backend, "first/file.txt", hash)
+	require.NoError(t, err)
+	assert.Equal(t, BlobPath(hash), blobPath)
+	assert.False(t, duplicate)
+
+	exists, err := backend.FileExists("first/file.txt")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	stored, err := backend.ReadFile(blobPath)
+	require.NoError(t, err)
+	assert.Equal(t, data, stored)
+
+	t.Run("identical content is only stored once", func(t *testing.T) {
+		_, err = backend.WriteFile(bytes.NewReader(data), "second/file.txt")
+		require.NoError(t, err)
+
+		secondPath, duplicate, err := StoreBlob(backend, "second/file.txt", hash)
+		require.NoError(t, err)
+		assert.Equal(t, blobPath, secondPath)
+		assert.True(t, duplicate)
+
+		require.NoError(t, CommitBlob(backend, "second/file.txt", hash))
+
+		exists, err := backend.FileExists("second/file.txt")
+		require.NoError(t, err)
+		assert.False(t, exists)
+
+		stored, err := backend.ReadFile(blobPath)
+		require.NoError(t, err)
+		assert.Equal(t, data, stored)
+	})
+
+	t.Run("the duplicate replaces a blob removed meanwhile", func(t *testing.T) {
+		_, err = backend.WriteFile(bytes.NewReader(data), "third/file.txt")
+		require.NoError(t, err)
+
+		_, duplicate, err := StoreBlob(backend, "third/file.txt", hash)
+		require.NoError(t, err)
+		require.True(t, duplicate)
+
+		require.NoError(t, RemoveBlob(backend, hash, func() (bool, error) { return false, nil }))
+		exists, err := backend.FileExists(blobPath)
+		require.NoError(t, err)
+		require.False(t, exists)
+
+		require.NoError(t, CommitBlob(backend, "third/file.txt", hash))
+
+		stored, err := backend.ReadFile(blobPath)
+		require.NoError(t, err)
+		assert.Equal(t, data, stored)
+	})
+}
+
+func TestRemoveBlob(t *testing.T) {
+	backend := &LocalFileBackend{directory: t.TempDir()}
+	data := []byte("some content")
+
+	hash, err := HashContent(bytes.NewReader(data))
+	require.NoError(t, err)
+	blobPath := BlobPath(hash)
+
+	_, err = backend.WriteFile(bytes.NewReader(data), blobPath)
+	require.NoError(t, err)
+
+	t.Run("referenced blob is kept", func(t *testing.T) {
+		require.NoError(t, RemoveBlob(backend, hash, func() (bool, error) { return true, nil }))
+
+		exists, err := backend.FileExists(blobPath)
+		require.NoError(t, err)
+		assert.True(t, exists)
+	})
+
+	t.Run("blob referenced while being removed is restored", func(t *testing.T) {
+		calls := 0
+		require.NoError(t, RemoveBlob(backend, hash, func() (bool, error) {
+			calls++
+			return calls > 1, nil
+		}))
+		assert.Equal(t, 2, calls)
+
+		stored, err := backend.ReadFile(blobPath)
+		require.NoError(t, err)
+		assert.Equal(t, data, stored)
+	})
+
+	t.Run("unreferenced blob is removed", func(t *testing.T) {
+		require.NoError(t, RemoveBlob(backend, hash, func() (bool, error) { return false, nil }))
+
+		exists, err := backend.FileExists(blobPath)
+		require.NoError(t, err)
+		assert.False(t, exists)
+
+		files, err := backend.ListDirectoryRecursively(BlobsDirectory)
+		require.NoError(t, err)
+		assert.Empty(t, files)
+
+		require.NoError(t, RemoveBlob(backend, hash, func() (bool, error) { return false, nil }))
+	})
+}
@@ -0,0 +1,709 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package filestore
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+// Files written through an EncryptedFileBackend start with a header holding the
+// per-file data key, wrapped with the master key, followed by the content split
+// in segments that are sealed individually so that readers can seek.
+//
+//	header:  magic (8) | master key id (8) | nonce (12) | wrapped data key (32) | tag (16)
+//	segment: sealed plaintext of up to encryptedSegmentSize bytes | tag (16)
+//
+// The nonce of each segment is its index, with the last byte set on the final
+// segment so that a truncated file fails to decrypt.
+const (
+	encryptedMagic       = "MMENCv01"
+	encryptionKeySize    = 32
+	encryptionKeyIDSize  = 8
+	encryptedNonceSize   = 12
+	encryptedTagSize     = 16
+	encryptedHeaderSize  = len(encryptedMagic) + encryptionKeyIDSize + encryptedNonceSize + encryptionKeySize + encryptedTagSize
+	encryptedSegmentSize = 64 * 1024
+	encryptedSealedSize  = encryptedSegmentSize + encryptedTagSize
+)
+
+type encryptionKey struct {
+	id   []byte
+	aead cipher.AEAD
+}
+
+// EncryptedFileBackend wraps a FileBackend to encrypt every file it writes with
+// its own data key, which is in turn encrypted with a master key. Files are
+// decrypted transparently when read, and files written before encryption was
+// enabled are read as they are.
+//
+// The master key can be rotated by configuring a new key and keeping the
+// previous ones until RewrapFile has been called on every file.
+//
+// As the stored content is encrypted, it does not implement FileBackendWithLinkGenerator.
+type EncryptedFileBackend struct {
+	backend  FileBackend
+	current  *encryptionKey
+	previous []*encryptionKey
+}
+
+// NewEncryptedFileBackend wraps the given backend, using the base64 encoded
+// 256-bit key to encrypt new files. The previous keys are only used to read
+// files written before the key was rotated.
+func NewEncryptedFileBackend(backend FileBackend, key string, previousKeys []string) (*EncryptedFileBackend, error) {
+	current, err := newEncryptionKey(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid encryption key")
+	}
+
+	b := &EncryptedFileBackend{
+		backend: backend,
+		current: current,
+	}
+	for _, k := range previousKeys {
+		previous, err := newEncryptionKey(k)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid previous encryption key")
+		}
+		b.previous = append(b.previous, previous)
+	}
+
+	return b, nil
+}
+
+func newEncryptionKey(key string) (*encryptionKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) != encryptionKeySize {
+		return nil, errors.Errorf("the key must be %d bytes long", encryptionKeySize)
+	}
+
+	aead, err := newAEAD(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(raw)
+	return &encryptionKey{id: sum[:encryptionKeyIDSize], aead: aead}, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Unwrap returns the backend the files are stored in.
+func (b *EncryptedFileBackend) Unwrap() FileBackend {
+	return b.backend
+}
+
+func (b *EncryptedFileBackend) findKey(id []byte) *encryptionKey {
+	if bytes.Equal(b.current.id, id) {
+		return b.current
+	}
+	for _, k := range b.previous {
+		if bytes.Equal(k.id, id) {
+			return k
+		}
+	}
+	return nil
+}
+
+// newHeader generates a data key and returns it along with the header storing it.
+func (b *EncryptedFileBackend) newHeader() ([]byte, []byte, error) {
+	dataKey := make([]byte, encryptionKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, nil, errors.Wrap(err, "unable to generate a data key")
+	}
+
+	header, err := b.wrapDataKey(dataKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return header, dataKey, nil
+}
+
+func (b *EncryptedFileBackend) wrapDataKey(dataKey []byte) ([]byte, error) {
+	header := make([]byte, 0, encryptedHeaderSize)
+	header = append(header, encryptedMagic...)
+	header = append(header, b.current.id...)
+
+	nonce := make([]byte, encryptedNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errors.Wrap(err, "unable to generate a nonce")
+	}
+	header = append(header, nonce...)
+
+	return b.current.aead.Seal(header, nonce, dataKey, header[:len(encryptedMagic)+encryptionKeyIDSize]), nil
+}
+
+// readHeader reads the header at the start of r. It returns a nil header, and
+// rewinds r, if the file was not written encrypted.
+func (b *EncryptedFileBackend) readHeader(r io.ReadSeeker) ([]byte, error) {
+	header := make([]byte, encryptedHeaderSize)
+	n, err := io.ReadFull(r, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+
+	if n < encryptedHeaderSize || string(header[:len(encryptedMagic)]) != encryptedMagic {
+		if _, err := r.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	return header, nil
+}
+
+func (b *EncryptedFileBackend) unwrapDataKey(header []byte) ([]byte, *encryptionKey, error) {
+	idEnd := len(encryptedMagic) + encryptionKeyIDSize
+	key := b.findKey(header[len(encryptedMagic):idEnd])
+	if key == nil {
+		return nil, nil, errors.New("the file was encrypted with an unknown key")
+	}
+
+	nonce := header[idEnd : idEnd+encryptedNonceSize]
+	dataKey, err := key.aead.Open(nil, nonce, header[idEnd+encryptedNonceSize:], header[:idEnd])
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "unable to decrypt the data key")
+	}
+	return dataKey, key, nil
+}
+
+func segmentNonce(index int64, last bool) []byte {
+	nonce := make([]byte, encryptedNonceSize)
+	binary.BigEndian.PutUint64(nonce, uint64(index))
+	if last {
+		nonce[encryptedNonceSize-1] = 1
+	}
+	return nonce
+}
+
+func (b *EncryptedFileBackend) DriverName() string {
+	return b.backend.DriverName()
+}
+
+func (b *EncryptedFileBackend) TestConnection() error {
+	return b.backend.TestConnection()
+}
+
+// Caller must close the first return value
+func (b *EncryptedFileBackend) Reader(path string) (ReadCloseSeeker, error) {
+	r, err := b.backend.Reader(path)
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := b.readHeader(r)
+	if err != nil {
+		r.Close()
+		return nil, errors.Wrapf(err, "unable to read file %s", path)
+	}
+	if header == nil {
+		return r, nil
+	}
+
+	dataKey, _, err := b.unwrapDataKey(header)
+	if err != nil {
+		r.Close()
+		return nil, errors.Wrapf(err, "unable to read file %s", path)
+	}
+
+	dr, err := newDecryptingReader(r, dataKey)
+	if err != nil {
+		r.Close()
+		return nil, errors.Wrapf(err, "unable to read file %s", path)
+	}
+	return dr, nil
+}
+
+func (b *EncryptedFileBackend) ReadFile(path string) ([]byte, error) {
+	r, err := b.Reader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	f, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read file %s", path)
+	}
+	return f, nil
+}
+
+func (b *EncryptedFileBackend) FileExists(path string) (bool, error) {
+	return b.backend.FileExists(path)
+}
+
+func (b *EncryptedFileBackend) FileSize(path string) (int64, error) {
+	r, err := b.Reader(path)
+	if err != nil {
+		return 0, errors.Wrapf(err, "unable to get file size for %s", path)
+	}
+	defer r.Close()
+
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, errors.Wrapf(err, "unable to get file size for %s", path)
+	}
+	return size, nil
+}
+
+func (b *EncryptedFileBackend) FileModTime(path string) (time.Time, error) {
+	return b.backend.FileModTime(path)
+}
+
+// CopyFile copies the encrypted content as it is, as the data key is not bound to the path of the file.
+func (b *EncryptedFileBackend) CopyFile(oldPath, newPath string) error {
+	return b.backend.CopyFile(oldPath, newPath)
+}
+
+func (b *EncryptedFileBackend) MoveFile(oldPath, newPath string) error {
+	return b.backend.MoveFile(oldPath, newPath)
+}
+
+func (b *EncryptedFileBackend) WriteFile(fr io.Reader, path string) (int64, error) {
+	return b.WriteFileContext(context.Background(), fr, path)
+}
+
+func (b *EncryptedFileBackend) WriteFileContext(ctx context.Context, fr io.Reader, path string) (int64, error) {
+	header, dataKey, err := b.newHeader()
+	if err != nil {
+		return 0, errors.Wrapf(err, "unable write the data in the file %s", path)
+	}
+
+	er, err := newEncryptingReader(ctx, fr, header, dataKey)
+	if err != nil {
+		return 0, errors.Wrapf(err, "unable write the data in the file %s", path)
+	}
+
+	if _, err := TryWriteFileContext(ctx, b.backend, er, path); err != nil {
+		return er.written, err
+	}
+	return er.written, nil
+}
+
+// AppendFile is not supported, as the last segment of the file is sealed and
+// the whole file would have to be rewritten to extend it.
+func (b *EncryptedFileBackend) AppendFile(fr io.Reader, path string) (int64, error) {
+	return 0, errors.Wrapf(ErrAppendNotSupported, "unable append the data in the file %s", path)
+}
+
+func (b *EncryptedFileBackend) RemoveFile(path string) error {
+	return b.backend.RemoveFile(path)
+}
+
+func (b *EncryptedFileBackend) ListDirectory(path string) ([]string, error) {
+	return b.backend.ListDirectory(path)
+}
+
+func (b *EncryptedFileBackend) ListDirectoryRecursively(path string) ([]string, error) {
+	return b.backend.ListDirectoryRecursively(path)
+}
+
+func (b *EncryptedFileBackend) RemoveDirectory(path string) error {
+	return b.backend.RemoveDirectory(path)
+}
+
+// isDirectory reports whether path is a directory. Object stores have no
+// directories, so any path that is not a file is listed as a directory there.
+func (b *EncryptedFileBackend) isDirectory(path string) (bool, error) {
+	if local, ok := b.backend.(*LocalFileBackend); ok {
+		info, err := os.Stat(filepath.Join(local.directory, path))
+		if err != nil {
+			return false, errors.Wrapf(err, "unable to stat path %s", path)
+		}
+		return info.IsDir(), nil
+	}
+
+	exists, err := b.backend.FileExists(path)
+	if err != nil {
+		return false, err
+	}
+	return !exists, nil
+}
+
+// ZipReader will create a zip of path. If path is a single file, it will zip the single file.
+// If deflate is true, the contents will be compressed. It will stream the zip to io.ReadCloser.
+func (b *EncryptedFileBackend) ZipReader(path string, deflate bool) (io.ReadCloser, error) {
+	deflateMethod := zip.Store
+	if deflate {
+		deflateMethod = zip.Deflate
+	}
+
+	isDir, err := b.isDirectory(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// We want the zipped files to be relative to the directory, or at the root
+	// of the zip for a single file.
+	paths := []string{path}
+	stripPath := filepath.Dir(path) + "/"
+	if isDir {
+		paths, err = b.backend.ListDirectoryRecursively(path)
+		if err != nil {
+			return nil, err
+		}
+		stripPath = strings.TrimSuffix(path, "/") + "/"
+	}
+
+	pr, pw := io.Pipe()
+
+	go func() {
+		defer pw.Close()
+
+		zipWriter := zip.NewWriter(pw)
+		defer zipWriter.Close()
+
+		for _, p := range paths {
+			if err := b.copyFileToZipWriter(zipWriter, p, stripPath, deflateMethod); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+	}()
+
+	return pr, nil
+}
+
+func (b *EncryptedFileBackend) copyFileToZipWriter(zipWriter *zip.Writer, path, stripPath string, deflateMethod uint16) error {
+	modTime, err := b.backend.FileModTime(path)
+	if err != nil {
+		return errors.Wrapf(err, "unable to get modification time for %s", path)
+	}
+
+	header := &zip.FileHeader{
+		Name:     strings.TrimPrefix(path, stripPath),
+		Method:   deflateMethod,
+		Modified: modTime,
+	}
+	header.SetMode(0644) // rw-r--r-- permissions
+
+	writer, err := zipWriter.CreateHeader(header)
+	if err != nil {
+		return errors.Wrapf(err, "unable to create zip entry for %s", path)
+	}
+
+	r, err := b.Reader(path)
+	if err != nil {
+		return errors.Wrapf(err, "unable to create reader for %s", path)
+	}
+	defer r.Close()
+
+	if _, err := io.Copy(writer, r); err != nil {
+		return errors.Wrapf(err, "unable to copy content for %s", path)
+	}
+
+	return nil
+}
+
+// RewrapFile makes sure the file is encrypted with the current master key. Files
+// encrypted with a previous key only have their header rewritten, and files
+// written before encryption was enabled are encrypted. It returns whether the
+// file was rewritten.
+func (b *EncryptedFileBackend) RewrapFile(path string) (bool, error) {
+	r, err := b.backend.Reader(path)
+	if err != nil {
+		return false, errors.Wrapf(err, "unable to read file %s", path)
+	}
+	defer r.Close()
+
+	header, err := b.readHeader(r)
+	if err != nil {
+		return false, errors.Wrapf(err, "unable to read file %s", path)
+	}
+
+	var content io.Reader
+	if header == nil {
+		newHeader, dataKey, err := b.newHeader()
+		if err != nil {
+			return false, errors.Wrapf(err, "unable to encrypt file %s", path)
+		}
+		er, err := newEncryptingReader(context.Background(), r, newHeader, dataKey)
+		if err != nil {
+			return false, errors.Wrapf(err, "unable to encrypt file %s", path)
+		}
+		content = er
+	} else {
+		dataKey, key, err := b.unwrapDataKey(header)
+		if err != nil {
+			return false, errors.Wrapf(err, "unable to read file %s", path)
+		}
+		if key == b.current {
+			return false, nil
+		}
+
+		newHeader, err := b.wrapDataKey(dataKey)
+		if err != nil {
+			return false, errors.Wrapf(err, "unable to rewrap file %s", path)
+		}
+		content = io.MultiReader(bytes.NewReader(newHeader), r)
+	}
+
+	tmpPath := path + "." + model.NewId() + ".tmp"
+	if _, err := b.backend.WriteFile(content, tmpPath); err != nil {
+		b.backend.RemoveFile(tmpPath)
+		return false, errors.Wrapf(err, "unable to rewrap file %s", path)
+	}
+
+	if err := b.backend.MoveFile(tmpPath, path); err != nil {
+		b.backend.RemoveFile(tmpPath)
+		return false, errors.Wrapf(err, "unable to rewrap file %s", path)
+	}
+
+	return true, nil
+}
+
+// encryptingReader encrypts the content read from the source, emitting the
+// header first and then one sealed segment at a time.
+type encryptingReader struct {
+	ctx     context.Context
+	src     *bufio.Reader
+	aead    cipher.AEAD
+	index   int64
+	buf     []byte
+	sealed  []byte
+	out     []byte
+	done    bool
+	written int64
+}
+
+func newEncryptingReader(ctx context.Context, src io.Reader, header, dataKey []byte) (*encryptingReader, error) {
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &encryptingReader{
+		ctx:    ctx,
+		src:    bufio.NewReaderSize(src, encryptedSegmentSize),
+		aead:   aead,
+		buf:    make([]byte, encryptedSegmentSize),
+		sealed: make([]byte, 0, encryptedSealedSize),
+		out:    header,
+	}, nil
+}
+
+func (e *encryptingReader) Read(p []byte) (int, error) {
+	for len(e.out) == 0 {
+		if e.done {
+			return 0, io.EOF
+		}
+		if err := e.sealSegment(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, e.out)
+	e.out = e.out[n:]
+	return n, nil
+}
+
+func (e *encryptingReader) sealSegment() error {
+	n, err := io.ReadFull(e.src, e.buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+
+	// The context is checked here as not all backends support writing with a context.
+	if err := e.ctx.Err(); err != nil {
+		return err
+	}
+
+	last := err != nil
+	if !last {
+		if _, err := e.src.Peek(1); err == io.EOF {
+			last = true
+		} else if err != nil {
+			return err
+		}
+	}
+
+	e.out = e.aead.Seal(e.sealed[:0], segmentNonce(e.index, last), e.buf[:n], nil)
+	e.written += int64(n)
+	e.index++
+	e.done = last
+	return nil
+}
+
+// decryptingReader decrypts the segments following the header, which must
+// have already been read from the source. The segments are read from the same
+// source by Read and ReadAt, so the mutex serializes them.
+type decryptingReader struct {
+	mut       sync.Mutex
+	src       ReadCloseSeeker
+	aead      cipher.AEAD
+	size      int64
+	segments  int64
+	offset    int64
+	srcOffset int64
+	index     int64
+	plain     []byte
+	sealed    []byte
+}
+
+var (
+	_ ReadCloseSeeker = (*decryptingReader)(nil)
+	_ io.ReaderAt     = (*decryptingReader)(nil)
+)
+
+func newDecryptingReader(src ReadCloseSeeker, dataKey []byte) (*decryptingReader, error) {
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	end, err := src.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	sealedSize := end - int64(encryptedHeaderSize)
+	segments := (sealedSize + encryptedSealedSize - 1) / encryptedSealedSize
+	if segments == 0 {
+		return nil, errors.New("the encrypted file is truncated")
+	}
+
+	return &decryptingReader{
+		src:       src,
+		aead:      aead,
+		size:      sealedSize - segments*encryptedTagSize,
+		segments:  segments,
+		srcOffset: end,
+		index:     -1,
+		sealed:    make([]byte, encryptedSealedSize),
+	}, nil
+}
+
+func (d *decryptingReader) openSegment(index int64) error {
+	start := int64(encryptedHeaderSize) + index*encryptedSealedSize
+	if d.srcOffset != start {
+		if _, err := d.src.Seek(start, io.SeekStart); err != nil {
+			return err
+		}
+		d.srcOffset = start
+	}
+
+	n, err := io.ReadFull(d.src, d.sealed)
+	d.srcOffset += int64(n)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return err
+	}
+
+	plain, err := d.aead.Open(d.plain[:0], segmentNonce(index, index == d.segments-1), d.sealed[:n], nil)
+	if err != nil {
+		return errors.Wrap(err, "unable to decrypt the file")
+	}
+	d.plain = plain
+	d.index = index
+	return nil
+}
+
+// readAt copies the plaintext at the given offset from the segment holding it.
+func (d *decryptingReader) readAt(p []byte, off int64) (int, error) {
+	if off >= d.size {
+		// Opening the last segment authenticates the end of the file.
+		if d.index != d.segments-1 {
+			if err := d.openSegment(d.segments - 1); err != nil {
+				return 0, err
+			}
+		}
+		return 0, io.EOF
+	}
+
+	index := off / encryptedSegmentSize
+	if index != d.index {
+		if err := d.openSegment(index); err != nil {
+			return 0, err
+		}
+	}
+
+	return copy(p, d.plain[off%encryptedSegmentSize:]), nil
+}
+
+func (d *decryptingReader) Read(p []byte) (int, error) {
+	d.mut.Lock()
+	defer d.mut.Unlock()
+
+	n, err := d.readAt(p, d.offset)
+	d.offset += int64(n)
+	return n, err
+}
+
+func (d *decryptingReader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+
+	d.mut.Lock()
+	defer d.mut.Unlock()
+
+	var n int
+	for n < len(p) {
+		m, err := d.readAt(p[n:], off+int64(n))
+		n += m
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+func (d *decryptingReader) Seek(offset int64, whence int) (int64, error) {
+	var abs int64
+	switch whence {
+	case io.SeekStart:
+		abs = offset
+	case io.SeekCurrent:
+		abs = d.offset + offset
+	case io.SeekEnd:
+		abs = d.size + offset
+	default:
+		return 0, errors.New("invalid whence")
+	}
+
+	if abs < 0 {
+		return 0, errors.New("negative position")
+	}
+
+	d.offset = abs
+	return abs, nil
+}
+
+func (d *decryptingReader) Close() error {
+	return d.src.Close()
+}
+
+// CancelTimeout cancels the timeout of the underlying reader, if it has one.
+func (d *decryptingReader) CancelTimeout() bool {
+	if tc, ok := d.src.(interface{ CancelTimeout() bool }); ok {
+		return tc.CancelTimeout()
+	}
+	return true
+}
@@ -0,0 +1,249 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package filestore
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestEncryptionKey(t *testing.T) string {
+	key := make([]byte, encryptionKeySize)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+func newTestEncryptedBackend(t *testing.T, dir, key string, previousKeys ...string) *EncryptedFileBackend {
+	backend, err := NewEncryptedFileBackend(&LocalFileBackend{directory: dir}, key, previousKeys)
+	require.NoError(t, err)
+	return backend
+}
+
+func TestNewEncryptedFileBackend(t *testing.T) {
+	_, err := NewEncryptedFileBackend(&LocalFileBackend{}, "", nil)
+	require.Error(t, err)
+
+	_, err = NewEncryptedFileBackend(&LocalFileBackend{}, base64.StdEncoding.EncodeToString([]byte("short")), nil)
+	require.Error(t, err)
+
+	_, err = NewEncryptedFileBackend(&LocalFileBackend{}, newTestEncryptionKey(t), []string{"invalid"})
+	require.Error(t, err)
+
+	backend, err := NewFileBackend(FileBackendSettings{
+		DriverName:    driverLocal,
+		Directory:     t.TempDir(),
+		EncryptionKey: newTestEncryptionKey(t),
+	})
+	require.NoError(t, err)
+	require.IsType(t, &EncryptedFileBackend{}, backend)
+	_, ok := backend.(FileBackendWithLinkGenerator)
+	require.False(t, ok)
+}
+
+func TestEncryptedFileBackend(t *testing.T) {
+	dir := t.TempDir()
+	key := newTestEncryptionKey(t)
+	backend := newTestEncryptedBackend(t, dir, key)
+
+	for name, size := range map[string]int{
+		"empty":             0,
+		"small":             100,
+		"one segment":       encryptedSegmentSize,
+		"several segments":  3*encryptedSegmentSize + 17,
+		"segment and a bit": encryptedSegmentSize + 1,
+	} {
+		t.Run(name, func(t *testing.T) {
+			data := make([]byte, size)
+			_, err := rand.Read(data)
+			require.NoError(t, err)
+
+			path := "tests/" + randomString()
+			written, err := backend.WriteFile(bytes.NewReader(data), path)
+			require.NoError(t, err)
+			assert.EqualValues(t, size, written)
+
+			stored, err := os.ReadFile(filepath.Join(dir, path))
+			require.NoError(t, err)
+			if size > 0 {
+				assert.NotContains(t, string(stored), string(data))
+			}
+
+			read, err := backend.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, data, read)
+
+			fileSize, err := backend.FileSize(path)
+			require.NoError(t, err)
+			assert.EqualValues(t, size, fileSize)
+		})
+	}
+
+	t.Run("seek", func(t *testing.T) {
+		data := make([]byte, 2*encryptedSegmentSize+100)
+		_, err := rand.Read(data)
+		require.NoError(t, err)
+
+		path := "tests/" + randomString()
+		_, err = backend.WriteFile(bytes.NewReader(data), path)
+		require.NoError(t, err)
+
+		r, err := backend.Reader(path)
+		require.NoError(t, err)
+		defer r.Close()
+
+		for _, offset := range []int64{encryptedSegmentSize + 10, 5, 2 * encryptedSegmentSize} {
+			_, err = r.Seek(offset, io.SeekStart)
+			require.NoError(t, err)
+
+			buf := make([]byte, 50)
+			_, err = io.ReadFull(r, buf)
+			require.NoError(t, err)
+			assert.Equal(t, data[offset:offset+50], buf)
+		}
+
+		end, err := r.Seek(-10, io.SeekEnd)
+		require.NoError(t, err)
+		rest, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t, data[end:], rest)
+	})
+
+	t.Run("read at", func(t *testing.T) {
+		data := make([]byte, 2*encryptedSegmentSize+100)
+		_, err := rand.Read(data)
+		require.NoError(t, err)
+
+		path := "tests/" + randomString()
+		_, err = backend.WriteFile(bytes.NewReader(data), path)
+		require.NoError(t, err)
+
+		r, err := backend.Reader(path)
+		require.NoError(t, err)
+		defer r.Close()
+		ra, ok := r.(io.ReaderAt)
+		require.True(t, ok)
+
+		// Spans the end of the first segment.
+		buf := make([]byte, 100)
+		n, err := ra.ReadAt(buf, encryptedSegmentSize-50)
+		require.NoError(t, err)
+		assert.Equal(t, 100, n)
+		assert.Equal(t, data[encryptedSegmentSize-50:encryptedSegmentSize+50], buf)
+
+		n, err = ra.ReadAt(buf, int64(len(data))-40)
+		assert.Equal(t, io.EOF, err)
+		assert.Equal(t, data[len(data)-40:], buf[:n])
+
+		// The offset of Read isn't moved by ReadAt.
+		_, err = io.ReadFull(r, buf)
+		require.NoError(t, err)
+		assert.Equal(t, data[:100], buf)
+	})
+
+	t.Run("append is not supported", func(t *testing.T) {
+		path := "tests/" + randomString()
+		_, err := backend.WriteFile(bytes.NewReader([]byte("hello")), path)
+		require.NoError(t, err)
+
+		written, err := backend.AppendFile(bytes.NewReader([]byte(" world")), path)
+		require.ErrorIs(t, err, ErrAppendNotSupported)
+		assert.Zero(t, written)
+		assert.False(t, SupportsAppend(backend))
+		assert.True(t, SupportsAppend(backend.Unwrap()))
+
+		read, err := backend.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "hello", string(read))
+	})
+
+	t.Run("plaintext files are read as they are", func(t *testing.T) {
+		path := "tests/" + randomString()
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "tests"), 0750))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, path), []byte("plaintext"), 0600))
+
+		read, err := backend.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "plaintext", string(read))
+	})
+
+	t.Run("truncated files fail to decrypt", func(t *testing.T) {
+		data := make([]byte, 2*encryptedSegmentSize)
+		path := "tests/" + randomString()
+		_, err := backend.WriteFile(bytes.NewReader(data), path)
+		require.NoError(t, err)
+
+		fullPath := filepath.Join(dir, path)
+		stored, err := os.ReadFile(fullPath)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(fullPath, stored[:encryptedHeaderSize+encryptedSealedSize], 0600))
+
+		_, err = backend.ReadFile(path)
+		require.Error(t, err)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		path := "tests/" + randomString()
+		_, err := backend.WriteFile(bytes.NewReader([]byte("secret")), path)
+		require.NoError(t, err)
+
+		other := newTestEncryptedBackend(t, dir, newTestEncryptionKey(t))
+		_, err = other.ReadFile(path)
+		require.Error(t, err)
+	})
+}
+
+func TestEncryptedFileBackendRewrapFile(t *testing.T) {
+	dir := t.TempDir()
+	oldKey := newTestEncryptionKey(t)
+	newKey := newTestEncryptionKey(t)
+
+	oldBackend := newTestEncryptedBackend(t, dir, oldKey)
+	encryptedPath := "tests/" + randomString()
+	_, err := oldBackend.WriteFile(bytes.NewReader([]byte("encrypted with the old key")), encryptedPath)
+	require.NoError(t, err)
+
+	plaintextPath := "tests/" + randomString()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, plaintextPath), []byte("plaintext"), 0600))
+
+	backend := newTestEncryptedBackend(t, dir, newKey, oldKey)
+
+	rewrapped, err := backend.RewrapFile(encryptedPath)
+	require.NoError(t, err)
+	assert.True(t, rewrapped)
+
+	rewrapped, err = backend.RewrapFile(encryptedPath)
+	require.NoError(t, err)
+	assert.False(t, rewrapped, "files encrypted with the current key should be left as they are")
+
+	rewrapped, err = backend.RewrapFile(plaintextPath)
+	require.NoError(t, err)
+	assert.True(t, rewrapped)
+
+	stored, err := os.ReadFile(filepath.Join(dir, plaintextPath))
+	require.NoError(t, err)
+	assert.NotContains(t, string(stored), "plaintext")
+
+	// The old key is no longer needed to read the files.
+	rotated := newTestEncryptedBackend(t, dir, newKey)
+
+	read, err := rotated.ReadFile(encryptedPath)
+	require.NoError(t, err)
+	assert.Equal(t, "encrypted with the old key", string(read))
+
+	read, err = rotated.ReadFile(plaintextPath)
+	require.NoError(t, err)
+	assert.Equal(t, "plaintext", string(read))
+
+	_, err = oldBackend.ReadFile(encryptedPath)
+	require.Error(t, err)
+}
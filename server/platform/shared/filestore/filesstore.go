@@ -15,8 +15,14 @@ import (
 const (
 	driverS3    = "amazons3"
 	driverLocal = "local"
+	driverGCS   = "googlecloudstorage"
+	driverAzure = "azureblob"
 )
 
+// ErrAppendNotSupported is returned by AppendFile when the backend cannot
+// extend a file without rewriting it.
+var ErrAppendNotSupported = errors.New("appending to a file is not supported by this backend")
+
 type ReadCloseSeeker interface {
 	io.ReadCloser
 	io.Seeker
@@ -65,14 +71,62 @@ type FileBackendSettings struct {
 	AmazonS3PresignExpiresSeconds      int64
 	AmazonS3UploadPartSizeBytes        int64
 	AmazonS3StorageClass               string
+
+	GoogleCloudStorageBucket                     string
+	GoogleCloudStoragePathPrefix                 string
+	GoogleCloudStorageCredentialsJSON            string
+	GoogleCloudStorageEndpoint                   string
+	GoogleCloudStorageRequestTimeoutMilliseconds int64
+	GoogleCloudStoragePresignExpiresSeconds      int64
+
+	AzureStorageAccountName                string
+	AzureStorageAccountKey                 string
+	AzureStorageContainer                  string
+	AzureStoragePathPrefix                 string
+	AzureStorageEndpoint                   string
+	AzureStorageRequestTimeoutMilliseconds int64
+	AzureStoragePresignExpiresSeconds      int64
+
+	// EncryptionKey enables the encryption of the stored files when set.
+	EncryptionKey          string
+	EncryptionPreviousKeys []string
 }
 
 func NewFileBackendSettingsFromConfig(fileSettings *model.FileSettings, enableComplianceFeature bool, skipVerify bool) FileBackendSettings {
-	if *fileSettings.DriverName == model.ImageDriverLocal {
+	settings := newDriverSettingsFromConfig(fileSettings, enableComplianceFeature, skipVerify)
+	if fileSettings.EnableEncryptionAtRest != nil && *fileSettings.EnableEncryptionAtRest {
+		settings.EncryptionKey = *fileSettings.EncryptionAtRestKey
+		settings.EncryptionPreviousKeys = fileSettings.EncryptionAtRestPreviousKeys
+	}
+	return settings
+}
+
+func newDriverSettingsFromConfig(fileSettings *model.FileSettings, enableComplianceFeature bool, skipVerify bool) FileBackendSettings {
+	switch *fileSettings.DriverName {
+	case model.ImageDriverLocal:
 		return FileBackendSettings{
 			DriverName: *fileSettings.DriverName,
 			Directory:  *fileSettings.Directory,
 		}
+	case model.ImageDriverGCS:
+		return FileBackendSettings{
+			DriverName:                                   *fileSettings.DriverName,
+			GoogleCloudStorageBucket:                     *fileSettings.GoogleCloudStorageBucket,
+			GoogleCloudStoragePathPrefix:                 *fileSettings.GoogleCloudStoragePathPrefix,
+			GoogleCloudStorageCredentialsJSON:            *fileSettings.GoogleCloudStorageCredentialsJSON,
+			GoogleCloudStorageEndpoint:                   *fileSettings.GoogleCloudStorageEndpoint,
+			GoogleCloudStorageRequestTimeoutMilliseconds: *fileSettings.GoogleCloudStorageRequestTimeoutMilliseconds,
+		}
+	case model.ImageDriverAzure:
+		return FileBackendSettings{
+			DriverName:                             *fileSettings.DriverName,
+			AzureStorageAccountName:                *fileSettings.AzureStorageAccountName,
+			AzureStorageAccountKey:                 *fileSettings.AzureStorageAccountKey,
+			AzureStorageContainer:                  *fileSettings.AzureStorageContainer,
+			AzureStoragePathPrefix:                 *fileSettings.AzureStoragePathPrefix,
+			AzureStorageEndpoint:                   *fileSettings.AzureStorageEndpoint,
+			AzureStorageRequestTimeoutMilliseconds: *fileSettings.AzureStorageRequestTimeoutMilliseconds,
+		}
 	}
 	return FileBackendSettings{
 		DriverName:                         *fileSettings.DriverName,
@@ -94,11 +148,33 @@ func NewFileBackendSettingsFromConfig(fileSettings *model.FileSettings, enableCo
 }
 
 func NewExportFileBackendSettingsFromConfig(fileSettings *model.FileSettings, enableComplianceFeature bool, skipVerify bool) FileBackendSettings {
-	if *fileSettings.ExportDriverName == model.ImageDriverLocal {
+	switch *fileSettings.ExportDriverName {
+	case model.ImageDriverLocal:
 		return FileBackendSettings{
 			DriverName: *fileSettings.ExportDriverName,
 			Directory:  *fileSettings.ExportDirectory,
 		}
+	case model.ImageDriverGCS:
+		return FileBackendSettings{
+			DriverName:                                   *fileSettings.ExportDriverName,
+			GoogleCloudStorageBucket:                     *fileSettings.ExportGoogleCloudStorageBucket,
+			GoogleCloudStoragePathPrefix:                 *fileSettings.ExportGoogleCloudStoragePathPrefix,
+			GoogleCloudStorageCredentialsJSON:            *fileSettings.ExportGoogleCloudStorageCredentialsJSON,
+			GoogleCloudStorageEndpoint:                   *fileSettings.ExportGoogleCloudStorageEndpoint,
+			GoogleCloudStorageRequestTimeoutMilliseconds: *fileSettings.ExportGoogleCloudStorageRequestTimeoutMilliseconds,
+			GoogleCloudStoragePresignExpiresSeconds:      *fileSettings.ExportGoogleCloudStoragePresignExpiresSeconds,
+		}
+	case model.ImageDriverAzure:
+		return FileBackendSettings{
+			DriverName:                             *fileSettings.ExportDriverName,
+			AzureStorageAccountName:                *fileSettings.ExportAzureStorageAccountName,
+			AzureStorageAccountKey:                 *fileSettings.ExportAzureStorageAccountKey,
+			AzureStorageContainer:                  *fileSettings.ExportAzureStorageContainer,
+			AzureStoragePathPrefix:                 *fileSettings.ExportAzureStoragePathPrefix,
+			AzureStorageEndpoint:                   *fileSettings.ExportAzureStorageEndpoint,
+			AzureStorageRequestTimeoutMilliseconds: *fileSettings.ExportAzureStorageRequestTimeoutMilliseconds,
+			AzureStoragePresignExpiresSeconds:      *fileSettings.ExportAzureStoragePresignExpiresSeconds,
+		}
 	}
 	return FileBackendSettings{
 		DriverName:                         *fileSettings.ExportDriverName,
@@ -144,6 +220,19 @@ func NewExportFileBackend(settings FileBackendSettings) (FileBackend, error) {
 }
 
 func newFileBackend(settings FileBackendSettings, canBeCloud bool) (FileBackend, error) {
+	backend, err := newDriverFileBackend(settings, canBeCloud)
+	if err != nil || settings.EncryptionKey == "" {
+		return backend, err
+	}
+
+	encrypted, err := NewEncryptedFileBackend(backend, settings.EncryptionKey, settings.EncryptionPreviousKeys)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to set up the file encryption")
+	}
+	return encrypted, nil
+}
+
+func newDriverFileBackend(settings FileBackendSettings, canBeCloud bool) (FileBackend, error) {
 	switch settings.DriverName {
 	case driverS3:
 		newBackendFn := NewS3FileBackend
@@ -155,6 +244,18 @@ func newFileBackend(settings FileBackendSettings, canBeCloud bool) (FileBackend,
 			return nil, errors.Wrap(err, "unable to connect to the s3 backend")
 		}
 		return backend, nil
+	case driverGCS:
+		backend, err := NewGCSFileBackend(settings)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to connect to the google cloud storage backend")
+		}
+		return backend, nil
+	case driverAzure:
+		backend, err := NewAzureFileBackend(settings)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to connect to the azure blob storage backend")
+		}
+		return backend, nil
 	case driverLocal:
 		return &LocalFileBackend{
 			directory: settings.Directory,
@@ -177,3 +278,11 @@ func TryWriteFileContext(ctx context.Context, fb FileBackend, fr io.Reader, path
 
 	return fb.WriteFile(fr, path)
 }
+
+// SupportsAppend checks if data can be appended to the files of the backend.
+// Callers should write the data to separate files otherwise, and join them in
+// a single write once complete.
+func SupportsAppend(fb FileBackend) bool {
+	_, encrypted := fb.(*EncryptedFileBackend)
+	return !encrypted
+}
@@ -56,6 +56,20 @@ func TestLocalFileBackendTestSuite(t *testing.T) {
 	})
 }
 
+func TestEncryptedLocalFileBackendTestSuite(t *testing.T) {
+	dir, err := os.MkdirTemp("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	suite.Run(t, &FileBackendTestSuite{
+		settings: FileBackendSettings{
+			DriverName:    driverLocal,
+			Directory:     dir,
+			EncryptionKey: newTestEncryptionKey(t),
+		},
+	})
+}
+
 func TestS3FileBackendTestSuite(t *testing.T) {
 	runBackendTest(t, false)
 }
@@ -93,6 +107,53 @@ func runBackendTest(t *testing.T, encrypt bool) {
 	})
 }
 
+func TestGCSFileBackendTestSuite(t *testing.T) {
+	gcsHost := os.Getenv("CI_FAKE_GCS_HOST")
+	if gcsHost == "" {
+		gcsHost = "localhost"
+	}
+
+	gcsPort := os.Getenv("CI_FAKE_GCS_PORT")
+	if gcsPort == "" {
+		gcsPort = "4443"
+	}
+
+	suite.Run(t, &FileBackendTestSuite{
+		settings: FileBackendSettings{
+			DriverName:                                   driverGCS,
+			GoogleCloudStorageBucket:                     "mattermost-test",
+			GoogleCloudStorageEndpoint:                   fmt.Sprintf("http://%s:%s/storage/v1/", gcsHost, gcsPort),
+			GoogleCloudStorageRequestTimeoutMilliseconds: 5000,
+		},
+	})
+}
+
+// azuriteAccountKey is the well-known key of the development storage account of Azurite.
+const azuriteAccountKey = "Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw=="
+
+func TestAzureFileBackendTestSuite(t *testing.T) {
+	azuriteHost := os.Getenv("CI_AZURITE_HOST")
+	if azuriteHost == "" {
+		azuriteHost = "localhost"
+	}
+
+	azuritePort := os.Getenv("CI_AZURITE_PORT")
+	if azuritePort == "" {
+		azuritePort = "10000"
+	}
+
+	suite.Run(t, &FileBackendTestSuite{
+		settings: FileBackendSettings{
+			DriverName:                             driverAzure,
+			AzureStorageAccountName:                "devstoreaccount1",
+			AzureStorageAccountKey:                 azuriteAccountKey,
+			AzureStorageContainer:                  "mattermost-test",
+			AzureStorageEndpoint:                   fmt.Sprintf("http://%s:%s/devstoreaccount1/", azuriteHost, azuritePort),
+			AzureStorageRequestTimeoutMilliseconds: 5000,
+		},
+	})
+}
+
 func (s *FileBackendTestSuite) SetupTest() {
 	backend, err := NewFileBackend(s.settings)
 	require.NoError(s.T(), err)
@@ -100,10 +161,17 @@ func (s *FileBackendTestSuite) SetupTest() {
 
 	// This is needed to create the bucket if it doesn't exist.
 	err = s.backend.TestConnection()
-	if _, ok := err.(*S3FileBackendNoBucketError); ok {
+	switch err.(type) {
+	case *S3FileBackendNoBucketError:
 		s3Backend := s.backend.(*S3FileBackend)
 		s.NoError(s3Backend.MakeBucket())
-	} else {
+	case *GCSFileBackendNoBucketError:
+		gcsBackend := s.backend.(*GCSFileBackend)
+		s.NoError(gcsBackend.MakeBucket("mattermost-test"))
+	case *AzureFileBackendNoContainerError:
+		azureBackend := s.backend.(*AzureFileBackend)
+		s.NoError(azureBackend.MakeContainer())
+	default:
 		s.NoError(err)
 	}
 }
@@ -480,6 +548,12 @@ func (s *FileBackendTestSuite) TestRemoveDirectory() {
 }
 
 func (s *FileBackendTestSuite) TestAppendFile() {
+	if !SupportsAppend(s.backend) {
+		_, err := s.backend.AppendFile(bytes.NewReader([]byte("data")), "tests/"+randomString())
+		s.ErrorIs(err, ErrAppendNotSupported)
+		return
+	}
+
 	s.Run("should fail if target file is missing", func() {
 		path := "tests/" + randomString()
 		b := make([]byte, 1024)
@@ -811,6 +885,56 @@ func TestNewExportFileBackendSettingsFromConfig(t *testing.T) {
 
 		require.Equal(t, expected, actual)
 	})
+
+	t.Run("gcs filestore", func(t *testing.T) {
+		expected := FileBackendSettings{
+			DriverName:                                   driverGCS,
+			GoogleCloudStorageBucket:                     "mattermost-test",
+			GoogleCloudStoragePathPrefix:                 "prefix",
+			GoogleCloudStorageCredentialsJSON:            "{}",
+			GoogleCloudStorageEndpoint:                   "http://localhost:4443/storage/v1/",
+			GoogleCloudStorageRequestTimeoutMilliseconds: 1000,
+			GoogleCloudStoragePresignExpiresSeconds:      60000,
+		}
+
+		actual := NewExportFileBackendSettingsFromConfig(&model.FileSettings{
+			ExportDriverName:                                   model.NewPointer(driverGCS),
+			ExportGoogleCloudStorageBucket:                     model.NewPointer("mattermost-test"),
+			ExportGoogleCloudStoragePathPrefix:                 model.NewPointer("prefix"),
+			ExportGoogleCloudStorageCredentialsJSON:            model.NewPointer("{}"),
+			ExportGoogleCloudStorageEndpoint:                   model.NewPointer("http://localhost:4443/storage/v1/"),
+			ExportGoogleCloudStorageRequestTimeoutMilliseconds: model.NewPointer(int64(1000)),
+			ExportGoogleCloudStoragePresignExpiresSeconds:      model.NewPointer(int64(60000)),
+		}, false, false)
+
+		require.Equal(t, expected, actual)
+	})
+
+	t.Run("azure filestore", func(t *testing.T) {
+		expected := FileBackendSettings{
+			DriverName:                             driverAzure,
+			AzureStorageAccountName:                "devstoreaccount1",
+			AzureStorageAccountKey:                 azuriteAccountKey,
+			AzureStorageContainer:                  "mattermost-test",
+			AzureStoragePathPrefix:                 "prefix",
+			AzureStorageEndpoint:                   "http://localhost:10000/devstoreaccount1/",
+			AzureStorageRequestTimeoutMilliseconds: 1000,
+			AzureStoragePresignExpiresSeconds:      60000,
+		}
+
+		actual := NewExportFileBackendSettingsFromConfig(&model.FileSettings{
+			ExportDriverName:                             model.NewPointer(driverAzure),
+			ExportAzureStorageAccountName:                model.NewPointer("devstoreaccount1"),
+			ExportAzureStorageAccountKey:                 model.NewPointer(azuriteAccountKey),
+			ExportAzureStorageContainer:                  model.NewPointer("mattermost-test"),
+			ExportAzureStoragePathPrefix:                 model.NewPointer("prefix"),
+			ExportAzureStorageEndpoint:                   model.NewPointer("http://localhost:10000/devstoreaccount1/"),
+			ExportAzureStorageRequestTimeoutMilliseconds: model.NewPointer(int64(1000)),
+			ExportAzureStoragePresignExpiresSeconds:      model.NewPointer(int64(60000)),
+		}, false, false)
+
+		require.Equal(t, expected, actual)
+	})
 }
 
 func (s *FileBackendTestSuite) TestZipReaderSingleFile() {
@@ -0,0 +1,490 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package filestore
+
+import (
+	"archive/zip"
+	"context"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/pkg/errors"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+
+	"github.com/mattermost/mattermost/server/public/shared/mlog"
+)
+
+// GCSFileBackend contains all necessary information to communicate with
+// a Google Cloud Storage bucket.
+type GCSFileBackend struct {
+	bucket          string
+	pathPrefix      string
+	credentialsJSON string
+	endpoint        string
+	client          *storage.Client
+	timeout         time.Duration
+	presignExpires  time.Duration
+}
+
+// GCSFileBackendNoBucketError is returned when testing a connection and no bucket is found
+type GCSFileBackendNoBucketError struct{}
+
+var _ FileBackendWithLinkGenerator = (*GCSFileBackend)(nil)
+
+func (s *GCSFileBackendNoBucketError) Error() string {
+	return "no such bucket"
+}
+
+// NewGCSFileBackend returns an instance of a GCSFileBackend.
+//
+// Requests are authenticated with the service account key in the settings or, when
+// none is given, with the application default credentials. An endpoint without
+// credentials, as used to target fake-gcs-server, sends requests unauthenticated.
+func NewGCSFileBackend(settings FileBackendSettings) (*GCSFileBackend, error) {
+	if settings.GoogleCloudStorageBucket == "" {
+		return nil, errors.New("missing google cloud storage bucket settings")
+	}
+
+	backend := &GCSFileBackend{
+		bucket:          settings.GoogleCloudStorageBucket,
+		pathPrefix:      settings.GoogleCloudStoragePathPrefix,
+		credentialsJSON: settings.GoogleCloudStorageCredentialsJSON,
+		endpoint:        settings.GoogleCloudStorageEndpoint,
+		timeout:         time.Duration(settings.GoogleCloudStorageRequestTimeoutMilliseconds) * time.Millisecond,
+		presignExpires:  time.Duration(settings.GoogleCloudStoragePresignExpiresSeconds) * time.Second,
+	}
+
+	var opts []option.ClientOption
+	if backend.endpoint != "" {
+		opts = append(opts, option.WithEndpoint(backend.endpoint))
+	}
+	if backend.credentialsJSON != "" {
+		opts = append(opts, option.WithCredentialsJSON([]byte(backend.credentialsJSON)))
+	} else if backend.endpoint != "" {
+		opts = append(opts, option.WithoutAuthentication())
+	}
+
+	client, err := storage.NewClient(context.Background(), opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create the google cloud storage client")
+	}
+	backend.client = client
+
+	return backend, nil
+}
+
+func (b *GCSFileBackend) DriverName() string {
+	return driverGCS
+}
+
+func (b *GCSFileBackend) TestConnection() error {
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+
+	// As with S3, listing under the path prefix only requires access to the prefix,
+	// whereas reading the bucket attributes requires access to the whole bucket.
+	if b.pathPrefix != "" {
+		_, err := b.client.Bucket(b.bucket).Objects(ctx, &storage.Query{Prefix: b.pathPrefix}).Next()
+		if err != nil && err != iterator.Done {
+			if errors.Is(err, storage.ErrBucketNotExist) {
+				return &GCSFileBackendNoBucketError{}
+			}
+			return errors.Wrap(err, "unable to list objects in the google cloud storage bucket")
+		}
+	} else if _, err := b.client.Bucket(b.bucket).Attrs(ctx); err != nil {
+		if errors.Is(err, storage.ErrBucketNotExist) {
+			return &GCSFileBackendNoBucketError{}
+		}
+		return errors.Wrap(err, "unable to check if the google cloud storage bucket exists")
+	}
+
+	mlog.Debug("Connection to Google Cloud Storage is good. Bucket exists.")
+	return nil
+}
+
+// MakeBucket creates the bucket in the given project.
+func (b *GCSFileBackend) MakeBucket(projectID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+
+	if err := b.client.Bucket(b.bucket).Create(ctx, projectID, nil); err != nil {
+		return errors.Wrap(err, "unable to create the google cloud storage bucket")
+	}
+	return nil
+}
+
+func (b *GCSFileBackend) prefixedPath(s string) string {
+	return filepath.Join(b.pathPrefix, s)
+}
+
+func (b *GCSFileBackend) object(path string) *storage.ObjectHandle {
+	return b.client.Bucket(b.bucket).Object(path)
+}
+
+// Caller must close the first return value
+func (b *GCSFileBackend) Reader(path string) (ReadCloseSeeker, error) {
+	path = b.prefixedPath(path)
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+	attrs, err := b.object(path).Attrs(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to open file %s", path)
+	}
+
+	return newRangeReader(attrs.Size, b.timeout, func(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+		reader, err := b.object(path).Generation(attrs.Generation).NewRangeReader(ctx, offset, length)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to open file %s", path)
+		}
+		return reader, nil
+	}), nil
+}
+
+func (b *GCSFileBackend) ReadFile(path string) ([]byte, error) {
+	path = b.prefixedPath(path)
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+	reader, err := b.object(path).NewReader(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to open file %s", path)
+	}
+	defer reader.Close()
+
+	f, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read file %s", path)
+	}
+	return f, nil
+}
+
+func (b *GCSFileBackend) FileExists(path string) (bool, error) {
+	path = b.prefixedPath(path)
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+	_, err := b.object(path).Attrs(ctx)
+	if err == nil {
+		return true, nil
+	}
+
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return false, nil
+	}
+
+	return false, errors.Wrapf(err, "unable to know if file %s exists", path)
+}
+
+func (b *GCSFileBackend) FileSize(path string) (int64, error) {
+	path = b.prefixedPath(path)
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+	attrs, err := b.object(path).Attrs(ctx)
+	if err != nil {
+		return 0, errors.Wrapf(err, "unable to get file size for %s", path)
+	}
+
+	return attrs.Size, nil
+}
+
+func (b *GCSFileBackend) FileModTime(path string) (time.Time, error) {
+	path = b.prefixedPath(path)
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+	attrs, err := b.object(path).Attrs(ctx)
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "unable to get modification time for file %s", path)
+	}
+
+	return attrs.Updated, nil
+}
+
+func (b *GCSFileBackend) CopyFile(oldPath, newPath string) error {
+	oldPath = b.prefixedPath(oldPath)
+	newPath = b.prefixedPath(newPath)
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+	if _, err := b.object(newPath).CopierFrom(b.object(oldPath)).Run(ctx); err != nil {
+		return errors.Wrapf(err, "unable to copy file from %s to %s", oldPath, newPath)
+	}
+
+	return nil
+}
+
+func (b *GCSFileBackend) MoveFile(oldPath, newPath string) error {
+	if err := b.CopyFile(oldPath, newPath); err != nil {
+		return err
+	}
+
+	if err := b.RemoveFile(oldPath); err != nil {
+		return errors.Wrapf(err, "unable to remove the file old file %s", oldPath)
+	}
+
+	return nil
+}
+
+func (b *GCSFileBackend) WriteFile(fr io.Reader, path string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+
+	return b.WriteFileContext(ctx, fr, path)
+}
+
+func (b *GCSFileBackend) WriteFileContext(ctx context.Context, fr io.Reader, path string) (int64, error) {
+	path = b.prefixedPath(path)
+
+	// Closing the writer commits the object, so it's discarded by canceling its context instead.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	writer := b.object(path).NewWriter(ctx)
+	writer.ContentType = contentTypeForPath(path)
+
+	written, err := io.Copy(writer, fr)
+	if err != nil {
+		cancel()
+		return written, errors.Wrapf(err, "unable write the data in the file %s", path)
+	}
+
+	if err := writer.Close(); err != nil {
+		return written, errors.Wrapf(err, "unable write the data in the file %s", path)
+	}
+
+	return written, nil
+}
+
+func (b *GCSFileBackend) AppendFile(fr io.Reader, path string) (int64, error) {
+	fp := b.prefixedPath(path)
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+	if _, err := b.object(fp).Attrs(ctx); err != nil {
+		return 0, errors.Wrapf(err, "unable to find the file %s to append the data", path)
+	}
+
+	partName := path + ".part"
+	written, err := b.WriteFile(fr, partName)
+	if err != nil {
+		return 0, errors.Wrapf(err, "unable append the data in the file %s", path)
+	}
+	defer b.RemoveFile(partName)
+
+	ctx2, cancel2 := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel2()
+	composer := b.object(fp).ComposerFrom(b.object(fp), b.object(b.prefixedPath(partName)))
+	composer.ContentType = contentTypeForPath(fp)
+	if _, err := composer.Run(ctx2); err != nil {
+		return 0, errors.Wrapf(err, "unable append the data in the file %s", path)
+	}
+
+	return written, nil
+}
+
+func (b *GCSFileBackend) RemoveFile(path string) error {
+	path = b.prefixedPath(path)
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+	if err := b.object(path).Delete(ctx); err != nil && !errors.Is(err, storage.ErrObjectNotExist) {
+		return errors.Wrapf(err, "unable to remove the file %s", path)
+	}
+
+	return nil
+}
+
+func (b *GCSFileBackend) listDirectory(path string, recursion bool) ([]string, error) {
+	path = b.prefixedPath(path)
+	if !strings.HasSuffix(path, "/") && path != "" {
+		// Appending "/" to make it consistent across all filestores
+		path = path + "/"
+	}
+
+	query := &storage.Query{Prefix: path}
+	if !recursion {
+		query.Delimiter = "/"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+
+	var paths []string
+	it := b.client.Bucket(b.bucket).Objects(ctx, query)
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to list the directory %s", path)
+		}
+
+		// Objects are returned with their name, and directories with their prefix.
+		name := attrs.Name
+		if name == "" {
+			name = attrs.Prefix
+		}
+
+		// We strip the path prefix that gets applied,
+		// so that it remains transparent to the application.
+		trimmed := strings.Trim(strings.TrimPrefix(name, b.pathPrefix), "/")
+		if trimmed != "" {
+			paths = append(paths, trimmed)
+		}
+	}
+
+	return paths, nil
+}
+
+func (b *GCSFileBackend) ListDirectory(path string) ([]string, error) {
+	return b.listDirectory(path, false)
+}
+
+func (b *GCSFileBackend) ListDirectoryRecursively(path string) ([]string, error) {
+	return b.listDirectory(path, true)
+}
+
+func (b *GCSFileBackend) RemoveDirectory(path string) error {
+	path = b.prefixedPath(path)
+	if !strings.HasSuffix(path, "/") && path != "" {
+		path = path + "/"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+
+	it := b.client.Bucket(b.bucket).Objects(ctx, &storage.Query{Prefix: path})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return errors.Wrapf(err, "unable to remove the directory %s", path)
+		}
+
+		if err := b.object(attrs.Name).Delete(ctx); err != nil && !errors.Is(err, storage.ErrObjectNotExist) {
+			return errors.Wrapf(err, "unable to remove the directory %s", path)
+		}
+	}
+
+	return nil
+}
+
+// ZipReader will create a zip of path. If path is a single file, it will zip the single file.
+// If deflate is true, the contents will be compressed. It will stream the zip to io.ReadCloser.
+func (b *GCSFileBackend) ZipReader(path string, deflate bool) (io.ReadCloser, error) {
+	deflateMethod := zip.Store
+	if deflate {
+		deflateMethod = zip.Deflate
+	}
+
+	path = b.prefixedPath(path)
+
+	pr, pw := io.Pipe()
+
+	go func() {
+		defer pw.Close()
+
+		zipWriter := zip.NewWriter(pw)
+		defer zipWriter.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+		defer cancel()
+
+		// Is path a single file?
+		if attrs, err := b.object(path).Attrs(ctx); err == nil {
+			// We want the zipped file to be at the root of the zip.
+			stripPath := filepath.Dir(path)
+			if stripPath != "" {
+				stripPath += "/"
+			}
+			if err := b.copyObjectToZipWriter(zipWriter, attrs, stripPath, deflateMethod); err != nil {
+				pw.CloseWithError(err)
+			}
+			return
+		}
+
+		// Is path a directory?
+		path = path + "/"
+		it := b.client.Bucket(b.bucket).Objects(ctx, &storage.Query{Prefix: path})
+		for {
+			attrs, err := it.Next()
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				pw.CloseWithError(errors.Wrapf(err, "unable to list the directory %s", path))
+				return
+			}
+
+			if err := b.copyObjectToZipWriter(zipWriter, attrs, path, deflateMethod); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+	}()
+
+	return pr, nil
+}
+
+func (b *GCSFileBackend) copyObjectToZipWriter(zipWriter *zip.Writer, attrs *storage.ObjectAttrs, stripPath string, deflateMethod uint16) error {
+	header := &zip.FileHeader{
+		Name:     strings.TrimPrefix(attrs.Name, stripPath),
+		Method:   deflateMethod,
+		Modified: attrs.Updated,
+	}
+	header.SetMode(0644) // rw-r--r-- permissions
+
+	writer, err := zipWriter.CreateHeader(header)
+	if err != nil {
+		return errors.Wrapf(err, "unable to create zip entry for %s", attrs.Name)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+	reader, err := b.object(attrs.Name).NewReader(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "unable to create reader for %s", attrs.Name)
+	}
+	defer reader.Close()
+
+	if _, err := io.Copy(writer, reader); err != nil {
+		return errors.Wrapf(err, "unable to copy content for %s", attrs.Name)
+	}
+
+	return nil
+}
+
+func (b *GCSFileBackend) GeneratePublicLink(path string) (string, time.Duration, error) {
+	path = b.prefixedPath(path)
+
+	link, err := b.client.Bucket(b.bucket).SignedURL(path, &storage.SignedURLOptions{
+		Scheme:          storage.SigningSchemeV4,
+		Method:          "GET",
+		Expires:         time.Now().Add(b.presignExpires),
+		QueryParameters: url.Values{"response-content-disposition": []string{"attachment"}},
+	})
+	if err != nil {
+		return "", 0, errors.Wrapf(err, "unable to generate public link for %s", path)
+	}
+
+	return link, b.presignExpires, nil
+}
+
+// contentTypeForPath returns the content type stored along with the file, matching the S3 backend.
+func contentTypeForPath(path string) string {
+	if ext := filepath.Ext(path); isFileExtImage(ext) {
+		return getImageMimeType(ext)
+	}
+	return "binary/octet-stream"
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package filestore
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewGCSFileBackend(t *testing.T) {
+	_, err := NewGCSFileBackend(FileBackendSettings{DriverName: driverGCS})
+	require.Error(t, err)
+	require.Equal(t, "missing google cloud storage bucket settings", err.Error())
+}
+
+func TestGCSGeneratePublicLink(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	credentials, err := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"project_id":   "mattermost-test",
+		"client_email": "mattermost@mattermost-test.iam.gserviceaccount.com",
+		"client_id":    "1",
+		"private_key": string(pem.EncodeToMemory(&pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(key),
+		})),
+		"token_uri": "https://oauth2.googleapis.com/token",
+	})
+	require.NoError(t, err)
+
+	backend, err := NewGCSFileBackend(FileBackendSettings{
+		DriverName:                                   driverGCS,
+		GoogleCloudStorageBucket:                     "mattermost-test",
+		GoogleCloudStoragePathPrefix:                 "prefix",
+		GoogleCloudStorageCredentialsJSON:            string(credentials),
+		GoogleCloudStoragePresignExpiresSeconds:      60,
+		GoogleCloudStorageRequestTimeoutMilliseconds: 5000,
+	})
+	require.NoError(t, err)
+
+	link, expires, err := backend.GeneratePublicLink("exports/export.zip")
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, expires)
+
+	u, err := url.Parse(link)
+	require.NoError(t, err)
+	assert.Contains(t, u.Path, "/mattermost-test/prefix/exports/export.zip")
+	assert.Equal(t, "attachment", u.Query().Get("response-content-disposition"))
+	assert.NotEmpty(t, u.Query().Get("X-Goog-Expires"))
+	assert.NotEmpty(t, u.Query().Get("X-Goog-Signature"))
+}
+
+type failingReader struct {
+	data []byte
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, errors.New("read failure")
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestGCSWriteFileFailingReader(t *testing.T) {
+	// A minimal fake of the JSON API, which only knows whether an object was uploaded.
+	var uploaded atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/upload/"):
+			if _, err := io.ReadAll(r.Body); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			uploaded.Store(true)
+			w.Write([]byte(`{"bucket":"mattermost-test","name":"upload.txt","size":"0"}`))
+		case r.Method == http.MethodGet && uploaded.Load():
+			w.Write([]byte(`{"bucket":"mattermost-test","name":"upload.txt","size":"0"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"code":404,"message":"not found"}}`))
+		}
+	}))
+	defer server.Close()
+
+	backend, err := NewGCSFileBackend(FileBackendSettings{
+		DriverName:                                   driverGCS,
+		GoogleCloudStorageBucket:                     "mattermost-test",
+		GoogleCloudStorageEndpoint:                   server.URL + "/storage/v1/",
+		GoogleCloudStorageRequestTimeoutMilliseconds: 5000,
+	})
+	require.NoError(t, err)
+
+	_, err = backend.WriteFile(&failingReader{data: []byte("truncated content")}, "upload.txt")
+	require.Error(t, err)
+
+	exists, err := backend.FileExists("upload.txt")
+	require.NoError(t, err)
+	assert.False(t, exists, "a failed write should not leave a truncated object")
+
+	_, err = backend.WriteFile(strings.NewReader("content"), "upload.txt")
+	require.NoError(t, err)
+
+	exists, err = backend.FileExists("upload.txt")
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
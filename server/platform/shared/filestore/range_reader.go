@@ -0,0 +1,129 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package filestore
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// openRangeFunc opens a reader on length bytes of the object starting at the given
+// offset. A negative length reads up to the end of the object.
+type openRangeFunc func(ctx context.Context, offset, length int64) (io.ReadCloser, error)
+
+// rangeReader turns the streaming readers returned by object stores into a
+// ReadCloseSeeker. Seeking closes the current stream, and the next read opens
+// a new one at the requested offset. ReadAt opens a separate stream for every
+// call, so it doesn't move the offset of Read.
+type rangeReader struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	timer  *time.Timer
+	open   openRangeFunc
+	size   int64
+	offset int64
+	reader io.ReadCloser
+}
+
+var (
+	_ ReadCloseSeeker = (*rangeReader)(nil)
+	_ io.ReaderAt     = (*rangeReader)(nil)
+)
+
+func newRangeReader(size int64, timeout time.Duration, open openRangeFunc) *rangeReader {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &rangeReader{
+		ctx:    ctx,
+		cancel: cancel,
+		timer:  time.AfterFunc(timeout, cancel),
+		open:   open,
+		size:   size,
+	}
+}
+
+func (r *rangeReader) Read(p []byte) (int, error) {
+	if r.offset >= r.size {
+		return 0, io.EOF
+	}
+
+	if r.reader == nil {
+		reader, err := r.open(r.ctx, r.offset, -1)
+		if err != nil {
+			return 0, err
+		}
+		r.reader = reader
+	}
+
+	n, err := r.reader.Read(p)
+	r.offset += int64(n)
+	return n, err
+}
+
+func (r *rangeReader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+	if off >= r.size {
+		return 0, io.EOF
+	}
+
+	length := min(int64(len(p)), r.size-off)
+	reader, err := r.open(r.ctx, off, length)
+	if err != nil {
+		return 0, err
+	}
+	defer reader.Close()
+
+	n, err := io.ReadFull(reader, p[:length])
+	if err == nil && int(length) < len(p) {
+		err = io.EOF
+	}
+	return n, err
+}
+
+func (r *rangeReader) Seek(offset int64, whence int) (int64, error) {
+	var abs int64
+	switch whence {
+	case io.SeekStart:
+		abs = offset
+	case io.SeekCurrent:
+		abs = r.offset + offset
+	case io.SeekEnd:
+		abs = r.size + offset
+	default:
+		return 0, errors.New("invalid whence")
+	}
+
+	if abs < 0 {
+		return 0, errors.New("negative position")
+	}
+
+	if abs != r.offset && r.reader != nil {
+		r.reader.Close()
+		r.reader = nil
+	}
+	r.offset = abs
+
+	return abs, nil
+}
+
+func (r *rangeReader) Close() error {
+	r.timer.Stop()
+	defer r.cancel()
+
+	if r.reader != nil {
+		return r.reader.Close()
+	}
+	return nil
+}
+
+// CancelTimeout attempts to cancel the timeout for this reader. It allows calling
+// code to ignore the timeout in case of longer running operations. The methods returns
+// false if the timeout has already fired.
+func (r *rangeReader) CancelTimeout() bool {
+	return r.timer.Stop()
+}
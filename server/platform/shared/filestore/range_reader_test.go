@@ -0,0 +1,91 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package filestore
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRangeReader(t *testing.T) {
+	data := []byte("0123456789abcdefghij")
+
+	var opened int
+	newReader := func() *rangeReader {
+		return newRangeReader(int64(len(data)), time.Minute, func(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+			opened++
+			end := int64(len(data))
+			if length >= 0 {
+				end = offset + length
+			}
+			return io.NopCloser(bytes.NewReader(data[offset:end])), nil
+		})
+	}
+
+	t.Run("read and seek", func(t *testing.T) {
+		r := newReader()
+		defer r.Close()
+
+		buf := make([]byte, 4)
+		_, err := io.ReadFull(r, buf)
+		require.NoError(t, err)
+		assert.Equal(t, "0123", string(buf))
+
+		pos, err := r.Seek(-4, io.SeekEnd)
+		require.NoError(t, err)
+		assert.Equal(t, int64(16), pos)
+
+		rest, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t, "ghij", string(rest))
+	})
+
+	t.Run("read at", func(t *testing.T) {
+		r := newReader()
+		defer r.Close()
+
+		buf := make([]byte, 5)
+		n, err := r.ReadAt(buf, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 5, n)
+		assert.Equal(t, "abcde", string(buf))
+
+		n, err = r.ReadAt(buf, 18)
+		assert.Equal(t, io.EOF, err)
+		assert.Equal(t, 2, n)
+		assert.Equal(t, "ij", string(buf[:n]))
+
+		n, err = r.ReadAt(buf, 20)
+		assert.Equal(t, io.EOF, err)
+		assert.Zero(t, n)
+
+		_, err = r.ReadAt(buf, -1)
+		assert.Error(t, err)
+	})
+
+	t.Run("read at doesn't move the offset", func(t *testing.T) {
+		r := newReader()
+		defer r.Close()
+
+		buf := make([]byte, 2)
+		_, err := io.ReadFull(r, buf)
+		require.NoError(t, err)
+
+		opened = 0
+		_, err = r.ReadAt(make([]byte, 3), 15)
+		require.NoError(t, err)
+		assert.Equal(t, 1, opened)
+
+		_, err = io.ReadFull(r, buf)
+		require.NoError(t, err)
+		assert.Equal(t, "23", string(buf))
+		assert.Equal(t, 1, opened)
+	})
+}
@@ -52,6 +52,7 @@ type mailData struct {
 	replyTo       mail.Address
 	subject       string
 	htmlBody      string
+	textBody      string
 	embeddedFiles map[string]io.Reader
 	mimeHeaders   map[string]string
 	messageID     string
@@ -236,6 +237,12 @@ func TestConnection(config *SMTPConfig) error {
 }
 
 func SendMailWithEmbeddedFilesUsingConfig(to, subject, htmlBody string, embeddedFiles map[string]io.Reader, config *SMTPConfig, enableComplianceFeatures bool, messageID string, inReplyTo string, references string, ccMail string, category string) error {
+	return SendMailWithTextBodyUsingConfig(to, subject, htmlBody, "", embeddedFiles, config, messageID, inReplyTo, references, ccMail, category)
+}
+
+// SendMailWithTextBodyUsingConfig sends an email with the given plain text alternative to its
+// HTML body. When textBody is empty, the plain text alternative is derived from the HTML body.
+func SendMailWithTextBodyUsingConfig(to, subject, htmlBody, textBody string, embeddedFiles map[string]io.Reader, config *SMTPConfig, messageID string, inReplyTo string, references string, ccMail string, category string) error {
 	fromMail := mail.Address{Name: config.FeedbackName, Address: config.FeedbackEmail}
 	replyTo := mail.Address{Name: config.FeedbackName, Address: config.ReplyToAddress}
 
@@ -247,6 +254,7 @@ func SendMailWithEmbeddedFilesUsingConfig(to, subject, htmlBody string, embedded
 		replyTo:       replyTo,
 		subject:       subject,
 		htmlBody:      htmlBody,
+		textBody:      textBody,
 		embeddedFiles: embeddedFiles,
 		messageID:     messageID,
 		inReplyTo:     inReplyTo,
@@ -289,6 +297,16 @@ func sendMailUsingConfigAdvanced(mail mailData, config *SMTPConfig) error {
 	return sendMail(c, mail, time.Now(), config)
 }
 
+// HTMLToText converts an HTML email body to its plain text alternative.
+func HTMLToText(htmlBody string) string {
+	txtBody, err := html2text.FromString(htmlBody)
+	if err != nil {
+		mlog.Warn("Unable to convert html body to text", mlog.Err(err))
+		return ""
+	}
+	return txtBody
+}
+
 const SendGridXSMTPAPIHeader = "X-SMTPAPI"
 
 func sendMail(c smtpClient, mail mailData, date time.Time, config *SMTPConfig) error {
@@ -296,10 +314,9 @@ func sendMail(c smtpClient, mail mailData, date time.Time, config *SMTPConfig) e
 
 	htmlMessage := mail.htmlBody
 
-	txtBody, err := html2text.FromString(mail.htmlBody)
-	if err != nil {
-		mlog.Warn("Unable to convert html body to text", mlog.Err(err))
-		txtBody = ""
+	txtBody := mail.textBody
+	if txtBody == "" {
+		txtBody = HTMLToText(mail.htmlBody)
 	}
 
 	headers := map[string][]string{
@@ -354,11 +371,11 @@ func sendMail(c smtpClient, mail mailData, date time.Time, config *SMTPConfig) e
 		m.EmbedReader(name, reader)
 	}
 
-	if err = c.Mail(mail.from.Address); err != nil {
+	if err := c.Mail(mail.from.Address); err != nil {
 		return errors.Wrap(err, "failed to set the from address")
 	}
 
-	if err = c.Rcpt(mail.smtpTo); err != nil {
+	if err := c.Rcpt(mail.smtpTo); err != nil {
 		return errors.Wrap(err, "failed to set the to address")
 	}
 
@@ -407,7 +407,7 @@ func TestSendMail(t *testing.T) {
 
 	for testName, tc := range testCases {
 		t.Run(testName, func(t *testing.T) {
-			mail := mailData{"", "", mail.Address{}, "", tc.replyTo, "", "", nil, nil, tc.messageID, tc.inReplyTo, tc.references, ""}
+			mail := mailData{"", "", mail.Address{}, "", tc.replyTo, "", "", "", nil, nil, tc.messageID, tc.inReplyTo, tc.references, ""}
 			cfg := getConfig()
 			err = sendMail(mocm, mail, time.Now(), cfg)
 			require.NoError(t, err)
@@ -421,3 +421,23 @@ func TestSendMail(t *testing.T) {
 		})
 	}
 }
+
+func TestSendMailTextBody(t *testing.T) {
+	mocm := &mockMailer{}
+	cfg := getConfig()
+
+	t.Run("derives the text body from the html body", func(t *testing.T) {
+		mocm.data = []byte{}
+		err := sendMail(mocm, mailData{htmlBody: "<p>Hello <b>world</b></p>"}, time.Now(), cfg)
+		require.NoError(t, err)
+		require.Contains(t, string(mocm.data), "Content-Type: text/plain; charset=UTF-8\r\n\r\nHello *world*")
+	})
+
+	t.Run("uses the given text body", func(t *testing.T) {
+		mocm.data = []byte{}
+		err := sendMail(mocm, mailData{htmlBody: "<p>Hello <b>world</b></p>", textBody: "Plain hello"}, time.Now(), cfg)
+		require.NoError(t, err)
+		require.Contains(t, string(mocm.data), "Content-Type: text/plain; charset=UTF-8\r\n\r\nPlain hello")
+		require.Contains(t, string(mocm.data), "<p>Hello <b>world</b></p>")
+	})
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package mfa
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// RecoveryCodeCount is the number of recovery codes issued to a user at once.
+	RecoveryCodeCount = 10
+
+	// recoveryCodeLength is the number of characters of a recovery code, ignoring the separator.
+	recoveryCodeLength = 10
+
+	// Ambiguous characters (0, o, 1, i, l) are left out so the codes can be safely copied by hand.
+	recoveryCodeAlphabet = "23456789abcdefghjkmnpqrstuvwxyz"
+
+	// recoveryCodeHashCost is the bcrypt cost of the stored hashes, the same as for passwords.
+	recoveryCodeHashCost = 10
+)
+
+// RecoveryCodeStore persists the hashes of the recovery codes issued to users.
+type RecoveryCodeStore interface {
+	ReplaceForUser(userID string, codeHashes []string) error
+	GetForUser(userID string) ([]string, error)
+	Consume(userID, codeHash string) (bool, error)
+}
+
+// RecoveryCodes issues and validates single-use recovery codes, which let a user
+// log in when their authenticator is not available.
+type RecoveryCodes struct {
+	store RecoveryCodeStore
+}
+
+func NewRecoveryCodes(store RecoveryCodeStore) *RecoveryCodes {
+	return &RecoveryCodes{store}
+}
+
+// IsRecoveryCode reports whether the given mfa token looks like a recovery code
+// rather than a TOTP code.
+func IsRecoveryCode(token string) bool {
+	code := normalizeRecoveryCode(token)
+	if len(code) != recoveryCodeLength {
+		return false
+	}
+
+	for _, r := range code {
+		if !strings.ContainsRune(recoveryCodeAlphabet, r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// hashRecoveryCode hashes the code with bcrypt, as the codes are short enough for unsalted
+// hashes to be brute-forced.
+func hashRecoveryCode(code string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(normalizeRecoveryCode(code)), recoveryCodeHashCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+func newRecoveryCode() (string, error) {
+	var sb strings.Builder
+	max := big.NewInt(int64(len(recoveryCodeAlphabet)))
+	for i := 0; i < recoveryCodeLength; i++ {
+		if i == recoveryCodeLength/2 {
+			sb.WriteByte('-')
+		}
+
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(recoveryCodeAlphabet[n.Int64()])
+	}
+
+	return sb.String(), nil
+}
+
+// Generate issues a new set of recovery codes for the user, invalidating any
+// previously issued ones. Only the hashes of the codes are stored, so the
+// returned codes must be shown to the user right away.
+func (r *RecoveryCodes) Generate(userID string) ([]string, error) {
+	codes := make([]string, 0, RecoveryCodeCount)
+	hashes := make([]string, 0, RecoveryCodeCount)
+	for len(codes) < RecoveryCodeCount {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to generate recovery code")
+		}
+
+		hash, err := hashRecoveryCode(code)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to hash recovery code")
+		}
+
+		codes = append(codes, code)
+		hashes = append(hashes, hash)
+	}
+
+	if err := r.store.ReplaceForUser(userID, hashes); err != nil {
+		return nil, errors.Wrap(err, "unable to store recovery codes")
+	}
+
+	return codes, nil
+}
+
+// Validate checks the recovery code against the ones issued to the user and,
+// if it matches, consumes it. As with ValidateToken, a code that does not
+// match is not an error.
+func (r *RecoveryCodes) Validate(userID, code string) (bool, error) {
+	if !IsRecoveryCode(code) {
+		return false, nil
+	}
+
+	hashes, err := r.store.GetForUser(userID)
+	if err != nil {
+		return false, errors.Wrap(err, "unable to get recovery codes")
+	}
+
+	normalized := []byte(normalizeRecoveryCode(code))
+	for _, hash := range hashes {
+		if bcrypt.CompareHashAndPassword([]byte(hash), normalized) != nil {
+			continue
+		}
+
+		// The code is only valid if it's still there, in case it was used concurrently.
+		ok, err := r.store.Consume(userID, hash)
+		if err != nil {
+			return false, errors.Wrap(err, "unable to consume recovery code")
+		}
+		return ok, nil
+	}
+
+	return false, nil
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package mfa
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+type testRecoveryCodeStore struct {
+	hashes map[string][]string
+}
+
+func (s *testRecoveryCodeStore) ReplaceForUser(userID string, codeHashes []string) error {
+	s.hashes[userID] = codeHashes
+	return nil
+}
+
+func (s *testRecoveryCodeStore) GetForUser(userID string) ([]string, error) {
+	return s.hashes[userID], nil
+}
+
+func (s *testRecoveryCodeStore) Consume(userID, codeHash string) (bool, error) {
+	for i, h := range s.hashes[userID] {
+		if h == codeHash {
+			s.hashes[userID] = append(s.hashes[userID][:i], s.hashes[userID][i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestIsRecoveryCode(t *testing.T) {
+	assert.True(t, IsRecoveryCode("abcde-fghjk"))
+	assert.True(t, IsRecoveryCode(" ABCDE-FGHJK "))
+	assert.True(t, IsRecoveryCode("abcdefghjk"))
+	assert.False(t, IsRecoveryCode("123456"))
+	assert.False(t, IsRecoveryCode("abcde-fghjl"))
+	assert.False(t, IsRecoveryCode(""))
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	userID := model.NewId()
+
+	t.Run("generate and validate", func(t *testing.T) {
+		s := &testRecoveryCodeStore{hashes: map[string][]string{}}
+		r := NewRecoveryCodes(s)
+
+		codes, err := r.Generate(userID)
+		require.NoError(t, err)
+		require.Len(t, codes, RecoveryCodeCount)
+		require.Len(t, s.hashes[userID], RecoveryCodeCount)
+
+		for _, code := range codes {
+			assert.Len(t, code, recoveryCodeLength+1)
+			assert.True(t, IsRecoveryCode(code))
+			assert.NotContains(t, s.hashes[userID], code, "codes should not be stored in plain text")
+		}
+		for _, hash := range s.hashes[userID] {
+			cost, err := bcrypt.Cost([]byte(hash))
+			require.NoError(t, err, "codes should be hashed with bcrypt")
+			assert.Equal(t, recoveryCodeHashCost, cost)
+		}
+
+		ok, err := r.Validate(userID, strings.ToUpper(codes[0]))
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		ok, err = r.Validate(userID, codes[0])
+		require.NoError(t, err)
+		require.False(t, ok, "recovery codes should be single use")
+
+		ok, err = r.Validate(model.NewId(), codes[1])
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		ok, err = r.Validate(userID, "123456")
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("regenerating invalidates previous codes", func(t *testing.T) {
+		s := &testRecoveryCodeStore{hashes: map[string][]string{}}
+		r := NewRecoveryCodes(s)
+
+		old, err := r.Generate(userID)
+		require.NoError(t, err)
+
+		_, err = r.Generate(userID)
+		require.NoError(t, err)
+
+		ok, err := r.Validate(userID, old[0])
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+}
@@ -0,0 +1,370 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package mfa
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost/server/public/model"
+)
+
+const (
+	TokenTypeWebAuthnRegistration = "webauthn_registration"
+	TokenTypeWebAuthnLogin        = "webauthn_login"
+)
+
+var (
+	// InvalidWebAuthnResponse indicates that the response returned by the
+	// authenticator could not be verified against a pending ceremony.
+	InvalidWebAuthnResponse = errors.New("invalid webauthn response")
+
+	// NoWebAuthnCredentials indicates that the user has no registered security keys.
+	NoWebAuthnCredentials = errors.New("no webauthn credentials registered")
+)
+
+type WebAuthnCredentialStore interface {
+	Save(credential *model.WebAuthnCredential) (*model.WebAuthnCredential, error)
+	GetForUser(userID string) ([]*model.WebAuthnCredential, error)
+	UpdateSignCount(id string, signCount int64, lastUsedAt int64) error
+}
+
+// WebAuthnSessionStore persists the state of pending registration and login
+// ceremonies, so that they can be completed on any node of a cluster.
+type WebAuthnSessionStore interface {
+	Save(token *model.Token) error
+	GetByToken(token string) (*model.Token, error)
+	Delete(token string) error
+}
+
+type WebAuthn struct {
+	webauthn    *webauthn.WebAuthn
+	credentials WebAuthnCredentialStore
+	sessions    WebAuthnSessionStore
+}
+
+type webAuthnSession struct {
+	UserId  string               `json:"user_id"`
+	Session webauthn.SessionData `json:"session"`
+}
+
+// webAuthnUser adapts a user and its stored credentials to the webauthn.User interface.
+type webAuthnUser struct {
+	user        *model.User
+	credentials []webauthn.Credential
+}
+
+func (u *webAuthnUser) WebAuthnID() []byte {
+	return []byte(u.user.Id)
+}
+
+func (u *webAuthnUser) WebAuthnName() string {
+	return u.user.Username
+}
+
+func (u *webAuthnUser) WebAuthnDisplayName() string {
+	return u.user.GetDisplayName(model.ShowNicknameFullName)
+}
+
+func (u *webAuthnUser) WebAuthnIcon() string {
+	return ""
+}
+
+func (u *webAuthnUser) WebAuthnCredentials() []webauthn.Credential {
+	return u.credentials
+}
+
+// NewWebAuthn returns a WebAuthn relying party bound to the host and origin of the given site URL.
+func NewWebAuthn(credentials WebAuthnCredentialStore, sessions WebAuthnSessionStore, siteURL, siteName string) (*WebAuthn, error) {
+	u, err := url.Parse(strings.TrimSpace(siteURL))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to parse the site url")
+	}
+	if u.Scheme == "" || u.Hostname() == "" {
+		return nil, errors.New("a site url is required to use webauthn")
+	}
+
+	if siteName == "" {
+		siteName = model.TeamSettingsDefaultSiteName
+	}
+
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          u.Hostname(),
+		RPDisplayName: siteName,
+		RPOrigins:     []string{u.Scheme + "://" + u.Host},
+		Timeouts: webauthn.TimeoutsConfig{
+			Login:        webauthn.TimeoutConfig{Enforce: true},
+			Registration: webauthn.TimeoutConfig{Enforce: true},
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to configure webauthn")
+	}
+
+	return &WebAuthn{
+		webauthn:    w,
+		credentials: credentials,
+		sessions:    sessions,
+	}, nil
+}
+
+// IsWebAuthnAssertion reports whether the given mfa token looks like a serialized
+// WebAuthn assertion rather than a TOTP code.
+func IsWebAuthnAssertion(token string) bool {
+	return strings.HasPrefix(strings.TrimSpace(token), "{")
+}
+
+// BeginRegistration starts the registration of a new security key for the user
+// and returns the options to pass to navigator.credentials.create().
+func (w *WebAuthn) BeginRegistration(user *model.User) (*protocol.CredentialCreation, error) {
+	waUser, err := w.getUser(user)
+	if err != nil {
+		return nil, err
+	}
+
+	exclusions := make([]protocol.CredentialDescriptor, 0, len(waUser.credentials))
+	for _, credential := range waUser.credentials {
+		exclusions = append(exclusions, credential.Descriptor())
+	}
+
+	creation, session, err := w.webauthn.BeginRegistration(waUser, webauthn.WithExclusions(exclusions))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to begin webauthn registration")
+	}
+
+	if err := w.saveSession(TokenTypeWebAuthnRegistration, user.Id, session); err != nil {
+		return nil, err
+	}
+
+	return creation, nil
+}
+
+// FinishRegistration verifies the response of navigator.credentials.create() and stores the new credential.
+func (w *WebAuthn) FinishRegistration(user *model.User, name string, response []byte) (*model.WebAuthnCredential, error) {
+	parsed, err := protocol.ParseCredentialCreationResponseBody(bytes.NewReader(response))
+	if err != nil {
+		return nil, errors.Wrap(InvalidWebAuthnResponse, err.Error())
+	}
+
+	session, err := w.consumeSession(TokenTypeWebAuthnRegistration, user.Id, parsed.Response.CollectedClientData.Challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	waUser, err := w.getUser(user)
+	if err != nil {
+		return nil, err
+	}
+
+	credential, err := w.webauthn.CreateCredential(waUser, *session, parsed)
+	if err != nil {
+		return nil, errors.Wrap(InvalidWebAuthnResponse, err.Error())
+	}
+
+	transports := make(model.StringArray, 0, len(credential.Transport))
+	for _, transport := range credential.Transport {
+		transports = append(transports, string(transport))
+	}
+
+	saved, err := w.credentials.Save(&model.WebAuthnCredential{
+		UserId:          user.Id,
+		Name:            strings.TrimSpace(name),
+		CredentialId:    base64.RawURLEncoding.EncodeToString(credential.ID),
+		PublicKey:       base64.StdEncoding.EncodeToString(credential.PublicKey),
+		AttestationType: credential.AttestationType,
+		Transports:      transports,
+		AAGUID:          hex.EncodeToString(credential.Authenticator.AAGUID),
+		SignCount:       int64(credential.Authenticator.SignCount),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to store webauthn credential")
+	}
+
+	return saved, nil
+}
+
+// BeginLogin starts a login ceremony for the user and returns the options to
+// pass to navigator.credentials.get().
+func (w *WebAuthn) BeginLogin(user *model.User) (*protocol.CredentialAssertion, error) {
+	waUser, err := w.getUser(user)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(waUser.credentials) == 0 {
+		return nil, NoWebAuthnCredentials
+	}
+
+	assertion, session, err := w.webauthn.BeginLogin(waUser)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to begin webauthn login")
+	}
+
+	if err := w.saveSession(TokenTypeWebAuthnLogin, user.Id, session); err != nil {
+		return nil, err
+	}
+
+	return assertion, nil
+}
+
+// ValidateAssertion verifies the response of navigator.credentials.get() against a pending
+// login ceremony. As with ValidateToken, an assertion that fails verification is not an error.
+func (w *WebAuthn) ValidateAssertion(user *model.User, assertion string) (bool, error) {
+	parsed, err := protocol.ParseCredentialRequestResponseBody(strings.NewReader(assertion))
+	if err != nil {
+		return false, nil
+	}
+
+	session, err := w.consumeSession(TokenTypeWebAuthnLogin, user.Id, parsed.Response.CollectedClientData.Challenge)
+	if err != nil {
+		if errors.Is(err, InvalidWebAuthnResponse) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	waUser, err := w.getUser(user)
+	if err != nil {
+		return false, err
+	}
+
+	credential, err := w.webauthn.ValidateLogin(waUser, *session, parsed)
+	if err != nil {
+		return false, nil
+	}
+
+	// A counter that did not increase suggests that the authenticator was cloned.
+	if credential.Authenticator.CloneWarning {
+		return false, nil
+	}
+
+	stored, err := w.credentials.GetForUser(user.Id)
+	if err != nil {
+		return true, errors.Wrap(err, "unable to retrieve webauthn credentials")
+	}
+
+	credentialID := base64.RawURLEncoding.EncodeToString(credential.ID)
+	for _, c := range stored {
+		if c.CredentialId == credentialID {
+			if err := w.credentials.UpdateSignCount(c.Id, int64(credential.Authenticator.SignCount), model.GetMillis()); err != nil {
+				return true, errors.Wrap(err, "unable to store the webauthn sign count")
+			}
+			break
+		}
+	}
+
+	return true, nil
+}
+
+func (w *WebAuthn) getUser(user *model.User) (*webAuthnUser, error) {
+	stored, err := w.credentials.GetForUser(user.Id)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to retrieve webauthn credentials")
+	}
+
+	credentials := make([]webauthn.Credential, 0, len(stored))
+	for _, c := range stored {
+		id, err := base64.RawURLEncoding.DecodeString(c.CredentialId)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to decode webauthn credential id=%s", c.Id)
+		}
+
+		publicKey, err := base64.StdEncoding.DecodeString(c.PublicKey)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to decode webauthn credential id=%s", c.Id)
+		}
+
+		aaguid, _ := hex.DecodeString(c.AAGUID)
+
+		transports := make([]protocol.AuthenticatorTransport, 0, len(c.Transports))
+		for _, transport := range c.Transports {
+			transports = append(transports, protocol.AuthenticatorTransport(transport))
+		}
+
+		credentials = append(credentials, webauthn.Credential{
+			ID:              id,
+			PublicKey:       publicKey,
+			AttestationType: c.AttestationType,
+			Transport:       transports,
+			Authenticator: webauthn.Authenticator{
+				AAGUID:    aaguid,
+				SignCount: uint32(c.SignCount),
+			},
+		})
+	}
+
+	return &webAuthnUser{user: user, credentials: credentials}, nil
+}
+
+// sessionKey derives the token under which a ceremony is stored from its challenge,
+// which the client echoes back in its response.
+func sessionKey(challenge string) string {
+	sum := sha256.Sum256([]byte(challenge))
+	return hex.EncodeToString(sum[:])
+}
+
+func (w *WebAuthn) saveSession(tokenType, userID string, session *webauthn.SessionData) error {
+	// The credentials are looked up again when the ceremony completes, so there
+	// is no need to store them, and they could overflow the token's extra field.
+	session.AllowedCredentialIDs = nil
+
+	extra, err := json.Marshal(webAuthnSession{UserId: userID, Session: *session})
+	if err != nil {
+		return errors.Wrap(err, "unable to serialize webauthn session")
+	}
+
+	token := &model.Token{
+		Token:    sessionKey(session.Challenge),
+		CreateAt: model.GetMillis(),
+		Type:     tokenType,
+		Extra:    string(extra),
+	}
+
+	if err := w.sessions.Save(token); err != nil {
+		return errors.Wrap(err, "unable to store webauthn session")
+	}
+
+	return nil
+}
+
+// consumeSession returns the pending ceremony matching the given challenge. Ceremonies are single use.
+func (w *WebAuthn) consumeSession(tokenType, userID, challenge string) (*webauthn.SessionData, error) {
+	if challenge == "" {
+		return nil, InvalidWebAuthnResponse
+	}
+
+	key := sessionKey(challenge)
+	token, err := w.sessions.GetByToken(key)
+	if err != nil {
+		return nil, errors.Wrap(InvalidWebAuthnResponse, err.Error())
+	}
+
+	if err := w.sessions.Delete(key); err != nil {
+		return nil, errors.Wrap(err, "unable to delete webauthn session")
+	}
+
+	if token.Type != tokenType {
+		return nil, InvalidWebAuthnResponse
+	}
+
+	var session webAuthnSession
+	if err := json.Unmarshal([]byte(token.Extra), &session); err != nil {
+		return nil, errors.Wrap(err, "unable to deserialize webauthn session")
+	}
+
+	if session.UserId != userID {
+		return nil, InvalidWebAuthnResponse
+	}
+
+	return &session.Session, nil
+}
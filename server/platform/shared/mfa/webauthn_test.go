@@ -0,0 +1,323 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package mfa
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost/server/public/model"
+	"github.com/mattermost/mattermost/server/v8/channels/store"
+)
+
+type testWebAuthnCredentialStore struct {
+	credentials []*model.WebAuthnCredential
+}
+
+func (s *testWebAuthnCredentialStore) Save(credential *model.WebAuthnCredential) (*model.WebAuthnCredential, error) {
+	credential.PreSave()
+	if err := credential.IsValid(); err != nil {
+		return nil, err
+	}
+	s.credentials = append(s.credentials, credential)
+	return credential, nil
+}
+
+func (s *testWebAuthnCredentialStore) GetForUser(userID string) ([]*model.WebAuthnCredential, error) {
+	credentials := []*model.WebAuthnCredential{}
+	for _, c := range s.credentials {
+		if c.UserId == userID {
+			credentials = append(credentials, c)
+		}
+	}
+	return credentials, nil
+}
+
+func (s *testWebAuthnCredentialStore) UpdateSignCount(id string, signCount int64, lastUsedAt int64) error {
+	for _, c := range s.credentials {
+		if c.Id == id {
+			c.SignCount = signCount
+			c.LastUsedAt = lastUsedAt
+		}
+	}
+	return nil
+}
+
+type testWebAuthnSessionStore struct {
+	tokens map[string]*model.Token
+}
+
+func (s *testWebAuthnSessionStore) Save(token *model.Token) error {
+	if err := token.IsValid(); err != nil {
+		return err
+	}
+	s.tokens[token.Token] = token
+	return nil
+}
+
+func (s *testWebAuthnSessionStore) GetByToken(token string) (*model.Token, error) {
+	t, ok := s.tokens[token]
+	if !ok {
+		return nil, store.NewErrNotFound("Token", token)
+	}
+	return t, nil
+}
+
+func (s *testWebAuthnSessionStore) Delete(token string) error {
+	delete(s.tokens, token)
+	return nil
+}
+
+// testAuthenticator is a software security key producing "none" attestations.
+type testAuthenticator struct {
+	key     *ecdsa.PrivateKey
+	id      []byte
+	counter uint32
+	rpID    string
+	origin  string
+}
+
+func newTestAuthenticator(t *testing.T, rpID, origin string) *testAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	return &testAuthenticator{
+		key:    key,
+		id:     []byte(model.NewId()),
+		rpID:   rpID,
+		origin: origin,
+	}
+}
+
+func (a *testAuthenticator) clientData(t *testing.T, ceremony protocol.CeremonyType, challenge string) []byte {
+	data, err := json.Marshal(map[string]string{
+		"type":      string(ceremony),
+		"challenge": challenge,
+		"origin":    a.origin,
+	})
+	require.NoError(t, err)
+	return data
+}
+
+func (a *testAuthenticator) authData(flags protocol.AuthenticatorFlags) []byte {
+	rpIDHash := sha256.Sum256([]byte(a.rpID))
+	data := append([]byte{}, rpIDHash[:]...)
+	data = append(data, byte(flags))
+	return binary.BigEndian.AppendUint32(data, a.counter)
+}
+
+func (a *testAuthenticator) create(t *testing.T, options *protocol.CredentialCreation) []byte {
+	publicKey, err := webauthncbor.Marshal(webauthncose.EC2PublicKeyData{
+		PublicKeyData: webauthncose.PublicKeyData{
+			KeyType:   int64(webauthncose.EllipticKey),
+			Algorithm: int64(webauthncose.AlgES256),
+		},
+		Curve:  1,
+		XCoord: a.key.X.FillBytes(make([]byte, 32)),
+		YCoord: a.key.Y.FillBytes(make([]byte, 32)),
+	})
+	require.NoError(t, err)
+
+	authData := a.authData(protocol.FlagUserPresent | protocol.FlagAttestedCredentialData)
+	authData = append(authData, make([]byte, 16)...)
+	authData = binary.BigEndian.AppendUint16(authData, uint16(len(a.id)))
+	authData = append(authData, a.id...)
+	authData = append(authData, publicKey...)
+
+	attestationObject, err := webauthncbor.Marshal(map[string]any{
+		"fmt":      "none",
+		"attStmt":  map[string]any{},
+		"authData": authData,
+	})
+	require.NoError(t, err)
+
+	response, err := json.Marshal(map[string]any{
+		"id":    base64.RawURLEncoding.EncodeToString(a.id),
+		"rawId": base64.RawURLEncoding.EncodeToString(a.id),
+		"type":  "public-key",
+		"response": map[string]string{
+			"clientDataJSON":    base64.RawURLEncoding.EncodeToString(a.clientData(t, protocol.CreateCeremony, options.Response.Challenge.String())),
+			"attestationObject": base64.RawURLEncoding.EncodeToString(attestationObject),
+		},
+	})
+	require.NoError(t, err)
+	return response
+}
+
+func (a *testAuthenticator) get(t *testing.T, options *protocol.CredentialAssertion) string {
+	a.counter++
+
+	clientData := a.clientData(t, protocol.AssertCeremony, options.Response.Challenge.String())
+	clientDataHash := sha256.Sum256(clientData)
+	authData := a.authData(protocol.FlagUserPresent)
+
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	require.NoError(t, err)
+
+	response, err := json.Marshal(map[string]any{
+		"id":    base64.RawURLEncoding.EncodeToString(a.id),
+		"rawId": base64.RawURLEncoding.EncodeToString(a.id),
+		"type":  "public-key",
+		"response": map[string]string{
+			"clientDataJSON":    base64.RawURLEncoding.EncodeToString(clientData),
+			"authenticatorData": base64.RawURLEncoding.EncodeToString(authData),
+			"signature":         base64.RawURLEncoding.EncodeToString(signature),
+		},
+	})
+	require.NoError(t, err)
+	return string(response)
+}
+
+func setupWebAuthn(t *testing.T) (*WebAuthn, *testWebAuthnCredentialStore, *testWebAuthnSessionStore) {
+	credentials := &testWebAuthnCredentialStore{}
+	sessions := &testWebAuthnSessionStore{tokens: map[string]*model.Token{}}
+
+	w, err := NewWebAuthn(credentials, sessions, "https://chat.example.com/", "")
+	require.NoError(t, err)
+
+	return w, credentials, sessions
+}
+
+func TestNewWebAuthn(t *testing.T) {
+	_, err := NewWebAuthn(nil, nil, "", "")
+	require.Error(t, err)
+
+	_, err = NewWebAuthn(nil, nil, "chat.example.com", "")
+	require.Error(t, err)
+
+	w, err := NewWebAuthn(nil, nil, "http://localhost:8065/subpath", "Site")
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", w.webauthn.Config.RPID)
+	assert.Equal(t, []string{"http://localhost:8065"}, w.webauthn.Config.RPOrigins)
+	assert.Equal(t, "Site", w.webauthn.Config.RPDisplayName)
+}
+
+func TestIsWebAuthnAssertion(t *testing.T) {
+	assert.True(t, IsWebAuthnAssertion(` {"id": "abc"}`))
+	assert.False(t, IsWebAuthnAssertion("123456"))
+	assert.False(t, IsWebAuthnAssertion(""))
+}
+
+func TestWebAuthnRegistrationAndLogin(t *testing.T) {
+	user := &model.User{Id: model.NewId(), Username: "user"}
+
+	t.Run("register and login with a security key", func(t *testing.T) {
+		w, credentials, sessions := setupWebAuthn(t)
+		authenticator := newTestAuthenticator(t, "chat.example.com", "https://chat.example.com")
+
+		creation, err := w.BeginRegistration(user)
+		require.NoError(t, err)
+		require.Len(t, sessions.tokens, 1)
+
+		credential, err := w.FinishRegistration(user, "my key", authenticator.create(t, creation))
+		require.NoError(t, err)
+		assert.Equal(t, user.Id, credential.UserId)
+		assert.Equal(t, "my key", credential.Name)
+		assert.Equal(t, "none", credential.AttestationType)
+		require.Len(t, credentials.credentials, 1)
+		require.Empty(t, sessions.tokens, "registration session should be single use")
+
+		assertion, err := w.BeginLogin(user)
+		require.NoError(t, err)
+		require.Len(t, assertion.Response.AllowedCredentials, 1)
+
+		ok, err := w.ValidateAssertion(user, authenticator.get(t, assertion))
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, int64(1), credentials.credentials[0].SignCount)
+		assert.NotZero(t, credentials.credentials[0].LastUsedAt)
+	})
+
+	t.Run("assertions cannot be replayed", func(t *testing.T) {
+		w, _, _ := setupWebAuthn(t)
+		authenticator := newTestAuthenticator(t, "chat.example.com", "https://chat.example.com")
+
+		creation, err := w.BeginRegistration(user)
+		require.NoError(t, err)
+		_, err = w.FinishRegistration(user, "my key", authenticator.create(t, creation))
+		require.NoError(t, err)
+
+		assertion, err := w.BeginLogin(user)
+		require.NoError(t, err)
+
+		response := authenticator.get(t, assertion)
+		ok, err := w.ValidateAssertion(user, response)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		ok, err = w.ValidateAssertion(user, response)
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("wrong origin", func(t *testing.T) {
+		w, credentials, _ := setupWebAuthn(t)
+		authenticator := newTestAuthenticator(t, "chat.example.com", "https://evil.example.com")
+
+		creation, err := w.BeginRegistration(user)
+		require.NoError(t, err)
+
+		_, err = w.FinishRegistration(user, "my key", authenticator.create(t, creation))
+		require.ErrorIs(t, err, InvalidWebAuthnResponse)
+		require.Empty(t, credentials.credentials)
+	})
+
+	t.Run("ceremony started for another user", func(t *testing.T) {
+		w, credentials, _ := setupWebAuthn(t)
+		authenticator := newTestAuthenticator(t, "chat.example.com", "https://chat.example.com")
+
+		creation, err := w.BeginRegistration(&model.User{Id: model.NewId(), Username: "other"})
+		require.NoError(t, err)
+
+		_, err = w.FinishRegistration(user, "my key", authenticator.create(t, creation))
+		require.ErrorIs(t, err, InvalidWebAuthnResponse)
+		require.Empty(t, credentials.credentials)
+	})
+
+	t.Run("unknown security key", func(t *testing.T) {
+		w, _, _ := setupWebAuthn(t)
+		authenticator := newTestAuthenticator(t, "chat.example.com", "https://chat.example.com")
+
+		creation, err := w.BeginRegistration(user)
+		require.NoError(t, err)
+		_, err = w.FinishRegistration(user, "my key", authenticator.create(t, creation))
+		require.NoError(t, err)
+
+		assertion, err := w.BeginLogin(user)
+		require.NoError(t, err)
+
+		other := newTestAuthenticator(t, "chat.example.com", "https://chat.example.com")
+		ok, err := w.ValidateAssertion(user, other.get(t, assertion))
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("no registered keys", func(t *testing.T) {
+		w, _, _ := setupWebAuthn(t)
+
+		_, err := w.BeginLogin(user)
+		require.ErrorIs(t, err, NoWebAuthnCredentials)
+	})
+
+	t.Run("malformed assertion", func(t *testing.T) {
+		w, _, _ := setupWebAuthn(t)
+
+		ok, err := w.ValidateAssertion(user, "{not json")
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+}
@@ -0,0 +1,28 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package model
+
+// BatchedEmailNotification is a post a user is notified of by email, waiting to be sent
+// along with the other posts of the batch.
+type BatchedEmailNotification struct {
+	Id       string `json:"id"`
+	UserId   string `json:"user_id"`
+	PostId   string `json:"post_id"`
+	TeamName string `json:"team_name"`
+	CreateAt int64  `json:"create_at"`
+
+	// Post is the post of the notification, loaded along with the batched notifications.
+	// It is nil when the post was deleted.
+	Post *Post `db:"-" json:"-"`
+}
+
+func (o *BatchedEmailNotification) PreSave() {
+	if o.Id == "" {
+		o.Id = NewId()
+	}
+
+	if o.CreateAt == 0 {
+		o.CreateAt = GetMillis()
+	}
+}
@@ -252,6 +252,10 @@ func (c *Client4) teamImportRoute(teamId string) string {
 	return c.teamRoute(teamId) + "/import"
 }
 
+func (c *Client4) teamImportJobRoute(teamId string) string {
+	return c.teamImportRoute(teamId) + "/job"
+}
+
 func (c *Client4) channelsRoute() string {
 	return "/channels"
 }
@@ -433,6 +437,14 @@ func (c *Client4) dataRetentionPolicyRoute(policyID string) string {
 	return fmt.Sprintf(c.dataRetentionRoute()+"/policies/%v", policyID)
 }
 
+func (c *Client4) legalHoldsRoute() string {
+	return "/legal_holds"
+}
+
+func (c *Client4) legalHoldRoute(legalHoldID string) string {
+	return fmt.Sprintf(c.legalHoldsRoute()+"/%v", legalHoldID)
+}
+
 func (c *Client4) elasticsearchRoute() string {
 	return "/elasticsearch"
 }
@@ -948,6 +960,26 @@ func (c *Client4) login(ctx context.Context, m map[string]string) (*User, *Respo
 	return &user, BuildResponse(r), nil
 }
 
+// BeginWebAuthnLogin verifies the user's password and returns the options to pass to
+// navigator.credentials.get(). The serialized assertion is then used as the MFA token
+// of LoginWithMFA.
+func (c *Client4) BeginWebAuthnLogin(ctx context.Context, loginId, password string) (json.RawMessage, *Response, error) {
+	m := make(map[string]string)
+	m["login_id"] = loginId
+	m["password"] = password
+	r, err := c.DoAPIPost(ctx, c.usersRoute()+"/login/webauthn/begin", MapToJSON(m))
+	if err != nil {
+		return nil, BuildResponse(r), err
+	}
+	defer closeBody(r)
+
+	var options json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&options); err != nil {
+		return nil, nil, NewAppError("BeginWebAuthnLogin", "api.unmarshal_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	return options, BuildResponse(r), nil
+}
+
 func (c *Client4) LoginWithDesktopToken(ctx context.Context, token, deviceId string) (*User, *Response, error) {
 	m := make(map[string]string)
 	m["token"] = token
@@ -1618,6 +1650,81 @@ func (c *Client4) GenerateMfaSecret(ctx context.Context, userId string) (*MfaSec
 	return &secret, BuildResponse(r), nil
 }
 
+// RegenerateMfaRecoveryCodes will issue a new set of MFA recovery codes for a user,
+// invalidating the previous ones. Must be logged in as the user.
+func (c *Client4) RegenerateMfaRecoveryCodes(ctx context.Context, userId string) (*MfaRecoveryCodes, *Response, error) {
+	r, err := c.DoAPIPost(ctx, c.userRoute(userId)+"/mfa/recovery_codes", "")
+	if err != nil {
+		return nil, BuildResponse(r), err
+	}
+	defer closeBody(r)
+	var codes MfaRecoveryCodes
+	if err := json.NewDecoder(r.Body).Decode(&codes); err != nil {
+		return nil, nil, NewAppError("RegenerateMfaRecoveryCodes", "api.unmarshal_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	return &codes, BuildResponse(r), nil
+}
+
+// BeginWebAuthnRegistration returns the options to pass to navigator.credentials.create()
+// to register a new security key. Must be logged in as the user.
+func (c *Client4) BeginWebAuthnRegistration(ctx context.Context, userId string) (json.RawMessage, *Response, error) {
+	r, err := c.DoAPIPost(ctx, c.userRoute(userId)+"/mfa/webauthn/register/begin", "")
+	if err != nil {
+		return nil, BuildResponse(r), err
+	}
+	defer closeBody(r)
+
+	var options json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&options); err != nil {
+		return nil, nil, NewAppError("BeginWebAuthnRegistration", "api.unmarshal_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	return options, BuildResponse(r), nil
+}
+
+// FinishWebAuthnRegistration completes the registration of a security key. Must be logged in as the user.
+func (c *Client4) FinishWebAuthnRegistration(ctx context.Context, userId string, registration *WebAuthnRegistration) (*WebAuthnCredential, *Response, error) {
+	buf, err := json.Marshal(registration)
+	if err != nil {
+		return nil, nil, NewAppError("FinishWebAuthnRegistration", "api.marshal_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	r, err := c.DoAPIPostBytes(ctx, c.userRoute(userId)+"/mfa/webauthn/register/finish", buf)
+	if err != nil {
+		return nil, BuildResponse(r), err
+	}
+	defer closeBody(r)
+
+	var credential WebAuthnCredential
+	if err := json.NewDecoder(r.Body).Decode(&credential); err != nil {
+		return nil, nil, NewAppError("FinishWebAuthnRegistration", "api.unmarshal_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	return &credential, BuildResponse(r), nil
+}
+
+// GetWebAuthnCredentials returns the security keys registered by a user.
+func (c *Client4) GetWebAuthnCredentials(ctx context.Context, userId string) ([]*WebAuthnCredential, *Response, error) {
+	r, err := c.DoAPIGet(ctx, c.userRoute(userId)+"/mfa/webauthn/credentials", "")
+	if err != nil {
+		return nil, BuildResponse(r), err
+	}
+	defer closeBody(r)
+
+	var credentials []*WebAuthnCredential
+	if err := json.NewDecoder(r.Body).Decode(&credentials); err != nil {
+		return nil, nil, NewAppError("GetWebAuthnCredentials", "api.unmarshal_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	return credentials, BuildResponse(r), nil
+}
+
+// DeleteWebAuthnCredential revokes one of a user's security keys.
+func (c *Client4) DeleteWebAuthnCredential(ctx context.Context, userId, credentialId string) (*Response, error) {
+	r, err := c.DoAPIDelete(ctx, c.userRoute(userId)+"/mfa/webauthn/credentials/"+credentialId)
+	if err != nil {
+		return BuildResponse(r), err
+	}
+	defer closeBody(r)
+	return BuildResponse(r), nil
+}
+
 // UpdateUserPassword updates a user's password. Must be logged in as the user or be a system administrator.
 func (c *Client4) UpdateUserPassword(ctx context.Context, userId, currentPassword, newPassword string) (*Response, error) {
 	requestBody := map[string]string{"current_password": currentPassword, "new_password": newPassword}
@@ -2842,41 +2949,69 @@ func (c *Client4) GetTeamUnread(ctx context.Context, teamId, userId string) (*Te
 
 // ImportTeam will import an exported team from other app into a existing team.
 func (c *Client4) ImportTeam(ctx context.Context, data []byte, filesize int, importFrom, filename, teamId string) (map[string]string, *Response, error) {
+	body, contentType, err := importTeamForm(data, filesize, importFrom, filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return c.DoUploadImportTeam(ctx, c.teamImportRoute(teamId), body.Bytes(), contentType)
+}
+
+// ImportTeamJob uploads a team exported from other app and starts a job importing it into an existing team.
+func (c *Client4) ImportTeamJob(ctx context.Context, data []byte, filesize int, importFrom, filename, teamId string) (*Job, *Response, error) {
+	body, contentType, err := importTeamForm(data, filesize, importFrom, filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r, err := c.DoAPIRequestReader(ctx, http.MethodPost, c.APIURL+c.teamImportJobRoute(teamId), body, map[string]string{"Content-Type": contentType})
+	if err != nil {
+		return nil, BuildResponse(r), err
+	}
+	defer closeBody(r)
+	var j Job
+	if err := json.NewDecoder(r.Body).Decode(&j); err != nil {
+		return nil, nil, NewAppError("ImportTeamJob", "api.unmarshal_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	return &j, BuildResponse(r), nil
+}
+
+func importTeamForm(data []byte, filesize int, importFrom, filename string) (*bytes.Buffer, string, error) {
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
 
 	part, err := writer.CreateFormFile("file", filename)
 	if err != nil {
-		return nil, nil, err
+		return nil, "", err
 	}
 
 	if _, err = io.Copy(part, bytes.NewBuffer(data)); err != nil {
-		return nil, nil, err
+		return nil, "", err
 	}
 
 	part, err = writer.CreateFormField("filesize")
 	if err != nil {
-		return nil, nil, err
+		return nil, "", err
 	}
 
 	if _, err = io.Copy(part, strings.NewReader(strconv.Itoa(filesize))); err != nil {
-		return nil, nil, err
+		return nil, "", err
 	}
 
 	part, err = writer.CreateFormField("importFrom")
 	if err != nil {
-		return nil, nil, err
+		return nil, "", err
 	}
 
 	if _, err := io.Copy(part, strings.NewReader(importFrom)); err != nil {
-		return nil, nil, err
+		return nil, "", err
 	}
 
 	if err := writer.Close(); err != nil {
-		return nil, nil, err
+		return nil, "", err
 	}
 
-	return c.DoUploadImportTeam(ctx, c.teamImportRoute(teamId), body.Bytes(), writer.FormDataContentType())
+	return body, writer.FormDataContentType(), nil
 }
 
 // InviteUsersToTeam invite users by email to the team.
@@ -6659,6 +6794,86 @@ func (c *Client4) GetChannelPoliciesForUser(ctx context.Context, userID string,
 	return &channels, BuildResponse(r), nil
 }
 
+// Legal Holds Section
+
+// CreateLegalHold creates a legal hold freezing the content of the specified
+// users and channels. The Id and CreatorId fields of `hold` are ignored.
+func (c *Client4) CreateLegalHold(ctx context.Context, hold *LegalHold) (*LegalHold, *Response, error) {
+	buf, err := json.Marshal(hold)
+	if err != nil {
+		return nil, nil, NewAppError("CreateLegalHold", "api.marshal_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	r, err := c.DoAPIPostBytes(ctx, c.legalHoldsRoute(), buf)
+	if err != nil {
+		return nil, BuildResponse(r), err
+	}
+	defer closeBody(r)
+	var h LegalHold
+	if err := json.NewDecoder(r.Body).Decode(&h); err != nil {
+		return nil, nil, NewAppError("CreateLegalHold", "api.unmarshal_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	return &h, BuildResponse(r), nil
+}
+
+// GetLegalHold returns the legal hold with the specified ID.
+func (c *Client4) GetLegalHold(ctx context.Context, legalHoldID string) (*LegalHold, *Response, error) {
+	r, err := c.DoAPIGet(ctx, c.legalHoldRoute(legalHoldID), "")
+	if err != nil {
+		return nil, BuildResponse(r), err
+	}
+	defer closeBody(r)
+	var h LegalHold
+	if err := json.NewDecoder(r.Body).Decode(&h); err != nil {
+		return nil, nil, NewAppError("GetLegalHold", "api.unmarshal_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	return &h, BuildResponse(r), nil
+}
+
+// GetLegalHolds returns a page of the legal holds, most recent first. The
+// released holds are only included when includeReleased is true.
+func (c *Client4) GetLegalHolds(ctx context.Context, page, perPage int, includeReleased bool) ([]*LegalHold, *Response, error) {
+	query := fmt.Sprintf("?page=%d&per_page=%d&include_released=%t", page, perPage, includeReleased)
+	r, err := c.DoAPIGet(ctx, c.legalHoldsRoute()+query, "")
+	if err != nil {
+		return nil, BuildResponse(r), err
+	}
+	defer closeBody(r)
+	var holds []*LegalHold
+	if err := json.NewDecoder(r.Body).Decode(&holds); err != nil {
+		return nil, nil, NewAppError("GetLegalHolds", "api.unmarshal_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	return holds, BuildResponse(r), nil
+}
+
+// ReleaseLegalHold releases the legal hold with the specified ID.
+func (c *Client4) ReleaseLegalHold(ctx context.Context, legalHoldID string) (*LegalHold, *Response, error) {
+	r, err := c.DoAPIPost(ctx, c.legalHoldRoute(legalHoldID)+"/release", "")
+	if err != nil {
+		return nil, BuildResponse(r), err
+	}
+	defer closeBody(r)
+	var h LegalHold
+	if err := json.NewDecoder(r.Body).Decode(&h); err != nil {
+		return nil, nil, NewAppError("ReleaseLegalHold", "api.unmarshal_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	return &h, BuildResponse(r), nil
+}
+
+// ExportLegalHold starts a job exporting the content covered by the legal hold
+// with the specified ID.
+func (c *Client4) ExportLegalHold(ctx context.Context, legalHoldID string) (*Job, *Response, error) {
+	r, err := c.DoAPIPost(ctx, c.legalHoldRoute(legalHoldID)+"/export", "")
+	if err != nil {
+		return nil, BuildResponse(r), err
+	}
+	defer closeBody(r)
+	var job Job
+	if err := json.NewDecoder(r.Body).Decode(&job); err != nil {
+		return nil, nil, NewAppError("ExportLegalHold", "api.unmarshal_error", nil, "", http.StatusInternalServerError).Wrap(err)
+	}
+	return &job, BuildResponse(r), nil
+}
+
 // Drafts Sections
 
 // UpsertDraft will create a new draft or update a draft if it already exists
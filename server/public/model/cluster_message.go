@@ -42,6 +42,7 @@ const (
 	ClusterEventPluginEvent                                 ClusterEvent = "plugin_event"
 	ClusterEventInvalidateCacheForTermsOfService            ClusterEvent = "inv_terms_of_service"
 	ClusterEventBusyStateChanged                            ClusterEvent = "busy_state_change"
+	ClusterEventBleveIndexSync                              ClusterEvent = "bleve_index_sync"
 	// Note: if you are adding a new event, please also add it in the slice of
 	// m.ClusterEventMap in metrics/metrics.go file.
 
@@ -60,6 +61,8 @@ const (
 	ClusterGossipEventResponseWebConnCount          = "gossip_response_webconn_count"
 	ClusterGossipEventRequestWSQueues               = "gossip_request_ws_queues"
 	ClusterGossipEventResponseWSQueues              = "gossip_response_ws_queues"
+	ClusterGossipEventRequestGetClusterInfo         = "gossip_request_cluster_info"
+	ClusterGossipEventResponseGetClusterInfo        = "gossip_response_cluster_info"
 
 	// SendTypes for ClusterMessage.
 	ClusterSendBestEffort = "best_effort"
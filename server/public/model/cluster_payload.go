@@ -0,0 +1,22 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package model
+
+// ClusterPayload holds a cluster message too large to be sent through the database
+// notifications, which carry its id instead.
+type ClusterPayload struct {
+	Id       string `json:"id"`
+	Data     []byte `json:"data"`
+	CreateAt int64  `json:"create_at"`
+}
+
+func (o *ClusterPayload) PreSave() {
+	if o.Id == "" {
+		o.Id = NewId()
+	}
+
+	if o.CreateAt == 0 {
+		o.CreateAt = GetMillis()
+	}
+}
@@ -5,6 +5,7 @@ package model
 
 import (
 	"crypto/tls"
+	"encoding/base64"
 	"encoding/json"
 	"io"
 	"math"
@@ -34,6 +35,8 @@ const (
 
 	ImageDriverLocal = "local"
 	ImageDriverS3    = "amazons3"
+	ImageDriverGCS   = "googlecloudstorage"
+	ImageDriverAzure = "azureblob"
 
 	DatabaseDriverMysql    = "mysql"
 	DatabaseDriverPostgres = "postgres"
@@ -102,6 +105,23 @@ const (
 	CacheTypeLRU   = "lru"
 	CacheTypeRedis = "redis"
 
+	ClusterMessageTransportAuto     = "auto"
+	ClusterMessageTransportHTTP     = "http"
+	ClusterMessageTransportRedis    = "redis"
+	ClusterMessageTransportDatabase = "database"
+
+	RateLimitStoreTypeMemory = "memory"
+	RateLimitStoreTypeRedis  = "redis"
+
+	RateLimitVaryByUser  = "user"
+	RateLimitVaryByToken = "token"
+	RateLimitVaryByIP    = "ip"
+
+	RateLimitAuthTypeSession = "session"
+	RateLimitAuthTypePAT     = "pat"
+	RateLimitAuthTypeOAuth   = "oauth"
+	RateLimitAuthTypeBot     = "bot"
+
 	SitenameMaxLength = 30
 
 	ServiceSettingsDefaultSiteURL                = "http://localhost:8065"
@@ -216,6 +236,9 @@ const (
 	BleveSettingsDefaultIndexDir  = ""
 	BleveSettingsDefaultBatchSize = 10000
 
+	PostgresSearchSettingsDefaultTextSearchConfig = "english"
+	PostgresSearchSettingsDefaultBatchSize        = 10000
+
 	DataRetentionSettingsDefaultMessageRetentionDays           = 365
 	DataRetentionSettingsDefaultMessageRetentionHours          = 0
 	DataRetentionSettingsDefaultFileRetentionDays              = 365
@@ -241,6 +264,8 @@ const (
 	ComplianceExportTypeActiance                   = "actiance"
 	ComplianceExportTypeGlobalrelay                = "globalrelay"
 	ComplianceExportTypeGlobalrelayZip             = "globalrelay-zip"
+	ComplianceExportTypeEml                        = "eml"
+	ComplianceExportTypeJsonl                      = "jsonl"
 	ComplianceExportChannelBatchSizeDefault        = 100
 	ComplianceExportChannelHistoryBatchSizeDefault = 10
 
@@ -248,6 +273,13 @@ const (
 	GlobalrelayCustomerTypeA10    = "A10"
 	GlobalrelayCustomerTypeCustom = "CUSTOM"
 
+	EmlExportGroupingPost       = "post"
+	EmlExportGroupingChannelDay = "channel_day"
+
+	MessageExportDeliveryTargetNone = ""
+	MessageExportDeliveryTargetSFTP = "sftp"
+	MessageExportDeliveryTargetS3   = "s3"
+
 	ClientSideCertCheckPrimaryAuth   = "primary"
 	ClientSideCertCheckSecondaryAuth = "secondary"
 
@@ -361,6 +393,7 @@ type ServiceSettings struct {
 	AllowedUntrustedInternalConnections *string  `access:"environment_web_server,write_restrictable,cloud_restrictable"`
 	EnableMultifactorAuthentication     *bool    `access:"authentication_mfa"`
 	EnforceMultifactorAuthentication    *bool    `access:"authentication_mfa"`
+	EnableWebAuthn                      *bool    `access:"authentication_mfa"`
 	EnableUserAccessTokens              *bool    `access:"integrations_integration_management"`
 	AllowCorsFrom                       *string  `access:"integrations_cors,write_restrictable,cloud_restrictable"`
 	CorsExposedHeaders                  *string  `access:"integrations_cors,write_restrictable,cloud_restrictable"`
@@ -521,6 +554,10 @@ func (s *ServiceSettings) SetDefaults(isUpdate bool) {
 		s.EnforceMultifactorAuthentication = NewPointer(false)
 	}
 
+	if s.EnableWebAuthn == nil {
+		s.EnableWebAuthn = NewPointer(false)
+	}
+
 	if s.EnableUserAccessTokens == nil {
 		s.EnableUserAccessTokens = NewPointer(false)
 	}
@@ -1022,6 +1059,7 @@ type ClusterSettings struct {
 	EnableExperimentalGossipEncryption *bool   `access:"environment_high_availability,write_restrictable,cloud_restrictable"`
 	ReadOnlyConfig                     *bool   `access:"environment_high_availability,write_restrictable,cloud_restrictable"`
 	GossipPort                         *int    `access:"environment_high_availability,write_restrictable,cloud_restrictable"` // telemetry: none
+	MessageTransport                   *string `access:"environment_high_availability,write_restrictable,cloud_restrictable"`
 }
 
 func (s *ClusterSettings) SetDefaults() {
@@ -1068,6 +1106,20 @@ func (s *ClusterSettings) SetDefaults() {
 	if s.GossipPort == nil {
 		s.GossipPort = NewPointer(8074)
 	}
+
+	if s.MessageTransport == nil {
+		s.MessageTransport = NewPointer(ClusterMessageTransportAuto)
+	}
+}
+
+func (s *ClusterSettings) isValid() *AppError {
+	switch *s.MessageTransport {
+	case ClusterMessageTransportAuto, ClusterMessageTransportHTTP, ClusterMessageTransportRedis, ClusterMessageTransportDatabase:
+	default:
+		return NewAppError("Config.IsValid", "model.config.is_valid.cluster_message_transport.app_error", map[string]any{"Transport": *s.MessageTransport}, "", http.StatusBadRequest)
+	}
+
+	return nil
 }
 
 type MetricsSettings struct {
@@ -1665,6 +1717,29 @@ type FileSettings struct {
 	AmazonS3RequestTimeoutMilliseconds *int64  `access:"environment_file_storage,write_restrictable,cloud_restrictable"` // telemetry: none
 	AmazonS3UploadPartSizeBytes        *int64  `access:"environment_file_storage,write_restrictable,cloud_restrictable"` // telemetry: none
 	AmazonS3StorageClass               *string `access:"environment_file_storage,write_restrictable,cloud_restrictable"` // telemetry: none
+	// Google Cloud Storage settings
+	GoogleCloudStorageBucket                     *string `access:"environment_file_storage,write_restrictable,cloud_restrictable"` // telemetry: none
+	GoogleCloudStoragePathPrefix                 *string `access:"environment_file_storage,write_restrictable,cloud_restrictable"` // telemetry: none
+	GoogleCloudStorageCredentialsJSON            *string `access:"environment_file_storage,write_restrictable,cloud_restrictable"` // telemetry: none
+	GoogleCloudStorageEndpoint                   *string `access:"environment_file_storage,write_restrictable,cloud_restrictable"` // telemetry: none
+	GoogleCloudStorageRequestTimeoutMilliseconds *int64  `access:"environment_file_storage,write_restrictable,cloud_restrictable"` // telemetry: none
+	// Azure Blob Storage settings
+	AzureStorageAccountName                *string `access:"environment_file_storage,write_restrictable,cloud_restrictable"` // telemetry: none
+	AzureStorageAccountKey                 *string `access:"environment_file_storage,write_restrictable,cloud_restrictable"` // telemetry: none
+	AzureStorageContainer                  *string `access:"environment_file_storage,write_restrictable,cloud_restrictable"` // telemetry: none
+	AzureStoragePathPrefix                 *string `access:"environment_file_storage,write_restrictable,cloud_restrictable"` // telemetry: none
+	AzureStorageEndpoint                   *string `access:"environment_file_storage,write_restrictable,cloud_restrictable"` // telemetry: none
+	AzureStorageRequestTimeoutMilliseconds *int64  `access:"environment_file_storage,write_restrictable,cloud_restrictable"` // telemetry: none
+	// Encryption at rest settings
+	EnableEncryptionAtRest       *bool    `access:"environment_file_storage,write_restrictable,cloud_restrictable"`
+	EncryptionAtRestKey          *string  `access:"environment_file_storage,write_restrictable,cloud_restrictable"` // telemetry: none
+	EncryptionAtRestPreviousKeys []string `access:"environment_file_storage,write_restrictable,cloud_restrictable"` // telemetry: none
+	// Deduplication settings
+	EnableFileDeduplication *bool `access:"environment_file_storage,write_restrictable,cloud_restrictable"`
+	// Antivirus settings
+	EnableAntivirusScan         *bool   `access:"environment_file_storage,write_restrictable,cloud_restrictable"`
+	AntivirusClamdAddress       *string `access:"environment_file_storage,write_restrictable,cloud_restrictable"` // telemetry: none
+	AntivirusScanTimeoutSeconds *int    `access:"environment_file_storage,write_restrictable,cloud_restrictable"` // telemetry: none
 	// Export store settings
 	DedicatedExportStore                     *bool   `access:"environment_file_storage,write_restrictable"`
 	ExportDriverName                         *string `access:"environment_file_storage,write_restrictable"`
@@ -1683,6 +1758,21 @@ type FileSettings struct {
 	ExportAmazonS3PresignExpiresSeconds      *int64  `access:"environment_file_storage,write_restrictable"` // telemetry: none
 	ExportAmazonS3UploadPartSizeBytes        *int64  `access:"environment_file_storage,write_restrictable"` // telemetry: none
 	ExportAmazonS3StorageClass               *string `access:"environment_file_storage,write_restrictable"` // telemetry: none
+	// Export Google Cloud Storage settings
+	ExportGoogleCloudStorageBucket                     *string `access:"environment_file_storage,write_restrictable"` // telemetry: none
+	ExportGoogleCloudStoragePathPrefix                 *string `access:"environment_file_storage,write_restrictable"` // telemetry: none
+	ExportGoogleCloudStorageCredentialsJSON            *string `access:"environment_file_storage,write_restrictable"` // telemetry: none
+	ExportGoogleCloudStorageEndpoint                   *string `access:"environment_file_storage,write_restrictable"` // telemetry: none
+	ExportGoogleCloudStorageRequestTimeoutMilliseconds *int64  `access:"environment_file_storage,write_restrictable"` // telemetry: none
+	ExportGoogleCloudStoragePresignExpiresSeconds      *int64  `access:"environment_file_storage,write_restrictable"` // telemetry: none
+	// Export Azure Blob Storage settings
+	ExportAzureStorageAccountName                *string `access:"environment_file_storage,write_restrictable"` // telemetry: none
+	ExportAzureStorageAccountKey                 *string `access:"environment_file_storage,write_restrictable"` // telemetry: none
+	ExportAzureStorageContainer                  *string `access:"environment_file_storage,write_restrictable"` // telemetry: none
+	ExportAzureStoragePathPrefix                 *string `access:"environment_file_storage,write_restrictable"` // telemetry: none
+	ExportAzureStorageEndpoint                   *string `access:"environment_file_storage,write_restrictable"` // telemetry: none
+	ExportAzureStorageRequestTimeoutMilliseconds *int64  `access:"environment_file_storage,write_restrictable"` // telemetry: none
+	ExportAzureStoragePresignExpiresSeconds      *int64  `access:"environment_file_storage,write_restrictable"` // telemetry: none
 }
 
 func (s *FileSettings) SetDefaults(isUpdate bool) {
@@ -1799,6 +1889,78 @@ func (s *FileSettings) SetDefaults(isUpdate bool) {
 		s.AmazonS3StorageClass = NewPointer("")
 	}
 
+	if s.GoogleCloudStorageBucket == nil {
+		s.GoogleCloudStorageBucket = NewPointer("")
+	}
+
+	if s.GoogleCloudStoragePathPrefix == nil {
+		s.GoogleCloudStoragePathPrefix = NewPointer("")
+	}
+
+	if s.GoogleCloudStorageCredentialsJSON == nil {
+		s.GoogleCloudStorageCredentialsJSON = NewPointer("")
+	}
+
+	if s.GoogleCloudStorageEndpoint == nil {
+		s.GoogleCloudStorageEndpoint = NewPointer("")
+	}
+
+	if s.GoogleCloudStorageRequestTimeoutMilliseconds == nil {
+		s.GoogleCloudStorageRequestTimeoutMilliseconds = NewPointer(int64(30000))
+	}
+
+	if s.AzureStorageAccountName == nil {
+		s.AzureStorageAccountName = NewPointer("")
+	}
+
+	if s.AzureStorageAccountKey == nil {
+		s.AzureStorageAccountKey = NewPointer("")
+	}
+
+	if s.AzureStorageContainer == nil {
+		s.AzureStorageContainer = NewPointer("")
+	}
+
+	if s.AzureStoragePathPrefix == nil {
+		s.AzureStoragePathPrefix = NewPointer("")
+	}
+
+	if s.AzureStorageEndpoint == nil {
+		s.AzureStorageEndpoint = NewPointer("")
+	}
+
+	if s.AzureStorageRequestTimeoutMilliseconds == nil {
+		s.AzureStorageRequestTimeoutMilliseconds = NewPointer(int64(30000))
+	}
+
+	if s.EnableEncryptionAtRest == nil {
+		s.EnableEncryptionAtRest = NewPointer(false)
+	}
+
+	if s.EncryptionAtRestKey == nil {
+		s.EncryptionAtRestKey = NewPointer("")
+	}
+
+	if s.EncryptionAtRestPreviousKeys == nil {
+		s.EncryptionAtRestPreviousKeys = []string{}
+	}
+
+	if s.EnableFileDeduplication == nil {
+		s.EnableFileDeduplication = NewPointer(false)
+	}
+
+	if s.EnableAntivirusScan == nil {
+		s.EnableAntivirusScan = NewPointer(false)
+	}
+
+	if s.AntivirusClamdAddress == nil {
+		s.AntivirusClamdAddress = NewPointer("localhost:3310")
+	}
+
+	if s.AntivirusScanTimeoutSeconds == nil {
+		s.AntivirusScanTimeoutSeconds = NewPointer(60)
+	}
+
 	if s.DedicatedExportStore == nil {
 		s.DedicatedExportStore = NewPointer(false)
 	}
@@ -1868,6 +2030,58 @@ func (s *FileSettings) SetDefaults(isUpdate bool) {
 	if s.ExportAmazonS3StorageClass == nil {
 		s.ExportAmazonS3StorageClass = NewPointer("")
 	}
+
+	if s.ExportGoogleCloudStorageBucket == nil {
+		s.ExportGoogleCloudStorageBucket = NewPointer("")
+	}
+
+	if s.ExportGoogleCloudStoragePathPrefix == nil {
+		s.ExportGoogleCloudStoragePathPrefix = NewPointer("")
+	}
+
+	if s.ExportGoogleCloudStorageCredentialsJSON == nil {
+		s.ExportGoogleCloudStorageCredentialsJSON = NewPointer("")
+	}
+
+	if s.ExportGoogleCloudStorageEndpoint == nil {
+		s.ExportGoogleCloudStorageEndpoint = NewPointer("")
+	}
+
+	if s.ExportGoogleCloudStorageRequestTimeoutMilliseconds == nil {
+		s.ExportGoogleCloudStorageRequestTimeoutMilliseconds = NewPointer(int64(30000))
+	}
+
+	if s.ExportGoogleCloudStoragePresignExpiresSeconds == nil {
+		s.ExportGoogleCloudStoragePresignExpiresSeconds = NewPointer(int64(21600)) // 6h
+	}
+
+	if s.ExportAzureStorageAccountName == nil {
+		s.ExportAzureStorageAccountName = NewPointer("")
+	}
+
+	if s.ExportAzureStorageAccountKey == nil {
+		s.ExportAzureStorageAccountKey = NewPointer("")
+	}
+
+	if s.ExportAzureStorageContainer == nil {
+		s.ExportAzureStorageContainer = NewPointer("")
+	}
+
+	if s.ExportAzureStoragePathPrefix == nil {
+		s.ExportAzureStoragePathPrefix = NewPointer("")
+	}
+
+	if s.ExportAzureStorageEndpoint == nil {
+		s.ExportAzureStorageEndpoint = NewPointer("")
+	}
+
+	if s.ExportAzureStorageRequestTimeoutMilliseconds == nil {
+		s.ExportAzureStorageRequestTimeoutMilliseconds = NewPointer(int64(30000))
+	}
+
+	if s.ExportAzureStoragePresignExpiresSeconds == nil {
+		s.ExportAzureStoragePresignExpiresSeconds = NewPointer(int64(21600)) // 6h
+	}
 }
 
 type EmailSettings struct {
@@ -1893,7 +2107,7 @@ type EmailSettings struct {
 	PushNotificationContents          *string `access:"site_notifications"`
 	PushNotificationBuffer            *int    // telemetry: none
 	EnableEmailBatching               *bool   `access:"site_notifications"`
-	EmailBatchingBufferSize           *int    `access:"experimental_features"`
+	EmailBatchingBufferSize           *int    `access:"experimental_features"` // Deprecated: the batched notifications are stored in the database and no longer buffered in memory
 	EmailBatchingInterval             *int    `access:"experimental_features"`
 	EnablePreviewModeBanner           *bool   `access:"site_notifications"`
 	SkipServerCertificateVerification *bool   `access:"environment_smtp,write_restrictable,cloud_restrictable"`
@@ -2053,6 +2267,102 @@ type RateLimitSettings struct {
 	VaryByRemoteAddr *bool  `access:"environment_rate_limiting,write_restrictable,cloud_restrictable"`
 	VaryByUser       *bool  `access:"environment_rate_limiting,write_restrictable,cloud_restrictable"`
 	VaryByHeader     string `access:"environment_rate_limiting,write_restrictable,cloud_restrictable"`
+	// StoreType is either memory, where every node enforces its own limits, or redis, where the
+	// limits are shared through the Redis cache and so apply across the cluster.
+	StoreType *string            `access:"environment_rate_limiting,write_restrictable,cloud_restrictable"`
+	Policies  []*RateLimitPolicy `access:"environment_rate_limiting,write_restrictable,cloud_restrictable"`
+}
+
+// RateLimitPolicy is a quota applying to the API requests matching it, on top of the global
+// rate limit. When several policies match a request, the one with the most specific route
+// applies, and then the first one configured.
+type RateLimitPolicy struct {
+	// Name identifies the policy in the metrics. It defaults to the route.
+	Name *string `access:"environment_rate_limiting,write_restrictable,cloud_restrictable"`
+	// Route is the path prefix of the requests, in which a * segment matches any segment,
+	// e.g. /api/v4/channels/*/posts.
+	Route *string `access:"environment_rate_limiting,write_restrictable,cloud_restrictable"`
+	// Methods, AuthTypes and Roles restrict the policy to the requests with one of the HTTP
+	// methods, authenticated in one of the ways, or by a user with one of the roles. An empty
+	// list matches all the requests.
+	Methods   []string `access:"environment_rate_limiting,write_restrictable,cloud_restrictable"`
+	AuthTypes []string `access:"environment_rate_limiting,write_restrictable,cloud_restrictable"`
+	Roles     []string `access:"environment_rate_limiting,write_restrictable,cloud_restrictable"`
+	PerSec    *int     `access:"environment_rate_limiting,write_restrictable,cloud_restrictable"`
+	MaxBurst  *int     `access:"environment_rate_limiting,write_restrictable,cloud_restrictable"`
+	// VaryBy is the key the requests are counted by: the user, the authentication token or the
+	// IP address. Requests without a session are counted by IP address.
+	VaryBy *string `access:"environment_rate_limiting,write_restrictable,cloud_restrictable"`
+}
+
+func (p *RateLimitPolicy) SetDefaults() {
+	if p.Route == nil {
+		p.Route = NewPointer("")
+	}
+
+	if p.Name == nil || *p.Name == "" {
+		p.Name = NewPointer(*p.Route)
+	}
+
+	if p.Methods == nil {
+		p.Methods = []string{}
+	}
+
+	if p.AuthTypes == nil {
+		p.AuthTypes = []string{}
+	}
+
+	if p.Roles == nil {
+		p.Roles = []string{}
+	}
+
+	if p.PerSec == nil {
+		p.PerSec = NewPointer(10)
+	}
+
+	if p.MaxBurst == nil {
+		p.MaxBurst = NewPointer(100)
+	}
+
+	if p.VaryBy == nil {
+		p.VaryBy = NewPointer(RateLimitVaryByUser)
+	}
+}
+
+func (p *RateLimitPolicy) isValid() *AppError {
+	if !strings.HasPrefix(*p.Route, "/") {
+		return NewAppError("Config.IsValid", "model.config.is_valid.rate_limit_policy_route.app_error", map[string]any{"Route": *p.Route}, "", http.StatusBadRequest)
+	}
+
+	if *p.PerSec <= 0 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.rate_sec.app_error", nil, "route="+*p.Route, http.StatusBadRequest)
+	}
+
+	if *p.MaxBurst <= 0 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.max_burst.app_error", nil, "route="+*p.Route, http.StatusBadRequest)
+	}
+
+	if *p.VaryBy != RateLimitVaryByUser && *p.VaryBy != RateLimitVaryByToken && *p.VaryBy != RateLimitVaryByIP {
+		return NewAppError("Config.IsValid", "model.config.is_valid.rate_limit_policy_vary_by.app_error", map[string]any{"Route": *p.Route}, "", http.StatusBadRequest)
+	}
+
+	for _, method := range p.Methods {
+		switch strings.ToUpper(method) {
+		case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		default:
+			return NewAppError("Config.IsValid", "model.config.is_valid.rate_limit_policy_method.app_error", map[string]any{"Route": *p.Route, "Method": method}, "", http.StatusBadRequest)
+		}
+	}
+
+	for _, authType := range p.AuthTypes {
+		switch authType {
+		case RateLimitAuthTypeSession, RateLimitAuthTypePAT, RateLimitAuthTypeOAuth, RateLimitAuthTypeBot:
+		default:
+			return NewAppError("Config.IsValid", "model.config.is_valid.rate_limit_policy_auth_type.app_error", map[string]any{"Route": *p.Route, "AuthType": authType}, "", http.StatusBadRequest)
+		}
+	}
+
+	return nil
 }
 
 func (s *RateLimitSettings) SetDefaults() {
@@ -2079,6 +2389,18 @@ func (s *RateLimitSettings) SetDefaults() {
 	if s.VaryByUser == nil {
 		s.VaryByUser = NewPointer(false)
 	}
+
+	if s.StoreType == nil {
+		s.StoreType = NewPointer(RateLimitStoreTypeMemory)
+	}
+
+	if s.Policies == nil {
+		s.Policies = []*RateLimitPolicy{}
+	}
+
+	for _, policy := range s.Policies {
+		policy.SetDefaults()
+	}
 }
 
 type PrivacySettings struct {
@@ -2982,6 +3304,7 @@ type BleveSettings struct {
 	EnableAutocomplete            *bool   `access:"experimental_bleve"`
 	BulkIndexingTimeWindowSeconds *int    `json:",omitempty"` // telemetry: none
 	BatchSize                     *int    `access:"experimental_bleve"`
+	EnableClusterSync             *bool   `access:"experimental_bleve"`
 }
 
 func (bs *BleveSettings) SetDefaults() {
@@ -3004,6 +3327,35 @@ func (bs *BleveSettings) SetDefaults() {
 	if bs.BatchSize == nil {
 		bs.BatchSize = NewPointer(BleveSettingsDefaultBatchSize)
 	}
+
+	if bs.EnableClusterSync == nil {
+		bs.EnableClusterSync = NewPointer(false)
+	}
+}
+
+type PostgresSearchSettings struct {
+	EnableIndexing   *bool   `access:"environment_database"`
+	EnableSearching  *bool   `access:"environment_database"`
+	TextSearchConfig *string `access:"environment_database"`
+	BatchSize        *int    `access:"environment_database"`
+}
+
+func (s *PostgresSearchSettings) SetDefaults() {
+	if s.EnableIndexing == nil {
+		s.EnableIndexing = NewPointer(false)
+	}
+
+	if s.EnableSearching == nil {
+		s.EnableSearching = NewPointer(false)
+	}
+
+	if s.TextSearchConfig == nil {
+		s.TextSearchConfig = NewPointer(PostgresSearchSettingsDefaultTextSearchConfig)
+	}
+
+	if s.BatchSize == nil {
+		s.BatchSize = NewPointer(PostgresSearchSettingsDefaultBatchSize)
+	}
 }
 
 type DataRetentionSettings struct {
@@ -3403,6 +3755,143 @@ func (s *GlobalRelayMessageExportSettings) SetDefaults() {
 	}
 }
 
+type EmlMessageExportSettings struct {
+	// Grouping is either "post", writing one message per post, or "channel_day", writing one
+	// message per channel and UTC day.
+	Grouping *string `access:"compliance_compliance_export"`
+}
+
+func (s *EmlMessageExportSettings) SetDefaults() {
+	if s.Grouping == nil {
+		s.Grouping = NewPointer(EmlExportGroupingPost)
+	}
+}
+
+// MessageExportDeliverySettings configures the copy of every exported batch to an archive,
+// along with a manifest holding its SHA-256 checksum.
+type MessageExportDeliverySettings struct {
+	// Target is either empty, leaving the batches in the export file store only, "sftp" or "s3".
+	Target *string `access:"compliance_compliance_export"`
+	// MaxRetries is the number of times a failed delivery is retried before failing the export.
+	MaxRetries *int `access:"compliance_compliance_export"`
+	// RetryIntervalSeconds is the wait before the first retry, doubled on every following one.
+	RetryIntervalSeconds *int `access:"compliance_compliance_export"`
+
+	SFTPHost       *string `access:"compliance_compliance_export"`
+	SFTPPort       *int    `access:"compliance_compliance_export"`
+	SFTPUsername   *string `access:"compliance_compliance_export"`
+	SFTPPassword   *string `access:"compliance_compliance_export"` // telemetry: none
+	SFTPPrivateKey *string `access:"compliance_compliance_export"` // telemetry: none
+	// SFTPHostKey is the public key of the server, in the authorized_keys format.
+	SFTPHostKey   *string `access:"compliance_compliance_export"` // telemetry: none
+	SFTPDirectory *string `access:"compliance_compliance_export"`
+
+	S3Bucket          *string `access:"compliance_compliance_export"`
+	S3PathPrefix      *string `access:"compliance_compliance_export"`
+	S3Region          *string `access:"compliance_compliance_export"`
+	S3Endpoint        *string `access:"compliance_compliance_export"`
+	S3AccessKeyId     *string `access:"compliance_compliance_export"` // telemetry: none
+	S3SecretAccessKey *string `access:"compliance_compliance_export"` // telemetry: none
+	S3SSL             *bool   `access:"compliance_compliance_export"`
+}
+
+func (s *MessageExportDeliverySettings) SetDefaults() {
+	if s.Target == nil {
+		s.Target = NewPointer(MessageExportDeliveryTargetNone)
+	}
+	if s.MaxRetries == nil {
+		s.MaxRetries = NewPointer(3)
+	}
+	if s.RetryIntervalSeconds == nil {
+		s.RetryIntervalSeconds = NewPointer(30)
+	}
+	if s.SFTPHost == nil {
+		s.SFTPHost = NewPointer("")
+	}
+	if s.SFTPPort == nil {
+		s.SFTPPort = NewPointer(22)
+	}
+	if s.SFTPUsername == nil {
+		s.SFTPUsername = NewPointer("")
+	}
+	if s.SFTPPassword == nil {
+		s.SFTPPassword = NewPointer("")
+	}
+	if s.SFTPPrivateKey == nil {
+		s.SFTPPrivateKey = NewPointer("")
+	}
+	if s.SFTPHostKey == nil {
+		s.SFTPHostKey = NewPointer("")
+	}
+	if s.SFTPDirectory == nil {
+		s.SFTPDirectory = NewPointer("")
+	}
+	if s.S3Bucket == nil {
+		s.S3Bucket = NewPointer("")
+	}
+	if s.S3PathPrefix == nil {
+		s.S3PathPrefix = NewPointer("")
+	}
+	if s.S3Region == nil {
+		s.S3Region = NewPointer("")
+	}
+	if s.S3Endpoint == nil {
+		s.S3Endpoint = NewPointer("s3.amazonaws.com")
+	}
+	if s.S3AccessKeyId == nil {
+		s.S3AccessKeyId = NewPointer("")
+	}
+	if s.S3SecretAccessKey == nil {
+		s.S3SecretAccessKey = NewPointer("")
+	}
+	if s.S3SSL == nil {
+		s.S3SSL = NewPointer(true)
+	}
+}
+
+func (s *MessageExportDeliverySettings) isValid(exportFormat string) *AppError {
+	switch *s.Target {
+	case MessageExportDeliveryTargetNone:
+		return nil
+	case MessageExportDeliveryTargetSFTP, MessageExportDeliveryTargetS3:
+	default:
+		return NewAppError("Config.IsValid", "model.config.is_valid.message_export.delivery.target.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	// Global Relay exports are sent by email rather than written to the export file store.
+	if exportFormat == ComplianceExportTypeGlobalrelay {
+		return NewAppError("Config.IsValid", "model.config.is_valid.message_export.delivery.global_relay.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *s.MaxRetries < 0 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.message_export.delivery.max_retries.app_error", nil, "", http.StatusBadRequest)
+	}
+	if *s.RetryIntervalSeconds <= 0 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.message_export.delivery.retry_interval.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *s.Target == MessageExportDeliveryTargetSFTP {
+		if *s.SFTPHost == "" {
+			return NewAppError("Config.IsValid", "model.config.is_valid.message_export.delivery.sftp_host.app_error", nil, "", http.StatusBadRequest)
+		} else if *s.SFTPPort <= 0 || *s.SFTPPort > 65535 {
+			return NewAppError("Config.IsValid", "model.config.is_valid.message_export.delivery.sftp_port.app_error", nil, "", http.StatusBadRequest)
+		} else if *s.SFTPUsername == "" {
+			return NewAppError("Config.IsValid", "model.config.is_valid.message_export.delivery.sftp_username.app_error", nil, "", http.StatusBadRequest)
+		} else if *s.SFTPPassword == "" && *s.SFTPPrivateKey == "" {
+			return NewAppError("Config.IsValid", "model.config.is_valid.message_export.delivery.sftp_credentials.app_error", nil, "", http.StatusBadRequest)
+		} else if *s.SFTPHostKey == "" {
+			// Without the key of the server, the archive could be sent to anyone in the middle.
+			return NewAppError("Config.IsValid", "model.config.is_valid.message_export.delivery.sftp_host_key.app_error", nil, "", http.StatusBadRequest)
+		}
+	}
+
+	if *s.Target == MessageExportDeliveryTargetS3 && *s.S3Bucket == "" {
+		return NewAppError("Config.IsValid", "model.config.is_valid.message_export.delivery.s3_bucket.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	return nil
+}
+
 type MessageExportSettings struct {
 	EnableExport            *bool   `access:"compliance_compliance_export"`
 	ExportFormat            *string `access:"compliance_compliance_export"`
@@ -3415,6 +3904,9 @@ type MessageExportSettings struct {
 
 	// formatter-specific settings - these are only expected to be non-nil if ExportFormat is set to the associated format
 	GlobalRelaySettings *GlobalRelayMessageExportSettings `access:"compliance_compliance_export"`
+	EmlSettings         *EmlMessageExportSettings         `access:"compliance_compliance_export"`
+
+	DeliverySettings *MessageExportDeliverySettings `access:"compliance_compliance_export"`
 }
 
 func (s *MessageExportSettings) SetDefaults() {
@@ -3454,6 +3946,16 @@ func (s *MessageExportSettings) SetDefaults() {
 		s.GlobalRelaySettings = &GlobalRelayMessageExportSettings{}
 	}
 	s.GlobalRelaySettings.SetDefaults()
+
+	if s.EmlSettings == nil {
+		s.EmlSettings = &EmlMessageExportSettings{}
+	}
+	s.EmlSettings.SetDefaults()
+
+	if s.DeliverySettings == nil {
+		s.DeliverySettings = &MessageExportDeliverySettings{}
+	}
+	s.DeliverySettings.SetDefaults()
 }
 
 type DisplaySettings struct {
@@ -3665,6 +4167,7 @@ type Config struct {
 	AnalyticsSettings           AnalyticsSettings
 	ElasticsearchSettings       ElasticsearchSettings
 	BleveSettings               BleveSettings
+	PostgresSearchSettings      PostgresSearchSettings
 	DataRetentionSettings       DataRetentionSettings
 	MessageExportSettings       MessageExportSettings
 	JobSettings                 JobSettings
@@ -3775,6 +4278,7 @@ func (o *Config) SetDefaults() {
 	o.LocalizationSettings.SetDefaults()
 	o.ElasticsearchSettings.SetDefaults()
 	o.BleveSettings.SetDefaults()
+	o.PostgresSearchSettings.SetDefaults()
 	o.NativeAppSettings.SetDefaults()
 	o.DataRetentionSettings.SetDefaults()
 	o.RateLimitSettings.SetDefaults()
@@ -3802,10 +4306,6 @@ func (o *Config) IsValid() *AppError {
 		return NewAppError("Config.IsValid", "model.config.is_valid.site_url_email_batching.app_error", nil, "", http.StatusBadRequest)
 	}
 
-	if *o.ClusterSettings.Enable && *o.EmailSettings.EnableEmailBatching {
-		return NewAppError("Config.IsValid", "model.config.is_valid.cluster_email_batching.app_error", nil, "", http.StatusBadRequest)
-	}
-
 	if appErr := o.CacheSettings.isValid(); appErr != nil {
 		return appErr
 	}
@@ -3850,6 +4350,22 @@ func (o *Config) IsValid() *AppError {
 		return appErr
 	}
 
+	if *o.RateLimitSettings.StoreType == RateLimitStoreTypeRedis && *o.CacheSettings.CacheType != CacheTypeRedis {
+		return NewAppError("Config.IsValid", "model.config.is_valid.rate_limit_store_type_redis.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if appErr := o.ClusterSettings.isValid(); appErr != nil {
+		return appErr
+	}
+
+	if *o.ClusterSettings.MessageTransport == ClusterMessageTransportRedis && *o.CacheSettings.CacheType != CacheTypeRedis {
+		return NewAppError("Config.IsValid", "model.config.is_valid.cluster_message_transport_redis.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *o.ClusterSettings.MessageTransport == ClusterMessageTransportDatabase && *o.SqlSettings.DriverName != DatabaseDriverPostgres {
+		return NewAppError("Config.IsValid", "model.config.is_valid.cluster_message_transport_database.app_error", nil, "", http.StatusBadRequest)
+	}
+
 	if appErr := o.ServiceSettings.isValid(); appErr != nil {
 		return appErr
 	}
@@ -3862,6 +4378,14 @@ func (o *Config) IsValid() *AppError {
 		return appErr
 	}
 
+	if *o.PostgresSearchSettings.EnableIndexing && *o.SqlSettings.DriverName != DatabaseDriverPostgres {
+		return NewAppError("Config.IsValid", "model.config.is_valid.postgres_search.driver.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if appErr := o.PostgresSearchSettings.isValid(); appErr != nil {
+		return appErr
+	}
+
 	if appErr := o.DataRetentionSettings.isValid(); appErr != nil {
 		return appErr
 	}
@@ -3978,7 +4502,7 @@ func (s *FileSettings) isValid() *AppError {
 		return NewAppError("Config.IsValid", "model.config.is_valid.max_file_size.app_error", nil, "", http.StatusBadRequest)
 	}
 
-	if !(*s.DriverName == ImageDriverLocal || *s.DriverName == ImageDriverS3) {
+	if !(*s.DriverName == ImageDriverLocal || *s.DriverName == ImageDriverS3 || *s.DriverName == ImageDriverGCS || *s.DriverName == ImageDriverAzure) {
 		return NewAppError("Config.IsValid", "model.config.is_valid.file_driver.app_error", nil, "", http.StatusBadRequest)
 	}
 
@@ -4006,6 +4530,24 @@ func (s *FileSettings) isValid() *AppError {
 		return NewAppError("Config.IsValid", "model.config.is_valid.storage_class.app_error", map[string]any{"Value": *s.ExportAmazonS3StorageClass}, "", http.StatusBadRequest)
 	}
 
+	if *s.EnableEncryptionAtRest {
+		for _, key := range append([]string{*s.EncryptionAtRestKey}, s.EncryptionAtRestPreviousKeys...) {
+			if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 32 {
+				return NewAppError("Config.IsValid", "model.config.is_valid.encryption_at_rest_key.app_error", nil, "", http.StatusBadRequest)
+			}
+		}
+	}
+
+	if *s.EnableAntivirusScan {
+		if *s.AntivirusClamdAddress == "" {
+			return NewAppError("Config.IsValid", "model.config.is_valid.antivirus_clamd_address.app_error", nil, "", http.StatusBadRequest)
+		}
+
+		if *s.AntivirusScanTimeoutSeconds <= 0 {
+			return NewAppError("Config.IsValid", "model.config.is_valid.antivirus_scan_timeout.app_error", map[string]any{"Value": *s.AntivirusScanTimeoutSeconds}, "", http.StatusBadRequest)
+		}
+	}
+
 	return nil
 }
 
@@ -4014,10 +4556,6 @@ func (s *EmailSettings) isValid() *AppError {
 		return NewAppError("Config.IsValid", "model.config.is_valid.email_security.app_error", nil, "", http.StatusBadRequest)
 	}
 
-	if *s.EmailBatchingBufferSize <= 0 {
-		return NewAppError("Config.IsValid", "model.config.is_valid.email_batching_buffer_size.app_error", nil, "", http.StatusBadRequest)
-	}
-
 	if *s.EmailBatchingInterval < 30 {
 		return NewAppError("Config.IsValid", "model.config.is_valid.email_batching_interval.app_error", nil, "", http.StatusBadRequest)
 	}
@@ -4042,6 +4580,16 @@ func (s *RateLimitSettings) isValid() *AppError {
 		return NewAppError("Config.IsValid", "model.config.is_valid.max_burst.app_error", nil, "", http.StatusBadRequest)
 	}
 
+	if *s.StoreType != RateLimitStoreTypeMemory && *s.StoreType != RateLimitStoreTypeRedis {
+		return NewAppError("Config.IsValid", "model.config.is_valid.rate_limit_store_type.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	for _, policy := range s.Policies {
+		if appErr := policy.isValid(); appErr != nil {
+			return appErr
+		}
+	}
+
 	return nil
 }
 
@@ -4382,6 +4930,23 @@ func (bs *BleveSettings) isValid() *AppError {
 	return nil
 }
 
+func (s *PostgresSearchSettings) isValid() *AppError {
+	if !*s.EnableIndexing && *s.EnableSearching {
+		return NewAppError("Config.IsValid", "model.config.is_valid.postgres_search.enable_searching.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if *s.TextSearchConfig == "" {
+		return NewAppError("Config.IsValid", "model.config.is_valid.postgres_search.text_search_config.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	minBatchSize := 1
+	if *s.BatchSize < minBatchSize {
+		return NewAppError("Config.IsValid", "model.config.is_valid.postgres_search.batch_size.app_error", map[string]any{"BatchSize": minBatchSize}, "", http.StatusBadRequest)
+	}
+
+	return nil
+}
+
 func (s *DataRetentionSettings) isValid() *AppError {
 	if s.MessageRetentionDays == nil || *s.MessageRetentionDays < 0 {
 		return NewAppError("Config.IsValid", "model.config.is_valid.data_retention.message_retention_days_too_low.app_error", nil, "", http.StatusBadRequest)
@@ -4445,7 +5010,7 @@ func (s *MessageExportSettings) isValid() *AppError {
 			return NewAppError("Config.IsValid", "model.config.is_valid.message_export.daily_runtime.app_error", nil, "", http.StatusBadRequest).Wrap(err)
 		} else if s.BatchSize == nil || *s.BatchSize < 0 {
 			return NewAppError("Config.IsValid", "model.config.is_valid.message_export.batch_size.app_error", nil, "", http.StatusBadRequest)
-		} else if s.ExportFormat == nil || (*s.ExportFormat != ComplianceExportTypeActiance && *s.ExportFormat != ComplianceExportTypeGlobalrelay && *s.ExportFormat != ComplianceExportTypeCsv && *s.ExportFormat != ComplianceExportTypeGlobalrelayZip) {
+		} else if s.ExportFormat == nil || (*s.ExportFormat != ComplianceExportTypeActiance && *s.ExportFormat != ComplianceExportTypeGlobalrelay && *s.ExportFormat != ComplianceExportTypeCsv && *s.ExportFormat != ComplianceExportTypeGlobalrelayZip && *s.ExportFormat != ComplianceExportTypeEml && *s.ExportFormat != ComplianceExportTypeJsonl) {
 			return NewAppError("Config.IsValid", "model.config.is_valid.message_export.export_type.app_error", nil, "", http.StatusBadRequest)
 		}
 
@@ -4466,6 +5031,18 @@ func (s *MessageExportSettings) isValid() *AppError {
 				return NewAppError("Config.IsValid", "model.config.is_valid.message_export.global_relay.smtp_password.app_error", nil, "", http.StatusBadRequest)
 			}
 		}
+
+		if *s.ExportFormat == ComplianceExportTypeEml {
+			if s.EmlSettings == nil || s.EmlSettings.Grouping == nil || (*s.EmlSettings.Grouping != EmlExportGroupingPost && *s.EmlSettings.Grouping != EmlExportGroupingChannelDay) {
+				return NewAppError("Config.IsValid", "model.config.is_valid.message_export.eml.grouping.app_error", nil, "", http.StatusBadRequest)
+			}
+		}
+
+		if s.DeliverySettings != nil {
+			if appErr := s.DeliverySettings.isValid(*s.ExportFormat); appErr != nil {
+				return appErr
+			}
+		}
 	}
 	return nil
 }
@@ -4532,6 +5109,30 @@ func (o *Config) Sanitize(pluginManifests []*Manifest) {
 		*o.FileSettings.AmazonS3SecretAccessKey = FakeSetting
 	}
 
+	if o.FileSettings.GoogleCloudStorageCredentialsJSON != nil && *o.FileSettings.GoogleCloudStorageCredentialsJSON != "" {
+		*o.FileSettings.GoogleCloudStorageCredentialsJSON = FakeSetting
+	}
+
+	if o.FileSettings.AzureStorageAccountKey != nil && *o.FileSettings.AzureStorageAccountKey != "" {
+		*o.FileSettings.AzureStorageAccountKey = FakeSetting
+	}
+
+	if o.FileSettings.EncryptionAtRestKey != nil && *o.FileSettings.EncryptionAtRestKey != "" {
+		*o.FileSettings.EncryptionAtRestKey = FakeSetting
+	}
+
+	for i := range o.FileSettings.EncryptionAtRestPreviousKeys {
+		o.FileSettings.EncryptionAtRestPreviousKeys[i] = FakeSetting
+	}
+
+	if o.FileSettings.ExportGoogleCloudStorageCredentialsJSON != nil && *o.FileSettings.ExportGoogleCloudStorageCredentialsJSON != "" {
+		*o.FileSettings.ExportGoogleCloudStorageCredentialsJSON = FakeSetting
+	}
+
+	if o.FileSettings.ExportAzureStorageAccountKey != nil && *o.FileSettings.ExportAzureStorageAccountKey != "" {
+		*o.FileSettings.ExportAzureStorageAccountKey = FakeSetting
+	}
+
 	if o.EmailSettings.SMTPPassword != nil && *o.EmailSettings.SMTPPassword != "" {
 		*o.EmailSettings.SMTPPassword = FakeSetting
 	}
@@ -4582,6 +5183,18 @@ func (o *Config) Sanitize(pluginManifests []*Manifest) {
 		*o.MessageExportSettings.GlobalRelaySettings.SMTPPassword = FakeSetting
 	}
 
+	if delivery := o.MessageExportSettings.DeliverySettings; delivery != nil {
+		if delivery.SFTPPassword != nil && *delivery.SFTPPassword != "" {
+			*delivery.SFTPPassword = FakeSetting
+		}
+		if delivery.SFTPPrivateKey != nil && *delivery.SFTPPrivateKey != "" {
+			*delivery.SFTPPrivateKey = FakeSetting
+		}
+		if delivery.S3SecretAccessKey != nil && *delivery.S3SecretAccessKey != "" {
+			*delivery.S3SecretAccessKey = FakeSetting
+		}
+	}
+
 	if o.ServiceSettings.SplitKey != nil {
 		*o.ServiceSettings.SplitKey = FakeSetting
 	}
@@ -4,6 +4,7 @@
 package model
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -161,6 +162,120 @@ func TestConfigDefaultFileSettingsS3SSE(t *testing.T) {
 	require.False(t, *c1.FileSettings.AmazonS3SSE)
 }
 
+func TestConfigFileSettingsEncryptionAtRest(t *testing.T) {
+	c1 := Config{}
+	c1.SetDefaults()
+
+	require.False(t, *c1.FileSettings.EnableEncryptionAtRest)
+	require.Nil(t, c1.FileSettings.isValid())
+
+	*c1.FileSettings.EnableEncryptionAtRest = true
+	require.NotNil(t, c1.FileSettings.isValid())
+
+	*c1.FileSettings.EncryptionAtRestKey = base64.StdEncoding.EncodeToString(make([]byte, 32))
+	require.Nil(t, c1.FileSettings.isValid())
+
+	c1.FileSettings.EncryptionAtRestPreviousKeys = []string{base64.StdEncoding.EncodeToString(make([]byte, 16))}
+	require.NotNil(t, c1.FileSettings.isValid())
+
+	c1.Sanitize(nil)
+	require.Equal(t, FakeSetting, *c1.FileSettings.EncryptionAtRestKey)
+	require.Equal(t, []string{FakeSetting}, c1.FileSettings.EncryptionAtRestPreviousKeys)
+}
+
+func TestConfigFileSettingsAntivirus(t *testing.T) {
+	c1 := Config{}
+	c1.SetDefaults()
+
+	require.False(t, *c1.FileSettings.EnableAntivirusScan)
+	require.Equal(t, "localhost:3310", *c1.FileSettings.AntivirusClamdAddress)
+	require.Equal(t, 60, *c1.FileSettings.AntivirusScanTimeoutSeconds)
+
+	*c1.FileSettings.EnableAntivirusScan = true
+	require.Nil(t, c1.FileSettings.isValid())
+
+	*c1.FileSettings.AntivirusScanTimeoutSeconds = 0
+	require.NotNil(t, c1.FileSettings.isValid())
+
+	*c1.FileSettings.AntivirusScanTimeoutSeconds = 60
+	*c1.FileSettings.AntivirusClamdAddress = ""
+	require.NotNil(t, c1.FileSettings.isValid())
+}
+
+func TestConfigClusterMessageTransport(t *testing.T) {
+	c1 := Config{}
+	c1.SetDefaults()
+
+	require.Equal(t, ClusterMessageTransportAuto, *c1.ClusterSettings.MessageTransport)
+	require.Nil(t, c1.ClusterSettings.isValid())
+
+	*c1.ClusterSettings.MessageTransport = "carrier_pigeon"
+	require.NotNil(t, c1.ClusterSettings.isValid())
+
+	*c1.ClusterSettings.MessageTransport = ClusterMessageTransportRedis
+	require.Nil(t, c1.ClusterSettings.isValid())
+	require.NotNil(t, c1.IsValid(), "the redis transport requires the redis cache")
+	*c1.CacheSettings.CacheType = CacheTypeRedis
+	*c1.CacheSettings.RedisAddress = "localhost:6379"
+	*c1.CacheSettings.RedisDB = 0
+	require.Nil(t, c1.IsValid())
+
+	*c1.ClusterSettings.MessageTransport = ClusterMessageTransportDatabase
+	*c1.SqlSettings.DriverName = DatabaseDriverMysql
+	require.NotNil(t, c1.IsValid(), "the database transport requires postgres")
+	*c1.SqlSettings.DriverName = DatabaseDriverPostgres
+	require.Nil(t, c1.IsValid())
+}
+
+func TestConfigRateLimitSettings(t *testing.T) {
+	c1 := Config{}
+	c1.SetDefaults()
+
+	require.Equal(t, RateLimitStoreTypeMemory, *c1.RateLimitSettings.StoreType)
+	require.Empty(t, c1.RateLimitSettings.Policies)
+
+	c1.RateLimitSettings.Policies = []*RateLimitPolicy{{Route: NewPointer("/api/v4/users/login")}}
+	c1.RateLimitSettings.SetDefaults()
+	require.Equal(t, RateLimitVaryByUser, *c1.RateLimitSettings.Policies[0].VaryBy)
+	require.Nil(t, c1.RateLimitSettings.isValid())
+
+	*c1.RateLimitSettings.Policies[0].VaryBy = "header"
+	require.NotNil(t, c1.RateLimitSettings.isValid())
+
+	*c1.RateLimitSettings.Policies[0].VaryBy = RateLimitVaryByIP
+	*c1.RateLimitSettings.Policies[0].Route = "api/v4/users/login"
+	require.NotNil(t, c1.RateLimitSettings.isValid())
+
+	*c1.RateLimitSettings.Policies[0].Route = "/api/v4/users/login"
+	c1.RateLimitSettings.Policies[0].Methods = []string{"post", "GET"}
+	c1.RateLimitSettings.Policies[0].AuthTypes = []string{RateLimitAuthTypePAT, RateLimitAuthTypeBot}
+	require.Equal(t, "/api/v4/users/login", *c1.RateLimitSettings.Policies[0].Name)
+	require.Nil(t, c1.RateLimitSettings.isValid())
+
+	c1.RateLimitSettings.Policies[0].Methods = []string{"CONNECT"}
+	require.NotNil(t, c1.RateLimitSettings.isValid())
+
+	c1.RateLimitSettings.Policies[0].Methods = nil
+	c1.RateLimitSettings.Policies[0].AuthTypes = []string{"cookie"}
+	require.NotNil(t, c1.RateLimitSettings.isValid())
+
+	c1.RateLimitSettings.Policies[0].AuthTypes = nil
+	*c1.RateLimitSettings.Policies[0].MaxBurst = 0
+	require.NotNil(t, c1.RateLimitSettings.isValid())
+
+	c1.RateLimitSettings.Policies = nil
+	*c1.RateLimitSettings.StoreType = RateLimitStoreTypeRedis
+	require.Nil(t, c1.RateLimitSettings.isValid())
+	require.NotNil(t, c1.IsValid(), "the redis store requires the redis cache")
+	*c1.CacheSettings.CacheType = CacheTypeRedis
+	*c1.CacheSettings.RedisAddress = "localhost:6379"
+	*c1.CacheSettings.RedisDB = 0
+	require.Nil(t, c1.IsValid())
+
+	*c1.RateLimitSettings.StoreType = "disk"
+	require.NotNil(t, c1.RateLimitSettings.isValid())
+}
+
 func TestConfigDefaultSignatureAlgorithm(t *testing.T) {
 	c1 := Config{}
 	c1.SetDefaults()
@@ -500,6 +615,131 @@ func TestMessageExportSettingsIsValidActiance(t *testing.T) {
 	require.Nil(t, mes.isValid())
 }
 
+func TestMessageExportSettingsIsValidJsonl(t *testing.T) {
+	mes := &MessageExportSettings{
+		EnableExport:        NewPointer(true),
+		ExportFormat:        NewPointer(ComplianceExportTypeJsonl),
+		ExportFromTimestamp: NewPointer(int64(0)),
+		DailyRunTime:        NewPointer("15:04"),
+		BatchSize:           NewPointer(100),
+	}
+
+	// should pass because everything is valid
+	require.Nil(t, mes.isValid())
+}
+
+func TestMessageExportSettingsIsValidEml(t *testing.T) {
+	mes := &MessageExportSettings{
+		EnableExport:        NewPointer(true),
+		ExportFormat:        NewPointer(ComplianceExportTypeEml),
+		ExportFromTimestamp: NewPointer(int64(0)),
+		DailyRunTime:        NewPointer("15:04"),
+		BatchSize:           NewPointer(100),
+	}
+
+	// should fail because eml settings are missing
+	require.NotNil(t, mes.isValid())
+
+	mes.EmlSettings = &EmlMessageExportSettings{}
+	mes.EmlSettings.SetDefaults()
+	require.Equal(t, EmlExportGroupingPost, *mes.EmlSettings.Grouping)
+	require.Nil(t, mes.isValid())
+
+	*mes.EmlSettings.Grouping = EmlExportGroupingChannelDay
+	require.Nil(t, mes.isValid())
+
+	*mes.EmlSettings.Grouping = "thread"
+	require.NotNil(t, mes.isValid())
+}
+
+func TestMessageExportSettingsIsValidDelivery(t *testing.T) {
+	newSettings := func(exportFormat string) *MessageExportSettings {
+		mes := &MessageExportSettings{
+			EnableExport:        NewPointer(true),
+			ExportFormat:        NewPointer(exportFormat),
+			ExportFromTimestamp: NewPointer(int64(0)),
+			DailyRunTime:        NewPointer("15:04"),
+			BatchSize:           NewPointer(100),
+			DeliverySettings:    &MessageExportDeliverySettings{},
+		}
+		mes.DeliverySettings.SetDefaults()
+		return mes
+	}
+
+	t.Run("no target", func(t *testing.T) {
+		mes := newSettings(ComplianceExportTypeGlobalrelay)
+		mes.GlobalRelaySettings = &GlobalRelayMessageExportSettings{
+			CustomerType: NewPointer(GlobalrelayCustomerTypeA10),
+			EmailAddress: NewPointer("test@mattermost.com"),
+			SMTPUsername: NewPointer("username"),
+			SMTPPassword: NewPointer("password"),
+		}
+		require.Nil(t, mes.isValid())
+	})
+
+	t.Run("unknown target", func(t *testing.T) {
+		mes := newSettings(ComplianceExportTypeJsonl)
+		*mes.DeliverySettings.Target = "ftp"
+		require.NotNil(t, mes.isValid())
+	})
+
+	t.Run("sftp", func(t *testing.T) {
+		mes := newSettings(ComplianceExportTypeJsonl)
+		*mes.DeliverySettings.Target = MessageExportDeliveryTargetSFTP
+		require.NotNil(t, mes.isValid(), "the host is missing")
+
+		*mes.DeliverySettings.SFTPHost = "archive.example.com"
+		*mes.DeliverySettings.SFTPUsername = "mattermost"
+		*mes.DeliverySettings.SFTPPassword = "password"
+		require.NotNil(t, mes.isValid(), "the host key is missing")
+
+		*mes.DeliverySettings.SFTPHostKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl"
+		require.Nil(t, mes.isValid())
+
+		*mes.DeliverySettings.SFTPPassword = ""
+		require.NotNil(t, mes.isValid(), "the credentials are missing")
+
+		*mes.DeliverySettings.SFTPPrivateKey = "private key"
+		require.Nil(t, mes.isValid())
+
+		*mes.DeliverySettings.SFTPPort = 70000
+		require.NotNil(t, mes.isValid())
+
+		*mes.DeliverySettings.SFTPPort = 22
+		*mes.DeliverySettings.RetryIntervalSeconds = 0
+		require.NotNil(t, mes.isValid())
+
+		*mes.DeliverySettings.RetryIntervalSeconds = 30
+		*mes.DeliverySettings.MaxRetries = -1
+		require.NotNil(t, mes.isValid())
+	})
+
+	t.Run("s3", func(t *testing.T) {
+		mes := newSettings(ComplianceExportTypeCsv)
+		*mes.DeliverySettings.Target = MessageExportDeliveryTargetS3
+		require.NotNil(t, mes.isValid(), "the bucket is missing")
+
+		*mes.DeliverySettings.S3Bucket = "archive"
+		require.Nil(t, mes.isValid())
+	})
+
+	t.Run("global relay sent by email", func(t *testing.T) {
+		mes := newSettings(ComplianceExportTypeGlobalrelay)
+		mes.GlobalRelaySettings = &GlobalRelayMessageExportSettings{
+			CustomerType: NewPointer(GlobalrelayCustomerTypeA10),
+			EmailAddress: NewPointer("test@mattermost.com"),
+			SMTPUsername: NewPointer("username"),
+			SMTPPassword: NewPointer("password"),
+		}
+		*mes.DeliverySettings.Target = MessageExportDeliveryTargetS3
+		*mes.DeliverySettings.S3Bucket = "archive"
+		require.NotNil(t, mes.isValid())
+
+		*mes.ExportFormat = ComplianceExportTypeGlobalrelayZip
+		require.Nil(t, mes.isValid())
+	})
+}
+
 func TestMessageExportSettingsIsValidGlobalRelaySettingsMissing(t *testing.T) {
 	mes := &MessageExportSettings{
 		EnableExport:        NewPointer(true),
@@ -1379,6 +1619,9 @@ func TestConfigSanitize(t *testing.T) {
 	*c.EmailSettings.SMTPPassword = "baz"
 	*c.GitLabSettings.Secret = "bingo"
 	*c.OpenIdSettings.Secret = "secret"
+	*c.MessageExportSettings.DeliverySettings.SFTPPassword = "sftp password"
+	*c.MessageExportSettings.DeliverySettings.SFTPPrivateKey = "sftp key"
+	*c.MessageExportSettings.DeliverySettings.S3SecretAccessKey = "s3 secret"
 	c.SqlSettings.DataSourceReplicas = []string{"stuff"}
 	c.SqlSettings.DataSourceSearchReplicas = []string{"stuff"}
 	c.SqlSettings.ReplicaLagSettings = []*ReplicaLagSettings{{
@@ -1400,6 +1643,9 @@ func TestConfigSanitize(t *testing.T) {
 	assert.Equal(t, FakeSetting, *c.ElasticsearchSettings.Password)
 	assert.Equal(t, FakeSetting, c.SqlSettings.DataSourceReplicas[0])
 	assert.Equal(t, FakeSetting, c.SqlSettings.DataSourceSearchReplicas[0])
+	assert.Equal(t, FakeSetting, *c.MessageExportSettings.DeliverySettings.SFTPPassword)
+	assert.Equal(t, FakeSetting, *c.MessageExportSettings.DeliverySettings.SFTPPrivateKey)
+	assert.Equal(t, FakeSetting, *c.MessageExportSettings.DeliverySettings.S3SecretAccessKey)
 
 	require.Len(t, c.SqlSettings.ReplicaLagSettings, 1)
 	assert.Equal(t, FakeSetting, *c.SqlSettings.ReplicaLagSettings[0].DataSource)
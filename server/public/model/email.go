@@ -0,0 +1,16 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package model
+
+// Email is an email rendered by the server from one of its templates, about to be sent
+// through the configured SMTP server.
+type Email struct {
+	To       string `json:"to"`
+	CC       string `json:"cc,omitempty"`
+	Subject  string `json:"subject"`
+	HTMLBody string `json:"html_body"`
+	TextBody string `json:"text_body"`
+	// Category identifies the kind of email being sent, e.g. "NotificationEmail" or "InviteEmail".
+	Category string `json:"category,omitempty"`
+}
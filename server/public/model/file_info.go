@@ -57,6 +57,13 @@ type FileInfo struct {
 	Content         string  `json:"-"`
 	RemoteId        *string `json:"remote_id"`
 	Archived        bool    `json:"archived"`
+	// ContentHash is the SHA-256 of the file content when the file is stored as a
+	// deduplicated blob shared with the other FileInfos having the same hash.
+	ContentHash string `json:"-"`
+	// QuarantinedAt is set when the antivirus scan flagged the file content,
+	// which then can no longer be downloaded.
+	QuarantinedAt    int64  `json:"quarantined_at,omitempty"`
+	QuarantineReason string `json:"-"`
 }
 
 func (fi *FileInfo) Auditable() map[string]interface{} {
@@ -124,6 +131,10 @@ func (fi *FileInfo) IsImage() bool {
 	return strings.HasPrefix(fi.MimeType, "image")
 }
 
+func (fi *FileInfo) IsQuarantined() bool {
+	return fi.QuarantinedAt != 0
+}
+
 func (fi *FileInfo) IsSvg() bool {
 	return fi.MimeType == "image/svg+xml"
 }
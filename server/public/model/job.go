@@ -16,6 +16,7 @@ const (
 	JobTypeElasticsearchPostIndexing     = "elasticsearch_post_indexing"
 	JobTypeElasticsearchPostAggregation  = "elasticsearch_post_aggregation"
 	JobTypeBlevePostIndexing             = "bleve_post_indexing"
+	JobTypePostgresPostIndexing          = "postgres_post_indexing"
 	JobTypeLdapSync                      = "ldap_sync"
 	JobTypeMigrations                    = "migrations"
 	JobTypePlugins                       = "plugins"
@@ -44,6 +45,12 @@ const (
 	JobTypeExportUsersToCSV              = "export_users_to_csv"
 	JobTypeDeleteDmsPreferencesMigration = "delete_dms_preferences_migration"
 	JobTypeMobileSessionMetadata         = "mobile_session_metadata"
+	JobTypeFileEncryptionKeyRotation     = "file_encryption_key_rotation"
+	JobTypeFileDeduplicationMigration    = "file_deduplication_migration"
+	JobTypeAntivirusRescan               = "antivirus_rescan"
+	JobTypeLegalHoldExport               = "legal_hold_export"
+	JobTypeSlackImport                   = "slack_import"
+	JobTypeEmailBatching                 = "email_batching"
 
 	JobStatusPending         = "pending"
 	JobStatusInProgress      = "in_progress"
@@ -60,6 +67,7 @@ var AllJobTypes = [...]string{
 	JobTypeElasticsearchPostIndexing,
 	JobTypeElasticsearchPostAggregation,
 	JobTypeBlevePostIndexing,
+	JobTypePostgresPostIndexing,
 	JobTypeLdapSync,
 	JobTypeMigrations,
 	JobTypePlugins,
@@ -77,6 +85,10 @@ var AllJobTypes = [...]string{
 	JobTypeCleanupDesktopTokens,
 	JobTypeRefreshPostStats,
 	JobTypeMobileSessionMetadata,
+	JobTypeFileEncryptionKeyRotation,
+	JobTypeAntivirusRescan,
+	JobTypeLegalHoldExport,
+	JobTypeSlackImport,
 }
 
 type Job struct {
@@ -0,0 +1,142 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package model
+
+import (
+	"net/http"
+	"slices"
+	"unicode/utf8"
+)
+
+const (
+	LegalHoldDisplayNameMaxRunes = 64
+	LegalHoldDescriptionMaxRunes = 1024
+	LegalHoldMaxUsersAndChannels = 1000
+)
+
+// LegalHold freezes the content of a set of users and channels. The posts and
+// files created between StartAt and EndAt by the users, or in the channels, of a
+// hold are exempt from deletion until the hold is released. An EndAt of zero
+// holds the content created after StartAt indefinitely.
+type LegalHold struct {
+	Id          string   `json:"id"`
+	DisplayName string   `json:"display_name"`
+	Description string   `json:"description"`
+	CreatorId   string   `json:"creator_id"`
+	UserIds     []string `json:"user_ids" db:"-"`
+	ChannelIds  []string `json:"channel_ids" db:"-"`
+	StartAt     int64    `json:"start_at"`
+	EndAt       int64    `json:"end_at"`
+	CreateAt    int64    `json:"create_at"`
+	UpdateAt    int64    `json:"update_at"`
+	ReleasedAt  int64    `json:"released_at"`
+}
+
+func (h *LegalHold) Auditable() map[string]any {
+	return map[string]any{
+		"id":           h.Id,
+		"display_name": h.DisplayName,
+		"creator_id":   h.CreatorId,
+		"user_ids":     h.UserIds,
+		"channel_ids":  h.ChannelIds,
+		"start_at":     h.StartAt,
+		"end_at":       h.EndAt,
+		"create_at":    h.CreateAt,
+		"released_at":  h.ReleasedAt,
+	}
+}
+
+func (h *LegalHold) PreSave() {
+	if h.Id == "" {
+		h.Id = NewId()
+	}
+
+	h.CreateAt = GetMillis()
+	h.UpdateAt = h.CreateAt
+	h.ReleasedAt = 0
+
+	slices.Sort(h.UserIds)
+	h.UserIds = slices.Compact(h.UserIds)
+	slices.Sort(h.ChannelIds)
+	h.ChannelIds = slices.Compact(h.ChannelIds)
+}
+
+func (h *LegalHold) IsValid() *AppError {
+	if !IsValidId(h.Id) {
+		return NewAppError("LegalHold.IsValid", "model.legal_hold.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if h.DisplayName == "" || utf8.RuneCountInString(h.DisplayName) > LegalHoldDisplayNameMaxRunes {
+		return NewAppError("LegalHold.IsValid", "model.legal_hold.is_valid.display_name.app_error", map[string]any{"MaxLength": LegalHoldDisplayNameMaxRunes}, "id="+h.Id, http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(h.Description) > LegalHoldDescriptionMaxRunes {
+		return NewAppError("LegalHold.IsValid", "model.legal_hold.is_valid.description.app_error", map[string]any{"MaxLength": LegalHoldDescriptionMaxRunes}, "id="+h.Id, http.StatusBadRequest)
+	}
+
+	if !IsValidId(h.CreatorId) {
+		return NewAppError("LegalHold.IsValid", "model.legal_hold.is_valid.creator_id.app_error", nil, "id="+h.Id, http.StatusBadRequest)
+	}
+
+	if len(h.UserIds) == 0 && len(h.ChannelIds) == 0 {
+		return NewAppError("LegalHold.IsValid", "model.legal_hold.is_valid.empty.app_error", nil, "id="+h.Id, http.StatusBadRequest)
+	}
+
+	if len(h.UserIds)+len(h.ChannelIds) > LegalHoldMaxUsersAndChannels {
+		return NewAppError("LegalHold.IsValid", "model.legal_hold.is_valid.too_many.app_error", map[string]any{"Max": LegalHoldMaxUsersAndChannels}, "id="+h.Id, http.StatusBadRequest)
+	}
+
+	for _, id := range h.UserIds {
+		if !IsValidId(id) {
+			return NewAppError("LegalHold.IsValid", "model.legal_hold.is_valid.user_id.app_error", nil, "id="+h.Id+" user_id="+id, http.StatusBadRequest)
+		}
+	}
+
+	for _, id := range h.ChannelIds {
+		if !IsValidId(id) {
+			return NewAppError("LegalHold.IsValid", "model.legal_hold.is_valid.channel_id.app_error", nil, "id="+h.Id+" channel_id="+id, http.StatusBadRequest)
+		}
+	}
+
+	if h.StartAt < 0 || (h.EndAt != 0 && h.EndAt < h.StartAt) {
+		return NewAppError("LegalHold.IsValid", "model.legal_hold.is_valid.time_range.app_error", nil, "id="+h.Id, http.StatusBadRequest)
+	}
+
+	if h.CreateAt == 0 {
+		return NewAppError("LegalHold.IsValid", "model.legal_hold.is_valid.create_at.app_error", nil, "id="+h.Id, http.StatusBadRequest)
+	}
+
+	if h.UpdateAt == 0 {
+		return NewAppError("LegalHold.IsValid", "model.legal_hold.is_valid.update_at.app_error", nil, "id="+h.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+// IsActive reports whether the hold has not been released yet.
+func (h *LegalHold) IsActive() bool {
+	return h.ReleasedAt == 0
+}
+
+// Covers reports whether the content created at createAt by the given user in
+// the given channel falls under the hold, regardless of whether it is active.
+func (h *LegalHold) Covers(userID, channelID string, createAt int64) bool {
+	if createAt < h.StartAt || (h.EndAt != 0 && createAt > h.EndAt) {
+		return false
+	}
+
+	return (userID != "" && slices.Contains(h.UserIds, userID)) ||
+		(channelID != "" && slices.Contains(h.ChannelIds, channelID))
+}
+
+// LegalHoldsCover reports whether any of the given holds is active and covers
+// the content created at createAt by the given user in the given channel.
+func LegalHoldsCover(holds []*LegalHold, userID, channelID string, createAt int64) bool {
+	for _, hold := range holds {
+		if hold.IsActive() && hold.Covers(userID, channelID, createAt) {
+			return true
+		}
+	}
+	return false
+}
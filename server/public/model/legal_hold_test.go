@@ -0,0 +1,85 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLegalHoldIsValid(t *testing.T) {
+	newHold := func() *LegalHold {
+		hold := &LegalHold{
+			DisplayName: "Case 42",
+			CreatorId:   NewId(),
+			UserIds:     []string{NewId()},
+		}
+		hold.PreSave()
+		return hold
+	}
+
+	require.Nil(t, newHold().IsValid())
+
+	for name, update := range map[string]func(h *LegalHold){
+		"invalid id":            func(h *LegalHold) { h.Id = "junk" },
+		"empty display name":    func(h *LegalHold) { h.DisplayName = "" },
+		"long display name":     func(h *LegalHold) { h.DisplayName = strings.Repeat("a", LegalHoldDisplayNameMaxRunes+1) },
+		"long description":      func(h *LegalHold) { h.Description = strings.Repeat("a", LegalHoldDescriptionMaxRunes+1) },
+		"invalid creator id":    func(h *LegalHold) { h.CreatorId = "" },
+		"no users and channels": func(h *LegalHold) { h.UserIds = nil },
+		"invalid user id":       func(h *LegalHold) { h.UserIds = []string{"junk"} },
+		"invalid channel id":    func(h *LegalHold) { h.ChannelIds = []string{"junk"} },
+		"end before start":      func(h *LegalHold) { h.StartAt, h.EndAt = 2, 1 },
+		"negative start":        func(h *LegalHold) { h.StartAt = -1 },
+	} {
+		t.Run(name, func(t *testing.T) {
+			hold := newHold()
+			update(hold)
+			assert.NotNil(t, hold.IsValid())
+		})
+	}
+}
+
+func TestLegalHoldPreSave(t *testing.T) {
+	userID := NewId()
+	hold := &LegalHold{
+		UserIds:    []string{userID, userID},
+		ReleasedAt: 1,
+	}
+	hold.PreSave()
+
+	assert.True(t, IsValidId(hold.Id))
+	assert.NotZero(t, hold.CreateAt)
+	assert.Equal(t, hold.CreateAt, hold.UpdateAt)
+	assert.Equal(t, []string{userID}, hold.UserIds)
+	assert.True(t, hold.IsActive())
+}
+
+func TestLegalHoldsCover(t *testing.T) {
+	userID := NewId()
+	channelID := NewId()
+	hold := &LegalHold{
+		UserIds:    []string{userID},
+		ChannelIds: []string{channelID},
+		StartAt:    100,
+		EndAt:      200,
+	}
+
+	assert.True(t, hold.Covers(userID, NewId(), 100))
+	assert.True(t, hold.Covers(NewId(), channelID, 200))
+	assert.False(t, hold.Covers(userID, channelID, 99))
+	assert.False(t, hold.Covers(userID, channelID, 201))
+	assert.False(t, hold.Covers(NewId(), NewId(), 150))
+	assert.False(t, hold.Covers("", "", 150))
+
+	hold.EndAt = 0
+	assert.True(t, hold.Covers(userID, "", 1000))
+
+	assert.True(t, LegalHoldsCover([]*LegalHold{hold}, userID, "", 150))
+	hold.ReleasedAt = 300
+	assert.False(t, LegalHoldsCover([]*LegalHold{hold}, userID, "", 150))
+}
@@ -5,6 +5,20 @@ package model
 
 import "encoding/json"
 
+// The delivery of a message export job to the archive configured in
+// MessageExportSettings.DeliverySettings is recorded in the job data under these keys.
+const (
+	MessageExportJobDataDeliveryTarget    = "delivery_target"
+	MessageExportJobDataDeliveryStatus    = "delivery_status"
+	MessageExportJobDataBatchesDelivered  = "batches_delivered"
+	MessageExportJobDataDeliveryAttempts  = "delivery_attempts"
+	MessageExportJobDataDeliveryError     = "delivery_error"
+	MessageExportJobDataLastDeliveredPath = "last_delivered_path"
+
+	MessageExportDeliveryStatusDelivered = "delivered"
+	MessageExportDeliveryStatusFailed    = "failed"
+)
+
 type MessageExport struct {
 	TeamId          *string
 	TeamName        *string
@@ -35,10 +49,12 @@ type MessageExport struct {
 
 // MessageExportCursor retrieves posts in the inclusive range:
 // [LastPostUpdateAt + LastPostId, UntilUpdateAt]
+// When LegalHoldId is set, only the posts covered by that legal hold are retrieved.
 type MessageExportCursor struct {
 	LastPostUpdateAt int64
 	LastPostId       string
 	UntilUpdateAt    int64
+	LegalHoldId      string
 }
 
 // PreviewID returns the value of the post's previewed_post prop, if present, or an empty string.
@@ -7,3 +7,9 @@ type MfaSecret struct {
 	Secret string `json:"secret"`
 	QRCode string `json:"qr_code"`
 }
+
+// MfaRecoveryCodes holds the single-use codes a user can enter in place of an
+// MFA token. They are only ever returned when issued.
+type MfaRecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
@@ -10,6 +10,11 @@ import (
 	"time"
 )
 
+const (
+	SearchTypePosts = "posts"
+	SearchTypeFiles = "files"
+)
+
 var searchTermPuncStart = regexp.MustCompile(`^[^\pL\d\s#"]+`)
 var searchTermPuncEnd = regexp.MustCompile(`[^\pL\p{M}\d\s*"]+$`)
 
@@ -36,6 +36,7 @@ const (
 	SystemLastAccessiblePostTime           = "LastAccessiblePostTime"
 	SystemLastAccessibleFileTime           = "LastAccessibleFileTime"
 	SystemHostedPurchaseNeedsScreening     = "HostedPurchaseNeedsScreening"
+	SystemAntivirusSignatureVersion        = "AntivirusSignatureVersion"
 	AwsMeteringReportInterval              = 1
 	AwsMeteringDimensionUsageHrs           = "UsageHrs"
 	CloudRenewalEmail                      = "CloudRenewalEmail"
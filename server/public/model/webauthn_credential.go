@@ -0,0 +1,95 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"net/http"
+	"unicode/utf8"
+)
+
+const (
+	WebAuthnCredentialNameMaxRunes = 64
+	WebAuthnCredentialIdMaxLength  = 512
+)
+
+// WebAuthnCredential is a public key credential (e.g. a hardware security key)
+// that a user registered as a second authentication factor.
+type WebAuthnCredential struct {
+	Id              string      `json:"id"`
+	UserId          string      `json:"user_id"`
+	Name            string      `json:"name"`
+	CredentialId    string      `json:"credential_id"`
+	PublicKey       string      `json:"public_key,omitempty"`
+	AttestationType string      `json:"attestation_type"`
+	Transports      StringArray `json:"transports"`
+	AAGUID          string      `json:"aaguid"`
+	SignCount       int64       `json:"sign_count"`
+	CreateAt        int64       `json:"create_at"`
+	LastUsedAt      int64       `json:"last_used_at"`
+
+	// RecoveryCodes are only set when registering the key turned MFA on for the user.
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+}
+
+// WebAuthnRegistration is the payload completing the registration of a security key.
+type WebAuthnRegistration struct {
+	Name     string          `json:"name"`
+	Response json.RawMessage `json:"response"`
+}
+
+func (c *WebAuthnCredential) Auditable() map[string]any {
+	return map[string]any{
+		"id":               c.Id,
+		"user_id":          c.UserId,
+		"name":             c.Name,
+		"attestation_type": c.AttestationType,
+		"aaguid":           c.AAGUID,
+		"create_at":        c.CreateAt,
+		"last_used_at":     c.LastUsedAt,
+	}
+}
+
+func (c *WebAuthnCredential) PreSave() {
+	if c.Id == "" {
+		c.Id = NewId()
+	}
+
+	if c.CreateAt == 0 {
+		c.CreateAt = GetMillis()
+	}
+}
+
+func (c *WebAuthnCredential) IsValid() *AppError {
+	if !IsValidId(c.Id) {
+		return NewAppError("WebAuthnCredential.IsValid", "model.webauthn_credential.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if !IsValidId(c.UserId) {
+		return NewAppError("WebAuthnCredential.IsValid", "model.webauthn_credential.is_valid.user_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if c.Name == "" || utf8.RuneCountInString(c.Name) > WebAuthnCredentialNameMaxRunes {
+		return NewAppError("WebAuthnCredential.IsValid", "model.webauthn_credential.is_valid.name.app_error", map[string]any{"MaxLength": WebAuthnCredentialNameMaxRunes}, "", http.StatusBadRequest)
+	}
+
+	if c.CredentialId == "" || len(c.CredentialId) > WebAuthnCredentialIdMaxLength {
+		return NewAppError("WebAuthnCredential.IsValid", "model.webauthn_credential.is_valid.credential_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if c.PublicKey == "" {
+		return NewAppError("WebAuthnCredential.IsValid", "model.webauthn_credential.is_valid.public_key.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if c.CreateAt == 0 {
+		return NewAppError("WebAuthnCredential.IsValid", "model.webauthn_credential.is_valid.create_at.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+// Sanitize removes the key material, which clients never need.
+func (c *WebAuthnCredential) Sanitize() {
+	c.PublicKey = ""
+}
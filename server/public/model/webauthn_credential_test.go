@@ -0,0 +1,67 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebAuthnCredentialIsValid(t *testing.T) {
+	c := WebAuthnCredential{}
+
+	appErr := c.IsValid()
+	require.NotNil(t, appErr)
+	require.Equal(t, "model.webauthn_credential.is_valid.id.app_error", appErr.Id)
+
+	c.Id = NewId()
+	appErr = c.IsValid()
+	require.NotNil(t, appErr)
+	require.Equal(t, "model.webauthn_credential.is_valid.user_id.app_error", appErr.Id)
+
+	c.UserId = NewId()
+	appErr = c.IsValid()
+	require.NotNil(t, appErr)
+	require.Equal(t, "model.webauthn_credential.is_valid.name.app_error", appErr.Id)
+
+	c.Name = strings.Repeat("ü", WebAuthnCredentialNameMaxRunes+1)
+	appErr = c.IsValid()
+	require.NotNil(t, appErr)
+	require.Equal(t, "model.webauthn_credential.is_valid.name.app_error", appErr.Id)
+
+	c.Name = strings.Repeat("ü", WebAuthnCredentialNameMaxRunes)
+	appErr = c.IsValid()
+	require.NotNil(t, appErr)
+	require.Equal(t, "model.webauthn_credential.is_valid.credential_id.app_error", appErr.Id)
+
+	c.CredentialId = strings.Repeat("a", WebAuthnCredentialIdMaxLength+1)
+	appErr = c.IsValid()
+	require.NotNil(t, appErr)
+	require.Equal(t, "model.webauthn_credential.is_valid.credential_id.app_error", appErr.Id)
+
+	c.CredentialId = NewId()
+	appErr = c.IsValid()
+	require.NotNil(t, appErr)
+	require.Equal(t, "model.webauthn_credential.is_valid.public_key.app_error", appErr.Id)
+
+	c.PublicKey = NewId()
+	appErr = c.IsValid()
+	require.NotNil(t, appErr)
+	require.Equal(t, "model.webauthn_credential.is_valid.create_at.app_error", appErr.Id)
+
+	c.CreateAt = GetMillis()
+	require.Nil(t, c.IsValid())
+}
+
+func TestWebAuthnCredentialPreSaveAndSanitize(t *testing.T) {
+	c := WebAuthnCredential{PublicKey: "key"}
+	c.PreSave()
+	require.True(t, IsValidId(c.Id))
+	require.NotZero(t, c.CreateAt)
+
+	c.Sanitize()
+	require.Empty(t, c.PublicKey)
+}
@@ -1161,6 +1161,540 @@ func (s *hooksRPCServer) GenerateSupportData(args *Z_GenerateSupportDataArgs, re
 	return nil
 }
 
+func init() {
+	hookNameToId["EmailWillBeSent"] = EmailWillBeSentID
+}
+
+type Z_EmailWillBeSentArgs struct {
+	A *model.Email
+}
+
+type Z_EmailWillBeSentReturns struct {
+	A *model.Email
+	B string
+}
+
+func (g *hooksRPCClient) EmailWillBeSent(email *model.Email) (*model.Email, string) {
+	_args := &Z_EmailWillBeSentArgs{email}
+	_returns := &Z_EmailWillBeSentReturns{}
+	if g.implemented[EmailWillBeSentID] {
+		if err := g.client.Call("Plugin.EmailWillBeSent", _args, _returns); err != nil {
+			g.log.Error("RPC call EmailWillBeSent to plugin failed.", mlog.Err(err))
+		}
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *hooksRPCServer) EmailWillBeSent(args *Z_EmailWillBeSentArgs, returns *Z_EmailWillBeSentReturns) error {
+	if hook, ok := s.impl.(interface {
+		EmailWillBeSent(email *model.Email) (*model.Email, string)
+	}); ok {
+		returns.A, returns.B = hook.EmailWillBeSent(args.A)
+	} else {
+		return encodableError(fmt.Errorf("Hook EmailWillBeSent called but not implemented."))
+	}
+	return nil
+}
+
+func init() {
+	hookNameToId["ChannelHasBeenUpdated"] = ChannelHasBeenUpdatedID
+}
+
+type Z_ChannelHasBeenUpdatedArgs struct {
+	A *Context
+	B *model.Channel
+}
+
+type Z_ChannelHasBeenUpdatedReturns struct {
+}
+
+func (g *hooksRPCClient) ChannelHasBeenUpdated(c *Context, channel *model.Channel) {
+	_args := &Z_ChannelHasBeenUpdatedArgs{c, channel}
+	_returns := &Z_ChannelHasBeenUpdatedReturns{}
+	if g.implemented[ChannelHasBeenUpdatedID] {
+		if err := g.client.Call("Plugin.ChannelHasBeenUpdated", _args, _returns); err != nil {
+			g.log.Error("RPC call ChannelHasBeenUpdated to plugin failed.", mlog.Err(err))
+		}
+	}
+
+}
+
+func (s *hooksRPCServer) ChannelHasBeenUpdated(args *Z_ChannelHasBeenUpdatedArgs, returns *Z_ChannelHasBeenUpdatedReturns) error {
+	if hook, ok := s.impl.(interface {
+		ChannelHasBeenUpdated(c *Context, channel *model.Channel)
+	}); ok {
+		hook.ChannelHasBeenUpdated(args.A, args.B)
+	} else {
+		return encodableError(fmt.Errorf("Hook ChannelHasBeenUpdated called but not implemented."))
+	}
+	return nil
+}
+
+func init() {
+	hookNameToId["ChannelWillBeArchived"] = ChannelWillBeArchivedID
+}
+
+type Z_ChannelWillBeArchivedArgs struct {
+	A *Context
+	B *model.Channel
+}
+
+type Z_ChannelWillBeArchivedReturns struct {
+	A string
+}
+
+func (g *hooksRPCClient) ChannelWillBeArchived(c *Context, channel *model.Channel) string {
+	_args := &Z_ChannelWillBeArchivedArgs{c, channel}
+	_returns := &Z_ChannelWillBeArchivedReturns{}
+	if g.implemented[ChannelWillBeArchivedID] {
+		if err := g.client.Call("Plugin.ChannelWillBeArchived", _args, _returns); err != nil {
+			g.log.Error("RPC call ChannelWillBeArchived to plugin failed.", mlog.Err(err))
+		}
+	}
+	return _returns.A
+}
+
+func (s *hooksRPCServer) ChannelWillBeArchived(args *Z_ChannelWillBeArchivedArgs, returns *Z_ChannelWillBeArchivedReturns) error {
+	if hook, ok := s.impl.(interface {
+		ChannelWillBeArchived(c *Context, channel *model.Channel) string
+	}); ok {
+		returns.A = hook.ChannelWillBeArchived(args.A, args.B)
+	} else {
+		return encodableError(fmt.Errorf("Hook ChannelWillBeArchived called but not implemented."))
+	}
+	return nil
+}
+
+func init() {
+	hookNameToId["ChannelHasBeenArchived"] = ChannelHasBeenArchivedID
+}
+
+type Z_ChannelHasBeenArchivedArgs struct {
+	A *Context
+	B *model.Channel
+}
+
+type Z_ChannelHasBeenArchivedReturns struct {
+}
+
+func (g *hooksRPCClient) ChannelHasBeenArchived(c *Context, channel *model.Channel) {
+	_args := &Z_ChannelHasBeenArchivedArgs{c, channel}
+	_returns := &Z_ChannelHasBeenArchivedReturns{}
+	if g.implemented[ChannelHasBeenArchivedID] {
+		if err := g.client.Call("Plugin.ChannelHasBeenArchived", _args, _returns); err != nil {
+			g.log.Error("RPC call ChannelHasBeenArchived to plugin failed.", mlog.Err(err))
+		}
+	}
+
+}
+
+func (s *hooksRPCServer) ChannelHasBeenArchived(args *Z_ChannelHasBeenArchivedArgs, returns *Z_ChannelHasBeenArchivedReturns) error {
+	if hook, ok := s.impl.(interface {
+		ChannelHasBeenArchived(c *Context, channel *model.Channel)
+	}); ok {
+		hook.ChannelHasBeenArchived(args.A, args.B)
+	} else {
+		return encodableError(fmt.Errorf("Hook ChannelHasBeenArchived called but not implemented."))
+	}
+	return nil
+}
+
+func init() {
+	hookNameToId["ChannelHasBeenRestored"] = ChannelHasBeenRestoredID
+}
+
+type Z_ChannelHasBeenRestoredArgs struct {
+	A *Context
+	B *model.Channel
+}
+
+type Z_ChannelHasBeenRestoredReturns struct {
+}
+
+func (g *hooksRPCClient) ChannelHasBeenRestored(c *Context, channel *model.Channel) {
+	_args := &Z_ChannelHasBeenRestoredArgs{c, channel}
+	_returns := &Z_ChannelHasBeenRestoredReturns{}
+	if g.implemented[ChannelHasBeenRestoredID] {
+		if err := g.client.Call("Plugin.ChannelHasBeenRestored", _args, _returns); err != nil {
+			g.log.Error("RPC call ChannelHasBeenRestored to plugin failed.", mlog.Err(err))
+		}
+	}
+
+}
+
+func (s *hooksRPCServer) ChannelHasBeenRestored(args *Z_ChannelHasBeenRestoredArgs, returns *Z_ChannelHasBeenRestoredReturns) error {
+	if hook, ok := s.impl.(interface {
+		ChannelHasBeenRestored(c *Context, channel *model.Channel)
+	}); ok {
+		hook.ChannelHasBeenRestored(args.A, args.B)
+	} else {
+		return encodableError(fmt.Errorf("Hook ChannelHasBeenRestored called but not implemented."))
+	}
+	return nil
+}
+
+func init() {
+	hookNameToId["ChannelPrivacyWillBeUpdated"] = ChannelPrivacyWillBeUpdatedID
+}
+
+type Z_ChannelPrivacyWillBeUpdatedArgs struct {
+	A *Context
+	B *model.Channel
+	C model.ChannelType
+}
+
+type Z_ChannelPrivacyWillBeUpdatedReturns struct {
+	A string
+}
+
+func (g *hooksRPCClient) ChannelPrivacyWillBeUpdated(c *Context, channel *model.Channel, privacy model.ChannelType) string {
+	_args := &Z_ChannelPrivacyWillBeUpdatedArgs{c, channel, privacy}
+	_returns := &Z_ChannelPrivacyWillBeUpdatedReturns{}
+	if g.implemented[ChannelPrivacyWillBeUpdatedID] {
+		if err := g.client.Call("Plugin.ChannelPrivacyWillBeUpdated", _args, _returns); err != nil {
+			g.log.Error("RPC call ChannelPrivacyWillBeUpdated to plugin failed.", mlog.Err(err))
+		}
+	}
+	return _returns.A
+}
+
+func (s *hooksRPCServer) ChannelPrivacyWillBeUpdated(args *Z_ChannelPrivacyWillBeUpdatedArgs, returns *Z_ChannelPrivacyWillBeUpdatedReturns) error {
+	if hook, ok := s.impl.(interface {
+		ChannelPrivacyWillBeUpdated(c *Context, channel *model.Channel, privacy model.ChannelType) string
+	}); ok {
+		returns.A = hook.ChannelPrivacyWillBeUpdated(args.A, args.B, args.C)
+	} else {
+		return encodableError(fmt.Errorf("Hook ChannelPrivacyWillBeUpdated called but not implemented."))
+	}
+	return nil
+}
+
+func init() {
+	hookNameToId["TeamHasBeenCreated"] = TeamHasBeenCreatedID
+}
+
+type Z_TeamHasBeenCreatedArgs struct {
+	A *Context
+	B *model.Team
+}
+
+type Z_TeamHasBeenCreatedReturns struct {
+}
+
+func (g *hooksRPCClient) TeamHasBeenCreated(c *Context, team *model.Team) {
+	_args := &Z_TeamHasBeenCreatedArgs{c, team}
+	_returns := &Z_TeamHasBeenCreatedReturns{}
+	if g.implemented[TeamHasBeenCreatedID] {
+		if err := g.client.Call("Plugin.TeamHasBeenCreated", _args, _returns); err != nil {
+			g.log.Error("RPC call TeamHasBeenCreated to plugin failed.", mlog.Err(err))
+		}
+	}
+
+}
+
+func (s *hooksRPCServer) TeamHasBeenCreated(args *Z_TeamHasBeenCreatedArgs, returns *Z_TeamHasBeenCreatedReturns) error {
+	if hook, ok := s.impl.(interface {
+		TeamHasBeenCreated(c *Context, team *model.Team)
+	}); ok {
+		hook.TeamHasBeenCreated(args.A, args.B)
+	} else {
+		return encodableError(fmt.Errorf("Hook TeamHasBeenCreated called but not implemented."))
+	}
+	return nil
+}
+
+func init() {
+	hookNameToId["TeamHasBeenUpdated"] = TeamHasBeenUpdatedID
+}
+
+type Z_TeamHasBeenUpdatedArgs struct {
+	A *Context
+	B *model.Team
+}
+
+type Z_TeamHasBeenUpdatedReturns struct {
+}
+
+func (g *hooksRPCClient) TeamHasBeenUpdated(c *Context, team *model.Team) {
+	_args := &Z_TeamHasBeenUpdatedArgs{c, team}
+	_returns := &Z_TeamHasBeenUpdatedReturns{}
+	if g.implemented[TeamHasBeenUpdatedID] {
+		if err := g.client.Call("Plugin.TeamHasBeenUpdated", _args, _returns); err != nil {
+			g.log.Error("RPC call TeamHasBeenUpdated to plugin failed.", mlog.Err(err))
+		}
+	}
+
+}
+
+func (s *hooksRPCServer) TeamHasBeenUpdated(args *Z_TeamHasBeenUpdatedArgs, returns *Z_TeamHasBeenUpdatedReturns) error {
+	if hook, ok := s.impl.(interface {
+		TeamHasBeenUpdated(c *Context, team *model.Team)
+	}); ok {
+		hook.TeamHasBeenUpdated(args.A, args.B)
+	} else {
+		return encodableError(fmt.Errorf("Hook TeamHasBeenUpdated called but not implemented."))
+	}
+	return nil
+}
+
+func init() {
+	hookNameToId["TeamHasBeenDeleted"] = TeamHasBeenDeletedID
+}
+
+type Z_TeamHasBeenDeletedArgs struct {
+	A *Context
+	B *model.Team
+}
+
+type Z_TeamHasBeenDeletedReturns struct {
+}
+
+func (g *hooksRPCClient) TeamHasBeenDeleted(c *Context, team *model.Team) {
+	_args := &Z_TeamHasBeenDeletedArgs{c, team}
+	_returns := &Z_TeamHasBeenDeletedReturns{}
+	if g.implemented[TeamHasBeenDeletedID] {
+		if err := g.client.Call("Plugin.TeamHasBeenDeleted", _args, _returns); err != nil {
+			g.log.Error("RPC call TeamHasBeenDeleted to plugin failed.", mlog.Err(err))
+		}
+	}
+
+}
+
+func (s *hooksRPCServer) TeamHasBeenDeleted(args *Z_TeamHasBeenDeletedArgs, returns *Z_TeamHasBeenDeletedReturns) error {
+	if hook, ok := s.impl.(interface {
+		TeamHasBeenDeleted(c *Context, team *model.Team)
+	}); ok {
+		hook.TeamHasBeenDeleted(args.A, args.B)
+	} else {
+		return encodableError(fmt.Errorf("Hook TeamHasBeenDeleted called but not implemented."))
+	}
+	return nil
+}
+
+func init() {
+	hookNameToId["UserWillBeUpdated"] = UserWillBeUpdatedID
+}
+
+type Z_UserWillBeUpdatedArgs struct {
+	A *Context
+	B *model.User
+	C *model.User
+	D map[string]string
+}
+
+type Z_UserWillBeUpdatedReturns struct {
+	A *model.User
+	B map[string]string
+	C string
+}
+
+func (g *hooksRPCClient) UserWillBeUpdated(c *Context, newUser, oldUser *model.User, attributes map[string]string) (*model.User, map[string]string, string) {
+	_args := &Z_UserWillBeUpdatedArgs{c, newUser, oldUser, attributes}
+	_returns := &Z_UserWillBeUpdatedReturns{}
+	if g.implemented[UserWillBeUpdatedID] {
+		if err := g.client.Call("Plugin.UserWillBeUpdated", _args, _returns); err != nil {
+			g.log.Error("RPC call UserWillBeUpdated to plugin failed.", mlog.Err(err))
+		}
+	}
+	return _returns.A, _returns.B, _returns.C
+}
+
+func (s *hooksRPCServer) UserWillBeUpdated(args *Z_UserWillBeUpdatedArgs, returns *Z_UserWillBeUpdatedReturns) error {
+	if hook, ok := s.impl.(interface {
+		UserWillBeUpdated(c *Context, newUser, oldUser *model.User, attributes map[string]string) (*model.User, map[string]string, string)
+	}); ok {
+		returns.A, returns.B, returns.C = hook.UserWillBeUpdated(args.A, args.B, args.C, args.D)
+	} else {
+		return encodableError(fmt.Errorf("Hook UserWillBeUpdated called but not implemented."))
+	}
+	return nil
+}
+
+func init() {
+	hookNameToId["UserHasBeenUpdated"] = UserHasBeenUpdatedID
+}
+
+type Z_UserHasBeenUpdatedArgs struct {
+	A *Context
+	B *model.User
+	C *model.User
+	D map[string]string
+}
+
+type Z_UserHasBeenUpdatedReturns struct {
+}
+
+func (g *hooksRPCClient) UserHasBeenUpdated(c *Context, newUser, oldUser *model.User, attributes map[string]string) {
+	_args := &Z_UserHasBeenUpdatedArgs{c, newUser, oldUser, attributes}
+	_returns := &Z_UserHasBeenUpdatedReturns{}
+	if g.implemented[UserHasBeenUpdatedID] {
+		if err := g.client.Call("Plugin.UserHasBeenUpdated", _args, _returns); err != nil {
+			g.log.Error("RPC call UserHasBeenUpdated to plugin failed.", mlog.Err(err))
+		}
+	}
+
+}
+
+func (s *hooksRPCServer) UserHasBeenUpdated(args *Z_UserHasBeenUpdatedArgs, returns *Z_UserHasBeenUpdatedReturns) error {
+	if hook, ok := s.impl.(interface {
+		UserHasBeenUpdated(c *Context, newUser, oldUser *model.User, attributes map[string]string)
+	}); ok {
+		hook.UserHasBeenUpdated(args.A, args.B, args.C, args.D)
+	} else {
+		return encodableError(fmt.Errorf("Hook UserHasBeenUpdated called but not implemented."))
+	}
+	return nil
+}
+
+func init() {
+	hookNameToId["CommandWillBeExecuted"] = CommandWillBeExecutedID
+}
+
+type Z_CommandWillBeExecutedArgs struct {
+	A *Context
+	B *model.CommandArgs
+}
+
+type Z_CommandWillBeExecutedReturns struct {
+	A *model.CommandArgs
+	B string
+}
+
+func (g *hooksRPCClient) CommandWillBeExecuted(c *Context, args *model.CommandArgs) (*model.CommandArgs, string) {
+	_args := &Z_CommandWillBeExecutedArgs{c, args}
+	_returns := &Z_CommandWillBeExecutedReturns{}
+	if g.implemented[CommandWillBeExecutedID] {
+		if err := g.client.Call("Plugin.CommandWillBeExecuted", _args, _returns); err != nil {
+			g.log.Error("RPC call CommandWillBeExecuted to plugin failed.", mlog.Err(err))
+		}
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *hooksRPCServer) CommandWillBeExecuted(args *Z_CommandWillBeExecutedArgs, returns *Z_CommandWillBeExecutedReturns) error {
+	if hook, ok := s.impl.(interface {
+		CommandWillBeExecuted(c *Context, args *model.CommandArgs) (*model.CommandArgs, string)
+	}); ok {
+		returns.A, returns.B = hook.CommandWillBeExecuted(args.A, args.B)
+	} else {
+		return encodableError(fmt.Errorf("Hook CommandWillBeExecuted called but not implemented."))
+	}
+	return nil
+}
+
+func init() {
+	hookNameToId["CommandHasBeenExecuted"] = CommandHasBeenExecutedID
+}
+
+type Z_CommandHasBeenExecutedArgs struct {
+	A *Context
+	B *model.CommandArgs
+	C *model.CommandResponse
+	D *model.AppError
+}
+
+type Z_CommandHasBeenExecutedReturns struct {
+}
+
+func (g *hooksRPCClient) CommandHasBeenExecuted(c *Context, args *model.CommandArgs, response *model.CommandResponse, appErr *model.AppError) {
+	_args := &Z_CommandHasBeenExecutedArgs{c, args, response, appErr}
+	_returns := &Z_CommandHasBeenExecutedReturns{}
+	if g.implemented[CommandHasBeenExecutedID] {
+		if err := g.client.Call("Plugin.CommandHasBeenExecuted", _args, _returns); err != nil {
+			g.log.Error("RPC call CommandHasBeenExecuted to plugin failed.", mlog.Err(err))
+		}
+	}
+
+}
+
+func (s *hooksRPCServer) CommandHasBeenExecuted(args *Z_CommandHasBeenExecutedArgs, returns *Z_CommandHasBeenExecutedReturns) error {
+	if hook, ok := s.impl.(interface {
+		CommandHasBeenExecuted(c *Context, args *model.CommandArgs, response *model.CommandResponse, appErr *model.AppError)
+	}); ok {
+		hook.CommandHasBeenExecuted(args.A, args.B, args.C, args.D)
+	} else {
+		return encodableError(fmt.Errorf("Hook CommandHasBeenExecuted called but not implemented."))
+	}
+	return nil
+}
+
+func init() {
+	hookNameToId["SearchWillBeExecuted"] = SearchWillBeExecutedID
+}
+
+type Z_SearchWillBeExecutedArgs struct {
+	A *Context
+	B string
+	C string
+	D string
+	E []*model.SearchParams
+}
+
+type Z_SearchWillBeExecutedReturns struct {
+	A []*model.SearchParams
+	B string
+}
+
+func (g *hooksRPCClient) SearchWillBeExecuted(c *Context, searchType, userID, teamID string, params []*model.SearchParams) ([]*model.SearchParams, string) {
+	_args := &Z_SearchWillBeExecutedArgs{c, searchType, userID, teamID, params}
+	_returns := &Z_SearchWillBeExecutedReturns{}
+	if g.implemented[SearchWillBeExecutedID] {
+		if err := g.client.Call("Plugin.SearchWillBeExecuted", _args, _returns); err != nil {
+			g.log.Error("RPC call SearchWillBeExecuted to plugin failed.", mlog.Err(err))
+		}
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *hooksRPCServer) SearchWillBeExecuted(args *Z_SearchWillBeExecutedArgs, returns *Z_SearchWillBeExecutedReturns) error {
+	if hook, ok := s.impl.(interface {
+		SearchWillBeExecuted(c *Context, searchType, userID, teamID string, params []*model.SearchParams) ([]*model.SearchParams, string)
+	}); ok {
+		returns.A, returns.B = hook.SearchWillBeExecuted(args.A, args.B, args.C, args.D, args.E)
+	} else {
+		return encodableError(fmt.Errorf("Hook SearchWillBeExecuted called but not implemented."))
+	}
+	return nil
+}
+
+func init() {
+	hookNameToId["SearchResultsWillBeReturned"] = SearchResultsWillBeReturnedID
+}
+
+type Z_SearchResultsWillBeReturnedArgs struct {
+	A *Context
+	B string
+	C *model.PostSearchResults
+	D *model.FileInfoList
+}
+
+type Z_SearchResultsWillBeReturnedReturns struct {
+	A *model.PostSearchResults
+	B *model.FileInfoList
+}
+
+func (g *hooksRPCClient) SearchResultsWillBeReturned(c *Context, userID string, postResults *model.PostSearchResults, fileResults *model.FileInfoList) (*model.PostSearchResults, *model.FileInfoList) {
+	_args := &Z_SearchResultsWillBeReturnedArgs{c, userID, postResults, fileResults}
+	_returns := &Z_SearchResultsWillBeReturnedReturns{}
+	if g.implemented[SearchResultsWillBeReturnedID] {
+		if err := g.client.Call("Plugin.SearchResultsWillBeReturned", _args, _returns); err != nil {
+			g.log.Error("RPC call SearchResultsWillBeReturned to plugin failed.", mlog.Err(err))
+		}
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *hooksRPCServer) SearchResultsWillBeReturned(args *Z_SearchResultsWillBeReturnedArgs, returns *Z_SearchResultsWillBeReturnedReturns) error {
+	if hook, ok := s.impl.(interface {
+		SearchResultsWillBeReturned(c *Context, userID string, postResults *model.PostSearchResults, fileResults *model.FileInfoList) (*model.PostSearchResults, *model.FileInfoList)
+	}); ok {
+		returns.A, returns.B = hook.SearchResultsWillBeReturned(args.A, args.B, args.C, args.D)
+	} else {
+		return encodableError(fmt.Errorf("Hook SearchResultsWillBeReturned called but not implemented."))
+	}
+	return nil
+}
+
 type Z_RegisterCommandArgs struct {
 	A *model.Command
 }
@@ -61,6 +61,21 @@ const (
 	OnSharedChannelsAttachmentSyncMsgID       = 43
 	OnSharedChannelsProfileImageSyncMsgID     = 44
 	GenerateSupportDataID                     = 45
+	EmailWillBeSentID                         = 46
+	ChannelHasBeenUpdatedID                   = 47
+	ChannelWillBeArchivedID                   = 48
+	ChannelHasBeenArchivedID                  = 49
+	ChannelHasBeenRestoredID                  = 50
+	ChannelPrivacyWillBeUpdatedID             = 51
+	TeamHasBeenCreatedID                      = 52
+	TeamHasBeenUpdatedID                      = 53
+	TeamHasBeenDeletedID                      = 54
+	UserWillBeUpdatedID                       = 55
+	UserHasBeenUpdatedID                      = 56
+	CommandWillBeExecutedID                   = 57
+	CommandHasBeenExecutedID                  = 58
+	SearchWillBeExecutedID                    = 59
+	SearchResultsWillBeReturnedID             = 60
 	TotalHooksID                              = iota
 )
 
@@ -395,4 +410,137 @@ type Hooks interface {
 	//
 	// Minimum server version: 9.8
 	GenerateSupportData(c *Context) ([]*model.FileData, error)
+
+	// EmailWillBeSent is invoked before an email rendered by the server is sent to the SMTP server.
+	// The email has its subject, HTML and plain text bodies already rendered for the recipient.
+	//
+	// To reject an email, return an non-empty string describing why the email was rejected.
+	// To modify the email, return the replacement, non-nil *model.Email and an empty string.
+	// To allow the email without modification, return a nil *model.Email and an empty string.
+	//
+	// A replacement with an empty plain text body gets it derived from its HTML body.
+	//
+	// Note that this method will not be called for the test emails sent from the System Console.
+	//
+	// Minimum server version: 10.5
+	EmailWillBeSent(email *model.Email) (*model.Email, string)
+
+	// ChannelHasBeenUpdated is invoked after a channel has been updated in the database,
+	// for example when it is renamed, its header or purpose changes or it is converted between
+	// public and private.
+	//
+	// Minimum server version: 10.5
+	ChannelHasBeenUpdated(c *Context, channel *model.Channel)
+
+	// ChannelWillBeArchived is invoked before a channel is archived.
+	//
+	// To reject the archiving of the channel, return a non-empty string describing why it was rejected.
+	// To allow it, return an empty string.
+	//
+	// Minimum server version: 10.5
+	ChannelWillBeArchived(c *Context, channel *model.Channel) string
+
+	// ChannelHasBeenArchived is invoked after a channel has been archived in the database.
+	//
+	// Minimum server version: 10.5
+	ChannelHasBeenArchived(c *Context, channel *model.Channel)
+
+	// ChannelHasBeenRestored is invoked after an archived channel has been restored in the database.
+	//
+	// Minimum server version: 10.5
+	ChannelHasBeenRestored(c *Context, channel *model.Channel)
+
+	// ChannelPrivacyWillBeUpdated is invoked before a channel is converted between public and private.
+	// The channel is passed as currently stored, privacy is the type it is being converted to.
+	//
+	// To reject the conversion, return a non-empty string describing why it was rejected.
+	// To allow it, return an empty string.
+	//
+	// Minimum server version: 10.5
+	ChannelPrivacyWillBeUpdated(c *Context, channel *model.Channel, privacy model.ChannelType) string
+
+	// TeamHasBeenCreated is invoked after a team has been committed to the database.
+	//
+	// Minimum server version: 10.5
+	TeamHasBeenCreated(c *Context, team *model.Team)
+
+	// TeamHasBeenUpdated is invoked after a team has been updated in the database, including
+	// when its privacy or scheme changes and when it is restored.
+	//
+	// Minimum server version: 10.5
+	TeamHasBeenUpdated(c *Context, team *model.Team)
+
+	// TeamHasBeenDeleted is invoked after a team has been archived or permanently deleted.
+	// The DeleteAt field of the team is set in both cases.
+	//
+	// Minimum server version: 10.5
+	TeamHasBeenDeleted(c *Context, team *model.Team)
+
+	// UserWillBeUpdated is invoked before a user's profile or custom profile attribute values are
+	// updated in the database. newUser is the profile about to be saved and oldUser the stored one.
+	// attributes maps the IDs of the custom profile attribute fields being set to their new values,
+	// it is nil when only the profile is updated. When only custom profile attribute values are
+	// updated, newUser and oldUser are the same stored profile.
+	//
+	// To reject the update, return a non-empty string describing why it was rejected.
+	// To modify the update, return a replacement, non-nil *model.User or attribute values and an empty string.
+	// A replacement user is only used when the profile is updated, replacement attribute values
+	// only when custom profile attribute values are updated.
+	// To allow the update without modification, return a nil *model.User, nil attribute values and an empty string.
+	//
+	// Note that this method will be called for updates made by plugins, including the plugin that
+	// made the update.
+	//
+	// Minimum server version: 10.5
+	UserWillBeUpdated(c *Context, newUser, oldUser *model.User, attributes map[string]string) (*model.User, map[string]string, string)
+
+	// UserHasBeenUpdated is invoked after a user's profile or custom profile attribute values have
+	// been updated in the database. The parameters are those of UserWillBeUpdated, as saved.
+	//
+	// Minimum server version: 10.5
+	UserHasBeenUpdated(c *Context, newUser, oldUser *model.User, attributes map[string]string)
+
+	// CommandWillBeExecuted is invoked before any slash command is executed, whether it is a
+	// built-in command, a custom command or a command registered by a plugin.
+	//
+	// To reject the command, return a non-empty string describing why the command was rejected.
+	// To modify the command, return the replacement, non-nil *model.CommandArgs and an empty string.
+	// Only the Command of the replacement is used: the other arguments, such as the user, channel
+	// and team the command is executed for, can't be rewritten.
+	// To allow the command without modification, return a nil *model.CommandArgs and an empty string.
+	//
+	// Note that this method will be called for commands executed by plugins, including the plugin that
+	// executed the command.
+	//
+	// Minimum server version: 10.5
+	CommandWillBeExecuted(c *Context, args *model.CommandArgs) (*model.CommandArgs, string)
+
+	// CommandHasBeenExecuted is invoked after a slash command has been executed, with the response
+	// it returned. It is also invoked for the commands rejected by CommandWillBeExecuted and for
+	// the commands that failed, with a nil response and the error they returned.
+	//
+	// Minimum server version: 10.5
+	CommandHasBeenExecuted(c *Context, args *model.CommandArgs, response *model.CommandResponse, appErr *model.AppError)
+
+	// SearchWillBeExecuted is invoked before a user's search for posts or files is executed, once
+	// channel names and usernames in the search have been resolved to their ids. The search type is
+	// either model.SearchTypePosts or model.SearchTypeFiles.
+	//
+	// To reject the search, return a non-empty string describing why the search was rejected.
+	// To modify the search, return the replacement search params and an empty string. Returning an
+	// empty, non-nil list of search params makes the search return no results.
+	// To allow the search without modification, return nil search params and an empty string.
+	//
+	// Minimum server version: 10.5
+	SearchWillBeExecuted(c *Context, searchType, userID, teamID string, params []*model.SearchParams) ([]*model.SearchParams, string)
+
+	// SearchResultsWillBeReturned is invoked before the results of a user's search are returned.
+	// Exactly one of postResults, for a search for posts, and fileResults, for a search for files,
+	// is non-nil.
+	//
+	// To drop or redact results, return the modified, non-nil results of the search.
+	// To return the results without modification, return nil for both.
+	//
+	// Minimum server version: 10.5
+	SearchResultsWillBeReturned(c *Context, userID string, postResults *model.PostSearchResults, fileResults *model.FileInfoList) (*model.PostSearchResults, *model.FileInfoList)
 }
@@ -291,3 +291,100 @@ func (hooks *hooksTimerLayer) GenerateSupportData(c *Context) ([]*model.FileData
 	hooks.recordTime(startTime, "GenerateSupportData", _returnsB == nil)
 	return _returnsA, _returnsB
 }
+
+func (hooks *hooksTimerLayer) EmailWillBeSent(email *model.Email) (*model.Email, string) {
+	startTime := timePkg.Now()
+	_returnsA, _returnsB := hooks.hooksImpl.EmailWillBeSent(email)
+	hooks.recordTime(startTime, "EmailWillBeSent", true)
+	return _returnsA, _returnsB
+}
+
+func (hooks *hooksTimerLayer) ChannelHasBeenUpdated(c *Context, channel *model.Channel) {
+	startTime := timePkg.Now()
+	hooks.hooksImpl.ChannelHasBeenUpdated(c, channel)
+	hooks.recordTime(startTime, "ChannelHasBeenUpdated", true)
+}
+
+func (hooks *hooksTimerLayer) ChannelWillBeArchived(c *Context, channel *model.Channel) string {
+	startTime := timePkg.Now()
+	_returnsA := hooks.hooksImpl.ChannelWillBeArchived(c, channel)
+	hooks.recordTime(startTime, "ChannelWillBeArchived", true)
+	return _returnsA
+}
+
+func (hooks *hooksTimerLayer) ChannelHasBeenArchived(c *Context, channel *model.Channel) {
+	startTime := timePkg.Now()
+	hooks.hooksImpl.ChannelHasBeenArchived(c, channel)
+	hooks.recordTime(startTime, "ChannelHasBeenArchived", true)
+}
+
+func (hooks *hooksTimerLayer) ChannelHasBeenRestored(c *Context, channel *model.Channel) {
+	startTime := timePkg.Now()
+	hooks.hooksImpl.ChannelHasBeenRestored(c, channel)
+	hooks.recordTime(startTime, "ChannelHasBeenRestored", true)
+}
+
+func (hooks *hooksTimerLayer) ChannelPrivacyWillBeUpdated(c *Context, channel *model.Channel, privacy model.ChannelType) string {
+	startTime := timePkg.Now()
+	_returnsA := hooks.hooksImpl.ChannelPrivacyWillBeUpdated(c, channel, privacy)
+	hooks.recordTime(startTime, "ChannelPrivacyWillBeUpdated", true)
+	return _returnsA
+}
+
+func (hooks *hooksTimerLayer) TeamHasBeenCreated(c *Context, team *model.Team) {
+	startTime := timePkg.Now()
+	hooks.hooksImpl.TeamHasBeenCreated(c, team)
+	hooks.recordTime(startTime, "TeamHasBeenCreated", true)
+}
+
+func (hooks *hooksTimerLayer) TeamHasBeenUpdated(c *Context, team *model.Team) {
+	startTime := timePkg.Now()
+	hooks.hooksImpl.TeamHasBeenUpdated(c, team)
+	hooks.recordTime(startTime, "TeamHasBeenUpdated", true)
+}
+
+func (hooks *hooksTimerLayer) TeamHasBeenDeleted(c *Context, team *model.Team) {
+	startTime := timePkg.Now()
+	hooks.hooksImpl.TeamHasBeenDeleted(c, team)
+	hooks.recordTime(startTime, "TeamHasBeenDeleted", true)
+}
+
+func (hooks *hooksTimerLayer) UserWillBeUpdated(c *Context, newUser, oldUser *model.User, attributes map[string]string) (*model.User, map[string]string, string) {
+	startTime := timePkg.Now()
+	_returnsA, _returnsB, _returnsC := hooks.hooksImpl.UserWillBeUpdated(c, newUser, oldUser, attributes)
+	hooks.recordTime(startTime, "UserWillBeUpdated", true)
+	return _returnsA, _returnsB, _returnsC
+}
+
+func (hooks *hooksTimerLayer) UserHasBeenUpdated(c *Context, newUser, oldUser *model.User, attributes map[string]string) {
+	startTime := timePkg.Now()
+	hooks.hooksImpl.UserHasBeenUpdated(c, newUser, oldUser, attributes)
+	hooks.recordTime(startTime, "UserHasBeenUpdated", true)
+}
+
+func (hooks *hooksTimerLayer) CommandWillBeExecuted(c *Context, args *model.CommandArgs) (*model.CommandArgs, string) {
+	startTime := timePkg.Now()
+	_returnsA, _returnsB := hooks.hooksImpl.CommandWillBeExecuted(c, args)
+	hooks.recordTime(startTime, "CommandWillBeExecuted", true)
+	return _returnsA, _returnsB
+}
+
+func (hooks *hooksTimerLayer) CommandHasBeenExecuted(c *Context, args *model.CommandArgs, response *model.CommandResponse, appErr *model.AppError) {
+	startTime := timePkg.Now()
+	hooks.hooksImpl.CommandHasBeenExecuted(c, args, response, appErr)
+	hooks.recordTime(startTime, "CommandHasBeenExecuted", true)
+}
+
+func (hooks *hooksTimerLayer) SearchWillBeExecuted(c *Context, searchType, userID, teamID string, params []*model.SearchParams) ([]*model.SearchParams, string) {
+	startTime := timePkg.Now()
+	_returnsA, _returnsB := hooks.hooksImpl.SearchWillBeExecuted(c, searchType, userID, teamID, params)
+	hooks.recordTime(startTime, "SearchWillBeExecuted", true)
+	return _returnsA, _returnsB
+}
+
+func (hooks *hooksTimerLayer) SearchResultsWillBeReturned(c *Context, userID string, postResults *model.PostSearchResults, fileResults *model.FileInfoList) (*model.PostSearchResults, *model.FileInfoList) {
+	startTime := timePkg.Now()
+	_returnsA, _returnsB := hooks.hooksImpl.SearchResultsWillBeReturned(c, userID, postResults, fileResults)
+	hooks.recordTime(startTime, "SearchResultsWillBeReturned", true)
+	return _returnsA, _returnsB
+}
@@ -20,11 +20,97 @@ type Hooks struct {
 	mock.Mock
 }
 
+// ChannelHasBeenArchived provides a mock function with given fields: c, channel
+func (_m *Hooks) ChannelHasBeenArchived(c *plugin.Context, channel *model.Channel) {
+	_m.Called(c, channel)
+}
+
 // ChannelHasBeenCreated provides a mock function with given fields: c, channel
 func (_m *Hooks) ChannelHasBeenCreated(c *plugin.Context, channel *model.Channel) {
 	_m.Called(c, channel)
 }
 
+// ChannelHasBeenRestored provides a mock function with given fields: c, channel
+func (_m *Hooks) ChannelHasBeenRestored(c *plugin.Context, channel *model.Channel) {
+	_m.Called(c, channel)
+}
+
+// ChannelHasBeenUpdated provides a mock function with given fields: c, channel
+func (_m *Hooks) ChannelHasBeenUpdated(c *plugin.Context, channel *model.Channel) {
+	_m.Called(c, channel)
+}
+
+// ChannelPrivacyWillBeUpdated provides a mock function with given fields: c, channel, privacy
+func (_m *Hooks) ChannelPrivacyWillBeUpdated(c *plugin.Context, channel *model.Channel, privacy model.ChannelType) string {
+	ret := _m.Called(c, channel, privacy)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ChannelPrivacyWillBeUpdated")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(*plugin.Context, *model.Channel, model.ChannelType) string); ok {
+		r0 = rf(c, channel, privacy)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// ChannelWillBeArchived provides a mock function with given fields: c, channel
+func (_m *Hooks) ChannelWillBeArchived(c *plugin.Context, channel *model.Channel) string {
+	ret := _m.Called(c, channel)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ChannelWillBeArchived")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(*plugin.Context, *model.Channel) string); ok {
+		r0 = rf(c, channel)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// CommandHasBeenExecuted provides a mock function with given fields: c, args, response, appErr
+func (_m *Hooks) CommandHasBeenExecuted(c *plugin.Context, args *model.CommandArgs, response *model.CommandResponse, appErr *model.AppError) {
+	_m.Called(c, args, response, appErr)
+}
+
+// CommandWillBeExecuted provides a mock function with given fields: c, args
+func (_m *Hooks) CommandWillBeExecuted(c *plugin.Context, args *model.CommandArgs) (*model.CommandArgs, string) {
+	ret := _m.Called(c, args)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CommandWillBeExecuted")
+	}
+
+	var r0 *model.CommandArgs
+	var r1 string
+	if rf, ok := ret.Get(0).(func(*plugin.Context, *model.CommandArgs) (*model.CommandArgs, string)); ok {
+		return rf(c, args)
+	}
+	if rf, ok := ret.Get(0).(func(*plugin.Context, *model.CommandArgs) *model.CommandArgs); ok {
+		r0 = rf(c, args)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.CommandArgs)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*plugin.Context, *model.CommandArgs) string); ok {
+		r1 = rf(c, args)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	return r0, r1
+}
+
 // ConfigurationWillBeSaved provides a mock function with given fields: newCfg
 func (_m *Hooks) ConfigurationWillBeSaved(newCfg *model.Config) (*model.Config, error) {
 	ret := _m.Called(newCfg)
@@ -55,6 +141,36 @@ func (_m *Hooks) ConfigurationWillBeSaved(newCfg *model.Config) (*model.Config,
 	return r0, r1
 }
 
+// EmailWillBeSent provides a mock function with given fields: email
+func (_m *Hooks) EmailWillBeSent(email *model.Email) (*model.Email, string) {
+	ret := _m.Called(email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EmailWillBeSent")
+	}
+
+	var r0 *model.Email
+	var r1 string
+	if rf, ok := ret.Get(0).(func(*model.Email) (*model.Email, string)); ok {
+		return rf(email)
+	}
+	if rf, ok := ret.Get(0).(func(*model.Email) *model.Email); ok {
+		r0 = rf(email)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Email)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*model.Email) string); ok {
+		r1 = rf(email)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	return r0, r1
+}
+
 // ExecuteCommand provides a mock function with given fields: c, args
 func (_m *Hooks) ExecuteCommand(c *plugin.Context, args *model.CommandArgs) (*model.CommandResponse, *model.AppError) {
 	ret := _m.Called(c, args)
@@ -524,6 +640,68 @@ func (_m *Hooks) RunDataRetention(nowTime int64, batchSize int64) (int64, error)
 	return r0, r1
 }
 
+// SearchResultsWillBeReturned provides a mock function with given fields: c, userID, postResults, fileResults
+func (_m *Hooks) SearchResultsWillBeReturned(c *plugin.Context, userID string, postResults *model.PostSearchResults, fileResults *model.FileInfoList) (*model.PostSearchResults, *model.FileInfoList) {
+	ret := _m.Called(c, userID, postResults, fileResults)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SearchResultsWillBeReturned")
+	}
+
+	var r0 *model.PostSearchResults
+	var r1 *model.FileInfoList
+	if rf, ok := ret.Get(0).(func(*plugin.Context, string, *model.PostSearchResults, *model.FileInfoList) (*model.PostSearchResults, *model.FileInfoList)); ok {
+		return rf(c, userID, postResults, fileResults)
+	}
+	if rf, ok := ret.Get(0).(func(*plugin.Context, string, *model.PostSearchResults, *model.FileInfoList) *model.PostSearchResults); ok {
+		r0 = rf(c, userID, postResults, fileResults)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.PostSearchResults)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*plugin.Context, string, *model.PostSearchResults, *model.FileInfoList) *model.FileInfoList); ok {
+		r1 = rf(c, userID, postResults, fileResults)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.FileInfoList)
+		}
+	}
+
+	return r0, r1
+}
+
+// SearchWillBeExecuted provides a mock function with given fields: c, searchType, userID, teamID, params
+func (_m *Hooks) SearchWillBeExecuted(c *plugin.Context, searchType string, userID string, teamID string, params []*model.SearchParams) ([]*model.SearchParams, string) {
+	ret := _m.Called(c, searchType, userID, teamID, params)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SearchWillBeExecuted")
+	}
+
+	var r0 []*model.SearchParams
+	var r1 string
+	if rf, ok := ret.Get(0).(func(*plugin.Context, string, string, string, []*model.SearchParams) ([]*model.SearchParams, string)); ok {
+		return rf(c, searchType, userID, teamID, params)
+	}
+	if rf, ok := ret.Get(0).(func(*plugin.Context, string, string, string, []*model.SearchParams) []*model.SearchParams); ok {
+		r0 = rf(c, searchType, userID, teamID, params)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.SearchParams)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*plugin.Context, string, string, string, []*model.SearchParams) string); ok {
+		r1 = rf(c, searchType, userID, teamID, params)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	return r0, r1
+}
+
 // ServeHTTP provides a mock function with given fields: c, w, r
 func (_m *Hooks) ServeHTTP(c *plugin.Context, w http.ResponseWriter, r *http.Request) {
 	_m.Called(c, w, r)
@@ -534,6 +712,21 @@ func (_m *Hooks) ServeMetrics(c *plugin.Context, w http.ResponseWriter, r *http.
 	_m.Called(c, w, r)
 }
 
+// TeamHasBeenCreated provides a mock function with given fields: c, team
+func (_m *Hooks) TeamHasBeenCreated(c *plugin.Context, team *model.Team) {
+	_m.Called(c, team)
+}
+
+// TeamHasBeenDeleted provides a mock function with given fields: c, team
+func (_m *Hooks) TeamHasBeenDeleted(c *plugin.Context, team *model.Team) {
+	_m.Called(c, team)
+}
+
+// TeamHasBeenUpdated provides a mock function with given fields: c, team
+func (_m *Hooks) TeamHasBeenUpdated(c *plugin.Context, team *model.Team) {
+	_m.Called(c, team)
+}
+
 // UserHasBeenCreated provides a mock function with given fields: c, user
 func (_m *Hooks) UserHasBeenCreated(c *plugin.Context, user *model.User) {
 	_m.Called(c, user)
@@ -544,6 +737,11 @@ func (_m *Hooks) UserHasBeenDeactivated(c *plugin.Context, user *model.User) {
 	_m.Called(c, user)
 }
 
+// UserHasBeenUpdated provides a mock function with given fields: c, newUser, oldUser, attributes
+func (_m *Hooks) UserHasBeenUpdated(c *plugin.Context, newUser *model.User, oldUser *model.User, attributes map[string]string) {
+	_m.Called(c, newUser, oldUser, attributes)
+}
+
 // UserHasJoinedChannel provides a mock function with given fields: c, channelMember, actor
 func (_m *Hooks) UserHasJoinedChannel(c *plugin.Context, channelMember *model.ChannelMember, actor *model.User) {
 	_m.Called(c, channelMember, actor)
@@ -569,6 +767,45 @@ func (_m *Hooks) UserHasLoggedIn(c *plugin.Context, user *model.User) {
 	_m.Called(c, user)
 }
 
+// UserWillBeUpdated provides a mock function with given fields: c, newUser, oldUser, attributes
+func (_m *Hooks) UserWillBeUpdated(c *plugin.Context, newUser *model.User, oldUser *model.User, attributes map[string]string) (*model.User, map[string]string, string) {
+	ret := _m.Called(c, newUser, oldUser, attributes)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UserWillBeUpdated")
+	}
+
+	var r0 *model.User
+	var r1 map[string]string
+	var r2 string
+	if rf, ok := ret.Get(0).(func(*plugin.Context, *model.User, *model.User, map[string]string) (*model.User, map[string]string, string)); ok {
+		return rf(c, newUser, oldUser, attributes)
+	}
+	if rf, ok := ret.Get(0).(func(*plugin.Context, *model.User, *model.User, map[string]string) *model.User); ok {
+		r0 = rf(c, newUser, oldUser, attributes)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*plugin.Context, *model.User, *model.User, map[string]string) map[string]string); ok {
+		r1 = rf(c, newUser, oldUser, attributes)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(map[string]string)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(*plugin.Context, *model.User, *model.User, map[string]string) string); ok {
+		r2 = rf(c, newUser, oldUser, attributes)
+	} else {
+		r2 = ret.Get(2).(string)
+	}
+
+	return r0, r1, r2
+}
+
 // UserWillLogIn provides a mock function with given fields: c, user
 func (_m *Hooks) UserWillLogIn(c *plugin.Context, user *model.User) string {
 	ret := _m.Called(c, user)